@@ -0,0 +1,217 @@
+// Package accesslog writes one line per HTTP request in the Apache
+// combined format or as JSON. Streams are logged when they open and when
+// they close, rather than per frame.
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Log formats, chosen with SHOUT_LOG_ACCESS_FORMAT.
+const (
+	FormatCombined = "combined"
+	FormatJSON     = "json"
+)
+
+// Stream events, logged in place of a single line for streams.
+const (
+	EventStreamOpen  = "stream-open"
+	EventStreamClose = "stream-close"
+)
+
+// combinedTime is the timestamp layout of the Apache formats.
+const combinedTime = "02/Jan/2006:15:04:05 -0700"
+
+// Entry is one logged request.
+type Entry struct {
+	// Time is when the request arrived.
+	Time time.Time `json:"time"`
+	IP   string    `json:"ip"`
+	// User is the API key's name, if any.
+	User      string `json:"user,omitempty"`
+	Method    string `json:"method"`
+	URI       string `json:"uri"`
+	Proto     string `json:"proto"`
+	Status    int    `json:"status"`
+	Bytes     int64  `json:"bytes"`
+	Referer   string `json:"referer,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	// Latency is how long the response took; for a stream's open event,
+	// until the stream started.
+	Latency time.Duration `json:"-"`
+	// Event is EventStreamOpen or EventStreamClose for streams.
+	Event string `json:"event,omitempty"`
+}
+
+// Logger writes entries to stdout, stderr or a file. It is safe for
+// concurrent use.
+//
+// Usage example:
+//
+//	logger, err := accesslog.Open(cfg.Log)
+//	logger.Log(accesslog.Entry{Time: start, Method: "GET", URI: "/HI", Status: 200})
+type Logger struct {
+	format string
+	path   string
+
+	mu sync.Mutex
+	w  io.Writer
+	// file is the open log file, when logging to one
+	file *os.File
+}
+
+// Open creates the logger selected by the configuration.
+//
+// Parameters:
+//   - cfg: access log format and output
+//
+// Returns:
+//   - *Logger: the logger, or nil when access logging is off
+//   - error: error if the log file cannot be opened
+//
+// Example:
+//
+//	logger, err := accesslog.Open(cfg.Log)
+//	if err != nil {
+//	    log.Fatalf("Failed to open access log: %v", err)
+//	}
+func Open(cfg config.LogConfig) (*Logger, error) {
+	switch cfg.AccessFormat {
+	case "", "off":
+		return nil, nil
+	}
+	l := &Logger{format: cfg.AccessFormat}
+	switch cfg.AccessOutput {
+	case "", "stdout":
+		l.w = os.Stdout
+	case "stderr":
+		l.w = os.Stderr
+	default:
+		l.path = cfg.AccessOutput
+		if err := l.Reopen(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// New creates a logger writing to w, for tests and embedding.
+//
+// Parameters:
+//   - w: where lines are written
+//   - format: FormatCombined or FormatJSON
+//
+// Returns:
+//   - *Logger: the logger
+func New(w io.Writer, format string) *Logger {
+	return &Logger{format: format, w: w}
+}
+
+// Reopen closes and reopens the log file, so a file moved away by log
+// rotation is replaced. It does nothing when logging to stdout or stderr.
+//
+// Returns:
+//   - error: error if the file cannot be opened; logging continues to the
+//     old file
+func (l *Logger) Reopen() error {
+	if l.path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open access log: %w", err)
+	}
+	l.mu.Lock()
+	old := l.file
+	l.file, l.w = file, file
+	l.mu.Unlock()
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// Close closes the log file, if any.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Log writes e as one line.
+//
+// Parameters:
+//   - e: the request to log
+func (l *Logger) Log(e Entry) {
+	var line []byte
+	if l.format == FormatJSON {
+		line = jsonLine(e)
+	} else {
+		line = []byte(combinedLine(e))
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(line)
+}
+
+// combinedLine formats e in the Apache combined format, followed by the
+// latency in microseconds (like %D) and the stream event, if any.
+func combinedLine(e Entry) string {
+	bytes := "-"
+	if e.Bytes > 0 {
+		bytes = strconv.FormatInt(e.Bytes, 10)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s - %s [%s] \"%s %s %s\" %d %s \"%s\" \"%s\" %d",
+		dash(e.IP), dash(quoteSafe(e.User)), e.Time.Format(combinedTime),
+		e.Method, quoteSafe(e.URI), e.Proto, e.Status, bytes,
+		dash(quoteSafe(e.Referer)), dash(quoteSafe(e.UserAgent)), e.Latency.Microseconds())
+	if e.Event != "" {
+		b.WriteString(" " + e.Event)
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// jsonLine formats e as a JSON object with the latency in milliseconds.
+func jsonLine(e Entry) []byte {
+	line, err := json.Marshal(struct {
+		Entry
+		LatencyMS float64 `json:"latency_ms"`
+	}{e, float64(e.Latency.Microseconds()) / 1000})
+	if err != nil {
+		return nil
+	}
+	return append(line, '\n')
+}
+
+// dash returns s, or "-" if it is empty, as Apache logs missing values.
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// quoteSafe escapes the quotes, backslashes and control characters that
+// would let a client forge log fields.
+func quoteSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return '?'
+		}
+		return r
+	}, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+}
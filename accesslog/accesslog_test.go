@@ -0,0 +1,114 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+var testEntry = Entry{
+	Time:      time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC),
+	IP:        "203.0.113.7",
+	Method:    "GET",
+	URI:       "/HELLO?f=doom",
+	Proto:     "HTTP/1.1",
+	Status:    200,
+	Bytes:     512,
+	UserAgent: "curl/8.5.0",
+	Latency:   1500 * time.Microsecond,
+}
+
+func TestLogCombined(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry func(e Entry) Entry
+		want  string
+	}{
+		{
+			name:  "request",
+			entry: func(e Entry) Entry { return e },
+			want:  `203.0.113.7 - - [01/Mar/2025:10:30:00 +0000] "GET /HELLO?f=doom HTTP/1.1" 200 512 "-" "curl/8.5.0" 1500` + "\n",
+		},
+		{
+			name: "stream open with a key",
+			entry: func(e Entry) Entry {
+				e.User, e.Bytes, e.Event = "acme", 0, EventStreamOpen
+				return e
+			},
+			want: `203.0.113.7 - acme [01/Mar/2025:10:30:00 +0000] "GET /HELLO?f=doom HTTP/1.1" 200 - "-" "curl/8.5.0" 1500 stream-open` + "\n",
+		},
+		{
+			name: "forged fields",
+			entry: func(e Entry) Entry {
+				e.UserAgent = "x\" 200 \"\n"
+				return e
+			},
+			want: `203.0.113.7 - - [01/Mar/2025:10:30:00 +0000] "GET /HELLO?f=doom HTTP/1.1" 200 512 "-" "x\" 200 \"?" 1500` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			New(&out, FormatCombined).Log(tt.entry(testEntry))
+			if out.String() != tt.want {
+				t.Errorf("line =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLogJSON(t *testing.T) {
+	var out bytes.Buffer
+	New(&out, FormatJSON).Log(testEntry)
+
+	var got map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if got["latency_ms"] != 1.5 || got["bytes"] != 512.0 || got["uri"] != "/HELLO?f=doom" || got["time"] != "2025-03-01T10:30:00Z" {
+		t.Errorf("entry = %v", got)
+	}
+	if _, ok := got["event"]; ok {
+		t.Errorf("plain request has an event: %v", got)
+	}
+}
+
+func TestOpenFileReopen(t *testing.T) {
+	if logger, err := Open(config.LogConfig{AccessFormat: "off"}); logger != nil || err != nil {
+		t.Fatalf("Open(off) = %v, %v, want nil, nil", logger, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "access.log")
+	logger, err := Open(config.LogConfig{AccessFormat: FormatCombined, AccessOutput: path})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer logger.Close()
+	logger.Log(testEntry)
+
+	// Rotate the file away, as logrotate does, then reopen
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Reopen(); err != nil {
+		t.Fatalf("Reopen() error = %v", err)
+	}
+	logger.Log(testEntry)
+
+	for _, p := range []string{path + ".1", path} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s has %q, want one line", p, data)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package ansiart plays back classic BBS-era ANSI artwork (.ANS files):
+// it splits off the SAUCE metadata record, translates the CP437 bytes to
+// UTF-8 and keeps only the escape sequences that draw, so the art renders
+// in a modern terminal as it did under ANSI.SYS.
+package ansiart
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultWidth is the column count .ANS files assume when SAUCE gives none.
+const DefaultWidth = 80
+
+// eof ends the artwork; SAUCE data may follow it.
+const eof = 0x1A
+
+// cp437 maps each CP437 byte to its Unicode character. The low control
+// range holds the symbols DOS drew for those codes.
+var cp437 = [256]rune{
+	' ', '☺', '☻', '♥', '♦', '♣', '♠', '•', '◘', '○', '◙', '♂', '♀', '♪', '♫', '☼',
+	'►', '◄', '↕', '‼', '¶', '§', '▬', '↨', '↑', '↓', '→', '←', '∟', '↔', '▲', '▼',
+	' ', '!', '"', '#', '$', '%', '&', '\'', '(', ')', '*', '+', ',', '-', '.', '/',
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', ':', ';', '<', '=', '>', '?',
+	'@', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O',
+	'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '[', '\\', ']', '^', '_',
+	'`', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o',
+	'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z', '{', '|', '}', '~', '⌂',
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å',
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', '¢', '£', '¥', '₧', 'ƒ',
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '⌐', '¬', '½', '¼', '¡', '«', '»',
+	'░', '▒', '▓', '│', '┤', '╡', '╢', '╖', '╕', '╣', '║', '╗', '╝', '╜', '╛', '┐',
+	'└', '┴', '┬', '├', '─', '┼', '╞', '╟', '╚', '╔', '╩', '╦', '╠', '═', '╬', '╧',
+	'╨', '╤', '╥', '╙', '╘', '╒', '╓', '╫', '╪', '┘', '┌', '█', '▄', '▌', '▐', '▀',
+	'α', 'ß', 'Γ', 'π', 'Σ', 'σ', 'µ', 'τ', 'Φ', 'Θ', 'Ω', 'δ', '∞', 'φ', 'ε', '∩',
+	'≡', '±', '≥', '≤', '⌠', '⌡', '÷', '≈', '°', '∙', '·', '√', 'ⁿ', '²', '■', '\u00a0',
+}
+
+// allowedCSI lists the final bytes of the control sequences passed
+// through: colors, cursor movement, erasing and cursor save/restore.
+// Anything else, such as mode changes or status reports that make the
+// terminal answer, is dropped.
+const allowedCSI = "mABCDHfJKsu"
+
+// DecodeCP437 translates CP437 text, such as SAUCE fields, to UTF-8.
+//
+// Parameters:
+//   - data: the CP437 bytes
+//
+// Returns:
+//   - string: the UTF-8 text
+//
+// Example:
+//
+//	DecodeCP437([]byte{0xDB, 0xB2}) // "█▓"
+func DecodeCP437(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		b.WriteRune(cp437[c])
+	}
+	return b.String()
+}
+
+// Translate converts .ANS artwork to UTF-8 terminal output. CP437
+// characters become their Unicode equivalents, color and cursor sequences
+// pass through, other escape sequences are dropped, and lines wrap at
+// width columns as they did on an 80-column DOS screen, so the art keeps
+// its shape on wider terminals. The output ends with an attribute reset.
+// Data after the DOS end-of-file character (0x1A) is ignored; use Split
+// first to read the SAUCE record.
+//
+// Parameters:
+//   - art: the artwork bytes without SAUCE
+//   - width: the screen width the art was drawn for; below 1 means DefaultWidth
+//
+// Returns:
+//   - string: UTF-8 output ready to write to a terminal
+//
+// Example:
+//
+//	art, sauce := ansiart.Split(data)
+//	fmt.Print(ansiart.Translate(art, sauce.Columns()))
+func Translate(art []byte, width int) string {
+	if width < 1 {
+		width = DefaultWidth
+	}
+	t := &translator{width: width}
+	for i := 0; i < len(art); i++ {
+		switch c := art[i]; c {
+		case eof:
+			i = len(art)
+		case '\r':
+			t.b.WriteByte('\r')
+			t.col, t.pending = 0, false
+		case '\n':
+			// Terminals add the carriage return on output
+			t.b.WriteByte('\n')
+			t.col, t.pending = 0, false
+		case '\t':
+			t.print(' ')
+			for t.col%8 != 0 && !t.pending {
+				t.print(' ')
+			}
+		case 0x1B:
+			i = t.escape(art, i)
+		default:
+			t.print(cp437[c])
+		}
+	}
+	t.b.WriteString("\x1b[0m")
+	return t.b.String()
+}
+
+// translator tracks the cursor column so lines can wrap where DOS
+// wrapped them.
+type translator struct {
+	b     strings.Builder
+	width int
+	col   int
+	saved int
+	// pending is set after drawing in the last column; the next character
+	// wraps to a new line first, as on a VT100
+	pending bool
+}
+
+// print draws one character.
+func (t *translator) print(r rune) {
+	if t.pending {
+		t.b.WriteString("\r\n")
+		t.col, t.pending = 0, false
+	}
+	t.b.WriteRune(r)
+	t.col++
+	if t.col >= t.width {
+		t.col, t.pending = t.width-1, true
+	}
+}
+
+// escape handles the escape sequence starting at art[i] and returns the
+// index of its last byte.
+func (t *translator) escape(art []byte, i int) int {
+	if i+1 >= len(art) {
+		return i
+	}
+	switch art[i+1] {
+	case '[':
+	case ']', 'P', '_', '^':
+		// Skip string sequences up to BEL or ESC \
+		for j := i + 2; j < len(art); j++ {
+			if art[j] == 0x07 || art[j] == eof {
+				return j
+			}
+			if art[j] == 0x1B && j+1 < len(art) && art[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(art)
+	default:
+		return i + 1
+	}
+
+	// Control sequence: parameter bytes, intermediate bytes, final byte
+	j := i + 2
+	for j < len(art) && art[j] >= 0x20 && art[j] <= 0x3F {
+		j++
+	}
+	if j >= len(art) || art[j] < 0x40 || art[j] > 0x7E {
+		return j - 1
+	}
+	params, final := string(art[i+2:j]), art[j]
+	if strings.IndexByte(allowedCSI, final) < 0 || strings.ContainsAny(params, "?<=> !\"#$%&'()*+,-./") {
+		return j
+	}
+
+	t.b.WriteString("\x1b[" + params + string(final))
+	t.move(final, params)
+	return j
+}
+
+// move updates the cursor column for a control sequence.
+func (t *translator) move(final byte, params string) {
+	args := strings.Split(params, ";")
+	arg := func(n, def int) int {
+		if n >= len(args) {
+			return def
+		}
+		v, err := strconv.Atoi(args[n])
+		if err != nil || v < 1 {
+			return def
+		}
+		return v
+	}
+
+	switch final {
+	case 'C':
+		t.col = min(t.col+arg(0, 1), t.width-1)
+	case 'D':
+		t.col = max(t.col-arg(0, 1), 0)
+	case 'H', 'f':
+		t.col = min(arg(1, 1)-1, t.width-1)
+	case 'J':
+		// ANSI.SYS homes the cursor when clearing the screen
+		if arg(0, 0) == 2 {
+			t.col = 0
+		}
+	case 's':
+		t.saved = t.col
+	case 'u':
+		t.col = t.saved
+	case 'm', 'K':
+		return
+	}
+	t.pending = false
+}
@@ -0,0 +1,61 @@
+package ansiart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeCP437(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want string
+	}{
+		{in: []byte("Hello"), want: "Hello"},
+		{in: []byte{0xB0, 0xB1, 0xB2, 0xDB}, want: "░▒▓█"},
+		{in: []byte{0xC9, 0xCD, 0xBB}, want: "╔═╗"},
+		{in: []byte{0x01, 0x03, 0x7F}, want: "☺♥⌂"},
+		{in: []byte{0x82, 0xE1, 0xFF}, want: "éß\u00a0"},
+	}
+	for _, tt := range tests {
+		if got := DecodeCP437(tt.in); got != tt.want {
+			t.Errorf("DecodeCP437(% x) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name  string
+		art   string
+		width int
+		want  string
+	}{
+		{name: "plain text", art: "HI\r\nTHERE", want: "HI\r\nTHERE"},
+		{name: "cp437 blocks", art: "\xdb\xb2\xb1\xb0", want: "█▓▒░"},
+		{name: "colors pass through", art: "\x1b[1;31mRED\x1b[0m", want: "\x1b[1;31mRED\x1b[0m"},
+		{name: "cursor moves pass through", art: "A\x1b[5CB\x1b[2A", want: "A\x1b[5CB\x1b[2A"},
+		{name: "mode changes dropped", art: "\x1b[?25lA\x1b[=7hB", want: "AB"},
+		{name: "status reports dropped", art: "A\x1b[6nB", want: "AB"},
+		{name: "title sequences dropped", art: "\x1b]0;pwned\x07A\x1b]2;x\x1b\\B", want: "AB"},
+		{name: "other escapes dropped", art: "A\x1bcB", want: "AB"},
+		{name: "stops at EOF", art: "ART\x1aSAUCE", want: "ART"},
+		{name: "wraps at width", art: "ABCDEF", width: 4, want: "ABCD\r\nEF"},
+		{name: "full line then newline doesn't double wrap", art: "ABCD\r\nEF", width: 4, want: "ABCD\r\nEF"},
+		{name: "cursor forward counts toward wrap", art: "A\x1b[2CBC", width: 4, want: "A\x1b[2CB\r\nC"},
+		{name: "absolute position resets column", art: "AB\x1b[1;1HCDEF", width: 4, want: "AB\x1b[1;1HCDEF"},
+		{name: "tabs expand to spaces", art: "A\tB", want: "A       B"},
+		{name: "unterminated sequence", art: "A\x1b[31", want: "A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Translate([]byte(tt.art), tt.width)
+			if !strings.HasSuffix(got, "\x1b[0m") {
+				t.Errorf("output %q should end with an attribute reset", got)
+			}
+			if got = strings.TrimSuffix(got, "\x1b[0m"); got != tt.want {
+				t.Errorf("Translate(%q) = %q, want %q", tt.art, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package ansiart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// SAUCE record layout; see https://www.acid.org/info/sauce/sauce.htm.
+const (
+	sauceSize    = 128
+	sauceID      = "SAUCE00"
+	commentID    = "COMNT"
+	commentSize  = 64
+	dataTypeChar = 1
+	flagICE      = 0x01
+)
+
+// Sauce is the metadata record appended to artwork by the SAUCE standard.
+type Sauce struct {
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	Group  string `json:"group,omitempty"`
+	// Date is when the art was made, as YYYY-MM-DD; empty if unset or invalid
+	Date string `json:"date,omitempty"`
+	// Width and Height are the character dimensions, 0 if unknown
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// ICEColors is set when blink bits select bright backgrounds instead
+	ICEColors bool `json:"iceColors,omitempty"`
+	// Font names the intended font, such as "IBM VGA"
+	Font     string   `json:"font,omitempty"`
+	Comments []string `json:"comments,omitempty"`
+}
+
+// Columns returns the screen width the art was drawn for, or DefaultWidth
+// if the record is nil or doesn't say.
+//
+// Returns:
+//   - int: the width in columns
+func (s *Sauce) Columns() int {
+	if s == nil || s.Width < 1 {
+		return DefaultWidth
+	}
+	return s.Width
+}
+
+// Split separates artwork from its SAUCE record and comment block, if it
+// has one. The art is cut at the DOS end-of-file character that precedes
+// the record.
+//
+// Parameters:
+//   - data: the contents of an .ANS file
+//
+// Returns:
+//   - []byte: the artwork
+//   - *Sauce: the metadata, or nil if the file has none
+//
+// Example:
+//
+//	art, sauce := ansiart.Split(data)
+//	if sauce != nil {
+//	    log.Printf("%q by %s", sauce.Title, sauce.Author)
+//	}
+func Split(data []byte) ([]byte, *Sauce) {
+	if len(data) < sauceSize || string(data[len(data)-sauceSize:len(data)-sauceSize+len(sauceID)]) != sauceID {
+		return cutEOF(data), nil
+	}
+	record := data[len(data)-sauceSize:]
+	art := data[:len(data)-sauceSize]
+
+	s := &Sauce{
+		Title:  field(record[7:42]),
+		Author: field(record[42:62]),
+		Group:  field(record[62:82]),
+		Font:   field(record[106:128]),
+	}
+	if d, err := time.Parse("20060102", string(record[82:90])); err == nil {
+		s.Date = d.Format("2006-01-02")
+	}
+	if record[94] == dataTypeChar {
+		s.Width = int(binary.LittleEndian.Uint16(record[96:98]))
+		s.Height = int(binary.LittleEndian.Uint16(record[98:100]))
+		s.ICEColors = record[105]&flagICE != 0
+	}
+
+	if n := int(record[104]); n > 0 {
+		size := len(commentID) + n*commentSize
+		if start := len(art) - size; start >= 0 && string(art[start:start+len(commentID)]) == commentID {
+			for i := 0; i < n; i++ {
+				line := art[start+len(commentID)+i*commentSize:][:commentSize]
+				s.Comments = append(s.Comments, field(line))
+			}
+			art = art[:start]
+		}
+	}
+	return cutEOF(art), s
+}
+
+// cutEOF returns data up to the first end-of-file character.
+func cutEOF(data []byte) []byte {
+	if i := bytes.IndexByte(data, eof); i >= 0 {
+		return data[:i]
+	}
+	return data
+}
+
+// field decodes a space or NUL padded SAUCE string.
+func field(b []byte) string {
+	return strings.TrimSpace(DecodeCP437(bytes.TrimRight(b, "\x00 ")))
+}
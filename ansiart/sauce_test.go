@@ -0,0 +1,85 @@
+package ansiart
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// sauceRecord builds a SAUCE record for character art.
+func sauceRecord(title, author, group, date string, width, height int, flags byte, comments int) []byte {
+	r := make([]byte, sauceSize)
+	for i := 7; i < sauceSize; i++ {
+		r[i] = ' '
+	}
+	copy(r, sauceID)
+	copy(r[7:42], title)
+	copy(r[42:62], author)
+	copy(r[62:82], group)
+	copy(r[82:90], date)
+	r[94] = dataTypeChar
+	r[95] = 1
+	binary.LittleEndian.PutUint16(r[96:], uint16(width))
+	binary.LittleEndian.PutUint16(r[98:], uint16(height))
+	r[104] = byte(comments)
+	r[105] = flags
+	copy(r[106:], "IBM VGA\x00\x00")
+	return r
+}
+
+func TestSplit(t *testing.T) {
+	art := []byte("\x1b[31m\xdb\xdb\r\n")
+
+	t.Run("no sauce", func(t *testing.T) {
+		got, sauce := Split(art)
+		if string(got) != string(art) || sauce != nil {
+			t.Errorf("Split() = %q, %+v; want the art unchanged and no SAUCE", got, sauce)
+		}
+		if sauce.Columns() != DefaultWidth {
+			t.Errorf("Columns() = %d, want %d", sauce.Columns(), DefaultWidth)
+		}
+	})
+
+	t.Run("with sauce", func(t *testing.T) {
+		data := append(append(append([]byte{}, art...), eof), sauceRecord("Dragon \x82", "Ansi Artist", "ACiD", "19960704", 160, 50, flagICE, 0)...)
+		got, sauce := Split(data)
+		if string(got) != string(art) {
+			t.Errorf("art = %q, want %q", got, art)
+		}
+		if sauce == nil {
+			t.Fatal("expected a SAUCE record")
+		}
+		want := Sauce{Title: "Dragon é", Author: "Ansi Artist", Group: "ACiD", Date: "1996-07-04", Width: 160, Height: 50, ICEColors: true, Font: "IBM VGA"}
+		if sauce.Title != want.Title || sauce.Author != want.Author || sauce.Group != want.Group || sauce.Date != want.Date ||
+			sauce.Width != want.Width || sauce.Height != want.Height || sauce.ICEColors != want.ICEColors || sauce.Font != want.Font {
+			t.Errorf("sauce = %+v, want %+v", *sauce, want)
+		}
+		if sauce.Columns() != 160 {
+			t.Errorf("Columns() = %d, want 160", sauce.Columns())
+		}
+	})
+
+	t.Run("with comments", func(t *testing.T) {
+		comment := make([]byte, commentSize*2)
+		for i := range comment {
+			comment[i] = ' '
+		}
+		copy(comment, "first line")
+		copy(comment[commentSize:], "second line")
+
+		data := append(append([]byte{}, art...), eof)
+		data = append(data, commentID...)
+		data = append(data, comment...)
+		data = append(data, sauceRecord("T", "A", "G", "bad date", 80, 25, 0, 2)...)
+
+		got, sauce := Split(data)
+		if string(got) != string(art) {
+			t.Errorf("art = %q, want %q", got, art)
+		}
+		if len(sauce.Comments) != 2 || sauce.Comments[0] != "first line" || sauce.Comments[1] != "second line" {
+			t.Errorf("comments = %q", sauce.Comments)
+		}
+		if sauce.Date != "" {
+			t.Errorf("date = %q, want empty for an invalid date", sauce.Date)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+// Package apierrors defines the errors the shout.sh API returns to clients.
+// Each error has a stable code for scripts and an HTTP status, and is sent
+// as plain text to curl or as problem details (RFC 9457) to JSON clients.
+package apierrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderErrorCode carries the error code on every error response, so
+// scripts can branch on it without parsing the body.
+const HeaderErrorCode = "X-Shout-Error"
+
+// MIMEProblemJSON is the media type of problem details responses.
+const MIMEProblemJSON = "application/problem+json"
+
+// Errors returned by the API. Use WithDetail and WithHint to add request
+// specifics; the copies still match the originals with errors.Is.
+var (
+	// ErrNoText means the request path held no renderable text.
+	ErrNoText = &Error{Code: "no_text", Status: fiber.StatusBadRequest, Message: "No text provided"}
+	// ErrTextTooLong means the text exceeded SHOUT_TEXT_MAX_LENGTH.
+	ErrTextTooLong = &Error{Code: "text_too_long", Status: fiber.StatusBadRequest, Message: "Text too long"}
+	// ErrBlockedText means the text contained blocked words.
+	ErrBlockedText = &Error{Code: "blocked_text", Status: fiber.StatusBadRequest, Message: "Text contains blocked words"}
+	// ErrBadOption means a query parameter had an invalid value.
+	ErrBadOption = &Error{Code: "bad_option", Status: fiber.StatusBadRequest, Message: "Invalid option"}
+	// ErrFallback means a ?strict=true request names a font that isn't
+	// loaded, or has characters its font can't draw.
+	ErrFallback = &Error{Code: "fallback", Status: fiber.StatusBadRequest, Message: "Text can't be drawn as requested"}
+	// ErrFontNotFound means neither the requested nor the default font is loaded.
+	ErrFontNotFound = &Error{Code: "font_not_found", Status: fiber.StatusNotFound, Message: "Font not found"}
+	// ErrNotAcceptable means the route can't send any of the media types
+	// the Accept header asks for.
+	ErrNotAcceptable = &Error{Code: "not_acceptable", Status: fiber.StatusNotAcceptable, Message: "No acceptable format"}
+	// ErrBodyTooLarge means the request body exceeded its size limit.
+	ErrBodyTooLarge = &Error{Code: "body_too_large", Status: fiber.StatusRequestEntityTooLarge, Message: "Request body too large"}
+	// ErrURITooLong means the request URL exceeded SHOUT_SERVER_MAX_URL_BYTES.
+	ErrURITooLong = &Error{Code: "uri_too_long", Status: fiber.StatusRequestURITooLong, Message: "Request URL too long"}
+	// ErrHeadersTooLarge means the request line and headers exceeded
+	// SHOUT_SERVER_MAX_HEADER_BYTES.
+	ErrHeadersTooLarge = &Error{Code: "headers_too_large", Status: fiber.StatusRequestHeaderFieldsTooLarge, Message: "Request headers too large"}
+	// ErrKeyRequired means the server only answers requests with an API key.
+	ErrKeyRequired = &Error{Code: "key_required", Status: fiber.StatusUnauthorized, Message: "API key required"}
+	// ErrInvalidKey means the API key is unknown.
+	ErrInvalidKey = &Error{Code: "invalid_key", Status: fiber.StatusUnauthorized, Message: "Invalid API key"}
+	// ErrBadSignature means a signed request mode server got a request
+	// without a valid, current signature.
+	ErrBadSignature = &Error{Code: "bad_signature", Status: fiber.StatusUnauthorized, Message: "Missing or invalid request signature"}
+	// ErrIPDenied means the client's IP is not on the allow list, or is on
+	// the deny list.
+	ErrIPDenied = &Error{Code: "ip_denied", Status: fiber.StatusForbidden, Message: "Access denied for your IP"}
+	// ErrCountryDenied means the client's country is refused by the GeoIP
+	// policy.
+	ErrCountryDenied = &Error{Code: "country_denied", Status: fiber.StatusForbidden, Message: "Access denied from your country"}
+	// ErrSuspectedBot means the client was classified as a scanner or bot.
+	ErrSuspectedBot = &Error{Code: "suspected_bot", Status: fiber.StatusForbidden, Message: "Request looks automated"}
+	// ErrNotEntitled means the API key's tier doesn't include the feature.
+	ErrNotEntitled = &Error{Code: "not_entitled", Status: fiber.StatusForbidden, Message: "Your plan doesn't include this feature"}
+	// ErrFeatureDisabled means the operator turned the route's feature off
+	// (SHOUT_FEATURES_*).
+	ErrFeatureDisabled = &Error{Code: "feature_disabled", Status: fiber.StatusNotFound, Message: "This feature is disabled on this server"}
+	// ErrShortlinkNotFound means a short link ID is unknown or expired.
+	ErrShortlinkNotFound = &Error{Code: "shortlink_not_found", Status: fiber.StatusNotFound, Message: "Short link not found"}
+	// ErrPresetNotFound means the API key hasn't saved a preset by the name.
+	ErrPresetNotFound = &Error{Code: "preset_not_found", Status: fiber.StatusNotFound, Message: "Saved preset not found"}
+	// ErrPresetLimit means the API key has saved as many presets as it may.
+	ErrPresetLimit = &Error{Code: "preset_limit", Status: fiber.StatusConflict, Message: "Too many saved presets"}
+	// ErrArtNotFound means no .ANS artwork has the requested name.
+	ErrArtNotFound = &Error{Code: "art_not_found", Status: fiber.StatusNotFound, Message: "Artwork not found"}
+	// ErrRateLimited means the client made too many requests.
+	ErrRateLimited = &Error{Code: "rate_limited", Status: fiber.StatusTooManyRequests, Message: "Too many requests, try again later"}
+	// ErrQuotaExceeded means the client used up its daily quota.
+	ErrQuotaExceeded = &Error{Code: "quota_exceeded", Status: fiber.StatusTooManyRequests, Message: "Daily quota used up"}
+	// ErrStreamCapacity means the server is at its concurrent stream limit.
+	ErrStreamCapacity = &Error{Code: "stream_capacity", Status: fiber.StatusServiceUnavailable, Message: "Too many active streams, try again later"}
+	// ErrRenderTimeout means rendering exceeded its time budget.
+	ErrRenderTimeout = &Error{Code: "render_timeout", Status: fiber.StatusServiceUnavailable, Message: "Rendering took too long"}
+	// ErrQueueFull means the async render queue has no room for the job.
+	ErrQueueFull = &Error{Code: "queue_full", Status: fiber.StatusServiceUnavailable, Message: "Too many queued renders, try again later"}
+	// ErrRelayFailed means a relayed banner couldn't be delivered.
+	ErrRelayFailed = &Error{Code: "relay_failed", Status: fiber.StatusBadGateway, Message: "Could not deliver the banner"}
+	// ErrRenderFailed means rendering failed for an unexpected reason.
+	ErrRenderFailed = &Error{Code: "render_failed", Status: fiber.StatusInternalServerError, Message: "Error generating ASCII art"}
+)
+
+// Error is an API error with a stable code and HTTP status.
+//
+// Usage example:
+//
+//	return apierrors.Send(c, apierrors.ErrTextTooLong.WithDetail("max %d characters", 20))
+type Error struct {
+	// Code identifies the error for scripts, e.g. "text_too_long".
+	Code string
+	// Status is the HTTP status code sent with the error.
+	Status int
+	// Message is a short human-readable summary.
+	Message string
+	// Detail explains this occurrence, if anything beyond Message.
+	Detail string
+	// Hints suggest how to fix the request.
+	Hints []string
+}
+
+// Error returns the message and detail.
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return e.Message
+	}
+	return e.Message + ": " + e.Detail
+}
+
+// Is reports whether target is an Error with the same code, so copies
+// made by WithDetail and WithHint match the package's errors.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail returns a copy of the error with the detail set.
+//
+// Parameters:
+//   - format: fmt format string for the detail
+//   - args: format arguments
+//
+// Returns:
+//   - *Error: the copy
+//
+// Example:
+//
+//	err := apierrors.ErrBadOption.WithDetail("%v", parseErr)
+func (e *Error) WithDetail(format string, args ...any) *Error {
+	cp := *e
+	cp.Detail = fmt.Sprintf(format, args...)
+	return &cp
+}
+
+// WithHint returns a copy of the error with a hint added. Empty hints are
+// ignored.
+//
+// Parameters:
+//   - hint: advice for fixing the request
+//
+// Returns:
+//   - *Error: the copy
+//
+// Example:
+//
+//	err := apierrors.ErrRenderTimeout.WithHint("try shorter text")
+func (e *Error) WithHint(hint string) *Error {
+	cp := *e
+	if hint != "" {
+		cp.Hints = append(append([]string(nil), e.Hints...), hint)
+	}
+	return &cp
+}
+
+// Send writes err as the response. Clients that accept JSON get problem
+// details; everyone else gets "Error: ..." plus one "Hint: ..." line per
+// hint. Errors that aren't an *Error are sent as ErrRenderFailed without
+// exposing their text.
+//
+// Parameters:
+//   - c: the request context
+//   - err: the error to send
+//
+// Returns:
+//   - error: the result of writing the response
+//
+// Example:
+//
+//	if text == "" {
+//	    return apierrors.Send(c, apierrors.ErrNoText)
+//	}
+func Send(c *fiber.Ctx, err error) error {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = ErrRenderFailed
+	}
+
+	c.Set(HeaderErrorCode, apiErr.Code)
+	c.Status(apiErr.Status)
+
+	if strings.Contains(c.Get(fiber.HeaderAccept), "json") {
+		problem := fiber.Map{
+			"type":   "about:blank",
+			"title":  apiErr.Message,
+			"status": apiErr.Status,
+			"code":   apiErr.Code,
+		}
+		if apiErr.Detail != "" {
+			problem["detail"] = apiErr.Detail
+		}
+		if len(apiErr.Hints) > 0 {
+			problem["hints"] = apiErr.Hints
+		}
+		return c.JSON(problem, MIMEProblemJSON)
+	}
+
+	var b strings.Builder
+	b.WriteString("Error: " + apiErr.Error() + "\n")
+	for _, hint := range apiErr.Hints {
+		b.WriteString("Hint: " + hint + "\n")
+	}
+	c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+	return c.SendString(b.String())
+}
@@ -0,0 +1,125 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("validating: %w", ErrTextTooLong.WithDetail("max %d characters", 20))
+
+	if !errors.Is(err, ErrTextTooLong) {
+		t.Error("copy made by WithDetail should match ErrTextTooLong")
+	}
+	if errors.Is(err, ErrNoText) {
+		t.Error("errors with different codes should not match")
+	}
+	if got := ErrTextTooLong.WithDetail("max 20 characters").Error(); got != "Text too long: max 20 characters" {
+		t.Errorf("Error() = %q", got)
+	}
+	if ErrTextTooLong.Detail != "" || len(ErrRenderTimeout.WithHint("x").Hints) != 1 || len(ErrRenderTimeout.Hints) != 0 {
+		t.Error("WithDetail and WithHint must not modify the original error")
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		accept      string
+		wantStatus  int
+		wantCode    string
+		wantType    string
+		wantBody    string
+		wantProblem map[string]any
+	}{
+		{
+			name:       "plain text",
+			err:        ErrTextTooLong.WithDetail("max 20 characters"),
+			wantStatus: 400,
+			wantCode:   "text_too_long",
+			wantType:   "text/plain; charset=utf-8",
+			wantBody:   "Error: Text too long: max 20 characters\n",
+		},
+		{
+			name:       "plain text with hints",
+			err:        ErrRenderTimeout.WithHint("try shorter text").WithHint("").WithHint("Did you mean font=doom?"),
+			wantStatus: 503,
+			wantCode:   "render_timeout",
+			wantType:   "text/plain; charset=utf-8",
+			wantBody:   "Error: Rendering took too long\nHint: try shorter text\nHint: Did you mean font=doom?\n",
+		},
+		{
+			name:       "problem details",
+			err:        ErrBadOption.WithDetail("invalid color").WithHint("see /colors"),
+			accept:     "application/json",
+			wantStatus: 400,
+			wantCode:   "bad_option",
+			wantType:   MIMEProblemJSON,
+			wantProblem: map[string]any{
+				"type":   "about:blank",
+				"title":  "Invalid option",
+				"status": float64(400),
+				"code":   "bad_option",
+				"detail": "invalid color",
+				"hints":  []any{"see /colors"},
+			},
+		},
+		{
+			name:       "unknown error hides its text",
+			err:        errors.New("open /etc/fonts: permission denied"),
+			wantStatus: 500,
+			wantCode:   "render_failed",
+			wantType:   "text/plain; charset=utf-8",
+			wantBody:   "Error: Error generating ASCII art\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error { return Send(c, tt.err) })
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("%s = %q, want %q", HeaderErrorCode, got, tt.wantCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+
+			if tt.wantProblem == nil {
+				if string(body) != tt.wantBody {
+					t.Errorf("body = %q, want %q", body, tt.wantBody)
+				}
+				return
+			}
+			var problem map[string]any
+			if err := json.Unmarshal(body, &problem); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if fmt.Sprint(problem) != fmt.Sprint(tt.wantProblem) {
+				t.Errorf("problem = %v, want %v", problem, tt.wantProblem)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package apikey identifies API clients by key and the tier each key
+// belongs to: its rate limit, how many streams it may hold and which
+// features it may use. Keys live in a pluggable store.
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Features that tiers grant. Requests without a key get the anonymous
+// tier's features.
+const (
+	FeatureGIF     = "gif"
+	FeaturePNG     = "png"
+	FeatureSVG     = "svg"
+	FeatureAsync   = "async"
+	FeatureDiscord = "discord"
+)
+
+// Features lists every feature a tier may grant.
+var Features = []string{FeatureGIF, FeaturePNG, FeatureSVG, FeatureAsync, FeatureDiscord}
+
+// AnonymousTier names the tier of requests without a key.
+const AnonymousTier = "anonymous"
+
+// ErrUnknownKey is returned by Lookup for keys that aren't in the store.
+var ErrUnknownKey = errors.New("unknown API key")
+
+// Tier is what a group of keys may do.
+type Tier struct {
+	// Name identifies the tier.
+	Name string `json:"-"`
+	// RequestsPerMinute limits requests per key, or per IP for the
+	// anonymous tier; 0 is unlimited.
+	RequestsPerMinute int `json:"requests_per_minute"`
+	// MaxStreams limits the streams one key, or IP, holds at once; 0
+	// leaves only the server-wide limit.
+	MaxStreams int64 `json:"max_streams"`
+	// RequestsPerDay limits requests per key, or IP, over a rolling day; 0
+	// is unlimited.
+	RequestsPerDay int64 `json:"requests_per_day"`
+	// StreamSecondsPerDay limits seconds of streaming per key, or IP, over
+	// a rolling day; 0 is unlimited.
+	StreamSecondsPerDay int64 `json:"stream_seconds_per_day"`
+	// Features are the features the tier grants.
+	Features []string `json:"features"`
+}
+
+// Allows reports whether the tier grants feature.
+//
+// Parameters:
+//   - feature: one of the Feature constants
+//
+// Returns:
+//   - bool: true if the tier lists the feature
+func (t *Tier) Allows(feature string) bool {
+	return slices.Contains(t.Features, feature)
+}
+
+// Key is a known API key.
+type Key struct {
+	// Name identifies the key's owner in logs and rate limits; the key
+	// itself is never kept in memory.
+	Name string
+	// Tier is what the key may do.
+	Tier *Tier
+}
+
+// Store finds keys. Implementations must be safe for concurrent use.
+type Store interface {
+	// Lookup returns the key, or ErrUnknownKey.
+	Lookup(ctx context.Context, key string) (*Key, error)
+	// Anonymous returns the tier of requests without a key, or nil to use
+	// the server's default.
+	Anonymous() *Tier
+	// Close releases the store's resources.
+	Close() error
+}
+
+// Open creates the store selected by the configuration.
+//
+// Parameters:
+//   - cfg: API key settings
+//
+// Returns:
+//   - Store: the store, or nil when API keys are disabled
+//   - error: error if the store cannot be loaded
+//
+// Example:
+//
+//	keys, err := apikey.Open(cfg.Auth)
+//	if err != nil {
+//	    log.Fatalf("Failed to load API keys: %v", err)
+//	}
+func Open(cfg config.AuthConfig) (Store, error) {
+	if cfg.KeysFile == "" {
+		return nil, nil
+	}
+	return LoadFile(cfg.KeysFile)
+}
+
+// Hash returns the hex SHA-256 of key, as key files store it.
+//
+// Parameters:
+//   - key: the API key
+//
+// Returns:
+//   - string: the lowercase hex digest
+//
+// Example:
+//
+//	fmt.Println(apikey.Hash("s3cret")) // put this in the keys file
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// FileStore holds the tiers and keys of a JSON file, loaded once.
+//
+// The file names the tiers and lists the keys by their SHA-256 (see
+// Hash), so it can be checked in without leaking the keys themselves:
+//
+//	{
+//	  "tiers": {
+//	    "anonymous": {"requests_per_minute": 30, "max_streams": 1},
+//	    "pro": {"requests_per_minute": 600, "max_streams": 10, "features": ["gif", "png"]}
+//	  },
+//	  "keys": [
+//	    {"name": "acme", "sha256": "9f86d081884c7d65...", "tier": "pro"}
+//	  ]
+//	}
+//
+// Usage example:
+//
+//	store, err := LoadFile("keys.json")
+//	key, err := store.Lookup(ctx, c.Get("X-API-Key"))
+type FileStore struct {
+	anonymous *Tier
+	// keys maps the SHA-256 of each key to it
+	keys map[string]*Key
+}
+
+// keysFile is the JSON layout of a keys file.
+type keysFile struct {
+	Tiers map[string]*Tier `json:"tiers"`
+	Keys  []struct {
+		Name   string `json:"name"`
+		SHA256 string `json:"sha256"`
+		Tier   string `json:"tier"`
+	} `json:"keys"`
+}
+
+// LoadFile reads a keys file.
+//
+// Parameters:
+//   - path: the JSON file of tiers and keys
+//
+// Returns:
+//   - *FileStore: the store
+//   - error: error if the file can't be read, or names an unknown tier or
+//     feature, or lists a key twice
+func LoadFile(path string) (*FileStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+	var file keysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid keys file %s: %w", path, err)
+	}
+
+	for name, tier := range file.Tiers {
+		if tier == nil {
+			return nil, fmt.Errorf("tier %q is empty", name)
+		}
+		tier.Name = name
+		for _, f := range tier.Features {
+			if !slices.Contains(Features, f) {
+				return nil, fmt.Errorf("tier %q has unknown feature %q, want one of %s", name, f, strings.Join(Features, ", "))
+			}
+		}
+		if tier.RequestsPerMinute < 0 || tier.MaxStreams < 0 || tier.RequestsPerDay < 0 || tier.StreamSecondsPerDay < 0 {
+			return nil, fmt.Errorf("tier %q limits must not be negative", name)
+		}
+	}
+
+	store := &FileStore{anonymous: file.Tiers[AnonymousTier], keys: make(map[string]*Key, len(file.Keys))}
+	for i, k := range file.Keys {
+		tier, ok := file.Tiers[k.Tier]
+		if !ok {
+			return nil, fmt.Errorf("key %d (%s) has unknown tier %q", i+1, k.Name, k.Tier)
+		}
+		hash := strings.ToLower(k.SHA256)
+		if len(hash) != 64 || strings.Trim(hash, "0123456789abcdef") != "" {
+			return nil, fmt.Errorf("key %d (%s) needs the hex sha256 of the key", i+1, k.Name)
+		}
+		if _, ok := store.keys[hash]; ok {
+			return nil, fmt.Errorf("key %d (%s) is listed twice", i+1, k.Name)
+		}
+		store.keys[hash] = &Key{Name: k.Name, Tier: tier}
+	}
+	return store, nil
+}
+
+// Lookup returns the key, or ErrUnknownKey.
+func (s *FileStore) Lookup(_ context.Context, key string) (*Key, error) {
+	if k, ok := s.keys[Hash(key)]; ok {
+		return k, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// Anonymous returns the file's anonymous tier, or nil if it has none.
+func (s *FileStore) Anonymous() *Tier {
+	return s.anonymous
+}
+
+// Close does nothing; the keys are in memory.
+func (s *FileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// writeKeys writes a keys file and returns its path.
+func writeKeys(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	valid := `{
+		"tiers": {
+			"anonymous": {"requests_per_minute": 30},
+			"pro": {"requests_per_minute": 600, "max_streams": 5, "features": ["gif", "png"]}
+		},
+		"keys": [{"name": "acme", "sha256": "` + Hash("s3cret") + `", "tier": "pro"}]
+	}`
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: valid},
+		{name: "bad JSON", content: `{`, wantErr: "invalid keys file"},
+		{name: "unknown tier", content: `{"keys": [{"name": "a", "sha256": "` + Hash("a") + `", "tier": "gold"}]}`, wantErr: `unknown tier "gold"`},
+		{name: "unknown feature", content: `{"tiers": {"pro": {"features": ["teleport"]}}}`, wantErr: `unknown feature "teleport"`},
+		{name: "plain key", content: `{"tiers": {"pro": {}}, "keys": [{"name": "a", "sha256": "s3cret", "tier": "pro"}]}`, wantErr: "needs the hex sha256"},
+		{name: "negative limit", content: `{"tiers": {"pro": {"max_streams": -1}}}`, wantErr: "must not be negative"},
+		{name: "negative quota", content: `{"tiers": {"pro": {"requests_per_day": -1}}}`, wantErr: "must not be negative"},
+		{
+			name:    "duplicate key",
+			content: `{"tiers": {"pro": {}}, "keys": [{"name": "a", "sha256": "` + Hash("a") + `", "tier": "pro"}, {"name": "b", "sha256": "` + Hash("a") + `", "tier": "pro"}]}`,
+			wantErr: "listed twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := LoadFile(writeKeys(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+
+			key, err := store.Lookup(context.Background(), "s3cret")
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if key.Name != "acme" || key.Tier.Name != "pro" || !key.Tier.Allows(FeatureGIF) || key.Tier.Allows(FeatureAsync) {
+				t.Errorf("Lookup() = %+v, tier %+v", key, key.Tier)
+			}
+			if _, err := store.Lookup(context.Background(), "guess"); !errors.Is(err, ErrUnknownKey) {
+				t.Errorf("Lookup(guess) error = %v, want ErrUnknownKey", err)
+			}
+			if anon := store.Anonymous(); anon == nil || anon.RequestsPerMinute != 30 {
+				t.Errorf("Anonymous() = %+v", anon)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open(config.AuthConfig{})
+	if store != nil || err != nil {
+		t.Errorf("Open() without a keys file = %v, %v, want nil, nil", store, err)
+	}
+	if _, err := Open(config.AuthConfig{KeysFile: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("Open() with a missing keys file succeeded")
+	}
+}
@@ -0,0 +1,197 @@
+// Package bots classifies requests from vulnerability scanners and other
+// automated clients, so they can be turned away before anything is
+// rendered: by user agent, by probing for paths such as /wp-login.php,
+// and by request cadence. A scanner or flooding client stays classified
+// for a while, so its next requests are turned away too.
+package bots
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Class is why a client was classified as a bot, or "" for none.
+type Class string
+
+// Classes of bots.
+const (
+	// ClassScanner is a client whose user agent names a scanning tool.
+	ClassScanner Class = "scanner"
+	// ClassProbe is a request for a path only a scanner would ask for,
+	// such as /wp-login.php or /.env. Any path can be text to shout, so
+	// unlike the others it isn't remembered against the client.
+	ClassProbe Class = "probe"
+	// ClassFlood is a client that sent more requests in a second than
+	// SHOUT_BOTS_MAX_PER_SECOND.
+	ClassFlood Class = "flood"
+)
+
+// scannerAgents are lower case fragments of the user agents of scanning
+// tools. Generic HTTP libraries such as curl are not here, as people
+// script shout.sh with them.
+var scannerAgents = []string{
+	"sqlmap", "nikto", "nmap", "masscan", "zgrab", "nuclei", "gobuster",
+	"dirbuster", "dirb/", "feroxbuster", "ffuf", "wpscan", "acunetix",
+	"netsparker", "openvas", "nessus", "jorgee", "censysinspect",
+	"l9explore", "l9tcpid", "expanse",
+}
+
+// hiddenSegments are lower case first path segments naming the hidden
+// files and directories scanners try, which are never text worth
+// shouting.
+var hiddenSegments = map[string]bool{
+	".env": true, ".git": true, ".aws": true, ".ssh": true, ".svn": true,
+	".ds_store": true, ".htaccess": true, ".htpasswd": true, ".vscode": true,
+}
+
+// probeSegments are lower case first path segments scanners try. Alone
+// they are words people shout, such as /WordPress or /Solr, so they only
+// count with more path after them, as in /wordpress/wp-admin/.
+var probeSegments = map[string]bool{
+	"wordpress": true, "phpmyadmin": true, "pma": true, "cgi-bin": true,
+	"actuator": true, "boaform": true, "hnap1": true, "owa": true,
+	"autodiscover": true, "solr": true, "server-status": true,
+}
+
+// scriptExtensions are lower case extensions of server-side scripts,
+// which shout.sh never serves.
+var scriptExtensions = []string{".php", ".asp", ".aspx", ".jsp", ".cgi"}
+
+// configExtensions are lower case extensions of config files and dumps.
+// /config.yml is text, so like probeSegments they only count in a path
+// of more than one segment.
+var configExtensions = []string{".sql", ".bak", ".ini", ".yml"}
+
+// sweepInterval is how often forgotten clients are dropped.
+const sweepInterval = time.Minute
+
+// client is what the detector remembers of one IP.
+type client struct {
+	// second is the Unix second count is for
+	second int64
+	count  int
+	class  Class
+	until  time.Time
+}
+
+// Detector classifies requests. It is safe for concurrent use.
+//
+// Usage example:
+//
+//	detector := bots.New(cfg.Bots)
+//	if class := detector.Classify(c.IP(), c.Get("User-Agent"), c.Path(), true); class != "" {
+//	    // turn the request away
+//	}
+type Detector struct {
+	maxPerSecond int
+	remember     time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+// New creates the detector configured in cfg.
+//
+// Parameters:
+//   - cfg: whether to detect bots, the cadence limit and how long a
+//     classification lasts
+//
+// Returns:
+//   - *Detector: the detector, or nil when detection is off
+func New(cfg config.BotsConfig) *Detector {
+	if !cfg.Detect {
+		return nil
+	}
+	return &Detector{
+		maxPerSecond: cfg.MaxPerSecond,
+		remember:     cfg.Remember,
+		now:          time.Now,
+		clients:      make(map[string]*client),
+	}
+}
+
+// Classify classifies a request from ip. A client classified as a scanner
+// or flood within the last SHOUT_BOTS_REMEMBER keeps its class; probes
+// are not remembered.
+//
+// Parameters:
+//   - ip: the client IP
+//   - userAgent: the User-Agent header
+//   - urlPath: the request path
+//   - cadence: whether the request counts toward the cadence limit;
+//     requests with an API key don't
+//
+// Returns:
+//   - Class: why the client is a bot, or "" if it doesn't look like one
+func (d *Detector) Classify(ip, userAgent, urlPath string, cadence bool) Class {
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep(now)
+
+	c := d.clients[ip]
+	if c == nil {
+		c = &client{}
+		d.clients[ip] = c
+	}
+	if c.class != "" && now.Before(c.until) {
+		return c.class
+	}
+	c.class = ""
+
+	class := classify(userAgent, urlPath)
+	if class == "" && cadence && d.maxPerSecond > 0 {
+		if second := now.Unix(); second != c.second {
+			c.second, c.count = second, 0
+		}
+		if c.count++; c.count > d.maxPerSecond {
+			class = ClassFlood
+		}
+	}
+	if class != "" && class != ClassProbe {
+		c.class, c.until = class, now.Add(d.remember)
+	}
+	return class
+}
+
+// sweep drops the clients that are neither classified nor counted in the
+// current second, at most every sweepInterval.
+func (d *Detector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < sweepInterval {
+		return
+	}
+	d.lastSweep = now
+	for ip, c := range d.clients {
+		if now.After(c.until) && c.second < now.Unix() {
+			delete(d.clients, ip)
+		}
+	}
+}
+
+// classify classifies a request by its user agent and path alone.
+func classify(userAgent, urlPath string) Class {
+	agent := strings.ToLower(userAgent)
+	for _, fragment := range scannerAgents {
+		if strings.Contains(agent, fragment) {
+			return ClassScanner
+		}
+	}
+	p := strings.ToLower(urlPath)
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	ext := path.Ext(p)
+	if hiddenSegments[segment] || slices.Contains(scriptExtensions, ext) {
+		return ClassProbe
+	}
+	// Single words could be text; scanners go deeper
+	if rest != "" && (probeSegments[segment] || strings.HasPrefix(segment, "wp-") || slices.Contains(configExtensions, ext)) {
+		return ClassProbe
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package bots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      Class
+	}{
+		{name: "curl", userAgent: "curl/8.5.0", path: "/HELLO", want: ""},
+		{name: "browser", userAgent: "Mozilla/5.0 (X11; Linux x86_64)", path: "/HELLO%20WORLD", want: ""},
+		{name: "scanner agent", userAgent: "sqlmap/1.7#stable (https://sqlmap.org)", path: "/", want: ClassScanner},
+		{name: "nuclei", userAgent: "Mozilla/5.0 Nuclei - Open-source project", path: "/", want: ClassScanner},
+		{name: "wordpress login", userAgent: "Mozilla/5.0", path: "/wp-login.php", want: ClassProbe},
+		{name: "wordpress content", userAgent: "Mozilla/5.0", path: "/WP-CONTENT/plugins/x/readme.txt", want: ClassProbe},
+		{name: "dotenv", userAgent: "Mozilla/5.0", path: "/.env", want: ClassProbe},
+		{name: "git config", userAgent: "Mozilla/5.0", path: "/.git/config", want: ClassProbe},
+		{name: "php script", userAgent: "Mozilla/5.0", path: "/admin/config.php", want: ClassProbe},
+		{name: "wordpress directory", userAgent: "Mozilla/5.0", path: "/wordpress/wp-admin/", want: ClassProbe},
+		{name: "config file", userAgent: "Mozilla/5.0", path: "/app/config.yml", want: ClassProbe},
+		{name: "text like a probe segment", userAgent: "curl/8.5.0", path: "/PMAIL", want: ""},
+		{name: "text naming a probe segment", userAgent: "curl/8.5.0", path: "/WordPress", want: ""},
+		{name: "text naming solr", userAgent: "curl/8.5.0", path: "/Solr", want: ""},
+		{name: "text naming owa", userAgent: "curl/8.5.0", path: "/OWA", want: ""},
+		{name: "text starting wp-", userAgent: "curl/8.5.0", path: "/wp-cli", want: ""},
+		{name: "text like a config file", userAgent: "curl/8.5.0", path: "/config.yml", want: ""},
+		{name: "text with a dot", userAgent: "curl/8.5.0", path: "/HELLO.WORLD", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classify(tt.userAgent, tt.path); got != tt.want {
+				t.Errorf("classify(%q, %q) = %q, want %q", tt.userAgent, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetector(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	d := New(config.BotsConfig{Detect: true, MaxPerSecond: 3, Remember: time.Minute})
+	d.now = func() time.Time { return now }
+
+	// A probe turns away only itself
+	if got := d.Classify("203.0.113.7", "curl/8.5.0", "/.env", true); got != ClassProbe {
+		t.Fatalf("probe classified %q, want %q", got, ClassProbe)
+	}
+	if got := d.Classify("203.0.113.7", "curl/8.5.0", "/HELLO", true); got != "" {
+		t.Errorf("request after a probe classified %q, want none", got)
+	}
+
+	// A scanner marks the client, whose next requests are bots too
+	if got := d.Classify("203.0.113.9", "nikto/2.5", "/", true); got != ClassScanner {
+		t.Fatalf("scanner classified %q, want %q", got, ClassScanner)
+	}
+	if got := d.Classify("203.0.113.9", "curl/8.5.0", "/HELLO", true); got != ClassScanner {
+		t.Errorf("request after a scan classified %q, want %q", got, ClassScanner)
+	}
+	now = now.Add(2 * time.Minute)
+	if got := d.Classify("203.0.113.9", "curl/8.5.0", "/HELLO", true); got != "" {
+		t.Errorf("request after the classification expired classified %q, want none", got)
+	}
+
+	// More than three requests in a second is a flood, unless they don't
+	// count toward the cadence
+	for i := range 3 {
+		if got := d.Classify("198.51.100.7", "curl/8.5.0", "/HI", true); got != "" {
+			t.Fatalf("request %d classified %q, want none", i+1, got)
+		}
+	}
+	if got := d.Classify("198.51.100.8", "curl/8.5.0", "/HI", false); got != "" {
+		t.Errorf("request outside the cadence classified %q, want none", got)
+	}
+	if got := d.Classify("198.51.100.7", "curl/8.5.0", "/HI", true); got != ClassFlood {
+		t.Errorf("fourth request in a second classified %q, want %q", got, ClassFlood)
+	}
+
+	// Forgotten clients are swept
+	now = now.Add(time.Hour)
+	d.Classify("192.0.2.1", "curl/8.5.0", "/HI", true)
+	if len(d.clients) != 1 {
+		t.Errorf("detector remembers %d clients after a sweep, want 1", len(d.clients))
+	}
+
+	if New(config.BotsConfig{}) != nil {
+		t.Error("New() with detection off returned a detector")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// completionValues are the values offered after flags that take one of a
+// fixed set; -f completes with the installed fonts.
+var completionValues = map[string][]string{
+	"cmode":      {"char", "word", "line"},
+	"gdir":       {"h", "v", "d1", "d2", "radial"},
+	"align":      {"left", "center", "right"},
+	"border":     {"single", "double", "rounded", "none"},
+	"anim":       {"colors", "typewriter", "scroll"},
+	"format":     {"stream", "cast", "gif"},
+	"screen":     {"alt"},
+	"term":       {"truecolor", "xterm-256color", "xterm", "dumb"},
+	"completion": {"bash", "zsh", "fish"},
+}
+
+// completionData fills the completion templates.
+type completionData struct {
+	// Flags are all flag names, without dashes
+	Flags []string
+	// Values maps flags to the values they complete with
+	Values map[string][]string
+	// Aliases maps short flag names to their long names
+	Aliases map[string]string
+}
+
+// bashCompletion also serves zsh, through bashcompinit.
+var bashCompletion = template.Must(template.New("bash").Funcs(template.FuncMap{"join": strings.Join}).Parse(`# shout completion; load with: source <(shout -completion bash)
+_shout() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	case ${prev#-} in
+	-f | f | -font | font)
+		COMPREPLY=($(compgen -W "$(shout -list-fonts 2>/dev/null) random" -- "$cur"))
+		return ;;
+{{- range $flag, $values := .Values}}
+	-{{$flag}} | {{$flag}}{{range $short, $long := $.Aliases}}{{if eq $long $flag}} | -{{$short}} | {{$short}}{{end}}{{end}})
+		COMPREPLY=($(compgen -W "{{join $values " "}}" -- "$cur"))
+		return ;;
+{{- end}}
+	esac
+	if [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "{{range .Flags}}-{{.}} {{end}}" -- "$cur"))
+	fi
+}
+complete -F _shout shout
+`))
+
+var fishCompletion = template.Must(template.New("fish").Funcs(template.FuncMap{"join": strings.Join}).Parse(`# shout completion; load with: shout -completion fish | source
+{{- range .Flags}}
+complete -c shout -o {{.}}
+{{- end}}
+complete -c shout -o f -o font -x -a "(shout -list-fonts) random"
+{{- range $flag, $values := .Values}}
+complete -c shout -o {{$flag}}{{range $short, $long := $.Aliases}}{{if eq $long $flag}} -o {{$short}}{{end}}{{end}} -x -a "{{join $values " "}}"
+{{- end}}
+`))
+
+// writeCompletion prints the completion script for shell.
+func writeCompletion(w io.Writer, shell string) error {
+	var opts options
+	fs := newFlagSet(&opts, io.Discard)
+	data := completionData{
+		Values:  map[string][]string{"color": render.PresetNames()},
+		Aliases: map[string]string{},
+	}
+	for name, values := range completionValues {
+		data.Values[name] = values
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		data.Flags = append(data.Flags, f.Name)
+	})
+	// Flags sharing a variable are aliases; the longest name is the main one
+	fs.VisitAll(func(short *flag.Flag) {
+		fs.VisitAll(func(long *flag.Flag) {
+			if short.Usage == long.Usage && len(short.Name) < len(long.Name) {
+				data.Aliases[short.Name] = long.Name
+			}
+		})
+	})
+
+	switch shell {
+	case "bash":
+		return bashCompletion.Execute(w, data)
+	case "zsh":
+		if _, err := io.WriteString(w, "autoload -U +X bashcompinit && bashcompinit\n"); err != nil {
+			return err
+		}
+		return bashCompletion.Execute(w, data)
+	case "fish":
+		return fishCompletion.Execute(w, data)
+	}
+	return fmt.Errorf("no completion for %q; use bash, zsh or fish", shell)
+}
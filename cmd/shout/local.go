@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// randomFont is the font name that picks one of the loaded fonts.
+const randomFont = "random"
+
+// renderLocal draws text as the server's static endpoint would, with the
+// fonts on this machine: wrapped to the terminal, or -mw, aligned within
+// it and colored for it.
+func renderLocal(opts options, text string, w io.Writer) error {
+	if opts.party || opts.anim != "" || opts.format != "" || opts.screen != "" {
+		return errors.New("animations and stream formats need a server; pass -server or set SHOUT_URL")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fonts, err := loadFonts(cfg.Fonts)
+	if err != nil {
+		return err
+	}
+
+	cols := opts.cols
+	if opts.MaxWidth > 0 {
+		cols = opts.MaxWidth
+	}
+	align := opts.Align
+	if align == "" {
+		align = cfg.Text.DefaultAlign
+	}
+	output, err := shout.New(fonts).Render(context.Background(), render.SanitizeText(text), shout.Options{
+		Font:        pickFont(opts, cfg.Fonts.Default, fonts.ListFonts()),
+		Color:       opts.Color,
+		ColorMode:   opts.ColorMode,
+		GradientDir: opts.GradientDir,
+		Align:       align,
+		Border:      opts.Border,
+		Cols:        cols,
+		Colors:      render.TermColorDepth(opts.term),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
+
+// loadFonts loads the configured fonts and every font bundled in the
+// binary, so the CLI works from any directory. Remote fonts are left to
+// the server.
+func loadFonts(cfg config.FontConfig) (*render.FontCache, error) {
+	embedded, err := fs.Glob(render.EmbeddedFonts, "*.flf")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range embedded {
+		if name = strings.TrimSuffix(name, ".flf"); !slices.Contains(cfg.Allowed, name) {
+			cfg.Allowed = append(cfg.Allowed, name)
+		}
+	}
+	cfg.Embedded, cfg.Remote = true, nil
+
+	fonts := render.NewFontCache()
+	if err := fonts.LoadFonts(cfg); err != nil {
+		return nil, err
+	}
+	return fonts, nil
+}
+
+// pickFont returns the font to draw with: the -f font, or the configured
+// default. -f random picks one of fonts, the same one each time for a
+// given -seed.
+func pickFont(opts options, defaultFont string, fonts []string) string {
+	switch {
+	case opts.Font == "":
+		return defaultFont
+	case opts.Font != randomFont || len(fonts) == 0:
+		return opts.Font
+	}
+	// The server's seeding, so a seed picks the same font here when the
+	// same fonts are loaded
+	seed, ok := shout.ParseSeed(opts.seed)
+	if !ok {
+		seed = rand.Uint64()
+	}
+	return fonts[shout.NewRand(seed).IntN(len(fonts))]
+}
+
+// listFonts prints the fonts available for local rendering, one per line.
+func listFonts(w io.Writer) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	fonts, err := loadFonts(cfg.Fonts)
+	if err != nil {
+		return err
+	}
+	for _, name := range fonts.ListFonts() {
+		fmt.Fprintln(w, name)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Command shout prints ASCII art banners from the command line. It renders
+// locally with the fonts bundled in the binary, or, with -server, asks a
+// shout.sh server, which also unlocks the animated modes. The text is the
+// arguments, or standard input when it's piped.
+//
+// Usage:
+//
+//	shout [flags] TEXT...
+//	echo HELLO | shout -f doom -c rainbow
+//	shout -server https://shout.sh -p -anim scroll DEPLOYED
+//
+// The flags mirror the server's query parameters; shout -h lists them.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// options are the parsed command line flags.
+type options struct {
+	types.RenderOptions
+
+	// Remote only: streaming and output options
+	party  bool
+	anim   string
+	loops  int
+	fps    int
+	format string
+	screen string
+
+	// The terminal, detected unless given
+	cols, rows int
+	term       string
+
+	seed   string
+	server string
+
+	completion string
+	listFonts  bool
+}
+
+// Errors for which the flag package has already printed the usage.
+var (
+	errUsage = errors.New("usage")
+	errHelp  = errors.New("help requested")
+)
+
+func main() {
+	// Font loading logs every font; the CLI only wants its output
+	log.SetOutput(io.Discard)
+
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	switch {
+	case err == nil, errors.Is(err, errHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "shout:", err)
+		os.Exit(1)
+	}
+}
+
+// run is the CLI with its inputs and outputs passed in.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	opts, text, err := parseArgs(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case opts.completion != "":
+		return writeCompletion(stdout, opts.completion)
+	case opts.listFonts:
+		return listFonts(stdout)
+	}
+
+	if text == "" {
+		if isTerminal(stdin) {
+			return fmt.Errorf("no text given; run shout -h for usage")
+		}
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("reading standard input: %w", err)
+		}
+		text = strings.TrimRight(string(data), "\n")
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("no text given")
+	}
+
+	detectTerminal(&opts, stdout)
+	if opts.server != "" {
+		return renderRemote(opts, text, stdout)
+	}
+	return renderLocal(opts, text, stdout)
+}
+
+// parseArgs parses the flags and joins the remaining arguments into the
+// text.
+func parseArgs(args []string, stderr io.Writer) (options, string, error) {
+	var opts options
+	fs := newFlagSet(&opts, stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return opts, "", errHelp
+		}
+		return opts, "", errUsage
+	}
+	return opts, strings.Join(fs.Args(), " "), nil
+}
+
+// newFlagSet returns the command line flags, parsed into opts. Each option
+// has the same names as the matching query parameter.
+func newFlagSet(opts *options, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("shout", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: shout [flags] TEXT...")
+		fmt.Fprintln(stderr, "Prints TEXT, or standard input, as an ASCII art banner.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	str := func(p *string, names []string, value, usage string) {
+		for _, name := range names {
+			fs.StringVar(p, name, value, usage)
+		}
+	}
+	num := func(p *int, names []string, usage string) {
+		for _, name := range names {
+			fs.IntVar(p, name, 0, usage)
+		}
+	}
+	str(&opts.Font, []string{"f", "font"}, "", "font name, or random")
+	str(&opts.Color, []string{"c", "color"}, "", "color preset, #RRGGBB, rgb(r,g,b), color name, or a comma-separated list")
+	str(&opts.ColorMode, []string{"cmode"}, "", "how a color list cycles: char, word or line")
+	str(&opts.GradientDir, []string{"gdir"}, "", "direction of a gradient: h, v, d1, d2 or radial")
+	num(&opts.MaxWidth, []string{"mw", "maxwidth"}, "wrap and align within this many columns instead of the terminal width")
+	str(&opts.Align, []string{"a", "align"}, "", "alignment within the terminal: left, center or right")
+	str(&opts.Border, []string{"b", "border"}, "", "border style: single, double, rounded or none")
+	num(&opts.Timeout, []string{"t", "timeout"}, "animation timeout in seconds (with -server)")
+	num(&opts.Speed, []string{"s", "speed"}, "animation speed, 1-10 (with -server)")
+	num(&opts.fps, []string{"fps"}, "animation frames per second (with -server)")
+	fs.BoolVar(&opts.party, "p", false, "stream animated party mode (with -server)")
+	fs.BoolVar(&opts.party, "party", false, "stream animated party mode (with -server)")
+	str(&opts.anim, []string{"anim"}, "", "party animation such as typewriter or scroll+rainbow (with -server)")
+	num(&opts.loops, []string{"loops"}, "passes of a finite animation (with -server)")
+	str(&opts.format, []string{"format"}, "", "stream format: stream, cast or gif (with -server)")
+	str(&opts.screen, []string{"screen"}, "", "alt plays streams on the alternate screen (with -server)")
+	num(&opts.cols, []string{"cols"}, "terminal width (default: detected)")
+	num(&opts.rows, []string{"rows"}, "terminal height (default: detected)")
+	str(&opts.term, []string{"term"}, "", "terminal type, as in $TERM (default: $COLORTERM or $TERM)")
+	str(&opts.seed, []string{"seed"}, "", "seed for random choices such as -f random")
+	str(&opts.server, []string{"server"}, os.Getenv("SHOUT_URL"), "shout.sh server to render with, such as https://shout.sh (default: $SHOUT_URL, or render locally)")
+	str(&opts.completion, []string{"completion"}, "", "print a completion script for bash, zsh or fish")
+	fs.BoolVar(&opts.listFonts, "list-fonts", false, "list the fonts available locally")
+	return fs
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// cleanEnv clears the environment the CLI reads, so tests don't depend on
+// the terminal they run in.
+func cleanEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"SHOUT_URL", "COLUMNS", "LINES", "TERM", "COLORTERM", "NO_COLOR"} {
+		t.Setenv(name, "")
+	}
+}
+
+// runCLI runs the CLI with args and stdin, returning its output.
+func runCLI(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+// renderSmall renders text in the small font, as the CLI should locally.
+func renderSmall(t *testing.T, text string) string {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fonts, err := loadFonts(cfg.Fonts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := render.GenerateASCII(text, types.RenderOptions{Font: "small"}, fonts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestLocal(t *testing.T) {
+	cleanEnv(t)
+	want := renderSmall(t, "HELLO WORLD")
+
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+	}{
+		{name: "arguments", args: []string{"-f", "small", "-term", "dumb", "HELLO", "WORLD"}},
+		{name: "long flags", args: []string{"-font=small", "--term=dumb", "HELLO WORLD"}},
+		{name: "stdin", stdin: "HELLO WORLD\n", args: []string{"-f", "small", "-term", "dumb"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCLI(t, tt.stdin, tt.args...)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("run() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestLocalTerminal(t *testing.T) {
+	cleanEnv(t)
+	t.Setenv("COLUMNS", "40")
+
+	got, err := runCLI(t, "", "-f", "small", "-c", "fire", "-a", "left", "HELLO WORLD")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	// No $TERM means no reduction: 24-bit colors, wrapped to $COLUMNS
+	if !strings.Contains(got, "\x1b[38;2;") {
+		t.Error("output has no 24-bit colors")
+	}
+	plain := render.ReduceColors(got, render.NoColor)
+	if want := renderSmall(t, "HELLO\nWORLD"); plain != want {
+		t.Errorf("run() with COLUMNS=40 =\n%s\nwant\n%s", plain, want)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	got, err = runCLI(t, "", "-f", "small", "-c", "fire", "HI")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Error("NO_COLOR output has escape sequences")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cleanEnv(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no text", args: []string{"-f", "small"}, want: "no text"},
+		{name: "party without server", args: []string{"-p", "HI"}, want: "need a server"},
+		{name: "anim without server", args: []string{"-anim", "scroll", "HI"}, want: "need a server"},
+		{name: "bad color", args: []string{"-c", "nope", "HI"}, want: "nope"},
+		{name: "unknown shell", args: []string{"-completion", "tcsh"}, want: "tcsh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCLI(t, "", tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("run() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := runCLI(t, "", "-nope"); err != errUsage {
+		t.Errorf("unknown flag: error = %v, want errUsage", err)
+	}
+	if _, err := runCLI(t, "", "-h"); err != errHelp {
+		t.Errorf("-h: error = %v, want errHelp", err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	cleanEnv(t)
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if strings.Contains(r.URL.Path, "FAIL") {
+			http.Error(w, "unknown font", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, "banner\n")
+	}))
+	defer server.Close()
+
+	t.Setenv("SHOUT_URL", server.URL)
+	out, err := runCLI(t, "HELLO WORLD\n", "-p", "-anim", "scroll", "-t", "5", "-cols", "80", "-term", "xterm")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if out != "banner\n" {
+		t.Errorf("run() = %q, want the response body", out)
+	}
+	if got.URL.Path != "/p/HELLO WORLD" {
+		t.Errorf("path = %q, want /p/HELLO WORLD", got.URL.Path)
+	}
+	want := url.Values{"anim": {"scroll"}, "timeout": {"5"}, "cols": {"80"}, "term": {"xterm"}}
+	if q := got.URL.Query(); q.Encode() != want.Encode() {
+		t.Errorf("query = %v, want %v", q, want)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != userAgent {
+		t.Errorf("User-Agent = %q, want %q", ua, userAgent)
+	}
+
+	_, err = runCLI(t, "", "-server", server.URL, "FAIL")
+	if err == nil || !strings.Contains(err.Error(), "unknown font") {
+		t.Errorf("run() error = %v, want the server's error", err)
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts options
+		text string
+		want string
+	}{
+		{name: "static", opts: options{server: "https://shout.sh"}, text: "HI", want: "https://shout.sh/HI"},
+		{name: "no scheme", opts: options{server: "shout.sh/"}, text: "HI", want: "https://shout.sh/HI"},
+		{name: "party", opts: options{server: "http://localhost:8080", party: true}, text: "HI", want: "http://localhost:8080/p/HI"},
+		{name: "escaped", opts: options{server: "shout.sh"}, text: "a/b?", want: "https://shout.sh/a%2Fb%3F"},
+		{
+			name: "options",
+			opts: options{server: "shout.sh", RenderOptions: types.RenderOptions{Font: "doom", MaxWidth: 40}, seed: "7"},
+			text: "HI",
+			want: "https://shout.sh/HI?font=doom&maxwidth=40&seed=7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := remoteURL(tt.opts, tt.text); got != tt.want {
+				t.Errorf("remoteURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeCompletion(&out, shell); err != nil {
+				t.Fatalf("writeCompletion() error = %v", err)
+			}
+			for _, want := range []string{"shout -list-fonts", "maxwidth", "rainbow", "typewriter"} {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("%s completion has no %q", shell, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPickFont(t *testing.T) {
+	fonts := []string{"doom", "small", "standard"}
+
+	if got := pickFont(options{}, "standard", fonts); got != "standard" {
+		t.Errorf("no font: got %q, want the default", got)
+	}
+	if got := pickFont(options{RenderOptions: types.RenderOptions{Font: "doom"}}, "standard", fonts); got != "doom" {
+		t.Errorf("-f doom: got %q", got)
+	}
+	seeded := options{RenderOptions: types.RenderOptions{Font: randomFont}, seed: "42"}
+	first := pickFont(seeded, "standard", fonts)
+	for range 10 {
+		if got := pickFont(seeded, "standard", fonts); got != first {
+			t.Fatalf("-seed 42 picked %q and %q", first, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+)
+
+// Sequences that put the terminal back after an interrupted stream, which
+// never receives the server's own closing sequence.
+const (
+	restoreTerminal = "\x1b[0m\x1b[?25h"
+	leaveAltScreen  = "\x1b[?1049l"
+)
+
+// userAgent identifies the CLI to servers.
+const userAgent = "shout-cli"
+
+// renderRemote asks the server to render text and copies the response to
+// w as it arrives, so animations play while they stream. Ctrl-C ends a
+// stream and restores the terminal.
+func renderRemote(opts options, text string, w io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, remoteURL(opts, text), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	_, err = io.Copy(w, resp.Body)
+	if ctx.Err() != nil {
+		restore := restoreTerminal
+		if opts.screen == "alt" {
+			restore += leaveAltScreen
+		}
+		_, err = io.WriteString(w, restore)
+	}
+	return err
+}
+
+// remoteURL returns the server URL that renders text with opts: the static
+// route, or the party route with -p, and a query parameter for each option
+// given. A server without a scheme is assumed to use HTTPS.
+func remoteURL(opts options, text string) string {
+	server := strings.TrimRight(opts.server, "/")
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	path := "/"
+	if opts.party {
+		path = "/p/"
+	}
+
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"font":   opts.Font,
+		"color":  opts.Color,
+		"cmode":  opts.ColorMode,
+		"gdir":   opts.GradientDir,
+		"align":  opts.Align,
+		"border": opts.Border,
+		"anim":   opts.anim,
+		"format": opts.format,
+		"screen": opts.screen,
+		"term":   opts.term,
+		"seed":   opts.seed,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	for name, value := range map[string]int{
+		"maxwidth": opts.MaxWidth,
+		"timeout":  opts.Timeout,
+		"speed":    opts.Speed,
+		"fps":      opts.fps,
+		"loops":    opts.loops,
+		"cols":     opts.cols,
+		"rows":     opts.rows,
+	} {
+		if value != 0 {
+			query.Set(name, strconv.Itoa(value))
+		}
+	}
+
+	u := server + path + url.PathEscape(text)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
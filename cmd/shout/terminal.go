@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// isTerminal reports whether f is a file connected to an interactive
+// terminal rather than a pipe or a regular file.
+func isTerminal(f any) bool {
+	file, ok := f.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// detectTerminal fills in the terminal size and type options that weren't
+// given: the size from the terminal stdout is written to, or $COLUMNS and
+// $LINES, and the type from $COLORTERM or $TERM. NO_COLOR turns colors off.
+func detectTerminal(opts *options, stdout io.Writer) {
+	cols, rows := 0, 0
+	if f, ok := stdout.(*os.File); ok && isTerminal(f) {
+		cols, rows, _ = windowSize(f)
+	}
+	if cols == 0 {
+		cols, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	}
+	if rows == 0 {
+		rows, _ = strconv.Atoi(os.Getenv("LINES"))
+	}
+	if opts.cols == 0 {
+		opts.cols = cols
+	}
+	if opts.rows == 0 {
+		opts.rows = rows
+	}
+
+	if opts.term != "" {
+		return
+	}
+	switch colorterm := os.Getenv("COLORTERM"); {
+	case os.Getenv("NO_COLOR") != "":
+		opts.term = "dumb"
+	case colorterm == "truecolor" || colorterm == "24bit":
+		opts.term = "truecolor"
+	default:
+		opts.term = os.Getenv("TERM")
+	}
+}
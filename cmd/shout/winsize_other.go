@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// windowSize reports no size: the window size isn't read on this
+// platform, so $COLUMNS and $LINES or -cols and -rows are used instead.
+func windowSize(*os.File) (cols, rows int, ok bool) {
+	return 0, 0, false
+}
@@ -1,66 +1,604 @@
 package config
 
 import (
+	"cmp"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
+	"github.com/ryanlewis/shout-sh/types"
 )
 
 var (
 	once     sync.Once
 	instance *Config
 	loadErr  error
+	// fromDotenv holds the variables the .env file set at Load, which
+	// Reload reads from the file again
+	fromDotenv map[string]bool
 )
 
+// redacted replaces secrets in Settings.
+const redacted = "[redacted]"
+
+// maxFooter caps the length of SHOUT_TEXT_FOOTER, in characters.
+const maxFooter = 200
+
+// presetName matches the names of SHOUT_TEXT_PRESETS.
+var presetName = regexp.MustCompile(`^[a-z0-9-]+$`)
+
 // Config represents the complete application configuration.
 // All settings use environment variables with SHOUT_ prefix.
 // Default values are specified as struct tags.
 type Config struct {
-	Version string `env:"SHOUT_VERSION" envDefault:"dev"`
+	Version string `env:"SHOUT_VERSION" envDefault:"dev" desc:"Version in logs, error reports and ETags"`
 
 	Server    ServerConfig    `envPrefix:"SHOUT_SERVER_"`
 	RateLimit RateLimitConfig `envPrefix:"SHOUT_RATELIMIT_"`
 	Fonts     FontConfig      `envPrefix:"SHOUT_FONTS_"`
 	Streaming StreamingConfig `envPrefix:"SHOUT_STREAMING_"`
 	Text      TextConfig      `envPrefix:"SHOUT_TEXT_"`
+
+	Compression CompressionConfig `envPrefix:"SHOUT_COMPRESSION_"`
+	CORS        CORSConfig        `envPrefix:"SHOUT_CORS_"`
+	Security    SecurityConfig    `envPrefix:"SHOUT_SECURITY_"`
+	Shortlinks  ShortlinkConfig   `envPrefix:"SHOUT_SHORTLINK_"`
+	Fortune     FortuneConfig     `envPrefix:"SHOUT_FORTUNE_"`
+	ANSI        ANSIConfig        `envPrefix:"SHOUT_ANSI_"`
+	GIF         GIFConfig         `envPrefix:"SHOUT_GIF_"`
+	Terminal    TerminalConfig    `envPrefix:"SHOUT_TERMINAL_"`
+	GRPC        GRPCConfig        `envPrefix:"SHOUT_GRPC_"`
+	Gopher      GopherConfig      `envPrefix:"SHOUT_GOPHER_"`
+	Discord     DiscordConfig     `envPrefix:"SHOUT_DISCORD_"`
+	Async       AsyncConfig       `envPrefix:"SHOUT_ASYNC_"`
+	Auth        AuthConfig        `envPrefix:"SHOUT_AUTH_"`
+	KeyPresets  KeyPresetsConfig  `envPrefix:"SHOUT_KEY_PRESETS_"`
+	Quota       QuotaConfig       `envPrefix:"SHOUT_QUOTA_"`
+	Access      AccessConfig      `envPrefix:"SHOUT_ACCESS_"`
+	Signing     SigningConfig     `envPrefix:"SHOUT_SIGNING_"`
+	Log         LogConfig         `envPrefix:"SHOUT_LOG_"`
+	GeoIP       GeoIPConfig       `envPrefix:"SHOUT_GEOIP_"`
+	Bots        BotsConfig        `envPrefix:"SHOUT_BOTS_"`
+	Tarpit      TarpitConfig      `envPrefix:"SHOUT_TARPIT_"`
+	Errors      ErrorsConfig      `envPrefix:"SHOUT_ERRORS_"`
+	Analytics   AnalyticsConfig   `envPrefix:"SHOUT_ANALYTICS_"`
+	TLS         TLSConfig         `envPrefix:"SHOUT_TLS_"`
+	VHosts      VHostsConfig      `envPrefix:"SHOUT_VHOSTS_"`
+	Features    FeaturesConfig    `envPrefix:"SHOUT_FEATURES_"`
+	Remote      RemoteConfig      `envPrefix:"SHOUT_REMOTE_"`
+	Cache       CacheConfig       `envPrefix:"SHOUT_CACHE_"`
 }
 
 // ServerConfig contains HTTP server settings
 type ServerConfig struct {
-	PublicPort int    `env:"PUBLIC_PORT" envDefault:"8080"`
-	AdminPort  int    `env:"ADMIN_PORT" envDefault:"9090"`
-	Host       string `env:"HOST" envDefault:"0.0.0.0"`
+	PublicPort int    `env:"PUBLIC_PORT" envDefault:"8080" desc:"Public API port"`
+	AdminPort  int    `env:"ADMIN_PORT" envDefault:"9090" desc:"Admin endpoints port, for internal use only"`
+	Host       string `env:"HOST" envDefault:"0.0.0.0" desc:"Address every port listens on"`
+	// StaticTimeoutMS bounds how long a static render may take; 0 disables it
+	StaticTimeoutMS int `env:"STATIC_TIMEOUT_MS" envDefault:"2000" desc:"Time budget for a static render before responding 503"`
+	// CacheControl is sent with static renders; empty omits the header
+	CacheControl string `env:"CACHE_CONTROL" envDefault:"public, max-age=86400" desc:"Cache-Control header of static renders; empty omits it"`
+	// ReadTimeout bounds reading a request, headers and body, so slow
+	// clients can't hold connections open
+	ReadTimeout time.Duration `env:"READ_TIMEOUT" envDefault:"10s" desc:"Longest a client may take to send a request, so slow clients can't hold connections open"`
+	// WriteTimeout bounds writing a response, apart from streams
+	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" envDefault:"15s" desc:"Longest a client may take to read a response, other than a stream"`
+	// IdleTimeout bounds how long a keep-alive connection waits for its
+	// next request
+	IdleTimeout time.Duration `env:"IDLE_TIMEOUT" envDefault:"60s" desc:"How long a keep-alive connection waits for its next request"`
+	// StreamWriteTimeout bounds writing each frame of a stream instead, so
+	// streams run as long as the client keeps reading; 0 for no limit
+	StreamWriteTimeout time.Duration `env:"STREAM_WRITE_TIMEOUT" envDefault:"30s" desc:"Longest a client may take to read each frame of a stream, 0 for no limit"`
+	// MaxURLBytes caps the request target, path and query; longer get 414
+	MaxURLBytes int `env:"MAX_URL_BYTES" envDefault:"4096" desc:"Longest URL, path and query, accepted before requests get 414"`
+	// MaxHeaderBytes caps the request line and headers; larger get 431
+	MaxHeaderBytes int `env:"MAX_HEADER_BYTES" envDefault:"8192" desc:"Largest request line and headers accepted before requests get 431; at least the URL limit"`
+	// MaxBodyBytes caps request bodies on every route; larger get 413
+	MaxBodyBytes int `env:"MAX_BODY_BYTES" envDefault:"1048576" desc:"Largest request body on any route before requests get 413"`
 }
 
 // RateLimitConfig contains rate limiting settings
 type RateLimitConfig struct {
-	RequestsPerMinute int `env:"REQUESTS_PER_MINUTE" envDefault:"100"`
-	Burst             int `env:"BURST" envDefault:"10"`
+	RequestsPerMinute int `env:"REQUESTS_PER_MINUTE" envDefault:"100" desc:"Requests per minute per client"`
+	Burst             int `env:"BURST" envDefault:"10" desc:"Requests a client may make at once on top of the rate"`
+	// Routes gives route classes their own requests per minute per
+	// client, as "class=N", on top of RequestsPerMinute
+	Routes []string `env:"ROUTES" desc:"Requests per minute per client for route classes, as class=N"`
+	// ExemptIPs are IPs and CIDR ranges, such as internal callers, that
+	// no rate limit or quota applies to
+	ExemptIPs []string `env:"EXEMPT_IPS" desc:"IPs and CIDR ranges that skip rate limits and quotas"`
+	// ExemptKeys are names of API keys no rate limit or quota applies to
+	ExemptKeys []string `env:"EXEMPT_KEYS" desc:"API key names that skip rate limits and quotas"`
+	// ExemptAgents are User-Agent prefixes of health checks and
+	// monitoring probes, such as kube-probe, that no rate limit or quota
+	// applies to
+	ExemptAgents []string `env:"EXEMPT_AGENTS" desc:"User-Agent prefixes that skip rate limits and quotas"`
 }
 
+// Route classes of SHOUT_RATELIMIT_ROUTES, grouping routes by how much
+// they cost to serve.
+const (
+	// RouteRender is static banners, badges, clocks, fortunes, composed
+	// documents and shortlink renders
+	RouteRender = "render"
+	// RouteParty is the /party streams
+	RouteParty = "party"
+	// RouteExport is GIF, PNG and SVG exports: countdowns, ANSI playback
+	// and async renders
+	RouteExport = "export"
+	// RouteShortlinks is creating shortlinks
+	RouteShortlinks = "shortlinks"
+	// RouteIntegrations is the Alertmanager and Discord integrations
+	RouteIntegrations = "integrations"
+)
+
+// RouteClasses lists the route classes SHOUT_RATELIMIT_ROUTES accepts.
+var RouteClasses = []string{RouteRender, RouteParty, RouteExport, RouteShortlinks, RouteIntegrations}
+
 // FontConfig contains font-related settings
 type FontConfig struct {
-	Default string   `env:"DEFAULT" envDefault:"standard"`
-	Path    string   `env:"PATH" envDefault:"./fonts"`
-	Allowed []string `env:"ALLOWED" envDefault:"standard,doom,banner,slant,3d,speed,starwars"`
+	Default string   `env:"DEFAULT" envDefault:"standard" desc:"Font used when a request doesn't name one"`
+	Path    string   `env:"PATH" envDefault:"./fonts" desc:"Directory of .flf fonts"`
+	Allowed []string `env:"ALLOWED" envDefault:"standard,doom,banner,slant,3d,speed,starwars,small" desc:"Fonts requests may use"`
+	// Controls lists .flc control files in Path applied before rendering
+	Controls []string `env:"CONTROLS" desc:"FIGlet control files (.flc in the fonts directory) applied to text before rendering"`
+	// Embedded falls back to the fonts bundled in the binary
+	Embedded bool `env:"EMBEDDED" envDefault:"true" desc:"Fall back to the fonts bundled in the binary when a font is missing from the fonts directory"`
+	// Watch reloads fonts when files in Path change
+	Watch bool `env:"WATCH" envDefault:"false" desc:"Reload fonts automatically when files in the fonts directory change"`
+	// Remote lists fonts to download at startup as "[name=]url[#sha256=hex]"
+	Remote []string `env:"REMOTE" desc:"Fonts to download at startup, as [name=]url[#sha256=hex]"`
+	// RemoteHosts are the only hosts remote fonts may be fetched from
+	RemoteHosts []string `env:"REMOTE_HOSTS" envDefault:"raw.githubusercontent.com" desc:"Hosts remote fonts may be downloaded from"`
+	// RemoteMaxBytes caps the size of a downloaded font
+	RemoteMaxBytes int64 `env:"REMOTE_MAX_BYTES" envDefault:"1048576" desc:"Maximum size of a downloaded font"`
+	// CacheDir stores downloaded fonts between restarts
+	CacheDir string `env:"CACHE_DIR" envDefault:"./cache/fonts" desc:"Where downloaded fonts are cached between restarts"`
+	// MemoryBudget caps the bytes of parsed fonts kept in memory; 0 is unlimited
+	MemoryBudget int64 `env:"MEMORY_BUDGET" envDefault:"16777216" desc:"Bytes of parsed fonts kept in memory, 0 for unlimited"`
+	// Warmup lists banners rendered at startup as "font:text", so their
+	// fonts are parsed before the first request and a broken one shows
+	// up in /readyz rather than in a user's request
+	Warmup []string `env:"WARMUP" desc:"Banners rendered at startup, as font:text, before /readyz reports ready"`
 }
 
 // StreamingConfig contains streaming/animation settings
 type StreamingConfig struct {
-	DefaultTimeout int `env:"DEFAULT_TIMEOUT" envDefault:"30"`
-	MaxTimeout     int `env:"MAX_TIMEOUT" envDefault:"300"`
-	DefaultSpeed   int `env:"DEFAULT_SPEED" envDefault:"5"`
-	BufferSize     int `env:"BUFFER_SIZE" envDefault:"4096"`
+	DefaultTimeout int `env:"DEFAULT_TIMEOUT" envDefault:"30" desc:"How long party mode runs without ?t=, in seconds"`
+	MaxTimeout     int `env:"MAX_TIMEOUT" envDefault:"300" desc:"Longest a stream or countdown may run, in seconds"`
+	DefaultSpeed   int `env:"DEFAULT_SPEED" envDefault:"5" desc:"Animation speed without ?speed="`
+	// DefaultColor animates party mode without ?color= or a preset in
+	// ?anim=
+	DefaultColor string `env:"DEFAULT_COLOR" envDefault:"rainbow" desc:"Party mode colors without ?color= or a preset in ?anim="`
+	// DefaultAnimation animates party mode without ?anim=
+	DefaultAnimation string `env:"DEFAULT_ANIMATION" envDefault:"colors" desc:"Party mode animation without ?anim=, such as typewriter or scroll+fire"`
+	// MinSpeed and MaxSpeed bound the ?speed= option, within 1-10
+	MinSpeed int `env:"MIN_SPEED" envDefault:"1" desc:"Slowest ?speed= accepted, from 1"`
+	MaxSpeed int `env:"MAX_SPEED" envDefault:"10" desc:"Fastest ?speed= accepted, up to 10"`
+	// MaxFPS caps the ?fps= option
+	MaxFPS     int `env:"MAX_FPS" envDefault:"20" desc:"Highest ?fps= accepted"`
+	BufferSize int `env:"BUFFER_SIZE" envDefault:"4096" desc:"Unused; kept so existing configurations still load"`
+	// MaxStreams caps concurrent streaming responses
+	MaxStreams int64 `env:"MAX_STREAMS" envDefault:"100" desc:"Streams served at once before new ones get 503"`
+	// MaxStreamsPerIP caps the streams one IP holds at once, with or
+	// without an API key; 0 is unlimited
+	MaxStreamsPerIP int64 `env:"MAX_STREAMS_PER_IP" envDefault:"0" desc:"Streams one IP may hold at once, with or without an API key, 0 for unlimited"`
+	// CountdownMessage is shown when a countdown reaches zero
+	CountdownMessage string `env:"COUNTDOWN_MESSAGE" envDefault:"TIME'S UP" desc:"Message shown when a countdown ends"`
+	// DegradeStreams is the percentage of MaxStreams in use, and DegradeCPU
+	// the percentage of CPU in use, from which new streams are degraded to
+	// DegradedFPS without compression; 0 turns a threshold off
+	DegradeStreams int `env:"DEGRADE_STREAMS" envDefault:"80" desc:"Percentage of the stream limit in use from which new streams are degraded, 0 for never"`
+	DegradeCPU     int `env:"DEGRADE_CPU" envDefault:"90" desc:"Percentage of CPU in use from which new streams are degraded, 0 for never"`
+	DegradedFPS    int `env:"DEGRADED_FPS" envDefault:"4" desc:"Frame rate cap for degraded streams"`
 }
 
 // TextConfig contains text processing settings
 type TextConfig struct {
-	MaxLength     int    `env:"MAX_LENGTH" envDefault:"100"`
-	DefaultAlign  string `env:"DEFAULT_ALIGN" envDefault:"center"`
-	DefaultBorder string `env:"DEFAULT_BORDER" envDefault:"none"`
+	MaxLength     int    `env:"MAX_LENGTH" envDefault:"100" desc:"Longest text accepted, in characters"`
+	DefaultAlign  string `env:"DEFAULT_ALIGN" envDefault:"center" desc:"Alignment within the terminal width when align isn't given"`
+	DefaultBorder string `env:"DEFAULT_BORDER" envDefault:"none" desc:"Border drawn when border isn't given: none, single, double or rounded"`
+	// DefaultColor colors banners without ?color=; empty leaves them
+	// uncolored. Party mode has its own, in StreamingConfig
+	DefaultColor string `env:"DEFAULT_COLOR" desc:"Colors of banners without ?color=, as a preset or colors"`
+	// DefaultColorMode is how a color list cycles without ?cmode=
+	DefaultColorMode string `env:"DEFAULT_COLOR_MODE" envDefault:"word" desc:"How a color list cycles without ?cmode=: char, word or line"`
+	// DefaultMaxWidth is the banner width without ?maxwidth=; 0 is none
+	DefaultMaxWidth int `env:"DEFAULT_MAX_WIDTH" envDefault:"0" desc:"Banner width without ?maxwidth=, at most SHOUT_TERMINAL_MAX_COLS; 0 for none"`
+	// Overflow is what happens to text longer than MaxLength: reject or truncate
+	Overflow string `env:"OVERFLOW" envDefault:"reject" desc:"What to do with text longer than the maximum length: reject with 400 or truncate"`
+	// Filter is the action for blocked words: allow, reject or mask
+	Filter string `env:"FILTER" envDefault:"allow" desc:"Blocked word handling: allow, reject or mask"`
+	// FilterWords lists blocked words
+	FilterWords []string `env:"FILTER_WORDS" desc:"Blocked words"`
+	// FilterFile is a file of blocked words, one per line
+	FilterFile string `env:"FILTER_FILE" desc:"File of blocked words, one per line"`
+	// Placeholders lists the placeholders expanded in text, such as {date};
+	// "none" disables them
+	Placeholders []string `env:"PLACEHOLDERS" envDefault:"hostname,date,time,ip" desc:"Placeholders expanded in text, or none"`
+	// Presets are the bundles of options ?preset= names, as name:query;
+	// they're separated by semicolons as color lists hold commas
+	Presets []string `env:"PRESETS" envSeparator:";" envDefault:"party:f=doom&c=rainbow&s=8;minimal:f=standard&c=none&a=left;retro:f=slant&c=green&b=single" desc:"Option presets for ?preset=, as name:query separated by semicolons, e.g. loud:f=doom&c=fire"`
+	// Footer is a line, such as an attribution, drawn below every static
+	// banner; requests with an API key leave it out with ?nofooter=1
+	Footer string `env:"FOOTER" desc:"Line drawn below static banners, such as generated by shout.sh; requests with an API key can leave it out with ?nofooter=1. Empty for none"`
+	// Themes are the looks ?theme= names, as name:slots with the slots
+	// fg, bg, accent, border and pad
+	Themes []string `env:"THEMES" envSeparator:";" envDefault:"ocean:fg=gradient:00bfff-0000cd&accent=skyblue&border=rounded&pad=1;terminal:fg=lime&bg=black&accent=green&border=single&pad=1;sunset:fg=gradient:ff4500-ffd700&accent=orange&border=double;paper:fg=black&bg=white&accent=gray&border=single&pad=1" desc:"Themes for ?theme=, as name:slots separated by semicolons, with the slots fg, bg, accent, border and pad, e.g. mono:fg=white&bg=black&border=double"`
+}
+
+// CompressionConfig contains response compression settings
+type CompressionConfig struct {
+	// Static compresses static renders with brotli or gzip
+	Static bool `env:"STATIC" envDefault:"true" desc:"Compress static responses with brotli or gzip"`
+	// Stream compresses streaming responses with gzip, flushed per frame
+	Stream bool `env:"STREAM" envDefault:"true" desc:"Compress streams with per-frame flushed gzip"`
+	// MinBytes is the smallest response body worth compressing
+	MinBytes int `env:"MIN_BYTES" envDefault:"512" desc:"Smallest response worth compressing"`
+}
+
+// CORSConfig contains cross-origin resource sharing settings
+type CORSConfig struct {
+	Enabled      bool     `env:"ENABLED" envDefault:"true" desc:"Send CORS headers and answer preflight requests so browser frontends can call the API"`
+	AllowOrigins []string `env:"ALLOW_ORIGINS" envDefault:"*" desc:"Comma-separated origins allowed to call the API"`
+	AllowHeaders []string `env:"ALLOW_HEADERS" envDefault:"Origin,Content-Type,Accept,If-None-Match,X-API-Key" desc:"Request headers browsers may send"`
+	// MaxAge is how long browsers may cache a preflight response, in seconds
+	MaxAge int `env:"MAX_AGE" envDefault:"86400" desc:"Seconds browsers may cache a preflight response"`
+}
+
+// SecurityConfig contains the security headers of HTTP responses
+type SecurityConfig struct {
+	// Headers sends the security headers; off leaves them to a proxy
+	Headers bool `env:"HEADERS" envDefault:"true" desc:"Send nosniff, Referrer-Policy and Content Security Policy headers"`
+	// FrameAncestors are the CSP sources that may embed HTML and SVG
+	// responses in a frame
+	FrameAncestors []string `env:"FRAME_ANCESTORS" envDefault:"'self'" desc:"CSP sources that may embed the help page and badges in a frame"`
+	// ReferrerPolicy is sent with every response; empty omits it
+	ReferrerPolicy string `env:"REFERRER_POLICY" envDefault:"no-referrer" desc:"Referrer-Policy header; empty omits it"`
+	// HelpCSP, BadgeCSP and DocsCSP replace the built-in Content Security
+	// Policy of the HTML help page, the SVG badges and the admin API docs
+	HelpCSP  string `env:"HELP_CSP" desc:"Content Security Policy of the HTML help page, in place of the built-in one"`
+	BadgeCSP string `env:"BADGE_CSP" desc:"Content Security Policy of badges, in place of the built-in one"`
+	DocsCSP  string `env:"DOCS_CSP" desc:"Content Security Policy of the admin /docs page, in place of the built-in one"`
+}
+
+// ShortlinkConfig contains saved shout settings
+type ShortlinkConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"true" desc:"Serve the /s short link endpoints"`
+	// Store is where saved shouts are kept: memory or redis
+	Store string `env:"STORE" envDefault:"memory" desc:"Where saved shouts are kept: memory or redis"`
+	// RedisURL addresses the Redis server, e.g. redis://localhost:6379/0
+	RedisURL string `env:"REDIS_URL" redact:"url" desc:"Redis server for the redis store"`
+	// TTL is how long a saved shout can be replayed
+	TTL time.Duration `env:"TTL" envDefault:"720h" desc:"How long a saved shout can be replayed"`
+	// MaxEntries caps the memory store; the oldest shouts are dropped first
+	MaxEntries int `env:"MAX_ENTRIES" envDefault:"10000" desc:"Most shouts kept by the memory store; the oldest are dropped first"`
+	// CreatesPerMinute limits how many shouts one client may save; 0 is unlimited
+	CreatesPerMinute int `env:"CREATES_PER_MINUTE" envDefault:"10" desc:"Short links one client may create per minute, 0 for no limit"`
+}
+
+// FortuneConfig contains /fortune settings
+type FortuneConfig struct {
+	// Path is a fortune file with quotes separated by "%" lines; empty uses
+	// the bundled quotes
+	Path string `env:"PATH" desc:"Fortune file with quotes separated by lines holding only %"`
+	// Font draws the quote; the headline uses the requested font
+	Font string `env:"FONT" envDefault:"small" desc:"Font the fortune quote is drawn in"`
+	// Width is the column the quote is wrapped at, unless ?mw= is given
+	Width int `env:"WIDTH" envDefault:"80" desc:"Column the fortune quote is wrapped at, unless mw is given"`
+}
+
+// ANSIConfig contains .ANS artwork playback settings
+type ANSIConfig struct {
+	// Path is a directory of .ans files served by name; empty disables it
+	Path string `env:"PATH" desc:"Directory of .ans files served at /ans/{name}"`
+	// MaxBytes caps the size of uploaded artwork
+	MaxBytes int `env:"MAX_BYTES" envDefault:"262144" desc:"Largest .ANS upload accepted"`
+}
+
+// GIFConfig contains animated GIF export settings. Rendering GIFs is CPU
+// heavy, so it is off by default.
+type GIFConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"false" desc:"Allow ?format=gif exports, which are CPU heavy"`
+	// MaxFrames caps the frames in one GIF
+	MaxFrames int `env:"MAX_FRAMES" envDefault:"300" desc:"Most frames in one GIF"`
+	// MaxCols and MaxRows bound the emulated screen; larger output is cropped
+	MaxCols int `env:"MAX_COLS" envDefault:"160" desc:"Widest GIF screen in characters"`
+	MaxRows int `env:"MAX_ROWS" envDefault:"60" desc:"Tallest GIF screen in characters"`
+	// Scale enlarges the 7x13 pixel character cells
+	Scale int `env:"SCALE" envDefault:"1" desc:"Pixel scale of the 7x13 GIF font, 1-4"`
+	// PerMinute limits how many GIFs one client may render; 0 is unlimited
+	PerMinute int `env:"PER_MINUTE" envDefault:"5" desc:"GIF exports allowed per client per minute, 0 for no limit"`
+}
+
+// TerminalConfig contains the client terminal size assumed when a request
+// doesn't send one with ?cols=, ?rows= or X-Terminal-Size, and whether it
+// shows colors when the request doesn't say with ?nc=
+type TerminalConfig struct {
+	// Cols and Rows are the assumed size; 0 leaves text unwrapped and
+	// frames uncropped
+	Cols int `env:"COLS" envDefault:"0" desc:"Terminal width assumed when a request doesn't send one, 0 for unknown"`
+	Rows int `env:"ROWS" envDefault:"0" desc:"Terminal height assumed when a request doesn't send one, 0 for unknown"`
+	// MaxCols and MaxRows bound the size a request may give
+	MaxCols int `env:"MAX_COLS" envDefault:"500" desc:"Widest terminal a request may give"`
+	MaxRows int `env:"MAX_ROWS" envDefault:"200" desc:"Tallest terminal a request may give"`
+	// NoColor leaves colors out of every banner, as the NO_COLOR
+	// convention asks, unless the request sends ?nc=0
+	NoColor bool `env:"NO_COLOR" envDefault:"false" desc:"Leave colors out unless a request sends ?nc=0"`
+	// NonInteractiveNoColor leaves colors out for clients that look like
+	// CI jobs rather than terminals: a NonInteractiveAgents user agent
+	// that accepts text/plain
+	NonInteractiveNoColor bool `env:"NON_INTERACTIVE_NO_COLOR" envDefault:"true" desc:"Leave colors out for non-interactive clients, those with a user agent in SHOUT_TERMINAL_NON_INTERACTIVE_AGENTS accepting text/plain, unless they send ?nc=0"`
+	// NonInteractiveAgents are matched within User-Agent, ignoring case
+	NonInteractiveAgents []string `env:"NON_INTERACTIVE_AGENTS" envDefault:"Jenkins,GitLab,Buildkite,TeamCity,Travis,CircleCI,Drone,Bitbucket-Pipelines,Azure-Pipelines" desc:"User agents of CI systems, matched as substrings ignoring case, whose requests count as non-interactive"`
+}
+
+// GRPCConfig contains settings for the optional gRPC API, served on its
+// own port
+type GRPCConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"false" desc:"Serve the gRPC API"`
+	Port    int  `env:"PORT" envDefault:"9091" desc:"gRPC API port"`
+	// Reflection lets tools such as grpcurl list the services
+	Reflection bool `env:"REFLECTION" envDefault:"true" desc:"Register gRPC server reflection"`
+}
+
+// GopherConfig contains settings for the optional Gopher listener
+type GopherConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"false" desc:"Serve Gopher"`
+	Port    int  `env:"PORT" envDefault:"7070" desc:"Gopher port"`
+	// Hostname is the host menus link to, as clients reach it
+	Hostname string `env:"HOSTNAME" envDefault:"localhost" desc:"Host that Gopher menus link to, as clients reach it"`
+	// Width is the column banners wrap at; Gopher clients are usually 70-80 wide
+	Width int `env:"WIDTH" envDefault:"70" desc:"Column Gopher banners wrap at, 0 for none"`
+}
+
+// DiscordConfig contains settings for the Discord integration
+type DiscordConfig struct {
+	// Relay serves POST /integrations/discord, which posts banners to webhooks
+	Relay bool `env:"RELAY" envDefault:"false" desc:"Serve POST /integrations/discord"`
+	// WebhookHosts are the only hosts the relay posts to
+	WebhookHosts []string `env:"WEBHOOK_HOSTS" envDefault:"discord.com,discordapp.com,canary.discord.com,ptb.discord.com" desc:"Hosts the relay posts to"`
+	// RelaysPerMinute limits how many banners one client may relay; 0 is unlimited
+	RelaysPerMinute int `env:"RELAYS_PER_MINUTE" envDefault:"10" desc:"Banners one client may relay per minute; 0 is unlimited"`
+}
+
+// AsyncConfig contains settings for async renders, which are queued and
+// POSTed to a callback URL when done
+type AsyncConfig struct {
+	// Enabled serves POST /render/async
+	Enabled bool `env:"ENABLED" envDefault:"false" desc:"Serve POST /render/async"`
+	// Secret is the HMAC-SHA256 key callbacks are signed with
+	Secret string `env:"SECRET" redact:"true" desc:"Key callbacks are signed with; required with async renders"`
+	// Workers is how many jobs render at once
+	Workers int `env:"WORKERS" envDefault:"2" desc:"Async renders run at once"`
+	// QueueSize bounds the jobs waiting for a worker; more are turned away
+	QueueSize int `env:"QUEUE_SIZE" envDefault:"32" desc:"Async renders waiting for a worker before new ones get 503"`
+	// CallbackHosts, when set, are the only hosts callbacks are sent to
+	CallbackHosts []string `env:"CALLBACK_HOSTS" desc:"Hosts callbacks may be sent to"`
+	// JobsPerMinute limits how many jobs one client may queue; 0 is unlimited
+	JobsPerMinute int `env:"JOBS_PER_MINUTE" envDefault:"10" desc:"Async renders one client may queue per minute, 0 for no limit"`
+}
+
+// AuthConfig contains settings for the optional API keys, which select a
+// tier of rate limits and features
+type AuthConfig struct {
+	// KeysFile is a JSON file of tiers and hashed keys; empty disables keys
+	KeysFile string `env:"KEYS_FILE" desc:"JSON file of API key tiers and hashed keys; unset disables keys"`
+	// Required refuses requests without a key
+	Required bool `env:"REQUIRED" envDefault:"false" desc:"Refuse requests without an API key"`
+}
+
+// KeyPresetsConfig contains the presets API keys save for themselves
+// with PUT /me/presets/{name}
+type KeyPresetsConfig struct {
+	// Store is where saved presets are kept: memory or redis
+	Store string `env:"STORE" envDefault:"memory" desc:"Where presets saved by API keys are kept: memory or redis"`
+	// RedisURL addresses the Redis server, e.g. redis://localhost:6379/0
+	RedisURL string `env:"REDIS_URL" redact:"url" desc:"Redis server for the redis store"`
+	// MaxPerKey caps the presets one key may save
+	MaxPerKey int `env:"MAX_PER_KEY" envDefault:"50" desc:"Most presets one API key may save"`
+}
+
+// QuotaConfig contains the daily quotas of clients without an API key, or
+// of every client when keys are disabled
+type QuotaConfig struct {
+	// RequestsPerDay limits requests per IP over a rolling day; 0 is unlimited
+	RequestsPerDay int64 `env:"REQUESTS_PER_DAY" envDefault:"0" desc:"Requests one IP without an API key may make in a rolling day, 0 for no limit"`
+	// StreamSecondsPerDay limits seconds of streaming per IP over a rolling
+	// day; 0 is unlimited
+	StreamSecondsPerDay int64 `env:"STREAM_SECONDS_PER_DAY" envDefault:"0" desc:"Seconds one IP without an API key may stream in a rolling day, 0 for no limit"`
+}
+
+// AccessConfig contains the IP allow and deny lists of the HTTP API
+type AccessConfig struct {
+	// Allow, when set, are the only IPs or CIDR ranges served
+	Allow []string `env:"ALLOW" desc:"IPs and CIDR ranges that are the only ones served"`
+	// Deny are IPs or CIDR ranges refused, even when allowed
+	Deny []string `env:"DENY" desc:"IPs and CIDR ranges refused, even when allowed"`
+	// File holds more "allow" and "deny" lines, reloaded when it changes
+	File string `env:"FILE" desc:"File of allow <range> and deny <range> lines, reloaded when it changes"`
+}
+
+// SigningConfig contains the settings of signed request mode, in which
+// the HTTP API only answers requests signed with one of the secrets
+type SigningConfig struct {
+	// Secrets sign requests; empty turns signing off. Several allow the
+	// secret to be rotated without downtime.
+	Secrets []string `env:"SECRETS" redact:"true" desc:"Secrets requests must be signed with; unset serves unsigned requests"`
+	// MaxAge is how old a signature may be, and how far in the future
+	MaxAge time.Duration `env:"MAX_AGE" envDefault:"5m" desc:"How far a signature's timestamp may be from the server's clock"`
+	// SingleUse refuses a signature seen before, within MaxAge
+	SingleUse bool `env:"SINGLE_USE" envDefault:"false" desc:"Refuse a signature seen before"`
+}
+
+// GeoIPConfig contains the per-country access policy, which needs a
+// MaxMind DB to place client IPs. IPs the database doesn't place are
+// always served.
+type GeoIPConfig struct {
+	// Database is a MaxMind DB file such as GeoLite2-Country.mmdb; empty
+	// turns GeoIP off
+	Database string `env:"DATABASE" desc:"MaxMind DB (.mmdb) placing client IPs in countries; unset turns GeoIP off"`
+	// Allow lists the only countries served, as ISO codes such as DE
+	Allow []string `env:"ALLOW" desc:"Countries, as ISO codes, that are the only ones served"`
+	// Deny lists countries refused, even when allowed
+	Deny []string `env:"DENY" desc:"Countries, as ISO codes, that are refused"`
+	// RateLimits gives countries their own requests per minute per IP, as
+	// "CC=N", for requests without an API key
+	RateLimits []string `env:"RATE_LIMITS" desc:"Requests per minute per IP in a country without an API key, as CC=N"`
+}
+
+// BotsConfig contains the settings of scanner and bot detection
+type BotsConfig struct {
+	// Detect classifies requests by user agent, probed paths and cadence
+	Detect bool `env:"DETECT" envDefault:"true" desc:"Turn away scanners and bots before rendering"`
+	// Action is what bots get: static, a 403, or tarpit, the 403 dripped
+	// out slowly
+	Action string `env:"ACTION" envDefault:"static" desc:"What bots get: static, an immediate 403, or tarpit, the 403 dripped out slowly"`
+	// MaxPerSecond is the request rate from one IP above which it is a
+	// bot; 0, the default, turns cadence detection off, since curl loops
+	// and clients behind NAT send requests quickly too
+	MaxPerSecond int `env:"MAX_PER_SECOND" envDefault:"0" desc:"Requests per second from one IP above which it is a bot, 0 to turn cadence detection off"`
+	// Remember is how long a client stays classified
+	Remember time.Duration `env:"REMEMBER" envDefault:"10m" desc:"How long a client stays classified as a bot"`
+}
+
+// TarpitConfig contains the settings of the tarpit, which drips errors
+// out slowly to clients that keep hitting their limits, and to bots with
+// SHOUT_BOTS_ACTION=tarpit
+type TarpitConfig struct {
+	// Strikes is how many 429 responses a client may get within Window
+	// before the next ones are tarpitted; 0 never tarpits limit offenders
+	Strikes int `env:"STRIKES" envDefault:"0" desc:"429 responses a client may get within the window before the next ones are tarpitted, 0 for never"`
+	// Window is the period strikes are counted over
+	Window time.Duration `env:"WINDOW" envDefault:"1m" desc:"Period strikes are counted over"`
+	// Interval is the time between the bytes of a tarpitted response
+	Interval time.Duration `env:"INTERVAL" envDefault:"1s" desc:"Time between the bytes of a tarpitted response"`
+	// MaxBytes is how many bytes a tarpitted response drips before it ends
+	MaxBytes int `env:"MAX_BYTES" envDefault:"30" desc:"Bytes a tarpitted response drips before it ends"`
+	// MaxClients is how many clients are held at once; the rest get their
+	// error straight away
+	MaxClients int64 `env:"MAX_CLIENTS" envDefault:"100" desc:"Clients held in the tarpit at once"`
+}
+
+// LogConfig contains access log settings
+type LogConfig struct {
+	// AccessFormat is combined (Apache) or json; empty or off logs nothing
+	AccessFormat string `env:"ACCESS_FORMAT" envDefault:"off" desc:"Access log format: combined, json or off"`
+	// AccessOutput is stdout, stderr or a file to append to, which is
+	// reopened on SIGHUP for log rotation
+	AccessOutput string `env:"ACCESS_OUTPUT" envDefault:"stdout" desc:"Where access logs go: stdout, stderr or a file, reopened on SIGHUP"`
+}
+
+// ErrorsConfig contains the settings of error reporting: panics and 5xx
+// responses are sent to Sentry, a webhook, or both
+type ErrorsConfig struct {
+	// SentryDSN is the project DSN from Sentry's client keys settings;
+	// empty doesn't report to Sentry
+	SentryDSN string `env:"SENTRY_DSN" redact:"true" desc:"Sentry DSN panics and 5xx responses are reported to; unset disables Sentry"`
+	// Webhook receives each report as JSON; empty sends none
+	Webhook string `env:"WEBHOOK" redact:"true" desc:"URL panics and 5xx responses are posted to as JSON; unset disables the webhook"`
+	// Environment tags reports, such as production or staging
+	Environment string `env:"ENVIRONMENT" envDefault:"production" desc:"Environment reports are tagged with"`
+}
+
+// AnalyticsConfig contains the settings of usage analytics: renders are
+// counted by font, color and animation, to show which fonts the default
+// set should keep
+type AnalyticsConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"true" desc:"Count renders by font, color and animation"`
+}
+
+// TLSConfig contains the settings of HTTPS on the public port, with a
+// certificate from files or from an ACME CA such as Let's Encrypt. With
+// neither, the public port serves plain HTTP
+type TLSConfig struct {
+	// CertFile and KeyFile are a PEM certificate chain and its key
+	CertFile string `env:"CERT_FILE" desc:"PEM certificate the public port serves HTTPS with"`
+	KeyFile  string `env:"KEY_FILE" desc:"PEM key of the certificate"`
+	// ACMEDomains are the only domains certificates are requested for;
+	// empty disables ACME
+	ACMEDomains []string `env:"ACME_DOMAINS" desc:"Domains to obtain certificates for from Let's Encrypt; unset disables ACME"`
+	// ACMEEmail is given to the CA for expiry and policy notices
+	ACMEEmail string `env:"ACME_EMAIL" desc:"Contact address for the ACME account, told about expiring certificates"`
+	// ACMECacheDir keeps the account key and certificates between
+	// restarts, so they aren't requested again
+	ACMECacheDir string `env:"ACME_CACHE_DIR" envDefault:"./cache/acme" desc:"Where ACME certificates and the account key are kept"`
+	// ACMEDirectory is the CA's directory URL; empty is Let's Encrypt
+	ACMEDirectory string `env:"ACME_DIRECTORY" desc:"ACME directory URL, such as Let's Encrypt staging"`
+	// RedirectPort serves plain HTTP that redirects to HTTPS and answers
+	// ACME HTTP challenges; 0 disables it
+	RedirectPort int `env:"REDIRECT_PORT" desc:"Port serving plain HTTP that redirects to HTTPS"`
+}
+
+// VHostsConfig contains the settings of virtual hosts, which give each
+// host name the instance serves its own defaults and branding
+type VHostsConfig struct {
+	// File is a JSON file of hosts; empty serves every host alike
+	File string `env:"FILE" desc:"JSON file of host names with their own defaults, footer and rate limit"`
+}
+
+// FeaturesConfig switches whole subsystems off, whatever their own
+// settings say, for operators who don't want to serve expensive or risky
+// routes. Routes of a feature that is off answer 404 feature_disabled.
+type FeaturesConfig struct {
+	// EnablePNG allows PNG artifacts of async renders
+	EnablePNG bool `env:"ENABLE_PNG" envDefault:"true" desc:"Allow PNG exports"`
+	// EnableGIF allows GIF exports, with SHOUT_GIF_ENABLED also set
+	EnableGIF bool `env:"ENABLE_GIF" envDefault:"true" desc:"Allow GIF exports, when SHOUT_GIF_ENABLED is also set"`
+	// EnableStreaming serves party mode, countdowns and ANSI playback,
+	// over HTTP and gRPC
+	EnableStreaming bool `env:"ENABLE_STREAMING" envDefault:"true" desc:"Serve party mode, countdowns and ANSI playback"`
+	// EnableUploads serves POST /ans, which plays back uploaded art
+	EnableUploads bool `env:"ENABLE_UPLOADS" envDefault:"true" desc:"Serve POST /ans, which plays back uploaded art"`
+	// EnableShortlinks serves the /s endpoints, with
+	// SHOUT_SHORTLINK_ENABLED also set
+	EnableShortlinks bool `env:"ENABLE_SHORTLINKS" envDefault:"true" desc:"Serve the /s short link endpoints, when SHOUT_SHORTLINK_ENABLED is also set"`
+}
+
+// RemoteConfig contains the settings of a Consul KV prefix that settings
+// are also read from, under the environment and the .env file, so a fleet
+// can be reconfigured in one place. Changes under the prefix are applied
+// as POST /reload on the admin port would
+type RemoteConfig struct {
+	// ConsulAddr is the Consul HTTP API; empty reads no remote settings
+	ConsulAddr string `env:"CONSUL_ADDR" desc:"Consul HTTP address settings are read from, such as http://127.0.0.1:8500; unset reads none"`
+	// Prefix holds one key per setting, named by its environment
+	// variable, such as shout/SHOUT_RATELIMIT_REQUESTS_PER_MINUTE
+	Prefix string `env:"PREFIX" envDefault:"shout/" desc:"Consul KV prefix whose keys, named by environment variable, hold settings"`
+	// Token is the Consul ACL token, which needs read access to Prefix
+	Token string `env:"TOKEN" redact:"true" desc:"Consul ACL token with read access to the prefix"`
+}
+
+// CacheConfig contains the settings of the render cache, which keeps
+// static banners in memory and, in a fleet, in Redis, so popular ones are
+// rendered once
+type CacheConfig struct {
+	// Entries caps the banners kept in memory; 0 keeps none
+	Entries int `env:"ENTRIES" envDefault:"1000" desc:"Static banners kept in memory, 0 to keep none"`
+	// TTL is how long a banner is kept, in memory and in Redis
+	TTL time.Duration `env:"TTL" envDefault:"10m" desc:"How long a rendered banner is cached"`
+	// RedisURL addresses the Redis server the fleet shares banners
+	// through, e.g. redis://localhost:6379/1; empty caches on each
+	// instance only
+	RedisURL string `env:"REDIS_URL" redact:"url" desc:"Redis server the fleet shares rendered banners through; unset caches on each instance only"`
+	// Namespace prefixes the Redis keys, so instances of different
+	// deployments can share a server
+	Namespace string `env:"NAMESPACE" envDefault:"shout:render:" desc:"Prefix of the render cache's Redis keys"`
+	// LockTimeout is how long instances wait for the one rendering a
+	// banner missing from Redis, before rendering it themselves
+	LockTimeout time.Duration `env:"LOCK_TIMEOUT" envDefault:"5s" desc:"How long instances wait for another to render a banner missing from Redis"`
 }
 
 // Load reads configuration from environment variables and .env file.
@@ -81,10 +619,23 @@ func Load() (*Config, error) {
 		instance = &Config{}
 
 		// Try to load .env file if it exists (ignore error if not found)
+		fromDotenv = dotenvOnly()
 		_ = godotenv.Load()
 
-		// Parse environment variables into config struct
-		if err := env.Parse(instance); err != nil {
+		// Parse environment variables into config struct, reading
+		// settings given as files
+		vars := environ()
+		if err := readFileSettings(vars); err != nil {
+			loadErr = err
+			instance = nil // Clear instance on error
+			return
+		}
+		if err := readRemoteSettings(vars); err != nil {
+			loadErr = err
+			instance = nil // Clear instance on error
+			return
+		}
+		if err := env.ParseWithOptions(instance, env.Options{Environment: vars}); err != nil {
 			loadErr = fmt.Errorf("failed to parse environment variables: %w", err)
 			instance = nil // Clear instance on error
 			return
@@ -135,28 +686,414 @@ func MustLoad() *Config {
 	return cfg
 }
 
+// FieldError is a setting that failed validation.
+type FieldError struct {
+	// Field is the setting's environment variable, such as
+	// SHOUT_SERVER_PUBLIC_PORT
+	Field string
+	// Value is the value received, after defaults, with secrets redacted
+	Value string
+	// Message says what is wrong with the value
+	Message string
+}
+
+// Error returns the setting, its value and what is wrong with it.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s=%q: %s", e.Field, e.Value, e.Message)
+}
+
+// ValidationError holds every setting that failed validation, in the
+// order Validate checks them.
+//
+// Example:
+//
+//	var invalid *config.ValidationError
+//	if errors.As(err, &invalid) {
+//	    for _, field := range invalid.Fields {
+//	        log.Printf("%s: %s", field.Field, field.Message)
+//	    }
+//	}
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Error returns a report of the invalid settings, one per line after the
+// first if there are several.
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 1 {
+		return e.Fields[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d invalid settings:", len(e.Fields))
+	for _, field := range e.Fields {
+		b.WriteString("\n  ")
+		b.WriteString(field.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the FieldErrors, for errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = field
+	}
+	return errs
+}
+
+// validation collects the failures Validate finds.
+type validation struct {
+	settings map[string]string
+	fields   []*FieldError
+}
+
+// fail records that the setting named by its environment variable is
+// invalid, with a message formatted from format and args.
+func (v *validation) fail(field, format string, args ...any) {
+	v.fields = append(v.fields, &FieldError{
+		Field:   field,
+		Value:   v.settings[field],
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
 // Validate checks if the configuration values are valid.
-// Returns an error if any validation fails.
+// It checks every setting rather than stopping at the first problem.
+//
+// Returns:
+//   - error: a *ValidationError listing each invalid setting with the
+//     value received, or nil if the configuration is valid
 func (c *Config) Validate() error {
+	v := &validation{settings: c.Settings()}
+
 	// Validate ports
 	if c.Server.PublicPort < 1 || c.Server.PublicPort > 65535 {
-		return fmt.Errorf("invalid port: public port must be between 1 and 65535, got %d", c.Server.PublicPort)
+		v.fail("SHOUT_SERVER_PUBLIC_PORT", "invalid port: public port must be between 1 and 65535")
 	}
 	if c.Server.AdminPort < 1 || c.Server.AdminPort > 65535 {
-		return fmt.Errorf("invalid port: admin port must be between 1 and 65535, got %d", c.Server.AdminPort)
+		v.fail("SHOUT_SERVER_ADMIN_PORT", "invalid port: admin port must be between 1 and 65535")
+	}
+
+	if c.GRPC.Enabled && (c.GRPC.Port < 1 || c.GRPC.Port > 65535) {
+		v.fail("SHOUT_GRPC_PORT", "invalid port: gRPC port must be between 1 and 65535")
+	}
+	if c.Gopher.Enabled {
+		if c.Gopher.Port < 1 || c.Gopher.Port > 65535 {
+			v.fail("SHOUT_GOPHER_PORT", "invalid port: Gopher port must be between 1 and 65535")
+		}
+		if c.Gopher.Hostname == "" {
+			v.fail("SHOUT_GOPHER_HOSTNAME", "gopher requires SHOUT_GOPHER_HOSTNAME")
+		}
+		if c.Gopher.Width < 0 || c.Gopher.Width > c.Terminal.MaxCols {
+			v.fail("SHOUT_GOPHER_WIDTH", "gopher width must be between 0 and %d", c.Terminal.MaxCols)
+		}
+	}
+
+	if c.Discord.Relay && len(c.Discord.WebhookHosts) == 0 {
+		v.fail("SHOUT_DISCORD_WEBHOOK_HOSTS", "discord relay requires SHOUT_DISCORD_WEBHOOK_HOSTS")
+	}
+	if c.Discord.RelaysPerMinute < 0 {
+		v.fail("SHOUT_DISCORD_RELAYS_PER_MINUTE", "discord relays per minute must not be negative")
+	}
+
+	if c.Async.Enabled {
+		if c.Async.Secret == "" {
+			v.fail("SHOUT_ASYNC_SECRET", "async renders require SHOUT_ASYNC_SECRET to sign callbacks")
+		}
+		if c.Async.Workers < 1 {
+			v.fail("SHOUT_ASYNC_WORKERS", "async workers and queue size must be positive")
+		}
+		if c.Async.QueueSize < 1 {
+			v.fail("SHOUT_ASYNC_QUEUE_SIZE", "async workers and queue size must be positive")
+		}
+	}
+	if c.Async.JobsPerMinute < 0 {
+		v.fail("SHOUT_ASYNC_JOBS_PER_MINUTE", "async jobs per minute must not be negative")
+	}
+
+	if c.Auth.Required && c.Auth.KeysFile == "" {
+		v.fail("SHOUT_AUTH_KEYS_FILE", "required API keys need SHOUT_AUTH_KEYS_FILE")
+	}
+
+	// Validate saved preset settings
+	switch c.KeyPresets.Store {
+	case "memory":
+	case "redis":
+		if c.KeyPresets.RedisURL == "" {
+			v.fail("SHOUT_KEY_PRESETS_REDIS_URL", "preset store redis requires SHOUT_KEY_PRESETS_REDIS_URL")
+		}
+	default:
+		v.fail("SHOUT_KEY_PRESETS_STORE", "invalid preset store: must be memory or redis")
+	}
+	if c.KeyPresets.MaxPerKey < 1 {
+		v.fail("SHOUT_KEY_PRESETS_MAX_PER_KEY", "presets per key must be positive")
+	}
+
+	if c.Quota.RequestsPerDay < 0 {
+		v.fail("SHOUT_QUOTA_REQUESTS_PER_DAY", "daily quotas must not be negative")
+	}
+	if c.Quota.StreamSecondsPerDay < 0 {
+		v.fail("SHOUT_QUOTA_STREAM_SECONDS_PER_DAY", "daily quotas must not be negative")
+	}
+
+	switch c.Log.AccessFormat {
+	case "", "off", "combined", "json":
+	default:
+		v.fail("SHOUT_LOG_ACCESS_FORMAT", "access log format must be combined, json or off")
+	}
+
+	if dsn := c.Errors.SentryDSN; dsn != "" {
+		u, err := url.Parse(dsn)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User.Username() == "" || strings.Trim(u.Path, "/") == "" {
+			v.fail("SHOUT_ERRORS_SENTRY_DSN", "Sentry DSN must look like https://key@o0.ingest.sentry.io/123")
+		}
+	}
+	if hook := c.Errors.Webhook; hook != "" {
+		if u, err := url.Parse(hook); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			v.fail("SHOUT_ERRORS_WEBHOOK", "error webhook must be an http or https URL")
+		}
+	}
+
+	if c.TLS.CertFile == "" && c.TLS.KeyFile != "" {
+		v.fail("SHOUT_TLS_CERT_FILE", "TLS requires both SHOUT_TLS_CERT_FILE and SHOUT_TLS_KEY_FILE")
+	}
+	if c.TLS.CertFile != "" && c.TLS.KeyFile == "" {
+		v.fail("SHOUT_TLS_KEY_FILE", "TLS requires both SHOUT_TLS_CERT_FILE and SHOUT_TLS_KEY_FILE")
+	}
+	if c.TLS.CertFile != "" && len(c.TLS.ACMEDomains) > 0 {
+		v.fail("SHOUT_TLS_ACME_DOMAINS", "TLS certificate files and ACME domains can't both be set")
+	}
+	for _, domain := range c.TLS.ACMEDomains {
+		if !validDomain(domain) {
+			v.fail("SHOUT_TLS_ACME_DOMAINS", "ACME domain %q must be a host name such as shout.example.com", domain)
+		}
+	}
+	if dir := c.TLS.ACMEDirectory; dir != "" {
+		if u, err := url.Parse(dir); err != nil || u.Scheme != "https" || u.Host == "" {
+			v.fail("SHOUT_TLS_ACME_DIRECTORY", "ACME directory must be an https URL")
+		}
+	}
+	if c.TLS.RedirectPort != 0 {
+		if c.TLS.RedirectPort < 1 || c.TLS.RedirectPort > 65535 {
+			v.fail("SHOUT_TLS_REDIRECT_PORT", "invalid port: TLS redirect port must be between 1 and 65535")
+		}
+		if c.TLS.CertFile == "" && len(c.TLS.ACMEDomains) == 0 {
+			v.fail("SHOUT_TLS_REDIRECT_PORT", "TLS redirect port requires SHOUT_TLS_CERT_FILE or SHOUT_TLS_ACME_DOMAINS")
+		}
+		if c.TLS.RedirectPort == c.Server.PublicPort || c.TLS.RedirectPort == c.Server.AdminPort {
+			v.fail("SHOUT_TLS_REDIRECT_PORT", "TLS redirect port %d is already the public or admin port", c.TLS.RedirectPort)
+		}
+	}
+
+	if len(c.Signing.Secrets) > 0 && c.Signing.MaxAge <= 0 {
+		v.fail("SHOUT_SIGNING_MAX_AGE", "signature max age must be positive")
+	}
+
+	if c.Bots.Action != "static" && c.Bots.Action != "tarpit" {
+		v.fail("SHOUT_BOTS_ACTION", "bot action must be static or tarpit")
+	}
+	if c.Bots.MaxPerSecond < 0 {
+		v.fail("SHOUT_BOTS_MAX_PER_SECOND", "bot cadence limit and memory must not be negative")
+	}
+	if c.Bots.Remember < 0 {
+		v.fail("SHOUT_BOTS_REMEMBER", "bot cadence limit and memory must not be negative")
+	}
+
+	if c.Tarpit.Strikes < 0 {
+		v.fail("SHOUT_TARPIT_STRIKES", "tarpit strikes, size and clients must not be negative")
+	}
+	if c.Tarpit.MaxBytes < 0 {
+		v.fail("SHOUT_TARPIT_MAX_BYTES", "tarpit strikes, size and clients must not be negative")
+	}
+	if c.Tarpit.MaxClients < 0 {
+		v.fail("SHOUT_TARPIT_MAX_CLIENTS", "tarpit strikes, size and clients must not be negative")
+	}
+	if c.Tarpit.Strikes > 0 || c.Bots.Action == "tarpit" {
+		if c.Tarpit.Interval <= 0 {
+			v.fail("SHOUT_TARPIT_INTERVAL", "tarpit interval and window must be positive")
+		}
+		if c.Tarpit.Window <= 0 {
+			v.fail("SHOUT_TARPIT_WINDOW", "tarpit interval and window must be positive")
+		}
+	}
+
+	if c.GeoIP.Database == "" && len(c.GeoIP.Allow)+len(c.GeoIP.Deny)+len(c.GeoIP.RateLimits) > 0 {
+		v.fail("SHOUT_GEOIP_DATABASE", "country policies need SHOUT_GEOIP_DATABASE")
+	}
+	for _, code := range c.GeoIP.Allow {
+		if !validCountry(code) {
+			v.fail("SHOUT_GEOIP_ALLOW", "country %q is not a two-letter ISO code", code)
+		}
+	}
+	for _, code := range c.GeoIP.Deny {
+		if !validCountry(code) {
+			v.fail("SHOUT_GEOIP_DENY", "country %q is not a two-letter ISO code", code)
+		}
+	}
+	for _, entry := range c.GeoIP.RateLimits {
+		code, limit, ok := strings.Cut(entry, "=")
+		if n, err := strconv.Atoi(limit); !ok || !validCountry(code) || err != nil || n <= 0 {
+			v.fail("SHOUT_GEOIP_RATE_LIMITS", "country rate limit %q must be a country code, =, and requests per minute, e.g. CN=20", entry)
+		}
+	}
+
+	for _, entry := range c.Access.Allow {
+		if !validAddressRange(entry) {
+			v.fail("SHOUT_ACCESS_ALLOW", "access list entry %q is not an IP or CIDR range", entry)
+		}
+	}
+	for _, entry := range c.Access.Deny {
+		if !validAddressRange(entry) {
+			v.fail("SHOUT_ACCESS_DENY", "access list entry %q is not an IP or CIDR range", entry)
+		}
+	}
+
+	timeouts := []struct {
+		field   string
+		timeout time.Duration
+	}{
+		{"SHOUT_SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SHOUT_SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SHOUT_SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SHOUT_SERVER_STREAM_WRITE_TIMEOUT", c.Server.StreamWriteTimeout},
+	}
+	for _, t := range timeouts {
+		if t.timeout < 0 {
+			v.fail(t.field, "server timeouts must not be negative")
+		}
+	}
+
+	if c.Server.MaxURLBytes <= 0 {
+		v.fail("SHOUT_SERVER_MAX_URL_BYTES", "request size limits must be positive")
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		v.fail("SHOUT_SERVER_MAX_HEADER_BYTES", "request size limits must be positive")
+	}
+	if c.Server.MaxBodyBytes <= 0 {
+		v.fail("SHOUT_SERVER_MAX_BODY_BYTES", "request size limits must be positive")
+	}
+	if c.Server.MaxHeaderBytes > 0 && c.Server.MaxURLBytes > c.Server.MaxHeaderBytes {
+		v.fail("SHOUT_SERVER_MAX_URL_BYTES", "URL limit (%d) must not exceed the header limit (%d), which includes the URL",
+			c.Server.MaxURLBytes, c.Server.MaxHeaderBytes)
+	}
+
+	if c.Server.StaticTimeoutMS < 0 {
+		v.fail("SHOUT_SERVER_STATIC_TIMEOUT_MS", "static render timeout must not be negative")
+	}
+
+	if c.CORS.MaxAge < 0 {
+		v.fail("SHOUT_CORS_MAX_AGE", "CORS max age must not be negative")
 	}
 
 	// Validate rate limits
 	if c.RateLimit.RequestsPerMinute < 1 {
-		return fmt.Errorf("rate limit must be positive, got %d", c.RateLimit.RequestsPerMinute)
+		v.fail("SHOUT_RATELIMIT_REQUESTS_PER_MINUTE", "rate limit must be positive")
 	}
 	if c.RateLimit.Burst < 1 {
-		return fmt.Errorf("rate limit burst must be positive, got %d", c.RateLimit.Burst)
+		v.fail("SHOUT_RATELIMIT_BURST", "rate limit burst must be positive")
+	}
+	for _, entry := range c.RateLimit.ExemptIPs {
+		if !validAddressRange(entry) {
+			v.fail("SHOUT_RATELIMIT_EXEMPT_IPS", "rate limit exemption %q is not an IP or CIDR range", entry)
+		}
+	}
+	for _, entry := range c.RateLimit.Routes {
+		class, limit, ok := strings.Cut(entry, "=")
+		if n, err := strconv.Atoi(limit); !ok || !slices.Contains(RouteClasses, class) || err != nil || n <= 0 {
+			v.fail("SHOUT_RATELIMIT_ROUTES", "route rate limit %q must be a route class (%s), =, and requests per minute, e.g. party=10",
+				entry, strings.Join(RouteClasses, ", "))
+		}
 	}
 
 	// Validate text settings
 	if c.Text.MaxLength < 1 {
-		return fmt.Errorf("max text length must be positive, got %d", c.Text.MaxLength)
+		v.fail("SHOUT_TEXT_MAX_LENGTH", "max text length must be positive")
+	}
+
+	// Validate remote font settings
+	if c.Fonts.RemoteMaxBytes < 1 {
+		v.fail("SHOUT_FONTS_REMOTE_MAX_BYTES", "remote font size limit must be positive")
+	}
+	if c.Fonts.MemoryBudget < 0 {
+		v.fail("SHOUT_FONTS_MEMORY_BUDGET", "font memory budget must not be negative")
+	}
+	for _, entry := range c.Fonts.Warmup {
+		if font, text, ok := strings.Cut(entry, ":"); !ok || font == "" || text == "" {
+			v.fail("SHOUT_FONTS_WARMUP", "warmup banner %q must be a font, :, and text, e.g. doom:HELLO", entry)
+		}
+	}
+
+	// Validate overflow handling
+	if c.Text.Overflow != "reject" && c.Text.Overflow != "truncate" {
+		v.fail("SHOUT_TEXT_OVERFLOW", "invalid text overflow: must be reject or truncate")
+	}
+
+	// Validate word filter action
+	validFilters := map[string]bool{
+		"allow":  true,
+		"reject": true,
+		"mask":   true,
+	}
+	if !validFilters[c.Text.Filter] {
+		v.fail("SHOUT_TEXT_FILTER", "invalid text filter: must be allow, reject, or mask")
+	}
+
+	// Validate placeholders
+	validPlaceholders := map[string]bool{
+		"hostname": true,
+		"date":     true,
+		"time":     true,
+		"ip":       true,
+		"none":     true,
+	}
+	for _, name := range c.Text.Placeholders {
+		if !validPlaceholders[name] {
+			v.fail("SHOUT_TEXT_PLACEHOLDERS", "invalid placeholder %q: must be hostname, date, time, ip, or none", name)
+		}
+	}
+
+	presets := make(map[string]bool)
+	for _, entry := range c.Text.Presets {
+		name, query, _ := strings.Cut(entry, ":")
+		if !presetName.MatchString(name) {
+			v.fail("SHOUT_TEXT_PRESETS", "preset %q must be a name of lowercase letters, digits and dashes, :, and options, e.g. loud:f=doom&c=fire", entry)
+			continue
+		}
+		if presets[name] {
+			v.fail("SHOUT_TEXT_PRESETS", "preset %s is defined twice", name)
+		}
+		presets[name] = true
+		values, err := url.ParseQuery(query)
+		if err == nil {
+			var opts types.RenderOptions
+			if opts, err = types.ParseQueryOptions(values); err == nil {
+				err = opts.Validate(types.OptionRules{})
+			}
+		}
+		if err != nil {
+			v.fail("SHOUT_TEXT_PRESETS", "preset %s: %v", name, err)
+		}
+	}
+
+	themes := make(map[string]bool)
+	for _, entry := range c.Text.Themes {
+		theme, err := types.ParseTheme(entry)
+		if err != nil {
+			v.fail("SHOUT_TEXT_THEMES", "%v", err)
+			continue
+		}
+		if themes[theme.Name] {
+			v.fail("SHOUT_TEXT_THEMES", "theme %s is defined twice", theme.Name)
+		}
+		themes[theme.Name] = true
+	}
+
+	if utf8.RuneCountInString(c.Text.Footer) > maxFooter {
+		v.fail("SHOUT_TEXT_FOOTER", "footer is longer than %d characters", maxFooter)
+	}
+	// Footers are sent to terminals, which act on control characters
+	if strings.IndexFunc(c.Text.Footer, unicode.IsControl) >= 0 {
+		v.fail("SHOUT_TEXT_FOOTER", "footer must be a single line without control characters")
 	}
 
 	// Validate alignment
@@ -166,30 +1103,317 @@ func (c *Config) Validate() error {
 		"right":  true,
 	}
 	if !validAlignments[c.Text.DefaultAlign] {
-		return fmt.Errorf("invalid alignment: must be left, center, or right, got %s", c.Text.DefaultAlign)
+		v.fail("SHOUT_TEXT_DEFAULT_ALIGN", "invalid alignment: must be left, center, or right")
+	}
+	switch c.Text.DefaultColorMode {
+	case "", "char", "word", "line":
+	default:
+		v.fail("SHOUT_TEXT_DEFAULT_COLOR_MODE", "invalid color mode: must be char, word, or line")
+	}
+	if c.Text.DefaultMaxWidth < 0 || c.Text.DefaultMaxWidth > c.Terminal.MaxCols {
+		v.fail("SHOUT_TEXT_DEFAULT_MAX_WIDTH", "invalid default max width: must be between 0 and %d", c.Terminal.MaxCols)
+	}
+
+	// Validate short link settings
+	if c.Shortlinks.Enabled {
+		switch c.Shortlinks.Store {
+		case "memory":
+		case "redis":
+			if c.Shortlinks.RedisURL == "" {
+				v.fail("SHOUT_SHORTLINK_REDIS_URL", "short link store redis requires SHOUT_SHORTLINK_REDIS_URL")
+			}
+		default:
+			v.fail("SHOUT_SHORTLINK_STORE", "invalid short link store: must be memory or redis")
+		}
+		if c.Shortlinks.TTL <= 0 {
+			v.fail("SHOUT_SHORTLINK_TTL", "short link TTL must be positive")
+		}
+		if c.Shortlinks.CreatesPerMinute < 0 {
+			v.fail("SHOUT_SHORTLINK_CREATES_PER_MINUTE", "short link create limit must not be negative")
+		}
+	}
+
+	if c.Fortune.Width < 1 {
+		v.fail("SHOUT_FORTUNE_WIDTH", "fortune width must be positive")
+	}
+
+	if c.ANSI.MaxBytes < 1 {
+		v.fail("SHOUT_ANSI_MAX_BYTES", "ANSI art size limit must be positive")
+	}
+
+	// Validate GIF export settings
+	if c.GIF.Enabled {
+		if c.GIF.MaxFrames < 1 {
+			v.fail("SHOUT_GIF_MAX_FRAMES", "GIF max frames must be positive")
+		}
+		if c.GIF.MaxCols < 1 {
+			v.fail("SHOUT_GIF_MAX_COLS", "GIF screen size must be positive")
+		}
+		if c.GIF.MaxRows < 1 {
+			v.fail("SHOUT_GIF_MAX_ROWS", "GIF screen size must be positive")
+		}
+		if c.GIF.Scale < 1 || c.GIF.Scale > 4 {
+			v.fail("SHOUT_GIF_SCALE", "GIF scale must be between 1 and 4")
+		}
+		if c.GIF.PerMinute < 0 {
+			v.fail("SHOUT_GIF_PER_MINUTE", "GIF rate limit must not be negative")
+		}
+	}
+
+	// Validate terminal size settings
+	if c.Terminal.MaxCols < 1 {
+		v.fail("SHOUT_TERMINAL_MAX_COLS", "terminal max size must be positive")
+	}
+	if c.Terminal.MaxRows < 1 {
+		v.fail("SHOUT_TERMINAL_MAX_ROWS", "terminal max size must be positive")
+	}
+	if c.Terminal.Cols < 0 || c.Terminal.Cols > c.Terminal.MaxCols {
+		v.fail("SHOUT_TERMINAL_COLS", "terminal size must be between 0 and %d columns", c.Terminal.MaxCols)
+	}
+	if c.Terminal.Rows < 0 || c.Terminal.Rows > c.Terminal.MaxRows {
+		v.fail("SHOUT_TERMINAL_ROWS", "terminal size must be between 0 and %d rows", c.Terminal.MaxRows)
 	}
 
 	// Validate streaming settings
 	if c.Streaming.DefaultTimeout < 1 {
-		return fmt.Errorf("streaming timeout must be positive, got %d", c.Streaming.DefaultTimeout)
+		v.fail("SHOUT_STREAMING_DEFAULT_TIMEOUT", "streaming timeout must be positive")
 	}
 	if c.Streaming.MaxTimeout < c.Streaming.DefaultTimeout {
-		return fmt.Errorf("max timeout must be >= default timeout, got max=%d, default=%d",
-			c.Streaming.MaxTimeout, c.Streaming.DefaultTimeout)
+		v.fail("SHOUT_STREAMING_MAX_TIMEOUT", "max timeout must be >= default timeout (%d)", c.Streaming.DefaultTimeout)
+	}
+	if c.Streaming.MaxStreams < 1 {
+		v.fail("SHOUT_STREAMING_MAX_STREAMS", "max streams must be positive")
+	}
+	if c.Streaming.MaxStreamsPerIP < 0 {
+		v.fail("SHOUT_STREAMING_MAX_STREAMS_PER_IP", "max streams per IP must be 0 or more")
 	}
 	if c.Streaming.DefaultSpeed < 1 || c.Streaming.DefaultSpeed > 10 {
-		return fmt.Errorf("streaming speed must be between 1 and 10, got %d", c.Streaming.DefaultSpeed)
+		v.fail("SHOUT_STREAMING_DEFAULT_SPEED", "streaming speed must be between 1 and 10")
+	}
+	if c.Streaming.MinSpeed < 1 || c.Streaming.MinSpeed > 10 {
+		v.fail("SHOUT_STREAMING_MIN_SPEED", "streaming speed range must be within 1-10")
+	}
+	if c.Streaming.MaxSpeed < 1 || c.Streaming.MaxSpeed > 10 || c.Streaming.MinSpeed > c.Streaming.MaxSpeed {
+		v.fail("SHOUT_STREAMING_MAX_SPEED", "streaming speed range must be within 1-10, from SHOUT_STREAMING_MIN_SPEED (%d) up", c.Streaming.MinSpeed)
+	}
+	if c.Streaming.DefaultSpeed < c.Streaming.MinSpeed || c.Streaming.DefaultSpeed > c.Streaming.MaxSpeed {
+		v.fail("SHOUT_STREAMING_DEFAULT_SPEED", "default speed must be between min and max speed (%d-%d)", c.Streaming.MinSpeed, c.Streaming.MaxSpeed)
+	}
+	if c.Streaming.MaxFPS < 1 {
+		v.fail("SHOUT_STREAMING_MAX_FPS", "max fps must be positive")
+	}
+	if c.Streaming.DegradeStreams < 0 || c.Streaming.DegradeStreams > 100 {
+		v.fail("SHOUT_STREAMING_DEGRADE_STREAMS", "degrade thresholds must be between 0 and 100 percent")
+	}
+	if c.Streaming.DegradeCPU < 0 || c.Streaming.DegradeCPU > 100 {
+		v.fail("SHOUT_STREAMING_DEGRADE_CPU", "degrade thresholds must be between 0 and 100 percent")
+	}
+	if c.Streaming.DegradedFPS < 1 {
+		v.fail("SHOUT_STREAMING_DEGRADED_FPS", "degraded fps must be positive")
+	}
+
+	if c.Cache.Entries < 0 {
+		v.fail("SHOUT_CACHE_ENTRIES", "render cache size must not be negative")
+	}
+	if c.Cache.TTL <= 0 {
+		v.fail("SHOUT_CACHE_TTL", "render cache TTL must be positive")
+	}
+	if c.Cache.LockTimeout <= 0 {
+		v.fail("SHOUT_CACHE_LOCK_TIMEOUT", "render cache lock timeout must be positive")
 	}
 
+	if addr := c.Remote.ConsulAddr; addr != "" {
+		if u, err := url.Parse(addr); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			v.fail("SHOUT_REMOTE_CONSUL_ADDR", "Consul address must be an http or https URL")
+		}
+	}
+
+	if len(v.fields) > 0 {
+		return &ValidationError{Fields: v.fields}
+	}
+	return nil
+}
+
+// Reload reads the configuration again the way Load did at startup, so
+// changes to the .env file and the Consul KV prefix take effect; variables set in the process
+// environment still take precedence over the file. The new configuration
+// is validated and returned, and doesn't replace the one Get returns.
+//
+// Returns:
+//   - *Config: the configuration as it stands now
+//   - error: parsing or validation error, with the current configuration
+//     left in force
+//
+// Example:
+//
+//	cfg, err := config.Reload()
+//	if err != nil {
+//	    log.Printf("Keeping the current config: %v", err)
+//	}
+func Reload() (*Config, error) {
+	vars := environ()
+	for key := range fromDotenv {
+		delete(vars, key)
+	}
+	if file, err := godotenv.Read(); err == nil {
+		for key, value := range file {
+			if _, set := vars[key]; !set {
+				vars[key] = value
+			}
+		}
+	}
+	if err := readFileSettings(vars); err != nil {
+		return nil, err
+	}
+	if err := readRemoteSettings(vars); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := env.ParseWithOptions(cfg, env.Options{Environment: vars}); err != nil {
+		return nil, fmt.Errorf("failed to parse environment variables: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// environ returns the process environment as a map.
+func environ() map[string]string {
+	vars := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		vars[key] = value
+	}
+	return vars
+}
+
+// readFileSettings sets each setting that vars gives as a file, by the
+// setting's variable with _FILE appended, such as SHOUT_ASYNC_SECRET_FILE
+// for SHOUT_ASYNC_SECRET, to the file's contents without the trailing
+// newline. Secrets can then be mounted as files, such as Docker secrets,
+// rather than passed in the environment. Settings whose _FILE variable is
+// a setting of its own, such as SHOUT_TEXT_FILTER_FILE, can't be given
+// this way.
+//
+// Returns:
+//   - error: a file that can't be read, or a setting given both ways
+func readFileSettings(vars map[string]string) error {
+	settings := Describe()
+	names := make(map[string]bool, len(settings))
+	for _, setting := range settings {
+		names[setting.Env] = true
+	}
+	for _, setting := range settings {
+		fileVar := setting.Env + "_FILE"
+		path, ok := vars[fileVar]
+		if !ok || names[fileVar] {
+			continue
+		}
+		if _, set := vars[setting.Env]; set {
+			return fmt.Errorf("%s and %s can't both be set", setting.Env, fileVar)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileVar, err)
+		}
+		vars[setting.Env] = strings.TrimRight(string(data), "\r\n")
+	}
 	return nil
 }
 
+// dotenvOnly returns the variables in the .env file that the process
+// environment doesn't set.
+func dotenvOnly() map[string]bool {
+	keys := make(map[string]bool)
+	file, err := godotenv.Read()
+	if err != nil {
+		return keys
+	}
+	for key := range file {
+		if _, set := os.LookupEnv(key); !set {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// Settings returns every setting keyed by its environment variable, with
+// the value in effect after defaults. Secrets are redacted, as is the
+// password in a Redis URL.
+//
+// Returns:
+//   - map[string]string: the settings, such as "SHOUT_SERVER_PUBLIC_PORT": "8080"
+//
+// Example:
+//
+//	for name, value := range cfg.Settings() {
+//	    fmt.Printf("%s=%s\n", name, value)
+//	}
+func (c *Config) Settings() map[string]string {
+	settings := make(map[string]string)
+	addSettings(settings, "", reflect.ValueOf(c).Elem())
+	return settings
+}
+
+// addSettings adds the fields of struct v to settings, named by their env
+// tags after prefix.
+func addSettings(settings map[string]string, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field, value := t.Field(i), v.Field(i)
+		if nested, ok := field.Tag.Lookup("envPrefix"); ok {
+			addSettings(settings, prefix+nested, value)
+			continue
+		}
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		settings[prefix+name] = settingValue(value, field.Tag.Get("redact"), cmp.Or(field.Tag.Get("envSeparator"), ","))
+	}
+}
+
+// settingValue formats a setting as it would be written in the
+// environment, redacted as the field's redact tag says: "true" for the
+// whole value, "url" for a URL's password. Lists are joined with sep.
+func settingValue(v reflect.Value, redact, sep string) string {
+	var s string
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case []string:
+		if redact == "true" && len(value) > 0 {
+			return redacted
+		}
+		return strings.Join(value, sep)
+	case string:
+		s = value
+	default:
+		return fmt.Sprint(value)
+	}
+	switch {
+	case s == "":
+		return s
+	case redact == "true":
+		return redacted
+	case redact == "url":
+		if u, err := url.Parse(s); err == nil {
+			return u.Redacted()
+		}
+		return redacted
+	}
+	return s
+}
+
 // Reset resets the singleton instance (useful for testing).
 // This should only be used in tests.
 func Reset() {
 	once = sync.Once{}
 	instance = nil
 	loadErr = nil
+	fromDotenv = nil
 	// Also remove any .env file that might affect subsequent tests
 	_ = os.Remove(".env")
 }
@@ -227,3 +1451,40 @@ func LoadFromEnv(envVars map[string]string) (*Config, error) {
 
 	return cfg, err
 }
+
+// validCountry reports whether code is a two-letter country code.
+func validCountry(code string) bool {
+	code = strings.TrimSpace(code)
+	return len(code) == 2 && strings.IndexFunc(code, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+	}) < 0
+}
+
+// validAddressRange reports whether entry is an IP or CIDR range.
+func validAddressRange(entry string) bool {
+	if _, err := netip.ParsePrefix(strings.TrimSpace(entry)); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(strings.TrimSpace(entry))
+	return err == nil
+}
+
+// validDomain reports whether domain is a fully qualified host name, with
+// no scheme, port or wildcard.
+func validDomain(domain string) bool {
+	labels := strings.Split(domain, ".")
+	if len(domain) > 253 || len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		if strings.IndexFunc(label, func(r rune) bool {
+			return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-'
+		}) >= 0 {
+			return false
+		}
+	}
+	return true
+}
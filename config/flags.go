@@ -0,0 +1,74 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flag returns the command line flag for the setting: the environment
+// variable without SHOUT_, in lower case with dashes, such as
+// server-public-port.
+func (s Setting) Flag() string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(s.Env, "SHOUT_")), "_", "-")
+}
+
+// BindFlags adds a flag to fs for every setting, named by Setting.Flag.
+// A flag on the command line sets its environment variable as it's
+// parsed, so it takes precedence over the environment and the .env file
+// when Load runs, and still applies after a Reload.
+//
+// Parameters:
+//   - fs: the flag set the server parses its arguments with
+//
+// Example:
+//
+//	fs := flag.NewFlagSet("shout-sh", flag.ExitOnError)
+//	config.BindFlags(fs)
+//	fs.Parse(os.Args[1:]) // --server-public-port 8000 --fonts-path ./fonts
+//	cfg, err := config.Load()
+func BindFlags(fs *flag.FlagSet) {
+	for _, setting := range Describe() {
+		fs.Var(&settingFlag{setting: setting}, setting.Flag(), setting.Env)
+	}
+}
+
+// settingFlag is the flag.Value of a setting, which checks the value has
+// the setting's type and sets its environment variable.
+type settingFlag struct {
+	setting Setting
+}
+
+// String returns the setting's default, shown in the flag usage.
+func (f *settingFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.setting.Default
+}
+
+// Set checks value and sets the setting's environment variable to it.
+func (f *settingFlag) Set(value string) error {
+	var err error
+	switch f.setting.Type {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "duration":
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", value, f.setting.Type)
+	}
+	return os.Setenv(f.setting.Env, value)
+}
+
+// IsBoolFlag lets boolean settings be given without a value, as
+// --gopher-enabled.
+func (f *settingFlag) IsBoolFlag() bool {
+	return f.setting.Type == "bool"
+}
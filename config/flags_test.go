@@ -0,0 +1,110 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestSettingFlag(t *testing.T) {
+	tests := map[string]string{
+		"SHOUT_SERVER_PUBLIC_PORT":       "server-public-port",
+		"SHOUT_FONTS_PATH":               "fonts-path",
+		"SHOUT_VERSION":                  "version",
+		"SHOUT_STREAMING_DEFAULT_SPEED":  "streaming-default-speed",
+		"SHOUT_SERVER_STATIC_TIMEOUT_MS": "server-static-timeout-ms",
+	}
+	for env, want := range tests {
+		if got := (Setting{Env: env}).Flag(); got != want {
+			t.Errorf("Setting{Env: %q}.Flag() = %q, want %q", env, got, want)
+		}
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr bool
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "flags set settings",
+			args: []string{"--server-public-port", "8000", "--fonts-path=/srv/fonts", "--fonts-allowed", "doom,slant"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.PublicPort != 8000 || cfg.Fonts.Path != "/srv/fonts" || !slices.Equal(cfg.Fonts.Allowed, []string{"doom", "slant"}) {
+					t.Errorf("got port %d, fonts %s %v", cfg.Server.PublicPort, cfg.Fonts.Path, cfg.Fonts.Allowed)
+				}
+			},
+		},
+		{
+			name: "flags override the environment",
+			env:  map[string]string{"SHOUT_SERVER_PUBLIC_PORT": "3000", "SHOUT_SERVER_ADMIN_PORT": "3001"},
+			args: []string{"-server-public-port=8000"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.PublicPort != 8000 || cfg.Server.AdminPort != 3001 {
+					t.Errorf("got ports %d and %d, want 8000 and 3001", cfg.Server.PublicPort, cfg.Server.AdminPort)
+				}
+			},
+		},
+		{
+			name: "bool without a value",
+			args: []string{"--gopher-enabled", "--fonts-watch=false"},
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.Gopher.Enabled || cfg.Fonts.Watch {
+					t.Errorf("got gopher %v, watch %v", cfg.Gopher.Enabled, cfg.Fonts.Watch)
+				}
+			},
+		},
+		{
+			name:    "not an int",
+			args:    []string{"--server-public-port", "http"},
+			wantErr: true,
+		},
+		{
+			name:    "not a duration",
+			args:    []string{"--shortlink-ttl", "30"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"--public-port", "8000"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// t.Setenv restores every setting the flags change afterwards
+			for _, setting := range Describe() {
+				value, set := tt.env[setting.Env]
+				t.Setenv(setting.Env, value)
+				if !set {
+					os.Unsetenv(setting.Env)
+				}
+			}
+
+			fs := flag.NewFlagSet("shout-sh", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			BindFlags(fs)
+			err := fs.Parse(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check == nil {
+				return
+			}
+
+			Reset()
+			defer Reset()
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
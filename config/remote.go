@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+var (
+	// remoteClient reads the Consul KV prefix. Watches bound each request
+	// with remoteWait, so it has no timeout of its own.
+	remoteClient = &http.Client{}
+	// remoteTimeout bounds a read of the prefix by Load and Reload
+	remoteTimeout = 10 * time.Second
+	// remoteWait is how long Consul holds a watch's request open when
+	// nothing under the prefix changes
+	remoteWait = 5 * time.Minute
+	// remoteRetry is how long a watch waits after a failed request
+	remoteRetry = 10 * time.Second
+)
+
+// consulKey is a key of Consul's KV API, as ?recurse lists them.
+type consulKey struct {
+	Key string
+	// Value is the base64 encoded value, or null for folders
+	Value []byte
+}
+
+// readRemoteSettings adds the settings held under SHOUT_REMOTE_PREFIX in
+// Consul, when vars sets SHOUT_REMOTE_CONSUL_ADDR, to the ones vars
+// doesn't already set. Keys that aren't settings are ignored.
+//
+// Returns:
+//   - error: the prefix can't be read
+func readRemoteSettings(vars map[string]string) error {
+	remote, err := remoteConfig(vars)
+	if err != nil || remote.ConsulAddr == "" {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	settings, _, err := fetchRemote(ctx, remote, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read settings from Consul: %w", err)
+	}
+	for name, value := range settings {
+		if _, set := vars[name]; !set {
+			vars[name] = value
+		}
+	}
+	return nil
+}
+
+// remoteConfig parses the SHOUT_REMOTE_ settings out of vars, which
+// say where the rest are read from.
+func remoteConfig(vars map[string]string) (RemoteConfig, error) {
+	var remote RemoteConfig
+	err := env.ParseWithOptions(&remote, env.Options{Environment: vars, Prefix: "SHOUT_REMOTE_"})
+	if err != nil {
+		return remote, fmt.Errorf("failed to parse environment variables: %w", err)
+	}
+	return remote, nil
+}
+
+// fetchRemote lists the settings under the prefix. With a non-zero index
+// it's a Consul blocking query, which returns once the prefix changes
+// from index, or after remoteWait.
+//
+// Returns:
+//   - map[string]string: the settings, keyed by environment variable
+//   - uint64: the prefix's index, to wait on for the next change
+//   - error: Consul can't be reached or refused the request
+func fetchRemote(ctx context.Context, remote RemoteConfig, index uint64) (map[string]string, uint64, error) {
+	prefix := strings.TrimLeft(remote.Prefix, "/")
+	query := url.Values{"recurse": {"true"}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", remoteWait.String())
+	}
+	endpoint := strings.TrimRight(remote.ConsulAddr, "/") + "/v1/kv/" + prefix + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if remote.Token != "" {
+		req.Header.Set("X-Consul-Token", remote.Token)
+	}
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	// Consul answers 404 when nothing is under the prefix yet
+	var keys []consulKey
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+			return nil, 0, fmt.Errorf("invalid response from %s: %w", remote.ConsulAddr, err)
+		}
+	case http.StatusNotFound:
+	default:
+		return nil, 0, fmt.Errorf("%s answered %s", remote.ConsulAddr, resp.Status)
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	names := make(map[string]bool)
+	for _, setting := range Describe() {
+		names[setting.Env] = true
+	}
+	settings := make(map[string]string)
+	for _, key := range keys {
+		name := strings.TrimPrefix(key.Key, prefix)
+		if names[name] && key.Value != nil {
+			settings[name] = strings.TrimRight(string(key.Value), "\r\n")
+		}
+	}
+	return settings, next, nil
+}
+
+// WatchRemote calls onChange whenever a key under the Consul KV prefix
+// changes, until ctx is cancelled. onChange is expected to Reload, which
+// reads the prefix again. Requests that fail are logged and retried, so a
+// Consul outage leaves the settings read last in force.
+//
+// Parameters:
+//   - ctx: stops the watch when cancelled
+//   - remote: the Consul address, prefix and token read at startup
+//   - onChange: called after each change
+//
+// Example:
+//
+//	go config.WatchRemote(ctx, cfg.Remote, func() { h.Reconfigure() })
+func WatchRemote(ctx context.Context, remote RemoteConfig, onChange func()) {
+	if remote.ConsulAddr == "" {
+		return
+	}
+	var index uint64
+	for {
+		_, next, err := fetchRemote(ctx, remote, index)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && next == 0 {
+			err = fmt.Errorf("%s answered without X-Consul-Index", remote.ConsulAddr)
+		}
+		if err != nil {
+			log.Printf("Warning: reading settings from Consul failed: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(remoteRetry):
+			}
+			continue
+		}
+
+		// The index can also go backwards, as after a Consul snapshot
+		// restore, which is a change too
+		if index > 0 && next != index {
+			onChange()
+		}
+		index = next
+	}
+}
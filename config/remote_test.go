@@ -0,0 +1,190 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConsul serves a KV prefix the way Consul's HTTP API does, with
+// blocking queries that return when set changes a key.
+type fakeConsul struct {
+	mu      sync.Mutex
+	keys    map[string]string
+	index   uint64
+	changed chan struct{}
+	token   string
+}
+
+func newFakeConsul(t *testing.T, keys map[string]string) (*fakeConsul, *httptest.Server) {
+	t.Helper()
+	fake := &fakeConsul{keys: keys, index: 1, changed: make(chan struct{})}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeConsul) set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[key] = value
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	if f.token != "" && r.Header.Get("X-Consul-Token") != f.token {
+		f.mu.Unlock()
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index == f.index {
+		changed := f.changed
+		f.mu.Unlock()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		f.mu.Lock()
+	}
+	defer f.mu.Unlock()
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	var keys []consulKey
+	for key, value := range f.keys {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, consulKey{Key: key, Value: []byte(value)})
+		}
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(keys)
+}
+
+func TestConfig_RemoteSettings(t *testing.T) {
+	fake, server := newFakeConsul(t, map[string]string{
+		"shout/SHOUT_RATELIMIT_REQUESTS_PER_MINUTE": "120",
+		"shout/SHOUT_TEXT_MAX_LENGTH":               "40",
+		"shout/SHOUT_NOT_A_SETTING":                 "ignored",
+		"other/SHOUT_FONTS_DEFAULT":                 "small",
+	})
+	fake.token = "t0ken"
+
+	tests := []struct {
+		name    string
+		envVars map[string]string
+		wantErr string
+		check   func(t *testing.T, cfg *Config)
+	}{
+		{
+			name:    "settings under the prefix",
+			envVars: map[string]string{"SHOUT_REMOTE_CONSUL_ADDR": server.URL, "SHOUT_REMOTE_TOKEN": "t0ken"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.RateLimit.RequestsPerMinute != 120 || cfg.Text.MaxLength != 40 {
+					t.Errorf("RequestsPerMinute = %d, MaxLength = %d, want 120 and 40 from Consul", cfg.RateLimit.RequestsPerMinute, cfg.Text.MaxLength)
+				}
+				if cfg.Fonts.Default != "standard" {
+					t.Errorf("Fonts.Default = %q, want the default, as the key is outside the prefix", cfg.Fonts.Default)
+				}
+			},
+		},
+		{
+			name:    "environment takes precedence",
+			envVars: map[string]string{"SHOUT_REMOTE_CONSUL_ADDR": server.URL, "SHOUT_REMOTE_TOKEN": "t0ken", "SHOUT_TEXT_MAX_LENGTH": "25"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Text.MaxLength != 25 || cfg.RateLimit.RequestsPerMinute != 120 {
+					t.Errorf("MaxLength = %d, RequestsPerMinute = %d, want 25 from the environment and 120 from Consul", cfg.Text.MaxLength, cfg.RateLimit.RequestsPerMinute)
+				}
+			},
+		},
+		{
+			name:    "another prefix",
+			envVars: map[string]string{"SHOUT_REMOTE_CONSUL_ADDR": server.URL, "SHOUT_REMOTE_TOKEN": "t0ken", "SHOUT_REMOTE_PREFIX": "other/"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Fonts.Default != "small" || cfg.Text.MaxLength != 100 {
+					t.Errorf("Fonts.Default = %q, MaxLength = %d, want small and the default 100", cfg.Fonts.Default, cfg.Text.MaxLength)
+				}
+			},
+		},
+		{
+			name:    "empty prefix",
+			envVars: map[string]string{"SHOUT_REMOTE_CONSUL_ADDR": server.URL, "SHOUT_REMOTE_TOKEN": "t0ken", "SHOUT_REMOTE_PREFIX": "empty/"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Text.MaxLength != 100 {
+					t.Errorf("MaxLength = %d, want the default 100", cfg.Text.MaxLength)
+				}
+			},
+		},
+		{
+			name:    "refused",
+			envVars: map[string]string{"SHOUT_REMOTE_CONSUL_ADDR": server.URL},
+			wantErr: "failed to read settings from Consul",
+		},
+		{
+			name:    "invalid address",
+			envVars: map[string]string{"SHOUT_REMOTE_CONSUL_ADDR": "consul:8500", "SHOUT_REMOTE_PREFIX": "empty/"},
+			wantErr: "failed to read settings from Consul",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer Reset()
+			cfg, err := LoadFromEnv(tt.envVars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadFromEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromEnv() unexpected error: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestWatchRemote(t *testing.T) {
+	fake, server := newFakeConsul(t, map[string]string{"shout/SHOUT_TEXT_MAX_LENGTH": "40"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{}, 1)
+	done := make(chan struct{})
+	go func() {
+		WatchRemote(ctx, RemoteConfig{ConsulAddr: server.URL, Prefix: "shout/"}, func() { changes <- struct{}{} })
+		close(done)
+	}()
+
+	select {
+	case <-changes:
+		t.Fatal("onChange called before anything changed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	fake.set("shout/SHOUT_TEXT_MAX_LENGTH", "50")
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("onChange not called after a change")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchRemote didn't stop with its context")
+	}
+}
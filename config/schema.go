@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Setting describes one setting of Config, from its struct tags.
+type Setting struct {
+	// Env is the environment variable, such as SHOUT_SERVER_PUBLIC_PORT
+	Env string `json:"name"`
+	// Type is the kind of value: string, int, bool, duration or list
+	Type string `json:"type"`
+	// Default is the value when the variable isn't set, as written in the
+	// environment
+	Default string `json:"default"`
+	// Description says what the setting does, from its desc tag
+	Description string `json:"description"`
+}
+
+// Describe returns every setting in Config, in the order the struct
+// declares them.
+//
+// Returns:
+//   - []Setting: the settings, such as {Env: "SHOUT_SERVER_PUBLIC_PORT",
+//     Type: "int", Default: "8080", Description: "Public API port"}
+func Describe() []Setting {
+	var settings []Setting
+	describeFields(&settings, "", reflect.TypeFor[Config]())
+	return settings
+}
+
+// describeFields adds the fields of struct t to settings, named by their
+// env tags after prefix.
+func describeFields(settings *[]Setting, prefix string, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if nested, ok := field.Tag.Lookup("envPrefix"); ok {
+			describeFields(settings, prefix+nested, field.Type)
+			continue
+		}
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		*settings = append(*settings, Setting{
+			Env:         prefix + name,
+			Type:        settingType(field.Type),
+			Default:     field.Tag.Get("envDefault"),
+			Description: field.Tag.Get("desc"),
+		})
+	}
+}
+
+// settingType names the kind of value a field of type t takes.
+func settingType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return "list"
+	case t.Kind() == reflect.Bool:
+		return "bool"
+	case t.Kind() == reflect.Int, t.Kind() == reflect.Int64:
+		return "int"
+	default:
+		return "string"
+	}
+}
+
+// WriteSchema writes every setting as a JSON array of objects with its
+// name, type, default and description, as Describe returns them.
+//
+// Parameters:
+//   - w: where to write the schema
+//
+// Returns:
+//   - error: any error writing to w
+func WriteSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Describe())
+}
+
+// WriteExample writes a YAML mapping of every setting to its default,
+// each under a comment with its description and type, to be edited and
+// used as the environment of a Docker Compose service or the data of a
+// Kubernetes ConfigMap.
+//
+// Parameters:
+//   - w: where to write the example
+//
+// Returns:
+//   - error: any error writing to w
+//
+// Example:
+//
+//	# Public API port (int)
+//	SHOUT_SERVER_PUBLIC_PORT: "8080"
+func WriteExample(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "# shout.sh settings, at their defaults"); err != nil {
+		return err
+	}
+	for _, setting := range Describe() {
+		// A JSON string is a valid YAML double-quoted scalar
+		value, err := json.Marshal(setting.Default)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "\n# %s (%s)\n%s: %s\n", setting.Description, setting.Type, setting.Env, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	settings := Describe()
+	if len(settings) != len((&Config{}).Settings()) {
+		t.Errorf("Describe() has %d settings, Settings() has %d", len(settings), len((&Config{}).Settings()))
+	}
+	for _, setting := range settings {
+		if setting.Description == "" {
+			t.Errorf("%s has no desc tag", setting.Env)
+		}
+	}
+
+	tests := []Setting{
+		{Env: "SHOUT_SERVER_PUBLIC_PORT", Type: "int", Default: "8080"},
+		{Env: "SHOUT_FONTS_ALLOWED", Type: "list", Default: "standard,doom,banner,slant,3d,speed,starwars,small"},
+		{Env: "SHOUT_GOPHER_ENABLED", Type: "bool", Default: "false"},
+		{Env: "SHOUT_SHORTLINK_TTL", Type: "duration", Default: "720h"},
+		{Env: "SHOUT_VHOSTS_FILE", Type: "string"},
+	}
+	for _, want := range tests {
+		found := false
+		for _, setting := range settings {
+			if setting.Env == want.Env {
+				found = true
+				setting.Description = ""
+				if setting != want {
+					t.Errorf("Describe() has %+v, want %+v", setting, want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Describe() lacks %s", want.Env)
+		}
+	}
+}
+
+func TestWriteSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSchema(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var schema []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("schema isn't a JSON array of objects: %v", err)
+	}
+	if len(schema) != len(Describe()) {
+		t.Fatalf("schema has %d settings, want %d", len(schema), len(Describe()))
+	}
+	want := map[string]string{"name": "SHOUT_SERVER_PUBLIC_PORT", "type": "int", "default": "8080", "description": "Public API port"}
+	for key, value := range want {
+		if schema[1][key] != value {
+			t.Errorf("schema[1][%q] = %q, want %q", key, schema[1][key], value)
+		}
+	}
+}
+
+func TestWriteExample(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteExample(&buf); err != nil {
+		t.Fatal(err)
+	}
+	example := buf.String()
+
+	for _, want := range []string{
+		"\n# Public API port (int)\nSHOUT_SERVER_PUBLIC_PORT: \"8080\"\n",
+		"\nSHOUT_SERVER_CACHE_CONTROL: \"public, max-age=86400\"\n",
+		"\nSHOUT_STREAMING_COUNTDOWN_MESSAGE: \"TIME'S UP\"\n",
+		"\nSHOUT_VHOSTS_FILE: \"\"\n",
+	} {
+		if !strings.Contains(example, want) {
+			t.Errorf("example lacks %q", want)
+		}
+	}
+	if got := strings.Count(example, "\nSHOUT_"); got != len(Describe()) {
+		t.Errorf("example has %d settings, want %d", got, len(Describe()))
+	}
+}
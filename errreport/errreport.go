@@ -0,0 +1,303 @@
+// Package errreport sends panics and server errors to Sentry, to a
+// webhook as JSON, or both, so operators of public instances hear about
+// crashes. Reports go out in the background and are dropped when too many
+// are waiting, so a slow or failing destination never holds up requests.
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Report levels: panics are fatal, 5xx responses errors.
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// queueSize bounds the reports waiting to be sent.
+const queueSize = 64
+
+// sendTimeout bounds how long one report may take to send.
+const sendTimeout = 10 * time.Second
+
+// Report is one panic or server error.
+type Report struct {
+	Time time.Time `json:"time"`
+	// Level is LevelFatal for panics and LevelError otherwise
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	// Stack is the stack trace of a panic
+	Stack string `json:"stack,omitempty"`
+	// Request is the request that failed, if any
+	Request *Request `json:"request,omitempty"`
+}
+
+// Request is the context of a failed request.
+type Request struct {
+	Method string `json:"method"`
+	// URL is the request URL, with API keys and signatures redacted
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	// Code is the error code of the response, such as render_failed
+	Code      string `json:"code,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// Reporter sends reports in the background. A nil Reporter reports
+// nothing, so callers needn't check whether reporting is on.
+//
+// Usage example:
+//
+//	reporter, err := errreport.Open(cfg.Errors, cfg.Version)
+//	defer reporter.Close(ctx)
+//	reporter.Report(errreport.Report{Level: errreport.LevelError, Message: "render failed"})
+type Reporter struct {
+	sentry      *sentryProject
+	webhook     string
+	environment string
+	release     string
+	client      *http.Client
+
+	queue chan Report
+	done  chan struct{}
+	// mu guards closed, so Report never sends on the queue Close closes
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Int64
+}
+
+// sentryProject is where a Sentry DSN sends events.
+type sentryProject struct {
+	storeURL string
+	auth     string
+}
+
+// Open creates the reporter selected by the configuration and starts
+// sending in the background.
+//
+// Parameters:
+//   - cfg: Sentry DSN, webhook and environment
+//   - release: the version reports are tagged with
+//
+// Returns:
+//   - *Reporter: the reporter, or nil when reporting is off
+//   - error: error if the Sentry DSN is malformed
+//
+// Example:
+//
+//	reporter, err := errreport.Open(cfg.Errors, cfg.Version)
+//	if err != nil {
+//	    log.Fatalf("Failed to set up error reporting: %v", err)
+//	}
+func Open(cfg config.ErrorsConfig, release string) (*Reporter, error) {
+	if cfg.SentryDSN == "" && cfg.Webhook == "" {
+		return nil, nil
+	}
+	r := &Reporter{
+		webhook:     cfg.Webhook,
+		environment: cfg.Environment,
+		release:     release,
+		client:      &http.Client{Timeout: sendTimeout},
+		queue:       make(chan Report, queueSize),
+		done:        make(chan struct{}),
+	}
+	if cfg.SentryDSN != "" {
+		project, err := parseDSN(cfg.SentryDSN)
+		if err != nil {
+			return nil, err
+		}
+		r.sentry = project
+	}
+	go r.run()
+	return r, nil
+}
+
+// parseDSN turns a DSN such as https://key@o1.ingest.sentry.io/42 into
+// the project's store endpoint and auth header.
+func parseDSN(dsn string) (*sentryProject, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN")
+	}
+	dir, project := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if project == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: no project ID")
+	}
+	auth := "Sentry sentry_version=7, sentry_client=shout.sh/1, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	store := url.URL{Scheme: u.Scheme, Host: u.Host, Path: dir + "api/" + project + "/store/"}
+	return &sentryProject{storeURL: store.String(), auth: auth}, nil
+}
+
+// Report queues rep to be sent, stamping its time if unset. When the
+// queue is full, or the reporter is closed, the report is dropped and
+// logged.
+//
+// Parameters:
+//   - rep: the panic or error
+func (r *Reporter) Report(rep Report) {
+	if r == nil {
+		return
+	}
+	if rep.Time.IsZero() {
+		rep.Time = time.Now()
+	}
+	if rep.Level == "" {
+		rep.Level = LevelError
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		log.Printf("Error report dropped after shutdown: %s", rep.Message)
+		return
+	}
+	select {
+	case r.queue <- rep:
+	default:
+		r.dropped.Add(1)
+		log.Printf("Error report dropped, %d waiting: %s", queueSize, rep.Message)
+	}
+}
+
+// Dropped returns how many reports were dropped because the queue was
+// full.
+func (r *Reporter) Dropped() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.dropped.Load()
+}
+
+// Close stops taking reports and waits for those queued to be sent, or
+// for ctx to be done.
+//
+// Parameters:
+//   - ctx: bounds how long to wait
+//
+// Returns:
+//   - error: ctx.Err() if reports were still waiting when ctx was done
+func (r *Reporter) Close(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run sends queued reports until the queue is closed.
+func (r *Reporter) run() {
+	defer close(r.done)
+	for rep := range r.queue {
+		if r.sentry != nil {
+			if err := r.post(r.sentry.storeURL, r.sentryEvent(rep), r.sentry.auth); err != nil {
+				log.Printf("Error reporting to Sentry: %v", err)
+			}
+		}
+		if r.webhook != "" {
+			if err := r.post(r.webhook, r.webhookBody(rep), ""); err != nil {
+				log.Printf("Error reporting to webhook: %v", err)
+			}
+		}
+	}
+}
+
+// post sends body as JSON to target, with the Sentry auth header if
+// given.
+func (r *Reporter) post(target string, body any, auth string) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth != "" {
+		req.Header.Set("X-Sentry-Auth", auth)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// webhookBody is rep as the webhook receives it, with the environment
+// and release.
+func (r *Reporter) webhookBody(rep Report) any {
+	return struct {
+		Report
+		Environment string `json:"environment,omitempty"`
+		Release     string `json:"release,omitempty"`
+	}{rep, r.environment, r.release}
+}
+
+// sentryEvent is rep as a Sentry event.
+func (r *Reporter) sentryEvent(rep Report) map[string]any {
+	event := map[string]any{
+		"event_id":    eventID(),
+		"timestamp":   rep.Time.UTC().Format(time.RFC3339Nano),
+		"level":       rep.Level,
+		"platform":    "go",
+		"logger":      "shout.sh",
+		"message":     map[string]string{"formatted": rep.Message},
+		"environment": r.environment,
+		"release":     r.release,
+	}
+	if rep.Stack != "" {
+		event["extra"] = map[string]string{"stack": rep.Stack}
+	}
+	if req := rep.Request; req != nil {
+		event["request"] = map[string]any{
+			"method":  req.Method,
+			"url":     req.URL,
+			"headers": map[string]string{"User-Agent": req.UserAgent},
+		}
+		tags := map[string]string{"status": strconv.Itoa(req.Status)}
+		if req.Code != "" {
+			tags["code"] = req.Code
+		}
+		event["tags"] = tags
+	}
+	return event
+}
+
+// eventID returns a random Sentry event ID: 32 hex digits.
+func eventID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,11 @@
+// Package fonts bundles the core FIGlet fonts into the binary so the service
+// can render banners even when no fonts directory is present on disk.
+package fonts
+
+import "embed"
+
+// FS holds the bundled .flf font files, addressed by file name
+// (e.g. "standard.flf").
+//
+//go:embed *.flf
+var FS embed.FS
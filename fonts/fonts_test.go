@@ -116,3 +116,29 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// TestEmbeddedFonts verifies the core fonts are bundled into the binary
+func TestEmbeddedFonts(t *testing.T) {
+	fontNames := []string{
+		"doom.flf",
+		"3d.flf",
+		"big.flf",
+		"bloody.flf",
+		"standard.flf",
+		"slant.flf",
+		"small.flf",
+		"shadow.flf",
+	}
+
+	for _, fontName := range fontNames {
+		t.Run(fontName, func(t *testing.T) {
+			data, err := FS.ReadFile(fontName)
+			if err != nil {
+				t.Fatalf("Font %s is not embedded: %v", fontName, err)
+			}
+			if !strings.HasPrefix(string(data), "flf2a") {
+				t.Errorf("Embedded font %s does not have a FIGlet header", fontName)
+			}
+		})
+	}
+}
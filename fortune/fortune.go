@@ -0,0 +1,165 @@
+// Package fortune loads fortune databases, the classic Unix format of
+// quotes separated by lines holding a single "%", and picks quotes from
+// them for the /fortune endpoint.
+package fortune
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+)
+
+// bundled is the fortune database built into the binary.
+//
+//go:embed fortunes.txt
+var bundled string
+
+// Database is a parsed fortune database. It is read-only after loading and
+// safe for concurrent use.
+type Database struct {
+	quotes []string
+}
+
+// Load reads a fortune database from a file, or returns the bundled
+// database when path is empty.
+//
+// Parameters:
+//   - path: the fortune file, or "" for the bundled quotes
+//
+// Returns:
+//   - *Database: the loaded quotes
+//   - error: error if the file cannot be read or holds no quotes
+//
+// Example:
+//
+//	db, err := fortune.Load(cfg.Fortune.Path)
+//	if err != nil {
+//	    log.Fatalf("Failed to load fortunes: %v", err)
+//	}
+func Load(path string) (*Database, error) {
+	if path == "" {
+		return Bundled(), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fortune file: %w", err)
+	}
+	defer file.Close()
+
+	db, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Bundled returns the fortune database built into the binary.
+//
+// Returns:
+//   - *Database: the bundled quotes
+func Bundled() *Database {
+	db, err := Parse(strings.NewReader(bundled))
+	if err != nil {
+		panic(fmt.Sprintf("bundled fortunes are invalid: %v", err))
+	}
+	return db
+}
+
+// Parse reads quotes in fortune format: each quote is one or more lines,
+// and quotes are separated by a line containing only "%". Blank quotes are
+// skipped.
+//
+// Parameters:
+//   - r: the fortune data
+//
+// Returns:
+//   - *Database: the parsed quotes
+//   - error: error if reading fails or there are no quotes
+//
+// Example:
+//
+//	db, err := fortune.Parse(strings.NewReader("Hello\n%\nWorld\n"))
+//	// db.Len() == 2
+func Parse(r io.Reader) (*Database, error) {
+	db := &Database{}
+	var quote []string
+	flush := func() {
+		if text := strings.Trim(strings.Join(quote, "\n"), "\n"); strings.TrimSpace(text) != "" {
+			db.quotes = append(db.quotes, text)
+		}
+		quote = quote[:0]
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "%" {
+			flush()
+			continue
+		}
+		quote = append(quote, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fortunes: %w", err)
+	}
+	flush()
+
+	if len(db.quotes) == 0 {
+		return nil, fmt.Errorf("no fortunes found")
+	}
+	return db, nil
+}
+
+// Len returns the number of quotes in the database.
+func (d *Database) Len() int {
+	return len(d.quotes)
+}
+
+// Random returns a quote picked at random.
+//
+// Example:
+//
+//	fmt.Println(db.Random())
+func (d *Database) Random() string {
+	return d.quotes[rand.IntN(len(d.quotes))]
+}
+
+// Pick returns a quote picked with r, so a seeded source picks the same
+// quote every time.
+//
+// Parameters:
+//   - r: the random source
+//
+// Returns:
+//   - string: the quote
+//
+// Example:
+//
+//	quote := db.Pick(rand.New(rand.NewPCG(42, 0)))
+func (d *Database) Pick(r *rand.Rand) string {
+	return d.quotes[r.IntN(len(d.quotes))]
+}
+
+// Daily returns the quote of the day for t: the same quote all day in t's
+// location, moving on to the next quote at midnight.
+//
+// Parameters:
+//   - t: the current time, in the location whose days count
+//
+// Returns:
+//   - string: the quote of the day
+//
+// Example:
+//
+//	quote := db.Daily(time.Now())
+func (d *Database) Daily(t time.Time) string {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	n := int64(len(d.quotes))
+	return d.quotes[(day%n+n)%n]
+}
@@ -0,0 +1,108 @@
+package fortune
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single quote", input: "Hello\n", want: []string{"Hello"}},
+		{name: "separated", input: "One\n%\nTwo\n%\n", want: []string{"One", "Two"}},
+		{name: "multi-line with attribution", input: "Be brief.\n\t-- Anon\n%\nNext\n", want: []string{"Be brief.\n\t-- Anon", "Next"}},
+		{name: "skips blank quotes", input: "%\n\n%\nOnly\n%\n   \n", want: []string{"Only"}},
+		{name: "windows line endings", input: "One\r\n%\r\nTwo\r\n", want: []string{"One", "Two"}},
+		{name: "empty", input: "", wantErr: true},
+		{name: "only separators", input: "%\n%\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Parse(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(db.quotes, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("quotes = %q, want %q", db.quotes, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	db, err := Load("")
+	if err != nil || db.Len() == 0 {
+		t.Fatalf("Load(\"\") = %v, %v; want the bundled quotes", db, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "quotes")
+	if err := os.WriteFile(path, []byte("A\n%\nB\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	db, err = Load(path)
+	if err != nil || db.Len() != 2 {
+		t.Fatalf("Load(%q) = %v, %v; want 2 quotes", path, db, err)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestRandom(t *testing.T) {
+	db, _ := Parse(strings.NewReader("A\n%\nB\n%\nC\n"))
+	seen := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		seen[db.Random()] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Random picked %v, want all of A, B and C", seen)
+	}
+}
+
+func TestPick(t *testing.T) {
+	db, _ := Parse(strings.NewReader("A\n%\nB\n%\nC\n"))
+	for seed := uint64(0); seed < 20; seed++ {
+		a := db.Pick(rand.New(rand.NewPCG(seed, 0)))
+		b := db.Pick(rand.New(rand.NewPCG(seed, 0)))
+		if a != b {
+			t.Fatalf("seed %d picked %q then %q", seed, a, b)
+		}
+	}
+}
+
+func TestDaily(t *testing.T) {
+	db, _ := Parse(strings.NewReader("A\n%\nB\n%\nC\n"))
+
+	morning := time.Date(2024, 3, 5, 0, 1, 0, 0, time.UTC)
+	evening := time.Date(2024, 3, 5, 23, 59, 0, 0, time.UTC)
+	if db.Daily(morning) != db.Daily(evening) {
+		t.Error("quote changed within a day")
+	}
+
+	seen := map[string]bool{}
+	for day := 0; day < 3; day++ {
+		seen[db.Daily(morning.AddDate(0, 0, day))] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("three days gave %d different quotes, want 3", len(seen))
+	}
+
+	// Days count in the given location, not UTC
+	tokyo := time.FixedZone("JST", 9*3600)
+	if db.Daily(time.Date(2024, 3, 5, 23, 0, 0, 0, time.UTC).In(tokyo)) != db.Daily(time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC)) {
+		t.Error("quote of the day should follow the local date")
+	}
+}
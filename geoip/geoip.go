@@ -0,0 +1,269 @@
+// Package geoip looks up the country of client IPs in a MaxMind DB file,
+// such as GeoLite2-Country, and applies the per-country policy of
+// SHOUT_GEOIP_*: refusing countries and giving them their own rate limits.
+package geoip
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Unknown stands in for the country of IPs the database doesn't place,
+// such as private ranges, in metrics.
+const Unknown = "unknown"
+
+// DB is a MaxMind DB file loaded into memory. It is safe for concurrent
+// use.
+//
+// Usage example:
+//
+//	db, err := geoip.Open("/var/lib/GeoIP/GeoLite2-Country.mmdb")
+//	country, err := db.Country(netip.MustParseAddr("203.0.113.7"))
+type DB struct {
+	r *reader
+}
+
+// Open loads the MaxMind DB file at path. Any database whose records have
+// a country, or failing that a registered_country, with an iso_code
+// works: MaxMind's Country and City databases, and DB-IP's lite ones.
+//
+// Parameters:
+//   - path: the .mmdb file
+//
+// Returns:
+//   - *DB: the database
+//   - error: error if the file can't be read or isn't a MaxMind DB
+//
+// Example:
+//
+//	db, err := geoip.Open(cfg.GeoIP.Database)
+//	if err != nil {
+//	    log.Fatalf("Failed to load GeoIP database: %v", err)
+//	}
+func Open(path string) (*DB, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+	r, err := newReader(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GeoIP database %s: %w", path, err)
+	}
+	return &DB{r: r}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country ip is in,
+// such as "DE", or "" if the database doesn't place it.
+//
+// Parameters:
+//   - ip: the address to look up
+//
+// Returns:
+//   - string: the upper case country code, or ""
+//   - error: error if the database is corrupt
+func (db *DB) Country(ip netip.Addr) (string, error) {
+	record, err := db.r.lookup(ip)
+	if err != nil {
+		return "", err
+	}
+	fields, _ := record.(map[string]any)
+	for _, name := range []string{"country", "registered_country"} {
+		country, _ := fields[name].(map[string]any)
+		if code, ok := country["iso_code"].(string); ok && code != "" {
+			return strings.ToUpper(code), nil
+		}
+	}
+	return "", nil
+}
+
+// Write writes a MaxMind DB placing each network in networks in the
+// country given by its ISO code, in the layout of GeoLite2-Country. It
+// suits tests and private deployments that map their own ranges; use
+// MaxMind's databases for the internet at large. Where networks overlap,
+// the most specific wins.
+//
+// Parameters:
+//   - w: where the database is written
+//   - networks: country codes by network; IPv4 networks are placed in the
+//     IPv4-mapped part of the IPv6 tree, as MaxMind does
+//
+// Returns:
+//   - error: error if writing fails
+//
+// Example:
+//
+//	f, _ := os.Create("office.mmdb")
+//	err := geoip.Write(f, map[netip.Prefix]string{netip.MustParsePrefix("10.0.0.0/8"): "GB"})
+func Write(w io.Writer, networks map[netip.Prefix]string) error {
+	// Shorter prefixes go in first so longer ones split their leaves
+	prefixes := make([]netip.Prefix, 0, len(networks))
+	for p := range networks {
+		prefixes = append(prefixes, p.Masked())
+	}
+	slices.SortFunc(prefixes, func(a, b netip.Prefix) int { return cmp.Compare(a.Bits(), b.Bits()) })
+
+	root := &trieNode{}
+	var data encoder
+	offsets := make(map[string]int)
+	for _, p := range prefixes {
+		code := strings.ToUpper(networks[p])
+		if _, ok := offsets[code]; !ok {
+			offsets[code] = data.Len()
+			data.writeMap(1)
+			data.writeString("country")
+			data.writeMap(1)
+			data.writeString("iso_code")
+			data.writeString(code)
+		}
+		addr, bits := p.Addr().As16(), p.Bits()
+		if p.Addr().Is4() {
+			// ::a.b.c.d, not the ::ffff:a.b.c.d of As16
+			v4 := p.Addr().As4()
+			addr = [16]byte{}
+			copy(addr[12:], v4[:])
+			bits += 96
+		}
+		root.insert(addr, bits, offsets[code])
+	}
+
+	// Number the inner nodes breadth first, the root being node 0
+	nodes := []*trieNode{root}
+	for i := 0; i < len(nodes); i++ {
+		for _, child := range nodes[i].children {
+			if child != nil && child.leaf < 0 {
+				child.index = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+	}
+	var out bytes.Buffer
+	for _, n := range nodes {
+		for _, child := range n.children {
+			record := len(nodes)
+			switch {
+			case child == nil:
+			case child.leaf >= 0:
+				record = len(nodes) + dataSeparator + child.leaf
+			default:
+				record = child.index
+			}
+			_ = binary.Write(&out, binary.BigEndian, uint32(record))
+		}
+	}
+	out.Write(make([]byte, dataSeparator))
+	out.Write(data.Bytes())
+
+	out.Write(metadataMarker)
+	var meta encoder
+	meta.writeMap(8)
+	meta.writeString("binary_format_major_version")
+	meta.writeUint(typeUint16, 2)
+	meta.writeString("binary_format_minor_version")
+	meta.writeUint(typeUint16, 0)
+	meta.writeString("build_epoch")
+	meta.writeUint(typeUint64, uint64(time.Now().Unix()))
+	meta.writeString("database_type")
+	meta.writeString("shout.sh-Country")
+	meta.writeString("description")
+	meta.writeMap(0)
+	meta.writeString("ip_version")
+	meta.writeUint(typeUint16, 6)
+	meta.writeString("node_count")
+	meta.writeUint(typeUint32, uint64(len(nodes)))
+	meta.writeString("record_size")
+	meta.writeUint(typeUint16, 32)
+	out.Write(meta.Bytes())
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// trieNode is a node of the search tree Write builds.
+type trieNode struct {
+	children [2]*trieNode
+	// leaf is the data offset of a leaf, or -1 for an inner node
+	leaf  int
+	index int
+}
+
+// insert records data at the network of the first bits bits of addr.
+func (n *trieNode) insert(addr [16]byte, bits, data int) {
+	for i := 0; i < bits; i++ {
+		bit := addr[i/8] >> (7 - i%8) & 1
+		child := n.children[bit]
+		switch {
+		case child == nil:
+			child = &trieNode{leaf: -1}
+			n.children[bit] = child
+		case child.leaf >= 0 && i < bits-1:
+			// Split the wider network so the narrower one can go in it
+			child.children = [2]*trieNode{{leaf: child.leaf}, {leaf: child.leaf}}
+			child.leaf = -1
+		}
+		n = child
+	}
+	n.children = [2]*trieNode{}
+	n.leaf = data
+}
+
+// encoder writes values in the data section format.
+type encoder struct {
+	bytes.Buffer
+}
+
+// writeHeader writes the control byte of a value of typ and size.
+func (e *encoder) writeHeader(typ, size int) {
+	extended := typ >= typeInt32
+	ctrl := typ << 5
+	if extended {
+		ctrl = typeExtended
+	}
+	var extra []byte
+	switch {
+	case size < 29:
+		ctrl |= size
+	case size < 285:
+		ctrl |= 29
+		extra = []byte{byte(size - 29)}
+	case size < 65821:
+		ctrl |= 30
+		extra = binary.BigEndian.AppendUint16(nil, uint16(size-285))
+	default:
+		ctrl |= 31
+		n := size - 65821
+		extra = []byte{byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+	e.WriteByte(byte(ctrl))
+	if extended {
+		e.WriteByte(byte(typ - 7))
+	}
+	e.Write(extra)
+}
+
+func (e *encoder) writeMap(pairs int) {
+	e.writeHeader(typeMap, pairs)
+}
+
+func (e *encoder) writeString(s string) {
+	e.writeHeader(typeString, len(s))
+	e.WriteString(s)
+}
+
+// writeUint writes n as typ, an unsigned integer type, in as few bytes as
+// it needs.
+func (e *encoder) writeUint(typ int, n uint64) {
+	b := binary.BigEndian.AppendUint64(nil, n)
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	e.writeHeader(typ, len(b))
+	e.Write(b)
+}
@@ -0,0 +1,171 @@
+package geoip
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// writeTestDB writes a database of networks to a temporary file and
+// returns its path.
+func writeTestDB(t *testing.T, networks map[string]string) string {
+	t.Helper()
+
+	prefixes := make(map[netip.Prefix]string, len(networks))
+	for network, country := range networks {
+		prefixes[netip.MustParsePrefix(network)] = country
+	}
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := Write(f, prefixes); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return path
+}
+
+func TestCountry(t *testing.T) {
+	db, err := Open(writeTestDB(t, map[string]string{
+		"203.0.113.0/24":  "de",
+		"203.0.113.64/26": "FR",
+		"198.51.100.7/32": "JP",
+		"2001:db8::/32":   "NL",
+	}))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "203.0.113.7", want: "DE"},
+		{ip: "203.0.113.70", want: "FR"},
+		{ip: "203.0.113.200", want: "DE"},
+		{ip: "198.51.100.7", want: "JP"},
+		{ip: "198.51.100.8", want: ""},
+		{ip: "::ffff:203.0.113.7", want: "DE"},
+		{ip: "2001:db8::1", want: "NL"},
+		{ip: "2001:db9::1", want: ""},
+		{ip: "10.0.0.1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, err := db.Country(netip.MustParseAddr(tt.ip))
+			if err != nil || got != tt.want {
+				t.Errorf("Country(%s) = %q, %v, want %q", tt.ip, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenCorrupt(t *testing.T) {
+	valid, err := os.ReadFile(writeTestDB(t, map[string]string{"203.0.113.0/24": "DE"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "not a database", data: []byte("hello")},
+		{name: "truncated metadata", data: valid[:len(valid)-10]},
+		{name: "tree overruns the file", data: valid[len(valid)-200:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bad.mmdb")
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Open(path); !errors.Is(err, errCorrupt) {
+				t.Errorf("Open() error = %v, want a corrupt database error", err)
+			}
+		})
+	}
+}
+
+func TestRecordSizes(t *testing.T) {
+	tests := []struct {
+		size        int
+		node        []byte
+		left, right int
+	}{
+		{size: 24, node: []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, left: 0x010203, right: 0x040506},
+		{size: 28, node: []byte{0x01, 0x02, 0x03, 0xab, 0x04, 0x05, 0x06}, left: 0xa010203, right: 0xb040506},
+		{size: 32, node: []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, left: 0x01020304, right: 0x05060708},
+	}
+
+	for _, tt := range tests {
+		r := &reader{buf: tt.node, recordSize: tt.size}
+		if left, right := r.record(0, 0), r.record(0, 1); left != tt.left || right != tt.right {
+			t.Errorf("%d-bit records = %#x, %#x, want %#x, %#x", tt.size, left, right, tt.left, tt.right)
+		}
+	}
+}
+
+func TestDecodePointer(t *testing.T) {
+	// A map whose value points back at the string key before it
+	d := decoder{buf: []byte{0xe1, 0x42, 'i', 'd', 0x20, 0x01}}
+	value, _, err := d.decode(0)
+	if err != nil {
+		t.Fatalf("decode() error = %v", err)
+	}
+	if got := value.(map[string]any)["id"]; got != "id" {
+		t.Errorf("pointed to value = %v, want id", got)
+	}
+
+	// A pointer to itself is refused rather than followed forever
+	if _, _, err := (decoder{buf: []byte{0x20, 0x00}}).decode(0); !errors.Is(err, errCorrupt) {
+		t.Errorf("decode() of a looping pointer error = %v, want a corrupt database error", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	if p, err := NewPolicy(config.GeoIPConfig{}); p != nil || err != nil {
+		t.Fatalf("NewPolicy() without a database = %v, %v, want nil, nil", p, err)
+	}
+
+	p, err := NewPolicy(config.GeoIPConfig{
+		Database:   writeTestDB(t, map[string]string{"203.0.113.0/24": "DE", "198.51.100.0/24": "KP"}),
+		Allow:      []string{"de", "kp", "fr"},
+		Deny:       []string{"KP"},
+		RateLimits: []string{"fr=20"},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		ip          string
+		wantCountry string
+		wantAllowed bool
+	}{
+		{ip: "203.0.113.7", wantCountry: "DE", wantAllowed: true},
+		{ip: "198.51.100.7", wantCountry: "KP", wantAllowed: false},
+		{ip: "10.0.0.1", wantCountry: "", wantAllowed: true},
+		{ip: "not an ip", wantCountry: "", wantAllowed: true},
+	}
+	for _, tt := range tests {
+		country := p.Country(tt.ip)
+		if country != tt.wantCountry || p.Allowed(country) != tt.wantAllowed {
+			t.Errorf("%s: country %q allowed %v, want %q allowed %v", tt.ip, country, p.Allowed(country), tt.wantCountry, tt.wantAllowed)
+		}
+	}
+	if p.Allowed("US") {
+		t.Error("US is allowed without being on the allow list")
+	}
+	if got := p.RateLimit("FR"); got != 20 {
+		t.Errorf("RateLimit(FR) = %d, want 20", got)
+	}
+}
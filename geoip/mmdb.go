@@ -0,0 +1,288 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+)
+
+// metadataMarker precedes the metadata map at the end of a MaxMind DB.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSeparator is the gap of zero bytes between the search tree and the
+// data section.
+const dataSeparator = 16
+
+// Data section field types.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// errCorrupt is wrapped by every error about a malformed database.
+var errCorrupt = errors.New("corrupt MaxMind DB")
+
+// reader reads a MaxMind DB file (https://maxmind.github.io/MaxMind-DB/):
+// a binary search tree on the bits of IP addresses whose leaves point
+// into a section of typed data.
+type reader struct {
+	buf        []byte
+	nodeCount  int
+	recordSize int
+	ipVersion  int
+	// data is the data section, which pointers are relative to
+	data []byte
+	// ipv4Start is the node IPv4 lookups start at in an IPv6 tree: the
+	// end of the ::/96 path, where MaxMind places the IPv4 space
+	ipv4Start int
+}
+
+// newReader parses the metadata of the database in buf.
+func newReader(buf []byte) (*reader, error) {
+	start := bytes.LastIndex(buf, metadataMarker)
+	if start < 0 {
+		return nil, fmt.Errorf("%w: no metadata", errCorrupt)
+	}
+	meta := decoder{buf: buf[start+len(metadataMarker):]}
+	value, _, err := meta.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is not a map", errCorrupt)
+	}
+
+	r := &reader{buf: buf}
+	for name, dst := range map[string]*int{"node_count": &r.nodeCount, "record_size": &r.recordSize, "ip_version": &r.ipVersion} {
+		n, ok := fields[name].(uint64)
+		if !ok || n > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: metadata lacks %s", errCorrupt, name)
+		}
+		*dst = int(n)
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", errCorrupt, r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", errCorrupt, r.ipVersion)
+	}
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+dataSeparator > start {
+		return nil, fmt.Errorf("%w: search tree overruns the file", errCorrupt)
+	}
+	r.data = buf[treeSize+dataSeparator : start]
+
+	if r.ipVersion == 6 {
+		for i := 0; i < 96 && r.ipv4Start < r.nodeCount; i++ {
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+	return r, nil
+}
+
+// lookup returns the data recorded for ip, or nil if there is none.
+func (r *reader) lookup(ip netip.Addr) (any, error) {
+	ip = ip.Unmap()
+	node := 0
+	switch {
+	case ip.Is4() && r.ipVersion == 6:
+		node = r.ipv4Start
+	case ip.Is6() && r.ipVersion == 4:
+		return nil, nil
+	}
+	addr := ip.AsSlice()
+	for i := 0; i < len(addr)*8 && node < r.nodeCount; i++ {
+		node = r.record(node, addr[i/8]>>(7-i%8)&1)
+	}
+	switch {
+	case node == r.nodeCount:
+		return nil, nil
+	case node < r.nodeCount:
+		return nil, fmt.Errorf("%w: search tree deeper than the address", errCorrupt)
+	}
+	d := decoder{buf: r.data}
+	value, _, err := d.decode(node - r.nodeCount - dataSeparator)
+	return value, err
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (r *reader) record(node int, bit byte) int {
+	b := r.buf[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[int(bit)*3:]
+		return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+	case 28:
+		if bit == 0 {
+			return int(b[3]&0xf0)<<20 | int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+		}
+		return int(b[3]&0x0f)<<24 | int(b[4])<<16 | int(b[5])<<8 | int(b[6])
+	default:
+		return int(binary.BigEndian.Uint32(b[int(bit)*4:]))
+	}
+}
+
+// decoder decodes values of the data section format. Maps decode to
+// map[string]any, arrays to []any and unsigned integers to uint64.
+type decoder struct {
+	buf []byte
+}
+
+// decode returns the value at offset and the offset after it.
+func (d decoder) decode(offset int) (any, int, error) {
+	typ, size, offset, err := d.header(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if typ == typePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Pointers never point to pointers, which also rules out loops
+		if typ, _, _, err := d.header(target); err != nil || typ == typePointer {
+			return nil, 0, fmt.Errorf("%w: bad pointer", errCorrupt)
+		}
+		value, _, err := d.decode(target)
+		return value, next, err
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]any, min(size, 64))
+		for range size {
+			var key, value any
+			if key, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: map key is not a string", errCorrupt)
+			}
+			if value, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			m[name] = value
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, min(size, 64))
+		for range size {
+			var value any
+			if value, offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > len(d.buf) {
+		return nil, 0, fmt.Errorf("%w: value overruns the data", errCorrupt)
+	}
+	b := d.buf[offset : offset+size]
+	switch typ {
+	case typeString:
+		return string(b), offset + size, nil
+	case typeBytes, typeUint128:
+		return bytes.Clone(b), offset + size, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("%w: double of %d bytes", errCorrupt, size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset + size, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("%w: float of %d bytes", errCorrupt, size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), offset + size, nil
+	case typeUint16, typeUint32, typeUint64, typeInt32:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("%w: integer of %d bytes", errCorrupt, size)
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		if typ == typeInt32 {
+			return int64(int32(n)), offset + size, nil
+		}
+		return n, offset + size, nil
+	}
+	return nil, 0, fmt.Errorf("%w: unexpected type %d", errCorrupt, typ)
+}
+
+// header decodes the control byte at offset, and the type and size bytes
+// that may follow it. For pointers, size holds the control byte's low
+// bits instead.
+func (d decoder) header(offset int) (typ, size, next int, err error) {
+	if offset < 0 || offset >= len(d.buf) {
+		return 0, 0, 0, fmt.Errorf("%w: offset %d out of range", errCorrupt, offset)
+	}
+	ctrl := d.buf[offset]
+	offset++
+	typ = int(ctrl >> 5)
+	if typ == typePointer {
+		return typ, int(ctrl & 0x1f), offset, nil
+	}
+	if typ == typeExtended {
+		if offset >= len(d.buf) {
+			return 0, 0, 0, fmt.Errorf("%w: truncated type", errCorrupt)
+		}
+		typ = 7 + int(d.buf[offset])
+		offset++
+	}
+
+	size = int(ctrl & 0x1f)
+	if size < 29 {
+		return typ, size, offset, nil
+	}
+	n := size - 28
+	if offset+n > len(d.buf) {
+		return 0, 0, 0, fmt.Errorf("%w: truncated size", errCorrupt)
+	}
+	extra := 0
+	for _, c := range d.buf[offset : offset+n] {
+		extra = extra<<8 | int(c)
+	}
+	size = []int{29, 285, 65821}[n-1] + extra
+	return typ, size, offset + n, nil
+}
+
+// pointer decodes a pointer whose control byte had the low bits in ctrl
+// and the rest of which starts at offset.
+func (d decoder) pointer(ctrl, offset int) (target, next int, err error) {
+	n := ctrl>>3 + 1
+	if offset+n > len(d.buf) {
+		return 0, 0, fmt.Errorf("%w: truncated pointer", errCorrupt)
+	}
+	target = ctrl & 0x7
+	if n == 4 {
+		target = 0
+	}
+	for _, c := range d.buf[offset : offset+n] {
+		target = target<<8 | int(c)
+	}
+	target += []int{0, 2048, 526336, 0}[n-1]
+	return target, offset + n, nil
+}
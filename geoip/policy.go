@@ -0,0 +1,119 @@
+package geoip
+
+import (
+	"log"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Policy is the per-country access policy of SHOUT_GEOIP_*. It is safe
+// for concurrent use.
+//
+// Usage example:
+//
+//	policy, err := geoip.NewPolicy(cfg.GeoIP)
+//	country := policy.Country(c.IP())
+//	if !policy.Allowed(country) {
+//	    // refuse the request
+//	}
+type Policy struct {
+	db     *DB
+	allow  map[string]bool
+	deny   map[string]bool
+	limits map[string]int
+}
+
+// NewPolicy loads the database and policy in cfg.
+//
+// Parameters:
+//   - cfg: the database, country lists and per-country rate limits
+//
+// Returns:
+//   - *Policy: the policy, or nil when no database is configured
+//   - error: error if the database can't be loaded or a limit is invalid
+//
+// Example:
+//
+//	policy, err := geoip.NewPolicy(cfg.GeoIP)
+//	if err != nil {
+//	    log.Fatalf("Failed to load GeoIP policy: %v", err)
+//	}
+func NewPolicy(cfg config.GeoIPConfig) (*Policy, error) {
+	if cfg.Database == "" {
+		return nil, nil
+	}
+	db, err := Open(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{db: db, allow: countrySet(cfg.Allow), deny: countrySet(cfg.Deny), limits: make(map[string]int)}
+	for _, entry := range cfg.RateLimits {
+		code, limit, _ := strings.Cut(entry, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil {
+			return nil, err
+		}
+		p.limits[strings.ToUpper(strings.TrimSpace(code))] = n
+	}
+	return p, nil
+}
+
+// Country returns the country code of ip, or "" when the database doesn't
+// place it or ip isn't an address.
+//
+// Parameters:
+//   - ip: the client IP
+//
+// Returns:
+//   - string: the upper case ISO country code, or ""
+func (p *Policy) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	country, err := p.db.Country(addr)
+	if err != nil {
+		log.Printf("Error looking up the country of %s: %v", ip, err)
+	}
+	return country
+}
+
+// Allowed reports whether clients from country are served: it is not
+// denied, and allowed if there is an allow list. Unknown countries are
+// always served.
+//
+// Parameters:
+//   - country: the code returned by Country
+//
+// Returns:
+//   - bool: true if the request may proceed
+func (p *Policy) Allowed(country string) bool {
+	if country == "" {
+		return true
+	}
+	return !p.deny[country] && (len(p.allow) == 0 || p.allow[country])
+}
+
+// RateLimit returns the requests per minute each IP in country may make,
+// or 0 if the country has no limit of its own.
+//
+// Parameters:
+//   - country: the code returned by Country
+//
+// Returns:
+//   - int: requests per minute, or 0
+func (p *Policy) RateLimit(country string) int {
+	return p.limits[country]
+}
+
+// countrySet returns the upper case codes as a set.
+func countrySet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	return set
+}
@@ -3,19 +3,38 @@ module github.com/ryanlewis/shout-sh
 go 1.24.6
 
 require (
-	github.com/andybalholm/brotli v1.1.0 // indirect
-	github.com/caarlos0/env/v11 v11.3.1 // indirect
-	github.com/gofiber/fiber/v2 v2.52.9 // indirect
+	github.com/alicebob/miniredis/v2 v2.33.0
+	github.com/andybalholm/brotli v1.1.0
+	github.com/caarlos0/env/v11 v11.3.1
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/gofiber/fiber/v2 v2.52.9
+	github.com/joho/godotenv v1.5.1
+	github.com/mattn/go-runewidth v0.0.16
+	github.com/redis/go-redis/v9 v9.7.0
+	github.com/rivo/uniseg v0.2.0
+	github.com/ryanlewis/go-figure v0.0.0-20210622060536-734e95fb86be
+	github.com/valyala/fasthttp v1.51.0
+	golang.org/x/crypto v0.39.0
+	golang.org/x/image v0.25.0
+	golang.org/x/sys v0.33.0
+	golang.org/x/text v0.26.0
+	google.golang.org/grpc v1.75.1
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/mattn/go-runewidth v0.0.16 // indirect
-	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/ryanlewis/go-figure v0.0.0-20210622060536-734e95fb86be // indirect
+	github.com/philhofer/fwd v1.1.3-0.20240916144458-20a13a1f6b7c // indirect
+	github.com/tinylib/msgp v1.2.5 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
-	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
-	golang.org/x/sys v0.28.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
 )
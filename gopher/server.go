@@ -0,0 +1,380 @@
+// Package gopher serves shout.sh over the Gopher protocol (RFC 1436): a
+// menu, the help page, the font list and static renders of text given as
+// selectors, drawn through the same pipeline as the HTTP API.
+package gopher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Selectors with their own pages; any other selector is text to render.
+const (
+	selectorHelp   = "help"
+	selectorFonts  = "fonts"
+	selectorRender = "render"
+)
+
+// Gopher item types used in menus.
+const (
+	typeText   = '0'
+	typeMenu   = '1'
+	typeError  = '3'
+	typeSearch = '7'
+	typeInfo   = 'i'
+)
+
+// maxSelector bounds the request line; RFC 1436 selectors are at most 255
+// characters, and the search text of a type 7 item comes on top.
+const maxSelector = 1024
+
+// connTimeout bounds how long a connection may take to send its request
+// and read the response.
+const connTimeout = 30 * time.Second
+
+// ErrServerClosed is returned by Serve after Shutdown.
+var ErrServerClosed = errors.New("gopher: server closed")
+
+// Deps bundles the shared services the server depends on.
+type Deps struct {
+	Config  *config.Config
+	Fonts   *render.FontCache
+	Metrics *types.Metrics
+	// Filter blocks words in request text; nil disables filtering.
+	Filter *render.WordFilter
+	// Help returns the help page; nil leaves it out of the menu.
+	Help func() string
+}
+
+// Server answers Gopher requests.
+//
+// Usage example:
+//
+//	g := gopher.New(gopher.Deps{Config: cfg, Fonts: cache, Help: help})
+//	lis, _ := net.Listen("tcp", ":7070")
+//	go g.Serve(lis)
+type Server struct {
+	cfg     *config.Config
+	fonts   *render.FontCache
+	metrics *types.Metrics
+	filter  *render.WordFilter
+	help    func() string
+	shout   *shout.Renderer
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	closed    bool
+	conns     sync.WaitGroup
+}
+
+// New creates a Server from its dependencies. A nil Metrics is replaced
+// with a fresh instance.
+//
+// Parameters:
+//   - deps: the shared services used by the server
+//
+// Returns:
+//   - *Server: a server ready to Serve
+func New(deps Deps) *Server {
+	if deps.Metrics == nil {
+		deps.Metrics = &types.Metrics{}
+	}
+	return &Server{
+		cfg:       deps.Config,
+		fonts:     deps.Fonts,
+		metrics:   deps.Metrics,
+		filter:    deps.Filter,
+		help:      deps.Help,
+		shout:     shout.New(deps.Fonts),
+		listeners: make(map[net.Listener]struct{}),
+	}
+}
+
+// Serve answers the connections accepted on lis, one request each, until
+// lis fails or Shutdown is called.
+//
+// Parameters:
+//   - lis: the listener to accept connections on
+//
+// Returns:
+//   - error: ErrServerClosed after Shutdown, otherwise the accept error
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.listeners[lis] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, lis)
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conns.Add(1)
+		s.mu.Unlock()
+		go func() {
+			defer s.conns.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// Shutdown stops accepting connections and waits for those in progress
+// to finish, or for ctx to be done.
+//
+// Parameters:
+//   - ctx: bounds how long to wait for open connections
+//
+// Returns:
+//   - error: ctx.Err() if connections were still open when ctx was done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	for lis := range s.listeners {
+		_ = lis.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.conns.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// serveConn reads one request from conn, answers it and closes conn.
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(connTimeout))
+
+	line, err := bufio.NewReader(io.LimitReader(conn, maxSelector)).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+	selector, query, _ := strings.Cut(strings.TrimRight(line, "\r\n"), "\t")
+
+	w := bufio.NewWriter(conn)
+	s.respond(w, strings.TrimPrefix(selector, "/"), query)
+	if err := w.Flush(); err != nil {
+		log.Printf("Gopher response failed: %v", err)
+	}
+}
+
+// respond writes the response to a request for selector, with the search
+// text of a type 7 item in query.
+func (s *Server) respond(w io.Writer, selector, query string) {
+	switch {
+	case selector == "":
+		s.writeMenu(w, s.rootMenu())
+	case selector == selectorHelp && s.help != nil:
+		writeText(w, s.help())
+	case selector == selectorFonts:
+		s.writeMenu(w, s.fontMenu())
+	case selector == selectorRender:
+		s.render(w, query, url.Values{})
+	case strings.HasPrefix(selector, selectorRender+"/"):
+		s.render(w, query, url.Values{"f": {strings.TrimPrefix(selector, selectorRender+"/")}})
+	default:
+		text, rawQuery, _ := strings.Cut(selector, "?")
+		params, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			s.writeError(w, apierrors.ErrBadOption.WithDetail("%v", err))
+			return
+		}
+		s.render(w, middleware.PathText(text), params)
+	}
+}
+
+// item is a line of a Gopher menu.
+type item struct {
+	kind     byte
+	display  string
+	selector string
+}
+
+// rootMenu lists what the server offers.
+func (s *Server) rootMenu() []item {
+	var items []item
+	if layout, err := render.GenerateLayoutContext(context.Background(), "shout.sh", types.RenderOptions{Font: s.cfg.Fonts.Default}, s.fonts); err == nil {
+		for _, line := range strings.Split(strings.TrimRight(layout.String(), "\n"), "\n") {
+			items = append(items, item{kind: typeInfo, display: line})
+		}
+	}
+	items = append(items,
+		item{kind: typeInfo, display: "ASCII art banners, now over Gopher."},
+		item{kind: typeInfo},
+		item{kind: typeSearch, display: "Shout some text", selector: selectorRender},
+		item{kind: typeMenu, display: "Shout in another font", selector: selectorFonts},
+	)
+	if s.help != nil {
+		items = append(items, item{kind: typeText, display: "Help", selector: selectorHelp})
+	}
+	return append(items,
+		item{kind: typeInfo},
+		item{kind: typeText, display: "Example: HELLO", selector: "HELLO"},
+		item{kind: typeInfo, display: "Any other selector is rendered, e.g. HELLO+WORLD?f=doom&a=left"},
+	)
+}
+
+// fontMenu lists the fonts, each as a search item that renders in it.
+func (s *Server) fontMenu() []item {
+	items := []item{{kind: typeInfo, display: "Pick a font, then type your text."}, {kind: typeInfo}}
+	for _, name := range s.fonts.ListFonts() {
+		display := name
+		if name == s.cfg.Fonts.Default {
+			display += " (default)"
+		}
+		items = append(items, item{kind: typeSearch, display: display, selector: selectorRender + "/" + name})
+	}
+	return items
+}
+
+// render validates text as the HTTP API does and writes it as a banner,
+// with the options in params: f or font, a or align, and mw or maxwidth,
+// which defaults to SHOUT_GOPHER_WIDTH.
+func (s *Server) render(w io.Writer, raw string, params url.Values) {
+	s.metrics.IncStatic()
+
+	text, err := middleware.CheckText(raw, s.cfg.Text)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if text == "" {
+		s.writeError(w, apierrors.ErrNoText)
+		return
+	}
+	masked, err := middleware.FilterText(s.filter, s.cfg.Text.Filter, text, s.metrics)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	width := s.cfg.Gopher.Width
+	if mw := firstOf(params.Get("mw"), params.Get("maxwidth")); mw != "" {
+		width, err = strconv.Atoi(mw)
+		if err != nil || width < 0 || width > s.cfg.Terminal.MaxCols {
+			s.writeError(w, apierrors.ErrBadOption.WithDetail("mw must be between 0 and %d, got %q", s.cfg.Terminal.MaxCols, mw))
+			return
+		}
+	}
+	font := firstOf(params.Get("f"), params.Get("font"), s.cfg.Fonts.Default)
+	align := firstOf(params.Get("a"), params.Get("align"), s.cfg.Text.DefaultAlign)
+
+	ctx, cancel := s.renderContext()
+	defer cancel()
+	banner, err := s.shout.Render(ctx, text, shout.Options{
+		Font:   font,
+		Align:  align,
+		Cols:   width,
+		Colors: render.NoColor,
+		Masked: masked,
+	})
+	if err != nil {
+		var optErr *shout.OptionError
+		switch {
+		case errors.As(err, &optErr):
+			s.writeError(w, apierrors.ErrBadOption.WithDetail("%v", optErr.Err))
+		case errors.Is(err, context.DeadlineExceeded):
+			s.metrics.IncError()
+			s.writeError(w, apierrors.ErrRenderTimeout)
+		default:
+			s.metrics.IncError()
+			log.Printf("Error generating ASCII: %v", err)
+			s.writeError(w, apierrors.ErrRenderFailed)
+		}
+		return
+	}
+	writeText(w, banner)
+}
+
+// renderContext bounds rendering by SHOUT_SERVER_STATIC_TIMEOUT_MS.
+func (s *Server) renderContext() (context.Context, context.CancelFunc) {
+	if budget := time.Duration(s.cfg.Server.StaticTimeoutMS) * time.Millisecond; budget > 0 {
+		return context.WithTimeout(context.Background(), budget)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// writeMenu writes items as a Gopher menu linking back to this server.
+func (s *Server) writeMenu(w io.Writer, items []item) {
+	for _, it := range items {
+		host, port := s.cfg.Gopher.Hostname, s.cfg.Gopher.Port
+		if it.kind == typeInfo || it.kind == typeError {
+			host, port = "(NULL)", 0
+		}
+		fmt.Fprintf(w, "%c%s\t%s\t%s\t%d\r\n", it.kind, clean(it.display), clean(it.selector), host, port)
+	}
+	io.WriteString(w, ".\r\n")
+}
+
+// writeError writes err as a one-line error menu, which clients show in
+// place of the page.
+func (s *Server) writeError(w io.Writer, err error) {
+	s.writeMenu(w, []item{{kind: typeError, display: err.Error()}})
+}
+
+// writeText writes text as a Gopher text file: lines end in CRLF, lines
+// starting with a dot get another, and a lone dot ends the file.
+func writeText(w io.Writer, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.HasPrefix(line, ".") {
+			line = "." + line
+		}
+		io.WriteString(w, strings.TrimRight(line, "\r")+"\r\n")
+	}
+	io.WriteString(w, ".\r\n")
+}
+
+// clean replaces the tabs and line breaks that would split a menu line.
+func clean(s string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+// firstOf returns the first non-empty string from the arguments.
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package gopher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// newTestServer serves Gopher on a loopback port, backed by the
+// repository's fonts, and returns its address.
+func newTestServer(t *testing.T) string {
+	t.Helper()
+
+	cfg, err := config.LoadFromEnv(map[string]string{
+		"SHOUT_FONTS_PATH":      "../fonts",
+		"SHOUT_FONTS_ALLOWED":   "standard,doom,small",
+		"SHOUT_TEXT_MAX_LENGTH": "20",
+		"SHOUT_GOPHER_HOSTNAME": "gopher.example",
+		"SHOUT_GOPHER_PORT":     "7070",
+	})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	fonts := render.NewFontCache()
+	if err := fonts.LoadFonts(cfg.Fonts); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := New(Deps{Config: cfg, Fonts: fonts, Help: func() string { return "Usage:\n.dotted\n" }})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+	return lis.Addr().String()
+}
+
+// request sends a request line to the server and returns the response.
+func request(t *testing.T, addr, line string) string {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, line+"\r\n"); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	body, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return string(body)
+}
+
+// banner renders text as the server should, as a Gopher text file.
+func banner(t *testing.T, text string, opts shout.Options) string {
+	t.Helper()
+
+	opts.Cols = 70
+	if opts.Align == "" {
+		opts.Align = "center"
+	}
+	output, err := shout.Render(context.Background(), text, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.ReplaceAll(output, "\n", "\r\n") + ".\r\n"
+}
+
+func TestServer(t *testing.T) {
+	addr := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		line     string
+		want     string
+		contains []string
+	}{
+		{
+			name: "root menu",
+			line: "",
+			contains: []string{
+				"7Shout some text\trender\tgopher.example\t7070\r\n",
+				"1Shout in another font\tfonts\tgopher.example\t7070\r\n",
+				"0Help\thelp\tgopher.example\t7070\r\n",
+				"iASCII art banners, now over Gopher.\t\t(NULL)\t0\r\n",
+			},
+		},
+		{name: "help", line: "help", want: "Usage:\r\n..dotted\r\n.\r\n"},
+		{
+			name: "fonts",
+			line: "/fonts",
+			contains: []string{
+				"7small\trender/small\tgopher.example\t7070\r\n",
+				"7standard (default)\trender/standard\tgopher.example\t7070\r\n",
+			},
+		},
+		{name: "selector text", line: "HELLO+YOU", want: banner(t, "HELLO YOU", shout.Options{Font: "standard"})},
+		{name: "selector options", line: "/HI?f=small&a=left", want: banner(t, "HI", shout.Options{Font: "small", Align: "left"})},
+		{name: "search", line: "render\tHI THERE", want: banner(t, "HI THERE", shout.Options{Font: "standard"})},
+		{name: "search in a font", line: "render/small\tHI", want: banner(t, "HI", shout.Options{Font: "small"})},
+		{name: "empty search", line: "render\t", contains: []string{"3"}},
+		{name: "text too long", line: strings.Repeat("A", 21), contains: []string{"3", "too long"}},
+		{name: "bad width", line: "HI?mw=wide", contains: []string{"3", "mw must be between"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := request(t, addr, tt.line)
+			if !strings.HasSuffix(got, ".\r\n") {
+				t.Errorf("response doesn't end with a lone dot: %q", got)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("response =\n%q\nwant\n%q", got, tt.want)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("response doesn't contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := New(Deps{Config: &config.Config{}, Fonts: render.NewFontCache()})
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	select {
+	case err := <-served:
+		if !errors.Is(err, ErrServerClosed) {
+			t.Errorf("Serve() error = %v, want ErrServerClosed", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Serve() didn't return after Shutdown")
+	}
+}
@@ -0,0 +1,353 @@
+// Package grpcapi serves the shout.sh gRPC API, which mirrors the HTTP
+// API for internal services: Render draws a static banner and
+// StreamFrames streams an animation, with gRPC flow control in place of
+// chunked responses. The messages and service are generated from
+// shout.proto.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative shout.proto
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// randomFont is the font option value that picks one of the loaded fonts.
+const randomFont = "random"
+
+// defaultStreamColor colors streams when no color is given and
+// SHOUT_STREAMING_DEFAULT_COLOR is empty.
+const defaultStreamColor = "rainbow"
+
+// Deps bundles the shared services the server depends on.
+type Deps struct {
+	Config  *config.Config
+	Fonts   *render.FontCache
+	Metrics *types.Metrics
+	// Filter blocks words in request text; nil disables filtering.
+	Filter *render.WordFilter
+}
+
+// Server implements the Shout gRPC service.
+//
+// Usage example:
+//
+//	g := grpc.NewServer()
+//	grpcapi.RegisterShoutServer(g, grpcapi.New(grpcapi.Deps{Config: cfg, Fonts: cache}))
+type Server struct {
+	UnimplementedShoutServer
+
+	cfg     *config.Config
+	fonts   *render.FontCache
+	metrics *types.Metrics
+	filter  *render.WordFilter
+	shout   *shout.Renderer
+	streams *types.ConnectionManager
+}
+
+// New creates a Server from its dependencies. A nil Metrics is replaced
+// with a fresh instance. Streams are limited to
+// SHOUT_STREAMING_MAX_STREAMS, counted apart from the HTTP streams.
+//
+// Parameters:
+//   - deps: the shared services used by the server
+//
+// Returns:
+//   - *Server: a server ready to be registered on a grpc.Server
+func New(deps Deps) *Server {
+	if deps.Metrics == nil {
+		deps.Metrics = &types.Metrics{}
+	}
+	return &Server{
+		cfg:     deps.Config,
+		fonts:   deps.Fonts,
+		metrics: deps.Metrics,
+		filter:  deps.Filter,
+		shout:   shout.New(deps.Fonts),
+		streams: types.NewConnectionManager(deps.Config.Streaming.MaxStreams),
+	}
+}
+
+// request is a validated request: the text and what to draw it with.
+type request struct {
+	text string
+	opts shout.Options
+	seed uint64
+}
+
+// parse validates the text and options shared by every RPC, as the HTTP
+// middleware and handlers do, drawing in color rather than the color
+// option. stream asks for the frame area of a stream, which keeps clear of
+// the last column and row.
+func (s *Server) parse(text string, in *Options, color string, stream bool) (request, error) {
+	text, err := middleware.CheckText(text, s.cfg.Text)
+	if err != nil {
+		return request{}, err
+	}
+	if text == "" {
+		return request{}, apierrors.ErrNoText
+	}
+	masked, err := middleware.FilterText(s.filter, s.cfg.Text.Filter, text, s.metrics)
+	if err != nil {
+		return request{}, err
+	}
+
+	cols, rows := int(in.GetCols()), int(in.GetRows())
+	if cols < 0 || cols > s.cfg.Terminal.MaxCols {
+		return request{}, apierrors.ErrBadOption.WithDetail("cols must be between 0 and %d, got %d", s.cfg.Terminal.MaxCols, cols)
+	}
+	if rows < 0 || rows > s.cfg.Terminal.MaxRows {
+		return request{}, apierrors.ErrBadOption.WithDetail("rows must be between 0 and %d, got %d", s.cfg.Terminal.MaxRows, rows)
+	}
+	if stream {
+		// One less, leaving sizes of 0 and 1 alone
+		cols, rows = max(cols-1, min(cols, 1)), max(rows-1, min(rows, 1))
+	}
+
+	seed, ok := shout.ParseSeed(in.GetSeed())
+	if !ok {
+		seed = rand.Uint64()
+	}
+	random := shout.NewRand(seed)
+
+	font := in.GetFont()
+	switch font {
+	case "":
+		font = s.cfg.Fonts.Default
+	case randomFont:
+		if fonts := s.fonts.ListFonts(); len(fonts) > 0 {
+			font = fonts[random.IntN(len(fonts))]
+		}
+	}
+	align := in.GetAlign()
+	if align == "" {
+		align = s.cfg.Text.DefaultAlign
+	}
+
+	scheme, err := render.ParseColor(color)
+	if err != nil {
+		return request{}, apierrors.ErrBadOption.WithDetail("%v", err)
+	}
+	mode, err := render.ParseColorMode(cmp.Or(in.GetCmode(), s.cfg.Text.DefaultColorMode))
+	if err != nil {
+		return request{}, apierrors.ErrBadOption.WithDetail("%v", err)
+	}
+	switch typed := scheme.(type) {
+	case *render.ColorList:
+		typed.Mode = mode
+	case *render.PresetScheme:
+		// Seeded before the phase is drawn, as on the HTTP API
+		scheme = typed.WithSeed(random.Uint64())
+	}
+
+	return request{
+		text: text,
+		seed: seed,
+		opts: shout.Options{
+			Font:   font,
+			Color:  color,
+			Scheme: scheme,
+			Align:  align,
+			Cols:   cols,
+			Rows:   rows,
+			Colors: render.TermColorDepth(in.GetTerm()),
+			Masked: masked,
+			Phase:  random.IntN(1 << 10),
+		},
+	}, nil
+}
+
+// Render draws a static banner, as GET /{text} does.
+//
+// Parameters:
+//   - ctx: the call context; rendering is also bounded by
+//     SHOUT_SERVER_STATIC_TIMEOUT_MS
+//   - req: the text and options
+//
+// Returns:
+//   - *RenderResponse: the banner, the font it was drawn in and the seed
+//   - error: a status error with a code matching the HTTP status
+func (s *Server) Render(ctx context.Context, req *RenderRequest) (*RenderResponse, error) {
+	s.metrics.IncStatic()
+
+	r, err := s.parse(req.GetText(), req.GetOptions(), cmp.Or(req.GetOptions().GetColor(), s.cfg.Text.DefaultColor), false)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	font := s.fonts.ResolveFont(r.opts.Font, cmp.Or(s.cfg.Fonts.Default, render.DefaultFont))
+	if font == nil {
+		return nil, toStatus(apierrors.ErrFontNotFound.WithDetail("%q", r.opts.Font))
+	}
+
+	ctx, cancel := s.renderContext(ctx)
+	defer cancel()
+	banner, err := s.shout.Render(ctx, r.text, r.opts)
+	if err != nil {
+		s.metrics.IncError()
+		return nil, toStatus(err)
+	}
+	return &RenderResponse{Banner: banner, Font: font.Name, Seed: strconv.FormatUint(r.seed, 10)}, nil
+}
+
+// StreamFrames streams the frames of an animation, as GET /p/{text} does.
+// Frames go out at the requested rate; a client that reads slower than
+// that holds up Send through flow control, and the frames that fall due
+// meanwhile are skipped, so the client always gets the current frame.
+//
+// Parameters:
+//   - req: the text, options and animation
+//   - out: the stream frames are sent on
+//
+// Returns:
+//   - error: a status error with a code matching the HTTP status
+func (s *Server) StreamFrames(req *StreamFramesRequest, out Shout_StreamFramesServer) error {
+	s.metrics.IncParty()
+	if !s.cfg.Features.EnableStreaming {
+		return toStatus(apierrors.ErrFeatureDisabled.WithDetail("streaming is turned off"))
+	}
+
+	animation := cmp.Or(req.GetAnim(), s.cfg.Streaming.DefaultAnimation)
+	anim, preset, err := shout.ParseAnimation(animation)
+	if err != nil {
+		return toStatus(apierrors.ErrBadOption.WithDetail("%v", err))
+	}
+	color := req.GetOptions().GetColor()
+	switch {
+	case preset != "" && color != "":
+		return toStatus(apierrors.ErrBadOption.WithDetail("anim=%s and color=%s both set the colors", preset, color))
+	case preset != "":
+		color = preset
+	case color == "":
+		color = cmp.Or(s.cfg.Streaming.DefaultColor, defaultStreamColor)
+	}
+	r, err := s.parse(req.GetText(), req.GetOptions(), color, true)
+	if err != nil {
+		return toStatus(err)
+	}
+	interval, err := s.interval(req)
+	if err != nil {
+		return toStatus(err)
+	}
+	if req.GetLoops() < 0 {
+		return toStatus(apierrors.ErrBadOption.WithDetail("loops must be 0 or more, got %d", req.GetLoops()))
+	}
+
+	timeout := time.Duration(req.GetTimeout()) * time.Second
+	if timeout <= 0 && (req.GetLoops() == 0 || anim.Endless()) {
+		timeout = time.Duration(s.cfg.Streaming.DefaultTimeout) * time.Second
+	}
+	if limit := time.Duration(s.cfg.Streaming.MaxTimeout) * time.Second; limit > 0 && (timeout <= 0 || timeout > limit) {
+		timeout = limit
+	}
+	r.opts.Animation, r.opts.Interval = animation, interval
+	r.opts.Loops, r.opts.Duration = int(req.GetLoops()), timeout
+
+	if !s.streams.TryAcquire() {
+		s.metrics.IncRejectedStream()
+		return toStatus(apierrors.ErrStreamCapacity)
+	}
+	defer s.streams.Release()
+
+	ctx := out.Context()
+	renderCtx, cancel := s.renderContext(ctx)
+	frames, err := s.shout.Frames(renderCtx, r.text, r.opts)
+	cancel()
+	if err != nil {
+		s.metrics.IncError()
+		return toStatus(err)
+	}
+
+	start := time.Now()
+	for n := 0; ; {
+		frame, last := frames(n)
+		if err := out.Send(&Frame{Index: int64(n), Content: frame, Last: last}); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		// Skip to the latest frame already due
+		next := max(n+1, int(time.Since(start)/interval))
+		s.metrics.AddDroppedFrames(int64(next - n - 1))
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-time.After(time.Until(start.Add(time.Duration(next) * interval))):
+		}
+		n = next
+	}
+}
+
+// renderContext bounds rendering by SHOUT_SERVER_STATIC_TIMEOUT_MS.
+func (s *Server) renderContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if budget := time.Duration(s.cfg.Server.StaticTimeoutMS) * time.Millisecond; budget > 0 {
+		return context.WithTimeout(ctx, budget)
+	}
+	return context.WithCancel(ctx)
+}
+
+// interval returns the time between frames: 1/fps when fps is given,
+// otherwise the interval of speed, within the SHOUT_STREAMING_ limits.
+func (s *Server) interval(req *StreamFramesRequest) (time.Duration, error) {
+	limits := s.cfg.Streaming
+	if fps := int(req.GetFps()); fps != 0 {
+		if fps < 1 || fps > limits.MaxFPS {
+			return 0, apierrors.ErrBadOption.WithDetail("fps must be between 1 and %d, got %d", limits.MaxFPS, fps)
+		}
+		return time.Second / time.Duration(fps), nil
+	}
+	speed := int(req.GetSpeed())
+	if speed == 0 {
+		speed = limits.DefaultSpeed
+	}
+	if speed < limits.MinSpeed || speed > limits.MaxSpeed {
+		return 0, apierrors.ErrBadOption.WithDetail("speed must be between %d and %d, got %d", limits.MinSpeed, limits.MaxSpeed, speed)
+	}
+	return shout.SpeedIntervals[speed-1], nil
+}
+
+// statusCodes map the HTTP statuses of API errors to gRPC codes.
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// toStatus converts err to a gRPC status error. API errors keep their
+// message; render timeouts are DeadlineExceeded; anything else is
+// Internal, without exposing its text.
+func toStatus(err error) error {
+	var apiErr *apierrors.Error
+	var optErr *shout.OptionError
+	switch {
+	case errors.As(err, &apiErr):
+		code, ok := statusCodes[apiErr.Status]
+		if !ok {
+			code = codes.Internal
+		}
+		return status.Error(code, apiErr.Error())
+	case errors.As(err, &optErr):
+		return status.Error(codes.InvalidArgument, apierrors.ErrBadOption.WithDetail("%v", optErr.Err).Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, apierrors.ErrRenderTimeout.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, apierrors.ErrRenderFailed.Error())
+}
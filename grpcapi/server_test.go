@@ -0,0 +1,225 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the API over an in-memory connection backed by the
+// repository's fonts and returns a client for it.
+func newTestClient(t *testing.T) ShoutClient {
+	t.Helper()
+
+	cfg, err := config.LoadFromEnv(map[string]string{
+		"SHOUT_FONTS_PATH":            "../fonts",
+		"SHOUT_FONTS_ALLOWED":         "standard,doom,small",
+		"SHOUT_TEXT_MAX_LENGTH":       "20",
+		"SHOUT_STREAMING_MAX_FPS":     "1000",
+		"SHOUT_STREAMING_MAX_STREAMS": "2",
+	})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	fonts := render.NewFontCache()
+	if err := fonts.LoadFonts(cfg.Fonts); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterShoutServer(server, New(Deps{Config: cfg, Fonts: fonts}))
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewShoutClient(conn)
+}
+
+func TestRender(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		req      *RenderRequest
+		wantCode codes.Code
+		check    func(t *testing.T, resp *RenderResponse)
+	}{
+		{
+			name: "plain",
+			req:  &RenderRequest{Text: "HI", Options: &Options{Font: "small", Seed: "42"}},
+			check: func(t *testing.T, resp *RenderResponse) {
+				want, err := shout.Render(ctx, "HI", shout.Options{Font: "small"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.GetBanner() != want {
+					t.Errorf("banner =\n%s\nwant\n%s", resp.GetBanner(), want)
+				}
+				if resp.GetFont() != "small" || resp.GetSeed() != "42" {
+					t.Errorf("font, seed = %q, %q, want small, 42", resp.GetFont(), resp.GetSeed())
+				}
+			},
+		},
+		{
+			name: "colored",
+			req:  &RenderRequest{Text: "HI", Options: &Options{Color: "fire"}},
+			check: func(t *testing.T, resp *RenderResponse) {
+				if !strings.Contains(resp.GetBanner(), "\x1b[38;2;") {
+					t.Errorf("banner has no 24-bit colors: %q", resp.GetBanner())
+				}
+			},
+		},
+		{
+			name: "reduced colors",
+			req:  &RenderRequest{Text: "HI", Options: &Options{Color: "fire", Term: "dumb"}},
+			check: func(t *testing.T, resp *RenderResponse) {
+				if strings.Contains(resp.GetBanner(), "\x1b[") {
+					t.Errorf("banner has colors for a dumb terminal: %q", resp.GetBanner())
+				}
+			},
+		},
+		{
+			name: "same seed, same random font",
+			req:  &RenderRequest{Text: "HI", Options: &Options{Font: "random", Seed: "7"}},
+			check: func(t *testing.T, resp *RenderResponse) {
+				again, err := client.Render(ctx, &RenderRequest{Text: "HI", Options: &Options{Font: "random", Seed: "7"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again.GetFont() != resp.GetFont() {
+					t.Errorf("seed 7 drew %s, then %s", resp.GetFont(), again.GetFont())
+				}
+			},
+		},
+		{name: "no text", req: &RenderRequest{}, wantCode: codes.InvalidArgument},
+		{name: "text too long", req: &RenderRequest{Text: strings.Repeat("A", 21)}, wantCode: codes.InvalidArgument},
+		{name: "bad color", req: &RenderRequest{Text: "HI", Options: &Options{Color: "nope"}}, wantCode: codes.InvalidArgument},
+		{name: "bad color mode", req: &RenderRequest{Text: "HI", Options: &Options{Color: "red,blue", Cmode: "nope"}}, wantCode: codes.InvalidArgument},
+		{name: "too many cols", req: &RenderRequest{Text: "HI", Options: &Options{Cols: 100000}}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Render(ctx, tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("Render() code = %v, want %v (%v)", got, tt.wantCode, err)
+			}
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+}
+
+// receive reads a stream to its end and returns its frames.
+func receive(t *testing.T, stream Shout_StreamFramesClient) ([]*Frame, error) {
+	t.Helper()
+
+	var frames []*Frame
+	for {
+		frame, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return frames, nil
+		}
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
+
+func TestStreamFrames(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		req        *StreamFramesRequest
+		wantCode   codes.Code
+		wantFrames int
+	}{
+		{
+			name:       "loops",
+			req:        &StreamFramesRequest{Text: "HI", Anim: "typewriter", Fps: 1000, Loops: 2},
+			wantFrames: 2 * (2 + shout.TypewriterHold),
+		},
+		{
+			name:       "preset",
+			req:        &StreamFramesRequest{Text: "HI", Anim: "typewriter+fire", Fps: 1000, Loops: 1},
+			wantFrames: 2 + shout.TypewriterHold,
+		},
+		{name: "unknown animation", req: &StreamFramesRequest{Text: "HI", Anim: "spin"}, wantCode: codes.InvalidArgument},
+		{name: "preset and color", req: &StreamFramesRequest{Text: "HI", Anim: "fire", Options: &Options{Color: "red"}}, wantCode: codes.InvalidArgument},
+		{name: "bad fps", req: &StreamFramesRequest{Text: "HI", Fps: 5000}, wantCode: codes.InvalidArgument},
+		{name: "bad speed", req: &StreamFramesRequest{Text: "HI", Speed: 11}, wantCode: codes.InvalidArgument},
+		{name: "bad loops", req: &StreamFramesRequest{Text: "HI", Loops: -1}, wantCode: codes.InvalidArgument},
+		{name: "no text", req: &StreamFramesRequest{}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.StreamFrames(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("StreamFrames() error = %v", err)
+			}
+			frames, err := receive(t, stream)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("StreamFrames() code = %v, want %v (%v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if len(frames) != tt.wantFrames {
+				t.Errorf("stream sent %d frames, want %d", len(frames), tt.wantFrames)
+			}
+			for i, frame := range frames {
+				if want := i == len(frames)-1; frame.GetLast() != want {
+					t.Errorf("frame %d last = %v, want %v", i, frame.GetLast(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamFramesTimeout(t *testing.T) {
+	client := newTestClient(t)
+
+	// An endless animation ends with its timeout
+	stream, err := client.StreamFrames(context.Background(), &StreamFramesRequest{Text: "HI", Anim: "scroll", Fps: 100, Timeout: 1})
+	if err != nil {
+		t.Fatalf("StreamFrames() error = %v", err)
+	}
+	frames, err := receive(t, stream)
+	if err != nil {
+		t.Fatalf("StreamFrames() error = %v", err)
+	}
+	if len(frames) == 0 || !frames[len(frames)-1].GetLast() {
+		t.Fatalf("stream of %d frames didn't end with the last frame", len(frames))
+	}
+	if got := frames[len(frames)-1].GetIndex(); got != 99 {
+		t.Errorf("last frame index = %d, want 99", got)
+	}
+}
@@ -0,0 +1,506 @@
+// The shout.sh gRPC API, mirroring the HTTP API for internal services.
+// Regenerate the Go code with go generate ./grpcapi.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: shout.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Options are the drawing options shared by every RPC, named after the
+// query parameters.
+type Options struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Font name, or "random"; misspelled names use the closest match.
+	Font string `protobuf:"bytes,1,opt,name=font,proto3" json:"font,omitempty"`
+	// Color preset, #RRGGBB, rgb(r,g,b), color name, or a comma-separated list.
+	Color string `protobuf:"bytes,2,opt,name=color,proto3" json:"color,omitempty"`
+	// How a color list cycles: char, word or line.
+	Cmode string `protobuf:"bytes,3,opt,name=cmode,proto3" json:"cmode,omitempty"`
+	// Text alignment within cols: left, center or right.
+	Align string `protobuf:"bytes,4,opt,name=align,proto3" json:"align,omitempty"`
+	// Terminal width and height; 0 is unknown.
+	Cols int32 `protobuf:"varint,5,opt,name=cols,proto3" json:"cols,omitempty"`
+	Rows int32 `protobuf:"varint,6,opt,name=rows,proto3" json:"rows,omitempty"`
+	// Terminal type, as in $TERM, which colors are reduced for.
+	Term string `protobuf:"bytes,7,opt,name=term,proto3" json:"term,omitempty"`
+	// Seed for random choices such as font=random.
+	Seed          string `protobuf:"bytes,8,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Options) Reset() {
+	*x = Options{}
+	mi := &file_shout_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Options) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Options) ProtoMessage() {}
+
+func (x *Options) ProtoReflect() protoreflect.Message {
+	mi := &file_shout_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Options.ProtoReflect.Descriptor instead.
+func (*Options) Descriptor() ([]byte, []int) {
+	return file_shout_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Options) GetFont() string {
+	if x != nil {
+		return x.Font
+	}
+	return ""
+}
+
+func (x *Options) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Options) GetCmode() string {
+	if x != nil {
+		return x.Cmode
+	}
+	return ""
+}
+
+func (x *Options) GetAlign() string {
+	if x != nil {
+		return x.Align
+	}
+	return ""
+}
+
+func (x *Options) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *Options) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Options) GetTerm() string {
+	if x != nil {
+		return x.Term
+	}
+	return ""
+}
+
+func (x *Options) GetSeed() string {
+	if x != nil {
+		return x.Seed
+	}
+	return ""
+}
+
+type RenderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Options       *Options               `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderRequest) Reset() {
+	*x = RenderRequest{}
+	mi := &file_shout_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderRequest) ProtoMessage() {}
+
+func (x *RenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shout_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderRequest.ProtoReflect.Descriptor instead.
+func (*RenderRequest) Descriptor() ([]byte, []int) {
+	return file_shout_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RenderRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *RenderRequest) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type RenderResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The banner, ending in a newline.
+	Banner string `protobuf:"bytes,1,opt,name=banner,proto3" json:"banner,omitempty"`
+	// The font the banner was drawn in.
+	Font string `protobuf:"bytes,2,opt,name=font,proto3" json:"font,omitempty"`
+	// The seed used for random choices.
+	Seed          string `protobuf:"bytes,3,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderResponse) Reset() {
+	*x = RenderResponse{}
+	mi := &file_shout_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderResponse) ProtoMessage() {}
+
+func (x *RenderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_shout_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderResponse.ProtoReflect.Descriptor instead.
+func (*RenderResponse) Descriptor() ([]byte, []int) {
+	return file_shout_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RenderResponse) GetBanner() string {
+	if x != nil {
+		return x.Banner
+	}
+	return ""
+}
+
+func (x *RenderResponse) GetFont() string {
+	if x != nil {
+		return x.Font
+	}
+	return ""
+}
+
+func (x *RenderResponse) GetSeed() string {
+	if x != nil {
+		return x.Seed
+	}
+	return ""
+}
+
+type StreamFramesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Text    string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Options *Options               `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// Animation, as in ?anim=, such as "scroll+rainbow".
+	Anim string `protobuf:"bytes,3,opt,name=anim,proto3" json:"anim,omitempty"`
+	// Animation speed, 1-10, or frames per second, which overrides it.
+	Speed int32 `protobuf:"varint,4,opt,name=speed,proto3" json:"speed,omitempty"`
+	Fps   int32 `protobuf:"varint,5,opt,name=fps,proto3" json:"fps,omitempty"`
+	// Passes of a finite animation before the stream ends; 0 repeats until
+	// the timeout.
+	Loops int32 `protobuf:"varint,6,opt,name=loops,proto3" json:"loops,omitempty"`
+	// Seconds before the stream ends; 0 is the server default.
+	Timeout       int32 `protobuf:"varint,7,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFramesRequest) Reset() {
+	*x = StreamFramesRequest{}
+	mi := &file_shout_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFramesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFramesRequest) ProtoMessage() {}
+
+func (x *StreamFramesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_shout_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFramesRequest.ProtoReflect.Descriptor instead.
+func (*StreamFramesRequest) Descriptor() ([]byte, []int) {
+	return file_shout_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamFramesRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *StreamFramesRequest) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *StreamFramesRequest) GetAnim() string {
+	if x != nil {
+		return x.Anim
+	}
+	return ""
+}
+
+func (x *StreamFramesRequest) GetSpeed() int32 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *StreamFramesRequest) GetFps() int32 {
+	if x != nil {
+		return x.Fps
+	}
+	return 0
+}
+
+func (x *StreamFramesRequest) GetLoops() int32 {
+	if x != nil {
+		return x.Loops
+	}
+	return 0
+}
+
+func (x *StreamFramesRequest) GetTimeout() int32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+// Frame is one frame of an animation.
+type Frame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The frame number, counting from 0; a gap means frames were skipped.
+	Index int64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// The frame, colored, without the control sequences that redraw it in
+	// place.
+	Content string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	// Whether this is the last frame.
+	Last          bool `protobuf:"varint,3,opt,name=last,proto3" json:"last,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	mi := &file_shout_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_shout_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_shout_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Frame) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Frame) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Frame) GetLast() bool {
+	if x != nil {
+		return x.Last
+	}
+	return false
+}
+
+var File_shout_proto protoreflect.FileDescriptor
+
+const file_shout_proto_rawDesc = "" +
+	"\n" +
+	"\vshout.proto\x12\bshout.v1\"\xaf\x01\n" +
+	"\aOptions\x12\x12\n" +
+	"\x04font\x18\x01 \x01(\tR\x04font\x12\x14\n" +
+	"\x05color\x18\x02 \x01(\tR\x05color\x12\x14\n" +
+	"\x05cmode\x18\x03 \x01(\tR\x05cmode\x12\x14\n" +
+	"\x05align\x18\x04 \x01(\tR\x05align\x12\x12\n" +
+	"\x04cols\x18\x05 \x01(\x05R\x04cols\x12\x12\n" +
+	"\x04rows\x18\x06 \x01(\x05R\x04rows\x12\x12\n" +
+	"\x04term\x18\a \x01(\tR\x04term\x12\x12\n" +
+	"\x04seed\x18\b \x01(\tR\x04seed\"P\n" +
+	"\rRenderRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12+\n" +
+	"\aoptions\x18\x02 \x01(\v2\x11.shout.v1.OptionsR\aoptions\"P\n" +
+	"\x0eRenderResponse\x12\x16\n" +
+	"\x06banner\x18\x01 \x01(\tR\x06banner\x12\x12\n" +
+	"\x04font\x18\x02 \x01(\tR\x04font\x12\x12\n" +
+	"\x04seed\x18\x03 \x01(\tR\x04seed\"\xc2\x01\n" +
+	"\x13StreamFramesRequest\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12+\n" +
+	"\aoptions\x18\x02 \x01(\v2\x11.shout.v1.OptionsR\aoptions\x12\x12\n" +
+	"\x04anim\x18\x03 \x01(\tR\x04anim\x12\x14\n" +
+	"\x05speed\x18\x04 \x01(\x05R\x05speed\x12\x10\n" +
+	"\x03fps\x18\x05 \x01(\x05R\x03fps\x12\x14\n" +
+	"\x05loops\x18\x06 \x01(\x05R\x05loops\x12\x18\n" +
+	"\atimeout\x18\a \x01(\x05R\atimeout\"K\n" +
+	"\x05Frame\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
+	"\x04last\x18\x03 \x01(\bR\x04last2\x86\x01\n" +
+	"\x05Shout\x12;\n" +
+	"\x06Render\x12\x17.shout.v1.RenderRequest\x1a\x18.shout.v1.RenderResponse\x12@\n" +
+	"\fStreamFrames\x12\x1d.shout.v1.StreamFramesRequest\x1a\x0f.shout.v1.Frame0\x01B'Z%github.com/ryanlewis/shout-sh/grpcapib\x06proto3"
+
+var (
+	file_shout_proto_rawDescOnce sync.Once
+	file_shout_proto_rawDescData []byte
+)
+
+func file_shout_proto_rawDescGZIP() []byte {
+	file_shout_proto_rawDescOnce.Do(func() {
+		file_shout_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_shout_proto_rawDesc), len(file_shout_proto_rawDesc)))
+	})
+	return file_shout_proto_rawDescData
+}
+
+var file_shout_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_shout_proto_goTypes = []any{
+	(*Options)(nil),             // 0: shout.v1.Options
+	(*RenderRequest)(nil),       // 1: shout.v1.RenderRequest
+	(*RenderResponse)(nil),      // 2: shout.v1.RenderResponse
+	(*StreamFramesRequest)(nil), // 3: shout.v1.StreamFramesRequest
+	(*Frame)(nil),               // 4: shout.v1.Frame
+}
+var file_shout_proto_depIdxs = []int32{
+	0, // 0: shout.v1.RenderRequest.options:type_name -> shout.v1.Options
+	0, // 1: shout.v1.StreamFramesRequest.options:type_name -> shout.v1.Options
+	1, // 2: shout.v1.Shout.Render:input_type -> shout.v1.RenderRequest
+	3, // 3: shout.v1.Shout.StreamFrames:input_type -> shout.v1.StreamFramesRequest
+	2, // 4: shout.v1.Shout.Render:output_type -> shout.v1.RenderResponse
+	4, // 5: shout.v1.Shout.StreamFrames:output_type -> shout.v1.Frame
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_shout_proto_init() }
+func file_shout_proto_init() {
+	if File_shout_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_shout_proto_rawDesc), len(file_shout_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_shout_proto_goTypes,
+		DependencyIndexes: file_shout_proto_depIdxs,
+		MessageInfos:      file_shout_proto_msgTypes,
+	}.Build()
+	File_shout_proto = out.File
+	file_shout_proto_goTypes = nil
+	file_shout_proto_depIdxs = nil
+}
@@ -0,0 +1,178 @@
+// The shout.sh gRPC API, mirroring the HTTP API for internal services.
+// Regenerate the Go code with go generate ./grpcapi.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: shout.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Shout_Render_FullMethodName       = "/shout.v1.Shout/Render"
+	Shout_StreamFrames_FullMethodName = "/shout.v1.Shout/StreamFrames"
+)
+
+// ShoutClient is the client API for Shout service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Shout renders ASCII art banners.
+type ShoutClient interface {
+	// Render draws a static banner, as GET /{text} does.
+	Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderResponse, error)
+	// StreamFrames streams the frames of an animated banner, as GET /p/{text}
+	// does. Frames are sent at the requested rate; when the client reads
+	// slower than that, frames are skipped rather than queued.
+	StreamFrames(ctx context.Context, in *StreamFramesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Frame], error)
+}
+
+type shoutClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShoutClient(cc grpc.ClientConnInterface) ShoutClient {
+	return &shoutClient{cc}
+}
+
+func (c *shoutClient) Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenderResponse)
+	err := c.cc.Invoke(ctx, Shout_Render_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shoutClient) StreamFrames(ctx context.Context, in *StreamFramesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Frame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Shout_ServiceDesc.Streams[0], Shout_StreamFrames_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamFramesRequest, Frame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shout_StreamFramesClient = grpc.ServerStreamingClient[Frame]
+
+// ShoutServer is the server API for Shout service.
+// All implementations must embed UnimplementedShoutServer
+// for forward compatibility.
+//
+// Shout renders ASCII art banners.
+type ShoutServer interface {
+	// Render draws a static banner, as GET /{text} does.
+	Render(context.Context, *RenderRequest) (*RenderResponse, error)
+	// StreamFrames streams the frames of an animated banner, as GET /p/{text}
+	// does. Frames are sent at the requested rate; when the client reads
+	// slower than that, frames are skipped rather than queued.
+	StreamFrames(*StreamFramesRequest, grpc.ServerStreamingServer[Frame]) error
+	mustEmbedUnimplementedShoutServer()
+}
+
+// UnimplementedShoutServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShoutServer struct{}
+
+func (UnimplementedShoutServer) Render(context.Context, *RenderRequest) (*RenderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Render not implemented")
+}
+func (UnimplementedShoutServer) StreamFrames(*StreamFramesRequest, grpc.ServerStreamingServer[Frame]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFrames not implemented")
+}
+func (UnimplementedShoutServer) mustEmbedUnimplementedShoutServer() {}
+func (UnimplementedShoutServer) testEmbeddedByValue()               {}
+
+// UnsafeShoutServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShoutServer will
+// result in compilation errors.
+type UnsafeShoutServer interface {
+	mustEmbedUnimplementedShoutServer()
+}
+
+func RegisterShoutServer(s grpc.ServiceRegistrar, srv ShoutServer) {
+	// If the following call pancis, it indicates UnimplementedShoutServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Shout_ServiceDesc, srv)
+}
+
+func _Shout_Render_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShoutServer).Render(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shout_Render_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShoutServer).Render(ctx, req.(*RenderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shout_StreamFrames_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFramesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShoutServer).StreamFrames(m, &grpc.GenericServerStream[StreamFramesRequest, Frame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Shout_StreamFramesServer = grpc.ServerStreamingServer[Frame]
+
+// Shout_ServiceDesc is the grpc.ServiceDesc for Shout service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Shout_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shout.v1.Shout",
+	HandlerType: (*ShoutServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Render",
+			Handler:    _Shout_Render_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFrames",
+			Handler:       _Shout_StreamFrames_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "shout.proto",
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// reloadConfig reads the configuration for ReloadConfig. Tests replace it
+// to avoid touching the process environment.
+var reloadConfig = config.Reload
+
+// liveSettings are the settings ReloadConfig applies to the HTTP API
+// without a restart, by environment variable or by prefix ending in _,
+// with the exceptions in restartSettings. The rest are read once, at
+// startup.
+var liveSettings = []string{
+	"SHOUT_VERSION",
+	"SHOUT_FONTS_",
+	"SHOUT_TEXT_MAX_LENGTH",
+	"SHOUT_TEXT_DEFAULT_ALIGN",
+	"SHOUT_TEXT_DEFAULT_BORDER",
+	"SHOUT_TEXT_DEFAULT_COLOR",
+	"SHOUT_TEXT_DEFAULT_COLOR_MODE",
+	"SHOUT_TEXT_DEFAULT_MAX_WIDTH",
+	"SHOUT_TEXT_OVERFLOW",
+	"SHOUT_TEXT_FILTER",
+	"SHOUT_TEXT_FILTER_WORDS",
+	"SHOUT_TEXT_FILTER_FILE",
+	"SHOUT_TEXT_PLACEHOLDERS",
+	"SHOUT_TEXT_PRESETS",
+	"SHOUT_TERMINAL_",
+	"SHOUT_STREAMING_",
+	"SHOUT_SERVER_STATIC_TIMEOUT_MS",
+	"SHOUT_SERVER_CACHE_CONTROL",
+	"SHOUT_SERVER_STREAM_WRITE_TIMEOUT",
+	"SHOUT_ANALYTICS_ENABLED",
+	"SHOUT_FORTUNE_FONT",
+	"SHOUT_FORTUNE_WIDTH",
+	"SHOUT_ANSI_MAX_BYTES",
+	"SHOUT_GIF_MAX_FRAMES",
+	"SHOUT_GIF_MAX_COLS",
+	"SHOUT_GIF_MAX_ROWS",
+	"SHOUT_GIF_SCALE",
+	"SHOUT_KEY_PRESETS_MAX_PER_KEY",
+}
+
+// restartSettings are the settings under a liveSettings prefix that still
+// need a restart.
+var restartSettings = []string{
+	"SHOUT_FONTS_WATCH",
+	"SHOUT_FONTS_MEMORY_BUDGET",
+	"SHOUT_FONTS_WARMUP",
+	"SHOUT_STREAMING_MAX_STREAMS",
+}
+
+// RegisterAdmin mounts the operator endpoints on the admin app.
+// These routes must only be exposed on the internal admin port.
+//
+// Parameters:
+//   - app: the Fiber app serving the admin port
+func (h *Handler) RegisterAdmin(app *fiber.App) {
+	app.Use(middleware.SecurityHeaders(h.securityConfig()))
+	app.Get("/stats", h.Stats)
+	app.Get("/metrics", h.Prometheus)
+	app.Get("/readyz", h.Readyz)
+	app.Post("/fonts/reload", h.ReloadFonts)
+	app.Get("/config", h.ShowConfig)
+	app.Post("/reload", h.ReloadConfig)
+	app.Get("/openapi.json", h.OpenAPI)
+	app.Get("/docs", middleware.CSP(firstOf(h.securityConfig().DocsCSP, docsCSP)), h.SwaggerUI)
+	if h.access != nil {
+		app.Post("/access/reload", h.ReloadAccess)
+	}
+	if h.quotas != nil {
+		app.Get("/quotas", h.Quotas)
+		app.Get("/quotas/:client", h.Quota)
+		app.Delete("/quotas/:client", h.ResetQuota)
+	}
+}
+
+// Stats reports request counters, bots turned away, the render duration
+// histogram, requests by country when GeoIP is on, and font and render
+// cache occupancy as JSON.
+//
+// Example:
+//
+//	curl localhost:9090/stats
+func (h *Handler) Stats(c *fiber.Ctx) error {
+	snap := h.metrics.Snapshot()
+	return c.JSON(fiber.Map{
+		"requests": snap.Requests,
+		"streams": fiber.Map{
+			"active":        h.streams.GetActiveCount(),
+			"droppedFrames": snap.Streams.DroppedFrames,
+			"degraded":      snap.Streams.Degraded,
+		},
+		"bots":           snap.Bots,
+		"renderDuration": snap.RenderDuration,
+		"fontCache":      h.fonts.Stats(),
+		"renderCache":    h.cache.Stats(),
+		"countries":      h.metrics.Countries(),
+		"usage": fiber.Map{
+			"fonts":      h.metrics.Usage(types.UsageFont),
+			"colors":     h.metrics.Usage(types.UsageColor),
+			"animations": h.metrics.Usage(types.UsageAnimation),
+		},
+	})
+}
+
+// ReloadFonts re-reads the fonts directory without restarting the service
+// and reports the fonts that are now available.
+//
+// Example:
+//
+//	curl -X POST localhost:9090/fonts/reload
+func (h *Handler) ReloadFonts(c *fiber.Ctx) error {
+	if err := h.fonts.LoadFonts(h.config().Fonts); err != nil {
+		log.Printf("Error reloading fonts: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "reloaded",
+		"fonts":  h.fonts.ListFonts(),
+	})
+}
+
+// ReloadAccess re-reads the IP access lists, as a change to
+// SHOUT_ACCESS_FILE does. A list that fails to load leaves the current
+// one in force.
+//
+// Example:
+//
+//	curl -X POST localhost:9090/access/reload
+func (h *Handler) ReloadAccess(c *fiber.Ctx) error {
+	if err := h.access.Reload(); err != nil {
+		log.Printf("Error reloading access list: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{"status": "reloaded"})
+}
+
+// ShowConfig reports the configuration in effect, every setting keyed by
+// its environment variable, with secrets redacted.
+//
+// Example:
+//
+//	curl localhost:9090/config
+func (h *Handler) ShowConfig(c *fiber.Ctx) error {
+	cfg := h.config()
+	if cfg == nil {
+		return c.JSON(fiber.Map{})
+	}
+	return c.JSON(cfg.Settings())
+}
+
+// ReloadConfig re-reads the configuration from the environment, the .env
+// file and the Consul KV prefix, and the fonts directory, without
+// restarting the service. It reports the settings that changed, and those
+// of them that only take effect after a restart. A configuration that
+// fails to load or validate, or whose fonts or blocked word list fail to
+// load, leaves the current one in force.
+//
+// Example:
+//
+//	curl -X POST localhost:9090/reload
+func (h *Handler) ReloadConfig(c *fiber.Ctx) error {
+	changed, restart, err := h.Reconfigure()
+	if err != nil {
+		status := fiber.StatusUnprocessableEntity
+		if errors.As(err, new(fontsError)) {
+			status = fiber.StatusInternalServerError
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":          "reloaded",
+		"fonts":           h.fonts.ListFonts(),
+		"changed":         changed,
+		"restartRequired": restart,
+	})
+}
+
+// fontsError is a reload whose fonts failed to load.
+type fontsError struct{ error }
+
+// Reconfigure reloads the configuration and fonts as ReloadConfig does,
+// for reloads that don't come over HTTP, such as a change to the Consul
+// KV prefix.
+//
+// Returns:
+//   - changed: the settings that changed, by environment variable
+//   - restart: those of them that only take effect after a restart
+//   - error: the configuration, its fonts or its blocked word list failed
+//     to load, leaving the current one in force
+//
+// Example:
+//
+//	go config.WatchRemote(ctx, cfg.Remote, func() { h.Reconfigure() })
+func (h *Handler) Reconfigure() (changed, restart []string, err error) {
+	cfg, err := reloadConfig()
+	if err != nil {
+		log.Printf("Error reloading config: %v", err)
+		return nil, nil, err
+	}
+	filter, err := render.LoadWordFilter(cfg.Text)
+	if err != nil {
+		log.Printf("Error reloading word filter: %v", err)
+		return nil, nil, err
+	}
+	if err := h.fonts.LoadFonts(cfg.Fonts); err != nil {
+		log.Printf("Error reloading fonts: %v", err)
+		return nil, nil, fontsError{err}
+	}
+
+	changed, restart = []string{}, []string{}
+	if old := h.config(); old != nil {
+		before, after := old.Settings(), cfg.Settings()
+		for name, value := range after {
+			if before[name] == value {
+				continue
+			}
+			changed = append(changed, name)
+			if !liveSetting(name) {
+				restart = append(restart, name)
+			}
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(restart)
+	h.cfg.Store(cfg)
+	h.filter.Store(filter)
+	log.Printf("Config reloaded: %d settings changed, %d need a restart", len(changed), len(restart))
+	return changed, restart, nil
+}
+
+// liveSetting reports whether ReloadConfig applies a change to the
+// setting named by environment variable name.
+func liveSetting(name string) bool {
+	if slices.Contains(restartSettings, name) {
+		return false
+	}
+	return slices.ContainsFunc(liveSettings, func(live string) bool {
+		if strings.HasSuffix(live, "_") {
+			return strings.HasPrefix(name, live)
+		}
+		return name == live
+	})
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/ipaccess"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestReloadFonts(t *testing.T) {
+	h := newTestHandler(t)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	// Drop a font from the cache's configuration and reload
+	h.config().Fonts.Allowed = []string{"standard"}
+
+	resp, body := doRequest(t, admin, httptest.NewRequest("POST", "/fonts/reload", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+
+	var got struct {
+		Status string   `json:"status"`
+		Fonts  []string `json:"fonts"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Status != "reloaded" || len(got.Fonts) != 1 || got.Fonts[0] != "standard" {
+		t.Errorf("unexpected response: %+v", got)
+	}
+	if _, ok := h.fonts.GetFont("doom"); ok {
+		t.Error("doom should be evicted after reload")
+	}
+}
+
+func TestReloadFontsNotOnPublicApp(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("POST", "/fonts/reload", nil))
+	if resp.StatusCode == 200 {
+		t.Error("reload endpoint must not be exposed on the public app")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	h := newTestHandler(t)
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	// The new config allows longer text, drops doom and moves the port
+	next := *h.config()
+	next.Text.MaxLength = 30
+	next.Fonts.Allowed = []string{"standard", "small"}
+	next.Server.PublicPort = 8081
+	reloadConfig = func() (*config.Config, error) { return &next, nil }
+	t.Cleanup(func() { reloadConfig = config.Reload })
+
+	long := "/" + strings.Repeat("A", 25)
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", long, nil)); resp.StatusCode != 400 {
+		t.Fatalf("status before reload = %d, want 400 for text over 20 characters", resp.StatusCode)
+	}
+
+	resp, body := doRequest(t, admin, httptest.NewRequest("POST", "/reload", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	var got struct {
+		Status          string   `json:"status"`
+		Fonts           []string `json:"fonts"`
+		Changed         []string `json:"changed"`
+		RestartRequired []string `json:"restartRequired"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	wantChanged := []string{"SHOUT_FONTS_ALLOWED", "SHOUT_SERVER_PUBLIC_PORT", "SHOUT_TEXT_MAX_LENGTH"}
+	if got.Status != "reloaded" || !slices.Equal(got.Changed, wantChanged) || !slices.Equal(got.RestartRequired, []string{"SHOUT_SERVER_PUBLIC_PORT"}) {
+		t.Errorf("unexpected response: %+v", got)
+	}
+	if !slices.Equal(got.Fonts, []string{"small", "standard"}) {
+		t.Errorf("fonts = %v, want small and standard", got.Fonts)
+	}
+
+	if resp, body := doRequest(t, app, httptest.NewRequest("GET", long, nil)); resp.StatusCode != 200 {
+		t.Errorf("status after reload = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+}
+
+func TestReloadConfigFilter(t *testing.T) {
+	h := newTestHandler(t)
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	// The filter is off at startup, so there is no word list to swap
+	next := *h.config()
+	next.Text.Filter = render.FilterReject
+	next.Text.FilterWords = []string{"bad"}
+	reloadConfig = func() (*config.Config, error) { return &next, nil }
+	t.Cleanup(func() { reloadConfig = config.Reload })
+
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/BAD", nil)); resp.StatusCode != 200 {
+		t.Fatalf("status before reload = %d, want 200 with the filter off", resp.StatusCode)
+	}
+	resp, body := doRequest(t, admin, httptest.NewRequest("POST", "/reload", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("reload status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if !strings.Contains(body, `"restartRequired":[]`) {
+		t.Errorf("filter settings reported as needing a restart: %s", body)
+	}
+	if resp, body := doRequest(t, app, httptest.NewRequest("GET", "/BAD", nil)); resp.StatusCode != 400 {
+		t.Errorf("status after reload = %d, want 400 (body %q)", resp.StatusCode, body)
+	}
+}
+
+func TestReloadConfigInvalid(t *testing.T) {
+	h := newTestHandler(t)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+	current := h.config()
+
+	reloadConfig = func() (*config.Config, error) { return nil, errors.New("max fps must be positive, got 0") }
+	t.Cleanup(func() { reloadConfig = config.Reload })
+
+	resp, body := doRequest(t, admin, httptest.NewRequest("POST", "/reload", nil))
+	if resp.StatusCode != fiber.StatusUnprocessableEntity || !strings.Contains(body, "max fps") {
+		t.Fatalf("status = %d, body %q, want 422 with the error", resp.StatusCode, body)
+	}
+	if h.config() != current {
+		t.Error("a failed reload replaced the config")
+	}
+}
+
+func TestShowConfig(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Async.Secret = "hunter2"
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	resp, body := doRequest(t, admin, httptest.NewRequest("GET", "/config", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	var got map[string]string
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got["SHOUT_FONTS_DEFAULT"] != "standard" || got["SHOUT_TEXT_MAX_LENGTH"] != "20" {
+		t.Errorf("unexpected settings: %v", got)
+	}
+	if got["SHOUT_ASYNC_SECRET"] != "[redacted]" || strings.Contains(body, "hunter2") {
+		t.Errorf("SHOUT_ASYNC_SECRET = %q, want it redacted", got["SHOUT_ASYNC_SECRET"])
+	}
+
+	// Neither is on the public app
+	app := newTestAppFrom(h)
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/config", nil)); strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		t.Error("config must not be exposed on the public app")
+	}
+}
+
+func TestStats(t *testing.T) {
+	h := newTestHandler(t)
+	app := fiber.New()
+	h.Register(app)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	doRequest(t, app, httptest.NewRequest("GET", "/HI?f=doom", nil))
+	doRequest(t, app, httptest.NewRequest("GET", "/HI?f=doom", nil))
+
+	resp, body := doRequest(t, admin, httptest.NewRequest("GET", "/stats", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+
+	var got struct {
+		Requests struct {
+			Static int64 `json:"static"`
+		} `json:"requests"`
+		FontCache render.CacheStats `json:"fontCache"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Requests.Static != 2 {
+		t.Errorf("static requests = %d, want 2", got.Requests.Static)
+	}
+	// Each render looks the font up twice, to check it has the text's
+	// characters and to draw them
+	if got.FontCache.Fonts != 3 || got.FontCache.Resident != 1 || got.FontCache.Hits != 3 || got.FontCache.Bytes == 0 {
+		t.Errorf("unexpected font cache stats: %+v", got.FontCache)
+	}
+}
+
+func TestReloadAccess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.txt")
+	if err := os.WriteFile(path, []byte("deny 10.6.6.6\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	guard, err := ipaccess.NewGuard(config.AccessConfig{File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t)
+	h = New(Deps{Config: h.config(), Fonts: h.fonts, Access: guard})
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	if resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+
+	// app.Test requests come from 0.0.0.0
+	if err := os.WriteFile(path, []byte("deny 0.0.0.0/8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if resp, body := doRequest(t, admin, httptest.NewRequest("POST", "/access/reload", nil)); resp.StatusCode != 200 {
+		t.Fatalf("reload status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil))
+	if resp.StatusCode != 403 || resp.Header.Get("X-Shout-Error") != "ip_denied" {
+		t.Errorf("status = %d, code %q, want 403 ip_denied", resp.StatusCode, resp.Header.Get("X-Shout-Error"))
+	}
+
+	// A broken list keeps the current one
+	if err := os.WriteFile(path, []byte("nope\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if resp, _ := doRequest(t, admin, httptest.NewRequest("POST", "/access/reload", nil)); resp.StatusCode != 500 {
+		t.Errorf("reload of a broken list status = %d, want 500", resp.StatusCode)
+	}
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != 403 {
+		t.Errorf("status after a failed reload = %d, want 403", resp.StatusCode)
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// maxAlertmanagerBytes caps the webhook body; Alertmanager sends every
+// alert of a group in one payload.
+const maxAlertmanagerBytes = 256 << 10
+
+// Limits on what one webhook draws, so a storm of alerts still fits a
+// wall display.
+const (
+	maxAlertBanners     = 8
+	maxAlertDetailLines = 5
+)
+
+// Alert colors, the font of the status line under each banner, and the
+// status of resolved alerts.
+const (
+	firingColor    = "red"
+	resolvedColor  = "green"
+	alertLineFont  = "small"
+	alertsResolved = "resolved"
+)
+
+// severityRank orders banners: the most severe firing alerts come first.
+var severityRank = map[string]int{"critical": 0, "error": 1, "warning": 2, "info": 3}
+
+// alertmanagerParams are the query parameters of the Alertmanager route.
+var alertmanagerParams = []optionHelp{
+	{Names: []string{"f", "font"}, Help: "Font of the alert names; the status below is always small", Type: "string"},
+	{Names: []string{"mw", "maxwidth"}, Help: "Column the banners wrap at, overriding cols", Type: "integer"},
+	{Names: []string{"a", "align"}, Help: "Text alignment: left, center or right", Type: "string"},
+	colsParam,
+	termParam,
+	ncParam,
+}
+
+// alertmanagerWebhook is the part of Alertmanager's webhook payload
+// (version 4) the receiver draws.
+type alertmanagerWebhook struct {
+	Alerts []alertmanagerAlert `json:"alerts"`
+}
+
+// alertmanagerAlert is one alert of a webhook.
+type alertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// alertGroup is the alerts drawn under one banner: those with the same
+// name and status.
+type alertGroup struct {
+	name     string
+	resolved bool
+	severity string
+	alerts   []alertmanagerAlert
+}
+
+// Alertmanager receives a Prometheus Alertmanager webhook and draws each
+// alert name as a banner, red while firing and green once resolved, with
+// its status and severity below and a line per alert from its summary.
+// The response is plain text for a wall display, e.g. piped from a
+// webhook relay to a terminal. Alerts of the same name and status share
+// a banner.
+//
+// Example:
+//
+//	curl -d @alerts.json 'localhost:8080/integrations/alertmanager?cols=120'
+func (h *Handler) Alertmanager(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+	if len(c.Body()) > maxAlertmanagerBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxAlertmanagerBytes))
+	}
+
+	var webhook alertmanagerWebhook
+	if err := json.Unmarshal(c.Body(), &webhook); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+	groups := groupAlerts(webhook.Alerts)
+	if len(groups) == 0 {
+		return apierrors.Send(c, apierrors.ErrNoText.WithDetail("the webhook has no alerts"))
+	}
+
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	opts.Font = h.requestFont(c, opts.Font)
+	suggestion := h.suggestFont(c, opts.Font)
+	font := h.fonts.ResolveFont(opts.Font, h.defaultFont())
+	if font == nil {
+		return apierrors.Send(c, apierrors.ErrFontNotFound.WithDetail("%q", opts.Font))
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	cols := term.cols
+	if opts.MaxWidth > 0 {
+		cols = opts.MaxWidth
+	}
+
+	// Alert names come from rules, not people, so long ones are cut
+	// rather than refused
+	text := h.textConfig()
+	text.Overflow = "truncate"
+
+	ctx, cancel := h.renderContext(c, opts.Timeout)
+	defer cancel()
+
+	var b strings.Builder
+	for i, g := range groups {
+		if i == maxAlertBanners {
+			fmt.Fprintf(&b, "... and %d more\n", len(groups)-i)
+			break
+		}
+		name, _ := middleware.CheckText(g.name, text)
+		masked, err := middleware.FilterText(h.wordFilter(), text.Filter, name, h.metrics)
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+		color, status := firingColor, "FIRING"
+		if g.resolved {
+			color, status = resolvedColor, "RESOLVED"
+		}
+		if g.severity != "" {
+			status += " " + strings.ToUpper(g.severity)
+		}
+		status, _ = middleware.CheckText(status, text)
+
+		// The name in the requested font, and the status in a small one
+		// below it
+		banners := []struct {
+			text string
+			opts shout.Options
+		}{
+			{name, shout.Options{Font: font.Name, Masked: masked}},
+			{status, shout.Options{Font: alertLineFont}},
+		}
+		for _, banner := range banners {
+			banner.opts.Color, banner.opts.Align, banner.opts.Cols, banner.opts.Colors = color, h.alignment(opts), cols, term.colors
+			output, err := h.shout.Render(ctx, banner.text, banner.opts)
+			if err != nil {
+				return h.renderFailed(c, err, suggestion)
+			}
+			b.WriteString(output)
+		}
+		for _, detail := range alertDetails(g.alerts) {
+			b.WriteString(detail + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(b.String())
+}
+
+// groupAlerts groups alerts by name and status: firing groups first, most
+// severe first, then by name.
+func groupAlerts(alerts []alertmanagerAlert) []alertGroup {
+	var groups []alertGroup
+	index := map[string]int{}
+	for _, a := range alerts {
+		name := cmp.Or(a.Labels["alertname"], "ALERT")
+		resolved := a.Status == alertsResolved
+		key := fmt.Sprintf("%s\x00%t", name, resolved)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, alertGroup{name: name, resolved: resolved, severity: a.Labels["severity"]})
+		}
+		groups[i].alerts = append(groups[i].alerts, a)
+		// A group is as severe as its most severe alert
+		if rank(a.Labels["severity"]) < rank(groups[i].severity) {
+			groups[i].severity = a.Labels["severity"]
+		}
+	}
+	slices.SortStableFunc(groups, func(a, b alertGroup) int {
+		if a.resolved != b.resolved {
+			if a.resolved {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(rank(a.severity), rank(b.severity)), strings.Compare(a.name, b.name))
+	})
+	return groups
+}
+
+// rank returns the order of a severity; unknown severities come last.
+func rank(severity string) int {
+	if r, ok := severityRank[strings.ToLower(severity)]; ok {
+		return r
+	}
+	return len(severityRank)
+}
+
+// alertDetails returns a line for each alert of a group: its summary,
+// description or instance, at most maxAlertDetailLines of them.
+func alertDetails(alerts []alertmanagerAlert) []string {
+	var lines []string
+	for i, a := range alerts {
+		if i == maxAlertDetailLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(alerts)-i))
+			break
+		}
+		detail := render.SanitizeText(cmp.Or(a.Annotations["summary"], a.Annotations["description"], a.Labels["instance"]))
+		if detail != "" {
+			lines = append(lines, "  - "+detail)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// alertmanagerPayload is a webhook with two firing alerts of one rule and
+// a resolved one.
+const alertmanagerPayload = `{
+	"version": "4",
+	"status": "firing",
+	"alerts": [
+		{"status": "firing", "labels": {"alertname": "DiskFull", "severity": "warning", "instance": "db1"}, "annotations": {}},
+		{"status": "firing", "labels": {"alertname": "DiskFull", "severity": "critical", "instance": "db2"}, "annotations": {"summary": "Disk on db2 is 99% full"}},
+		{"status": "resolved", "labels": {"alertname": "HighLoad", "severity": "critical"}, "annotations": {}}
+	]
+}`
+
+func TestGroupAlerts(t *testing.T) {
+	alerts := []alertmanagerAlert{
+		{Status: "resolved", Labels: map[string]string{"alertname": "A", "severity": "critical"}},
+		{Status: "firing", Labels: map[string]string{"alertname": "B", "severity": "info"}},
+		{Status: "firing", Labels: map[string]string{"alertname": "C", "severity": "warning"}},
+		{Status: "firing", Labels: map[string]string{"alertname": "B", "severity": "critical"}},
+		{Status: "firing", Labels: map[string]string{"severity": "page"}},
+	}
+
+	groups := groupAlerts(alerts)
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.name+"/"+g.severity)
+	}
+	if want := []string{"B/critical", "C/warning", "ALERT/page", "A/critical"}; !slices.Equal(got, want) {
+		t.Errorf("groupAlerts() = %v, want %v", got, want)
+	}
+	if len(groups[0].alerts) != 2 || !groups[3].resolved {
+		t.Errorf("groups = %+v", groups)
+	}
+}
+
+func TestAlertDetails(t *testing.T) {
+	var alerts []alertmanagerAlert
+	for range maxAlertDetailLines + 2 {
+		alerts = append(alerts, alertmanagerAlert{Annotations: map[string]string{"summary": "disk\tfull"}})
+	}
+	lines := alertDetails(alerts)
+	if len(lines) != maxAlertDetailLines+1 || lines[0] != "  - disk full" || lines[maxAlertDetailLines] != "  ... and 2 more" {
+		t.Errorf("alertDetails() = %q", lines)
+	}
+}
+
+func TestAlertmanager(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		contains   []string
+	}{
+		{
+			name:       "firing and resolved",
+			path:       "/integrations/alertmanager?f=small",
+			body:       alertmanagerPayload,
+			wantStatus: 200,
+			contains: []string{
+				render.Color{R: 255}.Foreground(),
+				render.Color{G: 128}.Foreground(),
+				"  - Disk on db2 is 99% full\n",
+				"  - db1\n",
+			},
+		},
+		{name: "no colors", path: "/integrations/alertmanager?f=small&term=dumb", body: alertmanagerPayload, wantStatus: 200},
+		{name: "no alerts", path: "/integrations/alertmanager", body: `{"alerts": []}`, wantStatus: 400},
+		{name: "bad JSON", path: "/integrations/alertmanager", body: `{`, wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q:\n%s", want, body)
+				}
+			}
+			if strings.Contains(tt.path, "term=dumb") && strings.Contains(body, "\x1b[") {
+				t.Errorf("body is colored for a dumb terminal: %q", body)
+			}
+		})
+	}
+
+	// Firing alerts are drawn above resolved ones
+	_, body := doRequest(t, app, httptest.NewRequest("POST", "/integrations/alertmanager?f=small", strings.NewReader(alertmanagerPayload)))
+	red, green := strings.Index(body, render.Color{R: 255}.Foreground()), strings.Index(body, render.Color{G: 128}.Foreground())
+	if red < 0 || green < red {
+		t.Errorf("firing alerts aren't first:\n%s", body)
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/ansiart"
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+// Headers carrying the SAUCE metadata of played artwork.
+const (
+	HeaderSauceTitle  = "X-Sauce-Title"
+	HeaderSauceAuthor = "X-Sauce-Author"
+	HeaderSauceGroup  = "X-Sauce-Group"
+	HeaderSauceDate   = "X-Sauce-Date"
+)
+
+// Limits of the ?baud= playback speed.
+const (
+	minBaud = 300
+	maxBaud = 115200
+)
+
+// defaultANSIMaxBytes caps uploads when no config is set.
+const defaultANSIMaxBytes = 256 << 10
+
+// ansiTick is the time between chunks of baud-rate playback.
+var ansiTick = 50 * time.Millisecond
+
+// artName matches the names of artwork in the gallery directory.
+var artName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ansiParams are the query parameters of the artwork routes.
+var ansiParams = []optionHelp{
+	{Names: []string{"baud"}, Help: "Play the art back at a modem speed such as 2400 or 14400", Type: "integer"},
+	termParam,
+	ncParam,
+	formatParam,
+	screenParam,
+}
+
+// PlayANSI plays back .ANS artwork sent as the request body, translated
+// from CP437 to UTF-8. SAUCE metadata is stripped from the output and
+// returned in X-Sauce-* headers, and ?baud= streams the art at a modem's
+// speed instead of all at once.
+//
+// Example:
+//
+//	curl --data-binary @art.ans 'localhost:8080/ans?baud=9600'
+func (h *Handler) PlayANSI(c *fiber.Ctx) error {
+	body := c.Body()
+	if max := h.ansiMaxBytes(); len(body) > max {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", max))
+	}
+	// The body buffer is reused once the handler returns, but playback may
+	// outlive it
+	return h.playANSI(c, append([]byte(nil), body...))
+}
+
+// GalleryANSI plays back artwork from the SHOUT_ANSI_PATH directory by
+// name; the .ans extension is optional.
+//
+// Example:
+//
+//	curl 'localhost:8080/ans/welcome?baud=2400'
+func (h *Handler) GalleryANSI(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if !artName.MatchString(name) {
+		return apierrors.Send(c, apierrors.ErrArtNotFound)
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".ans") {
+		name += ".ans"
+	}
+
+	path := filepath.Join(h.ansiGallery(), name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return apierrors.Send(c, apierrors.ErrArtNotFound.WithDetail("%q", c.Params("name")))
+	}
+	if err != nil {
+		log.Printf("Error reading artwork %s: %v", path, err)
+		return apierrors.Send(c, apierrors.ErrRenderFailed)
+	}
+	return h.playANSI(c, data)
+}
+
+// playANSI translates the artwork and sends it, all at once or at the
+// requested baud rate.
+func (h *Handler) playANSI(c *fiber.Ctx, data []byte) error {
+	baud := c.QueryInt("baud", 0)
+	if baud != 0 && (baud < minBaud || baud > maxBaud) {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("baud must be between %d and %d, got %d", minBaud, maxBaud, baud))
+	}
+
+	art, sauce := ansiart.Split(data)
+	if sauce != nil {
+		for header, value := range map[string]string{
+			HeaderSauceTitle:  sauce.Title,
+			HeaderSauceAuthor: sauce.Author,
+			HeaderSauceGroup:  sauce.Group,
+			HeaderSauceDate:   sauce.Date,
+		} {
+			if value != "" {
+				c.Set(header, value)
+			}
+		}
+	}
+	output := ansiart.Translate(art, sauce.Columns())
+
+	if baud == 0 {
+		if c.Query("format") != formatStream {
+			// Record the art as a single chunk
+			return h.streamChunks(c, ansiTick, "", "", func(int) (string, bool, error) {
+				return output, true, nil
+			})
+		}
+		c.Set(fiber.HeaderContentType, textPlain)
+		return c.SendString(output)
+	}
+
+	// A modem sends ten bits per character
+	perTick := float64(baud) / 10 * ansiTick.Seconds()
+	chars, sent := 0, 0
+	return h.streamChunks(c, ansiTick, "", ansiReset, func(n int) (string, bool, error) {
+		// Advance by characters so no UTF-8 sequence is split
+		end := sent
+		for due := int(float64(n+1) * perTick); chars < due && end < len(output); chars++ {
+			_, size := utf8.DecodeRuneInString(output[end:])
+			end += size
+		}
+		chunk := output[sent:end]
+		sent = end
+		return chunk, sent == len(output), nil
+	})
+}
+
+// ansiGallery returns the artwork directory, or "" if there is none.
+func (h *Handler) ansiGallery() string {
+	cfg := h.config()
+	if cfg == nil {
+		return ""
+	}
+	return cfg.ANSI.Path
+}
+
+// ansiMaxBytes returns the largest artwork accepted.
+func (h *Handler) ansiMaxBytes() int {
+	cfg := h.config()
+	if cfg == nil || cfg.ANSI.MaxBytes < 1 {
+		return defaultANSIMaxBytes
+	}
+	return cfg.ANSI.MaxBytes
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testArt is a tiny .ANS file with a SAUCE record.
+func testArt() []byte {
+	record := make([]byte, 128)
+	copy(record, "SAUCE00")
+	copy(record[7:], "Blocks")
+	copy(record[42:], "Tester")
+	copy(record[82:], "19940101")
+	return append([]byte("\x1b[31m\xdb\xb2\xb1\xb0\x1b[0m\r\n\x1a"), record...)
+}
+
+// wantArt is testArt translated to UTF-8.
+const wantArt = "\x1b[31m█▓▒░\x1b[0m\r\n\x1b[0m"
+
+func TestPlayANSI(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().ANSI.MaxBytes = 1024
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("POST", "/ans", bytes.NewReader(testArt())))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if body != wantArt {
+		t.Errorf("body = %q, want %q", body, wantArt)
+	}
+	if resp.Header.Get(HeaderSauceTitle) != "Blocks" || resp.Header.Get(HeaderSauceAuthor) != "Tester" || resp.Header.Get(HeaderSauceDate) != "1994-01-01" {
+		t.Errorf("SAUCE headers = %v", resp.Header)
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		body       []byte
+		wantStatus int
+	}{
+		{name: "too large", target: "/ans", body: bytes.Repeat([]byte("A"), 1025), wantStatus: 413},
+		{name: "baud too slow", target: "/ans?baud=100", body: testArt(), wantStatus: 400},
+		{name: "baud too fast", target: "/ans?baud=1000000", body: testArt(), wantStatus: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("POST", tt.target, bytes.NewReader(tt.body)))
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+		})
+	}
+
+	if strings.Contains(h.HelpText("shout.sh"), "/ans/{name}") {
+		t.Error("help shouldn't list the gallery route without a gallery")
+	}
+}
+
+func TestPlayANSIBaud(t *testing.T) {
+	orig := ansiTick
+	ansiTick = time.Millisecond
+	t.Cleanup(func() { ansiTick = orig })
+
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("POST", "/ans?baud=9600", bytes.NewReader(testArt())))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if want := wantArt + ansiReset; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+	if n := h.streams.GetActiveCount(); n != 0 {
+		t.Errorf("active streams = %d after playback, want 0", n)
+	}
+}
+
+func TestGalleryANSI(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "blocks.ans"), testArt(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHandler(t)
+	h.config().ANSI.Path = dir
+	app := newTestAppFrom(h)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{name: "by name", target: "/ans/blocks", wantStatus: 200},
+		{name: "with extension", target: "/ans/blocks.ans", wantStatus: 200},
+		{name: "versioned", target: "/v1/ans/blocks", wantStatus: 200},
+		{name: "missing", target: "/ans/dragon", wantStatus: 404},
+		{name: "other files", target: "/ans/secret.txt", wantStatus: 404},
+		{name: "path traversal", target: "/ans/..%2Fblocks", wantStatus: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus == 200 && body != wantArt {
+				t.Errorf("body = %q, want %q", body, wantArt)
+			}
+			if tt.wantStatus == 404 && !strings.Contains(resp.Header.Get("X-Shout-Error"), "art_not_found") {
+				t.Errorf("error code = %q, want art_not_found", resp.Header.Get("X-Shout-Error"))
+			}
+		})
+	}
+
+	if !strings.Contains(h.HelpText("shout.sh"), "/ans/{name}") {
+		t.Error("help should list the gallery route when a gallery is configured")
+	}
+}
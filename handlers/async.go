@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/shortlink"
+)
+
+// Async render artifact formats, chosen with the format field.
+const (
+	artifactText = "text"
+	artifactSVG  = "svg"
+	artifactPNG  = "png"
+	artifactGIF  = "gif"
+)
+
+// artifactFeatures are the API key features the image artifacts need.
+var artifactFeatures = map[string]string{
+	artifactSVG: apikey.FeatureSVG,
+	artifactPNG: apikey.FeaturePNG,
+	artifactGIF: apikey.FeatureGIF,
+}
+
+// Callback headers: the job the callback is for, when it was signed and
+// the signature, "sha256=" and the hex HMAC-SHA256 of the timestamp, a
+// dot and the body, keyed with SHOUT_ASYNC_SECRET.
+const (
+	HeaderJob       = "X-Shout-Job"
+	HeaderTimestamp = "X-Shout-Timestamp"
+	HeaderSignature = "X-Shout-Signature"
+)
+
+// callbackAttempts is how many times a callback is tried before the
+// artifact is dropped.
+const callbackAttempts = 3
+
+// callbackBackoff is the wait before the second attempt, doubling after.
+// Tests shorten it.
+var callbackBackoff = time.Second
+
+// asyncClient sends callbacks. It refuses to connect to loopback, private
+// and link-local addresses, so callbacks can't reach inside the network
+// the server runs in. Tests replace it to trust a local TLS server.
+var asyncClient = func() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would hide where the callback really goes
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: 10 * time.Second, Control: publicAddressOnly}).DialContext
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}
+}()
+
+// asyncParams documents the JSON body fields of POST /render/async besides
+// the text and options.
+var (
+	callbackParam = optionHelp{Names: []string{"callback_url"}, Help: "The https URL the finished artifact is POSTed to", Type: "string"}
+	asyncParams   = []optionHelp{
+		{Names: []string{"format"}, Help: "The artifact: text (default), svg, png, or an animated gif if enabled", Type: "string"},
+		{Names: []string{"anim"}, Help: "Animation of a gif: colors, typewriter or scroll, optionally layered with + and a color preset", Type: "string"},
+		{Names: []string{"loops"}, Help: "Passes of a typewriter or scroll gif; 0 records until the timeout", Type: "integer"},
+	}
+)
+
+// asyncRequest is the JSON body of POST /render/async.
+type asyncRequest struct {
+	CallbackURL string `json:"callback_url"`
+	Format      string `json:"format"`
+	Anim        string `json:"anim"`
+	Loops       int    `json:"loops"`
+	shortlink.Spec
+}
+
+// asyncJob is a render waiting for a worker.
+type asyncJob struct {
+	id       string
+	callback *url.URL
+	format   string
+	text     string
+	opts     shout.Options
+}
+
+// asyncQueue holds the jobs waiting for the SHOUT_ASYNC_WORKERS workers.
+type asyncQueue struct {
+	jobs    chan asyncJob
+	mu      sync.Mutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// startAsync starts the async render workers, or returns nil when async
+// renders are disabled.
+func (h *Handler) startAsync() *asyncQueue {
+	cfg := h.config()
+	if cfg == nil || !cfg.Async.Enabled {
+		return nil
+	}
+	q := &asyncQueue{jobs: make(chan asyncJob, cfg.Async.QueueSize)}
+	q.workers.Add(cfg.Async.Workers)
+	for range cfg.Async.Workers {
+		go func() {
+			defer q.workers.Done()
+			for job := range q.jobs {
+				h.runJob(job)
+			}
+		}()
+	}
+	return q
+}
+
+// enqueue adds job to the queue, reporting false when it is full or
+// closed.
+func (q *asyncQueue) enqueue(job asyncJob) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops taking async renders and waits for the queued ones to be
+// rendered and delivered, or for ctx to be done.
+//
+// Parameters:
+//   - ctx: bounds how long to wait for queued jobs
+//
+// Returns:
+//   - error: ctx.Err() if jobs were still running when ctx was done
+func (h *Handler) Shutdown(ctx context.Context) error {
+	q := h.async
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RenderAsync checks the text and options in the JSON body, queues the
+// render and answers 202 with the job ID. When the artifact is ready it
+// is POSTed to callback_url, signed with SHOUT_ASYNC_SECRET; a render
+// that fails is reported to the callback as a JSON problem instead.
+//
+// Example:
+//
+//	curl -d '{"callback_url":"https://example.com/hook","format":"gif","text":"HI","anim":"typewriter","loops":1}' localhost:8080/render/async
+func (h *Handler) RenderAsync(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+	if err := h.entitled(c, apikey.FeatureAsync); err != nil {
+		return apierrors.Send(c, err)
+	}
+	if len(c.Body()) > maxSpecBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxSpecBytes))
+	}
+
+	var req asyncRequest
+	if err := json.Unmarshal(c.Body(), &req); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+	callback, err := h.callbackURL(req.CallbackURL)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	switch req.Format {
+	case "":
+		req.Format = artifactText
+	case artifactText, artifactSVG:
+	case artifactPNG:
+		if !h.features().EnablePNG {
+			return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("PNG export is disabled on this server").WithHint("use format=svg"))
+		}
+	case artifactGIF:
+		if !h.gifEnabled() {
+			return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("GIF export is disabled on this server").WithHint("use format=svg or png"))
+		}
+	default:
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("unknown format %q", req.Format).WithHint("use format=text, svg, png or gif"))
+	}
+	if feature, ok := artifactFeatures[req.Format]; ok {
+		if err := h.entitled(c, feature); err != nil {
+			return apierrors.Send(c, err)
+		}
+	}
+
+	text, opts, _, err := h.specOptions(c, req.Spec)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if req.Format == artifactGIF {
+		if opts, err = h.gifOptions(req, opts); err != nil {
+			return apierrors.Send(c, err)
+		}
+	}
+
+	job := asyncJob{id: newJobID(), callback: callback, format: req.Format, text: text, opts: opts}
+	if !h.async.enqueue(job) {
+		return apierrors.Send(c, apierrors.ErrQueueFull)
+	}
+
+	c.Status(fiber.StatusAccepted)
+	c.Set(HeaderJob, job.id)
+	if strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMEApplicationJSON) {
+		return c.JSON(fiber.Map{"id": job.id, "status": "queued"})
+	}
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString("Queued job " + job.id + "\n")
+}
+
+// callbackURL parses raw as an https callback URL, on one of
+// SHOUT_ASYNC_CALLBACK_HOSTS when that is set.
+func (h *Handler) callbackURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return nil, apierrors.ErrBadOption.WithDetail("callback_url must be an absolute https URL, got %q", raw)
+	}
+	if hosts := h.config().Async.CallbackHosts; len(hosts) > 0 && !slices.Contains(hosts, u.Host) {
+		return nil, apierrors.ErrBadOption.WithDetail("callbacks to %s aren't allowed on this server", u.Host)
+	}
+	return u, nil
+}
+
+// gifOptions adds the animation of a gif job to opts: its frame rate
+// follows speed, and it ends after loops passes of a finite animation, or
+// at the timeout, which defaults to SHOUT_STREAMING_DEFAULT_TIMEOUT.
+func (h *Handler) gifOptions(req asyncRequest, opts shout.Options) (shout.Options, error) {
+	anim, preset, err := shout.ParseAnimation(req.Anim)
+	if err != nil {
+		return opts, apierrors.ErrBadOption.WithDetail("%v", err).
+			WithHint("use one of " + strings.Join(shout.AnimationNames(), ", ") + ", joined with +")
+	}
+	if preset != "" && req.Color != "" {
+		return opts, apierrors.ErrBadOption.WithDetail("anim=%s and color=%s both set the colors", preset, req.Color)
+	}
+	if req.Loops < 0 {
+		return opts, apierrors.ErrBadOption.WithDetail("loops must be 0 or more, got %d", req.Loops)
+	}
+	s := h.config().Streaming
+	speed := cmp.Or(req.Speed, s.DefaultSpeed)
+	if speed < s.MinSpeed || speed > s.MaxSpeed {
+		return opts, apierrors.ErrBadOption.WithDetail("speed must be between %d and %d, got %d", s.MinSpeed, s.MaxSpeed, speed)
+	}
+	timeout := min(time.Duration(req.Timeout)*time.Second, time.Duration(s.MaxTimeout)*time.Second)
+	if timeout <= 0 && (req.Loops == 0 || anim.Endless()) {
+		timeout = time.Duration(s.DefaultTimeout) * time.Second
+	}
+
+	if preset == "" && req.Color == "" {
+		opts.Color = defaultPartyColor
+		opts.Scheme = nil
+	}
+	opts.Animation = req.Anim
+	opts.Interval = shout.SpeedIntervals[speed-1]
+	opts.Loops = req.Loops
+	opts.Duration = timeout
+	return opts, nil
+}
+
+// runJob renders job and delivers the artifact, or the error, to its
+// callback.
+func (h *Handler) runJob(job asyncJob) {
+	body, contentType, err := h.renderArtifact(job)
+	if err != nil {
+		h.metrics.IncError()
+		log.Printf("Error rendering async job %s: %v", job.id, err)
+		apiErr := apierrors.ErrRenderFailed
+		if errors.Is(err, context.DeadlineExceeded) {
+			apiErr = apierrors.ErrRenderTimeout
+		}
+		body, _ = json.Marshal(fiber.Map{"code": apiErr.Code, "title": apiErr.Message, "status": apiErr.Status})
+		contentType = fiber.MIMEApplicationJSON
+	}
+	if err := h.deliver(job, body, contentType); err != nil {
+		log.Printf("Error delivering async job %s: %v", job.id, err)
+	}
+}
+
+// renderArtifact renders job in its format and returns the artifact and
+// its media type.
+func (h *Handler) renderArtifact(job asyncJob) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.renderBudget())
+	defer cancel()
+	h.countUsage(job.opts.Font, job.opts.Color, job.opts.Animation, job.format == artifactGIF)
+
+	if job.format == artifactGIF {
+		frames, err := h.shout.Frames(ctx, job.text, job.opts)
+		if err != nil {
+			return nil, "", err
+		}
+		events := []streamEvent{{0, ansiHideCursor + ansiClearScreen}}
+		var at time.Duration
+		for n := 0; n < h.config().GIF.MaxFrames; n++ {
+			at = time.Duration(n) * job.opts.Interval
+			frame, last := frames(n)
+			events = append(events, streamEvent{at, shout.Redraw(frame)})
+			if last {
+				break
+			}
+		}
+		var b bytes.Buffer
+		err = h.playEvents(append(events, streamEvent{at, ansiReset})).Encode(&b)
+		return b.Bytes(), "image/gif", err
+	}
+
+	output, err := h.shout.Render(ctx, job.text, job.opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var b bytes.Buffer
+	switch job.format {
+	case artifactSVG:
+		err = h.playEvents([]streamEvent{{0, output}}).EncodeSVG(&b)
+		return b.Bytes(), svgMediaType, err
+	case artifactPNG:
+		err = h.playEvents([]streamEvent{{0, output}}).EncodePNG(&b)
+		return b.Bytes(), "image/png", err
+	}
+	return []byte(output), textPlain, nil
+}
+
+// deliver POSTs body to the callback of job, signed, trying again after
+// network errors and 5xx answers.
+func (h *Handler) deliver(job asyncJob, body []byte, contentType string) error {
+	backoff := callbackBackoff
+	var err error
+	for attempt := 1; attempt <= callbackAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var resp *http.Response
+		resp, err = asyncClient.Do(h.callbackRequest(job, body, contentType))
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 == 2 {
+			return nil
+		}
+		err = fmt.Errorf("callback answered %s", resp.Status)
+		if resp.StatusCode < 500 {
+			return err
+		}
+	}
+	return err
+}
+
+// callbackRequest builds the signed callback request for job.
+func (h *Handler) callbackRequest(job asyncJob, body []byte, contentType string) *http.Request {
+	timestamp := strconv.FormatInt(clockNow().Unix(), 10)
+	req, _ := http.NewRequest(http.MethodPost, job.callback.String(), bytes.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	req.Header.Set(HeaderJob, job.id)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, signCallback(h.config().Async.Secret, timestamp, body))
+	return req
+}
+
+// signCallback returns the X-Shout-Signature of a callback body sent at
+// timestamp. Signing the timestamp too lets receivers refuse replays.
+func signCallback(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newJobID returns a random job ID.
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// publicAddressOnly refuses connections to addresses that aren't on the
+// public internet.
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("callbacks to %s aren't allowed", host)
+	}
+	return nil
+}
+
+// asyncLimiter limits how many jobs a client may queue per minute
+// (SHOUT_ASYNC_JOBS_PER_MINUTE), or returns a pass-through handler when
+// there is no limit.
+func (h *Handler) asyncLimiter() fiber.Handler {
+	cfg := h.config()
+	if cfg == nil || !cfg.Async.Enabled || cfg.Async.JobsPerMinute == 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return limiter.New(limiter.Config{
+		Max:        cfg.Async.JobsPerMinute,
+		Expiration: time.Minute,
+		LimitReached: func(c *fiber.Ctx) error {
+			return apierrors.Send(c, apierrors.ErrRateLimited)
+		},
+	})
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// svgMediaType is the media type of badges.
+const svgMediaType = "image/svg+xml"
+
+// Badge defaults: the value is drawn in the smallest bundled font on blue,
+// and the label on gray, as shields.io does.
+const (
+	defaultBadgeFont       = "small"
+	defaultBadgeColor      = "blue"
+	defaultBadgeLabelColor = "#555"
+)
+
+// Badge geometry in pixels. The label is set in Verdana like shields.io
+// badges, and the art in a monospace font whose lines touch, so the
+// glyphs join up.
+const (
+	badgeHeight        = 20
+	badgeLabelFontSize = 11
+	badgeLabelCharW    = 7
+	badgeLabelPad      = 6
+	badgeArtFontSize   = 8
+	badgeArtCharW      = 4.8
+	badgeArtPad        = 4
+)
+
+// badgeColors are the shields.io color names.
+var badgeColors = map[string]string{
+	"brightgreen":   "#4c1",
+	"green":         "#97ca00",
+	"yellowgreen":   "#a4a61d",
+	"yellow":        "#dfb317",
+	"orange":        "#fe7d37",
+	"red":           "#e05d44",
+	"blue":          "#007ec6",
+	"lightgrey":     "#9f9f9f",
+	"lightgray":     "#9f9f9f",
+	"grey":          "#555",
+	"gray":          "#555",
+	"success":       "#4c1",
+	"important":     "#fe7d37",
+	"critical":      "#e05d44",
+	"informational": "#007ec6",
+	"inactive":      "#9f9f9f",
+}
+
+// bareHex matches a hex color without its #, as shields.io accepts.
+var bareHex = regexp.MustCompile(`^(?i)[0-9a-f]{3}([0-9a-f]{3})?$`)
+
+// badgeParams are the query parameters of the badge route.
+var badgeParams = []optionHelp{
+	{Names: []string{"c", "color"}, Help: "Background of the value: a shields.io color name such as brightgreen or critical, hex with or without #, rgb(r,g,b) or a color name", Type: "string"},
+	{Names: []string{"labelColor"}, Help: "Background of the label, in the same forms as color", Type: "string"},
+	{Names: []string{"f", "font"}, Help: "Font of the value (default: small)", Type: "string"},
+}
+
+// Badge renders a shields.io style SVG badge: the label in plain text and
+// the value as ASCII art in a small font. Label and value are checked and
+// filtered like banner text.
+//
+// Example:
+//
+//	GET /badge/build/passing?color=brightgreen
+func (h *Handler) Badge(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+
+	var parts [2]string
+	var masked [2][]render.WordRange
+	for i, name := range []string{"label", "value"} {
+		text, err := middleware.CheckText(middleware.PathText(c.Params(name)), h.textConfig())
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+		if text == "" {
+			return apierrors.Send(c, apierrors.ErrNoText.WithDetail("the badge %s is empty", name))
+		}
+		masked[i], err = middleware.FilterText(h.wordFilter(), h.textConfig().Filter, text, h.metrics)
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+		parts[i] = text
+	}
+	label, value := maskText(parts[0], masked[0]), parts[1]
+
+	color, err := badgeColor(firstOf(c.Query("c"), c.Query("color"), defaultBadgeColor))
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err))
+	}
+	labelColor, err := badgeColor(firstOf(c.Query("labelColor"), defaultBadgeLabelColor))
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("labelColor: %v", err))
+	}
+	name := firstOf(c.Query("f"), c.Query("font"), defaultBadgeFont)
+	font := h.fonts.ResolveFont(name, h.defaultFont())
+	if font == nil {
+		return apierrors.Send(c, apierrors.ErrFontNotFound.WithDetail("%q", name))
+	}
+
+	ctx, cancel := h.renderContext(c, 0)
+	defer cancel()
+	art, err := h.shout.Render(ctx, value, shout.Options{Font: font.Name, Colors: render.NoColor, Masked: masked[1]})
+	if err != nil {
+		return h.renderFailed(c, err, "")
+	}
+	h.countUsage(font.Name, "", "", false)
+
+	svg := badgeSVG(label, maskText(value, masked[1]), strings.Split(strings.TrimRight(art, "\n"), "\n"), labelColor, color)
+	sum := sha256.Sum256([]byte(svg))
+	etag := `"` + hex.EncodeToString(sum[:])[:32] + `"`
+	h.setCacheHeaders(c, etag)
+	if etagMatches(c, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, svgMediaType)
+	return c.SendString(svg)
+}
+
+// badgeColor parses a badge color: a shields.io color name, hex without
+// its #, or anything render.ParseSolidColor accepts.
+func badgeColor(spec string) (render.Color, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if code, ok := badgeColors[spec]; ok {
+		spec = code
+	} else if bareHex.MatchString(spec) {
+		spec = "#" + spec
+	}
+	return render.ParseSolidColor(spec)
+}
+
+// maskText replaces the runes of text in ranges with render.MaskRune.
+func maskText(text string, ranges []render.WordRange) string {
+	if len(ranges) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	for _, r := range ranges {
+		for i := max(r.Start, 0); i < min(r.End, len(runes)); i++ {
+			if runes[i] != ' ' {
+				runes[i] = render.MaskRune
+			}
+		}
+	}
+	return string(runes)
+}
+
+// badgeSVG draws a badge with label on the left and the art lines of
+// value on the right, titled with both for screen readers. The badge
+// grows to fit the art.
+func badgeSVG(label, value string, art []string, labelColor, color render.Color) string {
+	cols := 0
+	for _, line := range art {
+		cols = max(cols, render.StringWidth(line))
+	}
+	labelW := utf8.RuneCountInString(label)*badgeLabelCharW + 2*badgeLabelPad
+	artW := int(float64(cols)*badgeArtCharW+0.5) + 2*badgeArtPad
+	height := max(badgeHeight, len(art)*badgeArtFontSize+2*badgeArtPad)
+	width := labelW + artW
+	title := html.EscapeString(label + ": " + value)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s">`+"\n", width, height, title)
+	fmt.Fprintf(&b, "<title>%s</title>\n", title)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="%d" rx="3"/></clipPath>`+"\n", width, height)
+	b.WriteString(`<g clip-path="url(#r)">` + "\n")
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`+"\n", labelW, height, labelColor.Hex())
+	fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d" fill="%s"/>`+"\n", labelW, artW, height, color.Hex())
+	b.WriteString("</g>\n")
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="%d" text-anchor="middle" dominant-baseline="central">%s</text>`+"\n",
+		labelW/2, height/2, inkFor(labelColor), badgeLabelFontSize, html.EscapeString(label))
+	// Lines are set from the top, centered as a block
+	top := (height-len(art)*badgeArtFontSize)/2 + badgeArtFontSize - 1
+	fmt.Fprintf(&b, `<text fill="%s" font-family="DejaVu Sans Mono,Menlo,Consolas,monospace" font-size="%d" xml:space="preserve">`, inkFor(color), badgeArtFontSize)
+	for i, line := range art {
+		fmt.Fprintf(&b, `<tspan x="%d" y="%d">%s</tspan>`, labelW+badgeArtPad, top+i*badgeArtFontSize, html.EscapeString(line))
+	}
+	b.WriteString("</text>\n</svg>\n")
+	return b.String()
+}
+
+// inkFor returns the text color that reads best on background bg: white,
+// or dark gray on light colors.
+func inkFor(bg render.Color) string {
+	// Relative luminance with the Rec. 601 weights, out of 255
+	if (299*int(bg.R)+587*int(bg.G)+114*int(bg.B))/1000 > 160 {
+		return "#333"
+	}
+	return "#fff"
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestBadge(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		contains   []string
+	}{
+		{
+			name:       "default colors",
+			path:       "/badge/build/passing",
+			wantStatus: 200,
+			contains:   []string{`aria-label="build: passing"`, `fill="#007ec6"`, `fill="#555555"`, ">build</text>", "<tspan"},
+		},
+		{name: "shields color", path: "/badge/build/passing?color=brightgreen", wantStatus: 200, contains: []string{`fill="#44cc11"`}},
+		{name: "bare hex", path: "/badge/build/passing?c=ff69b4&labelColor=fff", wantStatus: 200, contains: []string{`fill="#ff69b4"`, `fill="#ffffff"`, `fill="#333"`}},
+		{name: "spaces and escaping", path: "/badge/a%3Cb/x+y", wantStatus: 200, contains: []string{`aria-label="a&lt;b: x y"`}},
+		{name: "bad color", path: "/badge/build/passing?color=nope", wantStatus: 400},
+		{name: "value too long", path: "/badge/build/" + strings.Repeat("A", 21), wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.path, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			if got := resp.Header.Get(fiber.HeaderContentType); got != svgMediaType {
+				t.Errorf("Content-Type = %q, want %q", got, svgMediaType)
+			}
+			if err := xml.Unmarshal([]byte(body), new(struct{})); err != nil {
+				t.Errorf("badge isn't valid XML: %v\n%s", err, body)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("badge doesn't contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+
+	// The art lines of the value are all drawn
+	art, err := newTestHandler(t).shout.Render(context.Background(), "OK", shout.Options{Font: "small", Colors: render.NoColor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body := doRequest(t, app, httptest.NewRequest("GET", "/badge/status/OK", nil))
+	if got, want := strings.Count(body, "<tspan"), strings.Count(strings.TrimRight(art, "\n"), "\n")+1; got != want {
+		t.Errorf("badge has %d art lines, want %d", got, want)
+	}
+}
+
+func TestBadgeETag(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/badge/build/passing", nil))
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if etag == "" {
+		t.Fatal("badge has no ETag")
+	}
+	req := httptest.NewRequest("GET", "/badge/build/passing", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	if resp, _ := doRequest(t, app, req); resp.StatusCode != fiber.StatusNotModified {
+		t.Errorf("status = %d, want 304", resp.StatusCode)
+	}
+}
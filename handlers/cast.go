@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// Stream output formats, chosen with ?format=.
+const (
+	formatStream = ""
+	formatCast   = "cast"
+	formatGIF    = "gif"
+)
+
+// castMediaType is the media type of asciinema recordings.
+const castMediaType = "application/x-asciicast"
+
+// maxCastFrames bounds the frames recorded into one cast, since recording
+// runs as fast as the frames render.
+const maxCastFrames = 10000
+
+// formatParam documents ?format= on the streaming routes.
+var formatParam = optionHelp{Names: []string{"format"}, Help: "cast returns an asciinema v2 recording of the stream instead, gif an animated GIF if enabled", Type: "string"}
+
+// castHeader is the first line of an asciinema v2 recording.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env"`
+}
+
+// streamFormat returns the requested ?format=, or an error if it isn't a
+// stream format, is disabled or isn't in the client's plan.
+func (h *Handler) streamFormat(c *fiber.Ctx) (string, error) {
+	switch format := c.Query("format"); format {
+	case formatStream, formatCast:
+		return format, nil
+	case formatGIF:
+		if !h.gifEnabled() {
+			return "", apierrors.ErrBadOption.WithDetail("GIF export is disabled on this server").WithHint("use format=cast")
+		}
+		if err := h.entitled(c, apikey.FeatureGIF); err != nil {
+			return "", err
+		}
+		return format, nil
+	default:
+		return "", apierrors.ErrBadOption.WithDetail("unknown format %q", format).WithHint("use format=cast")
+	}
+}
+
+// streamEvent is a chunk of stream output and when it would be sent.
+type streamEvent struct {
+	at   time.Duration
+	data string
+}
+
+// record renders every chunk of a stream without waiting between them,
+// stamping each with the time it would have been streamed. Recording stops
+// at the last chunk, after SHOUT_STREAMING_MAX_TIMEOUT (or the shorter
+// ?t=) of stream time, or after maxFrames chunks.
+func (h *Handler) record(c *fiber.Ctx, interval time.Duration, prologue, epilogue string, next frameSource, maxFrames int) ([]streamEvent, error) {
+	cfg := h.config()
+	var limit time.Duration
+	if cfg != nil {
+		limit = time.Duration(cfg.Streaming.MaxTimeout) * time.Second
+	}
+	if t := time.Duration(c.QueryInt("t", c.QueryInt("timeout", 0))) * time.Second; t > 0 && (limit == 0 || t < limit) {
+		limit = t
+	}
+
+	events := []streamEvent{{0, prologue}}
+	var elapsed time.Duration
+	for n := 0; n < maxFrames; n++ {
+		elapsed = time.Duration(n) * interval
+		chunk, last, err := next(n)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, streamEvent{elapsed, chunk})
+		if last || limit > 0 && elapsed >= limit {
+			break
+		}
+	}
+	return append(events, streamEvent{elapsed, epilogue}), nil
+}
+
+// recordCast records a stream (see record) and sends it as an asciinema
+// v2 .cast file.
+func (h *Handler) recordCast(c *fiber.Ctx, interval time.Duration, prologue, epilogue string, next frameSource) error {
+	events, err := h.record(c, interval, prologue, epilogue, next, maxCastFrames)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrRenderFailed.WithDetail("%v", err))
+	}
+
+	var output strings.Builder
+	for _, e := range events {
+		output.WriteString(e.data)
+	}
+	width, height := castSize(output.String())
+
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: clockNow().Unix(),
+		Title:     "shout.sh" + c.Path(),
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	for _, e := range events {
+		if e.data == "" {
+			continue
+		}
+		seconds := math.Round(e.at.Seconds()*1e6) / 1e6
+		enc.Encode([]any{seconds, "o", e.data})
+	}
+
+	c.Set(fiber.HeaderContentType, castMediaType)
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="shout.cast"`)
+	return c.SendString(b.String())
+}
+
+// castSize returns the terminal size needed to play output back: the
+// widest line, and the most lines drawn between two cursor-home sequences.
+func castSize(output string) (width, height int) {
+	for _, screen := range strings.Split(output, ansiHome) {
+		lines := strings.Split(strings.TrimRight(render.StripANSI(screen), "\r\n"), "\n")
+		height = max(height, len(lines))
+		for _, line := range lines {
+			// Carriage returns start over on the same line
+			for _, part := range strings.Split(line, "\r") {
+				width = max(width, render.StringWidth(part))
+			}
+		}
+	}
+	return max(width, 1), max(height, 1)
+}
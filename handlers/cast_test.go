@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// parseCast splits an asciinema v2 recording into its header and events.
+func parseCast(t *testing.T, body string) (castHeader, [][]any) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+
+	var header castHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("invalid header %q: %v", lines[0], err)
+	}
+	var events [][]any
+	for _, line := range lines[1:] {
+		var event []any
+		if err := json.Unmarshal([]byte(line), &event); err != nil || len(event) != 3 || event[1] != "o" {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return header, events
+}
+
+func TestCountdownCast(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/2?f=small&format=cast", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != castMediaType {
+		t.Errorf("Content-Type = %q, want %q", got, castMediaType)
+	}
+
+	header, events := parseCast(t, body)
+	frame := renderText(t, h, "0:02")
+	lines := strings.Split(strings.TrimRight(frame, "\n"), "\n")
+	if header.Version != 2 || header.Height < len(lines) || header.Width < len(lines[0]) {
+		t.Errorf("header = %+v, want version 2 fitting a %d line frame", header, len(lines))
+	}
+
+	// Prologue, three frames and the epilogue, a second apart
+	var times []float64
+	for _, e := range events {
+		times = append(times, e[0].(float64))
+	}
+	if want := []float64{0, 0, 1, 2, 2}; !slices.Equal(times, want) {
+		t.Errorf("event times = %v, want %v", times, want)
+	}
+	if h.streams.GetActiveCount() != 0 {
+		t.Error("recording shouldn't hold a stream slot")
+	}
+}
+
+func TestCastBounds(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 2
+	app := newTestAppFrom(h)
+
+	// 300 baud plays 30 characters a second, so this art takes 10 seconds
+	art := bytes.Repeat([]byte("A"), 300)
+
+	tests := []struct {
+		name     string
+		target   string
+		wantLast float64
+	}{
+		{name: "max timeout", target: "/ans?baud=300&format=cast", wantLast: 2},
+		{name: "timeout option", target: "/ans?baud=300&format=cast&t=1", wantLast: 1},
+		{name: "timeout option can't extend", target: "/ans?baud=300&format=cast&t=30", wantLast: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("POST", tt.target, bytes.NewReader(art)))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+			}
+			_, events := parseCast(t, body)
+			if last := events[len(events)-1][0].(float64); last != tt.wantLast {
+				t.Errorf("recording ends at %vs, want %vs", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestANSICast(t *testing.T) {
+	h := newTestHandler(t)
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("POST", "/ans?format=cast", bytes.NewReader(testArt())))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	header, events := parseCast(t, body)
+	if len(events) != 1 || events[0][2] != wantArt {
+		t.Errorf("events = %q, want the whole art at once", events)
+	}
+	if header.Width != 4 || header.Height != 1 {
+		t.Errorf("size = %dx%d, want 4x1", header.Width, header.Height)
+	}
+
+	resp, _ = doRequest(t, app, httptest.NewRequest("POST", "/ans?baud=9600&format=gif", bytes.NewReader(testArt())))
+	if resp.StatusCode != 400 {
+		t.Errorf("unknown format status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestCastSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "empty", output: "", wantWidth: 1, wantHeight: 1},
+		{name: "lines", output: "AB\r\nABCD\r\n", wantWidth: 4, wantHeight: 2},
+		{name: "escapes don't count", output: "\x1b[31mAB\x1b[0m", wantWidth: 2, wantHeight: 1},
+		{name: "redrawn frames", output: ansiHome + "A\nB\nC\n" + ansiHome + "ABC\n", wantWidth: 3, wantHeight: 3},
+		{name: "wide characters", output: "█▓▒░", wantWidth: 4, wantHeight: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := castSize(tt.output)
+			if w != tt.wantWidth || h != tt.wantHeight {
+				t.Errorf("castSize() = %dx%d, want %dx%d", w, h, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+)
+
+// Default formats of the clock endpoints.
+const (
+	defaultDateFormat = "%Y-%m-%d"
+	defaultTimeFormat = "%H:%M"
+)
+
+// clockNow is replaced in tests.
+var clockNow = time.Now
+
+// clockParams are the query parameters of the date and time routes.
+var clockParams = []optionHelp{
+	{Names: []string{"tz"}, Help: "IANA time zone such as Europe/London (default: server time)", Type: "string"},
+	{Names: []string{"fmt"}, Help: "strftime format such as %A or %H:%M:%S", Type: "string"},
+	colsParam,
+	termParam,
+	ncParam,
+}
+
+// Date renders today's date as a banner, formatted with ?fmt= (default
+// %Y-%m-%d) in the ?tz= time zone.
+//
+// Example:
+//
+//	GET /date?tz=Asia/Tokyo&fmt=%25A&f=doom
+func (h *Handler) Date(c *fiber.Ctx) error {
+	return h.clock(c, defaultDateFormat)
+}
+
+// Time renders the current time as a banner, formatted with ?fmt=
+// (default %H:%M) in the ?tz= time zone.
+//
+// Example:
+//
+//	GET /time?tz=America/New_York&c=fire
+func (h *Handler) Time(c *fiber.Ctx) error {
+	return h.clock(c, defaultTimeFormat)
+}
+
+// clock renders the current time with the requested format and time zone.
+// The formatted text goes through the same length and word checks as path
+// text, since ?fmt= can carry literal words.
+func (h *Handler) clock(c *fiber.Ctx, defaultFormat string) error {
+	h.metrics.IncClock()
+
+	loc := time.Local
+	if tz := c.Query("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("unknown time zone %q", tz).
+				WithHint("use an IANA name such as UTC or Europe/London"))
+		}
+		loc = l
+	}
+
+	format := c.Query("fmt", defaultFormat)
+	formatted, err := strftime(clockNow().In(loc), format)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err))
+	}
+
+	text := h.textConfig()
+	formatted, err = middleware.CheckText(formatted, text)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if formatted == "" {
+		return apierrors.Send(c, apierrors.ErrNoText)
+	}
+	masked, err := middleware.FilterText(h.wordFilter(), text.Filter, formatted, h.metrics)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	// The output changes over time, so shared caches mustn't keep it
+	c.Locals(middleware.DynamicKey, true)
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	return h.renderStatic(c, formatted, opts, masked)
+}
+
+// strftime formats t using C strftime conversions. Supported: %a %A %b %B
+// %d %e %H %I %j %m %M %p %S %y %Y %Z %z, the shorthands %D %F %R %T, and
+// %n and %% for a new line and a percent sign.
+func strftime(t time.Time, format string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return "", fmt.Errorf("format %q ends with a lone %%", format)
+		}
+
+		switch verb := format[i]; verb {
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'b':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'e':
+			b.WriteString(t.Format("_2"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'I':
+			b.WriteString(t.Format("03"))
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case 'y':
+			b.WriteString(t.Format("06"))
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 'D':
+			b.WriteString(t.Format("01/02/06"))
+		case 'F':
+			b.WriteString(t.Format("2006-01-02"))
+		case 'R':
+			b.WriteString(t.Format("15:04"))
+		case 'T':
+			b.WriteString(t.Format("15:04:05"))
+		case 'n':
+			b.WriteByte('\n')
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", fmt.Errorf("unsupported format directive %%%c", verb)
+		}
+	}
+	return b.String(), nil
+}
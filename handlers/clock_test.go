@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	orig := clockNow
+	clockNow = func() time.Time { return time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC) }
+	t.Cleanup(func() { clockNow = orig })
+
+	h := newTestHandler(t)
+	app := newTestAppFrom(h)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantText   string
+	}{
+		{name: "date", target: "/date?f=small&tz=UTC", wantStatus: 200, wantText: "2024-03-05"},
+		{name: "time", target: "/time?f=small&tz=UTC", wantStatus: 200, wantText: "14:07"},
+		{name: "time zone", target: "/time?f=small&tz=Asia/Tokyo", wantStatus: 200, wantText: "23:07"},
+		{name: "date rolls over in time zone", target: "/date?f=small&tz=Pacific/Auckland", wantStatus: 200, wantText: "2024-03-06"},
+		{name: "format", target: "/date?f=small&tz=UTC&fmt=" + url.QueryEscape("%a %d %b"), wantStatus: 200, wantText: "Tue 05 Mar"},
+		{name: "versioned", target: "/v1/time?f=small&tz=UTC&fmt=" + url.QueryEscape("%T"), wantStatus: 200, wantText: "14:07:09"},
+		{name: "unknown time zone", target: "/time?tz=Mars/Olympus", wantStatus: 400},
+		{name: "unsupported directive", target: "/time?fmt=" + url.QueryEscape("%Q"), wantStatus: 400},
+		{name: "too long", target: "/date?fmt=" + url.QueryEscape("%A %B %d %Y"), wantStatus: 400},
+		{name: "bad color", target: "/time?c=notacolor", wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantText == "" {
+				return
+			}
+			if want := renderText(t, h, tt.wantText); body != want {
+				t.Errorf("body =\n%s\nwant %q rendered as\n%s", body, tt.wantText, want)
+			}
+			if got := resp.Header.Get("Cache-Control"); got != dynamicCacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, dynamicCacheControl)
+			}
+		})
+	}
+}
+
+func TestStrftime(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 9, 7, 9, 0, time.FixedZone("EST", -5*3600))
+
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{format: "%Y-%m-%d", want: "2024-03-05"},
+		{format: "%H:%M:%S", want: "09:07:09"},
+		{format: "%I:%M %p", want: "09:07 AM"},
+		{format: "%a %A %b %B", want: "Tue Tuesday Mar March"},
+		{format: "%e/%y %j", want: " 5/24 065"},
+		{format: "%Z %z", want: "EST -0500"},
+		{format: "%D %F %R %T", want: "03/05/24 2024-03-05 09:07 09:07:09"},
+		{format: "%H%n%M 100%%", want: "09\n07 100%"},
+		{format: "NO DIRECTIVES", want: "NO DIRECTIVES"},
+		{format: "%k", wantErr: true},
+		{format: "50%", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := strftime(ts, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("strftime(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("strftime(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// Colors lists the supported color presets and named colors.
+// JSON is returned when the client asks for it via the Accept header,
+// plain text otherwise.
+//
+// Example:
+//
+//	GET /colors
+func (h *Handler) Colors(c *fiber.Ctx) error {
+	presets := render.PresetNames()
+	names := render.ColorNames()
+
+	if strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMEApplicationJSON) {
+		return c.JSON(fiber.Map{
+			"presets": presets,
+			"names":   names,
+			"formats": []string{"#RRGGBB", "#RGB", "rgb(r,g,b)"},
+		})
+	}
+
+	var b strings.Builder
+	b.WriteString("Presets:\n")
+	for _, name := range presets {
+		b.WriteString("  " + name + "\n")
+	}
+	b.WriteString("\nCustom colors:\n  #RRGGBB, #RGB, rgb(r,g,b)\n")
+	b.WriteString("\nNamed colors:\n")
+	for _, name := range names {
+		b.WriteString("  " + name + "\n")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+	return c.SendString(b.String())
+}
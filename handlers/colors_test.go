@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestColors(t *testing.T) {
+	app := newTestApp(t)
+
+	t.Run("plain text listing", func(t *testing.T) {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", "/colors", nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		for _, want := range []string{"rainbow", "rebeccapurple", "#RRGGBB", "rgb(r,g,b)"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body missing %q", want)
+			}
+		}
+	})
+
+	t.Run("json listing", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/colors", nil)
+		req.Header.Set("Accept", "application/json")
+		_, body := doRequest(t, app, req)
+
+		var got struct {
+			Presets []string `json:"presets"`
+			Names   []string `json:"names"`
+		}
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(got.Presets) == 0 || len(got.Names) == 0 {
+			t.Errorf("expected presets and names, got %+v", got)
+		}
+	})
+}
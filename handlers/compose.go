@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Section types of a composed document.
+const (
+	sectionBanner = "banner"
+	sectionText   = "text"
+	sectionRule   = "rule"
+	sectionBlank  = "blank"
+)
+
+// maxComposeBytes caps the JSON body of a composed document.
+const maxComposeBytes = 64 << 10
+
+// Limits on what one document draws.
+const (
+	maxComposeSections = 32
+	maxBlankLines      = 10
+)
+
+// composeParams are the query parameters of the compose route.
+var composeParams = []optionHelp{
+	colsParam,
+	termParam,
+	ncParam,
+	asciiParam,
+}
+
+// composeDocument is the JSON body of a compose request.
+type composeDocument struct {
+	Sections []composeSection `json:"sections"`
+}
+
+// composeSection is one section of a composed document, with render
+// options of its own that fill in from those of the query string.
+type composeSection struct {
+	// Type is banner, text, rule or blank
+	Type string `json:"type"`
+	// Text is what a banner or text section shows
+	Text string `json:"text"`
+	// Char is the characters a rule repeats, and Style names them, as
+	// GET /hr takes them
+	Char  string `json:"char"`
+	Style string `json:"style"`
+	// Lines is the height of a blank section; 0 is 1
+	Lines int `json:"lines"`
+	types.RenderOptions
+}
+
+// Compose lays out the sections of the JSON body, in order, into one
+// document: banners drawn as /{text} draws them, lines of plain text,
+// horizontal rules and blank lines. Each section takes the render options
+// of the query string, overriding them with its own, so a theme or color
+// set once applies to every section that doesn't set another. Text and
+// rules are aligned within cols and colored as banners are; borders,
+// backgrounds and padding are drawn around banners only. ?ascii=strict
+// draws every section uncolored in 7-bit ASCII, as the routes of each
+// kind of section do.
+//
+// Example:
+//
+//	curl -d '{"sections":[{"type":"banner","text":"web-01","font":"doom"},{"type":"rule"},{"type":"text","text":"uptime 12 days","color":"gray"}]}' 'localhost:8080/compose?cols=80&a=center&c=ocean'
+func (h *Handler) Compose(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+	if len(c.Body()) > maxComposeBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxComposeBytes))
+	}
+
+	var doc composeDocument
+	if err := json.Unmarshal(c.Body(), &doc); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+	if len(doc.Sections) == 0 {
+		return apierrors.Send(c, apierrors.ErrNoText.WithDetail("the document has no sections"))
+	}
+	if len(doc.Sections) > maxComposeSections {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("a document has at most %d sections, got %d", maxComposeSections, len(doc.Sections)))
+	}
+
+	defaults, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if term, err = withASCII(c, term); err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	ctx, cancel := h.renderContext(c, defaults.Timeout)
+	defer cancel()
+
+	var b strings.Builder
+	for i, section := range doc.Sections {
+		output, err := h.drawSection(ctx, c, section, defaults, term)
+		if err != nil {
+			var apiErr *apierrors.Error
+			if !errors.As(err, &apiErr) {
+				apiErr = h.renderError(err, "")
+			}
+			return apierrors.Send(c, sectionError(i, apiErr))
+		}
+		b.WriteString(output)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(b.String())
+}
+
+// drawSection draws one section of a composed document, with defaults
+// filling in the options it leaves out, for the terminal term.
+func (h *Handler) drawSection(ctx context.Context, c *fiber.Ctx, section composeSection, defaults types.RenderOptions, term terminal) (string, error) {
+	switch section.Type {
+	case sectionBlank:
+		if section.Lines < 0 || section.Lines > maxBlankLines {
+			return "", apierrors.ErrBadOption.WithDetail("lines must be between 0 and %d, got %d", maxBlankLines, section.Lines)
+		}
+		return strings.Repeat("\n", max(section.Lines, 1)), nil
+	case sectionBanner, sectionText, sectionRule:
+	default:
+		return "", apierrors.ErrBadOption.WithDetail("unknown section type %q", section.Type).
+			WithHint("use banner, text, rule or blank")
+	}
+
+	opts, err := h.sectionOptions(section.RenderOptions, defaults)
+	if err != nil {
+		return "", err
+	}
+	if section.Type == sectionRule {
+		return h.drawRule(opts, section.Char, section.Style, term)
+	}
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return "", apierrors.ErrBadOption.WithDetail("%v", err)
+	}
+	cols := term.cols
+	if opts.MaxWidth > 0 {
+		cols = opts.MaxWidth
+	}
+	align := h.alignment(opts)
+
+	text, err := middleware.CheckText(section.Text, h.textConfig())
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", apierrors.ErrNoText
+	}
+	masked, err := middleware.FilterText(h.wordFilter(), h.textConfig().Filter, text, h.metrics)
+	if err != nil {
+		return "", err
+	}
+	if term.ascii {
+		text = render.Transliterate(text)
+	}
+
+	if section.Type == sectionText {
+		layout := render.AlignLayout(render.TextLayout(render.MaskText(text, masked)), cols, align)
+		return asciiSection(render.ReduceColors(render.ColorizeLayout(layout, scheme, 0), term.colors), term), nil
+	}
+
+	opts.Font = h.requestFont(c, opts.Font)
+	suggestion := h.suggestFont(c, opts.Font)
+	font := h.fonts.EngineFont(opts, h.defaultFont())
+	if font == nil {
+		return "", apierrors.ErrFontNotFound.WithDetail("%q", opts.Font)
+	}
+	output, err := h.shout.Render(ctx, text, shout.Options{
+		Font:       font.Name,
+		Engine:     opts.Engine,
+		Scale:      opts.Scale,
+		Fill:       opts.Fill,
+		Hardblank:  opts.Hardblank,
+		Scheme:     scheme,
+		Align:      align,
+		Border:     opts.Border,
+		Padding:    opts.Padding,
+		Accent:     opts.Accent,
+		Background: opts.Background,
+		Cols:       cols,
+		Colors:     term.colors,
+		Masked:     masked,
+	})
+	if err != nil {
+		return "", h.renderError(err, suggestion)
+	}
+	return asciiSection(output, term), nil
+}
+
+// asciiSection returns the drawn output of a section in 7-bit ASCII when
+// term asks for it, with the fallbacks of static banners.
+func asciiSection(output string, term terminal) string {
+	if term.ascii {
+		return render.ASCIIOnly(output)
+	}
+	return output
+}
+
+// sectionOptions returns the options of a section: its own, then its
+// theme's, then defaults, the document's.
+func (h *Handler) sectionOptions(opts, defaults types.RenderOptions) (types.RenderOptions, error) {
+	theme, err := h.theme(opts.Theme)
+	if err != nil {
+		return types.RenderOptions{}, err
+	}
+	rules := h.optionRules()
+	rules.Defaults = theme.Options().Normalize(types.OptionRules{Defaults: defaults})
+	opts = opts.Normalize(rules)
+	if err := h.validateOptions(opts); err != nil {
+		return types.RenderOptions{}, err
+	}
+	return opts, nil
+}
+
+// sectionError names the section, numbered from 1, that err is about.
+func sectionError(i int, err *apierrors.Error) *apierrors.Error {
+	detail := fmt.Sprintf("section %d", i+1)
+	if err.Detail != "" {
+		detail += ": " + err.Detail
+	}
+	return err.WithDetail("%s", detail)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestCompose(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		want       string
+		contains   []string
+	}{
+		{
+			name:       "every section type",
+			path:       "/compose?cols=20&a=left&term=dumb",
+			body:       `{"sections":[{"type":"text","text":"web-01"},{"type":"rule","char":"="},{"type":"blank","lines":2},{"type":"text","text":"up","align":"right"}]}`,
+			wantStatus: 200,
+			want:       "web-01\n====================\n\n\n                  up\n",
+		},
+		{
+			name:       "banner",
+			path:       "/compose?f=small&term=dumb",
+			body:       `{"sections":[{"type":"banner","text":"HI"},{"type":"rule","char":"-","maxwidth":4},{"type":"rule","style":"double","maxwidth":2}]}`,
+			wantStatus: 200,
+			contains:   []string{"|_||_| |___|\n----\n══\n"},
+		},
+		{
+			name:       "section options override the query's",
+			path:       "/compose?c=red",
+			body:       `{"sections":[{"type":"text","text":"a"},{"type":"text","text":"b","color":"blue"}]}`,
+			wantStatus: 200,
+			contains:   []string{render.Color{R: 255}.Foreground() + "a", render.Color{B: 255}.Foreground() + "b"},
+		},
+		{
+			name:       "ascii",
+			path:       "/compose?f=small&c=red&ascii=strict",
+			body:       `{"sections":[{"type":"banner","text":"HI","border":"single"},{"type":"rule","style":"double","maxwidth":2},{"type":"text","text":"café"}]}`,
+			wantStatus: 200,
+			want:       "+-------------+\n|  _  _   ___ |\n| | || | |_ _||\n| | __ |  | | |\n| |_||_| |___||\n+-------------+\n==\ncafe\n",
+		},
+		{name: "bad ascii", path: "/compose?ascii=loose", body: `{"sections":[{"type":"rule"}]}`, wantStatus: 400, contains: []string{"ascii must be strict"}},
+		{name: "no sections", path: "/compose", body: `{"sections":[]}`, wantStatus: 400, contains: []string{"the document has no sections"}},
+		{name: "unknown type", path: "/compose", body: `{"sections":[{"type":"rule"},{"type":"chart"}]}`, wantStatus: 400, contains: []string{`section 2: unknown section type "chart"`}},
+		{name: "bad option", path: "/compose", body: `{"sections":[{"type":"text","text":"a","align":"diagonal"}]}`, wantStatus: 400, contains: []string{"section 1: align must be"}},
+		{name: "text missing", path: "/compose", body: `{"sections":[{"type":"banner"}]}`, wantStatus: 400},
+		{name: "too many blank lines", path: "/compose", body: `{"sections":[{"type":"blank","lines":50}]}`, wantStatus: 400},
+		{name: "bad rule", path: "/compose", body: `{"sections":[{"type":"rule","char":"\u001b[31m"}]}`, wantStatus: 400},
+		{name: "bad JSON", path: "/compose", body: `{`, wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.want != "" && body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
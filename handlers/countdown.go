@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// countdownTick is the time between countdown frames; tests shorten it.
+var countdownTick = time.Second
+
+// defaultCountdownMessage is shown at zero when no message is configured.
+const defaultCountdownMessage = "TIME'S UP"
+
+// Countdown streams a big-digit countdown that redraws every second and
+// ends with a message. The duration is the :seconds path parameter, or
+// the time left until the RFC 3339 ?until= timestamp. ?msg= replaces the
+// final message; the render options set font and colors, and animated
+// color presets move with each tick. With the terminal size from ?cols=
+// and ?rows= or X-Terminal-Size, the digits and message are aligned
+// within it and cropped to fit, and the message wraps.
+//
+// Example:
+//
+//	curl localhost:8080/countdown/90?f=doom&c=rainbow
+//	curl 'localhost:8080/countdown?until=2025-01-01T00:00:00Z&msg=HAPPY+NEW+YEAR'
+func (h *Handler) Countdown(c *fiber.Ctx) error {
+	h.metrics.IncCountdown()
+
+	seconds, err := h.countdownSeconds(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	message, masked, err := h.countdownMessage(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	opts.Font = h.requestFont(c, opts.Font)
+	suggestion := h.suggestFont(c, opts.Font)
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err).WithHint(suggestion))
+	}
+
+	scheme = h.seededScheme(c, scheme)
+	phase := h.requestRand(c).IntN(phaseRange)
+
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	bounds, align := term.stream(), h.alignment(opts)
+
+	renderBudget := h.renderBudget()
+	if font := h.fonts.EngineFont(opts, h.defaultFont()); font != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), renderBudget)
+		message, err = bounds.wrap(ctx, font, message)
+		cancel()
+		if err != nil {
+			return apierrors.Send(c, apierrors.ErrRenderFailed.WithDetail("%v", err).WithHint(suggestion))
+		}
+	}
+	h.countUsage(opts.Font, opts.Color, "", false)
+	return h.stream(c, countdownTick, func(n int) (string, bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), renderBudget)
+		defer cancel()
+
+		remaining := seconds - n
+		text, frameMask := formatCountdown(remaining), []render.WordRange(nil)
+		if remaining <= 0 {
+			text, frameMask = message, masked
+		}
+
+		layout, err := render.GenerateLayoutContext(ctx, text, opts, h.fonts)
+		if err != nil {
+			return "", false, err
+		}
+		render.MaskLayout(layout, frameMask)
+		layout = bounds.crop(render.AlignLayout(layout, bounds.cols, align))
+		return render.ColorizeLayout(layout, scheme, phase+n), remaining <= 0, nil
+	})
+}
+
+// countdownSeconds reads the countdown length from the path or ?until=,
+// bounded by SHOUT_STREAMING_MAX_TIMEOUT.
+func (h *Handler) countdownSeconds(c *fiber.Ctx) (int, error) {
+	cfg := h.config()
+	var seconds int
+	if raw := c.Params("seconds"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return 0, apierrors.ErrBadOption.WithDetail("seconds must be a whole number, got %q", raw)
+		}
+		seconds = n
+	} else if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return 0, apierrors.ErrBadOption.WithDetail("until must be an RFC 3339 time such as 2025-01-01T00:00:00Z, got %q", until)
+		}
+		seconds = int(math.Ceil(time.Until(t).Seconds()))
+		if seconds < 0 {
+			seconds = 0
+		}
+	} else {
+		return 0, apierrors.ErrBadOption.WithDetail("give the seconds in the path or an until time").
+			WithHint("try /countdown/60 or /countdown?until=2025-01-01T00:00:00Z")
+	}
+
+	if cfg != nil && cfg.Streaming.MaxTimeout > 0 && seconds > cfg.Streaming.MaxTimeout {
+		return 0, apierrors.ErrBadOption.WithDetail("countdowns may last at most %d seconds", cfg.Streaming.MaxTimeout)
+	}
+	return seconds, nil
+}
+
+// countdownMessage returns the validated final message and the word
+// ranges to mask in it.
+func (h *Handler) countdownMessage(c *fiber.Ctx) (string, []render.WordRange, error) {
+	cfg := h.config()
+	raw := c.Query("msg")
+	if raw == "" && cfg != nil {
+		raw = cfg.Streaming.CountdownMessage
+	}
+	if raw == "" {
+		raw = defaultCountdownMessage
+	}
+
+	text := h.textConfig()
+	message, err := middleware.CheckText(raw, text)
+	if err != nil {
+		return "", nil, err
+	}
+	if message == "" {
+		message = defaultCountdownMessage
+	}
+	masked, err := middleware.FilterText(h.wordFilter(), text.Filter, message, h.metrics)
+	if err != nil {
+		return "", nil, err
+	}
+	return message, masked, nil
+}
+
+// renderBudget returns the time allowed to render one frame, which is the
+// static render budget, or the frame interval if that's unlimited.
+func (h *Handler) renderBudget() time.Duration {
+	cfg := h.config()
+	if cfg != nil && cfg.Server.StaticTimeoutMS > 0 {
+		return time.Duration(cfg.Server.StaticTimeoutMS) * time.Millisecond
+	}
+	return countdownTick
+}
+
+// formatCountdown formats seconds as M:SS, or H:MM:SS from an hour up.
+func formatCountdown(seconds int) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	h, m, s := seconds/3600, seconds/60%60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// fastCountdown shortens the countdown tick for the duration of a test.
+func fastCountdown(t *testing.T) {
+	t.Helper()
+	orig := countdownTick
+	countdownTick = time.Millisecond
+	t.Cleanup(func() { countdownTick = orig })
+}
+
+// renderText renders text in the small test font without color.
+func renderText(t *testing.T, h *Handler, text string) string {
+	t.Helper()
+	out, err := render.GenerateASCII(text, types.RenderOptions{Font: "small"}, h.fonts)
+	if err != nil {
+		t.Fatalf("render %q: %v", text, err)
+	}
+	return out
+}
+
+func TestCountdown(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/2?f=small&msg=GO", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	// Three frames: 0:02, 0:01 and the message
+	if n := strings.Count(body, ansiHome); n != 3 {
+		t.Errorf("got %d frames, want 3", n)
+	}
+	plain := strings.ReplaceAll(body, ansiClearLine, "")
+	for _, text := range []string{"0:02", "0:01", "GO"} {
+		if !strings.Contains(plain, strings.TrimRight(renderText(t, h, text), "\n")) {
+			t.Errorf("stream is missing the frame for %q", text)
+		}
+	}
+	if strings.Contains(plain, strings.TrimRight(renderText(t, h, "0:00"), "\n")) {
+		t.Error("the message should replace the 0:00 frame")
+	}
+	if !strings.HasPrefix(body, ansiHideCursor) || !strings.HasSuffix(body, ansiShowCursor) {
+		t.Error("stream should hide the cursor and restore it at the end")
+	}
+}
+
+func TestCountdownErrors(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{name: "no duration", target: "/countdown", wantStatus: 400},
+		{name: "not a number", target: "/countdown/soon", wantStatus: 400},
+		{name: "negative", target: "/countdown/-5", wantStatus: 400},
+		{name: "too long", target: "/countdown/61", wantStatus: 400},
+		{name: "bad until", target: "/countdown?until=tomorrow", wantStatus: 400},
+		{name: "message too long", target: "/countdown/1?msg=" + strings.Repeat("A", 21), wantStatus: 400},
+		{name: "bad color", target: "/countdown/1?c=notacolor", wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+		})
+	}
+}
+
+func TestCountdownUntil(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	// A time in the past goes straight to the message
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown?f=small&until="+past, nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if n := strings.Count(body, ansiHome); n != 1 {
+		t.Errorf("got %d frames, want 1", n)
+	}
+	plain := strings.ReplaceAll(body, ansiClearLine, "")
+	if !strings.Contains(plain, strings.TrimRight(renderText(t, h, "TIME'S UP"), "\n")) {
+		t.Error("stream should end with the default message")
+	}
+}
+
+func TestCountdownStreamCapacity(t *testing.T) {
+	h := newTestHandler(t)
+	h.streams = types.NewConnectionManager(1)
+	h.streams.TryAcquire()
+	app := newTestAppFrom(h)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/countdown/1", nil))
+	if resp.StatusCode != 503 || resp.Header.Get("X-Shout-Error") != "stream_capacity" {
+		t.Errorf("response = %d %q, want 503 stream_capacity", resp.StatusCode, resp.Header.Get("X-Shout-Error"))
+	}
+	if h.metrics.Snapshot().Requests.RejectedStreams != 1 {
+		t.Errorf("RejectedStreams = %d, want 1", h.metrics.Snapshot().Requests.RejectedStreams)
+	}
+}
+
+func TestCountdownReleasesStream(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	doRequest(t, app, httptest.NewRequest("GET", "/countdown/1", nil))
+	if n := h.streams.GetActiveCount(); n != 0 {
+		t.Errorf("active streams = %d after the stream ended, want 0", n)
+	}
+
+	// HEAD requests don't hold a stream slot
+	resp, body := doRequest(t, app, httptest.NewRequest("HEAD", "/countdown/1", nil))
+	if resp.StatusCode != 200 || body != "" || h.streams.GetActiveCount() != 0 {
+		t.Errorf("HEAD = %d %q with %d active streams", resp.StatusCode, body, h.streams.GetActiveCount())
+	}
+}
+
+func TestCountdownCompression(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	h.config().Compression = config.CompressionConfig{Stream: true}
+	app := newTestAppFrom(h)
+
+	req := httptest.NewRequest("GET", "/countdown/1?f=small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, body := doRequest(t, app, req)
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	gz, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("invalid gzip stream: %v", err)
+	}
+	plain, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("invalid gzip stream: %v", err)
+	}
+	if strings.Count(string(plain), ansiHome) != 2 {
+		t.Errorf("decompressed stream has %d frames, want 2", strings.Count(string(plain), ansiHome))
+	}
+}
+
+func TestFormatCountdown(t *testing.T) {
+	tests := map[int]string{
+		-1:   "0:00",
+		0:    "0:00",
+		9:    "0:09",
+		90:   "1:30",
+		3600: "1:00:00",
+		3725: "1:02:05",
+	}
+	for seconds, want := range tests {
+		if got := formatCountdown(seconds); got != want {
+			t.Errorf("formatCountdown(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
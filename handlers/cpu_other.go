@@ -0,0 +1,11 @@
+//go:build !unix
+
+package handlers
+
+import "time"
+
+// processCPUTime returns 0: CPU time isn't measured on this platform, so
+// streams are never degraded for CPU use.
+func processCPUTime() time.Duration {
+	return 0
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/shortlink"
+)
+
+// Discord message limits and the code block that colors ANSI output.
+const (
+	discordMessageLimit = 2000
+	discordBlockStart   = "```ansi\n"
+	discordBlockEnd     = "```"
+)
+
+// maxDiscordMessages bounds the messages one relayed banner may take.
+const maxDiscordMessages = 5
+
+// discordWebhookPath is the path every Discord webhook URL starts with.
+const discordWebhookPath = "/api/webhooks/"
+
+// discordClient posts relayed banners. Tests replace it to trust a local
+// TLS server.
+var discordClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookParam documents the webhook_url field of the relay body.
+var webhookParam = optionHelp{Names: []string{"webhook_url"}, Help: "The Discord webhook to post to, https://discord.com/api/webhooks/...", Type: "string"}
+
+// sgrSequence matches the color and reset escape sequences in output.
+var sgrSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// discordRelay is the JSON body of POST /integrations/discord.
+type discordRelay struct {
+	WebhookURL string `json:"webhook_url"`
+	shortlink.Spec
+}
+
+// RelayDiscord renders the text and options in the JSON body, as
+// ?format=discord does, and posts the messages to the Discord webhook in
+// webhook_url. Mentions in the text never ping anyone.
+//
+// Example:
+//
+//	curl -d '{"webhook_url":"https://discord.com/api/webhooks/1/abc","text":"SHIPPED","color":"fire"}' localhost:8080/integrations/discord
+func (h *Handler) RelayDiscord(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+	if err := h.entitled(c, apikey.FeatureDiscord); err != nil {
+		return apierrors.Send(c, err)
+	}
+	if len(c.Body()) > maxSpecBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxSpecBytes))
+	}
+
+	var relay discordRelay
+	if err := json.Unmarshal(c.Body(), &relay); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+	webhook, err := h.discordWebhook(relay.WebhookURL)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	text, opts, suggestion, err := h.specOptions(c, relay.Spec)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	opts.Colors = render.Discord
+
+	ctx, cancel := h.renderContext(c, relay.Timeout)
+	defer cancel()
+	output, err := h.shout.Render(ctx, text, opts)
+	if err != nil {
+		return h.renderFailed(c, err, suggestion)
+	}
+	h.countUsage(opts.Font, opts.Color, "", false)
+	messages := discordMessages(output)
+	if len(messages) > maxDiscordMessages {
+		return apierrors.Send(c, apierrors.ErrBadOption.
+			WithDetail("the banner needs %d messages, max %d", len(messages), maxDiscordMessages).
+			WithHint("try shorter text, a smaller font or a maxwidth"))
+	}
+
+	for _, message := range messages {
+		if err := postDiscord(c, webhook, message); err != nil {
+			return apierrors.Send(c, err)
+		}
+	}
+	if strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMEApplicationJSON) {
+		return c.JSON(fiber.Map{"messages": len(messages)})
+	}
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(fmt.Sprintf("Sent %d message(s) to Discord\n", len(messages)))
+}
+
+// discordWebhook parses raw as a webhook URL on one of the allowed hosts
+// (SHOUT_DISCORD_WEBHOOK_HOSTS), so the relay can't be pointed elsewhere.
+func (h *Handler) discordWebhook(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil || !strings.HasPrefix(u.Path, discordWebhookPath) ||
+		!slices.Contains(h.config().Discord.WebhookHosts, u.Host) {
+		return nil, apierrors.ErrBadOption.WithDetail("webhook_url must be a Discord webhook URL, got %q", raw).
+			WithHint("copy it from Server Settings > Integrations > Webhooks")
+	}
+	return u, nil
+}
+
+// postDiscord posts one message to the webhook.
+func postDiscord(c *fiber.Ctx, webhook *url.URL, message string) error {
+	body, err := json.Marshal(map[string]any{
+		"content":          message,
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(c.UserContext(), http.MethodPost, webhook.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := discordClient.Do(req)
+	if err != nil {
+		return apierrors.ErrRelayFailed.WithDetail("Discord didn't answer")
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return apierrors.ErrRelayFailed.WithDetail("Discord answered %s", resp.Status)
+	}
+	return nil
+}
+
+// discordLimiter limits how many banners a client may relay per minute
+// (SHOUT_DISCORD_RELAYS_PER_MINUTE), or returns a pass-through handler
+// when there is no limit.
+func (h *Handler) discordLimiter() fiber.Handler {
+	cfg := h.config()
+	if cfg == nil || !cfg.Discord.Relay || cfg.Discord.RelaysPerMinute == 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return limiter.New(limiter.Config{
+		Max:        cfg.Discord.RelaysPerMinute,
+		Expiration: time.Minute,
+		LimitReached: func(c *fiber.Ctx) error {
+			return apierrors.Send(c, apierrors.ErrRateLimited)
+		},
+	})
+}
+
+// discordMessages splits output into Discord messages: ansi code blocks
+// of whole lines, each within Discord's 2000 character limit. Repeated
+// color sequences are dropped to save room, and runs of backticks are
+// broken up with zero width spaces; a line too long for a message
+// even then loses its colors, and is cut if it still doesn't fit.
+func discordMessages(output string) []string {
+	budget := discordMessageLimit - utf8.RuneCountInString(discordBlockStart+discordBlockEnd)
+
+	var messages []string
+	var block strings.Builder
+	size := 0
+	flush := func() {
+		if size > 0 {
+			messages = append(messages, discordBlockStart+block.String()+discordBlockEnd)
+		}
+		block.Reset()
+		size = 0
+	}
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		// Backticks in the art could close the code block early
+		line = strings.ReplaceAll(compactColors(line), "``", "`\u200b`") + "\n"
+		n := utf8.RuneCountInString(line)
+		if n > budget {
+			line = sgrSequence.ReplaceAllString(line, "")
+			if n = utf8.RuneCountInString(line); n > budget {
+				line = string([]rune(line)[:budget-1]) + "\n"
+				n = budget
+			}
+		}
+		if size+n > budget {
+			flush()
+		}
+		block.WriteString(line)
+		size += n
+	}
+	flush()
+	return messages
+}
+
+// compactColors drops the color sequences in line that repeat the color
+// already in effect.
+func compactColors(line string) string {
+	current := ""
+	return sgrSequence.ReplaceAllStringFunc(line, func(seq string) string {
+		if seq == current {
+			return ""
+		}
+		current = seq
+		return seq
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestDiscordMessages(t *testing.T) {
+	red := render.Color{R: 255}.Foreground()
+	line := strings.Repeat(red+"#", 10) + render.AnsiReset
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{name: "one block", output: "AB\nCD\n", want: []string{"```ansi\nAB\nCD\n```"}},
+		{name: "repeated colors dropped", output: render.ReduceColors(line, render.Discord), want: []string{"```ansi\n\x1b[31m##########\x1b[0m\n```"}},
+		{name: "backticks broken up", output: "```\n", want: []string{"```ansi\n`​``\n```"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := discordMessages(tt.output)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("discordMessages() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Long output splits between lines, within the limit
+	long := strings.Repeat(strings.Repeat("#", 99)+"\n", 50)
+	messages := discordMessages(long)
+	if len(messages) != 3 {
+		t.Errorf("got %d messages, want 3", len(messages))
+	}
+	lines := 0
+	for _, m := range messages {
+		if n := utf8.RuneCountInString(m); n > discordMessageLimit {
+			t.Errorf("message is %d characters long", n)
+		}
+		lines += strings.Count(m, "#\n")
+	}
+	if lines != 50 {
+		t.Errorf("messages hold %d lines, want 50", lines)
+	}
+
+	// A line too long for any message is cut
+	messages = discordMessages(strings.Repeat("#", 3000))
+	if len(messages) != 1 || utf8.RuneCountInString(messages[0]) != discordMessageLimit {
+		t.Errorf("long line gave %d messages of %d characters", len(messages), utf8.RuneCountInString(messages[0]))
+	}
+}
+
+func TestStaticDiscordFormat(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&c=fire&format=discord", nil))
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if !strings.HasPrefix(body, "```ansi\n") || !strings.HasSuffix(body, "```\n") {
+		t.Errorf("body isn't an ansi code block: %q", body)
+	}
+	if strings.Contains(body, "38;2;") || !strings.Contains(body, "\x1b[3") {
+		t.Errorf("body isn't in Discord's colors: %q", body)
+	}
+
+	_, plain := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&c=fire", nil))
+	if plainTag, tag := etagOf(t, app, "/HI?f=small&c=fire"), etagOf(t, app, "/HI?f=small&c=fire&format=discord"); plainTag == tag {
+		t.Errorf("formats share the ETag %s", tag)
+	}
+	if plain == body {
+		t.Error("format=discord didn't change the output")
+	}
+
+	resp, _ = doRequest(t, app, httptest.NewRequest("GET", "/HI?format=docx", nil))
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("unknown format: status = %d, want 400", resp.StatusCode)
+	}
+}
+
+// etagOf returns the ETag of a GET of path.
+func etagOf(t *testing.T, app *fiber.App, path string) string {
+	t.Helper()
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", path, nil))
+	return resp.Header.Get(fiber.HeaderETag)
+}
+
+func TestRelayDiscord(t *testing.T) {
+	var posted []map[string]any
+	status := http.StatusNoContent
+	discord := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+		posted = append(posted, body)
+		w.WriteHeader(status)
+	}))
+	defer discord.Close()
+	defer func(client *http.Client) { discordClient = client }(discordClient)
+	discordClient = discord.Client()
+
+	webhook, _ := url.Parse(discord.URL)
+	base := newTestHandler(t)
+	base.config().Discord = config.DiscordConfig{Relay: true, WebhookHosts: []string{webhook.Host}}
+	app := newTestAppFrom(New(Deps{Config: base.config(), Fonts: base.fonts}))
+	hook := discord.URL + "/api/webhooks/1/token"
+
+	tests := []struct {
+		name       string
+		body       string
+		status     int
+		wantStatus int
+		wantPosts  int
+	}{
+		{name: "relayed", body: `{"webhook_url":"` + hook + `","text":"HI","font":"small","color":"fire"}`, wantStatus: 200, wantPosts: 1},
+		{name: "other host", body: `{"webhook_url":"https://example.com/api/webhooks/1/token","text":"HI"}`, wantStatus: 400},
+		{name: "not a webhook", body: `{"webhook_url":"` + discord.URL + `/admin","text":"HI"}`, wantStatus: 400},
+		{name: "plain http", body: `{"webhook_url":"http://` + webhook.Host + `/api/webhooks/1/token","text":"HI"}`, wantStatus: 400},
+		{name: "no text", body: `{"webhook_url":"` + hook + `"}`, wantStatus: 400},
+		{name: "bad JSON", body: `{`, wantStatus: 400},
+		{name: "Discord fails", body: `{"webhook_url":"` + hook + `","text":"HI"}`, status: http.StatusNotFound, wantStatus: 502, wantPosts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted, status = nil, http.StatusNoContent
+			if tt.status != 0 {
+				status = tt.status
+			}
+			req := httptest.NewRequest("POST", "/integrations/discord", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if len(posted) != tt.wantPosts {
+				t.Fatalf("posted %d messages, want %d", len(posted), tt.wantPosts)
+			}
+			if tt.wantPosts == 0 || tt.wantStatus != 200 {
+				return
+			}
+			content, _ := posted[0]["content"].(string)
+			if !strings.HasPrefix(content, "```ansi\n") || !strings.Contains(content, "\x1b[3") {
+				t.Errorf("content isn't a colored ansi block: %q", content)
+			}
+			if mentions, _ := posted[0]["allowed_mentions"].(map[string]any); mentions == nil {
+				t.Error("message allows mentions")
+			}
+		})
+	}
+
+	// The relay isn't served unless enabled
+	resp, _ := doRequest(t, newTestApp(t), httptest.NewRequest("POST", "/integrations/discord", strings.NewReader(`{}`)))
+	if resp.StatusCode == fiber.StatusOK {
+		t.Error("relay served without SHOUT_DISCORD_RELAY")
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// downloadParam and codingParam document the file downloads of static
+// routes.
+var (
+	downloadParam = optionHelp{Names: []string{"download"}, Help: "1 sends the banner as a file to save, as curl -OJ does: Content-Disposition names it after the text, .ans with colors and .txt without, with line endings normalized to LF", Type: "string"}
+	codingParam   = optionHelp{Names: []string{"coding"}, Help: "1 starts a downloaded .ans or .txt file with a # -*- coding: utf-8 -*- comment line declaring its encoding; files never start with a byte order mark", Type: "string"}
+)
+
+// codingLine is the comment line declaring a downloaded file's encoding,
+// as editors such as Emacs and Vim read it.
+const codingLine = "# -*- coding: utf-8 -*-\n"
+
+// maxFileStem caps the length of download file names taken from the text.
+const maxFileStem = 40
+
+// download is how a static render is sent as a file (?download=1).
+type download struct {
+	enabled bool
+	// coding starts the file with codingLine (?coding=1)
+	coding bool
+}
+
+// requestDownload returns the ?download= and ?coding= options of the
+// request.
+func requestDownload(c *fiber.Ctx) download {
+	return download{enabled: c.QueryBool("download"), coding: c.QueryBool("coding")}
+}
+
+// etag returns the ETag of a download of the banner with ETag etag, which
+// differs from the banner's as the body and headers do.
+func (d download) etag(etag string) string {
+	if !d.enabled || etag == "" {
+		return etag
+	}
+	suffix := "-download"
+	if d.coding {
+		suffix += "-coding"
+	}
+	return strings.TrimSuffix(etag, `"`) + suffix + `"`
+}
+
+// apply sets Content-Disposition for a download of banner and returns
+// its body: for text, with every line ending in LF, and the coding line
+// first when asked for and the file is plain text.
+func (d download) apply(c *fiber.Ctx, banner *staticBanner, body []byte) []byte {
+	if !d.enabled {
+		return body
+	}
+	ext := downloadExtension(banner.format, string(body))
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+downloadStem(banner)+ext+`"`)
+	if convertedFormat(banner.format) {
+		return body
+	}
+
+	text := strings.ReplaceAll(string(body), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if d.coding && (ext == ".ans" || ext == ".txt") {
+		text = codingLine + text
+	}
+	return []byte(text)
+}
+
+// downloadExtension returns the file extension of a download of output in
+// format: .ans for plain text with colors, .txt without.
+func downloadExtension(format, output string) string {
+	switch format {
+	case formatJSON:
+		return ".json"
+	case formatHTML:
+		return ".html"
+	case formatSVG:
+		return ".svg"
+	case formatMarkdown, formatMarkdownANSI:
+		return ".md"
+	case formatPlain:
+		if strings.Contains(output, "\x1b[") {
+			return ".ans"
+		}
+	}
+	return ".txt"
+}
+
+// downloadStem returns the file name of a download of banner, without the
+// extension: the text in lowercase letters and digits joined by dashes,
+// or shout for text that has none, or masked words that shouldn't end up
+// in the name.
+func downloadStem(banner *staticBanner) string {
+	if len(banner.masked) > 0 {
+		return "shout"
+	}
+	words := strings.FieldsFunc(strings.ToLower(render.Transliterate(banner.text)), func(r rune) bool {
+		return r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	stem := strings.Join(words, "-")
+	if len(stem) > maxFileStem {
+		stem = strings.TrimRight(stem[:maxFileStem], "-")
+	}
+	if stem == "" {
+		return "shout"
+	}
+	return stem
+}
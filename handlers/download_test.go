@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStaticDownload(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name            string
+		target          string
+		wantDisposition string
+		wantPrefix      string
+	}{
+		{name: "colored", target: "/Hello+World?download=1&c=red", wantDisposition: `attachment; filename="hello-world.ans"`},
+		{name: "uncolored", target: "/Hello+World?download=1", wantDisposition: `attachment; filename="hello-world.txt"`},
+		{name: "coding line", target: "/HI?download=1&coding=1", wantDisposition: `attachment; filename="hi.txt"`, wantPrefix: codingLine},
+		{name: "markdown takes no coding line", target: "/HI?download=1&coding=1&format=md", wantDisposition: `attachment; filename="hi.md"`, wantPrefix: "```"},
+		{name: "converted format", target: "/HI?download=1&format=svg", wantDisposition: `attachment; filename="hi.svg"`, wantPrefix: "<svg"},
+		{name: "text without letters", target: "/%E2%98%83%E2%98%83?download=1", wantDisposition: `attachment; filename="shout.txt"`},
+		{name: "not a download", target: "/HI?coding=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+			}
+			if got := resp.Header.Get(fiber.HeaderContentDisposition); got != tt.wantDisposition {
+				t.Errorf("Content-Disposition = %q, want %q", got, tt.wantDisposition)
+			}
+			if !strings.HasPrefix(body, tt.wantPrefix) {
+				t.Errorf("body = %q, want it to start with %q", body, tt.wantPrefix)
+			}
+			if tt.wantPrefix != codingLine && strings.Contains(body, "coding:") {
+				t.Errorf("unexpected coding line in %q", body)
+			}
+			if strings.HasPrefix(body, "\ufeff") || strings.Contains(body, "\r") {
+				t.Errorf("body has a byte order mark or carriage returns: %q", body)
+			}
+			if !strings.HasSuffix(body, "\n") {
+				t.Errorf("body doesn't end in a line break: %q", body)
+			}
+		})
+	}
+
+	// Downloads differ from the banner, so they have ETags of their own
+	plain, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil))
+	file, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI?download=1", nil))
+	coded, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI?download=1&coding=1", nil))
+	etags := map[string]bool{}
+	for _, resp := range []*http.Response{plain, file, coded} {
+		etags[resp.Header.Get(fiber.HeaderETag)] = true
+	}
+	if len(etags) != 3 {
+		t.Errorf("ETags = %v, want one per representation", etags)
+	}
+}
+
+func TestDownloadStem(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "HELLO WORLD", want: "hello-world"},
+		{text: "Café: v2.0!", want: "cafe-v2-0"},
+		{text: "☃", want: "shout"},
+		{text: strings.Repeat("ab ", 30), want: "ab-ab-ab-ab-ab-ab-ab-ab-ab-ab-ab-ab-ab-a"},
+	}
+	for _, tt := range tests {
+		if got := downloadStem(&staticBanner{text: tt.text}); got != tt.want {
+			t.Errorf("downloadStem(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
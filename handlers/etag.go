@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// staticETag computes a strong ETag for a static render. Static output is
+// fully determined by the service version, the font file, the control
+// files, the sanitized text, the masked words, the options that affect
+// drawing, the alignment, the terminal's width and colors, the output
+// format, and the server's and virtual host's footers, so the tag can be
+// computed without rendering.
+func (h *Handler) staticETag(text string, opts types.RenderOptions, font *render.Font, masked []render.WordRange, term terminal, align, format, attribution, footer string) (string, error) {
+	fontSum, err := font.Checksum()
+	if err != nil {
+		return "", err
+	}
+
+	// The resolved font is hashed instead of the requested name, and timeout
+	// and speed only affect streams, so they're left out
+	opts.Font, opts.Timeout, opts.Speed = "", 0, 0
+
+	hash := sha256.New()
+	for _, part := range []string{
+		h.version(),
+		font.Name,
+		fontSum,
+		h.fonts.ControlsChecksum(),
+		text,
+		fmt.Sprintf("%+v", opts),
+		fmt.Sprint(masked),
+		fmt.Sprint(term.cols, term.colors, term.ascii, align),
+		format,
+		attribution,
+		footer,
+	} {
+		io.WriteString(hash, part)
+		hash.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`, nil
+}
+
+// etagMatches reports whether the request's If-None-Match header matches
+// etag, using the weak comparison RFC 9110 requires for If-None-Match.
+func etagMatches(c *fiber.Ctx, etag string) bool {
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// dynamicCacheControl is sent instead of the configured Cache-Control when
+// the text had placeholders expanded, since the output then depends on the
+// time or the client.
+const dynamicCacheControl = "private, no-cache"
+
+// setCacheHeaders sets the ETag and the configured Cache-Control header.
+func (h *Handler) setCacheHeaders(c *fiber.Ctx, etag string) {
+	cfg := h.config()
+	if etag == "" {
+		return
+	}
+	c.Set(fiber.HeaderETag, etag)
+	if middleware.Dynamic(c) {
+		c.Set(fiber.HeaderCacheControl, dynamicCacheControl)
+		return
+	}
+	if cfg != nil && cfg.Server.CacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, cfg.Server.CacheControl)
+	}
+}
+
+// version returns the configured service version, or "" without config.
+func (h *Handler) version() string {
+	cfg := h.config()
+	if cfg == nil {
+		return ""
+	}
+	return cfg.Version
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStaticETag(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Server.CacheControl = "public, max-age=60"
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=doom", nil))
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != 200 || etag == "" || body == "" {
+		t.Fatalf("expected 200 with ETag, got %d %q", resp.StatusCode, etag)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	tests := []struct {
+		name        string
+		target      string
+		ifNoneMatch string
+		wantStatus  int
+		wantSameTag bool
+	}{
+		{name: "matching tag", target: "/HI?f=doom", ifNoneMatch: etag, wantStatus: 304, wantSameTag: true},
+		{name: "weak matching tag", target: "/HI?f=doom", ifNoneMatch: "W/" + etag, wantStatus: 304, wantSameTag: true},
+		{name: "tag in list", target: "/HI?f=doom", ifNoneMatch: `"other", ` + etag, wantStatus: 304, wantSameTag: true},
+		{name: "wildcard", target: "/HI?f=doom", ifNoneMatch: "*", wantStatus: 304, wantSameTag: true},
+		{name: "stale tag", target: "/HI?f=doom", ifNoneMatch: `"stale"`, wantStatus: 200, wantSameTag: true},
+		{name: "fuzzy font name resolves to same tag", target: "/HI?f=dooom&notes=false", ifNoneMatch: etag, wantStatus: 304, wantSameTag: true},
+		{name: "timeout does not change tag", target: "/HI?f=doom&t=5", ifNoneMatch: etag, wantStatus: 304, wantSameTag: true},
+		{name: "different text", target: "/HO?f=doom", ifNoneMatch: etag, wantStatus: 200},
+		{name: "different font", target: "/HI?f=small", ifNoneMatch: etag, wantStatus: 200},
+		{name: "different color", target: "/HI?f=doom&c=red", ifNoneMatch: etag, wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			resp, body := doRequest(t, app, req)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == 304 && body != "" {
+				t.Errorf("304 response should have no body, got %q", body)
+			}
+			if got := resp.Header.Get("ETag"); (got == etag) != tt.wantSameTag {
+				t.Errorf("ETag = %q, original %q, want same = %v", got, etag, tt.wantSameTag)
+			}
+		})
+	}
+}
+
+func TestStaticErrorHasNoCacheHeaders(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI?c=notacolor", nil))
+	if resp.StatusCode != 400 {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Cache-Control") != "" {
+		t.Error("error responses must not be cacheable")
+	}
+}
+
+func TestStaticPlaceholdersNotShared(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Server.CacheControl = "public, max-age=60"
+	h.config().Text.Placeholders = []string{"date"}
+	app := newTestAppFrom(h)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/%7Bdate%7D", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "private, no-cache" {
+		t.Errorf("Cache-Control = %q, want private, no-cache", got)
+	}
+
+	resp, _ = doRequest(t, app, httptest.NewRequest("GET", "/HI", nil))
+	if got := resp.Header.Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q, want the configured value", got)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/ipaccess"
+	"github.com/ryanlewis/shout-sh/middleware"
+)
+
+// exemptions are the clients no rate limit or quota applies to:
+// internal IPs, API keys and health check user agents
+// (SHOUT_RATELIMIT_EXEMPT_*).
+type exemptions struct {
+	// ips is an allow list of the exempt ranges; nil when there are none
+	ips    *ipaccess.List
+	keys   []string
+	agents []string
+}
+
+// newExemptions returns the configured exemptions, or nil when there are
+// none.
+func (h *Handler) newExemptions() *exemptions {
+	if h.config() == nil {
+		return nil
+	}
+	limits := h.config().RateLimit
+	if len(limits.ExemptIPs)+len(limits.ExemptKeys)+len(limits.ExemptAgents) == 0 {
+		return nil
+	}
+	e := &exemptions{keys: limits.ExemptKeys}
+	for _, agent := range limits.ExemptAgents {
+		if agent = strings.ToLower(strings.TrimSpace(agent)); agent != "" {
+			e.agents = append(e.agents, agent)
+		}
+	}
+	if len(limits.ExemptIPs) > 0 {
+		ips, err := ipaccess.Parse(limits.ExemptIPs, nil)
+		if err != nil {
+			log.Printf("Ignoring rate limit IP exemptions: %v", err)
+		} else {
+			e.ips = ips
+		}
+	}
+	return e
+}
+
+// exempt reports whether the request's client skips rate limits and
+// quotas: it comes from an exempt IP, with an exempt API key, or with a
+// User-Agent starting with an exempt prefix.
+func (h *Handler) exempt(c *fiber.Ctx) bool {
+	e := h.exemptions
+	if e == nil {
+		return false
+	}
+	if e.ips != nil && e.ips.Allowed(c.IP()) {
+		return true
+	}
+	if key := middleware.Key(c); key != nil && slices.Contains(e.keys, key.Name) {
+		return true
+	}
+	agent := strings.ToLower(c.Get(fiber.HeaderUserAgent))
+	for _, prefix := range e.agents {
+		if strings.HasPrefix(agent, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unlessExempt wraps limit, a rate limit or quota handler, so exempt
+// clients pass straight through it.
+func (h *Handler) unlessExempt(limit fiber.Handler) fiber.Handler {
+	if h.exemptions == nil {
+		return limit
+	}
+	return func(c *fiber.Ctx) error {
+		if h.exempt(c) {
+			return c.Next()
+		}
+		return limit(c)
+	}
+}
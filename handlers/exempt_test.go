@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestRateLimitExemptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		exemption config.RateLimitConfig
+		agent     string
+		key       string
+		want      []int
+	}{
+		{name: "no exemption", want: []int{200, 200, 429}},
+		{name: "exempt IP", exemption: config.RateLimitConfig{ExemptIPs: []string{"0.0.0.0/8"}}, want: []int{200, 200, 200}},
+		{name: "other IP", exemption: config.RateLimitConfig{ExemptIPs: []string{"10.0.0.0/8"}}, want: []int{200, 200, 429}},
+		{name: "exempt key", exemption: config.RateLimitConfig{ExemptKeys: []string{"acme"}}, key: "s3cret", want: []int{200, 200, 200}},
+		{name: "exempt agent", exemption: config.RateLimitConfig{ExemptAgents: []string{"kube-probe"}}, agent: "kube-probe/1.29", want: []int{200, 200, 200}},
+		{name: "agent prefix only", exemption: config.RateLimitConfig{ExemptAgents: []string{"kube-probe"}}, agent: "curl/8.0 kube-probe", want: []int{200, 200, 429}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newKeysHandler(t)
+			cfg := *h.config()
+			cfg.RateLimit = tt.exemption
+			// Clients that aren't exempt hit both limits on their third
+			// request
+			cfg.RateLimit.Routes = []string{"render=2"}
+			cfg.Quota.RequestsPerDay = 2
+			app := newTestAppFrom(New(Deps{Config: &cfg, Fonts: h.fonts, Keys: h.keys}))
+			for i, want := range tt.want {
+				req := httptest.NewRequest("GET", "/HI", nil)
+				if tt.agent != "" {
+					req.Header.Set("User-Agent", tt.agent)
+				}
+				if tt.key != "" {
+					req.Header.Set("X-API-Key", tt.key)
+				}
+				resp, body := doRequest(t, app, req)
+				if resp.StatusCode != want {
+					t.Fatalf("request %d status = %d, want %d (body %q)", i+1, resp.StatusCode, want, body)
+				}
+			}
+		})
+	}
+}
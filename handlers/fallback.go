@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// notePrefix starts the comment line noting what a static banner drew
+// differently from the request, so shells and config files skip it.
+const notePrefix = "# shout: "
+
+// strictParam and notesParam document how static routes answer text they
+// can't draw as requested.
+var (
+	strictParam = optionHelp{Names: []string{"strict"}, Help: "Fail with 400 instead of falling back to another font or drawing missing characters as ? (true/false)", Type: "string"}
+	notesParam  = optionHelp{Names: []string{"notes"}, Help: "Note a font fallback or missing characters in a # comment line below the banner; false leaves it out (true/false)", Type: "string"}
+)
+
+// fallback is what a banner draws differently from the request: another
+// font than the one named, or '?' for characters the font lacks.
+type fallback struct {
+	// requested is the font named, "" when it was drawn
+	requested string
+	// font is the font drawn instead
+	font string
+	// missing are the characters drawn as '?'
+	missing []rune
+}
+
+// checkFallback compares the font the request named with the one
+// resolved, and looks for characters of text the resolved font lacks.
+func (h *Handler) checkFallback(requested string, font *render.Font, text string) (fallback, error) {
+	fb := fallback{font: font.Name}
+	if requested != "" && requested != font.Name {
+		fb.requested = requested
+	}
+	missing, err := font.MissingRunes(h.fonts.ApplyControls(text))
+	if err != nil {
+		return fallback{}, err
+	}
+	fb.missing = missing
+	return fb, nil
+}
+
+// engineFontRequest returns the font opts ask for, to check for fallback:
+// opts.Font, or "" with the block and braille engines, which draw in
+// their own font whatever the font option.
+func engineFontRequest(opts types.RenderOptions) string {
+	if opts.Engine != "" && opts.Engine != render.EngineFiglet {
+		return ""
+	}
+	return opts.Font
+}
+
+// messages describes the fallback, one message per substitution, or none
+// when the banner is drawn as requested.
+func (fb fallback) messages() []string {
+	var messages []string
+	if fb.requested != "" {
+		messages = append(messages, fmt.Sprintf("font %q isn't loaded, drew %s", fb.requested, fb.font))
+	}
+	if len(fb.missing) > 0 {
+		messages = append(messages, fmt.Sprintf("%s has no %q, drew ?", fb.font, string(fb.missing)))
+	}
+	return messages
+}
+
+// strictFallback returns the error of a ?strict=true request whose banner
+// would fall back, or nil.
+func strictFallback(c *fiber.Ctx, fb fallback, suggestion string) *apierrors.Error {
+	messages := fb.messages()
+	if len(messages) == 0 || !c.QueryBool("strict") {
+		return nil
+	}
+	return apierrors.ErrFallback.WithDetail("%s", strings.Join(messages, "; ")).WithHint(suggestion)
+}
+
+// fallbackNote returns the comment line added below a banner that falls
+// back, or "" when it doesn't or the request sends ?notes=false.
+func fallbackNote(c *fiber.Ctx, fb fallback) string {
+	messages := fb.messages()
+	if len(messages) == 0 || !c.QueryBool("notes", true) {
+		return ""
+	}
+	return notePrefix + strings.Join(messages, "; ")
+}
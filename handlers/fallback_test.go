@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+func TestStaticFallback(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCode   string
+		wantNote   string
+	}{
+		{name: "drawn as requested", target: "/HI?f=doom", wantStatus: 200},
+		{name: "misspelled font", target: "/HI?f=dooom", wantStatus: 200, wantNote: `# shout: font "dooom" isn't loaded, drew doom`},
+		{name: "missing character", target: "/H%E2%98%83?f=doom", wantStatus: 200, wantNote: `# shout: doom has no "☃", drew ?`},
+		{name: "both", target: "/H%E2%98%83?f=dooom", wantStatus: 200, wantNote: `# shout: font "dooom" isn't loaded, drew doom; doom has no "☃", drew ?`},
+		{name: "notes off", target: "/HI?f=dooom&notes=false", wantStatus: 200},
+		{name: "strict misspelled font", target: "/HI?f=dooom&strict=true", wantStatus: 400, wantCode: "fallback"},
+		{name: "strict missing character", target: "/H%E2%98%83?f=doom&strict=true", wantStatus: 400, wantCode: "fallback"},
+		{name: "strict drawn as requested", target: "/HI?f=doom&strict=true", wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(apierrors.HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("%s = %q, want %q", apierrors.HeaderErrorCode, got, tt.wantCode)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+			note := ""
+			if last := lines[len(lines)-1]; strings.HasPrefix(last, notePrefix) {
+				note = last
+			}
+			if note != tt.wantNote {
+				t.Errorf("note = %q, want %q", note, tt.wantNote)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/shortlink"
+)
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		name       string
+		disable    func(f *config.FeaturesConfig)
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "streaming off: party", disable: func(f *config.FeaturesConfig) { f.EnableStreaming = false }, target: "/p/HI?t=1", wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "streaming off: versioned party", disable: func(f *config.FeaturesConfig) { f.EnableStreaming = false }, target: "/v1/party/HI?t=1", wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "streaming off: countdown", disable: func(f *config.FeaturesConfig) { f.EnableStreaming = false }, target: "/countdown/1", wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "streaming off: ANSI playback", disable: func(f *config.FeaturesConfig) { f.EnableStreaming = false }, method: "POST", target: "/ans", body: "HI", wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "streaming off: static still served", disable: func(f *config.FeaturesConfig) { f.EnableStreaming = false }, target: "/HI", wantStatus: 200},
+		{name: "uploads off: ANSI upload", disable: func(f *config.FeaturesConfig) { f.EnableUploads = false }, method: "POST", target: "/ans", body: "HI", wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "uploads off: countdown still served", disable: func(f *config.FeaturesConfig) { f.EnableUploads = false }, target: "/countdown/1", wantStatus: 200},
+		{name: "shortlinks off: create", disable: func(f *config.FeaturesConfig) { f.EnableShortlinks = false }, method: "POST", target: "/s", body: `{"text":"HI"}`, wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "shortlinks off: replay", disable: func(f *config.FeaturesConfig) { f.EnableShortlinks = false }, target: "/s/abc123", wantStatus: 404, wantCode: "feature_disabled"},
+		{name: "GIF off", disable: func(f *config.FeaturesConfig) { f.EnableGIF = false }, target: "/countdown/1?format=gif", wantStatus: 400, wantCode: "bad_option"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := newGIFHandler(t, 0)
+			tt.disable(&base.config().Features)
+			app := newTestAppFrom(New(Deps{Config: base.config(), Fonts: base.fonts, Shortlinks: shortlink.NewMemoryStore(100)}))
+
+			req := httptest.NewRequest(firstOf(tt.method, "GET"), tt.target, strings.NewReader(tt.body))
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if code := resp.Header.Get("X-Shout-Error"); code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+)
+
+// nofooterParam documents leaving out the footer of SHOUT_TEXT_FOOTER.
+var nofooterParam = optionHelp{Names: []string{"nofooter"}, Help: "1 leaves out the line this server draws below banners (SHOUT_TEXT_FOOTER); needs an API key", Type: "string"}
+
+// serverFooter returns the line SHOUT_TEXT_FOOTER draws below static
+// banners, or "" when there is none or a request with an API key leaves
+// it out with ?nofooter=1. Requests without a key can't leave it out, as
+// public instances use it for attribution.
+func (h *Handler) serverFooter(c *fiber.Ctx) (string, error) {
+	if !c.QueryBool("nofooter") {
+		return h.textConfig().Footer, nil
+	}
+	if middleware.Key(c) == nil {
+		return "", apierrors.ErrNotEntitled.
+			WithDetail("nofooter needs an API key").
+			WithHint("send an API key in the X-API-Key header")
+	}
+	return "", nil
+}
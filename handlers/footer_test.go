@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+func TestServerFooter(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		key        string
+		wantStatus int
+		wantCode   string
+		wantFooter bool
+	}{
+		{name: "drawn by default", target: "/HI?f=small", wantStatus: 200, wantFooter: true},
+		{name: "left out with a key", target: "/HI?f=small&nofooter=1", key: "s3cret", wantStatus: 200},
+		{name: "kept with a key", target: "/HI?f=small", key: "s3cret", wantStatus: 200, wantFooter: true},
+		{name: "needs a key to leave out", target: "/HI?f=small&nofooter=1", wantStatus: 403, wantCode: "not_entitled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newKeysHandler(t)
+			h.config().Text.Footer = "generated by shout.sh"
+			app := newTestAppFrom(h)
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(apierrors.HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("%s = %q, want %q", apierrors.HeaderErrorCode, got, tt.wantCode)
+			}
+			if got := strings.HasSuffix(body, "generated by shout.sh\n"); tt.wantStatus == 200 && got != tt.wantFooter {
+				t.Errorf("footer drawn = %v, want %v:\n%s", got, tt.wantFooter, body)
+			}
+		})
+	}
+}
+
+func TestServerFooterAligned(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Text.Footer = "by shout.sh"
+	app := newTestAppFrom(h)
+
+	_, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&cols=40&a=right", nil))
+	lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	if last := lines[len(lines)-1]; len(last) != 40 || !strings.HasSuffix(last, "by shout.sh") {
+		t.Errorf("footer %q isn't aligned right within 40 columns:\n%s", last, body)
+	}
+
+	// The footer is part of the banner, so it changes the ETag
+	plain, _ := doRequest(t, newTestApp(t), httptest.NewRequest("GET", "/HI?f=small&cols=40&a=right", nil))
+	footed, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&cols=40&a=right", nil))
+	if plain.Header.Get("ETag") == footed.Header.Get("ETag") {
+		t.Error("the footer didn't change the ETag")
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// Fallbacks for the fortune settings when no config is set.
+const (
+	defaultFortuneFont  = "small"
+	defaultFortuneWidth = 80
+)
+
+// fortuneParams are the query parameters of the fortune route.
+var fortuneParams = []optionHelp{
+	{Names: []string{"headline"}, Help: "Banner drawn above the quote in the chosen font", Type: "string"},
+	{Names: []string{"daily"}, Help: "Show the quote of the day instead of a random one (true/false)", Type: "string"},
+	colsParam,
+	termParam,
+	ncParam,
+	seedParam,
+}
+
+// Fortune renders a random quote in a small font, word wrapped at
+// SHOUT_FORTUNE_WIDTH columns, the terminal width from ?cols= or
+// X-Terminal-Size, or ?mw=. ?headline= draws a banner in the
+// requested font above it, and ?daily=true keeps the same quote all day,
+// for login MOTDs. Colors apply to both the headline and the quote.
+//
+// Example:
+//
+//	curl 'localhost:8080/fortune?headline=HELLO&c=ocean'
+func (h *Handler) Fortune(c *fiber.Ctx) error {
+	cfg := h.config()
+	h.metrics.IncFortune()
+
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	opts.Font = h.requestFont(c, opts.Font)
+	suggestion := h.suggestFont(c, opts.Font)
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err).WithHint(suggestion))
+	}
+
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	align := h.alignment(opts)
+
+	text := h.textConfig()
+	headline, err := middleware.CheckText(c.Query("headline"), text)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	masked, err := middleware.FilterText(h.wordFilter(), text.Filter, headline, h.metrics)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	var quote string
+	if c.QueryBool("daily") {
+		quote = h.fortunes.Daily(clockNow())
+		c.Set(fiber.HeaderCacheControl, dynamicCacheControl)
+	} else {
+		quote = h.fortunes.Pick(h.requestRand(c))
+		c.Set(fiber.HeaderCacheControl, "no-store")
+	}
+
+	ctx, cancel := h.renderContext(c, opts.Timeout)
+	defer cancel()
+
+	var b strings.Builder
+	if headline != "" {
+		if font := h.fonts.ResolveFont(opts.Font, h.defaultFont()); font != nil {
+			if headline, err = term.wrap(ctx, font, headline); err != nil {
+				return h.fortuneError(c, err)
+			}
+		}
+		layout, err := render.GenerateLayoutContext(ctx, headline, opts, h.fonts)
+		if err != nil {
+			return h.fortuneError(c, err)
+		}
+		render.MaskLayout(layout, masked)
+		b.WriteString(render.ColorizeLayout(render.AlignLayout(layout, term.cols, align), scheme, 0))
+		b.WriteString("\n")
+	}
+
+	quoteOpts := opts
+	quoteOpts.Font, quoteOpts.MaxWidth = defaultFortuneFont, defaultFortuneWidth
+	if cfg != nil && cfg.Fortune.Font != "" {
+		quoteOpts.Font = cfg.Fortune.Font
+	}
+	if cfg != nil && cfg.Fortune.Width > 0 {
+		quoteOpts.MaxWidth = cfg.Fortune.Width
+	}
+	if term.cols > 0 {
+		quoteOpts.MaxWidth = term.cols
+	}
+	if opts.MaxWidth > 0 {
+		quoteOpts.MaxWidth = opts.MaxWidth
+	}
+	font := h.fonts.ResolveFont(quoteOpts.Font, h.defaultFont())
+	if font == nil {
+		return h.fortuneError(c, errors.New("no fonts loaded"))
+	}
+	wrapped, err := render.WrapText(ctx, font, render.SanitizeText(quote), quoteOpts.MaxWidth)
+	if err != nil {
+		return h.fortuneError(c, err)
+	}
+	layout, err := render.GenerateLayoutContext(ctx, wrapped, quoteOpts, h.fonts)
+	if err != nil {
+		return h.fortuneError(c, err)
+	}
+	b.WriteString(render.ColorizeLayout(render.AlignLayout(layout, term.cols, align), scheme, 0))
+	h.countUsage(opts.Font, opts.Color, "", false)
+
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(render.ReduceColors(b.String(), term.colors))
+}
+
+// fortuneError reports a failed fortune render.
+func (h *Handler) fortuneError(c *fiber.Ctx, err error) error {
+	h.metrics.IncError()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apierrors.Send(c, apierrors.ErrRenderTimeout)
+	}
+	log.Printf("Error rendering fortune: %v", err)
+	return apierrors.Send(c, apierrors.ErrRenderFailed)
+}
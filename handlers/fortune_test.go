@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/fortune"
+)
+
+// newFortuneHandler returns a test handler serving a single quote.
+func newFortuneHandler(t *testing.T, quote string) *Handler {
+	t.Helper()
+	h := newTestHandler(t)
+	db, err := fortune.Parse(strings.NewReader(quote + "\n"))
+	if err != nil {
+		t.Fatalf("parse fortunes: %v", err)
+	}
+	h.fortunes = db
+	return h
+}
+
+func TestFortune(t *testing.T) {
+	h := newFortuneHandler(t, "WORK HARD")
+	h.config().Fortune.Font = "small"
+	app := newTestAppFrom(h)
+
+	tests := []struct {
+		name         string
+		target       string
+		wantStatus   int
+		wantText     string
+		wantHeadline string
+		wantCache    string
+	}{
+		{name: "random", target: "/fortune", wantStatus: 200, wantText: "WORK HARD", wantCache: "no-store"},
+		{name: "wrapped", target: "/fortune?mw=40", wantStatus: 200, wantText: "WORK\nHARD", wantCache: "no-store"},
+		{name: "headline", target: "/fortune?headline=HI&f=doom", wantStatus: 200, wantText: "WORK HARD", wantHeadline: "HI", wantCache: "no-store"},
+		{name: "daily", target: "/fortune?daily=true", wantStatus: 200, wantText: "WORK HARD", wantCache: dynamicCacheControl},
+		{name: "headline too long", target: "/fortune?headline=" + strings.Repeat("A", 21), wantStatus: 400},
+		{name: "bad color", target: "/fortune?c=notacolor", wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+
+			want := renderText(t, h, tt.wantText)
+			if tt.wantHeadline != "" {
+				headline, err := h.fonts.ResolveFont("doom", "").Render(tt.wantHeadline)
+				if err != nil {
+					t.Fatalf("render headline: %v", err)
+				}
+				want = headline + "\n" + want
+			}
+			if body != want {
+				t.Errorf("body =\n%s\nwant\n%s", body, want)
+			}
+			if got := resp.Header.Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestFortuneDaily(t *testing.T) {
+	orig := clockNow
+	t.Cleanup(func() { clockNow = orig })
+
+	h := newTestHandler(t)
+	app := newTestAppFrom(h)
+
+	get := func(day int) string {
+		clockNow = func() time.Time { return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC) }
+		_, body := doRequest(t, app, httptest.NewRequest("GET", "/fortune?daily=true", nil))
+		return body
+	}
+	if get(5) != get(5) {
+		t.Error("the quote of the day changed within the day")
+	}
+	if get(5) == get(6) {
+		t.Error("the quote of the day didn't change the next day")
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+)
+
+// geoLimiter limits each IP in a country with a rate limit of its own
+// (SHOUT_GEOIP_RATE_LIMITS) to that many requests per minute, with a
+// limiter per country. Requests with an API key follow their tier's limit
+// instead. It passes everything through when GeoIP is off.
+func (h *Handler) geoLimiter() fiber.Handler {
+	if h.geo == nil {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	var mu sync.Mutex
+	limiters := make(map[string]fiber.Handler)
+	return func(c *fiber.Ctx) error {
+		country := middleware.Country(c)
+		perMinute := h.geo.RateLimit(country)
+		if perMinute == 0 || middleware.Key(c) != nil || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		mu.Lock()
+		limit, ok := limiters[country]
+		if !ok {
+			limit = limiter.New(limiter.Config{
+				Max:        perMinute,
+				Expiration: time.Minute,
+				LimitReached: func(c *fiber.Ctx) error {
+					return apierrors.Send(c, apierrors.ErrRateLimited.WithDetail("%d requests per minute from %s", perMinute, country))
+				},
+			})
+			limiters[country] = limit
+		}
+		mu.Unlock()
+		return limit(c)
+	}
+}
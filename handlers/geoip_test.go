@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/geoip"
+)
+
+// newGeoHandler builds a handler whose GeoIP database places app.Test
+// clients, from 0.0.0.0, in France.
+func newGeoHandler(t *testing.T, cfg config.GeoIPConfig) *Handler {
+	t.Helper()
+
+	cfg.Database = filepath.Join(t.TempDir(), "test.mmdb")
+	f, err := os.Create(cfg.Database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := geoip.Write(f, map[netip.Prefix]string{netip.MustParsePrefix("0.0.0.0/8"): "FR"}); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := geoip.NewPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t)
+	return New(Deps{Config: h.config(), Fonts: h.fonts, GeoIP: policy})
+}
+
+func TestGeoIPRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		rateLimits []string
+		want       []int
+	}{
+		{name: "country limit", rateLimits: []string{"FR=2"}, want: []int{200, 200, 429}},
+		{name: "other country's limit", rateLimits: []string{"DE=1"}, want: []int{200, 200, 200}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestAppFrom(newGeoHandler(t, config.GeoIPConfig{RateLimits: tt.rateLimits}))
+			for i, want := range tt.want {
+				resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil))
+				if resp.StatusCode != want {
+					t.Fatalf("request %d status = %d, want %d (body %q)", i+1, resp.StatusCode, want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestGeoIPStats(t *testing.T) {
+	h := newGeoHandler(t, config.GeoIPConfig{Deny: []string{"FR"}})
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != fiber.StatusForbidden {
+		t.Fatalf("status = %d, want 403 for a denied country", resp.StatusCode)
+	}
+
+	_, body := doRequest(t, admin, httptest.NewRequest("GET", "/stats", nil))
+	var got struct {
+		Countries map[string]int64 `json:"countries"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Countries["FR"] != 1 {
+		t.Errorf("countries = %v, want one request from FR", got.Countries)
+	}
+}
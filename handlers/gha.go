@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"strings"
+)
+
+// ghaGroupTitle names the collapsible log group of ?format=gha.
+const ghaGroupTitle = "shout.sh"
+
+// ghaLog wraps output for a GitHub Actions log: a collapsible group, with
+// workflow commands stopped around the banner so art lines starting with
+// "::" print as they are. The stop token is derived from the output, so
+// the response stays deterministic.
+func ghaLog(output string) string {
+	sum := sha256.Sum256([]byte(output))
+	token := "shout-" + hex.EncodeToString(sum[:8])
+	return "::group::" + ghaGroupTitle + "\n" +
+		"::stop-commands::" + token + "\n" +
+		strings.TrimRight(output, "\n") + "\n" +
+		"::" + token + "::\n" +
+		"::endgroup::\n"
+}
+
+// ghaSummary wraps output as an HTML pre block for the Markdown of
+// $GITHUB_STEP_SUMMARY, which doesn't show terminal colors.
+func ghaSummary(output string) string {
+	return "<pre>\n" + html.EscapeString(strings.TrimRight(output, "\n")) + "\n</pre>\n"
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGHALog(t *testing.T) {
+	got := ghaLog("::warning::x\n")
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("ghaLog() = %q, want 5 lines", got)
+	}
+	if lines[0] != "::group::shout.sh" || lines[4] != "::endgroup::" {
+		t.Errorf("banner isn't in a group: %q", got)
+	}
+	token, ok := strings.CutPrefix(lines[1], "::stop-commands::")
+	if !ok || lines[3] != "::"+token+"::" {
+		t.Errorf("commands aren't stopped around the banner: %q", got)
+	}
+	if lines[2] != "::warning::x" {
+		t.Errorf("banner = %q", lines[2])
+	}
+	if ghaLog("::warning::x\n") != got {
+		t.Error("ghaLog() isn't deterministic")
+	}
+}
+
+func TestGHASummary(t *testing.T) {
+	if got, want := ghaSummary(" <_> & \n"), "<pre>\n &lt;_&gt; &amp; \n</pre>\n"; got != want {
+		t.Errorf("ghaSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestStaticGHAFormats(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		format     string
+		wantPrefix string
+		wantColors bool
+	}{
+		{format: "gha", wantPrefix: "::group::", wantColors: true},
+		{format: "gha-summary", wantPrefix: "<pre>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&c=fire&format="+tt.format, nil))
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if !strings.HasPrefix(body, tt.wantPrefix) {
+				t.Errorf("body doesn't start with %q: %q", tt.wantPrefix, body)
+			}
+			if strings.Contains(body, "\x1b[") != tt.wantColors {
+				t.Errorf("body colored = %v, want %v: %q", !tt.wantColors, tt.wantColors, body)
+			}
+		})
+	}
+}
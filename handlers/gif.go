@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/termgif"
+)
+
+// recordGIF records a stream (see record) and sends it as an animated GIF,
+// one frame per chunk, within the SHOUT_GIF_* bounds.
+func (h *Handler) recordGIF(c *fiber.Ctx, interval time.Duration, prologue, epilogue string, next frameSource) error {
+	cfg := h.config().GIF
+	events, err := h.record(c, interval, prologue, epilogue, next, cfg.MaxFrames)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrRenderFailed.WithDetail("%v", err))
+	}
+
+	rec := h.playEvents(events)
+	var b bytes.Buffer
+	if err := rec.Encode(&b); err != nil {
+		return apierrors.Send(c, apierrors.ErrRenderFailed.WithDetail("%v", err))
+	}
+	c.Set(fiber.HeaderContentType, "image/gif")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="shout.gif"`)
+	return c.Send(b.Bytes())
+}
+
+// playEvents plays events on a screen sized to their output, within the
+// SHOUT_GIF_* bounds, adding a frame whenever time moves on.
+func (h *Handler) playEvents(events []streamEvent) *termgif.Recorder {
+	cfg := h.config().GIF
+	var output []byte
+	for _, e := range events {
+		output = append(output, e.data...)
+	}
+	cols, rows := castSize(string(output))
+	rec := termgif.New(min(cols, cfg.MaxCols), min(rows, cfg.MaxRows), cfg.Scale)
+
+	// Chunks sent at the same moment share a frame
+	for i, e := range events {
+		rec.Write(e.data)
+		if i+1 < len(events) && events[i+1].at == e.at {
+			continue
+		}
+		var delay time.Duration
+		if i+1 < len(events) {
+			delay = events[i+1].at - e.at
+		}
+		rec.Frame(delay)
+	}
+	return rec
+}
+
+// gifLimiter limits how many GIFs a client may render per minute
+// (SHOUT_GIF_PER_MINUTE). Other requests pass through, as do all requests
+// when GIF export is disabled or unlimited.
+func (h *Handler) gifLimiter() fiber.Handler {
+	cfg := h.config()
+	if !h.gifEnabled() || cfg.GIF.PerMinute == 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return limiter.New(limiter.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return c.Query("format") != formatGIF
+		},
+		Max:        cfg.GIF.PerMinute,
+		Expiration: time.Minute,
+		LimitReached: func(c *fiber.Ctx) error {
+			return apierrors.Send(c, apierrors.ErrRateLimited)
+		},
+	})
+}
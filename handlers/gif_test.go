@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"image/gif"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/termgif"
+)
+
+// newGIFHandler returns a test handler with GIF export enabled.
+func newGIFHandler(t *testing.T, perMinute int) *Handler {
+	t.Helper()
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	h.config().GIF = config.GIFConfig{Enabled: true, MaxFrames: 300, MaxCols: 160, MaxRows: 60, Scale: 1, PerMinute: perMinute}
+	return New(Deps{Config: h.config(), Fonts: h.fonts})
+}
+
+func TestCountdownGIF(t *testing.T) {
+	h := newGIFHandler(t, 0)
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/2?f=small&format=gif", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "image/gif" {
+		t.Errorf("Content-Type = %q, want image/gif", got)
+	}
+
+	anim, err := gif.DecodeAll(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("invalid GIF: %v", err)
+	}
+	// The prologue shares the first frame and the epilogue the last
+	if len(anim.Image) != 3 {
+		t.Fatalf("frames = %d, want 3", len(anim.Image))
+	}
+	if anim.Delay[0] != 100 || anim.Delay[1] != 100 {
+		t.Errorf("delays = %v, want a second between frames", anim.Delay)
+	}
+
+	frame := renderText(t, h, "0:02")
+	lines := strings.Split(strings.TrimRight(frame, "\n"), "\n")
+	if b := anim.Image[0].Bounds(); b.Dx() < len(lines[0])*termgif.CellWidth || b.Dy() < len(lines)*termgif.CellHeight {
+		t.Errorf("size = %v, too small for a %d line frame", b, len(lines))
+	}
+	if h.streams.GetActiveCount() != 0 {
+		t.Error("recording shouldn't hold a stream slot")
+	}
+}
+
+func TestGIFBounds(t *testing.T) {
+	h := newGIFHandler(t, 0)
+	h.config().GIF.MaxFrames = 2
+	h.config().GIF.MaxCols = 10
+	h.config().GIF.MaxRows = 2
+	app := newTestAppFrom(h)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/30?format=gif", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	anim, err := gif.DecodeAll(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("invalid GIF: %v", err)
+	}
+	if len(anim.Image) != 2 {
+		t.Errorf("frames = %d, want 2", len(anim.Image))
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 10*termgif.CellWidth || b.Dy() != 2*termgif.CellHeight {
+		t.Errorf("size = %v, want %dx%d", b, 10*termgif.CellWidth, 2*termgif.CellHeight)
+	}
+}
+
+func TestGIFDisabled(t *testing.T) {
+	app := newTestAppFrom(newTestHandler(t))
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/2?format=gif", nil))
+	if resp.StatusCode != 400 || !strings.Contains(body, "disabled") {
+		t.Errorf("status = %d body %q, want 400 for disabled GIF export", resp.StatusCode, body)
+	}
+}
+
+func TestGIFRateLimit(t *testing.T) {
+	app := newTestAppFrom(newGIFHandler(t, 2))
+
+	for i := 0; i < 2; i++ {
+		resp, body := doRequest(t, app, httptest.NewRequest("POST", "/ans?format=gif", strings.NewReader("hi")))
+		if resp.StatusCode != 200 {
+			t.Fatalf("request %d: status = %d, want 200 (body %q)", i+1, resp.StatusCode, body)
+		}
+	}
+	resp, _ := doRequest(t, app, httptest.NewRequest("POST", "/ans?format=gif", strings.NewReader("hi")))
+	if resp.StatusCode != 429 {
+		t.Errorf("status = %d, want 429 once over the limit", resp.StatusCode)
+	}
+
+	// Other formats aren't limited
+	resp, _ = doRequest(t, app, httptest.NewRequest("POST", "/ans", strings.NewReader("hi")))
+	if resp.StatusCode != 200 {
+		t.Errorf("status = %d, want 200 for text playback", resp.StatusCode)
+	}
+}
@@ -0,0 +1,451 @@
+// Package handlers implements the HTTP endpoints of the shout.sh service.
+package handlers
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/accesslog"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/bots"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/errreport"
+	"github.com/ryanlewis/shout-sh/fortune"
+	"github.com/ryanlewis/shout-sh/geoip"
+	"github.com/ryanlewis/shout-sh/ipaccess"
+	"github.com/ryanlewis/shout-sh/keypreset"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/quota"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/rendercache"
+	"github.com/ryanlewis/shout-sh/shortlink"
+	"github.com/ryanlewis/shout-sh/types"
+	"github.com/ryanlewis/shout-sh/vhost"
+)
+
+// HeaderFontSuggestion names the closest matching font when the requested
+// font doesn't exist.
+const HeaderFontSuggestion = "X-Shout-Font-Suggestion"
+
+// Deps bundles the shared services the handlers depend on.
+type Deps struct {
+	Config  *config.Config
+	Fonts   *render.FontCache
+	Metrics *types.Metrics
+	// Filter blocks words in request text; nil disables filtering.
+	Filter *render.WordFilter
+	// Shortlinks saves shouts for replay; nil disables the /s endpoints.
+	Shortlinks shortlink.Store
+	// Fortunes holds the /fortune quotes; nil uses the bundled quotes.
+	Fortunes *fortune.Database
+	// Keys identifies API clients and their tiers; nil disables API keys.
+	Keys apikey.Store
+	// KeyPresets keeps the presets API keys save; nil disables the
+	// /me/presets endpoints.
+	KeyPresets keypreset.Store
+	// Access allows and denies client IPs; nil serves everyone.
+	Access *ipaccess.Guard
+	// AccessLog logs every request; nil disables access logging.
+	AccessLog *accesslog.Logger
+	// GeoIP places clients in countries and applies the per-country
+	// policy; nil disables it.
+	GeoIP *geoip.Policy
+	// Errors reports panics and server errors; nil disables reporting.
+	Errors *errreport.Reporter
+	// VHosts gives host names their own defaults, branding and rate
+	// limits; nil serves every host alike.
+	VHosts *vhost.Hosts
+	// Cache keeps rendered static banners; nil renders every request.
+	Cache *rendercache.Cache
+}
+
+// Handler serves the public shout.sh endpoints.
+//
+// Usage example:
+//
+//	h := handlers.New(handlers.Deps{Config: cfg, Fonts: cache, Metrics: metrics})
+//	h.Register(app)
+type Handler struct {
+	// cfg is the current configuration, which ReloadConfig swaps out
+	cfg     atomic.Pointer[config.Config]
+	fonts   *render.FontCache
+	metrics *types.Metrics
+	// filter blocks words in request text, nil disables filtering;
+	// ReloadConfig swaps it out with the configuration
+	filter atomic.Pointer[render.WordFilter]
+	// shout draws the banners; the handlers adapt requests to it
+	shout *shout.Renderer
+	// cache keeps static banners by ETag; nil renders every request
+	cache *rendercache.Cache
+
+	shortlinks  shortlink.Store
+	createLimit fiber.Handler
+	gifLimit    fiber.Handler
+	relayLimit  fiber.Handler
+	asyncLimit  fiber.Handler
+	// routeLimits are the limiters of route classes with a rate limit of
+	// their own
+	routeLimits map[string]fiber.Handler
+	// async queues POST /render/async jobs; nil when they are disabled
+	async    *asyncQueue
+	streams  *types.ConnectionManager
+	fortunes *fortune.Database
+
+	accessLog *accesslog.Logger
+	errors    *errreport.Reporter
+	access    *ipaccess.Guard
+	geo       *geoip.Policy
+	geoLimit  fiber.Handler
+	// vhosts are the virtual hosts; nil when every host is served alike
+	vhosts    *vhost.Hosts
+	hostLimit fiber.Handler
+	// bots classifies scanners; nil when detection is off
+	bots *bots.Detector
+	// tarpit holds bots and limit offenders; nil when neither is tarpitted
+	tarpit *middleware.Tarpit
+	keys   apikey.Store
+	// keyPresets keeps presets saved by API keys; nil when keys can't
+	// save presets
+	keyPresets keypreset.Store
+	// anonymous is the tier of requests without an API key
+	anonymous *apikey.Tier
+	// exemptions skip rate limits and quotas; nil when there are none
+	exemptions *exemptions
+	keyLimit   fiber.Handler
+	// quotas tracks daily usage; nil when no quota is set
+	quotas     quota.Store
+	quotaLimit fiber.Handler
+	// warmup is the progress of the startup warmup /readyz reports
+	warmup atomic.Pointer[warmupState]
+}
+
+// defaultMaxStreams caps concurrent streams when no limit is configured.
+const defaultMaxStreams = 100
+
+// streamKeyIdle is how long the stream count of a client or IP without
+// open streams is kept.
+const streamKeyIdle = 5 * time.Minute
+
+// New creates a Handler from its dependencies.
+// A nil Metrics is replaced with a fresh instance so handlers never need
+// to nil-check it, and nil Fortunes with the bundled quotes.
+//
+// Parameters:
+//   - deps: the shared services used by the handlers
+//
+// Returns:
+//   - *Handler: a handler ready to be registered on a Fiber app
+func New(deps Deps) *Handler {
+	if deps.Metrics == nil {
+		deps.Metrics = &types.Metrics{}
+	}
+	if deps.Fortunes == nil {
+		deps.Fortunes = fortune.Bundled()
+	}
+	h := &Handler{
+		fonts:      deps.Fonts,
+		shout:      shout.New(deps.Fonts),
+		metrics:    deps.Metrics,
+		shortlinks: deps.Shortlinks,
+		fortunes:   deps.Fortunes,
+		keys:       deps.Keys,
+		keyPresets: deps.KeyPresets,
+		access:     deps.Access,
+		accessLog:  deps.AccessLog,
+		errors:     deps.Errors,
+		geo:        deps.GeoIP,
+		vhosts:     deps.VHosts,
+		cache:      deps.Cache,
+	}
+	h.cfg.Store(deps.Config)
+	h.filter.Store(deps.Filter)
+	cfg := deps.Config
+	h.warmup.Store(h.newWarmupState())
+	h.anonymous = h.anonymousTier()
+	h.exemptions = h.newExemptions()
+	h.keyLimit = h.unlessExempt(h.keyLimiter())
+	h.geoLimit = h.unlessExempt(h.geoLimiter())
+	h.hostLimit = h.unlessExempt(h.hostLimiter())
+	if cfg != nil {
+		h.bots = bots.New(cfg.Bots)
+		if cfg.Bots.Action == "tarpit" || cfg.Tarpit.Strikes > 0 {
+			h.tarpit = middleware.NewTarpit(cfg.Tarpit.Interval, cfg.Tarpit.MaxBytes, cfg.Tarpit.MaxClients)
+		}
+	}
+	if h.quotasEnabled() {
+		h.quotas = quota.NewMemoryStore()
+	}
+	h.quotaLimit = h.unlessExempt(h.quotaLimiter())
+	// Shared by the root and versioned routes so both count against one limit
+	h.createLimit = h.unlessExempt(h.shortlinkLimiter())
+	h.gifLimit = h.unlessExempt(h.gifLimiter())
+	h.relayLimit = h.unlessExempt(h.discordLimiter())
+	h.asyncLimit = h.unlessExempt(h.asyncLimiter())
+	h.routeLimits = h.routeLimiters()
+	h.async = h.startAsync()
+
+	maxStreams := int64(defaultMaxStreams)
+	if cfg != nil && cfg.Streaming.MaxStreams > 0 {
+		maxStreams = cfg.Streaming.MaxStreams
+	}
+	h.streams = types.NewKeyedConnectionManager(maxStreams, streamKeyIdle)
+	return h
+}
+
+// config returns the current configuration, or nil if none is set.
+// ReloadConfig may replace it while requests are in flight, so a request
+// that needs several settings to agree reads it once.
+func (h *Handler) config() *config.Config {
+	return h.cfg.Load()
+}
+
+// wordFilter returns the current word filter, or nil if filtering is off.
+func (h *Handler) wordFilter() *render.WordFilter {
+	return h.filter.Load()
+}
+
+// APIVersion is the current versioned route prefix. Breaking changes to
+// responses or option names land under a new prefix, while the unprefixed
+// routes keep serving the current version for existing curl one-liners.
+const APIVersion = "/v1"
+
+// Register mounts all public routes on the given app, both under
+// APIVersion and at the root. GET routes also answer HEAD, and OPTIONS
+// and CORS preflight requests are handled for every path. Requests are
+// access logged, when logging is on, recorded in the route metrics, and
+// panics and 5xx responses reported, when error reporting is on. Overlong URLs, IPs the access lists
+// refuse and countries the GeoIP policy refuses are turned away first,
+// then, in signed request mode, requests without a valid signature, and
+// requests classified as bots. With API keys, every route checks the key
+// and its tier's rate limit, and requests without one their country's
+// rate limit, if it has one. With virtual hosts, requests take the
+// defaults of their host, and without an API key its rate limit, if it
+// has one. With quotas, every route checks the client's daily quota. With
+// SHOUT_TARPIT_STRIKES, clients that keep getting 429s are tarpitted.
+// Clients exempted by SHOUT_RATELIMIT_EXEMPT_* skip rate limits and
+// quotas.
+//
+// Parameters:
+//   - app: the Fiber app serving the public port
+func (h *Handler) Register(app *fiber.App) {
+	cfg := h.config()
+	if h.accessLog != nil {
+		app.Use(middleware.AccessLog(h.accessLog))
+	}
+	app.Use(middleware.RouteMetrics(h.metrics))
+	if h.errors != nil {
+		app.Use(middleware.ReportErrors(h.errors))
+	}
+	if cfg != nil {
+		app.Use(middleware.MaxURL(cfg.Server))
+	}
+	if cfg != nil && cfg.Tarpit.Strikes > 0 {
+		app.Use(middleware.TarpitOffenders(h.tarpit, cfg.Tarpit.Strikes, cfg.Tarpit.Window))
+	}
+	app.Use(middleware.SecurityHeaders(h.securityConfig()))
+	if h.access != nil {
+		app.Use(middleware.IPAccess(h.access))
+	}
+	if h.geo != nil {
+		app.Use(middleware.GeoIP(h.geo, h.metrics))
+	}
+	if cfg != nil && len(cfg.Signing.Secrets) > 0 {
+		app.Use(middleware.Signed(cfg.Signing))
+	}
+	if cfg != nil {
+		app.Use(middleware.CORS(cfg.CORS))
+	}
+	if h.vhosts != nil {
+		app.Use(middleware.VHost(h.vhosts))
+	}
+	if h.keys != nil {
+		app.Use(middleware.APIKey(h.keys, cfg != nil && cfg.Auth.Required))
+	}
+	// After the key lookup, so keyed clients are exempt from the cadence
+	// limit, and before the rate limits, so bots don't use them up
+	if h.bots != nil {
+		var botTarpit *middleware.Tarpit
+		if cfg.Bots.Action == "tarpit" {
+			botTarpit = h.tarpit
+		}
+		app.Use(middleware.Bots(h.bots, botTarpit, h.metrics))
+	}
+	if h.keys != nil {
+		app.Use(h.keyLimit)
+	}
+	if h.geo != nil {
+		app.Use(h.geoLimit)
+	}
+	if h.vhosts != nil {
+		app.Use(h.hostLimit)
+	}
+	if h.quotas != nil {
+		app.Use(h.quotaLimit)
+	}
+	app.Options("/*", middleware.Route(h.metrics), middleware.Options)
+
+	// The versioned group goes first so the root catch-all doesn't
+	// render "v1/..." as text
+	h.routes(app.Group(APIVersion))
+	h.routes(app)
+}
+
+// routes mounts the public endpoints on r. The catch-all text route is
+// registered last so it doesn't shadow the fixed endpoints.
+func (h *Handler) routes(r fiber.Router) {
+	// Rate limits of the route classes (SHOUT_RATELIMIT_ROUTES)
+	renders, exports := h.routeLimit(config.RouteRender), h.routeLimit(config.RouteExport)
+	integrations := h.routeLimit(config.RouteIntegrations)
+	// Labels each route's requests in the route metrics
+	route := middleware.Route(h.metrics)
+
+	helpPolicy := middleware.CSP(firstOf(h.securityConfig().HelpCSP, helpCSP))
+	r.Get("/", route, helpPolicy, h.Help)
+	r.Get("/help", route, helpPolicy, h.Help)
+	r.Get("/colors", route, h.Colors)
+	r.Get("/presets", route, h.Presets)
+	r.Get("/openapi.json", route, h.OpenAPI)
+	r.Get("/shout.sh", route, h.Script)
+	r.Get("/date", route, renders, h.compress(), h.Date)
+	r.Get("/time", route, renders, h.compress(), h.Time)
+	r.Get("/fortune", route, renders, h.compress(), h.Fortune)
+	r.Get("/badge/:label/:value", route, renders, middleware.CSP(firstOf(h.securityConfig().BadgeCSP, badgeCSP)), h.compress(), h.Badge)
+	r.Get("/hr", route, renders, h.compress(), h.HR)
+	r.Post("/table", route, renders, h.compress(), h.Table)
+	r.Post("/compose", route, renders, h.compress(), h.Compose)
+	r.Post("/integrations/alertmanager", route, integrations, h.compress(), h.Alertmanager)
+	features := h.features()
+	switch {
+	case !features.EnableStreaming:
+		disabled := featureDisabled("streaming")
+		r.Get("/countdown", route, disabled)
+		r.Get("/countdown/:seconds", route, disabled)
+		r.Post("/ans", route, disabled)
+		r.Get("/ans/:name", route, disabled)
+	default:
+		r.Get("/countdown", route, exports, h.gifLimit, h.Countdown)
+		r.Get("/countdown/:seconds", route, exports, h.gifLimit, h.Countdown)
+		if features.EnableUploads {
+			r.Post("/ans", route, exports, h.gifLimit, h.PlayANSI)
+		} else {
+			r.Post("/ans", route, featureDisabled("uploads"))
+		}
+		if h.ansiGallery() != "" {
+			r.Get("/ans/:name", route, exports, h.gifLimit, h.GalleryANSI)
+		}
+	}
+	if !features.EnableShortlinks {
+		r.Post("/s", route, featureDisabled("shortlinks"))
+		r.Get("/s/:id", route, featureDisabled("shortlinks"))
+	} else if h.shortlinks != nil {
+		r.Post("/s", route, h.routeLimit(config.RouteShortlinks), h.createLimit, h.CreateShortlink)
+		r.Get("/s/:id", route, renders, h.compress(), h.Shortlink)
+	}
+	if h.keys != nil && h.keyPresets != nil {
+		r.Get("/me/presets", route, h.KeyPresets)
+		r.Put("/me/presets/:preset", route, h.SaveKeyPreset)
+		r.Delete("/me/presets/:preset", route, h.DeleteKeyPreset)
+	}
+	if h.discordRelay() {
+		r.Post("/integrations/discord", route, integrations, h.relayLimit, h.RelayDiscord)
+	}
+	if h.async != nil {
+		r.Post("/render/async", route, exports, h.asyncLimit, h.RenderAsync)
+	}
+	text := h.textChecks()
+	party := slices.Concat([]fiber.Handler{route, h.routeLimit(config.RouteParty), h.gifLimit}, text, []fiber.Handler{h.Party})
+	if !features.EnableStreaming {
+		party = []fiber.Handler{route, featureDisabled("streaming")}
+	}
+	r.Get("/p/*", party...)
+	r.Get("/party/*", party...)
+	r.Get("/validate/*", slices.Concat([]fiber.Handler{route, renders}, text, []fiber.Handler{h.Validate})...)
+	r.Get("/*", slices.Concat([]fiber.Handler{route, renders, h.compress()}, text, []fiber.Handler{h.Static})...)
+}
+
+// textChecks returns the middleware that validates the path text, expands
+// its placeholders and applies the word filter. The text settings are read
+// per request, so ReloadConfig changes them without re-registering routes.
+func (h *Handler) textChecks() []fiber.Handler {
+	return []fiber.Handler{
+		func(c *fiber.Ctx) error { return middleware.InputValidation(h.textConfig())(c) },
+		func(c *fiber.Ctx) error { return middleware.Placeholders(h.textConfig())(c) },
+		func(c *fiber.Ctx) error {
+			return middleware.WordFilter(h.wordFilter(), h.textConfig().Filter, h.metrics)(c)
+		},
+	}
+}
+
+// compress returns the compression middleware for static routes, or a
+// pass-through handler when static compression is disabled.
+func (h *Handler) compress() fiber.Handler {
+	cfg := h.config()
+	if cfg == nil || !cfg.Compression.Static {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return middleware.Compress(cfg.Compression)
+}
+
+// features returns the subsystems the operator left on
+// (SHOUT_FEATURES_*), all of them without a config.
+func (h *Handler) features() config.FeaturesConfig {
+	if cfg := h.config(); cfg != nil {
+		return cfg.Features
+	}
+	return config.FeaturesConfig{EnablePNG: true, EnableGIF: true, EnableStreaming: true, EnableUploads: true, EnableShortlinks: true}
+}
+
+// featureDisabled answers the routes of a feature that is switched off,
+// so they don't fall through to the text route.
+func featureDisabled(feature string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return apierrors.Send(c, apierrors.ErrFeatureDisabled.WithDetail("%s is turned off", feature))
+	}
+}
+
+// gifEnabled reports whether GIF exports are served: SHOUT_GIF_ENABLED
+// with SHOUT_FEATURES_ENABLE_GIF.
+func (h *Handler) gifEnabled() bool {
+	cfg := h.config()
+	return cfg != nil && cfg.GIF.Enabled && cfg.Features.EnableGIF
+}
+
+// discordRelay reports whether POST /integrations/discord is served
+// (SHOUT_DISCORD_RELAY).
+func (h *Handler) discordRelay() bool {
+	cfg := h.config()
+	return cfg != nil && cfg.Discord.Relay
+}
+
+// Built-in Content Security Policies of the HTML and SVG routes, which
+// SHOUT_SECURITY_*_CSP replace. The help page has an inline style sheet
+// and badges nothing at all; the API docs load Swagger UI from unpkg and
+// fetch the OpenAPI document from the admin port.
+const (
+	helpCSP  = "default-src 'none'; style-src 'unsafe-inline'"
+	badgeCSP = middleware.DefaultCSP
+	docsCSP  = "default-src 'none'; script-src https://unpkg.com 'unsafe-inline'; style-src https://unpkg.com 'unsafe-inline'; img-src 'self' data:; connect-src 'self'"
+)
+
+// securityConfig returns the security header settings, or zero values if
+// no config is set.
+func (h *Handler) securityConfig() config.SecurityConfig {
+	cfg := h.config()
+	if cfg == nil {
+		return config.SecurityConfig{}
+	}
+	return cfg.Security
+}
+
+// textConfig returns the text settings, or zero values if no config is set.
+func (h *Handler) textConfig() config.TextConfig {
+	cfg := h.config()
+	if cfg == nil {
+		return config.TextConfig{}
+	}
+	return cfg.Text
+}
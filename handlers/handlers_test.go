@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// newTestApp builds a public app backed by the repository's fonts.
+func newTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	return newTestAppFrom(newTestHandler(t))
+}
+
+// newTestAppFrom builds a public app serving the given handler.
+func newTestAppFrom(h *Handler) *fiber.App {
+	app := fiber.New()
+	h.Register(app)
+	return app
+}
+
+// newTestHandler builds a handler backed by the repository's fonts.
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	cfg := &config.Config{
+		Fonts: config.FontConfig{
+			Default: "standard",
+			Path:    "../fonts",
+			Allowed: []string{"standard", "doom", "small"},
+		},
+		Text: config.TextConfig{
+			MaxLength: 20,
+			Overflow:  "reject",
+		},
+		Features: config.FeaturesConfig{
+			EnablePNG:        true,
+			EnableGIF:        true,
+			EnableStreaming:  true,
+			EnableUploads:    true,
+			EnableShortlinks: true,
+		},
+	}
+
+	fonts := render.NewFontCache()
+	if err := fonts.LoadFonts(cfg.Fonts); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	return New(Deps{Config: cfg, Fonts: fonts})
+}
+
+// doRequest performs a request against the app and returns the response and body.
+func doRequest(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request %s %s failed: %v", req.Method, req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return resp, string(body)
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// endpoint describes a public route on the help page.
+type endpoint struct {
+	// Method is the HTTP method; empty means GET.
+	Method      string
+	Path        string
+	Description string
+	// Options is set when the route accepts the render query parameters.
+	Options bool
+	// Params are query parameters specific to the route.
+	Params []optionHelp
+	// Body is set when the route takes the text and options as a JSON body.
+	Body bool
+	// BodyParams are required JSON body fields besides the text.
+	BodyParams []optionHelp
+	// BodyOptions are optional JSON body fields besides the render options.
+	BodyOptions []optionHelp
+	// RawBody is the media type of a raw request body, such as a file upload.
+	RawBody string
+	// Types lists the response media types; the first is the default.
+	Types []string
+	// Shortlinks is set for routes that only exist when short links are enabled.
+	Shortlinks bool
+	// Gallery is set for routes that only exist when SHOUT_ANSI_PATH is set.
+	Gallery bool
+	// Discord is set for routes that only exist when SHOUT_DISCORD_RELAY is set.
+	Discord bool
+	// Async is set for routes that only exist when SHOUT_ASYNC_ENABLED is set.
+	Async bool
+	// KeyPresets is set for routes that only exist when API keys can save
+	// presets.
+	KeyPresets bool
+}
+
+// endpoints lists the public routes shown on the help page and in the
+// OpenAPI document.
+var endpoints = []endpoint{
+	{Path: "/{text}", Description: "Render text as an ASCII art banner", Options: true, Params: []optionHelp{colsParam, termParam, ncParam, seedParam, staticFormatParam, strictParam, notesParam, asciiParam, downloadParam, codingParam, nofooterParam}, Types: []string{textPlain, fiber.MIMEApplicationJSON, fiber.MIMETextHTML, svgMediaType}},
+	{Path: "/validate/{text}", Description: "Describe how /{text} would be rendered, as JSON, without the banner", Options: true, Params: []optionHelp{colsParam, termParam, ncParam, seedParam, staticFormatParam, strictParam, notesParam, asciiParam, nofooterParam}, Types: []string{fiber.MIMEApplicationJSON}},
+	{Path: "/colors", Description: "List color presets and named colors", Types: []string{textPlain, fiber.MIMEApplicationJSON}},
+	{Path: "/presets", Description: "List the option presets for ?preset=", Types: []string{textPlain, fiber.MIMEApplicationJSON}},
+	{Path: "/help", Description: "Show this help", Types: []string{textPlain, fiber.MIMETextHTML}},
+	{Path: "/openapi.json", Description: "OpenAPI description of this API", Types: []string{fiber.MIMEApplicationJSON}},
+	{Path: "/shout.sh", Description: "Shell function that sizes output to your terminal", Types: []string{textPlain}},
+	{Path: "/date", Description: "Render today's date", Options: true, Params: clockParams, Types: []string{textPlain}},
+	{Path: "/time", Description: "Render the current time", Options: true, Params: clockParams, Types: []string{textPlain}},
+	{Path: "/fortune", Description: "Render a random quote, for MOTD scripts", Options: true, Params: fortuneParams, Types: []string{textPlain}},
+	{Path: "/hr", Description: "Draw a horizontal rule across the terminal, to go between banners", Options: true, Params: hrParams, Types: []string{textPlain}},
+	{Path: "/badge/{label}/{value}", Description: "SVG badge with the value in ASCII art, for READMEs", Params: badgeParams, Types: []string{svgMediaType}},
+	{Path: "/p/{text}", Description: "Stream the text with animated colors", Options: true, Params: partyParams, Types: []string{textPlain}},
+	{Path: "/countdown/{seconds}", Description: "Stream a live countdown", Options: true, Params: countdownParams, Types: []string{textPlain}},
+	{Path: "/countdown", Description: "Stream a live countdown to ?until=", Options: true, Params: countdownParams, Types: []string{textPlain}},
+	{Method: fiber.MethodPost, Path: "/ans", Description: "Play back .ANS art sent as the body", Params: ansiParams, RawBody: fiber.MIMEOctetStream, Types: []string{textPlain}},
+	{Path: "/ans/{name}", Description: "Play back .ANS art from the gallery", Params: ansiParams, Types: []string{textPlain}, Gallery: true},
+	{Method: fiber.MethodPost, Path: "/s", Description: "Save a shout; the JSON body holds text and options", Body: true, Types: []string{textPlain, fiber.MIMEApplicationJSON}, Shortlinks: true},
+	{Path: "/s/{id}", Description: "Replay a saved shout", Types: []string{textPlain}, Shortlinks: true},
+	{Path: "/me/presets", Description: "List the presets your API key saved", Types: []string{fiber.MIMEApplicationJSON}, KeyPresets: true},
+	{Method: fiber.MethodPut, Path: "/me/presets/{preset}", Description: "Save the options in the JSON body as a preset of your API key, for ?preset=@name", RawBody: fiber.MIMEApplicationJSON, Types: []string{fiber.MIMEApplicationJSON}, KeyPresets: true},
+	{Method: fiber.MethodDelete, Path: "/me/presets/{preset}", Description: "Delete a preset your API key saved", KeyPresets: true},
+	{Method: fiber.MethodPost, Path: "/compose", Description: "Lay out the sections of the JSON body, banners, text, rules and blank lines, each with its own options, into one document", Options: true, Params: composeParams, RawBody: fiber.MIMEApplicationJSON, Types: []string{textPlain}},
+	{Method: fiber.MethodPost, Path: "/table", Description: "Draw the rows of the JSON body as a table in box-drawing lines, with an optional header row and title banner", Options: true, Params: tableParams, RawBody: fiber.MIMEApplicationJSON, Types: []string{textPlain}},
+	{Method: fiber.MethodPost, Path: "/integrations/alertmanager", Description: "Draw the alerts of a Prometheus Alertmanager webhook as banners, red while firing and green once resolved", Params: alertmanagerParams, RawBody: fiber.MIMEApplicationJSON, Types: []string{textPlain}},
+	{Method: fiber.MethodPost, Path: "/integrations/discord", Description: "Post a banner to a Discord webhook; the JSON body holds webhook_url, text and options", Body: true, BodyParams: []optionHelp{webhookParam}, Types: []string{textPlain, fiber.MIMEApplicationJSON}, Discord: true},
+	{Method: fiber.MethodPost, Path: "/render/async", Description: "Queue a render and POST the artifact to callback_url when done; the JSON body holds callback_url, text and options", Body: true, BodyParams: []optionHelp{callbackParam}, BodyOptions: asyncParams, Types: []string{textPlain, fiber.MIMEApplicationJSON}, Async: true},
+}
+
+// countdownParams are the query parameters of the countdown routes.
+var countdownParams = []optionHelp{
+	{Names: []string{"until"}, Help: "RFC 3339 time to count down to, instead of a number of seconds", Type: "string"},
+	{Names: []string{"msg"}, Help: "Message shown when the countdown ends", Type: "string"},
+	colsParam,
+	rowsParam,
+	termParam,
+	ncParam,
+	seedParam,
+	formatParam,
+	screenParam,
+}
+
+// publicEndpoints returns the endpoints this handler serves.
+func (h *Handler) publicEndpoints() []endpoint {
+	list := make([]endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if e.Shortlinks && h.shortlinks == nil || e.Gallery && h.ansiGallery() == "" || e.Discord && !h.discordRelay() || e.Async && h.async == nil || e.KeyPresets && (h.keys == nil || h.keyPresets == nil) {
+			continue
+		}
+		if e.Method == "" {
+			e.Method = fiber.MethodGet
+		}
+		list = append(list, e)
+	}
+	return list
+}
+
+// textPlain is the media type of plain text responses.
+const textPlain = "text/plain; charset=utf-8"
+
+// optionHelp documents one query parameter.
+type optionHelp struct {
+	Names []string
+	Help  string
+	// Type is the OpenAPI schema type: "string" or "integer".
+	Type string
+}
+
+// renderOptions documents the query parameters, read from the query and
+// help tags of types.RenderOptions so the help page can't drift from the
+// options the handlers accept.
+var renderOptions = queryOptions(reflect.TypeOf(types.RenderOptions{}))
+
+// queryOptions collects the documented query parameters of a struct type.
+func queryOptions(t reflect.Type) []optionHelp {
+	var options []optionHelp
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		query := field.Tag.Get("query")
+		if query == "" {
+			continue
+		}
+		typ := "string"
+		if field.Type.Kind() == reflect.Int {
+			typ = "integer"
+		}
+		options = append(options, optionHelp{
+			Names: strings.Split(query, ","),
+			Help:  field.Tag.Get("help"),
+			Type:  typ,
+		})
+	}
+	return options
+}
+
+// helpPage renders the help text as a minimal page for browsers.
+var helpPage = template.Must(template.New("help").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>shout.sh</title>
+<style>body{margin:2em;background:#111;color:#eee}pre{font:14px/1.3 monospace}</style>
+</head>
+<body><pre>{{.}}</pre></body>
+</html>
+`))
+
+// Help prints a usage guide listing the endpoints, query parameters,
+// loaded fonts and color presets. Browsers asking for HTML get the same
+// guide as a web page; everything else, curl included, gets plain text.
+//
+// Example:
+//
+//	GET /help
+func (h *Handler) Help(c *fiber.Ctx) error {
+	text := h.HelpText(c.Hostname())
+
+	if c.Accepts(fiber.MIMETextPlain, fiber.MIMETextHTML) == fiber.MIMETextHTML {
+		var b strings.Builder
+		if err := helpPage.Execute(&b, text); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(b.String())
+	}
+
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(text)
+}
+
+// HelpText builds the plain text usage guide served at /help, for other
+// listeners to serve too.
+//
+// Parameters:
+//   - host: the host the curl examples are addressed to
+//
+// Returns:
+//   - string: the usage guide
+//
+// Example:
+//
+//	text := h.HelpText("shout.sh")
+func (h *Handler) HelpText(host string) string {
+	cfg := h.config()
+	fonts := h.fonts.ListFonts()
+	presets := render.PresetNames()
+	defaultFont := h.defaultFont()
+	maxLength := 0
+	var placeholders []string
+	if cfg != nil {
+		maxLength = cfg.Text.MaxLength
+		placeholders = slices.DeleteFunc(slices.Clone(cfg.Text.Placeholders), func(name string) bool { return name == "none" })
+	}
+
+	var b strings.Builder
+	if layout, err := render.GenerateLayoutContext(context.Background(), "shout.sh", types.RenderOptions{Font: defaultFont}, h.fonts); err == nil {
+		b.WriteString(layout.String())
+		b.WriteString("\n")
+	}
+	b.WriteString("ASCII art banners for your terminal.\n")
+
+	b.WriteString("\nUsage:\n")
+	b.WriteString("  curl " + host + "/{text}[?options]\n")
+	b.WriteString("  Use + or %20 for spaces and %0A for new lines.\n")
+	if maxLength > 0 {
+		fmt.Fprintf(&b, "  Text may be up to %d characters long.\n", maxLength)
+	}
+	if len(placeholders) > 0 {
+		fmt.Fprintf(&b, "  Placeholders expanded in text: {%s}\n", strings.Join(placeholders, "}, {"))
+	}
+
+	b.WriteString("\nEndpoints:\n")
+	for _, e := range h.publicEndpoints() {
+		fmt.Fprintf(&b, "  %-4s %-20s %s\n", e.Method, e.Path, e.Description)
+	}
+	fmt.Fprintf(&b, "  Every endpoint is also served under %s, e.g. %s/{text}.\n", APIVersion, APIVersion)
+
+	b.WriteString("\nOptions:\n")
+	for _, o := range append(renderOptions, presetParam) {
+		fmt.Fprintf(&b, "  %-16s %s\n", strings.Join(o.Names, ", "), o.Help)
+	}
+	for _, flag := range figletFlags {
+		fmt.Fprintf(&b, "  %-16s %s\n", flag.Flag, flag.Help)
+	}
+
+	b.WriteString("\nFonts:\n")
+	for _, name := range fonts {
+		if name == defaultFont {
+			name += " (default)"
+		}
+		b.WriteString("  " + name + "\n")
+	}
+
+	b.WriteString("\nColors:\n")
+	b.WriteString("  Presets: " + strings.Join(presets, ", ") + "\n")
+	b.WriteString("  Custom:  #RRGGBB, #RGB, rgb(r,g,b) or a color name (see /colors)\n")
+
+	b.WriteString("\nExamples:\n")
+	b.WriteString("  curl " + host + "/HELLO\n")
+	for _, name := range fonts {
+		if name != defaultFont {
+			fmt.Fprintf(&b, "  curl '%s/HELLO+WORLD?f=%s'\n", host, name)
+			break
+		}
+	}
+	if len(presets) > 0 {
+		fmt.Fprintf(&b, "  curl '%s/HELLO?c=%s'\n", host, presets[0])
+	}
+	fmt.Fprintf(&b, "  curl '%s/HELLO+WORLD?c=red,green,blue&cmode=char'\n", host)
+	if h.shortlinks != nil {
+		fmt.Fprintf(&b, "  curl -d '{\"text\":\"HELLO\",\"color\":\"fire\"}' %s/s\n", host)
+	}
+	return b.String()
+}
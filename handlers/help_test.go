@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestHelp(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		contains    []string
+	}{
+		{
+			name:        "home page for curl",
+			path:        "/",
+			accept:      "*/*",
+			contentType: "text/plain; charset=utf-8",
+			contains:    []string{"Usage:", "f, font", "cmode", "standard (default)", "doom", "rainbow", "up to 20 characters"},
+		},
+		{
+			name:        "help alias",
+			path:        "/help",
+			contentType: "text/plain; charset=utf-8",
+			contains:    []string{"Usage:", "/colors"},
+		},
+		{
+			name:        "browser",
+			path:        "/help",
+			accept:      "text/html,application/xhtml+xml,*/*;q=0.8",
+			contentType: "text/html; charset=utf-8",
+			contains:    []string{"<!DOCTYPE html>", "<pre>", "&#39;"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderOptionsDocumented(t *testing.T) {
+	typ := reflect.TypeOf(types.RenderOptions{})
+	if len(renderOptions) != typ.NumField() {
+		t.Errorf("help lists %d options, RenderOptions has %d fields", len(renderOptions), typ.NumField())
+	}
+	for _, o := range renderOptions {
+		if o.Help == "" {
+			t.Errorf("option %v has no help text", o.Names)
+		}
+	}
+}
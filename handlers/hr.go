@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Limits and defaults of rules.
+const (
+	// maxRuleWidth caps the width in columns of the characters a rule
+	// repeats
+	maxRuleWidth = 8
+	// defaultRuleWidth is the width of rules when neither maxwidth nor
+	// the terminal's width is known
+	defaultRuleWidth = 80
+	// ruleStyleGradient draws blocks in the color's gradient, or in
+	// defaultRuleGradient when the color isn't one
+	ruleStyleGradient   = "gradient"
+	defaultRuleGradient = "gradient:00bfff-ff00ff"
+)
+
+// hrParams are the query parameters of the rule route.
+var hrParams = []optionHelp{
+	{Names: []string{"char"}, Help: "Characters to repeat, up to 8 columns wide, such as = or -=; overrides style", Type: "string"},
+	{Names: []string{"style"}, Help: "Named rule: single or rounded (─), double (═), heavy (━), dashed (┄), ascii (-), equals (=), block (█), or gradient, blocks in color's gradient or else a blue to magenta one; default the border option's, or single", Type: "string"},
+	colsParam,
+	termParam,
+	ncParam,
+	asciiParam,
+}
+
+// HR draws a horizontal rule across the terminal: ?char= repeated, or
+// the character of ?style=, or of the border option, a theme's included.
+// It is as wide as maxwidth, or cols, and aligned within cols, and
+// colored by color as banners are; ?ascii=strict draws it uncolored in
+// ASCII.
+//
+// Example:
+//
+//	GET /hr?style=gradient&cols=80
+func (h *Handler) HR(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if err := h.validateOptions(opts); err != nil {
+		return apierrors.Send(c, err)
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if term, err = withASCII(c, term); err != nil {
+		return apierrors.Send(c, err)
+	}
+	output, err := h.drawRule(opts, c.Query("char"), c.Query("style"), term)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(output)
+}
+
+// drawRule draws a rule of char, or of the character of style, or else
+// of opts.Border, as wide as opts.MaxWidth or the terminal, aligned within
+// the terminal and colored by opts. char is measured in columns, as the
+// renderer measures text, so wide characters repeat half as often. For
+// ASCII terminals the rule is drawn with -, = and #.
+func (h *Handler) drawRule(opts types.RenderOptions, char, style string, term terminal) (string, error) {
+	if render.StringWidth(char) > maxRuleWidth || render.SanitizeText(char) != char {
+		return "", apierrors.ErrBadOption.WithDetail("char must be up to %d columns wide, without control characters or surrounding spaces, got %q", maxRuleWidth, char)
+	}
+	if char == "" {
+		if style == "" && opts.Border != "none" {
+			style = opts.Border
+		}
+		if style != "" {
+			var ok bool
+			if char, ok = render.RuleStyles[style]; !ok {
+				return "", apierrors.ErrBadOption.WithDetail("unknown rule style %q", style).
+					WithHint("use one of " + strings.Join(render.RuleStyleNames(), ", "))
+			}
+		}
+	}
+	if style == ruleStyleGradient && !strings.HasPrefix(opts.Color, "gradient:") {
+		opts.Color = defaultRuleGradient
+	}
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return "", apierrors.ErrBadOption.WithDetail("%v", err)
+	}
+
+	rule := render.Rule(char, cmp.Or(opts.MaxWidth, term.cols, defaultRuleWidth))
+	if term.ascii {
+		rule = render.ASCIIRule(rule)
+	}
+	layout := render.AlignLayout(render.TextLayout(rule), term.cols, h.alignment(opts))
+	return render.ReduceColors(render.ColorizeLayout(layout, scheme, 0), term.colors), nil
+}
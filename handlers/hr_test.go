@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestHR(t *testing.T) {
+	app := newTestAppFrom(newThemesHandler(t))
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		want       string
+		contains   []string
+	}{
+		{name: "default", target: "/hr?cols=5&term=dumb", wantStatus: 200, want: "─────\n"},
+		{name: "unknown width", target: "/hr?term=dumb", wantStatus: 200, want: strings.Repeat("─", 80) + "\n"},
+		{name: "char", target: "/hr?cols=5&char=-%3D&term=dumb", wantStatus: 200, want: "-=-=-\n"},
+		{name: "wide char", target: "/hr?cols=5&char=%E6%97%A5&term=dumb", wantStatus: 200, want: "日日\n"},
+		{name: "emoji", target: "/hr?cols=6&char=%F0%9F%94%A5&term=dumb", wantStatus: 200, want: "🔥🔥🔥\n"},
+		{name: "style", target: "/hr?cols=3&style=double&term=dumb", wantStatus: 200, want: "═══\n"},
+		{name: "border option", target: "/hr?cols=3&b=double&term=dumb", wantStatus: 200, want: "═══\n"},
+		{name: "centered within cols", target: "/hr?cols=8&mw=4&a=center&char=%3D&term=dumb", wantStatus: 200, want: "  ====\n"},
+		{
+			name:       "gradient",
+			target:     "/hr?cols=10&style=gradient",
+			wantStatus: 200,
+			contains:   []string{render.Color{R: 0, G: 191, B: 255}.Foreground() + "█", render.Color{R: 255, G: 0, B: 255}.Foreground() + "█"},
+		},
+		{
+			name:       "gradient of the color",
+			target:     "/hr?cols=10&style=gradient&c=gradient:f00-00f",
+			wantStatus: 200,
+			contains:   []string{render.Color{R: 255}.Foreground() + "█", render.Color{B: 255}.Foreground() + "█"},
+		},
+		{
+			name:       "theme",
+			target:     "/hr?cols=4&theme=ocean",
+			wantStatus: 200,
+			contains:   []string{render.Color{B: 255}.Foreground() + "─"},
+		},
+		{name: "ascii", target: "/hr?cols=4&ascii=strict", wantStatus: 200, want: "----\n"},
+		{name: "ascii double", target: "/hr?cols=4&style=double&ascii=strict", wantStatus: 200, want: "====\n"},
+		{name: "ascii gradient", target: "/hr?cols=4&style=gradient&ascii=strict", wantStatus: 200, want: "####\n"},
+		{name: "ascii theme", target: "/hr?cols=4&theme=ocean&ascii=strict", wantStatus: 200, want: "----\n"},
+		{name: "bad ascii", target: "/hr?ascii=loose", wantStatus: 400},
+		{name: "unknown style", target: "/hr?style=wavy", wantStatus: 400, contains: []string{`unknown rule style "wavy"`, "use one of ascii, block"}},
+		{name: "char too long", target: "/hr?char=123456789", wantStatus: 400},
+		{name: "wide char too long", target: "/hr?char=%E6%97%A5%E6%97%A5%E6%97%A5%E6%97%A5%E6%97%A5", wantStatus: 400, contains: []string{"up to 8 columns wide"}},
+		{name: "bad color", target: "/hr?c=nope", wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.want != "" && body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q: %q", want, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/keypreset"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// savedPresetPrefix marks a ?preset= name as one saved by the request's
+// API key, as in ?preset=@brand.
+const savedPresetPrefix = "@"
+
+// defaultMaxKeyPresets caps the presets one key may save without a
+// config.
+const defaultMaxKeyPresets = 50
+
+// presetOwner returns the API key of the request, whose presets the
+// /me/presets routes manage, or ErrKeyRequired without one.
+func presetOwner(c *fiber.Ctx) (*apikey.Key, error) {
+	key := middleware.Key(c)
+	if key == nil {
+		return nil, apierrors.ErrKeyRequired.WithHint("send your API key in the X-API-Key header")
+	}
+	return key, nil
+}
+
+// maxKeyPresets returns SHOUT_KEY_PRESETS_MAX_PER_KEY.
+func (h *Handler) maxKeyPresets() int {
+	if cfg := h.config(); cfg != nil && cfg.KeyPresets.MaxPerKey > 0 {
+		return cfg.KeyPresets.MaxPerKey
+	}
+	return defaultMaxKeyPresets
+}
+
+// savedPreset returns the options of the preset the request's API key
+// saved as name, for ?preset=@name.
+func (h *Handler) savedPreset(c *fiber.Ctx, name string) (types.RenderOptions, error) {
+	if h.keyPresets == nil {
+		return types.RenderOptions{}, apierrors.ErrBadOption.WithDetail("unknown preset %q", savedPresetPrefix+name).
+			WithHint("this server doesn't save presets")
+	}
+	key, err := presetOwner(c)
+	if err != nil {
+		return types.RenderOptions{}, err
+	}
+	opts, err := h.keyPresets.Load(c.UserContext(), key.Name, name)
+	if errors.Is(err, keypreset.ErrNotFound) {
+		return types.RenderOptions{}, apierrors.ErrPresetNotFound.WithDetail("%q", name).
+			WithHint("save it with PUT /me/presets/" + name)
+	}
+	if err != nil {
+		log.Printf("Error loading preset: %v", err)
+		return types.RenderOptions{}, err
+	}
+	return opts, nil
+}
+
+// KeyPresets lists the presets the request's API key has saved, by name.
+//
+// Example:
+//
+//	curl -H "X-API-Key: $KEY" localhost:8080/me/presets
+func (h *Handler) KeyPresets(c *fiber.Ctx) error {
+	key, err := presetOwner(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	presets, err := h.keyPresets.List(c.UserContext(), key.Name)
+	if err != nil {
+		log.Printf("Error listing presets: %v", err)
+		return apierrors.Send(c, err)
+	}
+	return c.JSON(fiber.Map{"presets": presets})
+}
+
+// SaveKeyPreset saves the options in the JSON body as a preset of the
+// request's API key, for ?preset=@name. Saving over a preset replaces it.
+//
+// Example:
+//
+//	curl -X PUT -H "X-API-Key: $KEY" -d '{"font":"doom","color":"fire"}' localhost:8080/me/presets/brand
+func (h *Handler) SaveKeyPreset(c *fiber.Ctx) error {
+	key, err := presetOwner(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	name := c.Params("preset")
+	if !keypreset.ValidName(name) {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid preset name %q", name).
+			WithHint("use up to 64 lowercase letters, digits and dashes"))
+	}
+	if len(c.Body()) > maxSpecBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxSpecBytes))
+	}
+
+	// Unknown fields are refused, so a misspelled option isn't saved as
+	// a preset that silently ignores it
+	var opts types.RenderOptions
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&opts); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+	if err := h.validateOptions(opts); err != nil {
+		return apierrors.Send(c, err.WithHint(h.suggestFont(c, opts.Font)))
+	}
+
+	ctx := c.UserContext()
+	presets, err := h.keyPresets.List(ctx, key.Name)
+	if err != nil {
+		log.Printf("Error listing presets: %v", err)
+		return apierrors.Send(c, err)
+	}
+	_, replaced := presets[name]
+	if limit := h.maxKeyPresets(); !replaced && len(presets) >= limit {
+		return apierrors.Send(c, apierrors.ErrPresetLimit.WithDetail("max %d", limit).
+			WithHint("delete one with DELETE /me/presets/{name}"))
+	}
+	if err := h.keyPresets.Save(ctx, key.Name, name, opts); err != nil {
+		log.Printf("Error saving preset: %v", err)
+		return apierrors.Send(c, err)
+	}
+
+	if !replaced {
+		c.Status(fiber.StatusCreated)
+	}
+	return c.JSON(fiber.Map{"name": name, "options": opts})
+}
+
+// DeleteKeyPreset forgets a preset the request's API key saved.
+//
+// Example:
+//
+//	curl -X DELETE -H "X-API-Key: $KEY" localhost:8080/me/presets/brand
+func (h *Handler) DeleteKeyPreset(c *fiber.Ctx) error {
+	key, err := presetOwner(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	name := c.Params("preset")
+	err = h.keyPresets.Delete(c.UserContext(), key.Name, name)
+	if errors.Is(err, keypreset.ErrNotFound) {
+		return apierrors.Send(c, apierrors.ErrPresetNotFound.WithDetail("%q", name))
+	}
+	if err != nil {
+		log.Printf("Error deleting preset: %v", err)
+		return apierrors.Send(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// isSavedPreset reports whether a ?preset= value names a preset saved by
+// the request's API key rather than one of SHOUT_TEXT_PRESETS.
+func isSavedPreset(name string) bool {
+	return strings.HasPrefix(name, savedPresetPrefix)
+}
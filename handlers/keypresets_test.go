@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/keypreset"
+)
+
+// newKeyPresetsHandler builds the handler of newKeysHandler with an
+// in-memory preset store, letting each key save maxPerKey presets.
+func newKeyPresetsHandler(t *testing.T, maxPerKey int) *Handler {
+	t.Helper()
+	base := newKeysHandler(t)
+	cfg := base.config()
+	cfg.KeyPresets.MaxPerKey = maxPerKey
+	return New(Deps{Config: cfg, Fonts: base.fonts, Keys: base.keys, KeyPresets: keypreset.NewMemoryStore()})
+}
+
+// presetRequest builds a request with body, sent with the "acme" key
+// unless key is empty.
+func presetRequest(method, target, body, key string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	return req
+}
+
+func TestKeyPresets(t *testing.T) {
+	app := newTestAppFrom(newKeyPresetsHandler(t, 10))
+	brand := `{"font":"small","align":"left"}`
+
+	if resp, body := doRequest(t, app, presetRequest("PUT", "/me/presets/brand", brand, "s3cret")); resp.StatusCode != 201 {
+		t.Fatalf("PUT status = %d, want 201 (body %q)", resp.StatusCode, body)
+	}
+	if resp, body := doRequest(t, app, presetRequest("PUT", "/me/presets/brand", brand, "s3cret")); resp.StatusCode != 200 {
+		t.Fatalf("second PUT status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+
+	resp, body := doRequest(t, app, presetRequest("GET", "/me/presets", "", "s3cret"))
+	var list struct {
+		Presets map[string]struct {
+			Font string `json:"font"`
+		} `json:"presets"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil || resp.StatusCode != 200 {
+		t.Fatalf("GET /me/presets = %d %q", resp.StatusCode, body)
+	}
+	if len(list.Presets) != 1 || list.Presets["brand"].Font != "small" {
+		t.Errorf("presets = %+v, want brand in font small", list.Presets)
+	}
+
+	_, want := doRequest(t, app, presetRequest("GET", "/HI?f=small&a=left", "", "s3cret"))
+	if resp, got := doRequest(t, app, presetRequest("GET", "/HI?preset=@brand", "", "s3cret")); resp.StatusCode != 200 || got != want {
+		t.Errorf("GET /HI?preset=@brand = %d %q, want the banner of f=small&a=left", resp.StatusCode, got)
+	}
+
+	if resp, body := doRequest(t, app, presetRequest("DELETE", "/me/presets/brand", "", "s3cret")); resp.StatusCode != 204 {
+		t.Fatalf("DELETE status = %d, want 204 (body %q)", resp.StatusCode, body)
+	}
+	for _, req := range []*http.Request{
+		presetRequest("DELETE", "/me/presets/brand", "", "s3cret"),
+		presetRequest("GET", "/HI?preset=@brand", "", "s3cret"),
+	} {
+		resp, _ := doRequest(t, app, req)
+		if resp.StatusCode != 404 || resp.Header.Get(apierrors.HeaderErrorCode) != "preset_not_found" {
+			t.Errorf("%s %s = %d %q, want 404 preset_not_found", req.Method, req.URL, resp.StatusCode, resp.Header.Get(apierrors.HeaderErrorCode))
+		}
+	}
+}
+
+func TestKeyPresetsRefused(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "save without a key", req: presetRequest("PUT", "/me/presets/brand", `{"font":"small"}`, ""), wantStatus: 401, wantCode: "key_required"},
+		{name: "list without a key", req: presetRequest("GET", "/me/presets", "", ""), wantStatus: 401, wantCode: "key_required"},
+		{name: "use without a key", req: presetRequest("GET", "/HI?preset=@brand", "", ""), wantStatus: 401, wantCode: "key_required"},
+		{name: "invalid name", req: presetRequest("PUT", "/me/presets/Brand", `{"font":"small"}`, "s3cret"), wantStatus: 400, wantCode: "bad_option"},
+		{name: "invalid JSON", req: presetRequest("PUT", "/me/presets/brand", `{"font":`, "s3cret"), wantStatus: 400, wantCode: "bad_option"},
+		{name: "unknown option", req: presetRequest("PUT", "/me/presets/brand", `{"volume":11}`, "s3cret"), wantStatus: 400, wantCode: "bad_option"},
+		{name: "invalid option", req: presetRequest("PUT", "/me/presets/brand", `{"align":"diagonal"}`, "s3cret"), wantStatus: 400, wantCode: "bad_option"},
+		{name: "over the limit", req: presetRequest("PUT", "/me/presets/other", `{"font":"small"}`, "s3cret"), wantStatus: 409, wantCode: "preset_limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestAppFrom(newKeyPresetsHandler(t, 1))
+			if resp, body := doRequest(t, app, presetRequest("PUT", "/me/presets/brand", `{"font":"small"}`, "s3cret")); resp.StatusCode != 201 {
+				t.Fatalf("PUT status = %d, want 201 (body %q)", resp.StatusCode, body)
+			}
+
+			resp, body := doRequest(t, app, tt.req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(apierrors.HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("%s = %q, want %q", apierrors.HeaderErrorCode, got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// anonymousTier returns the tier of requests without a key when the keys
+// file has none: the server's SHOUT_RATELIMIT_REQUESTS_PER_MINUTE and
+// SHOUT_QUOTA_* limits, and no features.
+func (h *Handler) anonymousTier() *apikey.Tier {
+	cfg := h.config()
+	if h.keys != nil {
+		if tier := h.keys.Anonymous(); tier != nil {
+			return tier
+		}
+	}
+	tier := &apikey.Tier{Name: apikey.AnonymousTier}
+	if cfg != nil {
+		tier.RequestsPerMinute = cfg.RateLimit.RequestsPerMinute
+		tier.RequestsPerDay = cfg.Quota.RequestsPerDay
+		tier.StreamSecondsPerDay = cfg.Quota.StreamSecondsPerDay
+	}
+	return tier
+}
+
+// tier returns the tier of the request's API key, or the anonymous tier.
+func (h *Handler) tier(c *fiber.Ctx) *apikey.Tier {
+	if key := middleware.Key(c); key != nil {
+		return key.Tier
+	}
+	return h.anonymous
+}
+
+// streamLimits returns the keys a stream counts against besides the
+// overall SHOUT_STREAMING_MAX_STREAMS: its client, capped by the tier's
+// MaxStreams, and its IP, capped by SHOUT_STREAMING_MAX_STREAMS_PER_IP.
+// Without an API key the client is the IP, which is then capped by the
+// lower of the two.
+func (h *Handler) streamLimits(c *fiber.Ctx, tier *apikey.Tier) []types.KeyLimit {
+	var perIP int64
+	if cfg := h.config(); cfg != nil {
+		perIP = cfg.Streaming.MaxStreamsPerIP
+	}
+	ip := types.KeyLimit{Key: "ip:" + c.IP(), Max: perIP}
+	client := types.KeyLimit{Key: clientID(c), Max: tier.MaxStreams}
+	if client.Key != ip.Key {
+		return []types.KeyLimit{client, ip}
+	}
+	if ip.Max == 0 || (client.Max > 0 && client.Max < ip.Max) {
+		ip.Max = client.Max
+	}
+	return []types.KeyLimit{ip}
+}
+
+// streamKeys returns the keys of limits, to release them with.
+func streamKeys(limits []types.KeyLimit) []string {
+	keys := make([]string, len(limits))
+	for i, limit := range limits {
+		keys[i] = limit.Key
+	}
+	return keys
+}
+
+// streamCapacityError explains which limit refused a stream: the server's,
+// the tier's or the IP's.
+func streamCapacityError(full types.KeyLimit, tier *apikey.Tier) error {
+	switch {
+	case full.Key == "":
+		return apierrors.ErrStreamCapacity
+	case full.Max == tier.MaxStreams:
+		return apierrors.ErrStreamCapacity.WithDetail("your plan allows %d streams at once", full.Max)
+	default:
+		return apierrors.ErrStreamCapacity.WithDetail("each IP may hold %d streams at once", full.Max)
+	}
+}
+
+// clientID identifies who a request counts against: its API key, or its
+// IP without one.
+func clientID(c *fiber.Ctx) string {
+	if key := middleware.Key(c); key != nil {
+		return "key:" + key.Name
+	}
+	return "ip:" + c.IP()
+}
+
+// entitled returns ErrNotEntitled unless the request's tier includes
+// feature. Every request is entitled when API keys are disabled.
+func (h *Handler) entitled(c *fiber.Ctx, feature string) error {
+	if h.keys == nil || h.tier(c).Allows(feature) {
+		return nil
+	}
+	err := apierrors.ErrNotEntitled.WithDetail("%s needs a plan that includes it", feature)
+	if middleware.Key(c) == nil {
+		return err.WithHint("send an API key in the X-API-Key header")
+	}
+	return err
+}
+
+// keyLimiter limits each client to its tier's requests per minute, with a
+// limiter per tier as they differ in their limits. It passes everything
+// through when API keys are disabled.
+func (h *Handler) keyLimiter() fiber.Handler {
+	if h.keys == nil {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	var mu sync.Mutex
+	limiters := make(map[string]fiber.Handler)
+	return func(c *fiber.Ctx) error {
+		tier := h.tier(c)
+		if tier.RequestsPerMinute == 0 || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		mu.Lock()
+		limit, ok := limiters[tier.Name]
+		if !ok {
+			limit = limiter.New(limiter.Config{
+				Max:          tier.RequestsPerMinute,
+				Expiration:   time.Minute,
+				KeyGenerator: clientID,
+				LimitReached: func(c *fiber.Ctx) error {
+					return apierrors.Send(c, apierrors.ErrRateLimited)
+				},
+			})
+			limiters[tier.Name] = limit
+		}
+		mu.Unlock()
+		return limit(c)
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/types"
+	"github.com/valyala/fasthttp"
+)
+
+// newKeysHandler builds a GIF-enabled handler whose keys file lets
+// anonymous clients make two requests a minute and the "pro" key s3cret
+// export GIFs.
+func newKeysHandler(t *testing.T) *Handler {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `{
+		"tiers": {
+			"anonymous": {"requests_per_minute": 2},
+			"pro": {"max_streams": 1, "features": ["gif"]}
+		},
+		"keys": [{"name": "acme", "sha256": "` + apikey.Hash("s3cret") + `", "tier": "pro"}]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := apikey.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newGIFHandler(t, 0)
+	return New(Deps{Config: h.config(), Fonts: h.fonts, Keys: keys})
+}
+
+func TestAPIKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		key        string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "anonymous text", target: "/HI", wantStatus: 200},
+		{name: "anonymous GIF", target: "/countdown/1?f=small&format=gif", wantStatus: 403, wantCode: "not_entitled"},
+		{name: "keyed GIF", target: "/countdown/1?f=small&format=gif", key: "s3cret", wantStatus: 200},
+		{name: "key in query", target: "/countdown/1?f=small&format=gif&key=s3cret", wantStatus: 200},
+		{name: "unknown key", target: "/HI", key: "guess", wantStatus: 401, wantCode: "invalid_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestAppFrom(newKeysHandler(t))
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(apierrors.HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("%s = %q, want %q", apierrors.HeaderErrorCode, got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAPIKeysRateLimit(t *testing.T) {
+	app := newTestAppFrom(newKeysHandler(t))
+
+	for i := range 2 {
+		if resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != 200 {
+			t.Fatalf("request %d status = %d (body %q)", i+1, resp.StatusCode, body)
+		}
+	}
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != 429 {
+		t.Errorf("third anonymous request status = %d, want 429", resp.StatusCode)
+	}
+
+	// The pro tier has no request limit
+	for i := range 5 {
+		req := httptest.NewRequest("GET", "/HI", nil)
+		req.Header.Set("X-API-Key", "s3cret")
+		if resp, body := doRequest(t, app, req); resp.StatusCode != 200 {
+			t.Fatalf("keyed request %d status = %d (body %q)", i+1, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestStreamLimits(t *testing.T) {
+	pro := &apikey.Tier{Name: "pro", MaxStreams: 5}
+	tests := []struct {
+		name     string
+		perIP    int64
+		key      *apikey.Key
+		anonMax  int64
+		want     []types.KeyLimit
+		wantFull string
+	}{
+		{
+			name: "anonymous, unlimited",
+			want: []types.KeyLimit{{Key: "ip:0.0.0.0"}},
+		},
+		{
+			name:    "anonymous, lower tier limit",
+			perIP:   3,
+			anonMax: 2,
+			want:    []types.KeyLimit{{Key: "ip:0.0.0.0", Max: 2}},
+		},
+		{
+			name:    "anonymous, lower IP limit",
+			perIP:   1,
+			anonMax: 2,
+			want:    []types.KeyLimit{{Key: "ip:0.0.0.0", Max: 1}},
+		},
+		{
+			name:  "keyed",
+			perIP: 3,
+			key:   &apikey.Key{Name: "acme", Tier: pro},
+			want:  []types.KeyLimit{{Key: "key:acme", Max: 5}, {Key: "ip:0.0.0.0", Max: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			h.config().Streaming.MaxStreamsPerIP = tt.perIP
+			app := fiber.New()
+			c := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(c)
+			tier := &apikey.Tier{MaxStreams: tt.anonMax}
+			if tt.key != nil {
+				c.Locals(middleware.KeyKey, tt.key)
+				tier = tt.key.Tier
+			}
+			if got := h.streamLimits(c, tier); !slices.Equal(got, tt.want) {
+				t.Errorf("streamLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamCapacityError(t *testing.T) {
+	tier := &apikey.Tier{MaxStreams: 2}
+	tests := []struct {
+		name       string
+		full       types.KeyLimit
+		wantDetail string
+	}{
+		{name: "server", full: types.KeyLimit{Max: 100}},
+		{name: "plan", full: types.KeyLimit{Key: "key:acme", Max: 2}, wantDetail: "your plan allows 2 streams at once"},
+		{name: "IP", full: types.KeyLimit{Key: "ip:1.2.3.4", Max: 3}, wantDetail: "each IP may hold 3 streams at once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err *apierrors.Error
+			if !errors.As(streamCapacityError(tt.full, tier), &err) || err.Code != "stream_capacity" {
+				t.Fatalf("streamCapacityError() = %v, want stream_capacity", err)
+			}
+			if err.Detail != tt.wantDetail {
+				t.Errorf("detail = %q, want %q", err.Detail, tt.wantDetail)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderDegraded is set on streams started while the server is under load,
+// which run at a reduced frame rate without compression.
+const HeaderDegraded = "X-Shout-Degraded"
+
+// degradedKey caches the degradation decision for a request.
+const degradedKey = "shout.degraded"
+
+// cpuPercent reports recent CPU use of the process as a percentage of all
+// cores; tests replace it.
+var cpuPercent = (&cpuSampler{}).percent
+
+// cpuSampleEvery is how often cpuSampler measures CPU use.
+const cpuSampleEvery = time.Second
+
+// cpuSampler measures the CPU time the process used between samples.
+type cpuSampler struct {
+	mu    sync.Mutex
+	at    time.Time
+	cpu   time.Duration
+	usage float64
+}
+
+// percent returns the CPU use measured over the last sample period, taking
+// a new sample if the last is stale. It returns 0 where CPU time isn't
+// available.
+func (s *cpuSampler) percent() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.at) < cpuSampleEvery {
+		return s.usage
+	}
+	cpu := processCPUTime()
+	if !s.at.IsZero() && cpu > 0 {
+		wall := now.Sub(s.at) * time.Duration(runtime.NumCPU())
+		s.usage = 100 * float64(cpu-s.cpu) / float64(wall)
+	}
+	s.at, s.cpu = now, cpu
+	return s.usage
+}
+
+// degraded reports whether a new stream should run degraded: when the
+// active streams reach SHOUT_STREAMING_DEGRADE_STREAMS percent of
+// SHOUT_STREAMING_MAX_STREAMS, or CPU use reaches
+// SHOUT_STREAMING_DEGRADE_CPU percent. The decision is made once per
+// request and announced in the X-Shout-Degraded header.
+func (h *Handler) degraded(c *fiber.Ctx) bool {
+	if d, ok := c.Locals(degradedKey).(bool); ok {
+		return d
+	}
+	d := h.underLoad()
+	c.Locals(degradedKey, d)
+	if d {
+		h.metrics.IncDegradedStream()
+		c.Set(HeaderDegraded, "1")
+	}
+	return d
+}
+
+// underLoad checks the degradation thresholds; a threshold of 0 is off.
+func (h *Handler) underLoad() bool {
+	cfg := h.config()
+	if cfg == nil {
+		return false
+	}
+	s := cfg.Streaming
+	if s.DegradeStreams > 0 && s.MaxStreams > 0 && h.streams.GetActiveCount()*100 >= int64(s.DegradeStreams)*s.MaxStreams {
+		return true
+	}
+	return s.DegradeCPU > 0 && cpuPercent() >= float64(s.DegradeCPU)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// loadHandler returns a party test handler with two stream slots that
+// degrades from one active stream or 90% CPU, to 4 frames a second.
+func loadHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newPartyHandler(t)
+	h.config().Streaming.MaxStreams = 2
+	h.config().Streaming.DegradeStreams = 50
+	h.config().Streaming.DegradeCPU = 90
+	h.config().Streaming.DegradedFPS = 4
+	h.config().Compression.Stream = true
+	return New(Deps{Config: h.config(), Fonts: h.fonts})
+}
+
+// fakeCPU reports a fixed CPU use for the duration of a test.
+func fakeCPU(t *testing.T, percent float64) {
+	t.Helper()
+	orig := cpuPercent
+	cpuPercent = func() float64 { return percent }
+	t.Cleanup(func() { cpuPercent = orig })
+}
+
+func TestDegradedStreams(t *testing.T) {
+	tests := []struct {
+		name         string
+		busyStreams  int
+		cpu          float64
+		wantDegraded bool
+	}{
+		{name: "idle", cpu: 10},
+		{name: "streams near capacity", busyStreams: 1, cpu: 10, wantDegraded: true},
+		{name: "CPU saturated", cpu: 95, wantDegraded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCPU(t, tt.cpu)
+			h := loadHandler(t)
+			for i := 0; i < tt.busyStreams; i++ {
+				h.streams.TryAcquire()
+			}
+			app := newTestAppFrom(h)
+
+			// 20 fps asked for; degraded streams get 4
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", "/p/HI?f=small&format=cast&fps=20&t=1", nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+			}
+			if got := resp.Header.Get(HeaderDegraded) != ""; got != tt.wantDegraded {
+				t.Errorf("%s header present = %v, want %v", HeaderDegraded, got, tt.wantDegraded)
+			}
+			_, events := parseCast(t, body)
+			wantStep := 0.05
+			if tt.wantDegraded {
+				wantStep = 0.25
+			}
+			if got := events[2][0].(float64); got != wantStep {
+				t.Errorf("second frame at %vs, want %vs", got, wantStep)
+			}
+		})
+	}
+}
+
+func TestDegradedStreamSkipsCompression(t *testing.T) {
+	fastCountdown(t)
+	fakeCPU(t, 95)
+	h := loadHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	req := httptest.NewRequest("GET", "/countdown/1?f=small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, _ := doRequest(t, app, req)
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q, want none for a degraded stream", got)
+	}
+	if h.metrics.Snapshot().Streams.Degraded != 1 {
+		t.Errorf("DegradedStreams = %d, want 1", h.metrics.Snapshot().Streams.Degraded)
+	}
+}
+
+func TestCPUSampler(t *testing.T) {
+	s := &cpuSampler{}
+	s.percent()
+	if s.at.IsZero() {
+		t.Fatal("first call should take a sample")
+	}
+	at := s.at
+	s.percent()
+	if s.at != at {
+		t.Error("samples should be at least cpuSampleEvery apart")
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import "strings"
+
+// Info strings of the fences ?format=md and md-ansi wrap banners in.
+const (
+	markdownPlainInfo = "text"
+	markdownANSIInfo  = "ansi"
+)
+
+// markdownBlock wraps output in a fenced Markdown code block with the
+// given info string. The fence is one backtick longer than the longest
+// run of backticks in the art, so the art can't close it early.
+func markdownBlock(output, info string) string {
+	longest, run := 0, 0
+	for _, r := range output {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + info + "\n" + strings.TrimRight(output, "\n") + "\n" + fence + "\n"
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMarkdownBlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		info   string
+		want   string
+	}{
+		{name: "plain", output: " _ \n|_|\n", info: "text", want: "```text\n _ \n|_|\n```\n"},
+		{name: "ansi", output: "\x1b[31mX\x1b[0m\n", info: "ansi", want: "```ansi\n\x1b[31mX\x1b[0m\n```\n"},
+		{name: "backticks in the art", output: "`` ```\n", info: "text", want: "````text\n`` ```\n````\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownBlock(tt.output, tt.info); got != tt.want {
+				t.Errorf("markdownBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticMarkdownFormats(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		format     string
+		wantPrefix string
+		wantColors bool
+	}{
+		{format: "md", wantPrefix: "```text\n"},
+		{format: "md-ansi", wantPrefix: "```ansi\n", wantColors: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&c=fire&format="+tt.format, nil))
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if !strings.HasPrefix(body, tt.wantPrefix) || !strings.HasSuffix(body, "\n```\n") {
+				t.Errorf("body isn't a %q code block: %q", tt.wantPrefix, body)
+			}
+			if strings.Contains(body, "\x1b[") != tt.wantColors {
+				t.Errorf("body colored = %v, want %v: %q", !tt.wantColors, tt.wantColors, body)
+			}
+		})
+	}
+}
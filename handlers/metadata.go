@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// Response metadata headers of static banners, for scripts that lay out
+// around a banner and operators checking the render cache.
+const (
+	// HeaderFont names the font the banner was drawn in, after fuzzy
+	// matching and fallback to the default.
+	HeaderFont = "X-Shout-Font"
+	// HeaderWidth is the width of the response body, in columns.
+	HeaderWidth = "X-Shout-Width"
+	// HeaderHeight is the height of the response body, in lines.
+	HeaderHeight = "X-Shout-Height"
+	// HeaderCache says whether the banner came from the render cache:
+	// hit or miss. It is left out when the cache is off.
+	HeaderCache = "X-Shout-Cache"
+)
+
+// Values of HeaderCache.
+const (
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+)
+
+// setOutputSize sets HeaderWidth and HeaderHeight to the size of output
+// on a terminal.
+func setOutputSize(c *fiber.Ctx, output string) {
+	width, height := outputSize(output)
+	c.Set(HeaderWidth, strconv.Itoa(width))
+	c.Set(HeaderHeight, strconv.Itoa(height))
+}
+
+// outputSize returns the columns of the widest line of output and its
+// number of lines, leaving out escape sequences and the final newline.
+func outputSize(output string) (width, height int) {
+	output = strings.TrimSuffix(render.StripANSI(output), "\n")
+	if output == "" {
+		return 0, 0
+	}
+	for line := range strings.SplitSeq(output, "\n") {
+		width = max(width, render.StringWidth(strings.TrimSuffix(line, "\r")))
+		height++
+	}
+	return width, height
+}
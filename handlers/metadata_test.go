@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStaticMetadata(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?font=dom&c=fire", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get(HeaderFont); got != "doom" {
+		t.Errorf("%s = %q, want doom for font=dom", HeaderFont, got)
+	}
+	width, height := outputSize(body)
+	if got := resp.Header.Get(HeaderWidth); got != strconv.Itoa(width) || width == 0 {
+		t.Errorf("%s = %q, want %d", HeaderWidth, got, width)
+	}
+	if got := resp.Header.Get(HeaderHeight); got != strconv.Itoa(height) || height < 3 {
+		t.Errorf("%s = %q, want %d", HeaderHeight, got, height)
+	}
+	// Without a render cache there is no hit or miss to report
+	if got := resp.Header.Get(HeaderCache); got != "" {
+		t.Errorf("%s = %q without a cache, want none", HeaderCache, got)
+	}
+}
+
+func TestOutputSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "empty", output: "", wantWidth: 0, wantHeight: 0},
+		{name: "lines", output: "ab\nabcd\n", wantWidth: 4, wantHeight: 2},
+		{name: "colors", output: "\x1b[31mabc\x1b[0m\n", wantWidth: 3, wantHeight: 1},
+		{name: "runes", output: "█▀▀█\n", wantWidth: 4, wantHeight: 1},
+		{name: "blank line", output: "ab\n\nab\n", wantWidth: 2, wantHeight: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := outputSize(tt.output)
+			if width != tt.wantWidth || height != tt.wantHeight {
+				t.Errorf("outputSize(%q) = %d, %d, want %d, %d", strings.TrimSpace(tt.output), width, height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+// Static output formats drawn from the text banner rather than wrapping
+// it, chosen with ?format= or the Accept header.
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatHTML = "html"
+	formatSVG  = "svg"
+)
+
+// negotiated maps the media types the Accept header can ask static routes
+// for to their formats, in order of preference when the header doesn't
+// prefer one.
+var negotiated = []struct {
+	mediaType string
+	format    string
+}{
+	{fiber.MIMETextPlain, formatPlain},
+	{fiber.MIMEApplicationJSON, formatJSON},
+	{fiber.MIMETextHTML, formatHTML},
+	{svgMediaType, formatSVG},
+}
+
+// negotiateFormat picks the static format of the media type the Accept
+// header prefers, plain text without the header, or ErrNotAcceptable
+// when it accepts none of them.
+func negotiateFormat(c *fiber.Ctx) (string, error) {
+	c.Vary(fiber.HeaderAccept)
+	if c.Get(fiber.HeaderAccept) == "" {
+		return formatPlain, nil
+	}
+	offers := make([]string, len(negotiated))
+	for i, n := range negotiated {
+		offers[i] = n.mediaType
+	}
+	accepted := c.Accepts(offers...)
+	for _, n := range negotiated {
+		if n.mediaType == accepted {
+			return n.format, nil
+		}
+	}
+	return "", apierrors.ErrNotAcceptable.WithDetail("can't send %s", c.Get(fiber.HeaderAccept)).
+		WithHint("accept text/plain, application/json, text/html or image/svg+xml, or choose with ?format=")
+}
+
+// convertedFormat reports whether format is drawn from the text banner
+// after it is rendered (convertStatic) rather than wrapping it.
+func convertedFormat(format string) bool {
+	return format == formatJSON || format == formatHTML || format == formatSVG
+}
+
+// staticJSON is the body of format=json.
+type staticJSON struct {
+	Text   string `json:"text"`
+	Font   string `json:"font"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Banner is the text banner, with the colors of the ?term= terminal
+	Banner string `json:"banner"`
+}
+
+// convertStatic draws the text banner of a static render in a converted
+// format, returning the body and its media type.
+func (h *Handler) convertStatic(banner *staticBanner, output string) ([]byte, string, error) {
+	var b bytes.Buffer
+	switch banner.format {
+	case formatJSON:
+		width, height := outputSize(output)
+		err := json.NewEncoder(&b).Encode(staticJSON{
+			Text:   banner.text,
+			Font:   banner.font.Name,
+			Width:  width,
+			Height: height,
+			Banner: output,
+		})
+		return b.Bytes(), fiber.MIMEApplicationJSON, err
+	case formatHTML:
+		fmt.Fprintf(&b, "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body style=\"margin:0\">\n",
+			html.EscapeString(banner.text))
+		if err := h.playEvents([]streamEvent{{0, output}}).EncodeHTML(&b); err != nil {
+			return nil, "", err
+		}
+		b.WriteString("</body>\n</html>\n")
+		return b.Bytes(), fiber.MIMETextHTMLCharsetUTF8, nil
+	case formatSVG:
+		err := h.playEvents([]streamEvent{{0, output}}).EncodeSVG(&b)
+		return b.Bytes(), svgMediaType, err
+	}
+	return []byte(output), textPlain, nil
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+func TestStaticNegotiation(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		accept     string
+		wantStatus int
+		wantType   string
+		wantBody   string
+	}{
+		{name: "no accept header", target: "/HI", wantStatus: 200, wantType: "text/plain", wantBody: "_"},
+		{name: "anything", target: "/HI", accept: "*/*", wantStatus: 200, wantType: "text/plain", wantBody: "_"},
+		{name: "plain text", target: "/HI", accept: "text/plain", wantStatus: 200, wantType: "text/plain", wantBody: "_"},
+		{name: "json", target: "/HI", accept: "application/json", wantStatus: 200, wantType: "application/json", wantBody: `"text":"HI"`},
+		{name: "html", target: "/HI", accept: "text/html", wantStatus: 200, wantType: "text/html", wantBody: "<pre"},
+		{name: "browser", target: "/HI", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", wantStatus: 200, wantType: "text/html", wantBody: "<!DOCTYPE html>"},
+		{name: "svg", target: "/HI", accept: "image/svg+xml", wantStatus: 200, wantType: "image/svg+xml", wantBody: "<svg"},
+		{name: "preferred by quality", target: "/HI", accept: "text/html;q=0.5, application/json", wantStatus: 200, wantType: "application/json", wantBody: `"banner":`},
+		{name: "format wins", target: "/HI?format=json", accept: "text/html", wantStatus: 200, wantType: "application/json", wantBody: `"font":`},
+		{name: "format text", target: "/HI?format=text", accept: "application/json", wantStatus: 200, wantType: "text/plain", wantBody: "_"},
+		{name: "format over unacceptable", target: "/HI?format=md", accept: "image/png", wantStatus: 200, wantType: "text/plain", wantBody: "```"},
+		{name: "unacceptable", target: "/HI", accept: "image/png", wantStatus: 406},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus != 200 {
+				if got := resp.Header.Get(apierrors.HeaderErrorCode); got != "not_acceptable" {
+					t.Errorf("%s = %q, want not_acceptable", apierrors.HeaderErrorCode, got)
+				}
+				return
+			}
+			if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("Content-Type = %q, want %s", got, tt.wantType)
+			}
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("body doesn't contain %q: %q", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestStaticNegotiationCaching(t *testing.T) {
+	app := newTestApp(t)
+
+	get := func(accept string) (string, string) {
+		req := httptest.NewRequest("GET", "/CACHED?f=doom", nil)
+		req.Header.Set("Accept", accept)
+		resp, body := doRequest(t, app, req)
+		if resp.StatusCode != 200 {
+			t.Fatalf("Accept %s: status = %d (body %q)", accept, resp.StatusCode, body)
+		}
+		if vary := resp.Header.Get("Vary"); !strings.Contains(vary, "Accept") {
+			t.Errorf("Vary = %q, want Accept", vary)
+		}
+		return resp.Header.Get("ETag"), body
+	}
+
+	textTag, text := get("text/plain")
+	jsonTag, body := get("application/json")
+	if textTag == jsonTag {
+		t.Errorf("text and JSON share the ETag %s", textTag)
+	}
+	var got staticJSON
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("decoding JSON: %v (body %q)", err, body)
+	}
+	// The JSON is drawn from the same text banner, cached or not
+	if got.Banner != text || got.Font != "doom" || got.Width == 0 || got.Height == 0 {
+		t.Errorf("JSON = %+v, want the text banner %q with its font and size", got, text)
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// openAPIVersion is the OpenAPI specification version of the document.
+const openAPIVersion = "3.0.3"
+
+// pathParams describes the path parameters used in endpoint paths.
+var pathParams = map[string]string{
+	"text":    "The text to render; use + or %20 for spaces and %0A for new lines",
+	"id":      "A short link ID returned by POST /s",
+	"seconds": "How many seconds to count down from",
+	"name":    "The name of an .ans file in the gallery",
+	"preset":  "The name of a preset saved by your API key",
+	"label":   "The badge label, drawn as plain text",
+	"value":   "The badge value, drawn as ASCII art",
+}
+
+// errorStatuses are the error responses of routes that render text. See
+// the apierrors package for the error codes behind them.
+var errorStatuses = map[string]string{
+	"304": "Not modified; the If-None-Match tag still matches",
+	"400": "Missing or invalid text or options",
+	"404": "No font could be loaded",
+	"503": "Rendering took too long",
+}
+
+// OpenAPI serves an OpenAPI 3 document describing the public routes,
+// generated from the same endpoint and option definitions as the help
+// page so the two can't disagree.
+//
+// Example:
+//
+//	curl localhost:3000/openapi.json
+func (h *Handler) OpenAPI(c *fiber.Ctx) error {
+	return c.JSON(h.openAPISpec())
+}
+
+// openAPISpec builds the OpenAPI document. The font parameter lists the
+// fonts loaded at the time of the call.
+func (h *Handler) openAPISpec() fiber.Map {
+	paths := fiber.Map{}
+	for _, e := range h.publicEndpoints() {
+		var params []fiber.Map
+		for name, description := range pathParams {
+			if strings.Contains(e.Path, "{"+name+"}") {
+				params = append(params, fiber.Map{
+					"name":        name,
+					"in":          "path",
+					"required":    true,
+					"description": description,
+					"schema":      fiber.Map{"type": "string"},
+				})
+			}
+		}
+		params = append(params, queryParameters(e.Params)...)
+		if e.Options {
+			params = append(params, h.optionParameters()...)
+		}
+
+		content := fiber.Map{}
+		for _, typ := range e.Types {
+			content[strings.Split(typ, ";")[0]] = fiber.Map{"schema": fiber.Map{"type": schemaType(typ)}}
+		}
+		status := "200"
+		// Posted specs are saved as short links
+		switch {
+		case e.Method == fiber.MethodPost && e.Shortlinks:
+			status = "201"
+		case e.Async:
+			status = "202"
+		case e.Method == fiber.MethodDelete:
+			status = "204"
+		}
+		response := fiber.Map{"description": "OK"}
+		if len(content) > 0 {
+			response["content"] = content
+		}
+		responses := fiber.Map{status: response}
+		if e.Options {
+			for status, description := range errorStatuses {
+				responses[status] = fiber.Map{"description": description}
+			}
+		}
+
+		op := fiber.Map{"summary": e.Description, "responses": responses}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if e.Body {
+			op["requestBody"] = h.specBody(e.BodyParams, e.BodyOptions)
+		}
+		if e.RawBody != "" {
+			schema := fiber.Map{"type": "string", "format": "binary"}
+			if e.RawBody == fiber.MIMEApplicationJSON {
+				schema = fiber.Map{"type": "object"}
+			}
+			op["requestBody"] = fiber.Map{
+				"required": true,
+				"content":  fiber.Map{e.RawBody: fiber.Map{"schema": schema}},
+			}
+		}
+
+		methods, _ := paths[e.Path].(fiber.Map)
+		if methods == nil {
+			methods = fiber.Map{}
+			paths[e.Path] = methods
+		}
+		methods[strings.ToLower(e.Method)] = op
+	}
+
+	return fiber.Map{
+		"openapi": openAPIVersion,
+		"info": fiber.Map{
+			"title":       "shout.sh",
+			"description": "ASCII art banners for your terminal.",
+			"version":     h.version(),
+		},
+		"servers": []fiber.Map{
+			{"url": APIVersion, "description": "Current API version"},
+			{"url": "/", "description": "Unversioned alias of the current API version"},
+		},
+		"paths": paths,
+	}
+}
+
+// optionParameters describes the render query parameters by their long
+// names, with short aliases noted in the description.
+func (h *Handler) optionParameters() []fiber.Map {
+	params := make([]fiber.Map, 0, len(renderOptions))
+	for _, o := range renderOptions {
+		name := o.Names[len(o.Names)-1]
+		description := o.Help
+		if len(o.Names) > 1 {
+			description += ". Alias: " + strings.Join(o.Names[:len(o.Names)-1], ", ")
+		}
+		for _, flag := range figletFlags {
+			if flag.Option == name {
+				description += ". Figlet flag: " + flag.Flag
+			}
+		}
+
+		schema := fiber.Map{"type": o.Type}
+		switch name {
+		case "font":
+			schema["enum"] = append(h.fonts.ListFonts(), randomFont)
+		case "engine":
+			schema["enum"] = types.Engines
+		}
+		params = append(params, fiber.Map{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"schema":      schema,
+		})
+	}
+	schema := fiber.Map{"type": presetParam.Type}
+	if presets := h.presets(); len(presets) > 0 {
+		names := make([]string, len(presets))
+		for i, preset := range presets {
+			names[i] = preset.Name
+		}
+		schema["enum"] = names
+	}
+	return append(params, fiber.Map{
+		"name":        presetParam.Names[0],
+		"in":          "query",
+		"description": presetParam.Help,
+		"schema":      schema,
+	})
+}
+
+// queryParameters describes route-specific query parameters.
+func queryParameters(options []optionHelp) []fiber.Map {
+	params := make([]fiber.Map, 0, len(options))
+	for _, o := range options {
+		params = append(params, fiber.Map{
+			"name":        o.Names[0],
+			"in":          "query",
+			"description": o.Help,
+			"schema":      fiber.Map{"type": o.Type},
+		})
+	}
+	return params
+}
+
+// specBody describes a JSON body holding the text and render options,
+// with the required fields in extra and the optional ones in options.
+func (h *Handler) specBody(extra, options []optionHelp) fiber.Map {
+	properties := fiber.Map{
+		"text": fiber.Map{"type": "string", "description": "The text to render"},
+	}
+	required := []string{"text"}
+	for _, o := range renderOptions {
+		properties[o.Names[len(o.Names)-1]] = fiber.Map{"type": o.Type, "description": o.Help}
+	}
+	for _, o := range extra {
+		properties[o.Names[0]] = fiber.Map{"type": o.Type, "description": o.Help}
+		required = append(required, o.Names[0])
+	}
+	for _, o := range options {
+		properties[o.Names[0]] = fiber.Map{"type": o.Type, "description": o.Help}
+	}
+	return fiber.Map{
+		"required": true,
+		"content": fiber.Map{
+			fiber.MIMEApplicationJSON: fiber.Map{
+				"schema": fiber.Map{
+					"type":       "object",
+					"required":   required,
+					"properties": properties,
+				},
+			},
+		},
+	}
+}
+
+// schemaType returns the OpenAPI schema type of a response media type.
+func schemaType(mediaType string) string {
+	if strings.HasPrefix(mediaType, fiber.MIMEApplicationJSON) {
+		return "object"
+	}
+	return "string"
+}
+
+// swaggerPage loads Swagger UI from a CDN and points it at the admin
+// port's copy of the OpenAPI document.
+const swaggerPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>shout.sh API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+// SwaggerUI serves a minimal Swagger UI page for exploring the API.
+//
+// Example:
+//
+//	open http://localhost:9090/docs
+func (h *Handler) SwaggerUI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(swaggerPage)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestOpenAPI(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/openapi.json", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Get struct {
+				Parameters []struct {
+					Name   string `json:"name"`
+					In     string `json:"in"`
+					Schema struct {
+						Type string   `json:"type"`
+						Enum []string `json:"enum"`
+					} `json:"schema"`
+				} `json:"parameters"`
+				Responses map[string]any `json:"responses"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(body), &spec); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", spec.OpenAPI)
+	}
+	for _, e := range newTestHandler(t).publicEndpoints() {
+		if _, ok := spec.Paths[e.Path]; !ok {
+			t.Errorf("spec is missing path %s", e.Path)
+		}
+	}
+
+	textOp := spec.Paths["/{text}"].Get
+	if _, ok := textOp.Responses["400"]; !ok {
+		t.Error("render path should document 400 responses")
+	}
+	params := make(map[string]string)
+	for _, p := range textOp.Parameters {
+		params[p.Name] = p.In + ":" + p.Schema.Type
+		if p.Name == "font" && strings.Join(p.Schema.Enum, ",") != "doom,small,standard,random" {
+			t.Errorf("font enum = %v, want loaded fonts", p.Schema.Enum)
+		}
+	}
+	for name, want := range map[string]string{"text": "path:string", "font": "query:string", "speed": "query:integer", "cmode": "query:string", "cols": "query:integer", "preset": "query:string"} {
+		if params[name] != want {
+			t.Errorf("parameter %s = %q, want %q", name, params[name], want)
+		}
+	}
+	// The text, ?cols=, ?term=, ?nc=, ?seed=, ?format=, ?strict=,
+	// ?notes=, ?ascii=, ?download=, ?coding=, ?nofooter= and ?preset=
+	// join the render options
+	if len(params) != len(renderOptions)+13 {
+		t.Errorf("got %d parameters, want %d", len(params), len(renderOptions)+13)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	h := newTestHandler(t)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	for _, path := range []string{"/docs", "/openapi.json"} {
+		resp, body := doRequest(t, admin, httptest.NewRequest("GET", path, nil))
+		if resp.StatusCode != 200 {
+			t.Errorf("GET %s status = %d, want 200", path, resp.StatusCode)
+		}
+		if path == "/docs" && !strings.Contains(body, "SwaggerUIBundle") {
+			t.Errorf("docs page does not load Swagger UI:\n%s", body)
+		}
+	}
+}
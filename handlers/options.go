@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// parseOptions reads render options from the query string, over the
+// slots of the ?theme= theme, or the preset's theme, then the options of
+// the ?preset= preset, if any, then the defaults of the request's virtual
+// host, if it has one, then the server's (defaultOptions). It fails only
+// for an unknown preset or theme.
+func (h *Handler) parseOptions(c *fiber.Ctx) (types.RenderOptions, error) {
+	return h.withDefaults(c, requestOptions(c), h.defaultOptions())
+}
+
+// figletFlag is a query parameter named after a figlet command line flag,
+// so figlet commands port over as they are typed: ?-f=slant&-w=120.
+type figletFlag struct {
+	// Flag is the parameter, such as "-f"
+	Flag string
+	// Option is the long name of the option the flag sets, "" for none
+	Option string
+	Help   string
+}
+
+// figletFlags are the figlet flags requestOptions understands. The
+// native parameters take precedence over them.
+var figletFlags = []figletFlag{
+	{Flag: "-f", Option: "font", Help: "figlet's font flag, as font"},
+	{Flag: "-w", Option: "maxwidth", Help: "figlet's output width, as maxwidth"},
+	{Flag: "-c", Option: "align", Help: "figlet's centering, as align=center; takes no value"},
+	{Flag: "-k", Help: "figlet's kerning, accepted but without effect: glyphs are always drawn at full width"},
+}
+
+// requestOptions reads the render options the query string gives.
+// Short aliases take precedence over the long parameter names, and both
+// over the figlet flags (figletFlags).
+func requestOptions(c *fiber.Ctx) types.RenderOptions {
+	return types.RenderOptions{
+		Font:        firstOf(c.Query("f"), c.Query("font"), c.Query("-f")),
+		Color:       firstOf(c.Query("c"), c.Query("color")),
+		ColorMode:   c.Query("cmode"),
+		GradientDir: c.Query("gdir"),
+		MaxWidth:    c.QueryInt("mw", c.QueryInt("maxwidth", c.QueryInt("-w", 0))),
+		Timeout:     c.QueryInt("t", c.QueryInt("timeout", 0)),
+		Speed:       c.QueryInt("s", c.QueryInt("speed", 0)),
+		Align:       firstOf(c.Query("a"), c.Query("align"), figletAlign(c)),
+		Border:      firstOf(c.Query("b"), c.Query("border")),
+		Engine:      c.Query("engine"),
+		Scale:       c.QueryInt("scale", 0),
+		Fill:        c.Query("fill"),
+		Hardblank:   c.Query("hardblank"),
+		Theme:       c.Query("theme"),
+		Background:  c.Query("bg"),
+		Accent:      c.Query("accent"),
+		Padding:     c.QueryInt("pad", 0),
+	}
+}
+
+// figletAlign returns "center" for figlet's -c flag, given bare (?-c) or
+// with any value but false, and "" otherwise.
+func figletAlign(c *fiber.Ctx) string {
+	if !c.Context().QueryArgs().Has("-c") || c.Query("-c") == "false" {
+		return ""
+	}
+	return "center"
+}
+
+// defaultOptions returns the server's render options for requests that
+// leave an option out: SHOUT_FONTS_DEFAULT and SHOUT_TEXT_DEFAULT_*.
+// Timeout and speed are left out, as their defaults depend on the route;
+// streams apply SHOUT_STREAMING_DEFAULT_TIMEOUT and _SPEED themselves.
+func (h *Handler) defaultOptions() types.RenderOptions {
+	cfg := h.config()
+	if cfg == nil {
+		return types.RenderOptions{Font: render.DefaultFont}
+	}
+	return types.RenderOptions{
+		Font:      cfg.Fonts.Default,
+		Color:     cfg.Text.DefaultColor,
+		ColorMode: cfg.Text.DefaultColorMode,
+		MaxWidth:  cfg.Text.DefaultMaxWidth,
+		Align:     cfg.Text.DefaultAlign,
+		Border:    cfg.Text.DefaultBorder,
+	}
+}
+
+// withDefaults fills in the options opts leaves out from the slots of its
+// theme, or the ?preset= preset's, then from the preset, then the
+// request's virtual host, if it has one, then from defaults, and brings
+// speed, timeout and width within the server's limits (optionRules).
+func (h *Handler) withDefaults(c *fiber.Ctx, opts, defaults types.RenderOptions) (types.RenderOptions, error) {
+	if host := middleware.Host(c); host != nil {
+		defaults.Font = firstOf(host.Font, defaults.Font)
+		defaults.Color = firstOf(host.Color, defaults.Color)
+		defaults.Align = firstOf(host.Align, defaults.Align)
+	}
+	preset, err := h.requestPreset(c)
+	if err != nil {
+		return types.RenderOptions{}, err
+	}
+	theme, err := h.theme(firstOf(opts.Theme, preset.Theme))
+	if err != nil {
+		return types.RenderOptions{}, err
+	}
+	rules := h.optionRules()
+	rules.Defaults = theme.Options().Normalize(types.OptionRules{
+		Defaults: preset.Normalize(types.OptionRules{Defaults: defaults}),
+	})
+	return opts.Normalize(rules), nil
+}
+
+// optionRules returns the server's limits for render options:
+// SHOUT_STREAMING_MIN_SPEED, _MAX_SPEED and _MAX_TIMEOUT, and
+// SHOUT_TERMINAL_MAX_COLS, with colors checked against the renderer's.
+func (h *Handler) optionRules() types.OptionRules {
+	rules := types.OptionRules{MaxWidth: defaultMaxCols, Colors: checkColors, SolidColor: checkSolidColor}
+	if cfg := h.config(); cfg != nil {
+		rules.MinSpeed, rules.MaxSpeed = cfg.Streaming.MinSpeed, cfg.Streaming.MaxSpeed
+		rules.MaxTimeout = cfg.Streaming.MaxTimeout
+		if cfg.Terminal.MaxCols > 0 {
+			rules.MaxWidth = cfg.Terminal.MaxCols
+		}
+	}
+	return rules
+}
+
+// validateOptions returns ErrBadOption describing each invalid option in
+// opts, or nil if they're all valid.
+func (h *Handler) validateOptions(opts types.RenderOptions) *apierrors.Error {
+	if err := opts.Validate(h.optionRules()); err != nil {
+		return apierrors.ErrBadOption.WithDetail("%v", err)
+	}
+	return nil
+}
+
+// checkColors reports whether color and mode are a color scheme the
+// renderer knows.
+func checkColors(color, mode string) error {
+	_, err := parseColorScheme(types.RenderOptions{Color: color, ColorMode: mode})
+	return err
+}
+
+// checkSolidColor reports whether color is a single color the renderer
+// knows, for the bg and accent options.
+func checkSolidColor(color string) error {
+	_, err := render.ParseSolidColor(color)
+	return err
+}
+
+// defaultFont returns the font renders fall back to when the requested
+// one isn't loaded: SHOUT_FONTS_DEFAULT.
+func (h *Handler) defaultFont() string {
+	if cfg := h.config(); cfg != nil && cfg.Fonts.Default != "" {
+		return cfg.Fonts.Default
+	}
+	return render.DefaultFont
+}
+
+// parseColorScheme resolves the color and cmode options into a scheme.
+func parseColorScheme(opts types.RenderOptions) (render.ColorScheme, error) {
+	scheme, err := render.ParseColor(opts.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := render.ParseColorMode(opts.ColorMode)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := scheme.(*render.ColorList); ok {
+		list.Mode = mode
+	}
+
+	dir, err := render.ParseGradientDirection(opts.GradientDir)
+	if err != nil {
+		return nil, err
+	}
+	if gradient, ok := scheme.(*render.Gradient); ok {
+		gradient.Direction = dir
+	}
+	return scheme, nil
+}
+
+// firstOf returns the first non-empty string from the arguments.
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
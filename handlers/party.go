@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+)
+
+// defaultPartyColor animates party mode when no color is given and
+// SHOUT_STREAMING_DEFAULT_COLOR is empty.
+const defaultPartyColor = "rainbow"
+
+// phaseRange bounds the random frame the colors of an animation start at.
+const phaseRange = 1 << 10
+
+// partyParams are the query parameters of the party routes.
+var partyParams = []optionHelp{
+	{Names: []string{"anim"}, Help: "Animation: colors, typewriter or scroll, optionally layered with + and a color preset, as in scroll+rainbow", Type: "string"},
+	{Names: []string{"loops"}, Help: "Passes of a typewriter or scroll animation before the stream ends; 0 repeats until the timeout", Type: "integer"},
+	fpsParam,
+	colsParam,
+	rowsParam,
+	termParam,
+	ncParam,
+	seedParam,
+	formatParam,
+	screenParam,
+}
+
+// Party streams the text in the request path as a banner whose colors
+// move with each frame, animated by ?anim=. The frame rate follows ?speed=
+// or ?fps=. The stream ends after ?loops= passes of a finite animation such
+// as typewriter, or after ?t= seconds, or SHOUT_STREAMING_DEFAULT_TIMEOUT
+// when neither is given. With the terminal size from ?cols= and ?rows= or
+// X-Terminal-Size, the text wraps and aligns within it and every frame is
+// cropped to fit. The text is read from middleware.InputValidation,
+// which must run first.
+//
+// Example:
+//
+//	curl 'localhost:8080/p/DEPLOY+SUCCESS?t=5&s=8'
+//	curl 'localhost:8080/p/HELLO?anim=typewriter&loops=2'
+func (h *Handler) Party(c *fiber.Ctx) error {
+	cfg := h.config()
+	h.metrics.IncParty()
+
+	text := middleware.Text(c)
+	if text == "" {
+		return apierrors.Send(c, apierrors.ErrNoText)
+	}
+
+	interval, err := h.frameInterval(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	animation := c.Query("anim")
+	if animation == "" && cfg != nil {
+		animation = cfg.Streaming.DefaultAnimation
+	}
+	anim, preset, err := shout.ParseAnimation(animation)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err).
+			WithHint("use one of "+strings.Join(shout.AnimationNames(), ", ")+", joined with +"))
+	}
+	loops := c.QueryInt("loops", 0)
+	if loops < 0 {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("loops must be 0 or more, got %d", loops))
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	bounds := term.stream()
+
+	// Streams have a color of their own, which a preset in the animation
+	// replaces, as it does the virtual host's
+	requested, defaults := requestOptions(c), h.defaultOptions()
+	defaults.Color = defaultPartyColor
+	if cfg != nil && cfg.Streaming.DefaultColor != "" {
+		defaults.Color = cfg.Streaming.DefaultColor
+	}
+	opts, err := h.withDefaults(c, requested, defaults)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	opts.Font = h.requestFont(c, opts.Font)
+	switch {
+	case preset != "" && requested.Color != "":
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("anim=%s and color=%s both set the colors", preset, requested.Color))
+	case preset != "":
+		opts.Color = preset
+	}
+	suggestion := h.suggestFont(c, opts.Font)
+	if err := h.validateOptions(opts); err != nil {
+		return apierrors.Send(c, err.WithHint(suggestion))
+	}
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err).WithHint(suggestion))
+	}
+
+	// Finite animations repeat for ?loops= passes; the others stop at the
+	// timeout
+	timeout := time.Duration(opts.Timeout) * time.Second
+	if timeout <= 0 && (loops == 0 || anim.Endless()) && cfg != nil {
+		timeout = time.Duration(cfg.Streaming.DefaultTimeout) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.renderBudget())
+	defer cancel()
+	frames, err := h.shout.Frames(ctx, text, shout.Options{
+		Font:      opts.Font,
+		Engine:    opts.Engine,
+		Scale:     opts.Scale,
+		Fill:      opts.Fill,
+		Hardblank: opts.Hardblank,
+		Color:     opts.Color,
+		Scheme:    h.seededScheme(c, scheme),
+		Align:     h.alignment(opts),
+		Cols:      bounds.cols,
+		Rows:      bounds.rows,
+		Masked:    middleware.Masked(c),
+		Animation: animation,
+		Interval:  interval,
+		Loops:     loops,
+		Duration:  timeout,
+		Phase:     h.requestRand(c).IntN(phaseRange),
+	})
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrRenderFailed.WithDetail("%v", err).WithHint(suggestion))
+	}
+	h.countUsage(opts.Font, opts.Color, animation, true)
+	return h.stream(c, interval, func(n int) (string, bool, error) {
+		frame, last := frames(n)
+		return frame, last, nil
+	})
+}
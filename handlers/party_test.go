@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// newPartyHandler returns a test handler with the default streaming speeds.
+func newPartyHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newTestHandler(t)
+	h.config().Streaming.DefaultTimeout = 2
+	h.config().Streaming.MaxTimeout = 60
+	h.config().Streaming.DefaultSpeed = 5
+	h.config().Streaming.MinSpeed = 1
+	h.config().Streaming.MaxSpeed = 10
+	h.config().Streaming.MaxFPS = 20
+	return h
+}
+
+func TestParty(t *testing.T) {
+	h := newPartyHandler(t)
+	app := newTestAppFrom(h)
+
+	for _, path := range []string{"/p/HI", "/party/HI", "/v1/p/HI"} {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", path+"?f=small&format=cast&s=1&t=3", nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s: status = %d, want 200 (body %q)", path, resp.StatusCode, body)
+		}
+		_, events := parseCast(t, body)
+
+		// Prologue, three frames a second apart and the epilogue
+		var times []float64
+		for _, e := range events {
+			times = append(times, e[0].(float64))
+		}
+		if want := []float64{0, 0, 1, 2, 2}; !slices.Equal(times, want) {
+			t.Errorf("%s: event times = %v, want %v", path, times, want)
+		}
+		// Rainbow by default, moving with each frame
+		if first, second := events[1][2].(string), events[2][2].(string); !strings.Contains(first, "\x1b[38;") || first == second {
+			t.Errorf("%s: frames should be colored and animated", path)
+		}
+	}
+}
+
+func TestPartyErrors(t *testing.T) {
+	app := newTestAppFrom(newPartyHandler(t))
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{name: "no text", target: "/p/%20", wantStatus: 400},
+		{name: "bad color", target: "/p/HI?c=nope", wantStatus: 400},
+		{name: "speed too high", target: "/p/HI?s=11", wantStatus: 400},
+		{name: "fps too high", target: "/p/HI?fps=21", wantStatus: 400},
+		{name: "text too long", target: "/p/" + strings.Repeat("A", 21), wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+		})
+	}
+}
+
+func TestFrameInterval(t *testing.T) {
+	h := newPartyHandler(t)
+	h.config().Streaming.MinSpeed = 2
+	h.config().Streaming.MaxSpeed = 9
+
+	tests := []struct {
+		name    string
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default speed", query: "", want: 250 * time.Millisecond},
+		{name: "slowest allowed", query: "s=2", want: 700 * time.Millisecond},
+		{name: "fastest allowed", query: "speed=9", want: 70 * time.Millisecond},
+		{name: "below min speed", query: "s=1", wantErr: true},
+		{name: "above max speed", query: "s=10", wantErr: true},
+		{name: "fps", query: "fps=4", want: 250 * time.Millisecond},
+		{name: "fps overrides speed", query: "s=2&fps=10", want: 100 * time.Millisecond},
+		{name: "fps above max", query: "fps=25", wantErr: true},
+		{name: "invalid fps", query: "fps=fast", wantErr: true},
+		{name: "zero fps", query: "fps=0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got time.Duration
+			var err error
+			app.Get("/", func(c *fiber.Ctx) error {
+				got, err = h.frameInterval(c)
+				return nil
+			})
+			doRequest(t, app, httptest.NewRequest("GET", "/?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("frameInterval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("frameInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPartyLoops(t *testing.T) {
+	h := newPartyHandler(t)
+	h.config().Streaming.DefaultTimeout = 1
+	app := newTestAppFrom(h)
+
+	// "HI" types in two frames and holds for shout.TypewriterHold
+	pass := 2 + shout.TypewriterHold
+
+	tests := []struct {
+		name       string
+		query      string
+		wantFrames int
+	}{
+		{name: "one pass", query: "anim=typewriter&loops=1", wantFrames: pass},
+		{name: "three passes", query: "anim=typewriter&loops=3", wantFrames: 3 * pass},
+		{name: "loops outlast the default timeout", query: "anim=scroll&loops=1", wantFrames: 2 * render.LayoutWidth(partyLayout(t, h))},
+		{name: "timeout cuts loops short", query: "anim=typewriter&loops=3&t=1", wantFrames: 4},
+		{name: "zero loops run to the timeout", query: "anim=typewriter&loops=0", wantFrames: 4},
+		{name: "endless animations ignore loops", query: "anim=colors&loops=1", wantFrames: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", "/p/HI?f=small&format=cast&s=5&"+tt.query, nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+			}
+			// The cast adds the prologue and epilogue
+			if _, events := parseCast(t, body); len(events)-2 != tt.wantFrames {
+				t.Errorf("got %d frames, want %d", len(events)-2, tt.wantFrames)
+			}
+		})
+	}
+}
+
+func TestPartyAnimationErrors(t *testing.T) {
+	app := newTestAppFrom(newPartyHandler(t))
+
+	for _, query := range []string{"anim=spin", "anim=typewriter&loops=-1", "anim=scroll+scroll", "anim=rainbow&c=red"} {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", "/p/HI?"+query, nil))
+		if resp.StatusCode != 400 {
+			t.Errorf("%s: status = %d, want 400 (body %q)", query, resp.StatusCode, body)
+		}
+	}
+}
+
+// partyLayout renders HI in the small font as party mode does.
+func partyLayout(t *testing.T, h *Handler) *render.Layout {
+	t.Helper()
+	layout, err := render.GenerateLayout("HI", types.RenderOptions{Font: "small"}, h.fonts)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return layout
+}
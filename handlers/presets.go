@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// presetParam documents ?preset=, which every route taking render options
+// accepts.
+var presetParam = optionHelp{Names: []string{"preset"}, Help: "Named bundle of options, see /presets, or @name for one saved by your API key; options given alongside override it", Type: "string"}
+
+// optionPreset is a named bundle of render options (SHOUT_TEXT_PRESETS).
+type optionPreset struct {
+	Name string `json:"name"`
+	// Query is the preset as written in the configuration, e.g. f=doom&c=fire
+	Query   string              `json:"query"`
+	Options types.RenderOptions `json:"options"`
+}
+
+// presets returns the presets of SHOUT_TEXT_PRESETS, in the order
+// configured. Entries that don't parse are skipped; config.Validate
+// rejects them before they get here.
+func (h *Handler) presets() []optionPreset {
+	cfg := h.config()
+	if cfg == nil {
+		return nil
+	}
+	presets := make([]optionPreset, 0, len(cfg.Text.Presets))
+	for _, entry := range cfg.Text.Presets {
+		name, query, _ := strings.Cut(entry, ":")
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			continue
+		}
+		opts, err := types.ParseQueryOptions(values)
+		if err != nil {
+			continue
+		}
+		presets = append(presets, optionPreset{Name: name, Query: query, Options: opts})
+	}
+	return presets
+}
+
+// requestPreset returns the options of the ?preset= preset, none without
+// one, or ErrBadOption for a preset that isn't configured. Names starting
+// with @ are presets saved by the request's API key (savedPreset).
+func (h *Handler) requestPreset(c *fiber.Ctx) (types.RenderOptions, error) {
+	name := c.Query("preset")
+	if name == "" {
+		return types.RenderOptions{}, nil
+	}
+	if isSavedPreset(name) {
+		return h.savedPreset(c, strings.TrimPrefix(name, savedPresetPrefix))
+	}
+	presets := h.presets()
+	names := make([]string, len(presets))
+	for i, preset := range presets {
+		if preset.Name == name {
+			return preset.Options, nil
+		}
+		names[i] = preset.Name
+	}
+	err := apierrors.ErrBadOption.WithDetail("unknown preset %q", name)
+	if len(names) == 0 {
+		return types.RenderOptions{}, err.WithHint("this server has no presets")
+	}
+	return types.RenderOptions{}, err.WithHint("use one of " + strings.Join(names, ", "))
+}
+
+// Presets lists the option presets ?preset= accepts, with the options
+// each one sets. JSON is returned when the client asks for it via the
+// Accept header, plain text otherwise.
+//
+// Example:
+//
+//	GET /presets
+func (h *Handler) Presets(c *fiber.Ctx) error {
+	presets := h.presets()
+	if strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMEApplicationJSON) {
+		return c.JSON(fiber.Map{"presets": presets})
+	}
+
+	var b strings.Builder
+	b.WriteString("Presets:\n")
+	for _, preset := range presets {
+		fmt.Fprintf(&b, "  %-12s %s\n", preset.Name, preset.Query)
+	}
+	b.WriteString("\nOptions given alongside ?preset= override the preset's.\n")
+	c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+	return c.SendString(b.String())
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPresetsHandler builds a handler with a "loud" preset drawing in small
+// with fire colors, and a "plain" one.
+func newPresetsHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newTestHandler(t)
+	h.config().Text.Presets = []string{"loud:f=small&c=fire&a=left", "plain:c=none"}
+	return h
+}
+
+func TestPresetRender(t *testing.T) {
+	app := newTestAppFrom(newPresetsHandler(t))
+	render := func(target string) string {
+		t.Helper()
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", target, nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("GET %s status = %d (body %q)", target, resp.StatusCode, body)
+		}
+		return body
+	}
+
+	tests := []struct {
+		name   string
+		preset string
+		same   string
+	}{
+		{name: "preset options", preset: "/HI?preset=loud", same: "/HI?f=small&c=fire&a=left"},
+		{name: "request overrides preset", preset: "/HI?preset=loud&f=doom&c=none", same: "/HI?f=doom&a=left"},
+		{name: "long names override short preset names", preset: "/HI?preset=loud&font=standard", same: "/HI?f=standard&c=fire&a=left"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := render(tt.preset), render(tt.same); got != want {
+				t.Errorf("%s =\n%s\nwant as %s:\n%s", tt.preset, got, tt.same, want)
+			}
+		})
+	}
+}
+
+func TestPresetUnknown(t *testing.T) {
+	app := newTestAppFrom(newPresetsHandler(t))
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?preset=quiet", nil))
+	if resp.StatusCode != 400 {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	for _, want := range []string{`unknown preset "quiet"`, "use one of loud, plain"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q: %s", want, body)
+		}
+	}
+}
+
+func TestPresets(t *testing.T) {
+	app := newTestAppFrom(newPresetsHandler(t))
+
+	t.Run("plain text listing", func(t *testing.T) {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", "/presets", nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		for _, want := range []string{"loud", "f=small&c=fire&a=left", "plain"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body missing %q: %s", want, body)
+			}
+		}
+	})
+
+	t.Run("json listing", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/presets", nil)
+		req.Header.Set("Accept", "application/json")
+		_, body := doRequest(t, app, req)
+
+		var got struct {
+			Presets []optionPreset `json:"presets"`
+		}
+		if err := json.Unmarshal([]byte(body), &got); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(got.Presets) != 2 || got.Presets[0].Name != "loud" || got.Presets[0].Options.Font != "small" {
+			t.Errorf("presets = %+v, want loud in small and plain", got.Presets)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// prometheusContentType is the content type of the Prometheus text
+// exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Prometheus serves the metrics in the Prometheus text exposition format:
+// latency histograms, response size summaries, status counts and
+// in-flight gauges by route, stream durations and bytes by route, and the
+// counters and render duration histogram of /stats.
+//
+// Example:
+//
+//	curl localhost:9090/metrics
+func (h *Handler) Prometheus(c *fiber.Ctx) error {
+	var p promWriter
+	m := h.metrics
+
+	routes := m.Routes()
+	p.family("shout_http_requests_total", "counter", "Requests answered, by method, route and status.")
+	for _, r := range routes {
+		for _, status := range slices.Sorted(maps.Keys(r.Statuses)) {
+			p.sample("shout_http_requests_total", labels("method", r.Method, "route", r.Route, "status", strconv.Itoa(status)), float64(r.Statuses[status]))
+		}
+	}
+	p.family("shout_http_request_duration_seconds", "histogram", "Time to answer requests, streams excepted, by method and route.")
+	for _, r := range routes {
+		p.histogram("shout_http_request_duration_seconds", labels("method", r.Method, "route", r.Route), r.Latency)
+	}
+	p.family("shout_http_response_size_bytes", "summary", "Response body sizes, streams excepted, by method and route.")
+	for _, r := range routes {
+		p.summary("shout_http_response_size_bytes", labels("method", r.Method, "route", r.Route), r.Size)
+	}
+	inFlight := m.InFlight()
+	p.family("shout_http_requests_in_flight", "gauge", "Requests being answered, by route.")
+	for _, route := range slices.Sorted(maps.Keys(inFlight)) {
+		p.sample("shout_http_requests_in_flight", labels("route", route), float64(inFlight[route]))
+	}
+
+	streams := m.Streams()
+	p.family("shout_streams_open", "gauge", "Streams running, by route.")
+	for _, s := range streams {
+		p.sample("shout_streams_open", labels("route", s.Route), float64(s.Open))
+	}
+	p.family("shout_stream_duration_seconds", "histogram", "How long closed streams ran, by route.")
+	for _, s := range streams {
+		p.histogram("shout_stream_duration_seconds", labels("route", s.Route), s.Duration)
+	}
+	p.family("shout_stream_sent_bytes", "summary", "Bytes closed streams sent, by route.")
+	for _, s := range streams {
+		p.summary("shout_stream_sent_bytes", labels("route", s.Route), s.Bytes)
+	}
+
+	snap := m.Snapshot()
+	p.family("shout_renders_total", "counter", "Renders, by kind.")
+	for _, kind := range []struct {
+		name  string
+		count int64
+	}{
+		{"static", snap.Requests.Static},
+		{"party", snap.Requests.Party},
+		{"fonts", snap.Requests.Fonts},
+		{"countdown", snap.Requests.Countdown},
+		{"clock", snap.Requests.Clock},
+		{"fortune", snap.Requests.Fortune},
+	} {
+		p.sample("shout_renders_total", labels("kind", kind.name), float64(kind.count))
+	}
+	p.family("shout_render_duration_seconds", "histogram", "Time to render static banners.")
+	p.histogram("shout_render_duration_seconds", "", snap.RenderDuration)
+	p.counter("shout_errors_total", "Render errors.", snap.Requests.Errors)
+	p.counter("shout_filtered_requests_total", "Requests caught by the word filter.", snap.Requests.Filtered)
+	p.counter("shout_rejected_streams_total", "Streams refused at the stream limit.", snap.Requests.RejectedStreams)
+	p.counter("shout_dropped_frames_total", "Stream frames skipped for slow clients.", snap.Streams.DroppedFrames)
+	p.counter("shout_degraded_streams_total", "Streams started degraded under load.", snap.Streams.Degraded)
+	p.family("shout_streams_active", "gauge", "Streams holding a slot of SHOUT_STREAMING_MAX_STREAMS.")
+	p.sample("shout_streams_active", "", float64(h.streams.GetActiveCount()))
+	p.family("shout_bot_requests_total", "counter", "Requests turned away as bots, by reason.")
+	for _, reason := range []struct {
+		name  string
+		count int64
+	}{
+		{"scanner", snap.Bots.Scanner},
+		{"probe", snap.Bots.Probe},
+		{"flood", snap.Bots.Flood},
+	} {
+		p.sample("shout_bot_requests_total", labels("reason", reason.name), float64(reason.count))
+	}
+	countries := m.Countries()
+	p.family("shout_country_requests_total", "counter", "Requests by client country, when GeoIP is on.")
+	for _, country := range slices.Sorted(maps.Keys(countries)) {
+		p.sample("shout_country_requests_total", labels("country", country), float64(countries[country]))
+	}
+
+	for _, usage := range []struct {
+		name, help, option string
+	}{
+		{"shout_font_renders_total", "Renders by font, when usage analytics are on.", types.UsageFont},
+		{"shout_color_renders_total", "Renders by color preset, custom or none, when usage analytics are on.", types.UsageColor},
+		{"shout_animation_renders_total", "Streams and gifs by animation, when usage analytics are on.", types.UsageAnimation},
+	} {
+		counts := m.Usage(usage.option)
+		p.family(usage.name, "counter", usage.help)
+		for _, value := range slices.Sorted(maps.Keys(counts)) {
+			p.sample(usage.name, labels(usage.option, value), float64(counts[value]))
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, prometheusContentType)
+	return c.SendString(p.String())
+}
+
+// promWriter builds a Prometheus text exposition.
+type promWriter struct {
+	strings.Builder
+}
+
+// family writes the HELP and TYPE lines of a metric.
+func (p *promWriter) family(name, typ, help string) {
+	fmt.Fprintf(p, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one sample; labels is empty or from labels().
+func (p *promWriter) sample(name, labels string, value float64) {
+	fmt.Fprintf(p, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// counter writes a counter metric without labels.
+func (p *promWriter) counter(name, help string, count int64) {
+	p.family(name, "counter", help)
+	p.sample(name, "", float64(count))
+}
+
+// histogram writes the cumulative buckets, sum and count of h.
+func (p *promWriter) histogram(name, labels string, h types.Histogram) {
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Counts[i]
+		p.sample(name+"_bucket", withLabel(labels, "le", strconv.FormatFloat(bound, 'g', -1, 64)), float64(cumulative))
+	}
+	p.sample(name+"_bucket", withLabel(labels, "le", "+Inf"), float64(h.Count))
+	p.sample(name+"_sum", labels, h.Sum)
+	p.sample(name+"_count", labels, float64(h.Count))
+}
+
+// summary writes the sum and count of s.
+func (p *promWriter) summary(name, labels string, s types.Summary) {
+	p.sample(name+"_sum", labels, s.Sum)
+	p.sample(name+"_count", labels, float64(s.Count))
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats name and value pairs as a label set, e.g.
+// {route="/date",method="GET"}.
+func labels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", pairs[i], labelEscaper.Replace(pairs[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel adds a label to a label set from labels().
+func withLabel(set, name, value string) string {
+	extra := labels(name, value)
+	if set == "" || set == "{}" {
+		return extra
+	}
+	return strings.TrimSuffix(set, "}") + "," + extra[1:]
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPrometheus(t *testing.T) {
+	h := newPartyHandler(t)
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	doRequest(t, app, httptest.NewRequest("GET", "/HI?f=doom", nil))
+	doRequest(t, app, httptest.NewRequest("GET", "/v1/HI?f=doom", nil))
+	doRequest(t, app, httptest.NewRequest("GET", "/badge/build/passing", nil))
+	doRequest(t, app, httptest.NewRequest("DELETE", "/HI", nil))
+	doRequest(t, app, httptest.NewRequest("GET", "/p/HI?f=small&s=10&t=1", nil))
+	// The stream is recorded once it has closed
+	deadline := time.Now().Add(5 * time.Second)
+	for len(h.metrics.Streams()) == 0 || h.metrics.Streams()[0].Open > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream never closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, body := doRequest(t, admin, httptest.NewRequest("GET", "/metrics", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != prometheusContentType {
+		t.Errorf("Content-Type = %q, want %q", got, prometheusContentType)
+	}
+	for _, want := range []string{
+		"# TYPE shout_http_request_duration_seconds histogram\n",
+		`shout_http_requests_total{method="GET",route="/*",status="200"} 1` + "\n",
+		`shout_http_requests_total{method="GET",route="/v1/*",status="200"} 1` + "\n",
+		`shout_http_requests_total{method="GET",route="/badge/:label/:value",status="200"} 1` + "\n",
+		`shout_http_requests_total{method="DELETE",route="unrouted",status="405"} 1` + "\n",
+		`shout_http_request_duration_seconds_bucket{method="GET",route="/*",le="+Inf"} 1` + "\n",
+		`shout_http_request_duration_seconds_count{method="GET",route="/*"} 1` + "\n",
+		`shout_http_response_size_bytes_count{method="GET",route="/*"} 1` + "\n",
+		`shout_http_requests_in_flight{route="/*"} 0` + "\n",
+		`shout_streams_open{route="/p/*"} 0` + "\n",
+		`shout_stream_duration_seconds_count{route="/p/*"} 1` + "\n",
+		`shout_stream_sent_bytes_count{route="/p/*"} 1` + "\n",
+		`shout_renders_total{kind="static"} 3` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+	// Streams are measured apart from requests
+	if strings.Contains(body, `shout_http_requests_total{method="GET",route="/p/*"`) {
+		t.Error("stream counted as a request")
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "pairs", got: labels("method", "GET", "route", "/date"), want: `{method="GET",route="/date"}`},
+		{name: "escaped", got: labels("route", "a\"b\\c\nd"), want: `{route="a\"b\\c\nd"}`},
+		{name: "with label", got: withLabel(labels("route", "/date"), "le", "0.5"), want: `{route="/date",le="0.5"}`},
+		{name: "with label to none", got: withLabel("", "le", "+Inf"), want: `{le="+Inf"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/quota"
+)
+
+// Quota headers: a client's daily request quota and what is left of it.
+const (
+	HeaderQuotaLimit     = "X-Quota-Limit"
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+	HeaderQuotaReset     = "X-Quota-Reset"
+)
+
+// quotasEnabled reports whether usage is tracked: when API keys are on,
+// as tiers may set quotas, or when SHOUT_QUOTA_* sets one.
+func (h *Handler) quotasEnabled() bool {
+	cfg := h.config()
+	return h.keys != nil || cfg != nil && (cfg.Quota.RequestsPerDay > 0 || cfg.Quota.StreamSecondsPerDay > 0)
+}
+
+// quotaLimiter counts each request against its client's daily quota and
+// refuses it with 429 once the quota is used up. Tracking failures let
+// requests through.
+func (h *Handler) quotaLimiter() fiber.Handler {
+	if h.quotas == nil {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return func(c *fiber.Ctx) error {
+		if c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		client, limit := clientID(c), h.tier(c).RequestsPerDay
+		usage, err := h.quotas.Usage(c.UserContext(), client)
+		if err != nil {
+			log.Printf("Error reading quota usage: %v", err)
+			return c.Next()
+		}
+		if limit > 0 {
+			if usage.Requests >= limit {
+				return apierrors.Send(c, quotaExceeded(c, usage, "%d requests a day", limit))
+			}
+			c.Set(HeaderQuotaLimit, strconv.FormatInt(limit, 10))
+			c.Set(HeaderQuotaRemaining, strconv.FormatInt(limit-usage.Requests-1, 10))
+		}
+		if err := h.quotas.Add(c.UserContext(), client, 1, 0); err != nil {
+			log.Printf("Error recording quota usage: %v", err)
+		}
+		return c.Next()
+	}
+}
+
+// streamQuota returns how long the request's client may still stream
+// today, 0 meaning no limit, or ErrQuotaExceeded if it may not. Exempt
+// clients have no limit.
+func (h *Handler) streamQuota(c *fiber.Ctx, tier *apikey.Tier) (time.Duration, error) {
+	if h.quotas == nil || tier.StreamSecondsPerDay == 0 || h.exempt(c) {
+		return 0, nil
+	}
+	usage, err := h.quotas.Usage(c.UserContext(), clientID(c))
+	if err != nil {
+		log.Printf("Error reading quota usage: %v", err)
+		return 0, nil
+	}
+	left := tier.StreamSecondsPerDay - usage.StreamSeconds
+	if left <= 0 {
+		return 0, quotaExceeded(c, usage, "%d seconds of streaming a day", tier.StreamSecondsPerDay)
+	}
+	return time.Duration(left) * time.Second, nil
+}
+
+// chargeStream records a stream of length d against client's quota,
+// rounded up to whole seconds. It runs after the request, so it takes
+// the client rather than the request context.
+func (h *Handler) chargeStream(client string, d time.Duration) {
+	if h.quotas == nil {
+		return
+	}
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if err := h.quotas.Add(context.Background(), client, 0, seconds); err != nil {
+		log.Printf("Error recording quota usage: %v", err)
+	}
+}
+
+// quotaExceeded returns ErrQuotaExceeded for the limit, and sets
+// Retry-After and X-Quota-Reset (a Unix time) to when the quota frees up.
+func quotaExceeded(c *fiber.Ctx, usage quota.Usage, limit string, args ...any) error {
+	retry := max(int64(time.Until(usage.Reset).Seconds()+1), 1)
+	c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retry, 10))
+	c.Set(HeaderQuotaReset, strconv.FormatInt(usage.Reset.Unix(), 10))
+	return apierrors.ErrQuotaExceeded.WithDetail("your plan allows "+limit, args...).
+		WithHint("try again after " + usage.Reset.UTC().Format(time.RFC3339))
+}
+
+// Quotas lists every client with usage in the last day, as JSON keyed by
+// client: "key:<name>" for API keys and "ip:<address>" otherwise.
+//
+// Example:
+//
+//	curl localhost:9090/quotas
+func (h *Handler) Quotas(c *fiber.Ctx) error {
+	clients, err := h.quotas.Clients(c.UserContext())
+	if err != nil {
+		return quotaStoreFailed(c, err)
+	}
+	usage := make(map[string]quota.Usage, len(clients))
+	for _, client := range clients {
+		if usage[client], err = h.quotas.Usage(c.UserContext(), client); err != nil {
+			return quotaStoreFailed(c, err)
+		}
+	}
+	return c.JSON(usage)
+}
+
+// Quota reports one client's usage in the last day.
+//
+// Example:
+//
+//	curl localhost:9090/quotas/key:acme
+func (h *Handler) Quota(c *fiber.Ctx) error {
+	client, err := url.PathUnescape(c.Params("client"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	usage, err := h.quotas.Usage(c.UserContext(), client)
+	if err != nil {
+		return quotaStoreFailed(c, err)
+	}
+	return c.JSON(usage)
+}
+
+// ResetQuota forgets one client's usage, giving it a full quota again.
+//
+// Example:
+//
+//	curl -X DELETE localhost:9090/quotas/ip:203.0.113.7
+func (h *Handler) ResetQuota(c *fiber.Ctx) error {
+	client, err := url.PathUnescape(c.Params("client"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.quotas.Reset(c.UserContext(), client); err != nil {
+		return quotaStoreFailed(c, err)
+	}
+	return c.JSON(fiber.Map{"status": "reset", "client": client})
+}
+
+// quotaStoreFailed answers an admin request the quota store failed.
+func quotaStoreFailed(c *fiber.Ctx, err error) error {
+	log.Printf("Error reading quotas: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/quota"
+)
+
+func TestRequestQuota(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Quota.RequestsPerDay = 2
+	h = New(Deps{Config: h.config(), Fonts: h.fonts})
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	for _, remaining := range []string{"1", "0"} {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+		}
+		if got := resp.Header.Get(HeaderQuotaRemaining); got != remaining {
+			t.Errorf("%s = %q, want %q", HeaderQuotaRemaining, got, remaining)
+		}
+	}
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil))
+	if resp.StatusCode != 429 || resp.Header.Get(apierrors.HeaderErrorCode) != "quota_exceeded" {
+		t.Fatalf("status = %d, code %q, want 429 quota_exceeded (body %q)", resp.StatusCode, resp.Header.Get(apierrors.HeaderErrorCode), body)
+	}
+	if resp.Header.Get("Retry-After") == "" || resp.Header.Get(HeaderQuotaReset) == "" {
+		t.Errorf("quota_exceeded without reset headers: %v", resp.Header)
+	}
+
+	// The admin port shows the usage and can reset it
+	_, body = doRequest(t, admin, httptest.NewRequest("GET", "/quotas", nil))
+	var usage map[string]quota.Usage
+	if err := json.Unmarshal([]byte(body), &usage); err != nil {
+		t.Fatalf("invalid JSON %q: %v", body, err)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("GET /quotas = %v, want one client", usage)
+	}
+	for client, u := range usage {
+		if u.Requests != 2 {
+			t.Errorf("%s requests = %d, want 2", client, u.Requests)
+		}
+		if resp, body := doRequest(t, admin, httptest.NewRequest("DELETE", "/quotas/"+client, nil)); resp.StatusCode != 200 {
+			t.Fatalf("DELETE /quotas/%s status = %d (body %q)", client, resp.StatusCode, body)
+		}
+	}
+	if resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != 200 {
+		t.Errorf("status after reset = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+}
+
+func TestStreamQuota(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	h.config().Quota.StreamSecondsPerDay = 1
+	app := newTestAppFrom(New(Deps{Config: h.config(), Fonts: h.fonts}))
+
+	if resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/1?f=small", nil)); resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	// The stream is charged a whole second, using up the quota
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/1?f=small", nil))
+	if resp.StatusCode != 429 || resp.Header.Get(apierrors.HeaderErrorCode) != "quota_exceeded" {
+		t.Errorf("second stream status = %d, want 429 quota_exceeded (body %q)", resp.StatusCode, body)
+	}
+}
+
+func TestQuotasNotOnAdminWithoutQuotas(t *testing.T) {
+	admin := fiber.New()
+	newTestHandler(t).RegisterAdmin(admin)
+
+	if resp, _ := doRequest(t, admin, httptest.NewRequest("GET", "/quotas", nil)); resp.StatusCode != 404 {
+		t.Errorf("GET /quotas status = %d, want 404 without quotas", resp.StatusCode)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+// routeLimiters builds a limiter for each route class with a rate limit
+// of its own (SHOUT_RATELIMIT_ROUTES), counting each client, by API key
+// or IP, separately.
+func (h *Handler) routeLimiters() map[string]fiber.Handler {
+	cfg := h.config()
+	limiters := make(map[string]fiber.Handler)
+	if cfg == nil {
+		return limiters
+	}
+	for _, entry := range cfg.RateLimit.Routes {
+		class, limit, _ := strings.Cut(entry, "=")
+		perMinute, err := strconv.Atoi(limit)
+		if err != nil || perMinute <= 0 {
+			continue
+		}
+		limiters[class] = h.unlessExempt(limiter.New(limiter.Config{
+			Next: func(c *fiber.Ctx) bool {
+				return c.Method() == fiber.MethodOptions
+			},
+			Max:          perMinute,
+			Expiration:   time.Minute,
+			KeyGenerator: clientID,
+			LimitReached: func(c *fiber.Ctx) error {
+				return apierrors.Send(c, apierrors.ErrRateLimited.WithDetail("%d %s requests per minute", perMinute, class))
+			},
+		}))
+	}
+	return limiters
+}
+
+// routeLimit returns the limiter of route class, or a pass-through
+// handler when the class has no limit of its own.
+func (h *Handler) routeLimit(class string) fiber.Handler {
+	if limit, ok := h.routeLimits[class]; ok {
+		return limit
+	}
+	return func(c *fiber.Ctx) error { return c.Next() }
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestRouteRateLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []string
+		paths  []string
+		want   []int
+	}{
+		{
+			name:   "render limit",
+			routes: []string{"render=2"},
+			paths:  []string{"/HI", "/v1/HO", "/HEY"},
+			want:   []int{200, 200, 429},
+		},
+		{
+			name:   "classes counted apart",
+			routes: []string{"render=1", "export=1"},
+			paths:  []string{"/HI", "/countdown/0", "/HO"},
+			want:   []int{200, 200, 429},
+		},
+		{
+			name:   "other class's limit",
+			routes: []string{"export=1"},
+			paths:  []string{"/HI", "/HI", "/HI"},
+			want:   []int{200, 200, 200},
+		},
+		{
+			name:   "unlimited routes",
+			routes: []string{"render=1"},
+			paths:  []string{"/help", "/colors", "/help"},
+			want:   []int{200, 200, 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			cfg := *h.config()
+			cfg.RateLimit.Routes = tt.routes
+			app := newTestAppFrom(New(Deps{Config: &cfg, Fonts: h.fonts}))
+			for i, path := range tt.paths {
+				resp, body := doRequest(t, app, httptest.NewRequest("GET", path, nil))
+				if resp.StatusCode != tt.want[i] {
+					t.Fatalf("%s status = %d, want %d (body %q)", path, resp.StatusCode, tt.want[i], body)
+				}
+				if resp.StatusCode == fiber.StatusTooManyRequests && !strings.Contains(body, "requests per minute") {
+					t.Errorf("body = %q, want the route class's limit", body)
+				}
+			}
+		})
+	}
+}
+
+func TestRouteClassesValid(t *testing.T) {
+	for _, class := range config.RouteClasses {
+		cfg, err := config.LoadFromEnv(map[string]string{"SHOUT_RATELIMIT_ROUTES": class + "=1"})
+		if err != nil {
+			t.Fatalf("class %s: %v", class, err)
+		}
+		h := New(Deps{Config: cfg})
+		if _, ok := h.routeLimits[class]; !ok {
+			t.Errorf("class %s has no limiter", class)
+		}
+	}
+}
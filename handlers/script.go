@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// scriptFallbackURL is written into the shell script when the request's
+// host can't be used in one.
+const scriptFallbackURL = "http://localhost:8080"
+
+// scriptURL matches server URLs that are safe to write into the script.
+var scriptURL = regexp.MustCompile(`^https?://[A-Za-z0-9.:\[\]-]+$`)
+
+// shellScript is the shout shell function served at /shout.sh. It is
+// POSIX sh, so it works when sourced by bash, zsh, dash and ksh, and runs
+// in a subshell so its variables don't leak. The text is percent-encoded
+// with awk, which every system with a shell has.
+var shellScript = template.Must(template.New("shout.sh").Parse(`# shout: ASCII art banners from {{.}}, sized for your terminal.
+#
+# Install it in this shell, or add the line to ~/.bashrc or ~/.zshrc:
+#   eval "$(curl -fsS {{.}}/shout.sh)"
+#
+# Usage:
+#   shout TEXT [OPTION=VALUE ...]
+#   shout -p TEXT [OPTION=VALUE ...]   (animated party mode)
+#
+# Options are the query parameters listed by curl {{.}}/help:
+#   shout 'hello world' f=doom c=rainbow
+#
+# Set SHOUT_URL to use another server.
+
+_shout_encode() {
+	LC_ALL=C awk 'BEGIN {
+		for (i = 1; i < 256; i++) ord[sprintf("%c", i)] = i
+		s = ARGV[1]
+		for (i = 1; i <= length(s); i++) {
+			c = substr(s, i, 1)
+			if (c ~ /[A-Za-z0-9._~-]/) out = out c
+			else out = out sprintf("%%%02X", ord[c])
+		}
+		print out
+	}' "$1"
+}
+
+shout() (
+	path=
+	if [ "$1" = "-p" ]; then
+		path=p/
+		shift
+	fi
+	if [ $# -eq 0 ]; then
+		echo "usage: shout [-p] TEXT [OPTION=VALUE ...]" >&2
+		exit 2
+	fi
+
+	url="${SHOUT_URL:-{{.}}}/$path$(_shout_encode "$1")"
+	shift
+	url="$url?cols=${COLUMNS:-$(tput cols 2>/dev/null || echo 0)}&rows=${LINES:-$(tput lines 2>/dev/null || echo 0)}"
+	case "$COLORTERM" in
+	truecolor | 24bit) url="$url&term=truecolor" ;;
+	*) url="$url&term=$(_shout_encode "${TERM:-dumb}")" ;;
+	esac
+	for opt in "$@"; do
+		url="$url&$opt"
+	done
+	curl -sS -- "$url"
+)
+`))
+
+// Script serves a shell function, shout, that sends the terminal's size
+// and type with every request, so output is wrapped, aligned and colored
+// to suit it. The script calls back to the server it was fetched from.
+//
+// Example:
+//
+//	eval "$(curl -fsS localhost:8080/shout.sh)"
+//	shout 'hello world' f=doom
+func (h *Handler) Script(c *fiber.Ctx) error {
+	url := c.BaseURL()
+	if !scriptURL.MatchString(url) {
+		url = scriptFallbackURL
+	}
+
+	var b strings.Builder
+	if err := shellScript.Execute(&b, url); err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(b.String())
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	app := newTestAppFrom(newTestHandler(t))
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "http://shout.example/shout.sh", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	for _, want := range []string{"shout() (", "${SHOUT_URL:-http://shout.example}", "cols=", "rows=", "term="} {
+		if !strings.Contains(body, want) {
+			t.Errorf("script is missing %q", want)
+		}
+	}
+
+	// Hosts that could break out of the script fall back to a fixed URL
+	req := httptest.NewRequest("GET", "/shout.sh", nil)
+	req.Host = "evil.example;rm"
+	if _, unsafe := doRequest(t, app, req); strings.Contains(unsafe, "evil") || !strings.Contains(unsafe, scriptFallbackURL) {
+		t.Error("unsafe host was written into the script")
+	}
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	if out, err := exec.Command(sh, "-n", "-c", body).CombinedOutput(); err != nil {
+		t.Fatalf("script has syntax errors: %v\n%s", err, out)
+	}
+	out, err := exec.Command(sh, "-c", body+"\n_shout_encode \"$1\"", "sh", "héllo world/?&").Output()
+	if err != nil {
+		t.Fatalf("_shout_encode: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(out)), "h%C3%A9llo%20world%2F%3F%26"; got != want {
+		t.Errorf("_shout_encode = %q, want %q", got, want)
+	}
+}
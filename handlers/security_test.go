@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/middleware"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Security = config.SecurityConfig{Headers: true, FrameAncestors: []string{"'self'"}, ReferrerPolicy: "no-referrer", BadgeCSP: "img-src 'self'"}
+	app := newTestAppFrom(h)
+	admin := fiber.New()
+	h.RegisterAdmin(admin)
+
+	tests := []struct {
+		name    string
+		app     *fiber.App
+		target  string
+		accept  string
+		wantCSP string
+	}{
+		{name: "banner", app: app, target: "/HI"},
+		{name: "help page", app: app, target: "/help", accept: "text/html", wantCSP: helpCSP + "; frame-ancestors 'self'"},
+		{name: "help text", app: app, target: "/help"},
+		{name: "configured badge policy", app: app, target: "/badge/build/ok", wantCSP: "img-src 'self'; frame-ancestors 'self'"},
+		{name: "API docs", app: admin, target: "/docs", wantCSP: docsCSP + "; frame-ancestors 'self'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, body := doRequest(t, tt.app, req)
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+			}
+			if got := resp.Header.Get("Content-Security-Policy"); got != tt.wantCSP {
+				t.Errorf("CSP = %q, want %q", got, tt.wantCSP)
+			}
+			if resp.Header.Get("X-Content-Type-Options") != "nosniff" || resp.Header.Get("Referrer-Policy") != "no-referrer" {
+				t.Errorf("missing nosniff or Referrer-Policy: %v", resp.Header)
+			}
+		})
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Signing = config.SigningConfig{Secrets: []string{"s3cret"}, MaxAge: time.Minute}
+	app := newTestAppFrom(h)
+
+	if resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/HI", nil)); resp.StatusCode != 401 || resp.Header.Get("X-Shout-Error") != "bad_signature" {
+		t.Errorf("unsigned status = %d, code %q, want 401 bad_signature", resp.StatusCode, resp.Header.Get("X-Shout-Error"))
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := middleware.SignRequest("s3cret", ts, "/v1/HI", url.Values{"f": {"small"}})
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/v1/HI?f=small&ts="+ts+"&sig="+url.QueryEscape(sig), nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("signed status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+}
+
+func TestBotsTurnedAway(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Bots = config.BotsConfig{Detect: true, Action: "static", Remember: time.Minute}
+	h = New(Deps{Config: h.config(), Fonts: h.fonts})
+	app := newTestAppFrom(h)
+
+	// The catch-all text route would otherwise render the probe
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/wp-login.php", nil))
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Fatalf("probe status = %d, want 403 (body %q)", resp.StatusCode, body)
+	}
+	// but not the prober's next request, nor text naming a probed path
+	for _, target := range []string{"/HI", "/WordPress", "/config.yml"} {
+		if resp, _ := doRequest(t, app, httptest.NewRequest("GET", target, nil)); resp.StatusCode != fiber.StatusOK {
+			t.Errorf("%s status after a probe = %d, want 200", target, resp.StatusCode)
+		}
+	}
+	if got := h.metrics.Snapshot().Bots.Probe; got != 1 {
+		t.Errorf("ProbeRequests = %d, want 1", got)
+	}
+}
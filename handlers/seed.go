@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// HeaderSeed reports the seed behind a response's random choices; sending
+// it back as ?seed= reproduces them.
+const HeaderSeed = "X-Shout-Seed"
+
+// randKey caches the random source of a request.
+const randKey = "shout.rand"
+
+// randomFont is the font option value that picks one of the loaded fonts.
+const randomFont = "random"
+
+// seedParam documents ?seed= on the routes that make random choices.
+var seedParam = optionHelp{Names: []string{"seed"}, Help: "Seed for random choices such as font=random, so identical requests give identical output", Type: "string"}
+
+// requestRand returns the random source for every random choice in the
+// request: the random font, a random quote, animation phase and matrix
+// rain. ?seed= fixes it, as a number or any other string, which is hashed;
+// without one, a seed is picked. The seed is reported in X-Shout-Seed.
+func (h *Handler) requestRand(c *fiber.Ctx) *rand.Rand {
+	if r, ok := c.Locals(randKey).(*rand.Rand); ok {
+		return r
+	}
+	seed, ok := shout.ParseSeed(c.Query("seed"))
+	if !ok {
+		seed = rand.Uint64()
+	}
+	r := shout.NewRand(seed)
+	c.Locals(randKey, r)
+	c.Set(HeaderSeed, strconv.FormatUint(seed, 10))
+	return r
+}
+
+// requestFont returns the font to render with: font, or
+// SHOUT_FONTS_DEFAULT if empty. font=random picks one of the loaded fonts
+// with the request's random source; unless ?seed= is given the output
+// differs every time, so it isn't cached publicly.
+func (h *Handler) requestFont(c *fiber.Ctx, font string) string {
+	cfg := h.config()
+	if font == "" && cfg != nil {
+		return cfg.Fonts.Default
+	}
+	if font != randomFont {
+		return font
+	}
+	fonts := h.fonts.ListFonts()
+	if len(fonts) == 0 {
+		return h.defaultFont()
+	}
+	if c.Query("seed") == "" {
+		c.Locals(middleware.DynamicKey, true)
+	}
+	return fonts[h.requestRand(c).IntN(len(fonts))]
+}
+
+// seededScheme seeds the random patterns of a color preset, such as matrix
+// rain, from the request's random source. Other schemes are returned as is.
+func (h *Handler) seededScheme(c *fiber.Ctx, scheme render.ColorScheme) render.ColorScheme {
+	if preset, ok := scheme.(*render.PresetScheme); ok {
+		return preset.WithSeed(h.requestRand(c).Uint64())
+	}
+	return scheme
+}
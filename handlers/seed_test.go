@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSeedReproducesRandomFont(t *testing.T) {
+	app := newTestAppFrom(newTestHandler(t))
+
+	// Without a seed, one is picked and reported
+	resp, first := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=random", nil))
+	seed := resp.Header.Get(HeaderSeed)
+	if resp.StatusCode != 200 || seed == "" {
+		t.Fatalf("status = %d, seed = %q; want 200 with a seed", resp.StatusCode, seed)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != dynamicCacheControl {
+		t.Errorf("Cache-Control = %q, want %q for an unseeded random font", got, dynamicCacheControl)
+	}
+
+	// Sending it back gives the same banner
+	resp, again := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=random&seed="+seed, nil))
+	if again != first || resp.Header.Get(HeaderSeed) != seed {
+		t.Errorf("seed %s gave a different banner", seed)
+	}
+	if got := resp.Header.Get("Cache-Control"); got == dynamicCacheControl {
+		t.Error("seeded renders should be cacheable")
+	}
+
+	// Every seed picks a loaded font, and seeds don't all pick the same one
+	banners := map[string]bool{}
+	for _, seed := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "party", "shout"} {
+		_, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=random&seed="+seed, nil))
+		banners[body] = true
+	}
+	if len(banners) < 2 {
+		t.Errorf("ten seeds picked %d fonts, want more than one", len(banners))
+	}
+}
+
+func TestSeedReproducesFortune(t *testing.T) {
+	app := newTestAppFrom(newTestHandler(t))
+
+	quotes := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		_, body := doRequest(t, app, httptest.NewRequest("GET", "/fortune?seed=42", nil))
+		quotes[body] = true
+	}
+	if len(quotes) != 1 {
+		t.Errorf("seed 42 gave %d different quotes, want 1", len(quotes))
+	}
+}
+
+func TestSeedReproducesAnimation(t *testing.T) {
+	app := newTestAppFrom(newPartyHandler(t))
+
+	events := func(query string) [][]any {
+		_, body := doRequest(t, app, httptest.NewRequest("GET", "/p/HI?f=small&format=cast&t=1&"+query, nil))
+		_, events := parseCast(t, body)
+		return events
+	}
+	for _, c := range []string{"rainbow", "matrix"} {
+		a, b := events("c="+c+"&seed=7"), events("c="+c+"&seed=7")
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("c=%s: seed 7 gave different animations", c)
+		}
+		if reflect.DeepEqual(a, events("c="+c+"&seed=8")) {
+			t.Errorf("c=%s: seeds 7 and 8 gave the same animation", c)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/shortlink"
+)
+
+// maxSpecBytes caps the JSON body of a short link request.
+const maxSpecBytes = 4 << 10
+
+// CreateShortlink saves the text and options in the JSON body and returns
+// a short link that replays them. JSON clients get the ID, URL and expiry
+// as JSON; everyone else gets the URL on a line of its own.
+//
+// Example:
+//
+//	curl -d '{"text":"HELLO","font":"doom","color":"fire"}' localhost:8080/s
+func (h *Handler) CreateShortlink(c *fiber.Ctx) error {
+	cfg := h.config()
+	if len(c.Body()) > maxSpecBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxSpecBytes))
+	}
+
+	var spec shortlink.Spec
+	if err := json.Unmarshal(c.Body(), &spec); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+
+	text, err := middleware.CheckText(spec.Text, h.textConfig())
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if text == "" {
+		return apierrors.Send(c, apierrors.ErrNoText)
+	}
+	if _, err := middleware.FilterText(h.wordFilter(), h.textConfig().Filter, text, h.metrics); err != nil {
+		return apierrors.Send(c, err)
+	}
+	spec.Text = text
+
+	if spec.Font == "" && cfg != nil {
+		spec.Font = cfg.Fonts.Default
+	}
+	suggestion := h.suggestFont(c, spec.Font)
+	if err := h.validateOptions(spec.RenderOptions); err != nil {
+		return apierrors.Send(c, err.WithHint(suggestion))
+	}
+	// Save the font actually used, so replays don't depend on fuzzy matching
+	font := h.fonts.ResolveFont(spec.Font, h.defaultFont())
+	if font == nil {
+		return apierrors.Send(c, apierrors.ErrFontNotFound.WithDetail("%q", spec.Font))
+	}
+	spec.Font = font.Name
+
+	ttl := cfg.Shortlinks.TTL
+	id, err := shortlink.Create(c.UserContext(), h.shortlinks, spec, ttl)
+	if err != nil {
+		log.Printf("Error saving short link: %v", err)
+		return apierrors.Send(c, err)
+	}
+
+	link := c.BaseURL() + "/s/" + id
+	c.Status(fiber.StatusCreated)
+	c.Location(link)
+	if strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMEApplicationJSON) {
+		return c.JSON(fiber.Map{
+			"id":        id,
+			"url":       link,
+			"expiresAt": time.Now().Add(ttl).UTC().Format(time.RFC3339),
+		})
+	}
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(link + "\n")
+}
+
+// Shortlink replays a saved shout with its stored options; query
+// parameters are ignored. The current word filter still applies.
+//
+// Example:
+//
+//	curl localhost:8080/s/k3Rt9wXa
+func (h *Handler) Shortlink(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !shortlink.ValidID(id) {
+		return apierrors.Send(c, apierrors.ErrShortlinkNotFound)
+	}
+
+	spec, err := h.shortlinks.Load(c.UserContext(), id)
+	if errors.Is(err, shortlink.ErrNotFound) {
+		return apierrors.Send(c, apierrors.ErrShortlinkNotFound)
+	}
+	if err != nil {
+		log.Printf("Error loading short link %s: %v", id, err)
+		return apierrors.Send(c, err)
+	}
+
+	masked, err := middleware.FilterText(h.wordFilter(), h.textConfig().Filter, spec.Text, h.metrics)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	return h.renderStatic(c, spec.Text, spec.RenderOptions, masked)
+}
+
+// specOptions checks the text and options of a posted spec as a static
+// render would, and returns the text with the options to draw it with and
+// the font suggestion for errors.
+func (h *Handler) specOptions(c *fiber.Ctx, spec shortlink.Spec) (string, shout.Options, string, error) {
+	text, err := middleware.CheckText(spec.Text, h.textConfig())
+	if err != nil {
+		return "", shout.Options{}, "", err
+	}
+	if text == "" {
+		return "", shout.Options{}, "", apierrors.ErrNoText
+	}
+	masked, err := middleware.FilterText(h.wordFilter(), h.textConfig().Filter, text, h.metrics)
+	if err != nil {
+		return "", shout.Options{}, "", err
+	}
+
+	opts := spec.RenderOptions
+	opts.Font = h.requestFont(c, opts.Font)
+	suggestion := h.suggestFont(c, opts.Font)
+	if err := h.validateOptions(opts); err != nil {
+		return "", shout.Options{}, suggestion, err.WithHint(suggestion)
+	}
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return "", shout.Options{}, suggestion, apierrors.ErrBadOption.WithDetail("%v", err).WithHint(suggestion)
+	}
+	font := h.fonts.ResolveFont(opts.Font, h.defaultFont())
+	if font == nil {
+		return "", shout.Options{}, suggestion, apierrors.ErrFontNotFound.WithDetail("%q", opts.Font)
+	}
+	return text, shout.Options{
+		Font:       font.Name,
+		Engine:     opts.Engine,
+		Scale:      opts.Scale,
+		Fill:       opts.Fill,
+		Hardblank:  opts.Hardblank,
+		Color:      opts.Color,
+		Scheme:     scheme,
+		Align:      h.alignment(opts),
+		Border:     opts.Border,
+		Padding:    opts.Padding,
+		Accent:     opts.Accent,
+		Background: opts.Background,
+		Cols:       opts.MaxWidth,
+		Masked:     masked,
+	}, suggestion, nil
+}
+
+// shortlinkLimiter limits how many short links a client may create per
+// minute (SHOUT_SHORTLINK_CREATES_PER_MINUTE), or returns a pass-through
+// handler when there is no limit.
+func (h *Handler) shortlinkLimiter() fiber.Handler {
+	cfg := h.config()
+	if cfg == nil || cfg.Shortlinks.CreatesPerMinute == 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return limiter.New(limiter.Config{
+		Max:        cfg.Shortlinks.CreatesPerMinute,
+		Expiration: time.Minute,
+		LimitReached: func(c *fiber.Ctx) error {
+			return apierrors.Send(c, apierrors.ErrRateLimited)
+		},
+	})
+}
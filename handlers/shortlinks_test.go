@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/shortlink"
+)
+
+// newShortlinkApp builds a public app with an in-memory short link store.
+func newShortlinkApp(t *testing.T, createsPerMinute int, filter *render.WordFilter) *fiber.App {
+	t.Helper()
+
+	base := newTestHandler(t)
+	base.config().Shortlinks = config.ShortlinkConfig{Enabled: true, TTL: time.Hour, CreatesPerMinute: createsPerMinute}
+	if filter != nil {
+		base.config().Text.Filter = render.FilterReject
+	}
+	return newTestAppFrom(New(Deps{
+		Config:     base.config(),
+		Fonts:      base.fonts,
+		Filter:     filter,
+		Shortlinks: shortlink.NewMemoryStore(100),
+	}))
+}
+
+// createShortlink posts body to /s and returns the response and body.
+func createShortlink(t *testing.T, app *fiber.App, body, accept string) (int, string) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/s", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, respBody := doRequest(t, app, req)
+	return resp.StatusCode, respBody
+}
+
+func TestShortlinkRoundTrip(t *testing.T) {
+	app := newShortlinkApp(t, 0, nil)
+
+	static, wantBody := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small", nil))
+	if static.StatusCode != 200 {
+		t.Fatalf("static status = %d", static.StatusCode)
+	}
+
+	status, body := createShortlink(t, app, `{"text":"HI","font":"smal"}`, "application/json")
+	if status != 201 {
+		t.Fatalf("create status = %d, want 201 (body %q)", status, body)
+	}
+	var created struct {
+		ID        string `json:"id"`
+		URL       string `json:"url"`
+		ExpiresAt string `json:"expiresAt"`
+	}
+	if err := json.Unmarshal([]byte(body), &created); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !shortlink.ValidID(created.ID) || !strings.HasSuffix(created.URL, "/s/"+created.ID) || created.ExpiresAt == "" {
+		t.Errorf("unexpected response: %+v", created)
+	}
+
+	// The stored spec replays with the resolved font, ignoring query parameters
+	resp, replay := doRequest(t, app, httptest.NewRequest("GET", "/s/"+created.ID+"?f=doom", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("replay status = %d, want 200", resp.StatusCode)
+	}
+	if replay != wantBody {
+		t.Errorf("replay differs from static render:\n%s\nwant:\n%s", replay, wantBody)
+	}
+
+	// Plain text clients get the URL on its own line
+	status, body = createShortlink(t, app, `{"text":"HI"}`, "")
+	if status != 201 || !strings.Contains(body, "/s/") || !strings.HasSuffix(body, "\n") {
+		t.Errorf("plain create = %d %q", status, body)
+	}
+}
+
+func TestShortlinkErrors(t *testing.T) {
+	app := newShortlinkApp(t, 0, render.NewWordFilter([]string{"darn"}))
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid JSON", body: `{"text":`, wantStatus: 400, wantCode: "bad_option"},
+		{name: "no text", body: `{"text":"  "}`, wantStatus: 400, wantCode: "no_text"},
+		{name: "too long", body: `{"text":"` + strings.Repeat("A", 21) + `"}`, wantStatus: 400, wantCode: "text_too_long"},
+		{name: "blocked word", body: `{"text":"DARN"}`, wantStatus: 400, wantCode: "blocked_text"},
+		{name: "bad color", body: `{"text":"HI","color":"notacolor"}`, wantStatus: 400, wantCode: "bad_option"},
+		{name: "body too large", body: `{"text":"HI","border":"` + strings.Repeat("x", maxSpecBytes) + `"}`, wantStatus: 413, wantCode: "body_too_large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/s", strings.NewReader(tt.body))
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get("X-Shout-Error"); got != tt.wantCode {
+				t.Errorf("X-Shout-Error = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+
+	for _, path := range []string{"/s/abcd2345", "/s/not-an-id"} {
+		resp, _ := doRequest(t, app, httptest.NewRequest("GET", path, nil))
+		if resp.StatusCode != 404 || resp.Header.Get("X-Shout-Error") != "shortlink_not_found" {
+			t.Errorf("GET %s = %d %q, want 404 shortlink_not_found", path, resp.StatusCode, resp.Header.Get("X-Shout-Error"))
+		}
+	}
+}
+
+func TestShortlinkRateLimit(t *testing.T) {
+	app := newShortlinkApp(t, 2, nil)
+
+	for i := 0; i < 2; i++ {
+		if status, body := createShortlink(t, app, `{"text":"HI"}`, ""); status != 201 {
+			t.Fatalf("create %d status = %d (body %q)", i, status, body)
+		}
+	}
+	// The versioned route shares the limit
+	req := httptest.NewRequest("POST", "/v1/s", strings.NewReader(`{"text":"HI"}`))
+	resp, _ := doRequest(t, app, req)
+	if resp.StatusCode != 429 || resp.Header.Get("X-Shout-Error") != "rate_limited" {
+		t.Errorf("third create = %d %q, want 429 rate_limited", resp.StatusCode, resp.Header.Get("X-Shout-Error"))
+	}
+}
+
+func TestShortlinksDisabled(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("POST", "/s", strings.NewReader(`{"text":"HI"}`)))
+	if resp.StatusCode == 201 {
+		t.Error("POST /s must not be served without a store")
+	}
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Static output formats, chosen with ?format=. The formats drawn from the
+// text banner (json, html and svg) are in negotiate.go.
+const (
+	formatPlain        = ""
+	formatDiscord      = "discord"
+	formatGHA          = "gha"
+	formatGHASummary   = "gha-summary"
+	formatMarkdown     = "md"
+	formatMarkdownANSI = "md-ansi"
+)
+
+// staticFormatParam documents ?format= on the static routes.
+var staticFormatParam = optionHelp{Names: []string{"format"}, Help: "text (the default, unless the Accept header asks for another) sends the banner as is; json as JSON with its size; html as a web page; svg as an image; discord wraps the banner in ansi code blocks of at most 2000 characters, in Discord's colors; gha in a GitHub Actions log group; gha-summary in an HTML pre block for $GITHUB_STEP_SUMMARY; md in a Markdown code block without colors; md-ansi in an ansi code block that keeps them", Type: "string"}
+
+// staticFormat returns the requested ?format=, or an error if it isn't a
+// static format. Without ?format=, the format is negotiated from the
+// Accept header when negotiate is set (negotiateFormat), and plain text
+// otherwise.
+func staticFormat(c *fiber.Ctx, negotiate bool) (string, error) {
+	switch format := c.Query("format"); format {
+	case formatPlain:
+		if negotiate {
+			return negotiateFormat(c)
+		}
+		return formatPlain, nil
+	case formatText:
+		return formatPlain, nil
+	case formatJSON, formatHTML, formatSVG, formatDiscord, formatGHA, formatGHASummary, formatMarkdown, formatMarkdownANSI:
+		return format, nil
+	default:
+		return "", apierrors.ErrBadOption.WithDetail("unknown format %q", format).WithHint("use format=text, json, html, svg, discord, gha, gha-summary, md or md-ansi")
+	}
+}
+
+// Static renders the text in the request path as a static ASCII art banner.
+// The text is read from middleware.InputValidation, which must run first.
+//
+// Example:
+//
+//	GET /HELLO+WORLD?font=doom&color=%23ff8800
+func (h *Handler) Static(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+
+	text := middleware.Text(c)
+	if text == "" {
+		return apierrors.Send(c, apierrors.ErrNoText)
+	}
+
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	return h.renderStatic(c, text, opts, middleware.Masked(c))
+}
+
+// renderStatic renders text with opts as a static banner, masking the given
+// word ranges, and sends it with cache headers in the ?format= format.
+// Conditional requests that still match are answered with 304 without
+// rendering.
+func (h *Handler) renderStatic(c *fiber.Ctx, text string, opts types.RenderOptions, masked []render.WordRange) error {
+	banner, err := h.prepareStatic(c, text, opts, masked, true)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	c.Set(HeaderFont, banner.font.Name)
+	if banner.etag != "" && etagMatches(c, banner.etag) {
+		h.setCacheHeaders(c, banner.etag)
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx, cancel := h.renderContext(c, banner.opts.Timeout)
+	defer cancel()
+	output, rendered, err := h.drawStatic(ctx, banner)
+	if err != nil {
+		return h.renderFailed(c, err, banner.suggestion)
+	}
+	body, contentType := []byte(output), textPlain
+	if convertedFormat(banner.format) {
+		if body, contentType, err = h.convertStatic(banner, output); err != nil {
+			return h.renderFailed(c, err, banner.suggestion)
+		}
+	}
+	body = banner.download.apply(c, banner, body)
+	h.countUsage(banner.font.Name, banner.opts.Color, "", false)
+	h.setCacheHeaders(c, banner.etag)
+	if h.cache != nil && banner.etag != "" {
+		c.Set(HeaderCache, cacheHit)
+		if rendered {
+			c.Set(HeaderCache, cacheMiss)
+		}
+	}
+	setOutputSize(c, output)
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
+
+// staticBanner is a static banner as the request asks for it, resolved
+// and checked but not yet drawn.
+type staticBanner struct {
+	text   string
+	opts   types.RenderOptions
+	masked []render.WordRange
+	font   *render.Font
+	scheme render.ColorScheme
+	// fallback is what the banner draws differently from the request
+	fallback fallback
+	format   string
+	term     terminal
+	align    string
+	// attribution is the server's footer (SHOUT_TEXT_FOOTER), drawn
+	// aligned below the banner
+	attribution string
+	// footer is the virtual host's footer and the fallback note, added
+	// below the banner as they are
+	footer string
+	// download is how the banner is sent as a file, if it is
+	download download
+	// etag identifies the output, "" when it couldn't be computed
+	etag string
+	// cacheKey keys the text banner in the render cache: the ETag, or
+	// for formats converted from the text, the ETag of the text
+	cacheKey string
+	// suggestion is the hint for a misspelled font, added to errors
+	suggestion string
+}
+
+// prepareStatic resolves the font, colors, terminal and ?format= of a
+// static banner of text, and checks them, without drawing it. With
+// negotiate set, the Accept header chooses the format when ?format=
+// doesn't. Its errors are ready for apierrors.Send.
+func (h *Handler) prepareStatic(c *fiber.Ctx, text string, opts types.RenderOptions, masked []render.WordRange, negotiate bool) (*staticBanner, error) {
+	opts.Font = h.requestFont(c, opts.Font)
+	suggestion := h.suggestFont(c, opts.Font)
+
+	if err := h.validateOptions(opts); err != nil {
+		return nil, err.WithHint(suggestion)
+	}
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return nil, apierrors.ErrBadOption.WithDetail("%v", err).WithHint(suggestion)
+	}
+
+	font := h.fonts.EngineFont(opts, h.defaultFont())
+	if font == nil {
+		return nil, apierrors.ErrFontNotFound.WithDetail("%q", opts.Font)
+	}
+	ascii, err := asciiOnly(c)
+	if err != nil {
+		return nil, err
+	}
+	if ascii {
+		text = render.Transliterate(text)
+	}
+	fb, err := h.checkFallback(engineFontRequest(opts), font, text)
+	if err != nil {
+		return nil, h.renderError(err, suggestion)
+	}
+	if err := strictFallback(c, fb, suggestion); err != nil {
+		return nil, err
+	}
+
+	format, err := staticFormat(c, negotiate)
+	if err != nil {
+		return nil, err
+	}
+	if format == formatSVG {
+		if err := h.entitled(c, apikey.FeatureSVG); err != nil {
+			return nil, err
+		}
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return nil, err
+	}
+	c.Vary(HeaderTerminalSize)
+	switch format {
+	case formatDiscord:
+		term.colors = render.Discord
+	case formatGHASummary, formatMarkdown:
+		term.colors = render.NoColor
+	}
+	attribution, err := h.serverFooter(c)
+	if err != nil {
+		return nil, err
+	}
+	footer := joinLines(hostFooter(c), fallbackNote(c, fb))
+	if term.ascii = ascii; ascii {
+		term.colors, footer = render.NoColor, render.ASCIIOnly(footer)
+	}
+	banner := &staticBanner{
+		text:        text,
+		opts:        opts,
+		masked:      masked,
+		font:        font,
+		scheme:      scheme,
+		fallback:    fb,
+		format:      format,
+		term:        term,
+		align:       h.alignment(opts),
+		footer:      footer,
+		attribution: attribution,
+		download:    requestDownload(c),
+		suggestion:  suggestion,
+	}
+
+	// Output is deterministic, so conditional requests skip rendering
+	banner.etag, err = h.staticETag(text, opts, font, masked, term, banner.align, format, banner.attribution, banner.footer)
+	if err != nil {
+		log.Printf("Error computing ETag: %v", err)
+	}
+	banner.cacheKey = banner.etag
+	if convertedFormat(format) && banner.etag != "" {
+		banner.cacheKey, _ = h.staticETag(text, opts, font, masked, term, banner.align, formatPlain, banner.attribution, banner.footer)
+	}
+	banner.etag = banner.download.etag(banner.etag)
+	return banner, nil
+}
+
+// drawStatic draws a prepared banner as text, in its ?format= unless that
+// is converted from the text, or takes it from the render cache. It
+// reports whether the banner was drawn rather than cached.
+func (h *Handler) drawStatic(ctx context.Context, banner *staticBanner) (output string, rendered bool, err error) {
+	// The ETag covers everything the output depends on, so it keys the
+	// render cache too
+	output, err = h.cache.Get(ctx, banner.cacheKey, func() (string, error) {
+		rendered = true
+		start := time.Now()
+		output, err := h.shout.Render(ctx, banner.text, shout.Options{
+			Font:       banner.font.Name,
+			Engine:     banner.opts.Engine,
+			Scale:      banner.opts.Scale,
+			Fill:       banner.opts.Fill,
+			Hardblank:  banner.opts.Hardblank,
+			Scheme:     banner.scheme,
+			Align:      banner.align,
+			Border:     banner.opts.Border,
+			Padding:    banner.opts.Padding,
+			Accent:     banner.opts.Accent,
+			Background: banner.opts.Background,
+			Footer:     banner.attribution,
+			Cols:       banner.term.cols,
+			Colors:     banner.term.colors,
+			Masked:     banner.masked,
+		})
+		if err != nil {
+			return "", err
+		}
+		if banner.term.ascii {
+			output = render.ASCIIOnly(output)
+		}
+		h.metrics.ObserveRenderDuration(time.Since(start))
+		return staticOutput(output, banner.format, banner.footer), nil
+	})
+	return output, rendered, err
+}
+
+// staticOutput adds footer below a rendered banner and wraps it in the
+// ?format= format.
+func staticOutput(output, format, footer string) string {
+	if footer != "" {
+		output += footer + "\n"
+	}
+	switch format {
+	case formatDiscord:
+		output = strings.Join(discordMessages(output), "\n") + "\n"
+	case formatGHA:
+		output = ghaLog(output)
+	case formatGHASummary:
+		output = ghaSummary(output)
+	case formatMarkdown:
+		output = markdownBlock(output, markdownPlainInfo)
+	case formatMarkdownANSI:
+		output = markdownBlock(output, markdownANSIInfo)
+	}
+	return output
+}
+
+// hostFooter returns the footer of the request's virtual host, added
+// below static banners, or "" if it has none.
+func hostFooter(c *fiber.Ctx) string {
+	if host := middleware.Host(c); host != nil {
+		return host.Footer
+	}
+	return ""
+}
+
+// joinLines joins the lines that aren't empty with newlines.
+func joinLines(lines ...string) string {
+	return strings.Join(slices.DeleteFunc(lines, func(line string) bool { return line == "" }), "\n")
+}
+
+// renderFailed answers a static render that failed with err, suggesting
+// a font where the request named an unknown one.
+func (h *Handler) renderFailed(c *fiber.Ctx, err error, suggestion string) error {
+	return apierrors.Send(c, h.renderError(err, suggestion))
+}
+
+// renderError counts and logs a static render that failed with err, and
+// returns the error to answer it with.
+func (h *Handler) renderError(err error, suggestion string) *apierrors.Error {
+	h.metrics.IncError()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apierrors.ErrRenderTimeout.
+			WithHint("try shorter text, fewer lines or a smaller font").
+			WithHint(suggestion)
+	}
+	log.Printf("Error generating ASCII: %v", err)
+	return apierrors.ErrRenderFailed.WithHint(suggestion)
+}
+
+// renderContext returns a context bounded by the static render budget
+// (SHOUT_SERVER_STATIC_TIMEOUT_MS). A positive timeout option, in seconds,
+// can shorten the budget but never extend it.
+func (h *Handler) renderContext(c *fiber.Ctx, timeoutOpt int) (context.Context, context.CancelFunc) {
+	cfg := h.config()
+	var budget time.Duration
+	if cfg != nil {
+		budget = time.Duration(cfg.Server.StaticTimeoutMS) * time.Millisecond
+	}
+	if opt := time.Duration(timeoutOpt) * time.Second; opt > 0 && (budget == 0 || opt < budget) {
+		budget = opt
+	}
+
+	if budget == 0 {
+		return context.WithCancel(c.UserContext())
+	}
+	return context.WithTimeout(c.UserContext(), budget)
+}
+
+// suggestFont checks whether the requested font exists and, if it doesn't
+// but a similar font does, sets the X-Shout-Font-Suggestion header. It
+// returns a hint to add to error responses, or "" if there is nothing to
+// suggest.
+func (h *Handler) suggestFont(c *fiber.Ctx, font string) string {
+	if _, exists := h.fonts.GetFont(font); exists {
+		return ""
+	}
+	suggestion, ok := h.fonts.SuggestFont(font)
+	if !ok {
+		return ""
+	}
+	c.Set(HeaderFontSuggestion, suggestion)
+	return "Did you mean font=" + suggestion + "?"
+}
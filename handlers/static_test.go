@@ -0,0 +1,671 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/rendercache"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestStatic(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		check      func(t *testing.T, body string)
+	}{
+		{
+			name:       "renders text",
+			target:     "/HI",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				if len(strings.Split(body, "\n")) < 3 {
+					t.Errorf("expected multi-line banner, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "hex color",
+			target:     "/HI?c=%23ff8800",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				if !strings.Contains(body, "\033[38;2;255;136;0m") {
+					t.Errorf("expected truecolor sequence, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "rgb color",
+			target:     "/HI?color=rgb(1,2,3)",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				if !strings.Contains(body, "\033[38;2;1;2;3m") {
+					t.Errorf("expected truecolor sequence, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "named color",
+			target:     "/HI?c=tomato",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				if !strings.Contains(body, "\033[38;2;255;99;71m") {
+					t.Errorf("expected tomato color sequence, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "invalid color",
+			target:     "/HI?c=notacolor",
+			wantStatus: 400,
+			check: func(t *testing.T, body string) {
+				if !strings.Contains(body, "invalid color") {
+					t.Errorf("expected invalid color error, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "color list per word",
+			target:     "/HI+YOU?c=red,blue",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				if !strings.Contains(body, "\033[38;2;255;0;0m") || !strings.Contains(body, "\033[38;2;0;0;255m") {
+					t.Errorf("expected both list colors, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "color list per line",
+			target:     "/HI%0AYOU?c=red,blue&cmode=line",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				lines := strings.Split(body, "\n")
+				if !strings.Contains(lines[0], "\033[38;2;255;0;0m") {
+					t.Errorf("first banner line should be red, got %q", lines[0])
+				}
+				if !strings.Contains(body, "\033[38;2;0;0;255m") {
+					t.Errorf("second banner line should be blue, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "color list per drawn character",
+			target:     "/I?c=red,yellow,green&cmode=char",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				// A single letter takes every color of the list
+				for _, color := range []string{"\033[38;2;255;0;0m", "\033[38;2;255;255;0m", "\033[38;2;0;128;0m"} {
+					if !strings.Contains(body, color) {
+						t.Errorf("expected %q in a one-letter banner, got %q", color, body)
+					}
+				}
+			},
+		},
+		{
+			name:       "vertical gradient",
+			target:     "/I?c=gradient:f00-ff0-0f0&gdir=v",
+			wantStatus: 200,
+			check: func(t *testing.T, body string) {
+				// The top row takes the first stop and the bottom row the last
+				lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+				if !strings.Contains(lines[0], "\033[38;2;255;0;0m") {
+					t.Errorf("expected the first stop on the top row, got %q", lines[0])
+				}
+				if !strings.Contains(body, "\033[38;2;0;255;0m") {
+					t.Errorf("expected the last stop in the banner, got %q", body)
+				}
+			},
+		},
+		{
+			name:       "invalid gradient",
+			target:     "/HI?c=gradient:f00",
+			wantStatus: 400,
+		},
+		{
+			name:       "invalid gradient direction",
+			target:     "/HI?c=gradient:f00-0f0&gdir=up",
+			wantStatus: 400,
+		},
+		{
+			name:       "invalid color mode",
+			target:     "/HI?c=red,blue&cmode=pixel",
+			wantStatus: 400,
+		},
+		{
+			name:       "non-ascii text does not crash",
+			target:     "/%E4%B8%96",
+			wantStatus: 200,
+		},
+		{
+			name:       "only whitespace",
+			target:     "/%20%20",
+			wantStatus: 400,
+		},
+		{
+			name:       "only control characters",
+			target:     "/%1B%5B2J%07",
+			wantStatus: 400,
+		},
+		{
+			name:       "text too long",
+			target:     "/" + strings.Repeat("A", 21),
+			wantStatus: 400,
+			check: func(t *testing.T, body string) {
+				if !strings.Contains(body, "max 20 characters") {
+					t.Errorf("expected length error, got %q", body)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.check != nil {
+				tt.check(t, body)
+			}
+		})
+	}
+}
+
+func TestStaticFontSuggestion(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name           string
+		target         string
+		wantStatus     int
+		wantSuggestion string
+		wantBodyLine   bool
+	}{
+		{name: "exact font", target: "/HI?f=doom", wantStatus: 200},
+		{name: "misspelled font", target: "/HI?f=dooom", wantStatus: 200, wantSuggestion: "doom"},
+		{name: "font prefix", target: "/HI?font=sta", wantStatus: 200, wantSuggestion: "standard"},
+		{name: "unrelated font", target: "/HI?f=zzzzzz", wantStatus: 200},
+		{name: "suggestion in error", target: "/HI?f=smal&c=notacolor", wantStatus: 400, wantSuggestion: "small", wantBodyLine: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(HeaderFontSuggestion); got != tt.wantSuggestion {
+				t.Errorf("%s = %q, want %q", HeaderFontSuggestion, got, tt.wantSuggestion)
+			}
+			if hasLine := strings.Contains(body, "Did you mean font="+tt.wantSuggestion+"?"); hasLine != tt.wantBodyLine {
+				t.Errorf("suggestion line present = %v, want %v (body %q)", hasLine, tt.wantBodyLine, body)
+			}
+		})
+	}
+}
+
+func TestStaticWordFilterMask(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Text.Filter = render.FilterMask
+	h.filter.Store(render.NewWordFilter([]string{"bad"}))
+	app := fiber.New()
+	h.Register(app)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/BAD", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	if !strings.ContainsRune(body, render.MaskRune) {
+		t.Errorf("expected masked output, got %q", body)
+	}
+	if strings.Trim(body, string(render.MaskRune)+" \n") != "" {
+		t.Errorf("expected only mask cells, got %q", body)
+	}
+}
+
+func TestStaticRenderTimeout(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Text.MaxLength = 0
+	h.config().Server.StaticTimeoutMS = 1
+	app := fiber.New(fiber.Config{ReadBufferSize: 1 << 20})
+	h.Register(app)
+
+	// Thousands of banner lines can't be drawn within a millisecond
+	target := "/" + strings.Repeat("WIDE+TEXT%0A", 20000) + "?f=doom"
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", target, nil))
+	if resp.StatusCode != 503 {
+		t.Fatalf("status = %d, want 503", resp.StatusCode)
+	}
+	if !strings.Contains(body, "Hint:") {
+		t.Errorf("expected a hint in the response, got %q", body)
+	}
+}
+
+func TestStaticConfigDefaults(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Fonts.Default = "doom"
+	h.config().Text.DefaultColor = "fire"
+	h.config().Text.DefaultColorMode = "char"
+	h.config().Text.DefaultAlign = "left"
+	app := newTestAppFrom(h)
+	plain := newTestApp(t)
+
+	tests := []struct {
+		name   string
+		target string
+		// same is a request to a server without defaults with the same output
+		same string
+	}{
+		{name: "config defaults", target: "/HI", same: "/HI?f=doom&c=fire&cmode=char&a=left"},
+		{name: "request options win", target: "/HI?f=small&c=ocean&cmode=line", same: "/HI?f=small&c=ocean&cmode=line&a=left"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200: %s", resp.StatusCode, body)
+			}
+			_, want := doRequest(t, plain, httptest.NewRequest("GET", tt.same, nil))
+			if body != want {
+				t.Errorf("body =\n%s\nwant\n%s", body, want)
+			}
+		})
+	}
+}
+
+func TestRenderContextBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeoutMS int
+		option    int
+		want      time.Duration
+	}{
+		{name: "route budget", timeoutMS: 2000, want: 2 * time.Second},
+		{name: "option shortens budget", timeoutMS: 5000, option: 1, want: time.Second},
+		{name: "option cannot extend budget", timeoutMS: 2000, option: 60, want: 2 * time.Second},
+		{name: "option without route budget", option: 3, want: 3 * time.Second},
+		{name: "no budget", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			h.config().Server.StaticTimeoutMS = tt.timeoutMS
+
+			app := fiber.New()
+			var got time.Duration
+			app.Get("/", func(c *fiber.Ctx) error {
+				ctx, cancel := h.renderContext(c, tt.option)
+				defer cancel()
+				if deadline, ok := ctx.Deadline(); ok {
+					got = time.Until(deadline)
+				}
+				return nil
+			})
+			doRequest(t, app, httptest.NewRequest("GET", "/", nil))
+
+			if got > tt.want || got < tt.want-time.Second/2 {
+				t.Errorf("budget = %v, want about %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticCompression(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Compression = config.CompressionConfig{Static: true, MinBytes: 64}
+	app := newTestAppFrom(h)
+
+	req := httptest.NewRequest("GET", "/HELLO?f=doom", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, body := doRequest(t, app, req)
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	etag := resp.Header.Get("ETag")
+	if !strings.HasPrefix(etag, "W/") {
+		t.Errorf("compressed response should carry a weak ETag, got %q", etag)
+	}
+	if !strings.HasPrefix(body, "\x1f\x8b") {
+		t.Error("body is not gzip data")
+	}
+
+	// The weak tag still validates a conditional request
+	req = httptest.NewRequest("GET", "/HELLO?f=doom", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+	if resp, _ := doRequest(t, app, req); resp.StatusCode != 304 {
+		t.Errorf("status = %d, want 304", resp.StatusCode)
+	}
+}
+
+func TestStaticHead(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HELLO?f=small", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("GET status = %d, want 200", resp.StatusCode)
+	}
+
+	head, headBody := doRequest(t, app, httptest.NewRequest("HEAD", "/HELLO?f=small", nil))
+	if head.StatusCode != 200 {
+		t.Fatalf("HEAD status = %d, want 200", head.StatusCode)
+	}
+	if headBody != "" {
+		t.Errorf("HEAD body = %q, want empty", headBody)
+	}
+	if want := strconv.Itoa(len(body)); head.Header.Get("Content-Length") != want {
+		t.Errorf("HEAD Content-Length = %q, want %s", head.Header.Get("Content-Length"), want)
+	}
+	if head.Header.Get("ETag") != resp.Header.Get("ETag") {
+		t.Errorf("HEAD ETag = %q, want %q", head.Header.Get("ETag"), resp.Header.Get("ETag"))
+	}
+}
+
+func TestStaticCORS(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().CORS = config.CORSConfig{Enabled: true, AllowOrigins: []string{"*"}}
+	app := newTestAppFrom(h)
+
+	req := httptest.NewRequest("GET", "/HELLO", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	resp, _ := doRequest(t, app, req)
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+
+	req = httptest.NewRequest("OPTIONS", "/HELLO", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	resp, _ = doRequest(t, app, req)
+	if resp.StatusCode != 204 {
+		t.Errorf("preflight status = %d, want 204", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Methods"); !strings.Contains(got, "HEAD") {
+		t.Errorf("Access-Control-Allow-Methods = %q, want HEAD included", got)
+	}
+}
+
+func TestVersionedRoutes(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		root      string
+		versioned string
+	}{
+		{root: "/HELLO?f=small", versioned: "/v1/HELLO?f=small"},
+		{root: "/HELLO+WORLD", versioned: "/v1/HELLO+WORLD"},
+		{root: "/colors", versioned: "/v1/colors"},
+		{root: "/help", versioned: "/v1/help"},
+		{root: "/", versioned: "/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versioned, func(t *testing.T) {
+			rootResp, rootBody := doRequest(t, app, httptest.NewRequest("GET", tt.root, nil))
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.versioned, nil))
+			if resp.StatusCode != 200 || rootResp.StatusCode != 200 {
+				t.Fatalf("status = %d (root %d), want 200", resp.StatusCode, rootResp.StatusCode)
+			}
+			if body != rootBody {
+				t.Errorf("%s body differs from %s:\n%s\nwant:\n%s", tt.versioned, tt.root, body, rootBody)
+			}
+		})
+	}
+}
+
+func TestStaticProblemDetails(t *testing.T) {
+	app := newTestApp(t)
+
+	req := httptest.NewRequest("GET", "/HI?f=smal&cmode=sparkle", nil)
+	req.Header.Set("Accept", "application/json")
+	resp, body := doRequest(t, app, req)
+	if resp.StatusCode != 400 {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", got)
+	}
+	if got := resp.Header.Get("X-Shout-Error"); got != "bad_option" {
+		t.Errorf("X-Shout-Error = %q, want bad_option", got)
+	}
+	for _, want := range []string{`"code":"bad_option"`, `"detail":"invalid color mode`, `Did you mean font=small?`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q: %s", want, body)
+		}
+	}
+}
+
+func TestStaticInvalidOptions(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?a=diagonal&b=wavy&mw=9999", nil))
+	if resp.StatusCode != 400 {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	for _, want := range []string{
+		`align must be left, center or right, got "diagonal"`,
+		`border must be single, double, rounded or none, got "wavy"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q: %s", want, body)
+		}
+	}
+	// Widths beyond SHOUT_TERMINAL_MAX_COLS are capped rather than refused
+	if strings.Contains(body, "maxwidth") {
+		t.Errorf("maxwidth reported invalid: %s", body)
+	}
+}
+
+func TestStaticFigletFlags(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name   string
+		target string
+		same   string
+	}{
+		{name: "font", target: "/HI?-f=slant", same: "/HI?f=slant"},
+		{name: "width", target: "/HELLO+WORLD?-w=30&-f=doom", same: "/HELLO+WORLD?mw=30&f=doom"},
+		{name: "center", target: "/HI?cols=60&a=left&-c", same: "/HI?cols=60&a=left"},
+		{name: "center without align", target: "/HI?cols=60&-c", same: "/HI?cols=60&a=center"},
+		{name: "center with a value", target: "/HI?cols=60&-c=1", same: "/HI?cols=60&a=center"},
+		{name: "kerning", target: "/HI?-k", same: "/HI"},
+		{name: "native wins", target: "/HI?-f=slant&font=doom", same: "/HI?font=doom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, got := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, got)
+			}
+			_, want := doRequest(t, app, httptest.NewRequest("GET", tt.same, nil))
+			if got != want {
+				t.Errorf("%s =\n%s\nwant the banner of %s:\n%s", tt.target, got, tt.same, want)
+			}
+		})
+	}
+
+	// -c=false leaves the alignment to the other options
+	_, left := doRequest(t, app, httptest.NewRequest("GET", "/HI?cols=60&a=left&-c=false", nil))
+	_, centered := doRequest(t, app, httptest.NewRequest("GET", "/HI?cols=60&a=center", nil))
+	if left == centered {
+		t.Error("-c=false centered the banner")
+	}
+}
+
+func TestStaticPixelEngines(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, small := doRequest(t, app, httptest.NewRequest("GET", "/HI?engine=block&font=doom", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, small)
+	}
+	if got := resp.Header.Get("X-Shout-Font"); got != render.BlockFontName {
+		t.Errorf("X-Shout-Font = %q, want %q", got, render.BlockFontName)
+	}
+	if !strings.Contains(small, "█") || strings.Contains(small, notePrefix) {
+		t.Errorf("block banner = %q, want block characters and no fallback note", small)
+	}
+
+	_, large := doRequest(t, app, httptest.NewRequest("GET", "/HI?engine=block&scale=2", nil))
+	if render.StringWidth(strings.Split(large, "\n")[0]) <= render.StringWidth(strings.Split(small, "\n")[0]) {
+		t.Errorf("scale=2 banner isn't wider than scale=1:\n%s\n%s", large, small)
+	}
+
+	_, missing := doRequest(t, app, httptest.NewRequest("GET", "/H%E2%98%83?engine=block", nil))
+	if !strings.Contains(missing, notePrefix) {
+		t.Errorf("banner with ☃ = %q, want a note that it was drawn as ?", missing)
+	}
+
+	resp, braille := doRequest(t, app, httptest.NewRequest("GET", "/HI?engine=braille", nil))
+	if got := resp.Header.Get("X-Shout-Font"); got != render.BrailleFontName || !strings.Contains(braille, "⣿") {
+		t.Errorf("braille banner in %q = %q, want braille patterns in %q", got, braille, render.BrailleFontName)
+	}
+
+	for _, target := range []string{"/HI?engine=pixels", "/HI?engine=block&scale=9"} {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", target, nil))
+		if resp.StatusCode != 400 || resp.Header.Get(apierrors.HeaderErrorCode) != "bad_option" {
+			t.Errorf("%s = %d %q, want 400 bad_option", target, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestStaticASCII(t *testing.T) {
+	app := newTestApp(t)
+
+	for _, target := range []string{
+		"/Caf%C3%A9+%E2%98%83?ascii=strict&color=rainbow",
+		"/Caf%C3%A9?ascii=strict&engine=block",
+		"/Caf%C3%A9?ascii=strict&engine=braille&format=md",
+	} {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", target, nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s status = %d, want 200 (body %q)", target, resp.StatusCode, body)
+		}
+		for _, b := range []byte(body) {
+			if b >= 0x80 || b == 0x1b {
+				t.Errorf("%s = %q, has byte %#x", target, body, b)
+				break
+			}
+		}
+	}
+
+	// Accents are dropped from the text, not drawn as ?
+	_, got := doRequest(t, app, httptest.NewRequest("GET", "/Caf%C3%A9?ascii=strict", nil))
+	_, want := doRequest(t, app, httptest.NewRequest("GET", "/Cafe", nil))
+	if got != want {
+		t.Errorf("ascii=strict banner of Café =\n%s\nwant the banner of Cafe:\n%s", got, want)
+	}
+
+	plain, _ := doRequest(t, app, httptest.NewRequest("GET", "/Cafe", nil))
+	strict, _ := doRequest(t, app, httptest.NewRequest("GET", "/Cafe?ascii=strict", nil))
+	if plain.Header.Get(fiber.HeaderETag) == strict.Header.Get(fiber.HeaderETag) {
+		t.Error("ascii=strict banner has the ETag of the plain one")
+	}
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/Cafe?ascii=loose", nil))
+	if resp.StatusCode != 400 || resp.Header.Get(apierrors.HeaderErrorCode) != "bad_option" {
+		t.Errorf("ascii=loose = %d %q, want 400 bad_option", resp.StatusCode, body)
+	}
+}
+
+func TestStaticFill(t *testing.T) {
+	app := newTestApp(t)
+
+	_, plain := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small", nil))
+	tests := []struct {
+		target string
+		cells  string
+	}{
+		{target: "/HI?f=small&fill=%23", cells: "#"},
+		{target: "/HI?f=small&fill=%E2%96%88", cells: "█"},
+		{target: "/HI?f=small&fill=text", cells: "HI"},
+		{target: "/HI?engine=block&fill=%23", cells: "#"},
+	}
+	for _, tt := range tests {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+		if resp.StatusCode != 200 {
+			t.Fatalf("%s status = %d, want 200 (body %q)", tt.target, resp.StatusCode, body)
+		}
+		if strings.Trim(body, " \n"+tt.cells) != "" {
+			t.Errorf("%s =\n%s\nwant only %q and spaces", tt.target, body, tt.cells)
+		}
+	}
+
+	// The glyphs keep their shape
+	_, filled := doRequest(t, app, httptest.NewRequest("GET", "/HI?f=small&fill=%23", nil))
+	shape := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r == ' ' || r == '\n' {
+				return r
+			}
+			return '#'
+		}, s)
+	}
+	if filled != shape(plain) {
+		t.Errorf("filled banner =\n%s\nwant the shape of\n%s", filled, plain)
+	}
+
+	// Hardblanks, the space glyph's here, are drawn in their own character
+	_, spaced := doRequest(t, app, httptest.NewRequest("GET", "/H%20I?f=small", nil))
+	_, dotted := doRequest(t, app, httptest.NewRequest("GET", "/H%20I?f=small&hardblank=.", nil))
+	if strings.Contains(spaced, ".") || !strings.Contains(dotted, ".") {
+		t.Errorf("hardblank=. =\n%s\nwant dots where\n%s\nhas hardblanks", dotted, spaced)
+	}
+
+	for _, target := range []string{"/HI?fill=ab", "/HI?hardblank=ab"} {
+		resp, body := doRequest(t, app, httptest.NewRequest("GET", target, nil))
+		if resp.StatusCode != 400 || resp.Header.Get(apierrors.HeaderErrorCode) != "bad_option" {
+			t.Errorf("%s = %d %q, want 400 bad_option", target, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestStaticRenderCache(t *testing.T) {
+	h := newTestHandler(t)
+	cache, err := rendercache.Open(config.CacheConfig{Entries: 10, TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	h.cache = cache
+	h.config().Analytics.Enabled = true
+	app := newTestAppFrom(h)
+
+	resp, first := doRequest(t, app, httptest.NewRequest("GET", "/HI?font=doom", nil))
+	if got := resp.Header.Get(HeaderCache); got != "miss" {
+		t.Errorf("first %s = %q, want miss", HeaderCache, got)
+	}
+	resp, second := doRequest(t, app, httptest.NewRequest("GET", "/HI?font=doom", nil))
+	if got := resp.Header.Get(HeaderCache); got != "hit" {
+		t.Errorf("second %s = %q, want hit", HeaderCache, got)
+	}
+	_, other := doRequest(t, app, httptest.NewRequest("GET", "/HI?font=doom&format=md", nil))
+	if first != second {
+		t.Errorf("cached banner = %q, want %q", second, first)
+	}
+	if !strings.HasPrefix(other, "```") {
+		t.Errorf("format=md banner = %q, want its own cache entry", other)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v, want 1 hit and 2 misses", stats)
+	}
+	if got := h.metrics.Usage(types.UsageFont)["doom"]; got != 3 {
+		t.Errorf("doom renders counted = %d, want 3 with cached ones", got)
+	}
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"log"
+	"runtime/debug"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/errreport"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/valyala/fasthttp"
+)
+
+// Terminal control sequences used to redraw frames in place.
+const (
+	ansiClearScreen = "\x1b[2J"
+	ansiHome        = "\x1b[H"
+	ansiClearLine   = "\x1b[K"
+	ansiClearBelow  = "\x1b[J"
+	ansiHideCursor  = "\x1b[?25l"
+	ansiShowCursor  = "\x1b[?25h"
+	ansiReset       = "\x1b[0m"
+	// ansiAltScreen and ansiMainScreen switch to the alternate screen
+	// buffer and back, as smcup and rmcup do
+	ansiAltScreen  = "\x1b[?1049h"
+	ansiMainScreen = "\x1b[?1049l"
+)
+
+// screenAlt is the ?screen= value that plays a stream on the alternate
+// screen.
+const screenAlt = "alt"
+
+// screenParam documents ?screen= on the streaming routes.
+var screenParam = optionHelp{Names: []string{"screen"}, Help: "alt plays the stream on the alternate screen, restoring the terminal when it ends", Type: "string"}
+
+// Speed and frame rate limits when no config is set.
+const (
+	defaultSpeed  = 5
+	defaultMaxFPS = 20
+)
+
+// fpsParam documents ?fps= on the animated routes.
+var fpsParam = optionHelp{Names: []string{"fps"}, Help: "Frames per second, overriding speed; capped by the server", Type: "integer"}
+
+// frameInterval returns the time between animation frames: 1/fps when
+// ?fps= is given, otherwise the interval of ?speed= (see shout.SpeedIntervals).
+// Speeds outside SHOUT_STREAMING_MIN_SPEED-MAX_SPEED and frame rates above
+// SHOUT_STREAMING_MAX_FPS are rejected, and degraded streams run no faster
+// than SHOUT_STREAMING_DEGRADED_FPS.
+func (h *Handler) frameInterval(c *fiber.Ctx) (time.Duration, error) {
+	interval, err := h.requestedInterval(c)
+	if err != nil || !h.degraded(c) {
+		return interval, err
+	}
+	return max(interval, time.Second/time.Duration(h.config().Streaming.DegradedFPS)), nil
+}
+
+// requestedInterval is frameInterval before degradation.
+func (h *Handler) requestedInterval(c *fiber.Ctx) (time.Duration, error) {
+	cfg := h.config()
+	minSpeed, maxSpeed, speed, maxFPS := 1, len(shout.SpeedIntervals), defaultSpeed, defaultMaxFPS
+	if cfg != nil {
+		s := cfg.Streaming
+		minSpeed, maxSpeed, speed, maxFPS = s.MinSpeed, s.MaxSpeed, s.DefaultSpeed, s.MaxFPS
+	}
+
+	if raw := c.Query("fps"); raw != "" {
+		fps := c.QueryInt("fps", 0)
+		if fps < 1 || fps > maxFPS {
+			return 0, apierrors.ErrBadOption.WithDetail("fps must be between 1 and %d, got %q", maxFPS, raw)
+		}
+		return time.Second / time.Duration(fps), nil
+	}
+
+	preset, err := h.requestPreset(c)
+	if err != nil {
+		return 0, err
+	}
+	if s := cmp.Or(requestOptions(c).Speed, preset.Speed); s != 0 {
+		speed = s
+	}
+	if speed < minSpeed || speed > maxSpeed {
+		return 0, apierrors.ErrBadOption.WithDetail("speed must be between %d and %d, got %d", minSpeed, maxSpeed, speed)
+	}
+	return shout.SpeedIntervals[speed-1], nil
+}
+
+// HeaderFramesDropped is the trailer reporting how many frames of a stream
+// were skipped because the client read too slowly.
+const HeaderFramesDropped = "X-Frames-Dropped"
+
+// droppedTrailer is the body of a stream, setting the HeaderFramesDropped
+// trailer once it is read to the end. fasthttp reads it on the goroutine
+// that writes the response out, after the header and before the
+// trailers, so the header is never changed while it is being written.
+type droppedTrailer struct {
+	io.ReadCloser
+	header *fasthttp.ResponseHeader
+	// dropped is stored by the stream before the body ends
+	dropped *atomic.Int64
+}
+
+// Read implements io.Reader.
+func (d *droppedTrailer) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	if err == io.EOF {
+		d.header.Set(HeaderFramesDropped, strconv.FormatInt(d.dropped.Load(), 10))
+	}
+	return n, err
+}
+
+// frameSource returns the content of frame n, counting from 0, and whether
+// it is the last frame. An error ends the stream with an error line.
+// Frames are skipped when the client falls behind, so n may jump: frame n
+// must not depend on earlier frames having been sent.
+type frameSource func(n int) (frame string, last bool, err error)
+
+// stream sends frames from next to the client every interval, redrawing
+// each one over the last. It holds one of the SHOUT_STREAMING_MAX_STREAMS
+// slots for the life of the stream and never runs longer than
+// SHOUT_STREAMING_MAX_TIMEOUT. The cursor is hidden while the stream runs,
+// and colors are reset and the cursor shown when it ends for any reason.
+// HEAD requests get the headers only, and ?format=cast and ?format=gif
+// return a recording instead (see record).
+//
+// next runs after the handler has returned, so it must not use c.
+func (h *Handler) stream(c *fiber.Ctx, interval time.Duration, next frameSource) error {
+	return h.streamChunks(c, interval, ansiHideCursor+ansiClearScreen, ansiReset+ansiShowCursor, func(n int) (string, bool, error) {
+		frame, last, err := next(n)
+		if err != nil {
+			return "", false, err
+		}
+		return shout.Redraw(frame), last, nil
+	})
+}
+
+// streamChunks is stream without the redrawing: the output of next is
+// written as is, after prologue and followed by epilogue. The epilogue is
+// written even when next fails or panics. With ?screen=alt, the stream
+// plays on the alternate screen, and colors are reduced to what ?term=
+// supports.
+func (h *Handler) streamChunks(c *fiber.Ctx, interval time.Duration, prologue, epilogue string, next frameSource) error {
+	cfg := h.config()
+	format, err := h.streamFormat(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	switch screen := c.Query("screen"); screen {
+	case "":
+	case screenAlt:
+		prologue, epilogue = ansiAltScreen+prologue, epilogue+ansiMainScreen
+	default:
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("unknown screen %q", screen).WithHint("use screen=alt"))
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if term.colors != render.TrueColor {
+		source := next
+		next = func(n int) (string, bool, error) {
+			chunk, last, err := source(n)
+			return render.ReduceColors(chunk, term.colors), last, err
+		}
+	}
+	switch format {
+	case formatCast:
+		return h.recordCast(c, interval, prologue, epilogue, next)
+	case formatGIF:
+		return h.recordGIF(c, interval, prologue, epilogue, next)
+	}
+
+	c.Set(fiber.HeaderContentType, textPlain)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	// Stop proxies such as nginx from buffering the frames
+	c.Set("X-Accel-Buffering", "no")
+	if c.Method() == fiber.MethodHead {
+		return nil
+	}
+
+	client, tier := clientID(c), h.tier(c)
+	charged := !h.exempt(c)
+	quotaLeft, err := h.streamQuota(c, tier)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	limits := h.streamLimits(c, tier)
+	if full, ok := h.streams.Acquire(limits...); !ok {
+		h.metrics.IncRejectedStream()
+		return apierrors.Send(c, streamCapacityError(full, tier))
+	}
+
+	var maxDuration time.Duration
+	if cfg != nil {
+		maxDuration = time.Duration(cfg.Streaming.MaxTimeout) * time.Second
+	}
+	if quotaLeft > 0 && (maxDuration == 0 || quotaLeft < maxDuration) {
+		maxDuration = quotaLeft
+	}
+	compression := h.compressionConfig()
+	if h.degraded(c) {
+		compression.Stream = false
+	}
+	encoding := middleware.StreamEncoding(c, compression)
+	closed := middleware.StreamClosed(c)
+	extendDeadline := h.streamDeadline(c)
+	var failed *errreport.Request
+	if h.errors != nil {
+		failed = middleware.ReportRequest(c, fiber.StatusOK)
+	}
+
+	var dropped atomic.Int64
+	c.Context().Response.Header.SetTrailer(HeaderFramesDropped)
+	body := fasthttp.NewStreamReader(func(w *bufio.Writer) {
+		defer h.streams.Release(streamKeys(limits)...)
+		// Runs last, after the epilogue has gone out
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Panic rendering stream frame: %v", r)
+				h.errors.Report(errreport.Report{
+					Level:   errreport.LevelFatal,
+					Message: fmt.Sprintf("panic rendering stream frame: %v", r),
+					Stack:   string(debug.Stack()),
+					Request: failed,
+				})
+			}
+		}()
+
+		sw := middleware.NewStreamWriter(w, encoding)
+		defer func() { closed(sw.Sent()) }()
+		defer sw.Close()
+
+		pace := &pacer{start: time.Now(), interval: interval}
+		deadline := pace.start.Add(maxDuration)
+		if charged {
+			defer func() { h.chargeStream(client, time.Since(pace.start)) }()
+		}
+		defer func() {
+			h.metrics.AddDroppedFrames(int64(pace.dropped))
+			dropped.Store(int64(pace.dropped))
+		}()
+
+		extendDeadline()
+		sw.Write([]byte(prologue))
+		defer sw.Write([]byte(epilogue))
+
+		for n := 0; ; n = pace.next(n) {
+			chunk, last, err := next(n)
+			extendDeadline()
+			if err != nil {
+				log.Printf("Error rendering stream frame: %v", err)
+				sw.Write([]byte("\nError: " + err.Error() + "\n"))
+				return
+			}
+			if _, err := sw.Write([]byte(chunk)); err != nil {
+				return
+			}
+			// A failed flush means the client went away
+			if err := sw.Flush(); err != nil || last {
+				return
+			}
+			if maxDuration > 0 && time.Now().After(deadline) {
+				return
+			}
+		}
+	})
+	resp := &c.Context().Response
+	resp.SetBodyStream(&droppedTrailer{ReadCloser: body, header: &resp.Header, dropped: &dropped}, -1)
+	return nil
+}
+
+// streamDeadline returns a function that moves the connection's write
+// deadline SHOUT_SERVER_STREAM_WRITE_TIMEOUT ahead, or clears it when
+// that is 0. Called before each frame, it replaces the server's write
+// timeout, which bounds the whole response, so a stream runs for as long
+// as the client keeps reading while one that stops reading is cut off.
+func (h *Handler) streamDeadline(c *fiber.Ctx) func() {
+	cfg := h.config()
+	var timeout time.Duration
+	if cfg != nil {
+		timeout = cfg.Server.StreamWriteTimeout
+	}
+	conn := c.Context().Conn()
+	if conn == nil {
+		return func() {}
+	}
+	return func() {
+		var deadline time.Time
+		if timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		_ = conn.SetWriteDeadline(deadline)
+	}
+}
+
+// pacer schedules the frames of a stream. A client that reads slower than
+// the frame rate blocks writes once its TCP window fills; rather than queue
+// frames behind it, the pacer skips the frames that fell due meanwhile, so
+// the client always sees the current frame.
+type pacer struct {
+	start    time.Time
+	interval time.Duration
+	// dropped counts the frames skipped
+	dropped int
+}
+
+// next waits until the frame after n is due and returns its number. If
+// later frames are already due, it skips to the latest of them.
+func (p *pacer) next(n int) int {
+	due := n + 1
+	if current := int(time.Since(p.start) / p.interval); current > due {
+		p.dropped += current - due
+		due = current
+	}
+	time.Sleep(time.Until(p.start.Add(time.Duration(due) * p.interval)))
+	return due
+}
+
+// compressionConfig returns the compression settings, or zero values if
+// no config is set.
+func (h *Handler) compressionConfig() config.CompressionConfig {
+	cfg := h.config()
+	if cfg == nil {
+		return config.CompressionConfig{}
+	}
+	return cfg.Compression
+}
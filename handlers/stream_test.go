@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStreamScreen(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPrefix string
+		wantSuffix string
+	}{
+		{name: "main screen", query: "", wantStatus: 200, wantPrefix: ansiHideCursor, wantSuffix: ansiReset + ansiShowCursor},
+		{name: "alternate screen", query: "&screen=alt", wantStatus: 200, wantPrefix: ansiAltScreen + ansiHideCursor, wantSuffix: ansiReset + ansiShowCursor + ansiMainScreen},
+		{name: "unknown screen", query: "&screen=side", wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/1?f=small"+tt.query, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			if !strings.HasPrefix(body, tt.wantPrefix) || !strings.HasSuffix(body, tt.wantSuffix) {
+				t.Errorf("body = %q, want it to start with %q and end with %q", body, tt.wantPrefix, tt.wantSuffix)
+			}
+		})
+	}
+
+	// Recordings switch screens too
+	_, body := doRequest(t, app, httptest.NewRequest("GET", "/countdown/1?f=small&format=cast&screen=alt", nil))
+	if _, events := parseCast(t, body); !strings.HasPrefix(events[0][2].(string), ansiAltScreen) {
+		t.Errorf("cast prologue = %q, want it to switch screens", events[0][2])
+	}
+}
+
+func TestStreamRestoresTerminal(t *testing.T) {
+	h := newTestHandler(t)
+	app := fiber.New()
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return h.stream(c, time.Millisecond, func(n int) (string, bool, error) {
+			if n == 1 {
+				panic("boom")
+			}
+			return "frame", false, nil
+		})
+	})
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/fail?screen=alt", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if !strings.HasSuffix(body, ansiReset+ansiShowCursor+ansiMainScreen) {
+		t.Errorf("body = %q, want the terminal restored after a failed frame", body)
+	}
+	if h.streams.GetActiveCount() != 0 {
+		t.Error("stream slot wasn't released")
+	}
+}
+
+func TestStreamFramesDroppedTrailer(t *testing.T) {
+	fastCountdown(t)
+	h := newTestHandler(t)
+	h.config().Streaming.MaxTimeout = 60
+	app := newTestAppFrom(h)
+
+	resp, _ := doRequest(t, app, httptest.NewRequest("GET", "/countdown/1?f=small", nil))
+	if got := resp.Trailer.Get(HeaderFramesDropped); got == "" {
+		t.Errorf("%s trailer missing; trailers = %v", HeaderFramesDropped, resp.Trailer)
+	}
+
+	// A frame that takes ten intervals to send skips the frames due meanwhile
+	var sent []int
+	app = fiber.New()
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		return h.stream(c, time.Millisecond, func(n int) (string, bool, error) {
+			sent = append(sent, n)
+			if n == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+			return "frame", n >= 20, nil
+		})
+	})
+	before := h.metrics.Snapshot().Streams.DroppedFrames
+	resp, _ = doRequest(t, app, httptest.NewRequest("GET", "/slow", nil))
+	dropped, _ := strconv.Atoi(resp.Trailer.Get(HeaderFramesDropped))
+	if len(sent) < 2 || sent[1] < 10 || dropped < 9 {
+		t.Errorf("frames sent = %v with %d dropped, want a jump of at least 10", sent, dropped)
+	}
+	if got := h.metrics.Snapshot().Streams.DroppedFrames - before; got != int64(dropped) {
+		t.Errorf("DroppedFrames = %d, want %d", got, dropped)
+	}
+}
+
+func TestStreamOutlivesWriteTimeout(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Server.StreamWriteTimeout = time.Second
+	// The server's write timeout would cut the stream off after 100ms
+	app := fiber.New(fiber.Config{WriteTimeout: 100 * time.Millisecond, DisableStartupMessage: true})
+	app.Get("/stream", func(c *fiber.Ctx) error {
+		return h.streamChunks(c, 10*time.Millisecond, "", "END", func(n int) (string, bool, error) {
+			return "frame\n", n == 29, nil
+		})
+	})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go func() { _ = app.Listener(lis) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	resp, err := http.Get("http://" + lis.Addr().String() + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("stream cut off after %d bytes: %v", len(body), err)
+	}
+	if got := strings.Count(string(body), "frame\n"); got != 30 || !strings.HasSuffix(string(body), "END") {
+		t.Errorf("stream sent %d frames, ending %q; want 30 and the epilogue", got, body[max(0, len(body)-10):])
+	}
+}
+
+func TestPacer(t *testing.T) {
+	tests := []struct {
+		name        string
+		elapsed     time.Duration
+		n           int
+		want        int
+		wantDropped int
+	}{
+		{name: "on time", elapsed: 0, n: 0, want: 1},
+		{name: "next frame due now", elapsed: 100 * time.Millisecond, n: 0, want: 1},
+		{name: "behind", elapsed: 350 * time.Millisecond, n: 0, want: 3, wantDropped: 2},
+		{name: "behind later on", elapsed: time.Second, n: 5, want: 10, wantDropped: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &pacer{start: time.Now().Add(-tt.elapsed), interval: 100 * time.Millisecond}
+			if got := p.next(tt.n); got != tt.want || p.dropped != tt.wantDropped {
+				t.Errorf("next(%d) = %d with %d dropped, want %d with %d dropped", tt.n, got, p.dropped, tt.want, tt.wantDropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// maxTableBytes caps the JSON body of a table.
+const maxTableBytes = 64 << 10
+
+// Limits on what one table draws.
+const (
+	maxTableRows    = 100
+	maxTableColumns = 12
+)
+
+// Defaults of tables: lines of the single border style, since a table
+// reads poorly without them, and a title in a small font.
+const (
+	defaultTableBorder = "single"
+	tableTitleFont     = "small"
+)
+
+// tableParams are the query parameters of the table route.
+var tableParams = []optionHelp{
+	colsParam,
+	termParam,
+	ncParam,
+	asciiParam,
+}
+
+// tableDocument is the JSON body of a table request.
+type tableDocument struct {
+	// Title is drawn as a banner above the table; "" for none
+	Title string `json:"title"`
+	// Header is the first row, drawn above a line of its own
+	Header []string `json:"header"`
+	// Align places the cells of each column: left, center or right
+	Align []string `json:"align"`
+	// Rows are the cells: strings, numbers, booleans or null
+	Rows [][]any `json:"rows"`
+}
+
+// Table draws the rows of the JSON body as a table in box-drawing lines,
+// with an optional header row and title banner. The query string's border
+// option picks the lines, single unless it, a preset or a theme gives
+// another, or none for columns without lines; accent colors the lines,
+// color the cells, and bg and pad fill a box around the table. Columns
+// holding only numbers are aligned right unless align says otherwise.
+// ?ascii=strict draws the lines with + - and |, and the cells
+// transliterated.
+//
+// Example:
+//
+//	curl -d '{"title":"FLEET","header":["host","cpu"],"rows":[["web-01",12],["db-01",80]]}' 'localhost:8080/table?theme=ocean'
+func (h *Handler) Table(c *fiber.Ctx) error {
+	h.metrics.IncStatic()
+	if len(c.Body()) > maxTableBytes {
+		return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", maxTableBytes))
+	}
+
+	var doc tableDocument
+	if err := json.Unmarshal(c.Body(), &doc); err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("invalid JSON body: %v", err))
+	}
+	if len(doc.Rows) == 0 && len(doc.Header) == 0 {
+		return apierrors.Send(c, apierrors.ErrNoText.WithDetail("the table has no rows"))
+	}
+	if len(doc.Rows) > maxTableRows {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("a table has at most %d rows, got %d", maxTableRows, len(doc.Rows)))
+	}
+	columns := len(doc.Header)
+	for _, row := range doc.Rows {
+		columns = max(columns, len(row))
+	}
+	if columns > maxTableColumns {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("a table has at most %d columns, got %d", maxTableColumns, columns))
+	}
+	align, err := tableAlignment(doc.Align, doc.Rows, columns)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	defaults := h.defaultOptions()
+	defaults.Border = defaultTableBorder
+	opts, err := h.withDefaults(c, requestOptions(c), defaults)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if err := h.validateOptions(opts); err != nil {
+		return apierrors.Send(c, err)
+	}
+	scheme, err := parseColorScheme(opts)
+	if err != nil {
+		return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%v", err))
+	}
+	term, err := h.terminalSize(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	if term, err = withASCII(c, term); err != nil {
+		return apierrors.Send(c, err)
+	}
+	cols := term.cols
+	if opts.MaxWidth > 0 {
+		cols = opts.MaxWidth
+	}
+
+	table := render.Table{Align: align, Border: opts.Border, Width: cols, Place: h.alignment(opts)}
+	if doc.Header != nil {
+		if table.Header, err = h.tableCells(doc.Header); err != nil {
+			return apierrors.Send(c, err)
+		}
+	}
+	for _, row := range doc.Rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = tableCell(value)
+		}
+		if cells, err = h.tableCells(cells); err != nil {
+			return apierrors.Send(c, err)
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	frame := render.Frame{Padding: opts.Padding, Width: cols, Align: table.Place}
+	for _, color := range []struct {
+		name, value string
+		dst         **render.Color
+	}{
+		{"accent", opts.Accent, &table.Accent},
+		{"bg", opts.Background, &frame.Background},
+	} {
+		if color.value == "" {
+			continue
+		}
+		parsed, err := render.ParseSolidColor(color.value)
+		if err != nil {
+			return apierrors.Send(c, apierrors.ErrBadOption.WithDetail("%s: %v", color.name, err))
+		}
+		*color.dst = &parsed
+	}
+	// A box around the table places it instead
+	if frame.Drawn() {
+		table.Width = 0
+	}
+
+	var b strings.Builder
+	if doc.Title != "" {
+		title, err := h.tableTitle(c, doc.Title, opts, scheme, cols, term)
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+		b.WriteString(title)
+	}
+	b.WriteString(render.FrameOutput(render.DrawTable(table, scheme), frame))
+
+	output := render.ReduceColors(b.String(), term.colors)
+	if term.ascii {
+		output = render.ASCIIOnly(output)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderContentType, textPlain)
+	return c.SendString(output)
+}
+
+// tableTitle draws the title of a table as a banner in a small font, or
+// the font the request names, colored and aligned as the table is.
+func (h *Handler) tableTitle(c *fiber.Ctx, title string, opts types.RenderOptions, scheme render.ColorScheme, cols int, term terminal) (string, error) {
+	text, err := middleware.CheckText(title, h.textConfig())
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", nil
+	}
+	masked, err := middleware.FilterText(h.wordFilter(), h.textConfig().Filter, text, h.metrics)
+	if err != nil {
+		return "", err
+	}
+	if term.ascii {
+		text = render.Transliterate(text)
+	}
+	name := firstOf(requestOptions(c).Font, tableTitleFont)
+	suggestion := h.suggestFont(c, name)
+	font := h.fonts.ResolveFont(name, h.defaultFont())
+	if font == nil {
+		return "", apierrors.ErrFontNotFound.WithDetail("%q", name)
+	}
+
+	ctx, cancel := h.renderContext(c, opts.Timeout)
+	defer cancel()
+	output, err := h.shout.Render(ctx, text, shout.Options{
+		Font:   font.Name,
+		Scheme: scheme,
+		Align:  h.alignment(opts),
+		Cols:   cols,
+		Masked: masked,
+	})
+	if err != nil {
+		return "", h.renderError(err, suggestion)
+	}
+	return output, nil
+}
+
+// tableCells checks the cells of a row as path text is checked, as single
+// lines, and masks blocked words in them.
+func (h *Handler) tableCells(cells []string) ([]string, error) {
+	text := h.textConfig()
+	checked := make([]string, len(cells))
+	for i, cell := range cells {
+		cell, err := middleware.CheckText(strings.ReplaceAll(cell, "\n", " "), text)
+		if err != nil {
+			return nil, err
+		}
+		masked, err := middleware.FilterText(h.wordFilter(), text.Filter, cell, h.metrics)
+		if err != nil {
+			return nil, err
+		}
+		checked[i] = render.MaskText(cell, masked)
+	}
+	return checked, nil
+}
+
+// tableCell returns the text of a cell of a JSON row: strings as they
+// are, numbers as written, booleans as true or false, and null as empty.
+func tableCell(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		// Nested arrays and objects are shown as JSON
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// tableAlignment returns the alignment of each of the columns of rows:
+// as align gives it, or else right for columns holding only numbers and
+// left for others. It returns ErrBadOption for an unknown alignment.
+func tableAlignment(align []string, rows [][]any, columns int) ([]string, error) {
+	if len(align) > columns {
+		return nil, apierrors.ErrBadOption.WithDetail("align has %d entries for %d columns", len(align), columns)
+	}
+	out := make([]string, columns)
+	for i := range out {
+		if i < len(align) && align[i] != "" {
+			if !slices.Contains(types.Alignments, align[i]) {
+				return nil, apierrors.ErrBadOption.WithDetail("align of column %d must be left, center or right, got %q", i+1, align[i])
+			}
+			out[i] = align[i]
+			continue
+		}
+		out[i] = "left"
+		if numericColumn(rows, i) {
+			out[i] = "right"
+		}
+	}
+	return out, nil
+}
+
+// numericColumn reports whether column i of rows holds numbers, and
+// nothing else but empty cells.
+func numericColumn(rows [][]any, i int) bool {
+	numbers := 0
+	for _, row := range rows {
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		if _, ok := row[i].(float64); !ok {
+			return false
+		}
+		numbers++
+	}
+	return numbers > 0
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestTable(t *testing.T) {
+	app := newTestAppFrom(newThemesHandler(t))
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		want       string
+		contains   []string
+	}{
+		{
+			name:       "numbers aligned right",
+			path:       "/table?term=dumb",
+			body:       `{"header":["host","cpu"],"rows":[["web-01",12],["db-01",8.5]]}`,
+			wantStatus: 200,
+			want: "┌────────┬─────┐\n" +
+				"│ host   │ cpu │\n" +
+				"├────────┼─────┤\n" +
+				"│ web-01 │  12 │\n" +
+				"│ db-01  │ 8.5 │\n" +
+				"└────────┴─────┘\n",
+		},
+		{
+			name:       "without lines",
+			path:       "/table?b=none&term=dumb",
+			body:       `{"rows":[["a",true],["bb",null]]}`,
+			wantStatus: 200,
+			want:       "a   true\nbb      \n",
+		},
+		{
+			name:       "alignment",
+			path:       "/table?b=double&term=dumb",
+			body:       `{"align":["right","center"],"rows":[["a","b"],["ccc","ddd"]]}`,
+			wantStatus: 200,
+			want:       "╔═════╦═════╗\n║   a ║  b  ║\n║ ccc ║ ddd ║\n╚═════╩═════╝\n",
+		},
+		{
+			name:       "placed within cols",
+			path:       "/table?cols=11&a=right&term=dumb",
+			body:       `{"rows":[["a"]]}`,
+			wantStatus: 200,
+			want:       "      ┌───┐\n      │ a │\n      └───┘\n",
+		},
+		{
+			name:       "title",
+			path:       "/table?term=dumb",
+			body:       `{"title":"HI","rows":[["a"]]}`,
+			wantStatus: 200,
+			contains:   []string{"|_||_|", "┌───┐\n│ a │\n└───┘\n"},
+		},
+		{
+			name:       "theme",
+			path:       "/table?theme=ocean",
+			body:       `{"rows":[["a"]]}`,
+			wantStatus: 200,
+			contains:   []string{render.Color{R: 255, G: 215}.Foreground() + "╭", render.Color{B: 255}.Foreground() + "a"},
+		},
+		{
+			name:       "ascii",
+			path:       "/table?ascii=strict&c=red&accent=gold",
+			body:       `{"header":["café"],"rows":[[1]]}`,
+			wantStatus: 200,
+			want:       "+------+\n| cafe |\n+------+\n|    1 |\n+------+\n",
+		},
+		{name: "unknown alignment", path: "/table", body: `{"align":["diagonal"],"rows":[["a"]]}`, wantStatus: 400, contains: []string{`align of column 1 must be left, center or right, got "diagonal"`}},
+		{name: "too many columns", path: "/table", body: `{"rows":[[1,2,3,4,5,6,7,8,9,10,11,12,13]]}`, wantStatus: 400, contains: []string{"at most 12 columns, got 13"}},
+		{name: "no rows", path: "/table", body: `{"rows":[]}`, wantStatus: 400, contains: []string{"the table has no rows"}},
+		{name: "bad JSON", path: "/table", body: `{`, wantStatus: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, body := doRequest(t, app, req)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.want != "" && body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
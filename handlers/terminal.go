@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// HeaderTerminalSize carries the client's terminal size as COLSxROWS, as
+// a wrapper script can read it from stty size.
+const HeaderTerminalSize = "X-Terminal-Size"
+
+// Fallbacks for the largest terminal accepted when no config is set.
+const (
+	defaultMaxCols = 500
+	defaultMaxRows = 200
+)
+
+// colsParam, rowsParam, termParam and ncParam document the terminal on
+// the routes that fit their output to it, and asciiParam on static routes.
+var (
+	ncParam    = optionHelp{Names: []string{"nc"}, Help: "1 leaves colors out, as NO_COLOR does; 0 keeps them for clients the server would leave them out for", Type: "string"}
+	colsParam  = optionHelp{Names: []string{"cols"}, Help: "Terminal width: text wraps and aligns within it and animations stay inside it", Type: "integer"}
+	rowsParam  = optionHelp{Names: []string{"rows"}, Help: "Terminal height: streamed frames are cropped to fit", Type: "integer"}
+	termParam  = optionHelp{Names: []string{"term"}, Help: "Terminal type, as in $TERM, such as xterm-256color or dumb; colors are reduced to what it shows", Type: "string"}
+	asciiParam = optionHelp{Names: []string{"ascii"}, Help: "strict for 7-bit ASCII output: text is transliterated, colors left out, and box, block and braille characters drawn with + - | and #", Type: "string"}
+)
+
+// asciiStrict is the ?ascii= value asking for 7-bit ASCII output.
+const asciiStrict = "strict"
+
+// terminal describes the client's terminal: its size in characters, where
+// 0 means unknown, and the colors it shows.
+type terminal struct {
+	cols, rows int
+	colors     render.ColorDepth
+	// ascii is set for terminals that only show 7-bit ASCII (?ascii=strict)
+	ascii bool
+}
+
+// terminalSize returns the client's terminal: the size from ?cols= and
+// ?rows=, then the X-Terminal-Size header, then SHOUT_TERMINAL_COLS and
+// SHOUT_TERMINAL_ROWS, and the colors of the ?term= terminal type.
+func (h *Handler) terminalSize(c *fiber.Ctx) (terminal, error) {
+	cfg := h.config()
+	var term terminal
+	maxCols, maxRows := defaultMaxCols, defaultMaxRows
+	if cfg != nil {
+		term = terminal{cols: cfg.Terminal.Cols, rows: cfg.Terminal.Rows}
+		if cfg.Terminal.MaxCols > 0 {
+			maxCols = cfg.Terminal.MaxCols
+		}
+		if cfg.Terminal.MaxRows > 0 {
+			maxRows = cfg.Terminal.MaxRows
+		}
+	}
+
+	if header := c.Get(HeaderTerminalSize); header != "" {
+		cols, rows, ok := strings.Cut(strings.ToLower(strings.TrimSpace(header)), "x")
+		var colsErr, rowsErr error
+		term.cols, colsErr = strconv.Atoi(cols)
+		term.rows, rowsErr = strconv.Atoi(rows)
+		if !ok || colsErr != nil || rowsErr != nil {
+			return terminal{}, apierrors.ErrBadOption.WithDetail("%s must be COLSxROWS, got %q", HeaderTerminalSize, header)
+		}
+	}
+
+	for _, dim := range []struct {
+		name  string
+		value *int
+		max   int
+	}{
+		{"cols", &term.cols, maxCols},
+		{"rows", &term.rows, maxRows},
+	} {
+		if raw := c.Query(dim.name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return terminal{}, apierrors.ErrBadOption.WithDetail("%s must be a number, got %q", dim.name, raw)
+			}
+			*dim.value = n
+		}
+		if *dim.value < 0 || *dim.value > dim.max {
+			return terminal{}, apierrors.ErrBadOption.WithDetail("%s must be between 0 and %d, got %d", dim.name, dim.max, *dim.value)
+		}
+	}
+	term.colors = render.TermColorDepth(c.Query("term"))
+	if h.noColor(c) {
+		term.colors = render.NoColor
+	}
+	return term, nil
+}
+
+// noColor reports whether output to the client leaves colors out: as ?nc=
+// says, or else for non-interactive clients as
+// SHOUT_TERMINAL_NON_INTERACTIVE_NO_COLOR says, or as
+// SHOUT_TERMINAL_NO_COLOR says.
+func (h *Handler) noColor(c *fiber.Ctx) bool {
+	if c.Query("nc") != "" {
+		return c.QueryBool("nc")
+	}
+	cfg := h.config()
+	if cfg == nil {
+		return false
+	}
+	if cfg.Terminal.NonInteractiveNoColor && len(cfg.Terminal.NonInteractiveAgents) > 0 {
+		c.Vary(fiber.HeaderUserAgent, fiber.HeaderAccept)
+		if nonInteractive(c, cfg.Terminal.NonInteractiveAgents) {
+			return true
+		}
+	}
+	return cfg.Terminal.NoColor
+}
+
+// nonInteractive reports whether the request looks like it comes from a
+// CI job rather than a terminal: one of agents is in its User-Agent, and
+// it asks for text/plain.
+func nonInteractive(c *fiber.Ctx, agents []string) bool {
+	if !strings.Contains(strings.ToLower(c.Get(fiber.HeaderAccept)), fiber.MIMETextPlain) {
+		return false
+	}
+	userAgent := strings.ToLower(c.Get(fiber.HeaderUserAgent))
+	for _, agent := range agents {
+		if agent != "" && strings.Contains(userAgent, strings.ToLower(agent)) {
+			return true
+		}
+	}
+	return false
+}
+
+// asciiOnly reports whether the request asks for output in 7-bit ASCII,
+// with ?ascii=strict.
+func asciiOnly(c *fiber.Ctx) (bool, error) {
+	switch ascii := c.Query("ascii"); ascii {
+	case "":
+		return false, nil
+	case asciiStrict:
+		return true, nil
+	default:
+		return false, apierrors.ErrBadOption.WithDetail("ascii must be %s, got %q", asciiStrict, ascii)
+	}
+}
+
+// withASCII returns term set up for the ?ascii=strict option of the
+// request: for 7-bit ASCII output, without colors, when it is given.
+func withASCII(c *fiber.Ctx, term terminal) (terminal, error) {
+	ascii, err := asciiOnly(c)
+	if err != nil {
+		return term, err
+	}
+	if term.ascii = ascii; ascii {
+		term.colors = render.NoColor
+	}
+	return term, nil
+}
+
+// stream returns the part of the terminal a stream may draw in: all but
+// the last column and row, so redrawing a frame never wraps a line or
+// scrolls the screen.
+func (t terminal) stream() terminal {
+	shrink := func(n int) int {
+		if n > 1 {
+			return n - 1
+		}
+		return n
+	}
+	return terminal{cols: shrink(t.cols), rows: shrink(t.rows), colors: t.colors}
+}
+
+// wrap breaks text between words so it fits the terminal width when drawn
+// in font. Text is left as is when the width is unknown.
+func (t terminal) wrap(ctx context.Context, font *render.Font, text string) (string, error) {
+	return render.WrapText(ctx, font, text, t.cols)
+}
+
+// crop cuts layout down to the terminal size, keeping the top left.
+func (t terminal) crop(layout *render.Layout) *render.Layout {
+	return render.CropLayout(layout, t.cols, t.rows)
+}
+
+// alignment returns how text lines are placed across the terminal: the
+// align option, or SHOUT_TEXT_DEFAULT_ALIGN.
+func (h *Handler) alignment(opts types.RenderOptions) string {
+	cfg := h.config()
+	if opts.Align == "" && cfg != nil {
+		return cfg.Text.DefaultAlign
+	}
+	return opts.Align
+}
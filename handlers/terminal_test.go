@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+func TestTerminalSize(t *testing.T) {
+	h := newTestHandler(t)
+	h.config().Terminal.Cols, h.config().Terminal.Rows = 80, 24
+	h.config().Terminal.MaxCols, h.config().Terminal.MaxRows = 200, 100
+
+	tests := []struct {
+		name    string
+		query   string
+		header  string
+		want    terminal
+		wantErr bool
+	}{
+		{name: "config default", want: terminal{cols: 80, rows: 24}},
+		{name: "query", query: "cols=120&rows=40", want: terminal{cols: 120, rows: 40}},
+		{name: "header", header: "132x43", want: terminal{cols: 132, rows: 43}},
+		{name: "query overrides header", query: "cols=100", header: "132x43", want: terminal{cols: 100, rows: 43}},
+		{name: "zero is unknown", query: "cols=0&rows=0", want: terminal{}},
+		{name: "above max cols", query: "cols=201", wantErr: true},
+		{name: "above max rows", header: "80x101", wantErr: true},
+		{name: "negative", query: "rows=-1", wantErr: true},
+		{name: "invalid query", query: "cols=wide", wantErr: true},
+		{name: "invalid header", header: "80 by 24", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got terminal
+			var err error
+			app.Get("/", func(c *fiber.Ctx) error {
+				got, err = h.terminalSize(c)
+				return nil
+			})
+			req := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderTerminalSize, tt.header)
+			}
+			doRequest(t, app, req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("terminalSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("terminalSize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalCrop(t *testing.T) {
+	layout := &render.Layout{Rows: []string{"abcdef", "ab", "abcdef"}, RowLines: []int{0, 0, 0}}
+
+	if got := (terminal{}).crop(layout); got != layout {
+		t.Error("an unknown size should leave the layout as is")
+	}
+	got := terminal{cols: 4, rows: 2}.crop(layout)
+	if want := []string{"abcd", "ab"}; !reflect.DeepEqual(got.Rows, want) {
+		t.Errorf("crop() rows = %q, want %q", got.Rows, want)
+	}
+	if len(got.RowLines) != 2 {
+		t.Errorf("crop() kept %d row lines, want 2", len(got.RowLines))
+	}
+	if want := (terminal{cols: 79, rows: 23}); (terminal{cols: 80, rows: 24}).stream() != want {
+		t.Errorf("stream() = %+v, want %+v", terminal{cols: 80, rows: 24}.stream(), want)
+	}
+}
+
+func TestStaticTerminalSize(t *testing.T) {
+	h := newTestHandler(t)
+	app := newTestAppFrom(h)
+	wrapped := renderText(t, h, "HELLO\nWORLD")
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HELLO+WORLD?f=small&cols=40", nil))
+	if resp.StatusCode != 200 || body != wrapped {
+		t.Errorf("cols=40: status %d, body\n%s\nwant\n%s", resp.StatusCode, body, wrapped)
+	}
+	if !strings.Contains(resp.Header.Get("Vary"), HeaderTerminalSize) {
+		t.Errorf("Vary = %q, want it to include %s", resp.Header.Get("Vary"), HeaderTerminalSize)
+	}
+	etag := resp.Header.Get("ETag")
+
+	req := httptest.NewRequest("GET", "/HELLO+WORLD?f=small", nil)
+	req.Header.Set(HeaderTerminalSize, "40x24")
+	if _, body := doRequest(t, app, req); body != wrapped {
+		t.Errorf("%s: 40x24 gave\n%s", HeaderTerminalSize, body)
+	}
+
+	// Right alignment ends the widest row of each line at the edge
+	resp, body = doRequest(t, app, httptest.NewRequest("GET", "/HELLO+WORLD?f=small&cols=40&a=right", nil))
+	if resp.Header.Get("ETag") == etag {
+		t.Error("alignment should change the ETag")
+	}
+	widest := 0
+	for _, row := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		widest = max(widest, len(row))
+	}
+	if widest != 40 {
+		t.Errorf("right aligned rows reach column %d, want 40:\n%s", widest, body)
+	}
+
+	resp, _ = doRequest(t, app, httptest.NewRequest("GET", "/HELLO?cols=1000", nil))
+	if resp.StatusCode != 400 {
+		t.Errorf("cols=1000: status %d, want 400", resp.StatusCode)
+	}
+}
+
+// ansiEscape matches the escape sequences of a stream.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+func TestPartyTerminalSize(t *testing.T) {
+	app := newTestAppFrom(newPartyHandler(t))
+
+	_, body := doRequest(t, app, httptest.NewRequest("GET", "/p/HELLO+WORLD?f=small&anim=scroll&format=cast&fps=20&t=1&cols=20&rows=3", nil))
+	_, events := parseCast(t, body)
+	if len(events) == 0 {
+		t.Fatal("no frames streamed")
+	}
+	for _, event := range events {
+		frame := ansiEscape.ReplaceAllString(event[2].(string), "")
+		lines := strings.Split(strings.TrimRight(frame, "\n"), "\n")
+		if len(lines) > 2 {
+			t.Errorf("frame has %d lines, want at most 2 in 3 rows:\n%s", len(lines), frame)
+		}
+		for _, line := range lines {
+			if n := len([]rune(line)); n > 19 {
+				t.Errorf("line is %d columns, want at most 19 in 20 cols: %q", n, line)
+			}
+		}
+	}
+}
+
+func TestTerminalColors(t *testing.T) {
+	app := newTestAppFrom(newPartyHandler(t))
+
+	tests := []struct {
+		path    string
+		want    string
+		notWant string
+	}{
+		{path: "/HI?f=small&c=fire", want: "\x1b[38;2;"},
+		{path: "/HI?f=small&c=fire&term=xterm-256color", want: "\x1b[38;5;", notWant: "\x1b[38;2;"},
+		{path: "/HI?f=small&c=fire&term=linux", want: "\x1b[9", notWant: "\x1b[38;"},
+		{path: "/HI?f=small&c=fire&term=dumb", notWant: "\x1b["},
+		{path: "/p/HI?f=small&t=1&term=dumb", notWant: "\x1b[38;"},
+	}
+
+	for _, tt := range tests {
+		_, body := doRequest(t, app, httptest.NewRequest("GET", tt.path, nil))
+		if tt.want != "" && !strings.Contains(body, tt.want) {
+			t.Errorf("%s: output has no %q", tt.path, tt.want)
+		}
+		if tt.notWant != "" && strings.Contains(body, tt.notWant) {
+			t.Errorf("%s: output has %q", tt.path, tt.notWant)
+		}
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	const ciAgent = "GitLab-Runner 16.0"
+
+	tests := []struct {
+		name      string
+		noColor   bool
+		path      string
+		userAgent string
+		accept    string
+		wantColor bool
+	}{
+		{name: "terminal", path: "/HI?c=fire", userAgent: "curl/8.0", wantColor: true},
+		{name: "nc", path: "/HI?c=fire&nc=1", userAgent: "curl/8.0"},
+		{name: "nc on a stream", path: "/p/HI?t=1&nc=true"},
+		{name: "ci accepting text", path: "/HI?c=fire", userAgent: ciAgent, accept: "text/plain"},
+		{name: "ci accepting anything", path: "/HI?c=fire", userAgent: ciAgent, accept: "*/*", wantColor: true},
+		{name: "ci asking for colors", path: "/HI?c=fire&nc=0", userAgent: ciAgent, accept: "text/plain", wantColor: true},
+		{name: "no color by default", noColor: true, path: "/HI?c=fire"},
+		{name: "colors asked for", noColor: true, path: "/HI?c=fire&nc=0", wantColor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newPartyHandler(t)
+			h.config().Terminal = config.TerminalConfig{
+				MaxCols:               500,
+				MaxRows:               200,
+				NoColor:               tt.noColor,
+				NonInteractiveNoColor: true,
+				NonInteractiveAgents:  []string{"gitlab", "Jenkins"},
+			}
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, body := doRequest(t, newTestAppFrom(h), req)
+			if got := strings.Contains(body, "\x1b[38;"); got != tt.wantColor {
+				t.Errorf("colored = %v, want %v:\n%s", got, tt.wantColor, body)
+			}
+			if vary := resp.Header.Get("Vary"); !strings.Contains(vary, "User-Agent") && !strings.Contains(tt.path, "nc=") {
+				t.Errorf("Vary = %q, want User-Agent", vary)
+			}
+		})
+	}
+
+	// Detection can be turned off
+	h := newPartyHandler(t)
+	h.config().Terminal = config.TerminalConfig{MaxCols: 500, MaxRows: 200, NonInteractiveAgents: []string{"gitlab"}}
+	req := httptest.NewRequest("GET", "/HI?c=fire", nil)
+	req.Header.Set("User-Agent", ciAgent)
+	req.Header.Set("Accept", "text/plain")
+	if _, body := doRequest(t, newTestAppFrom(h), req); !strings.Contains(body, "\x1b[38;") {
+		t.Errorf("banner for a CI client with detection off has no colors:\n%s", body)
+	}
+}
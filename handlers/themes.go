@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// themes returns the themes of SHOUT_TEXT_THEMES, in the order configured.
+// Entries that don't parse are skipped; config.Validate rejects them
+// before they get here.
+func (h *Handler) themes() []types.Theme {
+	cfg := h.config()
+	if cfg == nil {
+		return nil
+	}
+	themes := make([]types.Theme, 0, len(cfg.Text.Themes))
+	for _, entry := range cfg.Text.Themes {
+		if theme, err := types.ParseTheme(entry); err == nil {
+			themes = append(themes, theme)
+		}
+	}
+	return themes
+}
+
+// theme returns the theme called name, none for an empty name, or
+// ErrBadOption for a theme that isn't configured.
+func (h *Handler) theme(name string) (types.Theme, error) {
+	if name == "" {
+		return types.Theme{}, nil
+	}
+	themes := h.themes()
+	names := make([]string, len(themes))
+	for i, theme := range themes {
+		if theme.Name == name {
+			return theme, nil
+		}
+		names[i] = theme.Name
+	}
+	err := apierrors.ErrBadOption.WithDetail("unknown theme %q", name)
+	if len(names) == 0 {
+		return types.Theme{}, err.WithHint("this server has no themes")
+	}
+	return types.Theme{}, err.WithHint("use one of " + strings.Join(names, ", "))
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// newThemesHandler builds a handler with an "ocean" theme filling every
+// slot and a "plain" one setting only a border, a "boxed" preset using
+// the plain theme and a "painted" one setting the slots itself.
+func newThemesHandler(t *testing.T) *Handler {
+	t.Helper()
+	h := newTestHandler(t)
+	h.config().Text.Themes = []string{"ocean:fg=blue&bg=black&accent=gold&border=rounded&pad=1", "plain:border=single"}
+	h.config().Text.Presets = []string{"boxed:theme=plain&c=red&f=small", "painted:c=green&b=double"}
+	h.config().Text.DefaultColor = "magenta"
+	return h
+}
+
+func TestThemePrecedence(t *testing.T) {
+	app := newTestAppFrom(newThemesHandler(t))
+
+	tests := []struct {
+		name  string
+		query string
+		want  types.RenderOptions
+	}{
+		{
+			name:  "theme fills its slots",
+			query: "theme=ocean",
+			want:  types.RenderOptions{Theme: "ocean", Color: "blue", Background: "black", Accent: "gold", Border: "rounded", Padding: 1},
+		},
+		{
+			name:  "request overrides the theme",
+			query: "theme=ocean&c=red&accent=white&b=none",
+			want:  types.RenderOptions{Theme: "ocean", Color: "red", Background: "black", Accent: "white", Border: "none", Padding: 1},
+		},
+		{
+			name:  "theme overrides the preset",
+			query: "theme=ocean&preset=painted",
+			want:  types.RenderOptions{Theme: "ocean", Color: "blue", Background: "black", Accent: "gold", Border: "rounded", Padding: 1},
+		},
+		{
+			name:  "preset fills what the theme leaves out",
+			query: "theme=plain&preset=painted",
+			want:  types.RenderOptions{Theme: "plain", Color: "green", Border: "single"},
+		},
+		{
+			name:  "server defaults fill the rest",
+			query: "theme=plain",
+			want:  types.RenderOptions{Theme: "plain", Color: "magenta", Border: "single"},
+		},
+		{
+			name:  "preset's theme",
+			query: "preset=boxed",
+			want:  types.RenderOptions{Theme: "plain", Color: "red", Border: "single"},
+		},
+		{
+			name:  "request theme overrides the preset's",
+			query: "preset=boxed&theme=ocean",
+			want:  types.RenderOptions{Theme: "ocean", Color: "blue", Background: "black", Accent: "gold", Border: "rounded", Padding: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", "/validate/HI?"+tt.query, nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+			}
+			var v validation
+			if err := json.Unmarshal([]byte(body), &v); err != nil {
+				t.Fatalf("decoding %q: %v", body, err)
+			}
+			got := types.RenderOptions{
+				Theme:      v.Options.Theme,
+				Color:      v.Options.Color,
+				Background: v.Options.Background,
+				Accent:     v.Options.Accent,
+				Border:     v.Options.Border,
+				Padding:    v.Options.Padding,
+			}
+			if got != tt.want {
+				t.Errorf("options = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemeRender(t *testing.T) {
+	app := newTestAppFrom(newThemesHandler(t))
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?theme=ocean", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	// The border in gold on black, and the text in blue
+	for _, want := range []string{"\x1b[48;2;0;0;0m", "\x1b[38;2;255;215;0m╭", "\x1b[38;2;0;0;255m"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q: %q", want, body)
+		}
+	}
+
+	// Colors left out, the box is still drawn
+	_, body = doRequest(t, app, httptest.NewRequest("GET", "/HI?theme=ocean&term=dumb", nil))
+	if strings.Contains(body, "\x1b[") || !strings.HasPrefix(body, "╭") {
+		t.Errorf("expected an uncolored box, got %q", body)
+	}
+}
+
+func TestThemeUnknown(t *testing.T) {
+	app := newTestAppFrom(newThemesHandler(t))
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/HI?theme=forest", nil))
+	if resp.StatusCode != 400 {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+	for _, want := range []string{`unknown theme "forest"`, "use one of ocean, plain"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q: %s", want, body)
+		}
+	}
+}
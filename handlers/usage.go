@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Usage labels of colors that aren't a preset.
+const (
+	usageNoColor     = "none"
+	usageCustomColor = "custom"
+)
+
+// usageDefaultAnimation labels streams and gifs that name no animation.
+const usageDefaultAnimation = "colors"
+
+// countUsage counts a render in the usage analytics, unless
+// SHOUT_ANALYTICS_ENABLED is off: its font, resolved as the render
+// resolves it, its color preset, or custom or none, and, if animated is
+// set, the animations named in anim, without any preset. A preset in anim
+// counts as the color when color is empty. Only loaded fonts and known
+// names are counted, so the counters stay few.
+func (h *Handler) countUsage(font, color, anim string, animated bool) {
+	if cfg := h.config(); cfg != nil && !cfg.Analytics.Enabled {
+		return
+	}
+	if f := h.fonts.ResolveFont(font, h.defaultFont()); f != nil {
+		h.metrics.AddUsage(types.UsageFont, f.Name)
+	}
+	names, preset := animationUsage(anim)
+	h.metrics.AddUsage(types.UsageColor, colorUsage(cmp.Or(color, preset)))
+	if animated {
+		h.metrics.AddUsage(types.UsageAnimation, names)
+	}
+}
+
+// colorUsage labels a ?color= value: the preset it names, custom for
+// colors and lists, or none.
+func colorUsage(color string) string {
+	color = strings.ToLower(strings.TrimSpace(color))
+	switch {
+	case color == "":
+		return usageNoColor
+	case slices.Contains(render.PresetNames(), color):
+		return color
+	default:
+		return usageCustomColor
+	}
+}
+
+// animationUsage labels a valid ?anim= spec by its animations, sorted and
+// joined with +, and returns the color preset it names apart:
+// scroll+typewriter+fire and FIRE+typewriter+scroll are both
+// scroll+typewriter with fire, and an empty spec or a preset alone is
+// colors, the default animation.
+func animationUsage(spec string) (string, string) {
+	var names []string
+	var preset string
+	for _, part := range strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool { return r == '+' || r == ' ' }) {
+		if slices.Contains(render.PresetNames(), part) {
+			preset = part
+		} else {
+			names = append(names, part)
+		}
+	}
+	if len(names) == 0 {
+		return usageDefaultAnimation, preset
+	}
+	slices.Sort(names)
+	return strings.Join(names, "+"), preset
+}
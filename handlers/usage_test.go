@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestUsageAnalytics(t *testing.T) {
+	tests := []struct {
+		name           string
+		disabled       bool
+		wantFonts      map[string]int64
+		wantColors     map[string]int64
+		wantAnimations map[string]int64
+	}{
+		{
+			name:           "enabled",
+			wantFonts:      map[string]int64{"doom": 2, "small": 1, "standard": 2},
+			wantColors:     map[string]int64{"fire": 2, "custom": 1, "none": 2},
+			wantAnimations: map[string]int64{"typewriter": 1},
+		},
+		{
+			name:           "opted out",
+			disabled:       true,
+			wantFonts:      map[string]int64{},
+			wantColors:     map[string]int64{},
+			wantAnimations: map[string]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newPartyHandler(t)
+			h.config().Analytics.Enabled = !tt.disabled
+			app := newTestAppFrom(h)
+			admin := fiber.New()
+			h.RegisterAdmin(admin)
+
+			for _, path := range []string{
+				"/HI?f=doom&c=fire",
+				"/HI?f=dooom&c=%23ff8800",
+				"/HI",
+				"/p/HI?f=small&anim=FIRE%2Btypewriter&s=10&loops=1",
+				// Unknown fonts count as the default they are drawn in
+				"/HI?f=nonesuch",
+			} {
+				doRequest(t, app, httptest.NewRequest("GET", path, nil))
+			}
+
+			_, body := doRequest(t, admin, httptest.NewRequest("GET", "/stats", nil))
+			var got struct {
+				Usage struct {
+					Fonts      map[string]int64 `json:"fonts"`
+					Colors     map[string]int64 `json:"colors"`
+					Animations map[string]int64 `json:"animations"`
+				} `json:"usage"`
+			}
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if !maps.Equal(got.Usage.Fonts, tt.wantFonts) {
+				t.Errorf("fonts = %v, want %v", got.Usage.Fonts, tt.wantFonts)
+			}
+			if !maps.Equal(got.Usage.Colors, tt.wantColors) {
+				t.Errorf("colors = %v, want %v", got.Usage.Colors, tt.wantColors)
+			}
+			if !maps.Equal(got.Usage.Animations, tt.wantAnimations) {
+				t.Errorf("animations = %v, want %v", got.Usage.Animations, tt.wantAnimations)
+			}
+
+			_, metrics := doRequest(t, admin, httptest.NewRequest("GET", "/metrics", nil))
+			if want := `shout_font_renders_total{font="doom"} 2`; !tt.disabled && !strings.Contains(metrics, want) {
+				t.Errorf("metrics missing %q", want)
+			}
+		})
+	}
+}
+
+func TestAnimationUsage(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantAnim   string
+		wantPreset string
+	}{
+		{spec: "", wantAnim: "colors"},
+		{spec: "fire", wantAnim: "colors", wantPreset: "fire"},
+		{spec: "typewriter+fire", wantAnim: "typewriter", wantPreset: "fire"},
+		{spec: "Typewriter Scroll", wantAnim: "scroll+typewriter"},
+		{spec: "scroll+typewriter", wantAnim: "scroll+typewriter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			anim, preset := animationUsage(tt.spec)
+			if anim != tt.wantAnim || preset != tt.wantPreset {
+				t.Errorf("animationUsage(%q) = %q, %q, want %q, %q", tt.spec, anim, preset, tt.wantAnim, tt.wantPreset)
+			}
+		})
+	}
+}
+
+func TestColorUsage(t *testing.T) {
+	tests := map[string]string{
+		"":               "none",
+		"fire":           "fire",
+		"RAINBOW":        "rainbow",
+		"#ff8800":        "custom",
+		"red,green,blue": "custom",
+	}
+	for color, want := range tests {
+		if got := colorUsage(color); got != want {
+			t.Errorf("colorUsage(%q) = %q, want %q", color, got, want)
+		}
+	}
+}
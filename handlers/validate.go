@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// validation describes how a static render request would be served, as
+// GET /validate reports it.
+type validation struct {
+	Text string `json:"text"`
+	// Font is the font named, after presets and defaults, and the font
+	// that would draw the banner
+	Font struct {
+		Requested string `json:"requested"`
+		Resolved  string `json:"resolved"`
+	} `json:"font"`
+	// Options are the render options after presets, defaults and limits
+	Options types.RenderOptions `json:"options"`
+	Colors  struct {
+		Color string `json:"color"`
+		Mode  string `json:"mode"`
+		// Terminal is the color depth output is reduced to
+		Terminal string `json:"terminal"`
+	} `json:"colors"`
+	Format string `json:"format"`
+	// Cols is the terminal width text wraps and aligns within, 0 unknown
+	Cols  int    `json:"cols"`
+	Align string `json:"align"`
+	// Width and Height are those of the response body, as X-Shout-Width
+	// and X-Shout-Height report them
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Warnings are what the banner would draw differently from the
+	// request, as the ?notes= line says
+	Warnings []string `json:"warnings"`
+	ETag     string   `json:"etag"`
+}
+
+// Validate reports, as JSON, how the render of the text in the request
+// path would be interpreted: the font and colors resolved, the options
+// after presets and limits, the size of the output and any fallback,
+// without sending the banner. It takes the parameters of the render
+// route, and refuses what rendering would refuse with the same error.
+// The text is read from middleware.InputValidation, which must run first.
+//
+// Example:
+//
+//	GET /validate/HELLO+WORLD?font=doom&cols=80
+func (h *Handler) Validate(c *fiber.Ctx) error {
+	text := middleware.Text(c)
+	if text == "" {
+		return apierrors.Send(c, apierrors.ErrNoText)
+	}
+	opts, err := h.parseOptions(c)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+	banner, err := h.prepareStatic(c, text, opts, middleware.Masked(c), false)
+	if err != nil {
+		return apierrors.Send(c, err)
+	}
+
+	// The size is measured on the banner itself, which the render cache
+	// keeps for the render that follows
+	ctx, cancel := h.renderContext(c, banner.opts.Timeout)
+	defer cancel()
+	output, _, err := h.drawStatic(ctx, banner)
+	if err != nil {
+		return h.renderFailed(c, err, banner.suggestion)
+	}
+
+	var v validation
+	v.Text = banner.text
+	v.Font.Requested = banner.opts.Font
+	v.Font.Resolved = banner.font.Name
+	v.Options = banner.opts
+	v.Colors.Color = banner.opts.Color
+	v.Colors.Mode = banner.opts.ColorMode
+	v.Colors.Terminal = banner.term.colors.String()
+	v.Format = banner.format
+	v.Cols = banner.term.cols
+	v.Align = banner.align
+	v.Width, v.Height = outputSize(output)
+	v.Warnings = banner.fallback.messages()
+	if v.Warnings == nil {
+		v.Warnings = []string{}
+	}
+	v.ETag = banner.etag
+	return c.JSON(v)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+func TestValidate(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := doRequest(t, app, httptest.NewRequest("GET", "/validate/H%E2%98%83?f=dooom&c=fire&cols=80&a=left&term=xterm", nil))
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 (body %q)", resp.StatusCode, body)
+	}
+	var got validation
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Text != "H☃" || got.Font.Requested != "dooom" || got.Font.Resolved != "doom" {
+		t.Errorf("text and font = %q, %+v, want H☃ in doom for dooom", got.Text, got.Font)
+	}
+	if got.Colors.Color != "fire" || got.Colors.Terminal != "16" || got.Cols != 80 || got.Align != "left" {
+		t.Errorf("validation = %+v, want fire in 16 colors, left in 80 columns", got)
+	}
+	if len(got.Warnings) != 2 {
+		t.Errorf("warnings = %q, want the font fallback and the missing character", got.Warnings)
+	}
+
+	// The size is that of the banner the render route sends
+	resp, _ = doRequest(t, app, httptest.NewRequest("GET", "/H%E2%98%83?f=dooom&c=fire&cols=80&a=left&term=xterm", nil))
+	if resp.Header.Get(HeaderWidth) != strconv.Itoa(got.Width) || resp.Header.Get(HeaderHeight) != strconv.Itoa(got.Height) {
+		t.Errorf("size = %dx%d, render sent %sx%s", got.Width, got.Height, resp.Header.Get(HeaderWidth), resp.Header.Get(HeaderHeight))
+	}
+	if resp.Header.Get("ETag") != got.ETag {
+		t.Errorf("etag = %s, render sent %s", got.ETag, resp.Header.Get("ETag"))
+	}
+}
+
+func TestValidateRefused(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid option", target: "/validate/HI?a=diagonal", wantStatus: 400, wantCode: "bad_option"},
+		{name: "strict fallback", target: "/validate/HI?f=dooom&strict=true", wantStatus: 400, wantCode: "fallback"},
+		{name: "unknown format", target: "/validate/HI?format=pdf", wantStatus: 400, wantCode: "bad_option"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := resp.Header.Get(apierrors.HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("%s = %q, want %q", apierrors.HeaderErrorCode, got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/middleware"
+)
+
+// hostLimiter limits each client of a virtual host with a rate limit of
+// its own to that many requests per minute, with a limiter per host.
+// Requests with an API key follow their tier's limit instead. It passes
+// everything through when virtual hosts are off.
+func (h *Handler) hostLimiter() fiber.Handler {
+	if h.vhosts == nil {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	var mu sync.Mutex
+	limiters := make(map[string]fiber.Handler)
+	return func(c *fiber.Ctx) error {
+		host := middleware.Host(c)
+		if host == nil || host.RequestsPerMinute == 0 || middleware.Key(c) != nil || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		mu.Lock()
+		limit, ok := limiters[host.Name]
+		if !ok {
+			perMinute, name := host.RequestsPerMinute, host.Name
+			limit = limiter.New(limiter.Config{
+				Max:          perMinute,
+				Expiration:   time.Minute,
+				KeyGenerator: clientID,
+				LimitReached: func(c *fiber.Ctx) error {
+					return apierrors.Send(c, apierrors.ErrRateLimited.WithDetail("%d requests per minute on %s", perMinute, name))
+				},
+			})
+			limiters[host.Name] = limit
+		}
+		mu.Unlock()
+		return limit(c)
+	}
+}
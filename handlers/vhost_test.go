@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/vhost"
+)
+
+// newVHostHandler builds a test handler serving the hosts in content.
+func newVHostHandler(t *testing.T, content string) *Handler {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := vhost.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := newTestHandler(t)
+	return New(Deps{Config: base.config(), Fonts: base.fonts, VHosts: hosts})
+}
+
+func TestVHostDefaults(t *testing.T) {
+	h := newVHostHandler(t, `{"hosts": {
+		"shout.example.com": {"font": "doom", "color": "fire", "align": "left", "footer": "-- shout.example.com"},
+		"yell.example.org": {"font": "small"}
+	}}`)
+	app := newTestAppFrom(h)
+
+	tests := []struct {
+		name   string
+		target string
+		// same is a request on the default host with the same output
+		same   string
+		footer string
+	}{
+		{
+			name:   "host defaults",
+			target: "http://shout.example.com/HI",
+			same:   "/HI?f=doom&c=fire&a=left",
+			footer: "-- shout.example.com\n",
+		},
+		{
+			name:   "request options win",
+			target: "http://shout.example.com/HI?f=small&c=ocean",
+			same:   "/HI?f=small&c=ocean&a=left",
+			footer: "-- shout.example.com\n",
+		},
+		{
+			name:   "host without footer",
+			target: "http://yell.example.org:8080/HI",
+			same:   "/HI?f=small",
+		},
+		{
+			name:   "unlisted host",
+			target: "http://other.example.net/HI",
+			same:   "/HI",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doRequest(t, app, httptest.NewRequest("GET", tt.target, nil))
+			if resp.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200: %s", resp.StatusCode, body)
+			}
+			sameResp, want := doRequest(t, app, httptest.NewRequest("GET", tt.same, nil))
+			if body != want+tt.footer {
+				t.Errorf("body =\n%s\nwant\n%s", body, want+tt.footer)
+			}
+			if tt.footer != "" && resp.Header.Get("ETag") == sameResp.Header.Get("ETag") {
+				t.Error("ETag doesn't change with the footer")
+			}
+		})
+	}
+}
+
+func TestVHostRateLimit(t *testing.T) {
+	h := newVHostHandler(t, `{"hosts": {
+		"shout.example.com": {"requests_per_minute": 2},
+		"yell.example.org": {}
+	}}`)
+	app := newTestAppFrom(h)
+
+	for i, want := range []int{200, 200, 429} {
+		resp, _ := doRequest(t, app, httptest.NewRequest("GET", "http://shout.example.com/HI", nil))
+		if resp.StatusCode != want {
+			t.Errorf("request %d on the limited host: status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+	// Other hosts keep the server's limits
+	for i := range 3 {
+		resp, _ := doRequest(t, app, httptest.NewRequest("GET", "http://yell.example.org/HI", nil))
+		if resp.StatusCode != 200 {
+			t.Errorf("request %d on another host: status = %d, want 200", i+1, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/pkg/shout"
+)
+
+// Warmup statuses reported by /readyz.
+const (
+	warmupWarming = "warming"
+	warmupReady   = "ready"
+	warmupFailed  = "failed"
+)
+
+// warmupState is the progress of the startup warmup.
+type warmupState struct {
+	// Status is warming until every banner has been rendered, then ready,
+	// or failed if any of them couldn't be
+	Status  string         `json:"status"`
+	Results []warmupResult `json:"warmup"`
+}
+
+// warmupResult is the outcome of rendering one SHOUT_FONTS_WARMUP banner.
+type warmupResult struct {
+	Font string `json:"font"`
+	Text string `json:"text"`
+	// Millis is how long the render took, font parsing included
+	Millis int64 `json:"ms"`
+	// Error is why the banner couldn't be rendered; empty if it was
+	Error string `json:"error,omitempty"`
+}
+
+// newWarmupState returns the state of a warmup that hasn't run yet:
+// warming if there are banners to render, otherwise ready.
+func (h *Handler) newWarmupState() *warmupState {
+	if cfg := h.config(); cfg != nil && len(cfg.Fonts.Warmup) > 0 {
+		return &warmupState{Status: warmupWarming, Results: []warmupResult{}}
+	}
+	return &warmupState{Status: warmupReady, Results: []warmupResult{}}
+}
+
+// Warmup renders the SHOUT_FONTS_WARMUP banners, so their fonts are
+// parsed and cached before the first request needs them. Each result is
+// logged, and /readyz reports ready once every banner has rendered, or
+// failed if any couldn't, such as a font that's missing or broken.
+//
+// Parameters:
+//   - ctx: cancels the banners not yet rendered
+//
+// Returns:
+//   - error: the banners that failed, joined
+//
+// Example:
+//
+//	go func() {
+//	    if err := h.Warmup(ctx); err != nil {
+//	        log.Printf("Warning: %v", err)
+//	    }
+//	}()
+func (h *Handler) Warmup(ctx context.Context) error {
+	cfg := h.config()
+	if cfg == nil || len(cfg.Fonts.Warmup) == 0 {
+		return nil
+	}
+
+	state := &warmupState{Status: warmupReady, Results: []warmupResult{}}
+	var errs []error
+	for _, entry := range cfg.Fonts.Warmup {
+		font, text, _ := strings.Cut(entry, ":")
+		result := warmupResult{Font: font, Text: text}
+		start := time.Now()
+		err := h.warmupRender(ctx, font, text)
+		result.Millis = time.Since(start).Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+			state.Status = warmupFailed
+			errs = append(errs, fmt.Errorf("warmup of %q in %s failed: %w", text, font, err))
+			log.Printf("Warmup of %q in %s failed: %v", text, font, err)
+		} else {
+			log.Printf("Warmup rendered %q in %s in %dms", text, font, result.Millis)
+		}
+		state.Results = append(state.Results, result)
+	}
+	h.warmup.Store(state)
+	return errors.Join(errs...)
+}
+
+// warmupRender renders text in font within the static render budget.
+// Unlike a request, it doesn't fall back to another font.
+func (h *Handler) warmupRender(ctx context.Context, font, text string) error {
+	if _, ok := h.fonts.GetFont(font); !ok {
+		return fmt.Errorf("font %q isn't loaded", font)
+	}
+	if cfg := h.config(); cfg != nil && cfg.Server.StaticTimeoutMS > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Server.StaticTimeoutMS)*time.Millisecond)
+		defer cancel()
+	}
+	_, err := h.shout.Render(ctx, text, shout.Options{Font: font})
+	return err
+}
+
+// Readyz reports whether the service is ready for traffic, with the
+// results of the startup warmup. It answers 503 while the warmup banners
+// are rendering, and after any of them failed.
+//
+// Example:
+//
+//	curl localhost:9090/readyz
+//	# {"status":"ready","warmup":[{"font":"doom","text":"HELLO","ms":4}]}
+func (h *Handler) Readyz(c *fiber.Ctx) error {
+	state := h.warmup.Load()
+	if state.Status != warmupReady {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	return c.JSON(state)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWarmup(t *testing.T) {
+	tests := []struct {
+		name       string
+		warmup     []string
+		wantStatus int
+		wantState  string
+		wantErrors []bool
+	}{
+		{
+			name:       "nothing to warm up",
+			wantStatus: 200,
+			wantState:  warmupReady,
+		},
+		{
+			name:       "every banner renders",
+			warmup:     []string{"doom:HELLO", "small:shout.sh"},
+			wantStatus: 200,
+			wantState:  warmupReady,
+			wantErrors: []bool{false, false},
+		},
+		{
+			name:       "missing font",
+			warmup:     []string{"doom:HELLO", "bloody:HELLO"},
+			wantStatus: fiber.StatusServiceUnavailable,
+			wantState:  warmupFailed,
+			wantErrors: []bool{false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			h.config().Fonts.Warmup = tt.warmup
+			h.warmup.Store(h.newWarmupState())
+			admin := fiber.New()
+			h.RegisterAdmin(admin)
+
+			if len(tt.warmup) > 0 {
+				if resp, body := doRequest(t, admin, httptest.NewRequest("GET", "/readyz", nil)); resp.StatusCode != fiber.StatusServiceUnavailable {
+					t.Errorf("status before warmup = %d, want 503 (body %q)", resp.StatusCode, body)
+				}
+			}
+
+			err := h.Warmup(context.Background())
+			if wantErr := tt.wantState == warmupFailed; (err != nil) != wantErr {
+				t.Errorf("Warmup() error = %v, want error %v", err, wantErr)
+			}
+
+			resp, body := doRequest(t, admin, httptest.NewRequest("GET", "/readyz", nil))
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			var got warmupState
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if got.Status != tt.wantState || len(got.Results) != len(tt.wantErrors) {
+				t.Fatalf("readyz = %+v, want %s with %d results", got, tt.wantState, len(tt.wantErrors))
+			}
+			for i, result := range got.Results {
+				if (result.Error != "") != tt.wantErrors[i] {
+					t.Errorf("result %d = %+v, want error %v", i, result, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
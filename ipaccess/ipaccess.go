@@ -0,0 +1,196 @@
+// Package ipaccess decides which client IPs may use the HTTP API, from
+// allow and deny lists of IPs and CIDR ranges. Lists come from the
+// environment and, optionally, a file that is reloaded when it changes.
+package ipaccess
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// List holds allowed and denied address ranges. The zero List allows
+// everyone.
+type List struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// Parse builds a list from IPs and CIDR ranges.
+//
+// Parameters:
+//   - allow: the only ranges served; empty serves everyone not denied
+//   - deny: ranges refused, even when allowed
+//
+// Returns:
+//   - *List: the list
+//   - error: error naming the first entry that isn't an IP or range
+//
+// Example:
+//
+//	list, err := ipaccess.Parse([]string{"10.0.0.0/8"}, []string{"10.6.6.6"})
+func Parse(allow, deny []string) (*List, error) {
+	l := &List{}
+	for _, entry := range allow {
+		if err := l.add(&l.allow, entry); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range deny {
+		if err := l.add(&l.deny, entry); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// add parses entry, an IP or CIDR range, onto prefixes.
+func (l *List) add(prefixes *[]netip.Prefix, entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+	prefix, err := netip.ParsePrefix(entry)
+	if err != nil {
+		addr, addrErr := netip.ParseAddr(entry)
+		if addrErr != nil {
+			return fmt.Errorf("%q is not an IP or CIDR range", entry)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	*prefixes = append(*prefixes, prefix.Masked())
+	return nil
+}
+
+// Allowed reports whether ip may use the API: it must not be denied, and
+// must be allowed if there is an allow list. Unparseable IPs are allowed
+// only when there are no lists at all.
+//
+// Parameters:
+//   - ip: the client IP, as Fiber's c.IP() returns it
+//
+// Returns:
+//   - bool: true if the client is served
+func (l *List) Allowed(ip string) bool {
+	if len(l.allow) == 0 && len(l.deny) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	if contains(l.deny, addr) {
+		return false
+	}
+	return len(l.allow) == 0 || contains(l.allow, addr)
+}
+
+// contains reports whether any of prefixes holds addr.
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load builds the list from the configured lists and file. The file has
+// one "allow <range>" or "deny <range>" per line; blank lines and lines
+// starting with # are ignored.
+//
+// Parameters:
+//   - cfg: access list settings
+//
+// Returns:
+//   - *List: the combined list
+//   - error: error if the file can't be read or has a bad line
+func Load(cfg config.AccessConfig) (*List, error) {
+	allow, deny := cfg.Allow, cfg.Deny
+	if cfg.File != "" {
+		data, err := os.ReadFile(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read access list: %w", err)
+		}
+		// Copy so the file's entries don't end up in cfg's backing arrays
+		allow, deny = append([]string(nil), allow...), append([]string(nil), deny...)
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			action, entry, _ := strings.Cut(line, " ")
+			switch action {
+			case "allow":
+				allow = append(allow, entry)
+			case "deny":
+				deny = append(deny, entry)
+			default:
+				return nil, fmt.Errorf("%s:%d: want \"allow <range>\" or \"deny <range>\", got %q", cfg.File, n, line)
+			}
+		}
+	}
+	list, err := Parse(allow, deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access list: %w", err)
+	}
+	return list, nil
+}
+
+// Guard holds the current list, which Reload swaps out while requests are
+// being checked against it.
+//
+// Usage example:
+//
+//	guard, err := ipaccess.NewGuard(cfg.Access)
+//	app.Use(middleware.IPAccess(guard))
+//	go guard.Watch(ctx)
+type Guard struct {
+	cfg  config.AccessConfig
+	list atomic.Pointer[List]
+}
+
+// NewGuard loads the configured list.
+//
+// Parameters:
+//   - cfg: access list settings
+//
+// Returns:
+//   - *Guard: the guard, or nil when no list is configured
+//   - error: error if the list can't be loaded
+func NewGuard(cfg config.AccessConfig) (*Guard, error) {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 && cfg.File == "" {
+		return nil, nil
+	}
+	g := &Guard{cfg: cfg}
+	if err := g.Reload(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Allowed reports whether the current list allows ip.
+func (g *Guard) Allowed(ip string) bool {
+	return g.list.Load().Allowed(ip)
+}
+
+// Reload re-reads the list. On error the current list stays in force.
+//
+// Returns:
+//   - error: error if the new list can't be loaded
+func (g *Guard) Reload() error {
+	list, err := Load(g.cfg)
+	if err != nil {
+		return err
+	}
+	g.list.Store(list)
+	return nil
+}
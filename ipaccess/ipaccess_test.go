@@ -0,0 +1,113 @@
+package ipaccess
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestListAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		ip    string
+		want  bool
+	}{
+		{name: "no lists", ip: "203.0.113.7", want: true},
+		{name: "denied range", deny: []string{"203.0.113.0/24"}, ip: "203.0.113.7", want: false},
+		{name: "outside denied range", deny: []string{"203.0.113.0/24"}, ip: "198.51.100.1", want: true},
+		{name: "allowed", allow: []string{"10.0.0.0/8"}, ip: "10.1.2.3", want: true},
+		{name: "not allowed", allow: []string{"10.0.0.0/8"}, ip: "192.168.1.1", want: false},
+		{name: "deny beats allow", allow: []string{"10.0.0.0/8"}, deny: []string{"10.6.6.6"}, ip: "10.6.6.6", want: false},
+		{name: "IPv6", allow: []string{"2001:db8::/32"}, ip: "2001:db8::1", want: true},
+		{name: "mapped IPv4", allow: []string{"10.0.0.0/8"}, ip: "::ffff:10.1.2.3", want: true},
+		{name: "bad IP with lists", deny: []string{"10.6.6.6"}, ip: "nope", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := Parse(tt.allow, tt.deny)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := list.Allowed(tt.ip); got != tt.want {
+				t.Errorf("Allowed(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Parse(nil, []string{"10.0.0.0/33"}); err == nil {
+		t.Error("Parse() accepted a bad range")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.txt")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("# office\nallow 10.0.0.0/8\n\ndeny 10.6.6.6\n")
+	list, err := Load(config.AccessConfig{Deny: []string{"10.7.7.7"}, File: path})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for ip, want := range map[string]bool{"10.1.1.1": true, "10.6.6.6": false, "10.7.7.7": false, "192.168.0.1": false} {
+		if got := list.Allowed(ip); got != want {
+			t.Errorf("Allowed(%s) = %v, want %v", ip, got, want)
+		}
+	}
+
+	write("block 10.0.0.0/8\n")
+	if _, err := Load(config.AccessConfig{File: path}); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("Load() error = %v, want the bad line", err)
+	}
+}
+
+func TestGuardWatch(t *testing.T) {
+	if guard, err := NewGuard(config.AccessConfig{}); guard != nil || err != nil {
+		t.Fatalf("NewGuard() without lists = %v, %v, want nil, nil", guard, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "access.txt")
+	if err := os.WriteFile(path, []byte("deny 10.6.6.6\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	guard, err := NewGuard(config.AccessConfig{File: path})
+	if err != nil {
+		t.Fatalf("NewGuard() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = guard.Watch(ctx) }()
+
+	if guard.Allowed("10.6.6.6") || !guard.Allowed("10.7.7.7") {
+		t.Fatal("initial list not in force")
+	}
+	// Give the watcher time to start before changing the file
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("deny 10.7.7.7\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for guard.Allowed("10.7.7.7") {
+		if time.Now().After(deadline) {
+			t.Fatal("list wasn't reloaded after the file changed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !guard.Allowed("10.6.6.6") {
+		t.Error("old entry still in force after reload")
+	}
+}
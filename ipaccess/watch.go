@@ -0,0 +1,78 @@
+package ipaccess
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the several events of one save into one reload.
+const reloadDebounce = 250 * time.Millisecond
+
+// Watch reloads the list whenever its file changes. The file's directory
+// is watched rather than the file, so editors that save by renaming a new
+// file over it are seen too. It returns at once when there is no file,
+// and otherwise blocks until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: cancels the watcher
+//
+// Returns:
+//   - error: error if the file's directory cannot be watched
+//
+// Example:
+//
+//	go func() {
+//	    if err := guard.Watch(ctx); err != nil {
+//	        log.Printf("Access list watcher stopped: %v", err)
+//	    }
+//	}()
+func (g *Guard) Watch(ctx context.Context) error {
+	if g.cfg.File == "" {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create access list watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	path := filepath.Clean(g.cfg.File)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to watch access list %s: %w", path, err)
+	}
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path {
+				debounce = time.After(reloadDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Warning: access list watcher error: %v", err)
+
+		case <-debounce:
+			debounce = nil
+			if err := g.Reload(); err != nil {
+				log.Printf("Warning: access list reload failed, keeping the old list: %v", err)
+				continue
+			}
+			log.Printf("Access list changed, reloaded %s", path)
+		}
+	}
+}
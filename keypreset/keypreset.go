@@ -0,0 +1,79 @@
+// Package keypreset keeps the option presets API keys save for
+// themselves, which requests with the key use as ?preset=@name.
+package keypreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Store backends, configured with SHOUT_KEY_PRESETS_STORE.
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// ErrNotFound is returned by Load and Delete for presets the key hasn't
+// saved.
+var ErrNotFound = errors.New("preset not found")
+
+// validName matches preset names: lowercase letters, digits and dashes,
+// as SHOUT_TEXT_PRESETS names are.
+var validName = regexp.MustCompile(`^[a-z0-9-]{1,64}$`)
+
+// ValidName reports whether name can name a saved preset.
+//
+// Parameters:
+//   - name: the preset name, without the @ of ?preset=@name
+//
+// Returns:
+//   - bool: true for 1 to 64 lowercase letters, digits and dashes
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// Store keeps presets by the name of the key that saved them.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores opts as owner's preset name, replacing any saved before.
+	Save(ctx context.Context, owner, name string, opts types.RenderOptions) error
+	// Load returns owner's preset name, or ErrNotFound.
+	Load(ctx context.Context, owner, name string) (types.RenderOptions, error)
+	// List returns owner's presets by name.
+	List(ctx context.Context, owner string) (map[string]types.RenderOptions, error)
+	// Delete forgets owner's preset name, or returns ErrNotFound.
+	Delete(ctx context.Context, owner, name string) error
+	// Close releases the store's resources.
+	Close() error
+}
+
+// Open creates the store selected by the configuration.
+//
+// Parameters:
+//   - cfg: saved preset settings
+//
+// Returns:
+//   - Store: the store
+//   - error: error if the store cannot be created or reached
+//
+// Example:
+//
+//	store, err := keypreset.Open(cfg.KeyPresets)
+//	if err != nil {
+//	    log.Fatalf("Failed to open preset store: %v", err)
+//	}
+func Open(cfg config.KeyPresetsConfig) (Store, error) {
+	switch cfg.Store {
+	case StoreMemory, "":
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		return NewRedisStore(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown preset store: %q", cfg.Store)
+	}
+}
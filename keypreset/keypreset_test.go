@@ -0,0 +1,83 @@
+package keypreset
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// storeContract checks the behavior every Store must have.
+func storeContract(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	brand := types.RenderOptions{Font: "doom", Color: "fire", Align: "left"}
+
+	if err := store.Save(ctx, "acme", "brand", brand); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if got, err := store.Load(ctx, "acme", "brand"); err != nil || got != brand {
+		t.Errorf("Load() = %+v, %v, want %+v", got, err, brand)
+	}
+	// Presets belong to the key that saved them
+	if _, err := store.Load(ctx, "globex", "brand"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() by another key error = %v, want ErrNotFound", err)
+	}
+
+	quiet := types.RenderOptions{Color: "none"}
+	if err := store.Save(ctx, "acme", "brand", quiet); err != nil {
+		t.Fatalf("Save over a preset failed: %v", err)
+	}
+	store.Save(ctx, "acme", "loud", brand)
+	presets, err := store.List(ctx, "acme")
+	if err != nil || len(presets) != 2 || presets["brand"] != quiet {
+		t.Errorf("List() = %+v, %v, want brand replaced and loud", presets, err)
+	}
+
+	if err := store.Delete(ctx, "acme", "brand"); err != nil {
+		t.Errorf("Delete failed: %v", err)
+	}
+	if err := store.Delete(ctx, "acme", "brand"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete error = %v, want ErrNotFound", err)
+	}
+	if presets, err := store.List(ctx, "globex"); err != nil || len(presets) != 0 {
+		t.Errorf("List() of a key without presets = %+v, %v", presets, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	storeContract(t, NewMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := Open(config.KeyPresetsConfig{Store: StoreRedis, RedisURL: "redis://" + server.Addr()})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+	storeContract(t, store)
+}
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"brand", true},
+		{"team-2", true},
+		{"", false},
+		{"Brand", false},
+		{"@brand", false},
+		{"a/b", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidName(tt.name); got != tt.want {
+			t.Errorf("ValidName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
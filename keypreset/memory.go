@@ -0,0 +1,81 @@
+package keypreset
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// MemoryStore keeps presets in process memory. Presets are lost on
+// restart.
+//
+// Usage example:
+//
+//	store := NewMemoryStore()
+//	err := store.Save(ctx, "acme", "brand", types.RenderOptions{Font: "doom"})
+type MemoryStore struct {
+	mu      sync.Mutex
+	presets map[string]map[string]types.RenderOptions
+}
+
+// NewMemoryStore creates an empty in-memory store.
+//
+// Returns:
+//   - *MemoryStore: an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{presets: make(map[string]map[string]types.RenderOptions)}
+}
+
+// Save stores opts as owner's preset name.
+func (m *MemoryStore) Save(_ context.Context, owner, name string, opts types.RenderOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.presets[owner] == nil {
+		m.presets[owner] = make(map[string]types.RenderOptions)
+	}
+	m.presets[owner][name] = opts
+	return nil
+}
+
+// Load returns owner's preset name.
+func (m *MemoryStore) Load(_ context.Context, owner, name string) (types.RenderOptions, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	opts, ok := m.presets[owner][name]
+	if !ok {
+		return types.RenderOptions{}, ErrNotFound
+	}
+	return opts, nil
+}
+
+// List returns a copy of owner's presets.
+func (m *MemoryStore) List(_ context.Context, owner string) (map[string]types.RenderOptions, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	presets := maps.Clone(m.presets[owner])
+	if presets == nil {
+		presets = make(map[string]types.RenderOptions)
+	}
+	return presets, nil
+}
+
+// Delete forgets owner's preset name.
+func (m *MemoryStore) Delete(_ context.Context, owner, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.presets[owner][name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.presets[owner], name)
+	if len(m.presets[owner]) == 0 {
+		delete(m.presets, owner)
+	}
+	return nil
+}
+
+// Close does nothing; the store holds no resources.
+func (m *MemoryStore) Close() error {
+	return nil
+}
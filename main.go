@@ -1,7 +1,502 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	// Embedded so /date and /time can load ?tz= zones in minimal containers
+	_ "time/tzdata"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/ryanlewis/shout-sh/accesslog"
+	"github.com/ryanlewis/shout-sh/apikey"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/errreport"
+	"github.com/ryanlewis/shout-sh/fortune"
+	"github.com/ryanlewis/shout-sh/geoip"
+	"github.com/ryanlewis/shout-sh/gopher"
+	"github.com/ryanlewis/shout-sh/grpcapi"
+	"github.com/ryanlewis/shout-sh/handlers"
+	"github.com/ryanlewis/shout-sh/ipaccess"
+	"github.com/ryanlewis/shout-sh/keypreset"
+	"github.com/ryanlewis/shout-sh/middleware"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/rendercache"
+	"github.com/ryanlewis/shout-sh/shortlink"
+	"github.com/ryanlewis/shout-sh/sockets"
+	"github.com/ryanlewis/shout-sh/tlscert"
+	"github.com/ryanlewis/shout-sh/types"
+	"github.com/ryanlewis/shout-sh/vhost"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
 
 func main() {
-	fmt.Println("shout.sh - ASCII art text generator")
+	parseFlags(os.Args[1:])
+
+	cfg, err := config.Load()
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		log.Print("Invalid configuration:")
+		for _, field := range invalid.Fields {
+			log.Printf("  %v", field)
+		}
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Inherited first, so sockets aren't passed on to anything started below
+	socks, err := sockets.Inherit()
+	if err != nil {
+		log.Fatalf("Failed to inherit sockets: %v", err)
+	}
+
+	fonts := render.NewFontCache()
+	if err := fonts.LoadFonts(cfg.Fonts); err != nil {
+		log.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	if cfg.Fonts.Watch {
+		go func() {
+			if err := fonts.Watch(ctx, cfg.Fonts); err != nil {
+				log.Printf("Warning: font hot-reload disabled: %v", err)
+			}
+		}()
+	}
+
+	filter, err := render.LoadWordFilter(cfg.Text)
+	if err != nil {
+		log.Fatalf("Failed to load word filter: %v", err)
+	}
+
+	var shortlinks shortlink.Store
+	if cfg.Features.EnableShortlinks {
+		shortlinks, err = shortlink.Open(cfg.Shortlinks)
+		if err != nil {
+			log.Fatalf("Failed to open short link store: %v", err)
+		}
+	}
+	if shortlinks != nil {
+		defer shortlinks.Close()
+	}
+
+	cache, err := rendercache.Open(cfg.Cache)
+	if err != nil {
+		log.Fatalf("Failed to open render cache: %v", err)
+	}
+	defer cache.Close()
+
+	access, err := ipaccess.NewGuard(cfg.Access)
+	if err != nil {
+		log.Fatalf("Failed to load access list: %v", err)
+	}
+	if access != nil {
+		go func() {
+			if err := access.Watch(ctx); err != nil {
+				log.Printf("Warning: access list hot-reload disabled: %v", err)
+			}
+		}()
+	}
+
+	accessLog, err := accesslog.Open(cfg.Log)
+	if err != nil {
+		log.Fatalf("Failed to open access log: %v", err)
+	}
+	if accessLog != nil {
+		defer accessLog.Close()
+		go reopenOnHangup(ctx, accessLog)
+	}
+
+	reporter, err := errreport.Open(cfg.Errors, cfg.Version)
+	if err != nil {
+		log.Fatalf("Failed to set up error reporting: %v", err)
+	}
+	if reporter != nil {
+		defer closeReporter(reporter)
+	}
+
+	geo, err := geoip.NewPolicy(cfg.GeoIP)
+	if err != nil {
+		log.Fatalf("Failed to load GeoIP policy: %v", err)
+	}
+
+	keys, err := apikey.Open(cfg.Auth)
+	if err != nil {
+		log.Fatalf("Failed to load API keys: %v", err)
+	}
+	if keys != nil {
+		defer keys.Close()
+	}
+
+	var keyPresets keypreset.Store
+	if keys != nil {
+		keyPresets, err = keypreset.Open(cfg.KeyPresets)
+		if err != nil {
+			log.Fatalf("Failed to open preset store: %v", err)
+		}
+		defer keyPresets.Close()
+	}
+
+	hosts, err := vhost.Open(cfg.VHosts)
+	if err != nil {
+		log.Fatalf("Failed to load virtual hosts: %v", err)
+	}
+
+	fortunes, err := fortune.Load(cfg.Fortune.Path)
+	if err != nil {
+		log.Fatalf("Failed to load fortunes: %v", err)
+	}
+
+	certs, err := tlscert.Open(cfg.TLS)
+	if err != nil {
+		log.Fatalf("Failed to set up TLS: %v", err)
+	}
+
+	metrics := &types.Metrics{}
+
+	h := handlers.New(handlers.Deps{
+		Config:     cfg,
+		Fonts:      fonts,
+		Metrics:    metrics,
+		Filter:     filter,
+		Shortlinks: shortlinks,
+		Fortunes:   fortunes,
+		Keys:       keys,
+		KeyPresets: keyPresets,
+		Access:     access,
+		AccessLog:  accessLog,
+		GeoIP:      geo,
+		Errors:     reporter,
+		VHosts:     hosts,
+		Cache:      cache,
+	})
+
+	app := newPublicApp(cfg)
+	h.Register(app)
+
+	admin := newAdminApp()
+	h.RegisterAdmin(admin)
+
+	go config.WatchRemote(ctx, cfg.Remote, func() { h.Reconfigure() })
+	go func() {
+		if err := h.Warmup(ctx); err != nil {
+			log.Printf("Warning: /readyz reports not ready: %v", err)
+		}
+	}()
+
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		grpcServer = newGRPCServer(cfg, grpcapi.Deps{Config: cfg, Fonts: fonts, Metrics: metrics, Filter: filter})
+		addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.GRPC.Port)
+		lis, err := socks.Listen("grpc", addr)
+		if err != nil {
+			log.Fatalf("gRPC server failed: %v", err)
+		}
+		go func() {
+			log.Printf("Starting gRPC server on %s", addr)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
+	var gopherServer *gopher.Server
+	if cfg.Gopher.Enabled {
+		gopherServer = gopher.New(gopher.Deps{
+			Config:  cfg,
+			Fonts:   fonts,
+			Metrics: metrics,
+			Filter:  filter,
+			Help: func() string {
+				return h.HelpText(fmt.Sprintf("%s:%d", cfg.Gopher.Hostname, cfg.Server.PublicPort))
+			},
+		})
+		addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Gopher.Port)
+		lis, err := socks.Listen("gopher", addr)
+		if err != nil {
+			log.Fatalf("Gopher server failed: %v", err)
+		}
+		go func() {
+			log.Printf("Starting Gopher server on %s", addr)
+			if err := gopherServer.Serve(lis); err != nil && !errors.Is(err, gopher.ErrServerClosed) {
+				log.Fatalf("Gopher server failed: %v", err)
+			}
+		}()
+	}
+
+	apps := []*fiber.App{app, admin}
+	if certs != nil && cfg.TLS.RedirectPort > 0 {
+		redirect := newRedirectApp(certs, cfg.Server.PublicPort)
+		apps = append(apps, redirect)
+		addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.TLS.RedirectPort)
+		ln, err := socks.Listen("redirect", addr)
+		if err != nil {
+			log.Fatalf("HTTPS redirect failed: %v", err)
+		}
+		go func() {
+			log.Printf("Starting HTTPS redirect on %s", addr)
+			if err := redirect.Listener(ln); err != nil {
+				log.Fatalf("HTTPS redirect failed: %v", err)
+			}
+		}()
+	}
+
+	adminAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.AdminPort)
+	adminLn, err := socks.Listen("admin", adminAddr)
+	if err != nil {
+		log.Fatalf("Admin server failed: %v", err)
+	}
+	go func() {
+		log.Printf("Starting admin server on %s", adminAddr)
+		if err := admin.Listener(adminLn); err != nil {
+			log.Fatalf("Admin server failed: %v", err)
+		}
+	}()
+
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.PublicPort)
+	ln, err := socks.Listen("public", addr)
+	if err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+	protocol := "HTTP/1.1 only"
+	if certs != nil {
+		ln = tls.NewListener(ln, certs.TLSConfig())
+		protocol = "HTTPS, HTTP/1.1 only"
+	}
+
+	if err := socks.Ready(); err != nil {
+		log.Printf("Warning: readiness not reported: %v", err)
+	}
+	// Streams run for up to SHOUT_STREAMING_MAX_TIMEOUT, which an upgrade
+	// lets them finish
+	drain := time.Duration(cfg.Streaming.MaxTimeout)*time.Second + shutdownTimeout
+	stopped := make(chan struct{})
+	go func() {
+		gracefulShutdown(socks, drain, h, grpcServer, gopherServer, apps...)
+		close(stopped)
+	}()
+
+	log.Printf("Starting shout.sh %s on %s (%s)", cfg.Version, addr, protocol)
+	if err := app.Listener(ln); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+	<-stopped
+}
+
+// parseFlags parses the command line, where every setting can be given as
+// a flag in place of its environment variable, such as
+// --server-public-port for SHOUT_SERVER_PUBLIC_PORT. Flags take
+// precedence over the environment and the .env file. It exits on bad
+// flags, after printing the usage for --help, and after running the
+// config subcommand.
+func parseFlags(args []string) {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ExitOnError)
+	config.BindFlags(fs)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "Usage: %s [flags]\n", fs.Name())
+		fmt.Fprintf(w, "       %s config schema|example\n\n", fs.Name())
+		fmt.Fprintln(w, "Every setting can be given as a flag, over its environment variable and the .env file.")
+		fmt.Fprintln(w, "config schema lists the settings as JSON; config example writes them as YAML to edit.")
+		fmt.Fprintln(w)
+		for _, setting := range config.Describe() {
+			usage := setting.Env
+			if setting.Default != "" {
+				usage += ", default " + setting.Default
+			}
+			fmt.Fprintf(w, "  --%s %s\n    \t%s (%s)\n", setting.Flag(), setting.Type, setting.Description, usage)
+		}
+	}
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(configCommand(args[1:], fs))
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected argument %q\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// configCommand runs "config schema", which writes every setting as
+// JSON, or "config example", which writes them as YAML at their defaults,
+// and returns the exit status.
+func configCommand(args []string, fs *flag.FlagSet) int {
+	var err error
+	switch strings.Join(args, " ") {
+	case "schema":
+		err = config.WriteSchema(os.Stdout)
+	case "example":
+		err = config.WriteExample(os.Stdout)
+	default:
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// newPublicApp creates the Fiber app serving the public port.
+func newPublicApp(cfg *config.Config) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ServerHeader:          "shout.sh",
+		AppName:               "shout.sh " + cfg.Version,
+		DisableStartupMessage: true,
+		// Slow clients can't hold connections open; streams extend the write
+		// deadline frame by frame (SHOUT_SERVER_STREAM_WRITE_TIMEOUT)
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		// Oversized requests are refused before any handler reads them
+		BodyLimit:      cfg.Server.MaxBodyBytes,
+		ReadBufferSize: cfg.Server.MaxHeaderBytes,
+		ErrorHandler:   middleware.LimitErrors(cfg.Server),
+	})
+	app.Use(recover.New())
+	return app
+}
+
+// newAdminApp creates the Fiber app serving the internal admin port.
+func newAdminApp() *fiber.App {
+	admin := fiber.New(fiber.Config{
+		ServerHeader:          "shout.sh-admin",
+		DisableStartupMessage: true,
+	})
+	admin.Use(recover.New())
+	return admin
+}
+
+// newRedirectApp creates the Fiber app serving plain HTTP when the public
+// port serves HTTPS: it answers ACME challenges and redirects the rest.
+func newRedirectApp(certs *tlscert.Provider, httpsPort int) *fiber.App {
+	redirect := fiber.New(fiber.Config{
+		ServerHeader:          "shout.sh",
+		DisableStartupMessage: true,
+	})
+	redirect.Use(adaptor.HTTPHandler(certs.RedirectHandler(httpsPort)))
+	return redirect
+}
+
+// newGRPCServer creates the gRPC server for the optional gRPC API.
+func newGRPCServer(cfg *config.Config, deps grpcapi.Deps) *grpc.Server {
+	server := grpc.NewServer()
+	grpcapi.RegisterShoutServer(server, grpcapi.New(deps))
+	if cfg.GRPC.Reflection {
+		reflection.Register(server)
+	}
+	return server
+}
+
+// reopenOnHangup reopens the access log file on SIGHUP, so logrotate can
+// move it away, until ctx is done.
+func reopenOnHangup(ctx context.Context, logger *accesslog.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-hup:
+			if err := logger.Reopen(); err != nil {
+				log.Printf("Error reopening access log: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// shutdownTimeout bounds how long SIGINT and SIGTERM wait for requests and
+// streams to finish.
+const shutdownTimeout = 30 * time.Second
+
+// upgradeTimeout bounds how long the process an upgrade starts has to
+// become ready before it is abandoned.
+const upgradeTimeout = time.Minute
+
+// gracefulShutdown stops the servers when SIGINT or SIGTERM is received,
+// then waits for queued async renders to be delivered. On SIGUSR2 it
+// first hands the sockets to a new process started from the binary on
+// disk, then waits up to drain for the streams still running. An upgrade
+// that fails leaves the servers running.
+// grpcServer and gopherServer are nil when they are disabled.
+func gracefulShutdown(socks *sockets.Set, drain time.Duration, h *handlers.Handler, grpcServer *grpc.Server, gopherServer *gopher.Server, apps ...*fiber.App) {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR2)
+
+	timeout := shutdownTimeout
+	for sig := range quit {
+		if sig != syscall.SIGUSR2 {
+			log.Println("Shutting down servers...")
+			break
+		}
+		log.Println("Upgrading: starting new process...")
+		if err := socks.Upgrade(upgradeTimeout); err != nil {
+			log.Printf("Upgrade failed: %v", err)
+			continue
+		}
+		log.Println("Upgraded: new process is serving, finishing streams...")
+		timeout = drain
+		break
+	}
+	signal.Stop(quit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, app := range apps {
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			log.Printf("Error during shutdown: %v", err)
+		}
+	}
+	if gopherServer != nil {
+		if err := gopherServer.Shutdown(ctx); err != nil {
+			log.Printf("Error during shutdown: %v", err)
+		}
+	}
+	if grpcServer != nil {
+		// Streams may run until their timeout, so don't wait past the deadline
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if err := h.Shutdown(ctx); err != nil {
+		log.Printf("Error during shutdown: %v", err)
+	}
+}
+
+// closeReporter sends the error reports still queued at exit, giving up
+// after a few seconds.
+func closeReporter(reporter *errreport.Reporter) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := reporter.Close(ctx); err != nil {
+		log.Printf("Error reports not sent: %v", err)
+	}
 }
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/accesslog"
+)
+
+// accessLogKey is the Locals key holding the request's access log state.
+const accessLogKey = "shout.accesslog"
+
+// accessRequest is what AccessLog knows of a request before it is answered.
+type accessRequest struct {
+	logger *accesslog.Logger
+	start  time.Time
+}
+
+// AccessLog writes a line to logger for every request once it has been
+// answered. A streaming response is logged twice: when it opens, and,
+// if its handler calls StreamClosed, when it closes, with the bytes sent
+// and how long it ran. Individual frames are never logged.
+//
+// Parameters:
+//   - logger: where lines are written
+//
+// Returns:
+//   - fiber.Handler: app-level middleware, registered first so the
+//     latency covers the other middleware
+//
+// Example:
+//
+//	app.Use(middleware.AccessLog(logger))
+func AccessLog(logger *accesslog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(accessLogKey, &accessRequest{logger: logger, start: time.Now()})
+		err := c.Next()
+		// Let the error handler set the status before it is logged
+		if err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+			err = nil
+		}
+		entry := accessEntry(c)
+		if c.Response().IsBodyStream() {
+			entry.Event = accesslog.EventStreamOpen
+		} else {
+			entry.Bytes = int64(len(c.Response().Body()))
+		}
+		logger.Log(entry)
+		return err
+	}
+}
+
+// StreamClosed returns the function a streaming handler calls when its
+// stream ends, with the bytes sent, to log the close and record the
+// stream in the route metrics. The request is captured now, as c is
+// recycled before the stream ends. Without AccessLog or RouteMetrics the
+// function does nothing.
+//
+// Parameters:
+//   - c: the request context, before SetBodyStreamWriter
+//
+// Returns:
+//   - func(sent int64): logs and records the stream's close
+//
+// Example:
+//
+//	closed := middleware.StreamClosed(c)
+//	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+//	    sw := middleware.NewStreamWriter(w, enc)
+//	    defer func() { closed(sw.Sent()) }()
+//	    ...
+//	})
+func StreamClosed(c *fiber.Ctx) func(sent int64) {
+	logged, measured := streamLogged(c), streamMeasured(c)
+	return func(sent int64) {
+		logged(sent)
+		measured(sent)
+	}
+}
+
+// streamLogged returns the function that logs the stream's close. Without
+// AccessLog the function does nothing.
+func streamLogged(c *fiber.Ctx) func(sent int64) {
+	req, ok := c.Locals(accessLogKey).(*accessRequest)
+	if !ok {
+		return func(int64) {}
+	}
+	entry := accessEntry(c)
+	entry.Event = accesslog.EventStreamClose
+	return func(sent int64) {
+		entry.Bytes = sent
+		entry.Latency = time.Since(req.start)
+		req.logger.Log(entry)
+	}
+}
+
+// accessEntry describes the request in c, as answered so far, with API
+// keys and signatures redacted from its URI. Strings are copied, since
+// c's buffers are reused.
+func accessEntry(c *fiber.Ctx) accesslog.Entry {
+	req := c.Locals(accessLogKey).(*accessRequest)
+	entry := accesslog.Entry{
+		Time:      req.start,
+		IP:        strings.Clone(c.IP()),
+		Method:    strings.Clone(c.Method()),
+		URI:       redactedURI(c),
+		Proto:     string(c.Request().Header.Protocol()),
+		Status:    c.Response().StatusCode(),
+		Referer:   string(c.Request().Header.Referer()),
+		UserAgent: string(c.Request().Header.UserAgent()),
+		Latency:   time.Since(req.start),
+	}
+	if key := Key(c); key != nil {
+		entry.User = key.Name
+	}
+	return entry
+}
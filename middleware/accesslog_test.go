@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/accesslog"
+	"github.com/ryanlewis/shout-sh/apikey"
+)
+
+func TestAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	app := fiber.New()
+	app.Use(AccessLog(accesslog.New(&out, accesslog.FormatJSON)))
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Query("key") != "" {
+			c.Locals(KeyKey, &apikey.Key{Name: "acme"})
+		}
+		return c.Next()
+	})
+	app.Get("/HI", func(c *fiber.Ctx) error { return c.SendString("HI\n") })
+	app.Get("/missing", func(c *fiber.Ctx) error { return fiber.ErrNotFound })
+	app.Get("/stream", func(c *fiber.Ctx) error {
+		closed := StreamClosed(c)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			sw := NewStreamWriter(w, EncodingIdentity)
+			defer func() { closed(sw.Sent()) }()
+			for range 3 {
+				sw.Write([]byte("frame\n"))
+				sw.Flush()
+			}
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		want   []map[string]any
+	}{
+		{
+			name:   "request",
+			target: "/HI?key=k",
+			want:   []map[string]any{{"uri": "/HI?key=%5Bredacted%5D", "status": 200.0, "bytes": 3.0, "user": "acme", "proto": "HTTP/1.1"}},
+		},
+		{
+			name:   "signed request",
+			target: "/HI?c=red&sig=abc123&ts=1700000000&key=s3cret",
+			want:   []map[string]any{{"uri": "/HI?c=red&key=%5Bredacted%5D&sig=%5Bredacted%5D&ts=1700000000"}},
+		},
+		{
+			name:   "query kept as sent",
+			target: "/HI?f=doom&c=red",
+			want:   []map[string]any{{"uri": "/HI?f=doom&c=red"}},
+		},
+		{
+			name:   "error",
+			target: "/missing",
+			want:   []map[string]any{{"status": 404.0}},
+		},
+		{
+			name:   "stream opens and closes",
+			target: "/stream",
+			want: []map[string]any{
+				{"event": accesslog.EventStreamOpen, "bytes": 0.0},
+				{"event": accesslog.EventStreamClose, "bytes": 18.0, "status": 200.0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.target, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = io.ReadAll(resp.Body)
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("logged %d lines, want %d:\n%s", len(lines), len(tt.want), out.String())
+			}
+			for i, line := range lines {
+				var got map[string]any
+				if err := json.Unmarshal([]byte(line), &got); err != nil {
+					t.Fatalf("invalid JSON %q: %v", line, err)
+				}
+				for field, want := range tt.want[i] {
+					if got[field] != want {
+						t.Errorf("line %d %s = %v, want %v", i, field, got[field], want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStreamClosedWithoutAccessLog(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		StreamClosed(c)(10)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+}
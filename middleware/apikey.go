@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/apikey"
+)
+
+// HeaderAPIKey carries the API key; ?key= works too, for links.
+const HeaderAPIKey = "X-API-Key"
+
+// KeyKey is the Locals key holding the request's *apikey.Key.
+const KeyKey = "shout.key"
+
+// APIKey looks up the request's API key, from the X-API-Key header or
+// ?key=, and stores it for Key. Unknown keys fail with 401, as do
+// requests without a key when required is set. CORS preflight requests
+// pass through, since browsers send them without credentials.
+//
+// Parameters:
+//   - store: the known keys
+//   - required: whether requests without a key are refused
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	app.Use(middleware.APIKey(keys, cfg.Auth.Required))
+func APIKey(store apikey.Store, required bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		c.Vary(HeaderAPIKey)
+
+		raw := c.Get(HeaderAPIKey)
+		if raw == "" {
+			raw = c.Query("key")
+		}
+		if raw == "" {
+			if required {
+				return apierrors.Send(c, apierrors.ErrKeyRequired.WithHint("send it in the X-API-Key header"))
+			}
+			return c.Next()
+		}
+
+		key, err := store.Lookup(c.UserContext(), raw)
+		if err != nil {
+			if !errors.Is(err, apikey.ErrUnknownKey) {
+				log.Printf("Error looking up API key: %v", err)
+			}
+			return apierrors.Send(c, apierrors.ErrInvalidKey)
+		}
+		c.Locals(KeyKey, key)
+		return c.Next()
+	}
+}
+
+// Key returns the request's API key, or nil if it came without one.
+//
+// Parameters:
+//   - c: the request context
+//
+// Returns:
+//   - *apikey.Key: the key stored by APIKey, or nil
+func Key(c *fiber.Ctx) *apikey.Key {
+	key, _ := c.Locals(KeyKey).(*apikey.Key)
+	return key
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apikey"
+)
+
+// fakeKeys knows a single key, "s3cret".
+type fakeKeys struct{}
+
+func (fakeKeys) Lookup(_ context.Context, key string) (*apikey.Key, error) {
+	if key == "s3cret" {
+		return &apikey.Key{Name: "acme", Tier: &apikey.Tier{Name: "pro"}}, nil
+	}
+	return nil, apikey.ErrUnknownKey
+}
+
+func (fakeKeys) Anonymous() *apikey.Tier { return nil }
+
+func (fakeKeys) Close() error { return nil }
+
+func TestAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   bool
+		method     string
+		target     string
+		header     string
+		wantStatus int
+		wantKey    string
+	}{
+		{name: "no key", target: "/", wantStatus: 200},
+		{name: "header", target: "/", header: "s3cret", wantStatus: 200, wantKey: "acme"},
+		{name: "query", target: "/?key=s3cret", wantStatus: 200, wantKey: "acme"},
+		{name: "unknown key", target: "/?key=guess", wantStatus: 401},
+		{name: "required", required: true, target: "/", wantStatus: 401},
+		{name: "required with key", required: true, target: "/", header: "s3cret", wantStatus: 200, wantKey: "acme"},
+		{name: "preflight", required: true, method: "OPTIONS", target: "/", wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(APIKey(fakeKeys{}, tt.required))
+			app.All("/", func(c *fiber.Ctx) error {
+				if key := Key(c); key != nil {
+					return c.SendString(key.Name)
+				}
+				return c.SendString("")
+			})
+
+			method := tt.method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderAPIKey, tt.header)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus == 200 && string(body) != tt.wantKey {
+				t.Errorf("key = %q, want %q", body, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/bots"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Bots turns away requests the detector classifies as bots with 403,
+// through the tarpit if there is one, before anything is rendered, and
+// counts them in metrics by class. Requests with an API key don't count
+// toward the cadence limit, so it should run after APIKey.
+//
+// Parameters:
+//   - detector: classifies requests
+//   - tarpit: drips the error out slowly; nil answers straight away
+//   - metrics: where bot requests are counted
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	app.Use(middleware.Bots(detector, nil, metrics))
+func Bots(detector *bots.Detector, tarpit *Tarpit, metrics *types.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		class := detector.Classify(c.IP(), c.Get(fiber.HeaderUserAgent), c.Path(), Key(c) == nil)
+		switch class {
+		case "":
+			return c.Next()
+		case bots.ClassScanner:
+			metrics.IncScanner()
+		case bots.ClassProbe:
+			metrics.IncProbe()
+		case bots.ClassFlood:
+			metrics.IncFlood()
+		}
+		err := apierrors.ErrSuspectedBot.WithDetail("classified as %s", class)
+		if tarpit != nil {
+			return tarpit.Send(c, err)
+		}
+		return apierrors.Send(c, err)
+	}
+}
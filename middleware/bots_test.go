@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/bots"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestBots(t *testing.T) {
+	tests := []struct {
+		name       string
+		tarpit     *Tarpit
+		userAgent  string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "person", userAgent: "curl/8.5.0", path: "/HELLO", wantStatus: 200, wantBody: "rendered"},
+		{name: "scanner", userAgent: "Nikto/2.5.0", path: "/HELLO", wantStatus: 403, wantBody: "Error: Request looks automated: classified as scanner\n"},
+		{
+			name:       "probe in the tarpit",
+			tarpit:     NewTarpit(time.Millisecond, 60, 10),
+			userAgent:  "curl/8.5.0",
+			path:       "/wp-login.php",
+			wantStatus: 403,
+			wantBody:   "Error: Request looks automated: classified as probe\nError: R",
+		},
+		{name: "full tarpit", tarpit: NewTarpit(time.Millisecond, 40, 0), userAgent: "curl/8.5.0", path: "/.env", wantStatus: 403, wantBody: "Error: Request looks automated: classified as probe\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &types.Metrics{}
+			app := fiber.New()
+			app.Use(Bots(bots.New(config.BotsConfig{Detect: true, Remember: time.Minute}), tt.tarpit, metrics))
+			app.Get("/*", func(c *fiber.Ctx) error { return c.SendString("rendered") })
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("response = %d %q, want %d %q", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+			if tt.wantStatus == 403 {
+				if got := resp.Header.Get(apierrors.HeaderErrorCode); got != "suspected_bot" {
+					t.Errorf("error code = %q, want suspected_bot", got)
+				}
+				if bots := metrics.Snapshot().Bots; bots.Scanner+bots.Probe != 1 {
+					t.Errorf("bot requests counted = %+v, want 1", bots)
+				}
+			}
+		})
+	}
+}
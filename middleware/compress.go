@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/valyala/fasthttp"
+)
+
+// Content codings supported by the compression middleware.
+const (
+	EncodingBrotli   = "br"
+	EncodingGzip     = "gzip"
+	EncodingIdentity = ""
+)
+
+// Compress compresses successful responses with brotli or gzip when the
+// client's Accept-Encoding allows it, preferring brotli. Responses smaller
+// than cfg.MinBytes, already encoded, or not 200 OK are sent as is. A
+// strong ETag on a compressed response is made weak, since the compressed
+// bytes differ from the identity representation it describes.
+//
+// Parameters:
+//   - cfg: compression settings
+//
+// Returns:
+//   - fiber.Handler: route middleware
+//
+// Example:
+//
+//	app.Get("/*", middleware.Compress(cfg.Compression), h.Static)
+func Compress(cfg config.CompressionConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		c.Vary(fiber.HeaderAcceptEncoding)
+		if resp.StatusCode() != fiber.StatusOK || len(resp.Header.Peek(fiber.HeaderContentEncoding)) > 0 {
+			return nil
+		}
+		body := resp.Body()
+		if len(body) < cfg.MinBytes {
+			return nil
+		}
+
+		var compressed []byte
+		switch NegotiateEncoding(c.Get(fiber.HeaderAcceptEncoding), EncodingBrotli, EncodingGzip) {
+		case EncodingBrotli:
+			compressed = fasthttp.AppendBrotliBytesLevel(nil, body, fasthttp.CompressBrotliDefaultCompression)
+			c.Set(fiber.HeaderContentEncoding, EncodingBrotli)
+		case EncodingGzip:
+			compressed = fasthttp.AppendGzipBytesLevel(nil, body, fasthttp.CompressDefaultCompression)
+			c.Set(fiber.HeaderContentEncoding, EncodingGzip)
+		default:
+			return nil
+		}
+
+		if etag := c.GetRespHeader(fiber.HeaderETag); strings.HasPrefix(etag, `"`) {
+			c.Set(fiber.HeaderETag, "W/"+etag)
+		}
+		resp.SetBodyRaw(compressed)
+		return nil
+	}
+}
+
+// NegotiateEncoding picks the first of the offered codings that the
+// Accept-Encoding header accepts with a non-zero quality. A "*" entry
+// accepts any coding not listed explicitly.
+//
+// Parameters:
+//   - header: the request's Accept-Encoding header
+//   - offers: supported codings in order of preference
+//
+// Returns:
+//   - string: the chosen coding, or EncodingIdentity
+//
+// Example:
+//
+//	enc := NegotiateEncoding("gzip, br;q=0", EncodingBrotli, EncodingGzip) // "gzip"
+func NegotiateEncoding(header string, offers ...string) string {
+	quality := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		quality[name] = q
+	}
+
+	for _, offer := range offers {
+		q, listed := quality[offer]
+		if !listed {
+			q, listed = quality["*"]
+		}
+		if listed && q > 0 {
+			return offer
+		}
+	}
+	return EncodingIdentity
+}
+
+// StreamWriter writes frames of a streaming response. Flush pushes every
+// frame written so far to the client, through the compressor if one is
+// in use, so animations stay smooth. Close must be called at the end of
+// the stream to finish the encoding. Sent counts the bytes passed on to
+// the response, after compression.
+type StreamWriter interface {
+	io.Writer
+	Flush() error
+	Close() error
+	Sent() int64
+}
+
+// StreamEncoding negotiates compression for a streaming response and sets
+// the Content-Encoding and Vary headers accordingly. Only gzip is offered,
+// since it can be flushed frame by frame. It returns EncodingIdentity when
+// stream compression is disabled or the client doesn't accept gzip.
+//
+// Parameters:
+//   - c: the request context
+//   - cfg: compression settings
+//
+// Returns:
+//   - string: EncodingGzip or EncodingIdentity, to pass to NewStreamWriter
+//
+// Example:
+//
+//	enc := middleware.StreamEncoding(c, cfg.Compression)
+//	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+//	    sw := middleware.NewStreamWriter(w, enc)
+//	    defer sw.Close()
+//	    // write and flush frames
+//	})
+func StreamEncoding(c *fiber.Ctx, cfg config.CompressionConfig) string {
+	c.Vary(fiber.HeaderAcceptEncoding)
+	if !cfg.Stream {
+		return EncodingIdentity
+	}
+	if NegotiateEncoding(c.Get(fiber.HeaderAcceptEncoding), EncodingGzip) != EncodingGzip {
+		return EncodingIdentity
+	}
+	c.Set(fiber.HeaderContentEncoding, EncodingGzip)
+	return EncodingGzip
+}
+
+// NewStreamWriter wraps the response stream writer with the negotiated
+// encoding.
+//
+// Parameters:
+//   - w: the body stream writer
+//   - encoding: the result of StreamEncoding
+//
+// Returns:
+//   - StreamWriter: a writer that compresses when encoding is gzip
+func NewStreamWriter(w *bufio.Writer, encoding string) StreamWriter {
+	if encoding == EncodingGzip {
+		counted := &countingWriter{w: w}
+		return &gzipStreamWriter{gz: gzip.NewWriter(counted), w: w, counted: counted}
+	}
+	return &plainStreamWriter{w: w}
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// plainStreamWriter writes frames uncompressed.
+type plainStreamWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (p *plainStreamWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.n += int64(n)
+	return n, err
+}
+func (p *plainStreamWriter) Flush() error { return p.w.Flush() }
+func (p *plainStreamWriter) Close() error { return p.w.Flush() }
+func (p *plainStreamWriter) Sent() int64  { return p.n }
+
+// gzipStreamWriter compresses frames, sync-flushing the compressor on each
+// Flush so the client can decode every frame as it arrives.
+type gzipStreamWriter struct {
+	gz      *gzip.Writer
+	w       *bufio.Writer
+	counted *countingWriter
+}
+
+func (g *gzipStreamWriter) Sent() int64 { return g.counted.n }
+
+func (g *gzipStreamWriter) Write(b []byte) (int, error) { return g.gz.Write(b) }
+
+func (g *gzipStreamWriter) Flush() error {
+	if err := g.gz.Flush(); err != nil {
+		return err
+	}
+	return g.w.Flush()
+}
+
+func (g *gzipStreamWriter) Close() error {
+	if err := g.gz.Close(); err != nil {
+		return err
+	}
+	return g.w.Flush()
+}
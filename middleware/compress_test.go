@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", EncodingIdentity},
+		{"gzip", EncodingGzip},
+		{"gzip, br", EncodingBrotli},
+		{"br;q=0, gzip", EncodingGzip},
+		{"GZIP;q=0.5", EncodingGzip},
+		{"gzip;q=0", EncodingIdentity},
+		{"*", EncodingBrotli},
+		{"*;q=0, gzip", EncodingGzip},
+		{"deflate", EncodingIdentity},
+	}
+
+	for _, tt := range tests {
+		if got := NegotiateEncoding(tt.header, EncodingBrotli, EncodingGzip); got != tt.want {
+			t.Errorf("NegotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("shout ", 200)
+	cfg := config.CompressionConfig{Static: true, MinBytes: 512}
+
+	tests := []struct {
+		name           string
+		body           string
+		status         int
+		acceptEncoding string
+		wantEncoding   string
+		wantETag       string
+	}{
+		{name: "brotli", body: large, status: 200, acceptEncoding: "gzip, br", wantEncoding: "br", wantETag: `W/"tag"`},
+		{name: "gzip", body: large, status: 200, acceptEncoding: "gzip", wantEncoding: "gzip", wantETag: `W/"tag"`},
+		{name: "not accepted", body: large, status: 200, acceptEncoding: "", wantETag: `"tag"`},
+		{name: "too small", body: "hi", status: 200, acceptEncoding: "gzip", wantETag: `"tag"`},
+		{name: "error response", body: large, status: 400, acceptEncoding: "gzip", wantETag: `"tag"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", Compress(cfg), func(c *fiber.Ctx) error {
+				c.Set(fiber.HeaderETag, `"tag"`)
+				return c.Status(tt.status).SendString(tt.body)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			raw, _ := io.ReadAll(resp.Body)
+
+			if got := resp.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := resp.Header.Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := resp.Header.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+
+			var decoded []byte
+			switch tt.wantEncoding {
+			case "br":
+				decoded, err = io.ReadAll(brotli.NewReader(bytes.NewReader(raw)))
+			case "gzip":
+				var zr *gzip.Reader
+				if zr, err = gzip.NewReader(bytes.NewReader(raw)); err == nil {
+					decoded, err = io.ReadAll(zr)
+				}
+			default:
+				decoded = raw
+			}
+			if err != nil || string(decoded) != tt.body {
+				t.Errorf("decoded body mismatch (err %v)", err)
+			}
+		})
+	}
+}
+
+func TestStreamEncoding(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            config.CompressionConfig
+		acceptEncoding string
+		want           string
+	}{
+		{name: "gzip accepted", cfg: config.CompressionConfig{Stream: true}, acceptEncoding: "gzip, br", want: EncodingGzip},
+		{name: "brotli only", cfg: config.CompressionConfig{Stream: true}, acceptEncoding: "br", want: EncodingIdentity},
+		{name: "disabled", cfg: config.CompressionConfig{}, acceptEncoding: "gzip", want: EncodingIdentity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return c.SendString(StreamEncoding(c, tt.cfg))
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if string(body) != tt.want {
+				t.Errorf("StreamEncoding = %q, want %q", body, tt.want)
+			}
+			if got := resp.Header.Get("Content-Encoding"); got != tt.want {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGzipStreamWriterFlushesFrames(t *testing.T) {
+	var out bytes.Buffer
+	sw := NewStreamWriter(bufio.NewWriter(&out), EncodingGzip)
+
+	if _, err := sw.Write([]byte("frame one\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sw.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	// Everything written before the flush is decodable before the stream ends
+	zr, err := gzip.NewReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader failed: %v", err)
+	}
+	frame := make([]byte, len("frame one\n"))
+	if _, err := io.ReadFull(zr, frame); err != nil || string(frame) != "frame one\n" {
+		t.Fatalf("first frame = %q, %v", frame, err)
+	}
+
+	sw.Write([]byte("frame two\n"))
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	zr, _ = gzip.NewReader(bytes.NewReader(out.Bytes()))
+	if all, err := io.ReadAll(zr); err != nil || string(all) != "frame one\nframe two\n" {
+		t.Errorf("full stream = %q, %v", all, err)
+	}
+}
+
+func TestPlainStreamWriter(t *testing.T) {
+	var out bytes.Buffer
+	sw := NewStreamWriter(bufio.NewWriter(&out), EncodingIdentity)
+
+	sw.Write([]byte("frame"))
+	if out.Len() != 0 {
+		t.Error("frames should be buffered until flushed")
+	}
+	sw.Flush()
+	if out.String() != "frame" {
+		t.Errorf("output = %q, want frame", out.String())
+	}
+}
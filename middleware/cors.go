@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// AllowedMethods are the methods the public API answers.
+const AllowedMethods = "GET,HEAD,POST,OPTIONS"
+
+// exposedHeaders are the response headers browser clients may read.
+var exposedHeaders = []string{
+	fiber.HeaderContentLength,
+	fiber.HeaderETag,
+	"X-Shout-Font-Suggestion",
+	"X-Shout-Font",
+	"X-Shout-Width",
+	"X-Shout-Height",
+	"X-Shout-Cache",
+	"X-Quota-Limit",
+	"X-Quota-Remaining",
+	"X-Quota-Reset",
+	fiber.HeaderRetryAfter,
+}
+
+// CORS answers preflight requests and adds CORS headers so browser-based
+// frontends can call the API directly. When cfg.Enabled is false it
+// passes every request through untouched.
+//
+// Parameters:
+//   - cfg: allowed origins, request headers and preflight cache lifetime
+//
+// Returns:
+//   - fiber.Handler: app-level middleware
+//
+// Example:
+//
+//	app.Use(middleware.CORS(cfg.CORS))
+func CORS(cfg config.CORSConfig) fiber.Handler {
+	if !cfg.Enabled {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	origins := strings.Join(cfg.AllowOrigins, ",")
+	if origins == "" {
+		origins = "*"
+	}
+	return cors.New(cors.Config{
+		AllowOrigins:  origins,
+		AllowMethods:  AllowedMethods,
+		AllowHeaders:  strings.Join(cfg.AllowHeaders, ","),
+		ExposeHeaders: strings.Join(exposedHeaders, ","),
+		MaxAge:        cfg.MaxAge,
+	})
+}
+
+// Options answers plain OPTIONS requests (those that are not CORS
+// preflights) with the allowed methods.
+//
+// Example:
+//
+//	app.Options("/*", middleware.Options)
+func Options(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderAllow, strings.ReplaceAll(AllowedMethods, ",", ", "))
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestCORS(t *testing.T) {
+	cfg := config.CORSConfig{
+		Enabled:      true,
+		AllowOrigins: []string{"https://dash.example.com"},
+		AllowHeaders: []string{"If-None-Match"},
+		MaxAge:       600,
+	}
+
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantMaxAge  string
+		wantExposed bool
+	}{
+		{name: "simple request", cfg: cfg, method: "GET", origin: "https://dash.example.com", wantStatus: 200, wantOrigin: "https://dash.example.com", wantExposed: true},
+		{name: "other origin", cfg: cfg, method: "GET", origin: "https://evil.example.com", wantStatus: 200},
+		{name: "preflight", cfg: cfg, method: "OPTIONS", origin: "https://dash.example.com", preflight: true, wantStatus: 204, wantOrigin: "https://dash.example.com", wantMaxAge: "600"},
+		{name: "plain options", cfg: cfg, method: "OPTIONS", wantStatus: 204},
+		{name: "disabled", cfg: config.CORSConfig{}, method: "GET", origin: "https://dash.example.com", wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(CORS(tt.cfg))
+			app.Options("/*", Options)
+			app.Get("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+			req := httptest.NewRequest(tt.method, "/HELLO", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", "GET")
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.preflight {
+				if got := resp.Header.Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+					t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.wantMaxAge)
+				}
+			}
+			if tt.wantExposed {
+				if got := resp.Header.Get("Access-Control-Expose-Headers"); !strings.Contains(got, "ETag") {
+					t.Errorf("Access-Control-Expose-Headers = %q, want ETag included", got)
+				}
+			}
+			if tt.method == "OPTIONS" && !tt.preflight {
+				if got := resp.Header.Get("Allow"); got != "GET, HEAD, POST, OPTIONS" {
+					t.Errorf("Allow = %q, want %q", got, "GET, HEAD, POST, OPTIONS")
+				}
+			}
+		})
+	}
+}
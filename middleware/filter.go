@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// MaskKey is the Locals key holding the word ranges to mask.
+const MaskKey = "shout.mask"
+
+// WordFilter checks the validated text for blocked words. With the
+// "reject" action such requests fail with 400; with "mask" the matched
+// ranges are stored for the handler to pass to render.MaskLayout. Every
+// filtered request is counted in metrics.FilteredRequests. A nil filter
+// or the "allow" action lets all text through. It must run after
+// InputValidation.
+//
+// Parameters:
+//   - filter: the blocked word list, nil when filtering is off
+//   - action: render.FilterAllow, render.FilterReject or render.FilterMask
+//   - metrics: counters to update
+//
+// Returns:
+//   - fiber.Handler: route middleware
+//
+// Example:
+//
+//	app.Get("/*", middleware.InputValidation(cfg.Text),
+//	    middleware.WordFilter(filter, cfg.Text.Filter, metrics), h.Static)
+func WordFilter(filter *render.WordFilter, action string, metrics *types.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		matches, err := FilterText(filter, action, Text(c), metrics)
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+		if len(matches) > 0 {
+			c.Locals(MaskKey, matches)
+		}
+		return c.Next()
+	}
+}
+
+// FilterText applies the word filter to text that doesn't come through
+// InputValidation, with the same rules as WordFilter.
+//
+// Parameters:
+//   - filter: the blocked word list, nil when filtering is off
+//   - action: render.FilterAllow, render.FilterReject or render.FilterMask
+//   - text: the sanitized text to check
+//   - metrics: counters to update
+//
+// Returns:
+//   - []render.WordRange: ranges to mask, when action is "mask"
+//   - error: apierrors.ErrBlockedText when action is "reject" and the text matches
+//
+// Example:
+//
+//	masked, err := middleware.FilterText(filter, cfg.Text.Filter, spec.Text, metrics)
+func FilterText(filter *render.WordFilter, action, text string, metrics *types.Metrics) ([]render.WordRange, error) {
+	if filter == nil || action == render.FilterAllow {
+		return nil, nil
+	}
+
+	matches := filter.Match(text)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	metrics.IncFiltered()
+
+	if action == render.FilterReject {
+		return nil, apierrors.ErrBlockedText
+	}
+	return matches, nil
+}
+
+// Masked returns the word ranges WordFilter marked for masking, if any.
+//
+// Parameters:
+//   - c: the request context
+//
+// Returns:
+//   - []render.WordRange: ranges of the request text to mask
+//
+// Example:
+//
+//	render.MaskLayout(layout, middleware.Masked(c))
+func Masked(c *fiber.Ctx) []render.WordRange {
+	ranges, _ := c.Locals(MaskKey).([]render.WordRange)
+	return ranges
+}
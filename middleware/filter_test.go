@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestWordFilter(t *testing.T) {
+	filter := render.NewWordFilter([]string{"darn"})
+
+	tests := []struct {
+		name         string
+		filter       *render.WordFilter
+		action       string
+		target       string
+		wantStatus   int
+		wantMasked   string
+		wantFiltered int64
+	}{
+		{name: "clean text", filter: filter, action: render.FilterReject, target: "/HELLO", wantStatus: 200, wantMasked: "[]"},
+		{name: "reject", filter: filter, action: render.FilterReject, target: "/OH+DARN", wantStatus: 400, wantFiltered: 1},
+		{name: "mask", filter: filter, action: render.FilterMask, target: "/OH+DARN", wantStatus: 200, wantMasked: "[{3 7}]", wantFiltered: 1},
+		{name: "allow", filter: filter, action: render.FilterAllow, target: "/OH+DARN", wantStatus: 200, wantMasked: "[]"},
+		{name: "nil filter", filter: nil, action: render.FilterReject, target: "/OH+DARN", wantStatus: 200, wantMasked: "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &types.Metrics{}
+			app := fiber.New()
+			app.Get("/*",
+				InputValidation(config.TextConfig{}),
+				WordFilter(tt.filter, tt.action, metrics),
+				func(c *fiber.Ctx) error {
+					return c.SendString(fmt.Sprint(Masked(c)))
+				})
+
+			resp, err := app.Test(httptest.NewRequest("GET", tt.target, nil), -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus == 200 && string(body) != tt.wantMasked {
+				t.Errorf("masked = %s, want %s", body, tt.wantMasked)
+			}
+			if metrics.Snapshot().Requests.Filtered != tt.wantFiltered {
+				t.Errorf("FilteredRequests = %d, want %d", metrics.Snapshot().Requests.Filtered, tt.wantFiltered)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"cmp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/geoip"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// CountryKey is the Locals key holding the client's country code.
+const CountryKey = "shout.country"
+
+// GeoIP looks up the client's country, stores it for Country and counts
+// the request against it in metrics, then refuses countries the policy
+// doesn't serve with 403. Like IPAccess, it should run before anything
+// that counts requests.
+//
+// Parameters:
+//   - policy: the GeoIP database and per-country policy
+//   - metrics: where requests are counted by country
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	app.Use(middleware.GeoIP(policy, metrics))
+func GeoIP(policy *geoip.Policy, metrics *types.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		country := policy.Country(c.IP())
+		c.Locals(CountryKey, country)
+		metrics.AddCountry(cmp.Or(country, geoip.Unknown))
+		if !policy.Allowed(country) {
+			return apierrors.Send(c, apierrors.ErrCountryDenied.WithDetail("requests from %s are not served", country))
+		}
+		return c.Next()
+	}
+}
+
+// Country returns the client's ISO country code, or "" if GeoIP is off or
+// the database doesn't place the client.
+//
+// Parameters:
+//   - c: the request context
+//
+// Returns:
+//   - string: the country code stored by GeoIP, or ""
+func Country(c *fiber.Ctx) string {
+	country, _ := c.Locals(CountryKey).(string)
+	return country
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/geoip"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestGeoIP(t *testing.T) {
+	// app.Test requests come from 0.0.0.0
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := geoip.Write(f, map[netip.Prefix]string{netip.MustParsePrefix("0.0.0.0/8"): "FR"}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		name       string
+		cfg        config.GeoIPConfig
+		wantStatus int
+	}{
+		{name: "no policy", wantStatus: 200},
+		{name: "allowed", cfg: config.GeoIPConfig{Allow: []string{"FR"}}, wantStatus: 200},
+		{name: "not allowed", cfg: config.GeoIPConfig{Allow: []string{"DE"}}, wantStatus: 403},
+		{name: "denied", cfg: config.GeoIPConfig{Deny: []string{"fr"}}, wantStatus: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Database = path
+			policy, err := geoip.NewPolicy(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			metrics := &types.Metrics{}
+			app := fiber.New()
+			app.Use(GeoIP(policy, metrics))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendString(Country(c)) })
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil), -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == 403 && resp.Header.Get(apierrors.HeaderErrorCode) != "country_denied" {
+				t.Errorf("error code = %q, want country_denied", resp.Header.Get(apierrors.HeaderErrorCode))
+			}
+			if got := metrics.Countries()["FR"]; got != 1 {
+				t.Errorf("requests counted for FR = %d, want 1", got)
+			}
+		})
+	}
+}
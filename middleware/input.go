@@ -0,0 +1,111 @@
+// Package middleware provides Fiber middleware shared by the shout.sh routes.
+package middleware
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// TextKey is the Locals key holding the validated request text.
+const TextKey = "shout.text"
+
+// InputValidation decodes the text from the route's wildcard path segment,
+// sanitizes it (see render.SanitizeText) and enforces cfg.MaxLength. Text
+// that is too long is rejected with 400, or truncated when cfg.Overflow is
+// "truncate". A MaxLength below 1 disables the limit. The cleaned text is
+// stored for handlers to read with Text.
+//
+// Parameters:
+//   - cfg: text settings with the length limit and overflow behavior
+//
+// Returns:
+//   - fiber.Handler: route middleware for routes ending in a "*" parameter
+//
+// Example:
+//
+//	app.Get("/*", middleware.InputValidation(cfg.Text), h.Static)
+func InputValidation(cfg config.TextConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		text, err := CheckText(PathText(c.Params("*")), cfg)
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+
+		c.Locals(TextKey, text)
+		return c.Next()
+	}
+}
+
+// CheckText sanitizes request text and enforces cfg.MaxLength, for text
+// that doesn't arrive in the path.
+//
+// Parameters:
+//   - raw: the decoded request text
+//   - cfg: text settings with the length limit and overflow behavior
+//
+// Returns:
+//   - string: the cleaned, possibly truncated text
+//   - error: apierrors.ErrTextTooLong if the text is too long and must be rejected
+//
+// Example:
+//
+//	text, err := middleware.CheckText(spec.Text, cfg.Text)
+//	if err != nil {
+//	    return apierrors.Send(c, err)
+//	}
+func CheckText(raw string, cfg config.TextConfig) (string, error) {
+	text := render.SanitizeText(raw)
+
+	if cfg.MaxLength > 0 && utf8.RuneCountInString(text) > cfg.MaxLength {
+		if cfg.Overflow != "truncate" {
+			return "", apierrors.ErrTextTooLong.WithDetail("max %d characters", cfg.MaxLength)
+		}
+		text = render.TruncateText(text, cfg.MaxLength)
+	}
+	return text, nil
+}
+
+// Text returns the validated text stored by InputValidation, or "" if the
+// middleware did not run.
+//
+// Parameters:
+//   - c: the request context
+//
+// Returns:
+//   - string: the sanitized request text
+//
+// Example:
+//
+//	text := middleware.Text(c)
+//	if text == "" {
+//	    return apierrors.Send(c, apierrors.ErrNoText)
+//	}
+func Text(c *fiber.Ctx) string {
+	text, _ := c.Locals(TextKey).(string)
+	return text
+}
+
+// PathText decodes the text segment of a request path.
+// Plus signs are treated as spaces so "HELLO+WORLD" renders as two words.
+//
+// Parameters:
+//   - raw: the raw path segment
+//
+// Returns:
+//   - string: the decoded text
+//
+// Example:
+//
+//	PathText("HELLO+WORLD") // "HELLO WORLD"
+func PathText(raw string) string {
+	if decoded, err := url.PathUnescape(raw); err == nil {
+		raw = decoded
+	}
+	return strings.ReplaceAll(raw, "+", " ")
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestInputValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.TextConfig
+		target     string
+		wantStatus int
+		wantText   string
+	}{
+		{
+			name:       "decodes and sanitizes",
+			cfg:        config.TextConfig{MaxLength: 100},
+			target:     "/HELLO++%1B%5B31mWORLD%07",
+			wantStatus: 200,
+			wantText:   "HELLO WORLD",
+		},
+		{
+			name:       "rejects long text",
+			cfg:        config.TextConfig{MaxLength: 5, Overflow: "reject"},
+			target:     "/HELLO+WORLD",
+			wantStatus: 400,
+		},
+		{
+			name:       "truncates long text",
+			cfg:        config.TextConfig{MaxLength: 7, Overflow: "truncate"},
+			target:     "/HELLO+WORLD",
+			wantStatus: 200,
+			wantText:   "HELLO W",
+		},
+		{
+			name:       "length counted after sanitizing",
+			cfg:        config.TextConfig{MaxLength: 5},
+			target:     "/%1B%5B1mHELLO%1B%5B0m",
+			wantStatus: 200,
+			wantText:   "HELLO",
+		},
+		{
+			name:       "length counted in characters",
+			cfg:        config.TextConfig{MaxLength: 4},
+			target:     "/%C3%89%C3%89%C3%89%C3%89",
+			wantStatus: 200,
+			wantText:   "ÉÉÉÉ",
+		},
+		{
+			name:       "no limit",
+			cfg:        config.TextConfig{},
+			target:     "/HELLO+WORLD",
+			wantStatus: 200,
+			wantText:   "HELLO WORLD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/*", InputValidation(tt.cfg), func(c *fiber.Ctx) error {
+				return c.SendString(Text(c))
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", tt.target, nil), -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus == 200 && string(body) != tt.wantText {
+				t.Errorf("text = %q, want %q", body, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestTextWithoutMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Get("/*", func(c *fiber.Ctx) error {
+		return c.SendString(Text(c))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/HELLO", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); len(body) != 0 {
+		t.Errorf("expected empty text, got %q", body)
+	}
+}
+
+func TestPathText(t *testing.T) {
+	tests := map[string]string{
+		"HELLO+WORLD":   "HELLO WORLD",
+		"HELLO%20WORLD": "HELLO WORLD",
+		"100%25":        "100%",
+	}
+	for in, want := range tests {
+		if got := PathText(in); got != want {
+			t.Errorf("PathText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
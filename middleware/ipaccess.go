@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/ipaccess"
+)
+
+// IPAccess refuses requests from IPs the guard's current list doesn't
+// allow with 403. It should run before anything that counts requests, so
+// refused clients don't use up limits.
+//
+// Parameters:
+//   - guard: the allow and deny lists
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	app.Use(middleware.IPAccess(guard))
+func IPAccess(guard *ipaccess.Guard) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !guard.Allowed(c.IP()) {
+			return apierrors.Send(c, apierrors.ErrIPDenied)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/ipaccess"
+)
+
+func TestIPAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.AccessConfig
+		wantStatus int
+	}{
+		// app.Test requests come from 0.0.0.0
+		{name: "allowed", cfg: config.AccessConfig{Allow: []string{"0.0.0.0/8"}}, wantStatus: 200},
+		{name: "not allowed", cfg: config.AccessConfig{Allow: []string{"10.0.0.0/8"}}, wantStatus: 403},
+		{name: "denied", cfg: config.AccessConfig{Deny: []string{"0.0.0.0"}}, wantStatus: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, err := ipaccess.NewGuard(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			app := fiber.New()
+			app.Use(IPAccess(guard))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil), -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
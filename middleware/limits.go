@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// MaxURL refuses requests whose target, path and query, is longer than
+// cfg.MaxURLBytes with 414, before any text is checked or rendered. A
+// zero limit lets every URL through.
+//
+// Parameters:
+//   - cfg: the request size limits
+//
+// Returns:
+//   - fiber.Handler: app-level middleware
+//
+// Example:
+//
+//	app.Use(middleware.MaxURL(cfg.Server))
+func MaxURL(cfg config.ServerConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if cfg.MaxURLBytes > 0 && len(c.Request().RequestURI()) > cfg.MaxURLBytes {
+			return apierrors.Send(c, apierrors.ErrURITooLong.WithDetail("max %d bytes", cfg.MaxURLBytes))
+		}
+		return c.Next()
+	}
+}
+
+// LimitErrors returns an app error handler that answers the errors of the
+// server's own size limits, the body limit (fiber.Config.BodyLimit) and
+// header limit (ReadBufferSize), as API errors naming the configured cap.
+// Fasthttp raises these before any middleware runs. Other errors are left
+// to fiber.DefaultErrorHandler.
+//
+// Parameters:
+//   - cfg: the request size limits the app was configured with
+//
+// Returns:
+//   - fiber.ErrorHandler: the app's error handler
+//
+// Example:
+//
+//	app := fiber.New(fiber.Config{
+//	    BodyLimit:      cfg.Server.MaxBodyBytes,
+//	    ReadBufferSize: cfg.Server.MaxHeaderBytes,
+//	    ErrorHandler:   middleware.LimitErrors(cfg.Server),
+//	})
+func LimitErrors(cfg config.ServerConfig) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		switch {
+		case errors.Is(err, fiber.ErrRequestEntityTooLarge):
+			return apierrors.Send(c, apierrors.ErrBodyTooLarge.WithDetail("max %d bytes", cfg.MaxBodyBytes))
+		case errors.Is(err, fiber.ErrRequestHeaderFieldsTooLarge):
+			return apierrors.Send(c, apierrors.ErrHeadersTooLarge.WithDetail("max %d bytes", cfg.MaxHeaderBytes))
+		}
+		return fiber.DefaultErrorHandler(c, err)
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestRequestLimits(t *testing.T) {
+	cfg := config.ServerConfig{MaxURLBytes: 64, MaxHeaderBytes: 1024, MaxBodyBytes: 128}
+	app := fiber.New(fiber.Config{
+		BodyLimit:      cfg.MaxBodyBytes,
+		ReadBufferSize: cfg.MaxHeaderBytes,
+		ErrorHandler:   LimitErrors(cfg),
+
+		DisableStartupMessage: true,
+	})
+	app.Use(MaxURL(cfg))
+	app.All("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	// app.Test reports the server's own limit errors instead of the
+	// response, so serve on a real listener
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go func() { _ = app.Listener(lis) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+	base := "http://" + lis.Addr().String()
+
+	tests := []struct {
+		name       string
+		target     string
+		header     string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{name: "within limits", target: "/HI", body: strings.Repeat("a", 128), wantStatus: fiber.StatusOK},
+		{name: "long URL", target: "/" + strings.Repeat("A", 64), wantStatus: fiber.StatusRequestURITooLong, wantCode: "uri_too_long", wantDetail: "max 64 bytes"},
+		{name: "large body", target: "/HI", body: strings.Repeat("a", 129), wantStatus: fiber.StatusRequestEntityTooLarge, wantCode: "body_too_large", wantDetail: "max 128 bytes"},
+		{name: "large headers", target: "/HI", header: strings.Repeat("x", 2048), wantStatus: fiber.StatusRequestHeaderFieldsTooLarge, wantCode: "headers_too_large", wantDetail: "max 1024 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := fiber.MethodGet
+			if tt.body != "" {
+				method = fiber.MethodPost
+			}
+			req, err := http.NewRequest(method, base+tt.target, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Padding", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get(apierrors.HeaderErrorCode); got != tt.wantCode {
+				t.Errorf("error code = %q, want %q", got, tt.wantCode)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(body), tt.wantDetail) {
+				t.Errorf("body = %q, want it to mention %q", body, tt.wantDetail)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// routeMetricsKey is the Locals key holding the request's route metrics
+// state.
+const routeMetricsKey = "shout.routemetrics"
+
+// Unrouted is the route label of requests answered before reaching a
+// route, such as those an IP access list or rate limit turns away, and of
+// those no route matches.
+const Unrouted = "unrouted"
+
+// routeRequest is what RouteMetrics knows of a request before it is
+// answered.
+type routeRequest struct {
+	metrics *types.Metrics
+	start   time.Time
+	// route is set by Route once a route is matched
+	route string
+}
+
+// RouteMetrics records every request in metrics once it has been
+// answered: its status, latency and response size, by method and route.
+// A streaming response is recorded when it closes instead, if its handler
+// calls StreamClosed, with how long it ran and the bytes sent.
+//
+// Parameters:
+//   - metrics: where requests are recorded
+//
+// Returns:
+//   - fiber.Handler: app-level middleware, registered before the other
+//     middleware so the latency covers them
+//
+// Example:
+//
+//	app.Use(middleware.RouteMetrics(metrics))
+//	app.Get("/date", middleware.Route(metrics), dateHandler)
+func RouteMetrics(metrics *types.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := &routeRequest{metrics: metrics, start: time.Now(), route: Unrouted}
+		c.Locals(routeMetricsKey, req)
+		err := c.Next()
+		// Let the error handler set the status before it is recorded
+		if err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		if !c.Response().IsBodyStream() {
+			metrics.ObserveRequest(strings.Clone(c.Method()), req.route, c.Response().StatusCode(),
+				time.Since(req.start), int64(len(c.Response().Body())))
+		}
+		return nil
+	}
+}
+
+// Route labels the request with the path pattern of the route it
+// matched, for RouteMetrics, and counts it in the route's in-flight gauge
+// while the route's handlers run. Routes register it first.
+//
+// Parameters:
+//   - metrics: where the in-flight gauge is kept
+//
+// Returns:
+//   - fiber.Handler: route-level middleware
+func Route(metrics *types.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route := c.Route().Path
+		if req, ok := c.Locals(routeMetricsKey).(*routeRequest); ok {
+			req.route = route
+		}
+		done := metrics.RouteStarted(route)
+		defer done()
+		return c.Next()
+	}
+}
+
+// streamMeasured counts the stream about to open in its route's open
+// streams, and returns the function that records it when it closes.
+// Without RouteMetrics the function does nothing.
+func streamMeasured(c *fiber.Ctx) func(sent int64) {
+	req, ok := c.Locals(routeMetricsKey).(*routeRequest)
+	if !ok {
+		return func(int64) {}
+	}
+	req.metrics.StreamOpened(req.route)
+	return func(sent int64) {
+		req.metrics.StreamClosed(req.route, time.Since(req.start), sent)
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestRouteMetrics(t *testing.T) {
+	metrics := &types.Metrics{}
+	app := fiber.New()
+	app.Use(RouteMetrics(metrics))
+	app.Use(func(c *fiber.Ctx) error {
+		if c.Query("deny") != "" {
+			return fiber.ErrForbidden
+		}
+		return c.Next()
+	})
+	app.Get("/badge/:label", Route(metrics), func(c *fiber.Ctx) error {
+		if got := metrics.InFlight()["/badge/:label"]; got != 1 {
+			t.Errorf("in flight = %d, want 1", got)
+		}
+		return c.SendString("badge")
+	})
+	app.Get("/broken", Route(metrics), func(c *fiber.Ctx) error { return fiber.ErrServiceUnavailable })
+	app.Get("/stream", Route(metrics), func(c *fiber.Ctx) error {
+		closed := StreamClosed(c)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			sw := NewStreamWriter(w, EncodingIdentity)
+			defer func() { closed(sw.Sent()) }()
+			sw.Write([]byte("frame\n"))
+			sw.Flush()
+		})
+		return nil
+	})
+
+	for _, path := range []string{"/badge/build", "/badge/tests", "/broken", "/badge/build?deny=1", "/stream"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatalf("app.Test(%s) error = %v", path, err)
+		}
+		_, _ = io.ReadAll(resp.Body)
+	}
+	// The stream is recorded once its writer has returned
+	deadline := time.Now().Add(time.Second)
+	for len(metrics.Streams()) == 0 || metrics.Streams()[0].Open > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream never closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	want := map[string]map[int]uint64{
+		"/badge/:label": {200: 2},
+		"/broken":       {503: 1},
+		Unrouted:        {403: 1},
+	}
+	routes := metrics.Routes()
+	if len(routes) != len(want) {
+		t.Fatalf("routes = %+v, want %v", routes, want)
+	}
+	for _, r := range routes {
+		for status, count := range want[r.Route] {
+			if r.Statuses[status] != count {
+				t.Errorf("%s statuses = %v, want %v", r.Route, r.Statuses, want[r.Route])
+			}
+		}
+	}
+	if got := metrics.InFlight()["/badge/:label"]; got != 0 {
+		t.Errorf("in flight = %d, want 0", got)
+	}
+	if s := metrics.Streams()[0]; s.Route != "/stream" || s.Bytes.Sum != 6 || s.Duration.Count != 1 {
+		t.Errorf("stream = %+v, want /stream with 6 bytes sent", s)
+	}
+}
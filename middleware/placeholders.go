@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// DynamicKey is the Locals key set when the request text had placeholders
+// expanded, so its output varies by time or client.
+const DynamicKey = "shout.dynamic"
+
+// placeholderPattern matches a placeholder such as "{hostname}".
+var placeholderPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// placeholders produce the value of each supported placeholder.
+var placeholders = map[string]func(c *fiber.Ctx, now time.Time) string{
+	"hostname": func(*fiber.Ctx, time.Time) string {
+		name, _ := os.Hostname()
+		return name
+	},
+	"date": func(_ *fiber.Ctx, now time.Time) string { return now.Format("2006-01-02") },
+	"time": func(_ *fiber.Ctx, now time.Time) string { return now.Format("15:04") },
+	"ip":   func(c *fiber.Ctx, _ time.Time) string { return c.IP() },
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+// Placeholders expands placeholders such as "{hostname}", "{date}",
+// "{time}" and "{ip}" in the validated text, for MOTD-style banners.
+// Only names listed in cfg.Placeholders are expanded; anything else in
+// braces is rendered as typed. The expanded text is checked against
+// cfg.MaxLength again. It must run after InputValidation.
+//
+// Parameters:
+//   - cfg: text settings with the placeholder allow-list and length limit
+//
+// Returns:
+//   - fiber.Handler: route middleware
+//
+// Example:
+//
+//	app.Get("/*", middleware.InputValidation(cfg.Text), middleware.Placeholders(cfg.Text), h.Static)
+func Placeholders(cfg config.TextConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(cfg.Placeholders) == 0 {
+			return c.Next()
+		}
+
+		t := now()
+		expanded := ExpandPlaceholders(Text(c), cfg.Placeholders, func(name string) string {
+			return placeholders[name](c, t)
+		})
+		if expanded == Text(c) {
+			return c.Next()
+		}
+
+		text, err := CheckText(expanded, cfg)
+		if err != nil {
+			return apierrors.Send(c, err)
+		}
+		c.Locals(TextKey, text)
+		c.Locals(DynamicKey, true)
+		return c.Next()
+	}
+}
+
+// ExpandPlaceholders replaces the allowed, supported placeholders in text
+// with the values returned by value.
+//
+// Parameters:
+//   - text: the text containing placeholders
+//   - allowed: the placeholder names that may be expanded
+//   - value: returns the value of a supported placeholder
+//
+// Returns:
+//   - string: the text with placeholders replaced
+//
+// Example:
+//
+//	ExpandPlaceholders("ON {date}", []string{"date"}, func(string) string { return "2024-01-02" }) // "ON 2024-01-02"
+func ExpandPlaceholders(text string, allowed []string, value func(name string) string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := match[1 : len(match)-1]
+		if _, supported := placeholders[name]; !supported || !slices.Contains(allowed, name) {
+			return match
+		}
+		return value(name)
+	})
+}
+
+// Dynamic reports whether Placeholders expanded the request text, making
+// the output unsuitable for shared caches.
+//
+// Parameters:
+//   - c: the request context
+//
+// Returns:
+//   - bool: true if placeholders were expanded
+func Dynamic(c *fiber.Ctx) bool {
+	dynamic, _ := c.Locals(DynamicKey).(bool)
+	return dynamic
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestExpandPlaceholders(t *testing.T) {
+	values := map[string]string{"date": "2024-01-02", "time": "09:30", "ip": "10.0.0.1"}
+	lookup := func(name string) string { return values[name] }
+	all := []string{"hostname", "date", "time", "ip"}
+
+	tests := []struct {
+		name    string
+		text    string
+		allowed []string
+		want    string
+	}{
+		{name: "single", text: "ON {date}", allowed: all, want: "ON 2024-01-02"},
+		{name: "several", text: "{date} {time}", allowed: all, want: "2024-01-02 09:30"},
+		{name: "not allowed", text: "FROM {ip}", allowed: []string{"date"}, want: "FROM {ip}"},
+		{name: "unsupported", text: "UP {uptime}", allowed: append(all, "uptime"), want: "UP {uptime}"},
+		{name: "case sensitive", text: "{DATE}", allowed: all, want: "{DATE}"},
+		{name: "no placeholders", text: "HELLO", allowed: all, want: "HELLO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandPlaceholders(tt.text, tt.allowed, lookup); got != tt.want {
+				t.Errorf("ExpandPlaceholders(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2024, 1, 2, 9, 30, 0, 0, time.Local) }
+	hostname, _ := os.Hostname()
+
+	tests := []struct {
+		name        string
+		cfg         config.TextConfig
+		target      string
+		wantStatus  int
+		wantText    string
+		wantDynamic bool
+	}{
+		{
+			name:        "expands allowed placeholders",
+			cfg:         config.TextConfig{MaxLength: 100, Placeholders: []string{"date", "time"}},
+			target:      "/%7Bdate%7D+%7Btime%7D",
+			wantStatus:  200,
+			wantText:    "2024-01-02 09:30",
+			wantDynamic: true,
+		},
+		{
+			name:        "hostname",
+			cfg:         config.TextConfig{MaxLength: 100, Placeholders: []string{"hostname"}},
+			target:      "/Welcome+to+%7Bhostname%7D",
+			wantStatus:  200,
+			wantText:    "Welcome to " + hostname,
+			wantDynamic: true,
+		},
+		{
+			name:       "disabled",
+			cfg:        config.TextConfig{MaxLength: 100},
+			target:     "/%7Bdate%7D",
+			wantStatus: 200,
+			wantText:   "{date}",
+		},
+		{
+			name:       "expanded text too long",
+			cfg:        config.TextConfig{MaxLength: 8, Overflow: "reject", Placeholders: []string{"date"}},
+			target:     "/%7Bdate%7D",
+			wantStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/*", InputValidation(tt.cfg), Placeholders(tt.cfg), func(c *fiber.Ctx) error {
+				if Dynamic(c) != tt.wantDynamic {
+					t.Errorf("Dynamic = %v, want %v", Dynamic(c), tt.wantDynamic)
+				}
+				return c.SendString(Text(c))
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", tt.target, nil), -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus == 200 && string(body) != tt.wantText {
+				t.Errorf("text = %q, want %q", body, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/errreport"
+)
+
+// redactedParams are the query parameters kept out of error reports and
+// access logs.
+var redactedParams = []string{"key", "sig"}
+
+// ReportErrors sends panics and 5xx responses to reporter, with the
+// request that caused them. A panic is reported and then re-raised, so
+// the recover middleware still answers it.
+//
+// Parameters:
+//   - reporter: where reports go
+//
+// Returns:
+//   - fiber.Handler: app-level middleware, registered after the recover
+//     middleware
+//
+// Example:
+//
+//	app.Use(middleware.ReportErrors(reporter))
+func ReportErrors(reporter *errreport.Reporter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		defer func() {
+			if r := recover(); r != nil {
+				reporter.Report(errreport.Report{
+					Level:   errreport.LevelFatal,
+					Message: fmt.Sprintf("panic: %v", r),
+					Stack:   string(debug.Stack()),
+					Request: ReportRequest(c, fiber.StatusInternalServerError),
+				})
+				panic(r)
+			}
+		}()
+		err := c.Next()
+		// Let the error handler set the status before it is checked
+		if err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		if status := c.Response().StatusCode(); status >= fiber.StatusInternalServerError {
+			message := fmt.Sprintf("%d %s", status, c.Path())
+			if err != nil {
+				message = err.Error()
+			}
+			reporter.Report(errreport.Report{
+				Level:   errreport.LevelError,
+				Message: message,
+				Request: ReportRequest(c, status),
+			})
+		}
+		return nil
+	}
+}
+
+// ReportRequest describes the request in c for an error report, with API
+// keys and signatures redacted from its URL. Strings are copied, since
+// c's buffers are reused.
+//
+// Parameters:
+//   - c: the request context
+//   - status: the status the request was, or will be, answered with
+//
+// Returns:
+//   - *errreport.Request: the request's context
+func ReportRequest(c *fiber.Ctx, status int) *errreport.Request {
+	return &errreport.Request{
+		Method:    strings.Clone(c.Method()),
+		URL:       c.Protocol() + "://" + c.Hostname() + redactedURI(c),
+		Status:    status,
+		Code:      string(c.Response().Header.Peek(apierrors.HeaderErrorCode)),
+		UserAgent: string(c.Request().Header.UserAgent()),
+	}
+}
+
+// redactedURI returns the request URI of c with the values of
+// redactedParams replaced by [redacted]. A URI without them is returned
+// as sent, and one that doesn't parse as its path alone.
+func redactedURI(c *fiber.Ctx) string {
+	uri := string(c.Request().RequestURI())
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return (&url.URL{Path: c.Path()}).String()
+	}
+	query := u.Query()
+	if !slices.ContainsFunc(redactedParams, query.Has) {
+		return uri
+	}
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, "[redacted]")
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/errreport"
+)
+
+func TestReportErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		// wantReport is the level reported, or empty for no report
+		wantReport string
+		wantURL    string
+		wantCode   string
+	}{
+		{name: "ok", path: "/HI", wantStatus: 200},
+		{name: "client error", path: "/missing", wantStatus: 404},
+		{
+			name:       "server error",
+			path:       "/fail?key=s3cret&f=doom",
+			wantStatus: 500,
+			wantReport: errreport.LevelError,
+			wantURL:    "http://example.com/fail?f=doom&key=%5Bredacted%5D",
+			wantCode:   apierrors.ErrRenderFailed.Code,
+		},
+		{
+			name:       "returned error",
+			path:       "/broken",
+			wantStatus: 503,
+			wantReport: errreport.LevelError,
+			wantURL:    "http://example.com/broken",
+		},
+		{
+			name:       "panic",
+			path:       "/panic?sig=abc",
+			wantStatus: 500,
+			wantReport: errreport.LevelFatal,
+			wantURL:    "http://example.com/panic?sig=%5Bredacted%5D",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(chan errreport.Report, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var rep errreport.Report
+				_ = json.NewDecoder(r.Body).Decode(&rep)
+				got <- rep
+			}))
+			defer srv.Close()
+			reporter, err := errreport.Open(config.ErrorsConfig{Webhook: srv.URL}, "")
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			app := fiber.New()
+			app.Use(recover.New())
+			app.Use(ReportErrors(reporter))
+			app.Get("/HI", func(c *fiber.Ctx) error { return c.SendString("HI\n") })
+			app.Get("/missing", func(c *fiber.Ctx) error { return fiber.ErrNotFound })
+			app.Get("/fail", func(c *fiber.Ctx) error { return apierrors.Send(c, apierrors.ErrRenderFailed) })
+			app.Get("/broken", func(c *fiber.Ctx) error { return fiber.ErrServiceUnavailable })
+			app.Get("/panic", func(c *fiber.Ctx) error { panic("boom") })
+
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if err := reporter.Close(context.Background()); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+
+			if tt.wantReport == "" {
+				if len(got) > 0 {
+					t.Errorf("reported %+v, want no report", <-got)
+				}
+				return
+			}
+			if len(got) == 0 {
+				t.Fatal("nothing reported")
+			}
+			rep := <-got
+			if rep.Level != tt.wantReport {
+				t.Errorf("level = %q, want %q", rep.Level, tt.wantReport)
+			}
+			if rep.Request == nil || rep.Request.URL != tt.wantURL || rep.Request.Status != tt.wantStatus {
+				t.Fatalf("request = %+v, want %s answered %d", rep.Request, tt.wantURL, tt.wantStatus)
+			}
+			if rep.Request.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", rep.Request.Code, tt.wantCode)
+			}
+			if tt.wantReport == errreport.LevelFatal && !strings.Contains(rep.Stack, "goroutine") {
+				t.Errorf("stack = %q, want the panic's stack", rep.Stack)
+			}
+		})
+	}
+}
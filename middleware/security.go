@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// CSPKey is the Locals key holding the route's Content Security Policy.
+const CSPKey = "shout.csp"
+
+// DefaultCSP is the Content Security Policy of HTML and SVG responses
+// whose route sets none: nothing may load or run.
+const DefaultCSP = "default-src 'none'"
+
+// SecurityHeaders adds security headers to every response: nosniff, so
+// plain text banners are never sniffed as HTML, and cfg.ReferrerPolicy.
+// HTML and SVG responses also get the route's Content Security Policy
+// (see CSP), or DefaultCSP, with frame-ancestors limiting who may embed
+// them. It does nothing when cfg.Headers is off.
+//
+// Parameters:
+//   - cfg: frame ancestors and referrer policy
+//
+// Returns:
+//   - fiber.Handler: app-level middleware
+//
+// Example:
+//
+//	app.Use(middleware.SecurityHeaders(cfg.Security))
+func SecurityHeaders(cfg config.SecurityConfig) fiber.Handler {
+	if !cfg.Headers {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	ancestors := strings.Join(cfg.FrameAncestors, " ")
+	if ancestors == "" {
+		ancestors = "'none'"
+	}
+	frameOptions := ""
+	switch ancestors {
+	case "'none'":
+		frameOptions = "DENY"
+	case "'self'":
+		frameOptions = "SAMEORIGIN"
+	}
+
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		if cfg.ReferrerPolicy != "" {
+			c.Set(fiber.HeaderReferrerPolicy, cfg.ReferrerPolicy)
+		}
+		if !activeContent(string(c.Response().Header.ContentType())) {
+			return err
+		}
+		policy, _ := c.Locals(CSPKey).(string)
+		if policy == "" {
+			policy = DefaultCSP
+		}
+		if !strings.Contains(policy, "frame-ancestors") {
+			policy += "; frame-ancestors " + ancestors
+		}
+		c.Set(fiber.HeaderContentSecurityPolicy, policy)
+		// For browsers that predate frame-ancestors
+		if frameOptions != "" {
+			c.Set(fiber.HeaderXFrameOptions, frameOptions)
+		}
+		return err
+	}
+}
+
+// CSP sets the Content Security Policy SecurityHeaders sends for the
+// route's HTML and SVG responses.
+//
+// Parameters:
+//   - policy: the policy, without frame-ancestors unless overriding it
+//
+// Returns:
+//   - fiber.Handler: route middleware
+//
+// Example:
+//
+//	app.Get("/help", middleware.CSP("default-src 'none'; style-src 'unsafe-inline'"), h.Help)
+func CSP(policy string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(CSPKey, policy)
+		return c.Next()
+	}
+}
+
+// activeContent reports whether a response of contentType can run
+// scripts or load resources in a browser.
+func activeContent(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case fiber.MIMETextHTML, "image/svg+xml", "application/xhtml+xml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	enabled := config.SecurityConfig{Headers: true, FrameAncestors: []string{"'self'"}, ReferrerPolicy: "no-referrer"}
+
+	tests := []struct {
+		name        string
+		cfg         config.SecurityConfig
+		route       string
+		wantCSP     string
+		wantFrame   string
+		wantNosniff bool
+	}{
+		{name: "plain text", cfg: enabled, route: "/text", wantNosniff: true},
+		{name: "HTML default", cfg: enabled, route: "/html", wantCSP: "default-src 'none'; frame-ancestors 'self'", wantFrame: "SAMEORIGIN", wantNosniff: true},
+		{name: "route policy", cfg: enabled, route: "/styled", wantCSP: "style-src 'unsafe-inline'; frame-ancestors 'self'", wantFrame: "SAMEORIGIN", wantNosniff: true},
+		{
+			name:        "embeddable anywhere",
+			cfg:         config.SecurityConfig{Headers: true, FrameAncestors: []string{"*"}},
+			route:       "/html",
+			wantCSP:     "default-src 'none'; frame-ancestors *",
+			wantNosniff: true,
+		},
+		{
+			name:        "no ancestors",
+			cfg:         config.SecurityConfig{Headers: true},
+			route:       "/html",
+			wantCSP:     "default-src 'none'; frame-ancestors 'none'",
+			wantFrame:   "DENY",
+			wantNosniff: true,
+		},
+		{name: "disabled", cfg: config.SecurityConfig{}, route: "/html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(SecurityHeaders(tt.cfg))
+			html := func(c *fiber.Ctx) error {
+				c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+				return c.SendString("<p>hi</p>")
+			}
+			app.Get("/text", func(c *fiber.Ctx) error { return c.SendString("hi") })
+			app.Get("/html", html)
+			app.Get("/styled", CSP("style-src 'unsafe-inline'"), html)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tt.route, nil), -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := resp.Header.Get(fiber.HeaderContentSecurityPolicy); got != tt.wantCSP {
+				t.Errorf("CSP = %q, want %q", got, tt.wantCSP)
+			}
+			if got := resp.Header.Get(fiber.HeaderXFrameOptions); got != tt.wantFrame {
+				t.Errorf("X-Frame-Options = %q, want %q", got, tt.wantFrame)
+			}
+			if got := resp.Header.Get(fiber.HeaderXContentTypeOptions) == "nosniff"; got != tt.wantNosniff {
+				t.Errorf("nosniff = %v, want %v", got, tt.wantNosniff)
+			}
+			if got := resp.Header.Get(fiber.HeaderReferrerPolicy); got != tt.cfg.ReferrerPolicy {
+				t.Errorf("Referrer-Policy = %q, want %q", got, tt.cfg.ReferrerPolicy)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Signed request headers, the same as async callbacks carry. Links can't
+// set headers, so the ts and sig query parameters work too.
+const (
+	HeaderTimestamp = "X-Shout-Timestamp"
+	HeaderSignature = "X-Shout-Signature"
+)
+
+// signaturePrefix names the hash in signatures, as in GitHub webhooks.
+const signaturePrefix = "sha256="
+
+// SignRequest returns the signature of a request for path and query made
+// at timestamp: "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot,
+// the path, a question mark and the query sorted by key, without ts and
+// sig.
+//
+// Parameters:
+//   - secret: one of SHOUT_SIGNING_SECRETS
+//   - timestamp: the Unix time of the request, in seconds
+//   - path: the request path as sent, e.g. "/HELLO%20WORLD"
+//   - query: the query parameters
+//
+// Returns:
+//   - string: the value of X-Shout-Signature, or of ?sig=
+//
+// Example:
+//
+//	ts := strconv.FormatInt(time.Now().Unix(), 10)
+//	sig := middleware.SignRequest(secret, ts, "/HELLO", url.Values{"f": {"doom"}})
+//	// GET /HELLO?f=doom&ts=<ts>&sig=<sig>
+func SignRequest(secret, timestamp, path string, query url.Values) string {
+	signed := url.Values{}
+	for key, values := range query {
+		if key != "ts" && key != "sig" {
+			signed[key] = values
+		}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + path + "?" + signed.Encode()))
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signed refuses requests that don't carry a current signature by one of
+// cfg.Secrets, so an instance behind a CDN isn't an open renderer. The
+// timestamp must be within cfg.MaxAge of now, and with cfg.SingleUse a
+// signature is refused the second time. CORS preflight requests pass
+// through, since browsers send them without the signature.
+//
+// Parameters:
+//   - cfg: the secrets and how long a signature lasts
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	if len(cfg.Signing.Secrets) > 0 {
+//	    app.Use(middleware.Signed(cfg.Signing))
+//	}
+func Signed(cfg config.SigningConfig) fiber.Handler {
+	seen := &seenSignatures{expires: make(map[string]time.Time)}
+	return func(c *fiber.Ctx) error {
+		if c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		timestamp := cmp.Or(c.Get(HeaderTimestamp), c.Query("ts"))
+		signature := cmp.Or(c.Get(HeaderSignature), c.Query("sig"))
+		if timestamp == "" || signature == "" {
+			return apierrors.Send(c, apierrors.ErrBadSignature.WithHint("send X-Shout-Timestamp and X-Shout-Signature, or ?ts= and ?sig="))
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return apierrors.Send(c, apierrors.ErrBadSignature.WithDetail("the timestamp must be Unix seconds, got %q", timestamp))
+		}
+		signed, now := time.Unix(unix, 0), now()
+		if age := now.Sub(signed); age > cfg.MaxAge || age < -cfg.MaxAge {
+			return apierrors.Send(c, apierrors.ErrBadSignature.WithDetail("the timestamp is more than %v from the server's clock", cfg.MaxAge))
+		}
+
+		path := string(c.Request().URI().PathOriginal())
+		query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+		if err != nil {
+			return apierrors.Send(c, apierrors.ErrBadSignature.WithDetail("invalid query: %v", err))
+		}
+		if !strings.HasPrefix(signature, signaturePrefix) {
+			signature = signaturePrefix + signature
+		}
+		valid := false
+		for _, secret := range cfg.Secrets {
+			if hmac.Equal([]byte(signature), []byte(SignRequest(secret, timestamp, path, query))) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return apierrors.Send(c, apierrors.ErrBadSignature)
+		}
+		// Remembered as long as the timestamp is accepted, which for one
+		// in the future is longer than MaxAge from now
+		if cfg.SingleUse && !seen.add(signature, now, signed.Add(cfg.MaxAge)) {
+			return apierrors.Send(c, apierrors.ErrBadSignature.WithDetail("the signature was already used"))
+		}
+		return c.Next()
+	}
+}
+
+// seenSignatures remembers signatures until they expire, for single use.
+type seenSignatures struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	// swept is when expired signatures were last dropped
+	swept time.Time
+}
+
+// add records signature until expires, or reports false if it is already
+// recorded.
+func (s *seenSignatures) add(signature string, now, expires time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.swept) >= time.Minute {
+		for sig, exp := range s.expires {
+			if now.After(exp) {
+				delete(s.expires, sig)
+			}
+		}
+		s.swept = now
+	}
+	if exp, ok := s.expires[signature]; ok && !now.After(exp) {
+		return false
+	}
+	s.expires[signature] = expires
+	return true
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestSigned(t *testing.T) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	query := url.Values{"f": {"doom"}, "a": {"left"}}
+	sig := SignRequest("new", now, "/HELLO%20YOU", query)
+
+	tests := []struct {
+		name       string
+		target     string
+		headers    map[string]string
+		method     string
+		wantStatus int
+	}{
+		{name: "query signature", target: "/HELLO%20YOU?f=doom&a=left&ts=" + now + "&sig=" + url.QueryEscape(sig), wantStatus: 200},
+		{name: "params in another order", target: "/HELLO%20YOU?sig=" + url.QueryEscape(sig) + "&a=left&ts=" + now + "&f=doom", wantStatus: 200},
+		{name: "header signature", target: "/HELLO%20YOU?f=doom&a=left", headers: map[string]string{HeaderTimestamp: now, HeaderSignature: sig}, wantStatus: 200},
+		{name: "bare hex", target: "/HELLO%20YOU?f=doom&a=left&ts=" + now + "&sig=" + sig[len(signaturePrefix):], wantStatus: 200},
+		{name: "rotated secret", target: "/HI?ts=" + now + "&sig=" + url.QueryEscape(SignRequest("old", now, "/HI", nil)), wantStatus: 200},
+		{name: "unsigned", target: "/HI", wantStatus: 401},
+		{name: "changed query", target: "/HELLO%20YOU?f=small&a=left&ts=" + now + "&sig=" + url.QueryEscape(sig), wantStatus: 401},
+		{name: "changed path", target: "/BYE?f=doom&a=left&ts=" + now + "&sig=" + url.QueryEscape(sig), wantStatus: 401},
+		{name: "unknown secret", target: "/HI?ts=" + now + "&sig=" + url.QueryEscape(SignRequest("guess", now, "/HI", nil)), wantStatus: 401},
+		{name: "expired", target: "/HI?ts=" + old + "&sig=" + url.QueryEscape(SignRequest("new", old, "/HI", nil)), wantStatus: 401},
+		{name: "preflight", method: "OPTIONS", target: "/HI", wantStatus: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(Signed(config.SigningConfig{Secrets: []string{"new", "old"}, MaxAge: 5 * time.Minute}))
+			app.All("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+			method := tt.method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, tt.target, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSignedSingleUse(t *testing.T) {
+	app := fiber.New()
+	app.Use(Signed(config.SigningConfig{Secrets: []string{"s3cret"}, MaxAge: time.Minute, SingleUse: true}))
+	app.Get("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	target := "/HI?ts=" + now + "&sig=" + url.QueryEscape(SignRequest("s3cret", now, "/HI", nil))
+	for i, want := range []int{200, 401} {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil), -1)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("request %d status = %d, want %d", i+1, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestSignedSingleUseFutureTimestamp(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	start := time.Now()
+	clock := start
+	now = func() time.Time { return clock }
+
+	app := fiber.New()
+	app.Use(Signed(config.SigningConfig{Secrets: []string{"s3cret"}, MaxAge: time.Minute, SingleUse: true}))
+	app.Get("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	// Dated a minute ahead, the signature is accepted for two minutes
+	ts := strconv.FormatInt(start.Add(time.Minute).Unix(), 10)
+	target := "/HI?ts=" + ts + "&sig=" + url.QueryEscape(SignRequest("s3cret", ts, "/HI", nil))
+	for i, tt := range []struct {
+		after time.Duration
+		want  int
+	}{
+		{after: 0, want: 200},
+		{after: 90 * time.Second, want: 401},
+		{after: 3 * time.Minute, want: 401},
+	} {
+		clock = start.Add(tt.after)
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil), -1)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("request %d after %v status = %d, want %d", i+1, tt.after, resp.StatusCode, tt.want)
+		}
+	}
+}
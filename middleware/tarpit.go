@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Tarpit answers abusive clients slowly: their error is dripped out a byte
+// at a time, so scripts spend their own time waiting instead of retrying.
+// A limited number of clients are held at once; the rest get the error
+// straight away.
+//
+// Usage example:
+//
+//	tarpit := middleware.NewTarpit(time.Second, 30, 100)
+//	return tarpit.Send(c, apierrors.ErrSuspectedBot)
+type Tarpit struct {
+	interval time.Duration
+	maxBytes int
+	slots    *types.ConnectionManager
+}
+
+// NewTarpit creates a tarpit.
+//
+// Parameters:
+//   - interval: the time between bytes
+//   - maxBytes: how many bytes a response drips before it ends
+//   - maxClients: how many clients are held at once
+//
+// Returns:
+//   - *Tarpit: the tarpit
+func NewTarpit(interval time.Duration, maxBytes int, maxClients int64) *Tarpit {
+	return &Tarpit{interval: interval, maxBytes: maxBytes, slots: types.NewConnectionManager(maxClients)}
+}
+
+// Send answers with err like apierrors.Send, with its status and code,
+// but drips the plain text error, repeated up to the tarpit's size, a
+// byte at a time.
+//
+// Parameters:
+//   - c: the request context
+//   - err: the error to answer with
+//
+// Returns:
+//   - error: the result of starting the response
+func (t *Tarpit) Send(c *fiber.Ctx, err *apierrors.Error) error {
+	if t.maxBytes <= 0 || !t.slots.TryAcquire() {
+		return apierrors.Send(c, err)
+	}
+	text := "Error: " + err.Error() + "\n"
+	body := strings.Repeat(text, t.maxBytes/len(text)+1)[:t.maxBytes]
+
+	c.Set(apierrors.HeaderErrorCode, err.Code)
+	c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Status(err.Status)
+	if c.Method() == fiber.MethodHead {
+		t.slots.Release()
+		return nil
+	}
+	conn := c.Context().Conn()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer t.slots.Release()
+		for i := range len(body) {
+			if i > 0 {
+				time.Sleep(t.interval)
+			}
+			// The server's write timeout would end the drip early
+			if conn != nil {
+				_ = conn.SetWriteDeadline(time.Now().Add(t.interval + 10*time.Second))
+			}
+			w.WriteByte(body[i])
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// TarpitOffenders tarpits clients that keep hitting their limits: once a
+// client, its API key or else its IP, has had strikes 429 responses within
+// window, its next ones are dripped through the tarpit instead. It should
+// run before the rate limits and quotas, so it sees their responses.
+//
+// Parameters:
+//   - tarpit: drips the errors out
+//   - strikes: the 429s a client may get in window before it is tarpitted
+//   - window: the period strikes are counted over
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	app.Use(middleware.TarpitOffenders(tarpit, cfg.Tarpit.Strikes, cfg.Tarpit.Window))
+func TarpitOffenders(tarpit *Tarpit, strikes int, window time.Duration) fiber.Handler {
+	offenders := &strikeCounter{window: window, clients: make(map[string]*strikeCount)}
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err != nil || c.Response().StatusCode() != fiber.StatusTooManyRequests || c.Response().IsBodyStream() {
+			return err
+		}
+		client := "ip:" + c.IP()
+		if key := Key(c); key != nil {
+			client = "key:" + key.Name
+		}
+		if offenders.add(client, time.Now()) <= strikes {
+			return nil
+		}
+		limitErr := apierrors.ErrRateLimited
+		if string(c.Response().Header.Peek(apierrors.HeaderErrorCode)) == apierrors.ErrQuotaExceeded.Code {
+			limitErr = apierrors.ErrQuotaExceeded
+		}
+		c.Response().ResetBody()
+		return tarpit.Send(c, limitErr)
+	}
+}
+
+// strikeCount is a client's strikes in the window that started at start.
+type strikeCount struct {
+	start time.Time
+	count int
+}
+
+// strikeCounter counts strikes per client over fixed windows.
+type strikeCounter struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	clients   map[string]*strikeCount
+	lastSweep time.Time
+}
+
+// add records a strike for client and returns its strikes in the current
+// window, this one included.
+func (s *strikeCounter) add(client string, now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= s.window {
+		s.lastSweep = now
+		for id, sc := range s.clients {
+			if now.Sub(sc.start) >= s.window {
+				delete(s.clients, id)
+			}
+		}
+	}
+	sc := s.clients[client]
+	if sc == nil || now.Sub(sc.start) >= s.window {
+		sc = &strikeCount{start: now}
+		s.clients[client] = sc
+	}
+	sc.count++
+	return sc.count
+}
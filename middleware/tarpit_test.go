@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/apierrors"
+)
+
+func TestTarpitDrips(t *testing.T) {
+	tarpit := NewTarpit(20*time.Millisecond, 5, 1)
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return tarpit.Send(c, apierrors.ErrRateLimited) })
+
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "Error" || resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Errorf("response = %d %q, want 429 \"Error\"", resp.StatusCode, body)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("5 bytes took %v, want at least 4 intervals", elapsed)
+	}
+	if !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), "text/plain") {
+		t.Errorf("content type = %q, want text/plain", resp.Header.Get(fiber.HeaderContentType))
+	}
+}
+
+func TestTarpitOffenders(t *testing.T) {
+	app := fiber.New()
+	app.Use(TarpitOffenders(NewTarpit(time.Millisecond, 10, 10), 2, time.Minute))
+	app.Get("/limited", func(c *fiber.Ctx) error { return apierrors.Send(c, apierrors.ErrRateLimited) })
+	app.Get("/quota", func(c *fiber.Ctx) error { return apierrors.Send(c, apierrors.ErrQuotaExceeded) })
+	app.Get("/ok", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	tests := []struct {
+		path     string
+		wantBody string
+	}{
+		{path: "/limited", wantBody: "Error: Too many requests, try again later\n"},
+		{path: "/ok", wantBody: "ok"},
+		{path: "/limited", wantBody: "Error: Too many requests, try again later\n"},
+		// The third strike in the window is tarpitted
+		{path: "/limited", wantBody: "Error: Too"},
+		{path: "/quota", wantBody: "Error: Dai"},
+	}
+
+	for i, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil), -1)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i+1, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != tt.wantBody {
+			t.Errorf("request %d to %s body = %q, want %q", i+1, tt.path, body, tt.wantBody)
+		}
+	}
+}
+
+func TestStrikeCounter(t *testing.T) {
+	s := &strikeCounter{window: time.Minute, clients: make(map[string]*strikeCount)}
+	now := time.Unix(1700000000, 0)
+
+	for want := 1; want <= 3; want++ {
+		if got := s.add("ip:203.0.113.7", now); got != want {
+			t.Fatalf("strike %d counted as %d", want, got)
+		}
+	}
+	if got := s.add("ip:198.51.100.7", now); got != 1 {
+		t.Errorf("another client's first strike counted as %d", got)
+	}
+	if got := s.add("ip:203.0.113.7", now.Add(time.Minute)); got != 1 {
+		t.Errorf("strike in a new window counted as %d, want 1", got)
+	}
+	if len(s.clients) != 1 {
+		t.Errorf("counter remembers %d clients after a sweep, want 1", len(s.clients))
+	}
+}
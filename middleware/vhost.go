@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanlewis/shout-sh/vhost"
+)
+
+// HostKey is the Locals key holding the request's virtual host.
+const HostKey = "shout.vhost"
+
+// VHost looks up the virtual host of the request's Host header and stores
+// it for Host, so handlers apply its defaults and branding.
+//
+// Parameters:
+//   - hosts: the virtual hosts
+//
+// Returns:
+//   - fiber.Handler: app middleware
+//
+// Example:
+//
+//	app.Use(middleware.VHost(hosts))
+func VHost(hosts *vhost.Hosts) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if host := hosts.Lookup(c.Hostname()); host != nil {
+			c.Locals(HostKey, host)
+		}
+		return c.Next()
+	}
+}
+
+// Host returns the request's virtual host, or nil if virtual hosts are
+// off or the hosts file doesn't list the request's host.
+//
+// Parameters:
+//   - c: the request context
+//
+// Returns:
+//   - *vhost.Host: the host stored by VHost, or nil
+func Host(c *fiber.Ctx) *vhost.Host {
+	host, _ := c.Locals(HostKey).(*vhost.Host)
+	return host
+}
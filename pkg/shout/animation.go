@@ -0,0 +1,242 @@
+package shout
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// defaultAnimation is the animation used when none is named.
+const defaultAnimation = "colors"
+
+// TypewriterHold is how many frames the typewriter animation holds the
+// whole text before the next pass.
+const TypewriterHold = 4
+
+// Animation moves a rendered banner. Colors move with every frame of
+// every animation; the animation decides what is drawn.
+//
+// Animations combine with "+", as in typewriter+scroll (see
+// ParseAnimation): each layer transforms the output of the one before.
+type Animation struct {
+	// length returns the frames in one pass over layout, or 0 if the
+	// animation never ends
+	length func(layout *render.Layout) int
+	// frame returns what frame n of a pass draws
+	frame func(layout *render.Layout, n int) *render.Layout
+}
+
+// animations are the animations, keyed by name.
+var animations = map[string]Animation{
+	"colors": {
+		length: func(*render.Layout) int { return 0 },
+		frame:  func(layout *render.Layout, _ int) *render.Layout { return layout },
+	},
+	"typewriter": {
+		length: func(layout *render.Layout) int { return len(layout.Text) + TypewriterHold },
+		frame:  typewriter,
+	},
+	"scroll": {
+		length: func(layout *render.Layout) int { return 2 * render.LayoutWidth(layout) },
+		frame:  scroll,
+	},
+}
+
+// animationOrder is the order layers are applied in, whatever order they
+// were named in: what is drawn is decided before where it is drawn, so
+// typewriter+scroll and scroll+typewriter both type while scrolling.
+var animationOrder = []string{"typewriter", "scroll", "colors"}
+
+// AnimationNames returns the names ParseAnimation accepts: the
+// animations, then the color presets, which may be layered on them.
+//
+// Returns:
+//   - []string: the animation names, sorted, then the preset names
+//
+// Example:
+//
+//	fmt.Println(strings.Join(shout.AnimationNames(), ", "))
+func AnimationNames() []string {
+	names := slices.Sorted(maps.Keys(animations))
+	return append(names, render.PresetNames()...)
+}
+
+// ParseAnimation parses an animation spec, a "+" separated list of at most
+// one of each animation and at most one color preset, ignoring case. The
+// animations are layered (see layer) and the preset, if any, is returned
+// for the caller to color the frames with. An empty spec is the colors
+// animation.
+//
+// Parameters:
+//   - spec: the animation spec, such as "scroll+rainbow"
+//
+// Returns:
+//   - Animation: the layered animation
+//   - string: the color preset named in spec, or ""
+//   - error: error if spec names an unknown or repeated animation, or two presets
+//
+// Example:
+//
+//	anim, preset, err := shout.ParseAnimation("typewriter+fire")
+func ParseAnimation(spec string) (Animation, string, error) {
+	var names []string
+	var preset string
+	// A literal + in a query string decodes to a space
+	for _, part := range strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool { return r == '+' || r == ' ' }) {
+		switch {
+		case animations[part].frame != nil:
+			if slices.Contains(names, part) {
+				return Animation{}, "", fmt.Errorf("animation %q repeated", part)
+			}
+			names = append(names, part)
+		case slices.Contains(render.PresetNames(), part):
+			if preset != "" {
+				return Animation{}, "", fmt.Errorf("animations %q and %q both set the colors", preset, part)
+			}
+			preset = part
+		default:
+			return Animation{}, "", fmt.Errorf("unknown animation %q", part)
+		}
+	}
+	if len(names) == 0 {
+		names = []string{defaultAnimation}
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		return slices.Index(animationOrder, a) - slices.Index(animationOrder, b)
+	})
+	layers := make([]Animation, len(names))
+	for i, name := range names {
+		layers[i] = animations[name]
+	}
+	return layer(layers...), preset, nil
+}
+
+// layer combines animations into one. A pass lasts as long as the longest
+// finite layer; shorter layers hold their last frame until it ends. An
+// endless layer makes the whole animation endless only when every layer
+// is endless.
+func layer(layers ...Animation) Animation {
+	if len(layers) == 1 {
+		return layers[0]
+	}
+	return Animation{
+		length: func(layout *render.Layout) int {
+			longest := 0
+			for _, a := range layers {
+				longest = max(longest, a.length(layout))
+			}
+			return longest
+		},
+		frame: func(layout *render.Layout, n int) *render.Layout {
+			frame := layout
+			for _, a := range layers {
+				step := n
+				if length := a.length(layout); length > 0 {
+					step = min(n, length-1)
+				}
+				frame = a.frame(frame, step)
+			}
+			return frame
+		},
+	}
+}
+
+// Length returns the frames in one pass of the animation over layout, or
+// 0 if the animation never ends.
+//
+// Parameters:
+//   - layout: the banner being animated
+//
+// Returns:
+//   - int: the frames in a pass, 0 for an endless animation
+//
+// Example:
+//
+//	frames := loops * anim.Length(layout)
+func (a Animation) Length(layout *render.Layout) int {
+	return a.length(layout)
+}
+
+// Endless reports whether the animation never ends, whatever it draws:
+// finite animations last at least one frame even over an empty layout.
+//
+// Returns:
+//   - bool: true if passes of the animation have no end
+//
+// Example:
+//
+//	if anim.Endless() { /* stop on a timeout instead */ }
+func (a Animation) Endless() bool {
+	return a.length(&render.Layout{}) == 0
+}
+
+// Frame returns what frame n of a pass over layout draws. Layout is not
+// modified.
+//
+// Parameters:
+//   - layout: the banner being animated
+//   - n: the frame of the pass, counting from 0
+//
+// Returns:
+//   - *render.Layout: the frame
+//
+// Example:
+//
+//	frame := anim.Frame(layout, n%anim.Length(layout))
+func (a Animation) Frame(layout *render.Layout, n int) *render.Layout {
+	return a.frame(layout, n)
+}
+
+// typewriter draws the characters of the text up to n, one more each frame.
+// Rows keep their width, so layers after it see the whole banner.
+func typewriter(layout *render.Layout, n int) *render.Layout {
+	out := *layout
+	out.Rows = make([]string, len(layout.Rows))
+	for row, line := range layout.RowLines {
+		cells := render.Cells(layout.Rows[row])
+		for _, span := range layout.Spans[line] {
+			if span.Index <= n {
+				continue
+			}
+			for col := span.Start; col < span.End && col < len(cells); col++ {
+				cells[col] = " "
+			}
+		}
+		out.Rows[row] = render.JoinCells(cells)
+	}
+	return &out
+}
+
+// scroll slides the banner in from the right edge and out past the left,
+// n columns along, within a window as wide as the banner.
+func scroll(layout *render.Layout, n int) *render.Layout {
+	width := render.LayoutWidth(layout)
+	offset := n - width
+
+	out := *layout
+	out.Rows = make([]string, len(layout.Rows))
+	for row, text := range layout.Rows {
+		cells := render.Cells(text)
+		line := make([]string, width)
+		for col := range line {
+			line[col] = " "
+			if src := col + offset; src >= 0 && src < len(cells) {
+				line[col] = cells[src]
+			}
+		}
+		out.Rows[row] = strings.TrimRight(render.JoinCells(line), " ")
+	}
+	out.Spans = make([][]render.GlyphSpan, len(layout.Spans))
+	for line, spans := range layout.Spans {
+		for _, span := range spans {
+			span.Start -= offset
+			span.End -= offset
+			out.Spans[line] = append(out.Spans[line], span)
+		}
+	}
+	return &out
+}
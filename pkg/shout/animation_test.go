@@ -0,0 +1,164 @@
+package shout
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// testLayout is a two-glyph layout: "A" in columns 0-1 and "B" in 2-3.
+func testLayout() *render.Layout {
+	return &render.Layout{
+		Rows:     []string{"/\\|)", "/\\|)"},
+		RowLines: []int{0, 0},
+		Spans:    [][]render.GlyphSpan{{{Index: 0, Start: 0, End: 2}, {Index: 1, Start: 2, End: 4}}},
+		Text:     []rune("AB"),
+	}
+}
+
+func TestTypewriter(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{n: 0, want: []string{"/\\  ", "/\\  "}},
+		{n: 1, want: []string{"/\\|)", "/\\|)"}},
+		{n: 5, want: []string{"/\\|)", "/\\|)"}},
+	}
+
+	for _, tt := range tests {
+		layout := testLayout()
+		if got := typewriter(layout, tt.n).Rows; !slices.Equal(got, tt.want) {
+			t.Errorf("typewriter(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+		if layout.Rows[0] != "/\\|)" {
+			t.Fatal("typewriter changed the layout")
+		}
+	}
+	if got := animations["typewriter"].Length(testLayout()); got != 2+TypewriterHold {
+		t.Errorf("typewriter length = %d, want %d", got, 2+TypewriterHold)
+	}
+}
+
+func TestScroll(t *testing.T) {
+	tests := []struct {
+		n         int
+		want      string
+		wantStart int
+	}{
+		{n: 0, want: "", wantStart: 4},
+		{n: 1, want: "   /", wantStart: 3},
+		{n: 4, want: "/\\|)", wantStart: 0},
+		{n: 6, want: "|)", wantStart: -2},
+		{n: 7, want: ")", wantStart: -3},
+	}
+
+	for _, tt := range tests {
+		frame := scroll(testLayout(), tt.n)
+		if frame.Rows[0] != tt.want {
+			t.Errorf("scroll(%d) = %q, want %q", tt.n, frame.Rows[0], tt.want)
+		}
+		if got := frame.Spans[0][0].Start; got != tt.wantStart {
+			t.Errorf("scroll(%d) first glyph starts at %d, want %d", tt.n, got, tt.wantStart)
+		}
+	}
+	if got := animations["scroll"].Length(testLayout()); got != 8 {
+		t.Errorf("scroll length = %d, want 8", got)
+	}
+}
+
+func TestScrollWide(t *testing.T) {
+	// "日" glyphs take both columns of their span
+	layout := &render.Layout{
+		Rows:     []string{"/\\日"},
+		RowLines: []int{0},
+		Spans:    [][]render.GlyphSpan{{{Index: 0, Start: 0, End: 2}, {Index: 1, Start: 2, End: 4}}},
+		Text:     []rune("A日"),
+	}
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{n: 3, want: " /\\"},
+		{n: 4, want: "/\\日"},
+		{n: 6, want: "日"},
+		// Half a wide character is drawn as a space
+		{n: 7, want: ""},
+	}
+	for _, tt := range tests {
+		if got := scroll(layout, tt.n).Rows[0]; got != tt.want {
+			t.Errorf("scroll(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+	if got := typewriter(layout, 0).Rows[0]; got != "/\\  " {
+		t.Errorf("typewriter(0) = %q, want the wide glyph blanked to two spaces", got)
+	}
+}
+
+func TestParseAnimation(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantLength int
+		wantPreset string
+		wantErr    bool
+	}{
+		{spec: "", wantLength: 0},
+		{spec: "typewriter", wantLength: 2 + TypewriterHold},
+		{spec: "scroll+rainbow", wantLength: 8, wantPreset: "rainbow"},
+		{spec: "Rainbow Scroll", wantLength: 8, wantPreset: "rainbow"},
+		{spec: "fire", wantLength: 0, wantPreset: "fire"},
+		{spec: "typewriter+scroll", wantLength: 8},
+		{spec: "colors+typewriter", wantLength: 2 + TypewriterHold},
+		{spec: "scroll+scroll", wantErr: true},
+		{spec: "rainbow+fire", wantErr: true},
+		{spec: "spin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			anim, preset, err := ParseAnimation(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAnimation(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := anim.Length(testLayout()); got != tt.wantLength {
+				t.Errorf("length = %d, want %d", got, tt.wantLength)
+			}
+			if got := anim.Endless(); got != (tt.wantLength == 0) {
+				t.Errorf("Endless() = %v, want %v", got, tt.wantLength == 0)
+			}
+			if preset != tt.wantPreset {
+				t.Errorf("preset = %q, want %q", preset, tt.wantPreset)
+			}
+		})
+	}
+}
+
+func TestLayeredAnimation(t *testing.T) {
+	// Either order types while scrolling
+	for _, spec := range []string{"typewriter+scroll", "scroll+typewriter"} {
+		anim, _, err := ParseAnimation(spec)
+		if err != nil {
+			t.Fatalf("ParseAnimation(%q) error = %v", spec, err)
+		}
+		tests := []struct {
+			n    int
+			want string
+		}{
+			{n: 2, want: "  /\\"},
+			{n: 4, want: "/\\|)"},
+			// typewriter has finished and holds its last frame
+			{n: 7, want: ")"},
+		}
+		for _, tt := range tests {
+			if got := strings.TrimRight(anim.Frame(testLayout(), tt.n).Rows[0], " "); got != tt.want {
+				t.Errorf("%s frame %d = %q, want %q", spec, tt.n, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package shout
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// seedStream is the PCG stream used with seeds.
+const seedStream = 0x5eed
+
+// SpeedIntervals maps speeds 1-10 to the time between animation frames,
+// from one frame a second at speed 1 to 20 a second at speed 10. Each step
+// is roughly 1.4 times faster than the last, so the speeds feel evenly
+// spaced.
+var SpeedIntervals = [10]time.Duration{
+	1000 * time.Millisecond,
+	700 * time.Millisecond,
+	500 * time.Millisecond,
+	350 * time.Millisecond,
+	250 * time.Millisecond,
+	180 * time.Millisecond,
+	130 * time.Millisecond,
+	100 * time.Millisecond,
+	70 * time.Millisecond,
+	50 * time.Millisecond,
+}
+
+// ParseSeed reads a seed as the service's seed option does: a number is
+// used as is and anything else is hashed.
+//
+// Parameters:
+//   - raw: the seed option
+//
+// Returns:
+//   - uint64: the seed
+//   - bool: false for an empty value
+//
+// Example:
+//
+//	seed, ok := shout.ParseSeed("deploy-42")
+func ParseSeed(raw string) (uint64, bool) {
+	if raw == "" {
+		return 0, false
+	}
+	if seed, err := strconv.ParseUint(raw, 10, 64); err == nil {
+		return seed, true
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(raw))
+	return hash.Sum64(), true
+}
+
+// NewRand returns the random source for seed. The service makes every
+// random choice of a request, such as font=random, from this source, so
+// the same seed makes the same choices here.
+//
+// Parameters:
+//   - seed: the seed, as from ParseSeed
+//
+// Returns:
+//   - *rand.Rand: the random source
+//
+// Example:
+//
+//	font := fonts[shout.NewRand(seed).IntN(len(fonts))]
+func NewRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seedStream))
+}
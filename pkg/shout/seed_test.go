@@ -0,0 +1,39 @@
+package shout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSeed(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   uint64
+		wantOK bool
+	}{
+		{raw: "", wantOK: false},
+		{raw: "0", want: 0, wantOK: true},
+		{raw: "42", want: 42, wantOK: true},
+		{raw: "18446744073709551615", want: 1<<64 - 1, wantOK: true},
+		// FNV-1a of "a"
+		{raw: "a", want: 0xaf63dc4c8601ec8c, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseSeed(tt.raw)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseSeed(%q) = %d, %v; want %d, %v", tt.raw, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSpeedIntervals(t *testing.T) {
+	if SpeedIntervals[0] != time.Second || SpeedIntervals[9] != 50*time.Millisecond {
+		t.Errorf("speeds run from %v to %v, want 1s to 50ms", SpeedIntervals[0], SpeedIntervals[9])
+	}
+	for i := 1; i < len(SpeedIntervals); i++ {
+		if SpeedIntervals[i] >= SpeedIntervals[i-1] {
+			t.Errorf("speed %d (%v) isn't faster than speed %d (%v)", i+1, SpeedIntervals[i], i, SpeedIntervals[i-1])
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// Package shout renders shout.sh banners for Go programs, without the HTTP
+// server: Render draws a static banner and Stream plays an animated one to
+// a writer, with the same fonts, colors and animations as the service.
+//
+// Usage example:
+//
+//	banner, err := shout.Render(ctx, "HELLO", shout.Options{Font: "doom", Color: "fire"})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(banner)
+//
+//	err = shout.Stream(ctx, os.Stdout, "DEPLOYED", shout.Options{Animation: "scroll+rainbow", Loops: 1})
+package shout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// ErrNoFonts is returned when no font is loaded to render with.
+var ErrNoFonts = errors.New("shout: no fonts loaded")
+
+// OptionError reports an invalid option, such as an unknown color.
+type OptionError struct {
+	// Option is the name of the Options field
+	Option string
+	// Err describes what is wrong with it
+	Err error
+}
+
+// Error implements the error interface.
+func (e *OptionError) Error() string {
+	return "shout: " + e.Option + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// Options control how a banner is drawn. The zero value draws in the
+// default font, uncolored, without wrapping.
+type Options struct {
+	// Font names the font; "" is render.DefaultFont, and a misspelled
+	// name uses the closest match
+	Font string
+	// Engine is what draws the text: "figlet", the default, with Font,
+	// or "block" or "braille" with the built-in pixel font (see
+	// render.BlockFont and render.BrailleFont)
+	Engine string
+	// Scale is the size of the pixel font's pixels; 0 is 1 for block and
+	// render.BrailleScale for braille
+	Scale int
+	// Fill is a character to draw every glyph with, or "text" to draw each
+	// with its own letter (see render.FillLayout); "" draws them as the
+	// font does
+	Fill string
+	// Hardblank is a character to draw the font's hardblanks with; ""
+	// draws them as spaces
+	Hardblank string
+	// Color is a preset, #RRGGBB, rgb(r,g,b), a color name, or a
+	// comma-separated list; "" draws uncolored, or in the rainbow for
+	// streams
+	Color string
+	// ColorMode is how a color list cycles: char, word or line
+	ColorMode string
+	// GradientDir is the direction of a gradient: h, v, d1, d2 or radial;
+	// "" is h
+	GradientDir string
+	// Scheme, when set, colors the banner instead of Color, ColorMode and
+	// any preset in Animation
+	Scheme render.ColorScheme
+	// Align places each line within Cols: left, center or right; "" is
+	// left
+	Align string
+	// Border draws a box around static banners: single, double or
+	// rounded; "" and none draw none
+	Border string
+	// Padding is the space between a static banner and its border or
+	// background: Padding lines above and below, and twice as many
+	// columns either side
+	Padding int
+	// Accent colors the border, and Background fills the box behind the
+	// banner, each a #RRGGBB, rgb(r,g,b) or color name; "" for none
+	Accent, Background string
+	// Footer is a line of plain text drawn below a static banner and its
+	// box, aligned as the banner is (see render.AppendSection); "" for
+	// none
+	Footer string
+
+	// Cols and Rows are the size of the terminal drawn in, 0 if unknown.
+	// Text wraps to fit Cols, and stream frames are cropped to both
+	Cols, Rows int
+	// Colors is the color depth of the terminal; the zero value is
+	// 24-bit color
+	Colors render.ColorDepth
+	// Masked are the word ranges to draw masked (see render.MaskLayout)
+	Masked []render.WordRange
+
+	// Animation is an animation spec for streams (see ParseAnimation)
+	Animation string
+	// Interval is the time between stream frames; 0 is DefaultInterval
+	Interval time.Duration
+	// Loops is how many passes of a finite animation a stream plays; 0
+	// repeats until Duration
+	Loops int
+	// Duration ends a stream after this long; 0 with no Loops streams
+	// until the context is done
+	Duration time.Duration
+	// Phase is the frame the colors of a stream start at
+	Phase int
+}
+
+// DefaultInterval is the time between stream frames when Options.Interval
+// is 0: four frames a second, as at the service's default speed.
+const DefaultInterval = 250 * time.Millisecond
+
+// Renderer draws banners with a set of fonts. It is safe for concurrent
+// use.
+//
+// Usage example:
+//
+//	r := shout.New(cache)
+//	banner, err := r.Render(ctx, "HELLO", shout.Options{Font: "small"})
+type Renderer struct {
+	fonts *render.FontCache
+}
+
+// New creates a Renderer that draws with fonts.
+//
+// Parameters:
+//   - fonts: the loaded fonts
+//
+// Returns:
+//   - *Renderer: a renderer using fonts
+func New(fonts *render.FontCache) *Renderer {
+	return &Renderer{fonts: fonts}
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRenderer *Renderer
+	defaultErr      error
+)
+
+// Default returns the Renderer behind the package-level Render and Stream,
+// which draws with the fonts bundled in the module. The fonts are loaded
+// on first use.
+//
+// Returns:
+//   - *Renderer: the shared renderer
+//   - error: error if the bundled fonts cannot be loaded
+func Default() (*Renderer, error) {
+	defaultOnce.Do(func() {
+		names, err := fs.Glob(render.EmbeddedFonts, "*.flf")
+		if err != nil {
+			defaultErr = err
+			return
+		}
+		cfg := config.FontConfig{Embedded: true}
+		for _, name := range names {
+			cfg.Allowed = append(cfg.Allowed, strings.TrimSuffix(name, ".flf"))
+		}
+		fonts := render.NewFontCache()
+		if defaultErr = fonts.LoadFonts(cfg); defaultErr == nil {
+			defaultRenderer = New(fonts)
+		}
+	})
+	return defaultRenderer, defaultErr
+}
+
+// Render draws text as a static banner with the bundled fonts (see
+// Renderer.Render).
+//
+// Parameters:
+//   - ctx: bounds how long rendering may take
+//   - text: the text to draw
+//   - opts: how to draw it
+//
+// Returns:
+//   - string: the banner, ending in a newline
+//   - error: error if an option is invalid or rendering fails
+//
+// Example:
+//
+//	banner, err := shout.Render(ctx, "HELLO", shout.Options{Color: "fire"})
+func Render(ctx context.Context, text string, opts Options) (string, error) {
+	r, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return r.Render(ctx, text, opts)
+}
+
+// Render draws text as a static banner: wrapped to opts.Cols, aligned
+// within it, colored, drawn in the box of opts.Border, Padding and
+// Background, with opts.Footer below, and reduced to the colors of the
+// terminal.
+//
+// Parameters:
+//   - ctx: bounds how long rendering may take
+//   - text: the text to draw
+//   - opts: how to draw it
+//
+// Returns:
+//   - string: the banner, ending in a newline
+//   - error: an *OptionError for an invalid option, ErrNoFonts, or an
+//     error wrapping ctx.Err() if ctx is done first
+//
+// Example:
+//
+//	banner, err := r.Render(ctx, "HELLO WORLD", shout.Options{Cols: 80, Align: "center"})
+func (r *Renderer) Render(ctx context.Context, text string, opts Options) (string, error) {
+	scheme, err := opts.scheme("")
+	if err != nil {
+		return "", err
+	}
+	frame, err := opts.frame()
+	if err != nil {
+		return "", err
+	}
+	// A framed banner is wrapped to fit inside the box, and its lines
+	// aligned within the box rather than the terminal
+	inner := opts
+	if frame.Drawn() {
+		if inner.Cols > 0 {
+			inner.Cols = max(inner.Cols-frame.Extra(), 1)
+		}
+		inner.Align = ""
+	}
+	layout, err := r.layout(ctx, text, inner)
+	if err != nil || layout == nil {
+		return "", err
+	}
+	if frame.Drawn() {
+		layout = render.AlignLayout(layout, render.LayoutWidth(layout), opts.Align)
+	}
+	output := render.FrameOutput(render.ColorizeLayout(layout, scheme, 0), frame)
+	output = render.AppendSection(output, opts.Footer, opts.Align)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return render.ReduceColors(output, opts.Colors), nil
+}
+
+// layout renders text in the font of opts, wrapped to opts.Cols, masked
+// and aligned. It returns nil for empty text.
+func (r *Renderer) layout(ctx context.Context, text string, opts Options) (*render.Layout, error) {
+	if opts.Engine != "" && !slices.Contains(types.Engines, opts.Engine) {
+		return nil, &OptionError{Option: "Engine", Err: fmt.Errorf("unknown engine %q", opts.Engine)}
+	}
+	renderOpts := types.RenderOptions{Font: opts.Font, Engine: opts.Engine, Scale: opts.Scale, Fill: opts.Fill, Hardblank: opts.Hardblank}
+	font := r.fonts.EngineFont(renderOpts, render.DefaultFont)
+	if font == nil {
+		return nil, ErrNoFonts
+	}
+	renderOpts.Font = font.Name
+	// Wrapping only turns spaces into line breaks, so the masked word
+	// ranges still line up
+	text, err := render.WrapText(ctx, font, text, opts.Cols)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := render.GenerateLayoutContext(ctx, text, renderOpts, r.fonts)
+	if err != nil || layout == nil {
+		return nil, err
+	}
+	render.MaskLayout(layout, opts.Masked)
+	return render.AlignLayout(layout, opts.Cols, opts.Align), nil
+}
+
+// scheme returns the color scheme of opts: Scheme, or Color parsed with
+// ColorMode and GradientDir, or fallback when both are empty.
+func (opts Options) scheme(fallback string) (render.ColorScheme, error) {
+	if opts.Scheme != nil {
+		return opts.Scheme, nil
+	}
+	color := opts.Color
+	if color == "" {
+		color = fallback
+	}
+	scheme, err := render.ParseColor(color)
+	if err != nil {
+		return nil, &OptionError{Option: "Color", Err: err}
+	}
+	mode, err := render.ParseColorMode(opts.ColorMode)
+	if err != nil {
+		return nil, &OptionError{Option: "ColorMode", Err: err}
+	}
+	if list, ok := scheme.(*render.ColorList); ok {
+		list.Mode = mode
+	}
+	dir, err := render.ParseGradientDirection(opts.GradientDir)
+	if err != nil {
+		return nil, &OptionError{Option: "GradientDir", Err: err}
+	}
+	if gradient, ok := scheme.(*render.Gradient); ok {
+		gradient.Direction = dir
+	}
+	return scheme, nil
+}
+
+// frame returns the box of opts: Border, Padding, Accent and Background,
+// aligned within Cols.
+func (opts Options) frame() (render.Frame, error) {
+	if opts.Border != "" && !slices.Contains(types.Borders, opts.Border) {
+		return render.Frame{}, &OptionError{Option: "Border", Err: fmt.Errorf("unknown border %q", opts.Border)}
+	}
+	if opts.Padding < 0 || opts.Padding > types.MaxPadding {
+		return render.Frame{}, &OptionError{Option: "Padding", Err: fmt.Errorf("padding must be between 0 and %d, got %d", types.MaxPadding, opts.Padding)}
+	}
+	frame := render.Frame{Border: opts.Border, Padding: opts.Padding, Width: opts.Cols, Align: opts.Align}
+	for _, color := range []struct {
+		option, value string
+		dst           **render.Color
+	}{
+		{"Accent", opts.Accent, &frame.Accent},
+		{"Background", opts.Background, &frame.Background},
+	} {
+		if color.value == "" {
+			continue
+		}
+		c, err := render.ParseSolidColor(color.value)
+		if err != nil {
+			return render.Frame{}, &OptionError{Option: color.option, Err: err}
+		}
+		*color.dst = &c
+	}
+	return frame, nil
+}
+
+// interval returns the time between stream frames.
+func (opts Options) interval() time.Duration {
+	if opts.Interval > 0 {
+		return opts.Interval
+	}
+	return DefaultInterval
+}
@@ -0,0 +1,230 @@
+package shout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/render"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// defaultFonts returns the fonts of the default renderer.
+func defaultFonts(t *testing.T) *render.FontCache {
+	t.Helper()
+	r, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	return r.fonts
+}
+
+func TestRender(t *testing.T) {
+	ctx := context.Background()
+	plain, err := render.GenerateASCII("HI", types.RenderOptions{Font: "small"}, defaultFonts(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		opts    Options
+		want    string
+		check   func(t *testing.T, got string)
+		wantErr bool
+	}{
+		{name: "plain", text: "HI", opts: Options{Font: "small"}, want: plain},
+		{name: "misspelled font", text: "HI", opts: Options{Font: "smal"}, want: plain},
+		{
+			name: "colored",
+			text: "HI",
+			opts: Options{Font: "small", Color: "fire"},
+			check: func(t *testing.T, got string) {
+				if !strings.Contains(got, "\x1b[38;2;") {
+					t.Errorf("output has no 24-bit colors: %q", got)
+				}
+			},
+		},
+		{
+			name: "reduced colors",
+			text: "HI",
+			opts: Options{Font: "small", Color: "fire", Colors: render.NoColor},
+			want: plain,
+		},
+		{
+			name: "wrapped and centered",
+			text: "HI HI",
+			opts: Options{Font: "small", Cols: 20, Align: "center"},
+			check: func(t *testing.T, got string) {
+				lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+				if len(lines) <= len(strings.Split(strings.TrimRight(plain, "\n"), "\n")) {
+					t.Errorf("text didn't wrap:\n%s", got)
+				}
+				if !strings.HasPrefix(lines[0], "  ") {
+					t.Errorf("text isn't centered:\n%s", got)
+				}
+			},
+		},
+		{
+			name: "block engine",
+			text: "HI",
+			opts: Options{Font: "small", Engine: "block", Scale: 2},
+			check: func(t *testing.T, got string) {
+				if lines := strings.Split(strings.TrimRight(got, "\n"), "\n"); len(lines) != 7 || !strings.Contains(got, "█") {
+					t.Errorf("block banner at scale 2 =\n%s\nwant 7 lines of blocks", got)
+				}
+			},
+		},
+		{
+			name: "framed",
+			text: "HI HI",
+			opts: Options{Font: "small", Cols: 30, Align: "center", Border: "rounded", Padding: 1},
+			check: func(t *testing.T, got string) {
+				lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+				if !strings.HasPrefix(strings.TrimLeft(lines[0], " "), "╭") || !strings.HasPrefix(strings.TrimLeft(lines[len(lines)-1], " "), "╰") {
+					t.Errorf("expected a rounded box:\n%s", got)
+				}
+				// The box, padding included, fits within Cols
+				for _, line := range lines {
+					if width := render.StringWidth(line); width > 30 {
+						t.Errorf("line %q is %d columns wide, want at most 30", line, width)
+					}
+				}
+			},
+		},
+		{name: "footer", text: "HI", opts: Options{Font: "small", Footer: "by shout.sh"}, want: plain + " by shout.sh\n"},
+		{name: "empty", text: "", want: ""},
+		{name: "bad color", text: "HI", opts: Options{Color: "nope"}, wantErr: true},
+		{name: "bad color mode", text: "HI", opts: Options{Color: "red,blue", ColorMode: "nope"}, wantErr: true},
+		{name: "bad engine", text: "HI", opts: Options{Engine: "nope"}, wantErr: true},
+		{name: "bad border", text: "HI", opts: Options{Border: "wavy"}, wantErr: true},
+		{name: "bad background", text: "HI", opts: Options{Background: "nope"}, wantErr: true},
+		{name: "bad padding", text: "HI", opts: Options{Padding: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(ctx, tt.text, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var optErr *OptionError
+				if !errors.As(err, &optErr) {
+					t.Errorf("Render() error = %T, want *OptionError", err)
+				}
+				return
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			} else if got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Render(ctx, "HI", Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Render() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantFrames int
+	}{
+		{name: "loops", opts: Options{Animation: "typewriter", Loops: 2}, wantFrames: 2 * (2 + TypewriterHold)},
+		{name: "duration", opts: Options{Interval: time.Second, Duration: 3 * time.Second}, wantFrames: 3},
+		{name: "duration cuts loops short", opts: Options{Animation: "typewriter", Loops: 5, Interval: time.Second, Duration: 2 * time.Second}, wantFrames: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Font = "small"
+			frames, err := Frames(ctx, "HI", tt.opts)
+			if err != nil {
+				t.Fatalf("Frames() error = %v", err)
+			}
+			n := 0
+			for last := false; !last && n < 100; n++ {
+				_, last = frames(n)
+			}
+			if n != tt.wantFrames {
+				t.Errorf("stream ended after %d frames, want %d", n, tt.wantFrames)
+			}
+		})
+	}
+
+	if _, err := Frames(ctx, "HI", Options{Animation: "spin"}); err == nil {
+		t.Error("unknown animation: want an error")
+	}
+	if _, err := Frames(ctx, "HI", Options{Animation: "fire", Color: "red"}); err == nil {
+		t.Error("preset and color: want an error")
+	}
+
+	// Frames stay inside the terminal
+	frames, err := Frames(ctx, "HELLO", Options{Font: "small", Animation: "scroll", Cols: 10, Rows: 2, Colors: render.NoColor})
+	if err != nil {
+		t.Fatalf("Frames() error = %v", err)
+	}
+	for n := range 20 {
+		frame, _ := frames(n)
+		lines := strings.Split(strings.TrimRight(frame, "\n"), "\n")
+		if len(lines) > 2 {
+			t.Fatalf("frame %d has %d lines, want at most 2", n, len(lines))
+		}
+		for _, line := range lines {
+			if len([]rune(line)) > 10 {
+				t.Fatalf("frame %d line is wider than 10 columns: %q", n, line)
+			}
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	var out bytes.Buffer
+	opts := Options{Font: "small", Animation: "typewriter", Loops: 1, Interval: time.Millisecond, Colors: render.NoColor}
+	if err := Stream(context.Background(), &out, "HI", opts); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, ansiHideCursor+ansiClearScreen) {
+		t.Errorf("stream doesn't start by hiding the cursor: %q", got[:min(len(got), 20)])
+	}
+	if !strings.HasSuffix(got, render.AnsiReset+ansiShowCursor) {
+		t.Errorf("stream doesn't end by showing the cursor: %q", got[max(len(got)-20, 0):])
+	}
+	if n := strings.Count(got, ansiHome); n != 2+TypewriterHold {
+		t.Errorf("stream drew %d frames, want %d", n, 2+TypewriterHold)
+	}
+
+	// An endless stream ends with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	out.Reset()
+	if err := Stream(ctx, &out, "HI", Options{Interval: time.Millisecond}); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if !strings.HasSuffix(out.String(), ansiShowCursor) {
+		t.Error("cancelled stream doesn't restore the cursor")
+	}
+}
+
+func TestRedraw(t *testing.T) {
+	got := Redraw("AB  \nC\n")
+	want := ansiHome + "AB" + ansiClearLine + "\nC" + ansiClearLine + "\n" + ansiClearBelow
+	if got != want {
+		t.Errorf("Redraw = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,211 @@
+package shout
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// defaultStreamColor colors streams when no color is given.
+const defaultStreamColor = "rainbow"
+
+// Terminal control sequences used to redraw frames in place.
+const (
+	ansiClearScreen = "\x1b[2J"
+	ansiHome        = "\x1b[H"
+	ansiClearLine   = "\x1b[K"
+	ansiClearBelow  = "\x1b[J"
+	ansiHideCursor  = "\x1b[?25l"
+	ansiShowCursor  = "\x1b[?25h"
+)
+
+// FrameFunc returns frame n of a stream, counting from 0, and whether it
+// is the last frame. Frame n doesn't depend on earlier frames, so a slow
+// reader may skip frames.
+type FrameFunc func(n int) (frame string, last bool)
+
+// Frames renders text for a stream with the bundled fonts (see
+// Renderer.Frames).
+//
+// Parameters:
+//   - ctx: bounds how long rendering the banner may take
+//   - text: the text to animate
+//   - opts: how to draw and animate it
+//
+// Returns:
+//   - FrameFunc: the frames of the stream
+//   - error: error if an option is invalid or rendering fails
+//
+// Example:
+//
+//	frames, err := shout.Frames(ctx, "HI", shout.Options{Animation: "typewriter", Loops: 1})
+func Frames(ctx context.Context, text string, opts Options) (FrameFunc, error) {
+	r, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return r.Frames(ctx, text, opts)
+}
+
+// Frames renders text once and returns the frames of its animation,
+// each colored and cropped to opts.Cols and opts.Rows. The banner wraps,
+// aligns and moves within opts.Cols. The stream ends after opts.Loops
+// passes of a finite animation or after opts.Duration, whichever comes
+// first; with neither, frames never end. Colors start at opts.Phase and
+// default to the rainbow.
+//
+// Frames draw over each other in place, so a terminal stream should pass
+// one less than the terminal's size: the last column and row stay empty,
+// and redrawing never wraps a line or scrolls the screen.
+//
+// Parameters:
+//   - ctx: bounds how long rendering the banner may take
+//   - text: the text to animate
+//   - opts: how to draw and animate it
+//
+// Returns:
+//   - FrameFunc: the frames of the stream
+//   - error: an *OptionError for an invalid option, ErrNoFonts, or an
+//     error wrapping ctx.Err() if ctx is done first
+//
+// Example:
+//
+//	frames, err := r.Frames(ctx, "HI", shout.Options{Animation: "scroll", Cols: 79, Rows: 23})
+//	frame, last := frames(0)
+func (r *Renderer) Frames(ctx context.Context, text string, opts Options) (FrameFunc, error) {
+	anim, preset, err := ParseAnimation(opts.Animation)
+	if err != nil {
+		return nil, &OptionError{Option: "Animation", Err: err}
+	}
+	switch {
+	case preset != "" && opts.Scheme == nil && opts.Color != "":
+		return nil, &OptionError{Option: "Animation", Err: fmt.Errorf("preset %s and color %s both set the colors", preset, opts.Color)}
+	case preset != "":
+		opts.Color = preset
+	}
+	scheme, err := opts.scheme(defaultStreamColor)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := r.layout(ctx, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	if layout == nil {
+		layout = &render.Layout{}
+	}
+	layout = render.PadLayout(layout, opts.Cols)
+
+	length, frames := anim.Length(layout), 0
+	if length > 0 {
+		frames = opts.Loops * length
+	}
+	interval := opts.interval()
+	return func(n int) (string, bool) {
+		step := n
+		if length > 0 {
+			step = n % length
+		}
+		frame := render.CropLayout(anim.Frame(layout, step), opts.Cols, opts.Rows)
+		last := frames > 0 && n+1 >= frames || opts.Duration > 0 && time.Duration(n+1)*interval >= opts.Duration
+		output := render.ColorizeLayout(frame, scheme, opts.Phase+n)
+		return render.ReduceColors(output, opts.Colors), last
+	}, nil
+}
+
+// Stream plays text to w with the bundled fonts (see Renderer.Stream).
+//
+// Parameters:
+//   - ctx: ends the stream when done
+//   - w: the terminal to draw on
+//   - text: the text to animate
+//   - opts: how to draw and animate it
+//
+// Returns:
+//   - error: error if an option is invalid, rendering fails or w fails
+//
+// Example:
+//
+//	err := shout.Stream(ctx, os.Stdout, "DEPLOYED", shout.Options{Duration: 5 * time.Second})
+func Stream(ctx context.Context, w io.Writer, text string, opts Options) error {
+	r, err := Default()
+	if err != nil {
+		return err
+	}
+	return r.Stream(ctx, w, text, opts)
+}
+
+// Stream plays the frames of text (see Frames) to w, one every
+// opts.Interval, each redrawn over the last. The cursor is hidden while
+// the stream plays, and colors are reset and the cursor shown when it
+// ends, including when ctx is done, which ends the stream without error.
+//
+// Parameters:
+//   - ctx: ends the stream when done
+//   - w: the terminal to draw on
+//   - text: the text to animate
+//   - opts: how to draw and animate it
+//
+// Returns:
+//   - error: error if an option is invalid, rendering fails or w fails
+//
+// Example:
+//
+//	err := r.Stream(ctx, os.Stdout, "HI", shout.Options{Animation: "typewriter", Loops: 2})
+func (r *Renderer) Stream(ctx context.Context, w io.Writer, text string, opts Options) error {
+	frames, err := r.Frames(ctx, text, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, ansiHideCursor+ansiClearScreen); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(opts.interval())
+	defer ticker.Stop()
+play:
+	for n := 0; ; n++ {
+		frame, last := frames(n)
+		if _, err := io.WriteString(w, Redraw(frame)); err != nil {
+			return err
+		}
+		if last {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break play
+		case <-ticker.C:
+		}
+	}
+	_, err = io.WriteString(w, render.AnsiReset+ansiShowCursor)
+	return err
+}
+
+// Redraw returns the bytes that draw frame over the previous one: the
+// cursor moves home, each line clears what's left of the old frame to its
+// right, and anything below the new frame is cleared.
+//
+// Parameters:
+//   - frame: the frame to draw, as lines ending in newlines
+//
+// Returns:
+//   - string: the frame with the control sequences to draw it in place
+//
+// Example:
+//
+//	io.WriteString(os.Stdout, shout.Redraw(frame))
+func Redraw(frame string) string {
+	lines := strings.Split(strings.TrimRight(frame, "\n"), "\n")
+	for i, line := range lines {
+		// Clearing the line blanks the rest of it anyway, and trailing
+		// spaces could wrap in a terminal narrower than the frame
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return ansiHome + strings.Join(lines, ansiClearLine+"\n") + ansiClearLine + "\n" + ansiClearBelow
+}
@@ -0,0 +1,134 @@
+package quota
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// bucketWidth is the granularity of the rolling window: usage leaves the
+// window an hour's worth at a time.
+const bucketWidth = time.Hour
+
+// MemoryStore keeps usage in process memory, in hourly buckets per
+// client. Usage is lost on restart.
+//
+// Usage example:
+//
+//	store := NewMemoryStore()
+//	err := store.Add(ctx, "ip:203.0.113.7", 1, 0)
+type MemoryStore struct {
+	mu      sync.Mutex
+	clients map[string][]bucket
+	// swept is when expired buckets were last dropped from every client
+	swept time.Time
+	now   func() time.Time
+}
+
+// bucket is the usage of one client in the hour starting at start.
+type bucket struct {
+	start         time.Time
+	requests      int64
+	streamSeconds int64
+}
+
+// NewMemoryStore creates an empty in-memory store.
+//
+// Returns:
+//   - *MemoryStore: an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{clients: make(map[string][]bucket), now: time.Now}
+}
+
+// Add records requests and streamed seconds for client in the current
+// hour's bucket. Clients idle for a whole window are dropped.
+func (m *MemoryStore) Add(_ context.Context, client string, requests, streamSeconds int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.swept) >= bucketWidth {
+		for id := range m.clients {
+			m.expireLocked(id, now)
+		}
+		m.swept = now
+	}
+
+	buckets := m.expireLocked(client, now)
+	start := now.Truncate(bucketWidth)
+	if n := len(buckets); n == 0 || !buckets[n-1].start.Equal(start) {
+		buckets = append(buckets, bucket{start: start})
+	}
+	last := &buckets[len(buckets)-1]
+	last.requests += requests
+	last.streamSeconds += streamSeconds
+	m.clients[client] = buckets
+	return nil
+}
+
+// Usage sums client's buckets in the last Window.
+func (m *MemoryStore) Usage(_ context.Context, client string) (Usage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var usage Usage
+	buckets := m.expireLocked(client, m.now())
+	for _, b := range buckets {
+		usage.Requests += b.requests
+		usage.StreamSeconds += b.streamSeconds
+	}
+	if len(buckets) > 0 {
+		usage.Reset = buckets[0].start.Add(Window)
+	}
+	return usage, nil
+}
+
+// Clients lists the clients with usage in the last Window, sorted.
+func (m *MemoryStore) Clients(_ context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	clients := make([]string, 0, len(m.clients))
+	for id := range m.clients {
+		if len(m.expireLocked(id, now)) > 0 {
+			clients = append(clients, id)
+		}
+	}
+	slices.Sort(clients)
+	return clients, nil
+}
+
+// Reset forgets client's usage.
+func (m *MemoryStore) Reset(_ context.Context, client string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.clients, client)
+	return nil
+}
+
+// Close does nothing; the usage is in memory.
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// expireLocked drops client's buckets that have left the window, and the
+// client once none are left, and returns the buckets still in it. The
+// caller must hold m.mu.
+func (m *MemoryStore) expireLocked(client string, now time.Time) []bucket {
+	buckets := m.clients[client]
+	i := 0
+	for i < len(buckets) && !buckets[i].start.Add(Window).After(now) {
+		i++
+	}
+	if i == len(buckets) {
+		delete(m.clients, client)
+		return nil
+	}
+	if i > 0 {
+		buckets = slices.Clone(buckets[i:])
+		m.clients[client] = buckets
+	}
+	return buckets
+}
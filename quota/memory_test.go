@@ -0,0 +1,58 @@
+package quota
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	if err := store.Add(ctx, "ip:1.2.3.4", 1, 0); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	store.Add(ctx, "ip:1.2.3.4", 2, 30)
+	store.Add(ctx, "key:acme", 1, 0)
+
+	usage, err := store.Usage(ctx, "ip:1.2.3.4")
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	wantReset := time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC)
+	if usage.Requests != 3 || usage.StreamSeconds != 30 || !usage.Reset.Equal(wantReset) {
+		t.Errorf("Usage() = %+v, want 3 requests, 30 seconds, reset %v", usage, wantReset)
+	}
+	if clients, _ := store.Clients(ctx); !slices.Equal(clients, []string{"ip:1.2.3.4", "key:acme"}) {
+		t.Errorf("Clients() = %v", clients)
+	}
+
+	// The first hour leaves the window, the rest stays
+	now = wantReset
+	usage, _ = store.Usage(ctx, "ip:1.2.3.4")
+	if usage.Requests != 2 || usage.StreamSeconds != 30 {
+		t.Errorf("Usage() after the first hour expired = %+v, want 2 requests, 30 seconds", usage)
+	}
+
+	// A whole window later nothing is left
+	now = now.Add(Window)
+	if usage, _ = store.Usage(ctx, "ip:1.2.3.4"); usage != (Usage{}) {
+		t.Errorf("Usage() after the window = %+v, want zero", usage)
+	}
+	if clients, _ := store.Clients(ctx); len(clients) != 0 {
+		t.Errorf("Clients() after the window = %v, want none", clients)
+	}
+
+	store.Add(ctx, "key:acme", 5, 0)
+	if err := store.Reset(ctx, "key:acme"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if usage, _ = store.Usage(ctx, "key:acme"); usage.Requests != 0 {
+		t.Errorf("Usage() after Reset = %+v, want zero", usage)
+	}
+}
@@ -0,0 +1,38 @@
+// Package quota tracks how much each client uses the service over a
+// rolling day: requests made and seconds spent streaming. Limits are set
+// per tier (see apikey) and enforced by the handlers.
+package quota
+
+import (
+	"context"
+	"time"
+)
+
+// Window is how far back usage counts against a quota.
+const Window = 24 * time.Hour
+
+// Usage is what a client used in the last Window.
+type Usage struct {
+	// Requests counts requests made.
+	Requests int64 `json:"requests"`
+	// StreamSeconds counts seconds spent streaming, rounded up per stream.
+	StreamSeconds int64 `json:"stream_seconds"`
+	// Reset is when the oldest of this usage leaves the window, freeing
+	// up room; zero when there is no usage.
+	Reset time.Time `json:"reset,omitzero"`
+}
+
+// Store records usage per client. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Add records requests and streamed seconds for client.
+	Add(ctx context.Context, client string, requests, streamSeconds int64) error
+	// Usage returns what client used in the last Window.
+	Usage(ctx context.Context, client string) (Usage, error)
+	// Clients lists the clients with usage in the last Window.
+	Clients(ctx context.Context) ([]string, error)
+	// Reset forgets client's usage.
+	Reset(ctx context.Context, client string) error
+	// Close releases the store's resources.
+	Close() error
+}
@@ -0,0 +1,142 @@
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// asciiRunes are the ASCII characters drawn for punctuation, spaces and
+// letters that aren't ASCII letters with accents.
+var asciiRunes = map[rune]rune{
+	'\u00a0': ' ', '\u2002': ' ', '\u2003': ' ', '\u2009': ' ', '\u202f': ' ',
+	'‘': '\'', '’': '\'', '‚': '\'', '‛': '\'', '′': '\'',
+	'“': '"', '”': '"', '„': '"', '‟': '"', '″': '"', '«': '"', '»': '"',
+	'‐': '-', '‑': '-', '‒': '-', '–': '-', '—': '-', '―': '-', '−': '-',
+	'…': '.', '·': '.', '•': '*', '×': 'x', '÷': '/',
+	'ß': 's', 'æ': 'a', 'Æ': 'A', 'œ': 'o', 'Œ': 'O', 'ø': 'o', 'Ø': 'O',
+	'đ': 'd', 'Đ': 'D', 'ł': 'l', 'Ł': 'L', 'ı': 'i', 'þ': 'p', 'Þ': 'P',
+}
+
+// asciiRune returns the ASCII character r is written as, and whether it
+// has one.
+func asciiRune(r rune) (rune, bool) {
+	switch {
+	case r < utf8.RuneSelf:
+		return r, true
+	case r >= 0xFF01 && r <= 0xFF5E:
+		// Fullwidth forms of the ASCII characters
+		return r - 0xFEE0, true
+	}
+	if base, ok := baseLetter(r); ok {
+		return base, true
+	}
+	base, ok := asciiRunes[r]
+	return base, ok
+}
+
+// Transliterate writes text in ASCII, for terminals that can't show
+// anything else: letters lose their accents, quotes and dashes become
+// their ASCII forms, and other characters become '?'. Each character is
+// replaced by one, so word ranges (see WordRange) still line up.
+//
+// Parameters:
+//   - text: the text to render
+//
+// Returns:
+//   - string: text in ASCII
+//
+// Example:
+//
+//	Transliterate("Café “olé”") // `Cafe "ole"`
+func Transliterate(text string) string {
+	if ascii(text) {
+		return text
+	}
+	return strings.Map(func(r rune) rune {
+		if base, ok := asciiRune(r); ok {
+			return base
+		}
+		return '?'
+	}, text)
+}
+
+// ASCIIOnly makes rendered output 7-bit ASCII, for serial consoles and
+// terminals that show nothing else: escape sequences are removed, box
+// drawing becomes + - and |, block characters and braille patterns #,
+// and other characters their ASCII forms as in Transliterate, or '?'.
+// Every character keeps its width, so the output stays aligned.
+//
+// Parameters:
+//   - output: rendered output, which may hold ANSI sequences
+//
+// Returns:
+//   - string: the output in printable ASCII, tabs and line breaks
+//
+// Example:
+//
+//	ASCIIOnly("\x1b[31m╔═╗\x1b[0m") // "+-+"
+func ASCIIOnly(output string) string {
+	lines := strings.Split(StripANSI(output), "\n")
+	for i, line := range lines {
+		if ascii(line) {
+			continue
+		}
+		var b strings.Builder
+		eachCluster(line, func(cluster string, width int) {
+			r, _ := utf8.DecodeRuneInString(cluster)
+			switch {
+			case r == '\t' || r == '\r':
+				b.WriteRune(r)
+			case width == 0:
+				// Combining marks, zero width spaces and control characters
+			default:
+				b.WriteByte(asciiCell(r))
+				b.WriteString(strings.Repeat(" ", width-1))
+			}
+		})
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// asciiCell returns the ASCII character a column of output drawn with r
+// shows as.
+func asciiCell(r rune) byte {
+	switch {
+	case r >= 0x2500 && r <= 0x257F:
+		return boxRune(r)
+	case r == '░':
+		return '.'
+	case r == '▒':
+		return ':'
+	case r >= 0x2580 && r <= 0x259F:
+		return '#'
+	case r == 0x2800:
+		return ' '
+	case r > 0x2800 && r <= 0x28FF:
+		return '#'
+	}
+	if base, ok := asciiRune(r); ok && base >= ' ' && base <= '~' {
+		return byte(base)
+	}
+	return '?'
+}
+
+// boxRune returns the ASCII line a box drawing character (U+2500 to
+// U+257F) is drawn as: - for horizontal lines, | for vertical ones, the
+// diagonals as themselves, and + for corners and junctions.
+func boxRune(r rune) byte {
+	switch r {
+	case '─', '━', '┄', '┅', '┈', '┉', '╌', '╍', '═', '╴', '╶', '╸', '╺', '╼', '╾':
+		return '-'
+	case '│', '┃', '┆', '┇', '┊', '┋', '╎', '╏', '║', '╵', '╷', '╹', '╻', '╽', '╿':
+		return '|'
+	case '╱':
+		return '/'
+	case '╲':
+		return '\\'
+	case '╳':
+		return 'X'
+	}
+	return '+'
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "HELLO", want: "HELLO"},
+		{text: "Café Ångström", want: "Cafe Angstrom"},
+		{text: "“quoted” — it’s", want: `"quoted" - it's`},
+		{text: "Straße", want: "Strase"},
+		{text: "ＡＢＣ", want: "ABC"},
+		{text: "I ♥ 日本", want: "I ? ??"},
+		{text: "two\nlines", want: "two\nlines"},
+	}
+
+	for _, tt := range tests {
+		if got := Transliterate(tt.text); got != tt.want {
+			t.Errorf("Transliterate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestASCIIOnly(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "ascii", output: " _  _\n| || |\n", want: " _  _\n| || |\n"},
+		{name: "colors", output: "\x1b[38;2;255;0;0mHI\x1b[0m\n", want: "HI\n"},
+		{name: "box drawing", output: "╔═╗\n║ ║\n╚═╝", want: "+-+\n| |\n+-+"},
+		{name: "rounded box", output: "╭─╮\n╰─╯", want: "+-+\n+-+"},
+		{name: "blocks", output: "█▀▄ ░▒", want: "### .:"},
+		{name: "braille", output: "⣿⠀⡇", want: "# #"},
+		{name: "accents", output: "éé", want: "ee"},
+		{name: "wide characters keep their columns", output: "日|", want: "? |"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ASCIIOnly(tt.output)
+			if got != tt.want {
+				t.Errorf("ASCIIOnly(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+			for _, b := range []byte(got) {
+				if b >= 0x80 || b == 0x1b {
+					t.Fatalf("ASCIIOnly(%q) = %q, has byte %#x", tt.output, got, b)
+				}
+			}
+		})
+	}
+
+	// The pixel engines' output keeps its shape
+	layout, err := BrailleFont(2).LayoutContext(context.Background(), "HI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range layout.Rows {
+		if got := ASCIIOnly(row); len(got) != StringWidth(row) {
+			t.Errorf("ASCIIOnly(%q) = %q, want %d columns", row, got, StringWidth(row))
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package render
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ryanlewis/shout-sh/types"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rendering engines, chosen with the engine option.
+const (
+	// EngineFiglet draws text with FIGlet fonts; it is the default
+	EngineFiglet = "figlet"
+	// EngineBlock draws text with the built-in pixel font (BlockFont)
+	EngineBlock = "block"
+	// EngineBraille draws text with the built-in pixel font in braille
+	// patterns (BrailleFont)
+	EngineBraille = "braille"
+)
+
+// BlockFontName is the name of the fonts BlockFont builds. It isn't
+// "block", which is the name of a FIGlet font.
+const BlockFontName = "pixel"
+
+// blockCols and blockRows are the size of the pixel font's glyphs.
+const (
+	blockCols = 5
+	blockRows = 7
+)
+
+// blockPixels is the pixel font of the block engine: the printable ASCII
+// characters as 5x7 dots, a row per field, # for a dot.
+var blockPixels = map[rune]string{
+	' ':  "..... ..... ..... ..... ..... ..... .....",
+	'!':  "..#.. ..#.. ..#.. ..#.. ..#.. ..... ..#..",
+	'"':  ".#.#. .#.#. .#.#. ..... ..... ..... .....",
+	'#':  ".#.#. .#.#. ##### .#.#. ##### .#.#. .#.#.",
+	'$':  "..#.. .#### #.#.. .###. ..#.# ####. ..#..",
+	'%':  "##... ##..# ...#. ..#.. .#... #..## ...##",
+	'&':  ".##.. #..#. #.#.. .#... #.#.# #..#. .##.#",
+	'\'': "..#.. ..#.. ..#.. ..... ..... ..... .....",
+	'(':  "...#. ..#.. .#... .#... .#... ..#.. ...#.",
+	')':  ".#... ..#.. ...#. ...#. ...#. ..#.. .#...",
+	'*':  "..... ..#.. #.#.# .###. #.#.# ..#.. .....",
+	'+':  "..... ..#.. ..#.. ##### ..#.. ..#.. .....",
+	',':  "..... ..... ..... ..... .##.. ..#.. .#...",
+	'-':  "..... ..... ..... ##### ..... ..... .....",
+	'.':  "..... ..... ..... ..... ..... .##.. .##..",
+	'/':  "..... ....# ...#. ..#.. .#... #.... .....",
+	'0':  ".###. #...# #..## #.#.# ##..# #...# .###.",
+	'1':  "..#.. .##.. ..#.. ..#.. ..#.. ..#.. .###.",
+	'2':  ".###. #...# ....# ...#. ..#.. .#... #####",
+	'3':  "##### ...#. ..#.. ...#. ....# #...# .###.",
+	'4':  "...#. ..##. .#.#. #..#. ##### ...#. ...#.",
+	'5':  "##### #.... ####. ....# ....# #...# .###.",
+	'6':  "..##. .#... #.... ####. #...# #...# .###.",
+	'7':  "##### ....# ...#. ..#.. .#... .#... .#...",
+	'8':  ".###. #...# #...# .###. #...# #...# .###.",
+	'9':  ".###. #...# #...# .#### ....# ...#. .##..",
+	':':  "..... .##.. .##.. ..... .##.. .##.. .....",
+	';':  "..... .##.. .##.. ..... .##.. ..#.. .#...",
+	'<':  "...#. ..#.. .#... #.... .#... ..#.. ...#.",
+	'=':  "..... ..... ##### ..... ##### ..... .....",
+	'>':  ".#... ..#.. ...#. ....# ...#. ..#.. .#...",
+	'?':  ".###. #...# ....# ...#. ..#.. ..... ..#..",
+	'@':  ".###. #...# ....# .##.# #.#.# #.#.# .###.",
+	'A':  ".###. #...# #...# ##### #...# #...# #...#",
+	'B':  "####. #...# #...# ####. #...# #...# ####.",
+	'C':  ".###. #...# #.... #.... #.... #...# .###.",
+	'D':  "###.. #..#. #...# #...# #...# #..#. ###..",
+	'E':  "##### #.... #.... ####. #.... #.... #####",
+	'F':  "##### #.... #.... ####. #.... #.... #....",
+	'G':  ".###. #...# #.... #.### #...# #...# .####",
+	'H':  "#...# #...# #...# ##### #...# #...# #...#",
+	'I':  ".###. ..#.. ..#.. ..#.. ..#.. ..#.. .###.",
+	'J':  "..### ...#. ...#. ...#. ...#. #..#. .##..",
+	'K':  "#...# #..#. #.#.. ##... #.#.. #..#. #...#",
+	'L':  "#.... #.... #.... #.... #.... #.... #####",
+	'M':  "#...# ##.## #.#.# #.#.# #...# #...# #...#",
+	'N':  "#...# #...# ##..# #.#.# #..## #...# #...#",
+	'O':  ".###. #...# #...# #...# #...# #...# .###.",
+	'P':  "####. #...# #...# ####. #.... #.... #....",
+	'Q':  ".###. #...# #...# #...# #.#.# #..#. .##.#",
+	'R':  "####. #...# #...# ####. #.#.. #..#. #...#",
+	'S':  ".#### #.... #.... .###. ....# ....# ####.",
+	'T':  "##### ..#.. ..#.. ..#.. ..#.. ..#.. ..#..",
+	'U':  "#...# #...# #...# #...# #...# #...# .###.",
+	'V':  "#...# #...# #...# #...# #...# .#.#. ..#..",
+	'W':  "#...# #...# #...# #.#.# #.#.# #.#.# .#.#.",
+	'X':  "#...# #...# .#.#. ..#.. .#.#. #...# #...#",
+	'Y':  "#...# #...# .#.#. ..#.. ..#.. ..#.. ..#..",
+	'Z':  "##### ....# ...#. ..#.. .#... #.... #####",
+	'[':  ".###. .#... .#... .#... .#... .#... .###.",
+	'\\': "..... #.... .#... ..#.. ...#. ....# .....",
+	']':  ".###. ...#. ...#. ...#. ...#. ...#. .###.",
+	'^':  "..#.. .#.#. #...# ..... ..... ..... .....",
+	'_':  "..... ..... ..... ..... ..... ..... #####",
+	'`':  ".#... ..#.. ...#. ..... ..... ..... .....",
+	'a':  "..... ..... .###. ....# .#### #...# .####",
+	'b':  "#.... #.... #.##. ##..# #...# #...# ####.",
+	'c':  "..... ..... .###. #.... #.... #...# .###.",
+	'd':  "....# ....# .##.# #..## #...# #...# .####",
+	'e':  "..... ..... .###. #...# ##### #.... .###.",
+	'f':  "..##. .#..# .#... ###.. .#... .#... .#...",
+	'g':  "..... .#### #...# #...# .#### ....# .###.",
+	'h':  "#.... #.... #.##. ##..# #...# #...# #...#",
+	'i':  "..#.. ..... .##.. ..#.. ..#.. ..#.. .###.",
+	'j':  "...#. ..... ..##. ...#. ...#. #..#. .##..",
+	'k':  "#.... #.... #..#. #.#.. ##... #.#.. #..#.",
+	'l':  ".##.. ..#.. ..#.. ..#.. ..#.. ..#.. .###.",
+	'm':  "..... ..... ##.#. #.#.# #.#.# #...# #...#",
+	'n':  "..... ..... #.##. ##..# #...# #...# #...#",
+	'o':  "..... ..... .###. #...# #...# #...# .###.",
+	'p':  "..... ..... ####. #...# ####. #.... #....",
+	'q':  "..... ..... .##.# #..## .#### ....# ....#",
+	'r':  "..... ..... #.##. ##..# #.... #.... #....",
+	's':  "..... ..... .###. #.... .###. ....# ####.",
+	't':  ".#... .#... ###.. .#... .#... .#..# ..##.",
+	'u':  "..... ..... #...# #...# #...# #..## .##.#",
+	'v':  "..... ..... #...# #...# #...# .#.#. ..#..",
+	'w':  "..... ..... #...# #...# #.#.# #.#.# .#.#.",
+	'x':  "..... ..... #...# .#.#. ..#.. .#.#. #...#",
+	'y':  "..... ..... #...# #...# .#### ....# .###.",
+	'z':  "..... ..... ##### ...#. ..#.. .#... #####",
+	'{':  "...#. ..#.. ..#.. .#... ..#.. ..#.. ...#.",
+	'|':  "..#.. ..#.. ..#.. ..#.. ..#.. ..#.. ..#..",
+	'}':  ".#... ..#.. ..#.. ...#. ..#.. ..#.. .#...",
+	'~':  "..... ..... .#... #.#.# ...#. ..... .....",
+}
+
+// pixelFontKey identifies a font built from the pixel font.
+type pixelFontKey struct {
+	engine string
+	scale  int
+}
+
+var (
+	pixelMu    sync.Mutex
+	pixelFonts = make(map[pixelFontKey]*Font)
+)
+
+// BlockFont returns the font of the block engine: the built-in 5x7 pixel
+// font drawn with full and half block characters, two rows of dots to a
+// line, each dot scale columns wide and scale dots tall. Letters with
+// accents, in any Latin script, are drawn without them; other characters
+// outside ASCII are missing from the font, so they're drawn as '?' like
+// in FIGlet fonts. Fonts are built once per scale and shared.
+//
+// Parameters:
+//   - scale: the size of a dot, between 1 and types.MaxScale; values out
+//     of range are brought within it
+//
+// Returns:
+//   - *Font: the block font, named BlockFontName
+//
+// Example:
+//
+//	layout, err := render.BlockFont(2).LayoutContext(ctx, "HELLO")
+func BlockFont(scale int) *Font {
+	return pixelFont(EngineBlock, scale)
+}
+
+// pixelFont returns the font engine draws the pixel font with at scale,
+// building it on first use.
+func pixelFont(engine string, scale int) *Font {
+	scale = min(max(scale, 1), types.MaxScale)
+
+	pixelMu.Lock()
+	defer pixelMu.Unlock()
+	key := pixelFontKey{engine: engine, scale: scale}
+	if font, ok := pixelFonts[key]; ok {
+		return font
+	}
+
+	name, glyph, height := BlockFontName, blockGlyph, (blockRows*scale+1)/2
+	if engine == EngineBraille {
+		name, glyph, height = BrailleFontName, brailleGlyph, (blockRows*scale+3)/4
+	}
+	parsed := &figFont{
+		hardblank: '$',
+		height:    height,
+		baseline:  height,
+		glyphs:    make(map[rune][]string),
+	}
+	for r, pixels := range blockPixels {
+		parsed.glyphs[r] = glyph(strings.Fields(pixels), scale)
+	}
+	for r := rune(0xC0); r <= 0x24F; r++ {
+		if base, ok := baseLetter(r); ok {
+			parsed.glyphs[r] = parsed.glyphs[base]
+		}
+	}
+
+	// Pixel fonts have no file, so their checksum is of the glyphs drawn
+	h := sha256.New()
+	for _, r := range slices.Sorted(maps.Keys(parsed.glyphs)) {
+		h.Write([]byte(string(r)))
+		for _, row := range parsed.glyphs[r] {
+			h.Write([]byte(row + "\n"))
+		}
+	}
+	font := &Font{Name: name, built: parsed}
+	sum := hex.EncodeToString(h.Sum(nil))
+	font.sumOnce.Do(func() { font.sum = sum })
+	pixelFonts[key] = font
+	return font
+}
+
+// EngineFont returns the font opts draw with: the block or braille font
+// of opts.Scale with those engines, or else opts.Font as ResolveFont
+// resolves it.
+//
+// Parameters:
+//   - opts: the render options, of which Engine, Scale and Font are read
+//   - defaultName: the font to fall back to
+//
+// Returns:
+//   - *Font: the font to render with, nil if no FIGlet font is loaded
+//
+// Example:
+//
+//	font := cache.EngineFont(opts, "standard")
+func (fc *FontCache) EngineFont(opts types.RenderOptions, defaultName string) *Font {
+	switch opts.Engine {
+	case EngineBlock:
+		return BlockFont(opts.Scale)
+	case EngineBraille:
+		return BrailleFont(cmp.Or(opts.Scale, BrailleScale))
+	}
+	return fc.ResolveFont(opts.Font, defaultName)
+}
+
+// blockGlyph draws the rows of dots of a pixel font glyph at scale, with a
+// column of space after it, packing two rows of dots into each line with
+// half blocks.
+func blockGlyph(pixels []string, scale int) []string {
+	dot := func(x, y int) bool {
+		x, y = x/scale, y/scale
+		return y < len(pixels) && x < blockCols && pixels[y][x] == '#'
+	}
+	width := (blockCols + 1) * scale
+	lines := make([]string, (blockRows*scale+1)/2)
+	for i := range lines {
+		var b strings.Builder
+		for x := range width {
+			switch top, bottom := dot(x, 2*i), dot(x, 2*i+1); {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// baseLetter returns the ASCII letter r is drawn as when it carries
+// accents, such as e for é, and whether it is one.
+func baseLetter(r rune) (rune, bool) {
+	decomposed := []rune(norm.NFD.String(string(r)))
+	if len(decomposed) < 2 || decomposed[0] > unicode.MaxASCII || !unicode.IsLetter(decomposed[0]) {
+		return 0, false
+	}
+	for _, mark := range decomposed[1:] {
+		if !unicode.Is(unicode.Mn, mark) {
+			return 0, false
+		}
+	}
+	return decomposed[0], true
+}
@@ -0,0 +1,131 @@
+package render
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestBlockPixels(t *testing.T) {
+	for r, pixels := range blockPixels {
+		rows := strings.Fields(pixels)
+		if len(rows) != blockRows {
+			t.Errorf("%q has %d rows, want %d", r, len(rows), blockRows)
+		}
+		for _, row := range rows {
+			if len(row) != blockCols || strings.Trim(row, ".#") != "" {
+				t.Errorf("%q has row %q, want %d of . and #", r, row, blockCols)
+			}
+		}
+	}
+	for r := rune(' '); r <= '~'; r++ {
+		if _, ok := blockPixels[r]; !ok {
+			t.Errorf("no pixels for %q", r)
+		}
+	}
+}
+
+func TestBlockFont(t *testing.T) {
+	tests := []struct {
+		scale      int
+		wantHeight int
+		wantWidth  int
+	}{
+		{scale: 1, wantHeight: 4, wantWidth: 6},
+		{scale: 2, wantHeight: 7, wantWidth: 12},
+		{scale: 4, wantHeight: 14, wantWidth: 24},
+		// Out of range scales are brought within 1-4
+		{scale: 0, wantHeight: 4, wantWidth: 6},
+		{scale: 9, wantHeight: 14, wantWidth: 24},
+	}
+
+	for _, tt := range tests {
+		font := BlockFont(tt.scale)
+		if font.Name != BlockFontName {
+			t.Errorf("BlockFont(%d).Name = %q, want %q", tt.scale, font.Name, BlockFontName)
+		}
+		layout, err := font.LayoutContext(context.Background(), "HI")
+		if err != nil {
+			t.Fatalf("BlockFont(%d) layout error = %v", tt.scale, err)
+		}
+		if len(layout.Rows) != tt.wantHeight {
+			t.Errorf("BlockFont(%d) draws %d rows, want %d", tt.scale, len(layout.Rows), tt.wantHeight)
+		}
+		if got := layout.Spans[0][1].End - layout.Spans[0][1].Start; got != tt.wantWidth {
+			t.Errorf("BlockFont(%d) glyphs are %d columns wide, want %d", tt.scale, got, tt.wantWidth)
+		}
+	}
+
+	if BlockFont(2) != BlockFont(2) {
+		t.Error("BlockFont(2) built twice")
+	}
+	sum1, _ := BlockFont(1).Checksum()
+	sum2, _ := BlockFont(2).Checksum()
+	if sum1 == "" || sum1 == sum2 {
+		t.Errorf("checksums %q and %q, want distinct checksums per scale", sum1, sum2)
+	}
+}
+
+func TestBlockGlyph(t *testing.T) {
+	// Two rows of pixels to a line: ▀ for the top, ▄ for the bottom and █
+	// for both
+	want := []string{
+		" ▀█▀  ",
+		"  █   ",
+		"  █   ",
+		" ▀▀▀  ",
+	}
+	if got := blockGlyph(strings.Fields(blockPixels['I']), 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("blockGlyph('I') = %q, want %q", got, want)
+	}
+}
+
+func TestBlockFontUnicode(t *testing.T) {
+	font := BlockFont(1)
+
+	plain, err := font.LayoutContext(context.Background(), "Ecole")
+	if err != nil {
+		t.Fatalf("LayoutContext() error = %v", err)
+	}
+	accented, err := font.LayoutContext(context.Background(), "École")
+	if err != nil {
+		t.Fatalf("LayoutContext() error = %v", err)
+	}
+	if !reflect.DeepEqual(accented.Rows, plain.Rows) {
+		t.Errorf("École = %q, want the letters of Ecole %q", accented.Rows, plain.Rows)
+	}
+
+	missing, err := font.MissingRunes("Ça ☃ ok")
+	if err != nil {
+		t.Fatalf("MissingRunes() error = %v", err)
+	}
+	if string(missing) != "☃" {
+		t.Errorf("MissingRunes() = %q, want ☃", string(missing))
+	}
+}
+
+func TestEngineFont(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard"}}); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	if got := cache.EngineFont(types.RenderOptions{Engine: EngineBlock, Font: "standard", Scale: 3}, "standard"); got != BlockFont(3) {
+		t.Errorf("EngineFont(block) = %v, want BlockFont(3)", got.Name)
+	}
+	if got := cache.EngineFont(types.RenderOptions{Engine: EngineFiglet, Font: "standard"}, "standard"); got == nil || got.Name != "standard" {
+		t.Errorf("EngineFont(figlet) = %v, want standard", got)
+	}
+
+	layout, err := GenerateLayoutContext(context.Background(), "A", types.RenderOptions{Engine: EngineBlock}, cache)
+	if err != nil {
+		t.Fatalf("GenerateLayoutContext() error = %v", err)
+	}
+	if !strings.Contains(strings.Join(layout.Rows, ""), "█") {
+		t.Errorf("block engine layout = %q, want block characters", layout.Rows)
+	}
+}
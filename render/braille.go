@@ -0,0 +1,100 @@
+package render
+
+import "strings"
+
+// BrailleFontName is the name of the fonts BrailleFont builds.
+const BrailleFontName = "pixel-braille"
+
+// BrailleScale is the scale the braille engine draws at when the scale
+// option isn't given: at 1 its glyphs are only two lines tall.
+const BrailleScale = 2
+
+// brailleBits are the bits of the braille pattern characters (U+2800 to
+// U+28FF) that raise each of their dots, by row and column.
+var brailleBits = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// BrailleFont returns the font of the braille engine: the built-in 5x7
+// pixel font drawn with braille patterns, which hold two columns of four
+// dots each, four times the dots of a block character. Each pixel is
+// scale dots wide and tall, with the corners of diagonal strokes rounded
+// off, so large text is smoother than the block engine draws it. Like
+// BlockFont, letters with accents are drawn without them and other
+// characters outside ASCII as '?'. Fonts are built once per scale and
+// shared.
+//
+// Parameters:
+//   - scale: the size of a pixel in dots, between 1 and types.MaxScale;
+//     values out of range are brought within it
+//
+// Returns:
+//   - *Font: the braille font, named BrailleFontName
+//
+// Example:
+//
+//	layout, err := render.BrailleFont(render.BrailleScale).LayoutContext(ctx, "HELLO")
+func BrailleFont(scale int) *Font {
+	return pixelFont(EngineBraille, scale)
+}
+
+// brailleGlyph draws the rows of dots of a pixel font glyph at scale,
+// with a column of space after it, as braille patterns.
+func brailleGlyph(pixels []string, scale int) []string {
+	width := (blockCols + 1) * scale
+	lines := make([]string, (blockRows*scale+3)/4)
+	for i := range lines {
+		var b strings.Builder
+		for x := 0; x < width; x += 2 {
+			var pattern rune
+			for row, bits := range brailleBits {
+				for col, bit := range bits {
+					if smoothDot(pixels, x+col, 4*i+row, scale) {
+						pattern |= bit
+					}
+				}
+			}
+			if pattern == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteRune(0x2800 + pattern)
+			}
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// smoothDot reports whether the dot at x, y of a glyph drawn at scale is
+// raised. Each pixel is a square of scale dots, but the triangle in each
+// of its corners takes the color of the pixels beside it when both match
+// and the ones opposite don't, as the Scale2x algorithm does, which fills
+// in the steps of diagonal strokes.
+func smoothDot(pixels []string, x, y, scale int) bool {
+	px, py := x/scale, y/scale
+	// The corner the dot is in, and its distance from the corner's edges
+	mx, dx := x%scale, -1
+	if 2*mx > scale-1 {
+		mx, dx = scale-1-mx, 1
+	}
+	my, dy := y%scale, -1
+	if 2*my > scale-1 {
+		my, dy = scale-1-my, 1
+	}
+	if mx+my < scale-1 {
+		vertical, horizontal := pixelAt(pixels, px, py+dy), pixelAt(pixels, px+dx, py)
+		if vertical == horizontal && vertical != pixelAt(pixels, px-dx, py) && horizontal != pixelAt(pixels, px, py-dy) {
+			return vertical
+		}
+	}
+	return pixelAt(pixels, px, py)
+}
+
+// pixelAt reports whether the pixel at column x, row y of a glyph is set;
+// the pixels around the glyph aren't.
+func pixelAt(pixels []string, x, y int) bool {
+	return x >= 0 && y >= 0 && x < blockCols && y < len(pixels) && pixels[y][x] == '#'
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestBrailleGlyph(t *testing.T) {
+	// At scale 1 each character holds two columns and four rows of pixels
+	want := []string{"⡏⠉⡇", "⠧⠤⠇"}
+	pixels := []string{"#####", "#...#", "#...#", "#...#", "#...#", "#...#", "#####"}
+	if got := brailleGlyph(strings.Fields(strings.Join(pixels, " ")), 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("brailleGlyph() = %q, want %q", got, want)
+	}
+	if got := brailleGlyph(strings.Fields(blockPixels[' ']), 2); strings.TrimSpace(strings.Join(got, "")) != "" {
+		t.Errorf("brailleGlyph(' ') = %q, want blank lines", got)
+	}
+}
+
+func TestSmoothDot(t *testing.T) {
+	// A diagonal of two pixels: the empty pixels beside both get the
+	// corners between them
+	pixels := []string{
+		"#....",
+		".#...",
+	}
+	tests := []struct {
+		x, y int
+		want bool
+	}{
+		// Inside the set pixels
+		{x: 0, y: 0, want: true},
+		{x: 3, y: 3, want: true},
+		// The top left corner of the empty pixel right of the first
+		{x: 2, y: 1, want: true},
+		{x: 3, y: 1, want: false},
+		// The bottom right corner of the empty pixel below the first
+		{x: 1, y: 2, want: true},
+		{x: 0, y: 3, want: false},
+	}
+	for _, tt := range tests {
+		if got := smoothDot(pixels, tt.x, tt.y, 2); got != tt.want {
+			t.Errorf("smoothDot(%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	// Scale 1 draws the pixels as they are
+	if smoothDot(pixels, 1, 0, 1) || !smoothDot(pixels, 1, 1, 1) {
+		t.Error("smoothDot() at scale 1 changed the pixels")
+	}
+}
+
+func TestBrailleFont(t *testing.T) {
+	tests := []struct {
+		scale      int
+		wantHeight int
+		wantWidth  int
+	}{
+		{scale: 1, wantHeight: 2, wantWidth: 3},
+		{scale: 2, wantHeight: 4, wantWidth: 6},
+		{scale: 4, wantHeight: 7, wantWidth: 12},
+	}
+
+	for _, tt := range tests {
+		font := BrailleFont(tt.scale)
+		if font.Name != BrailleFontName {
+			t.Errorf("BrailleFont(%d).Name = %q, want %q", tt.scale, font.Name, BrailleFontName)
+		}
+		layout, err := font.LayoutContext(context.Background(), "Hé")
+		if err != nil {
+			t.Fatalf("BrailleFont(%d) layout error = %v", tt.scale, err)
+		}
+		if len(layout.Rows) != tt.wantHeight {
+			t.Errorf("BrailleFont(%d) draws %d rows, want %d", tt.scale, len(layout.Rows), tt.wantHeight)
+		}
+		if got := layout.Spans[0][1].End - layout.Spans[0][1].Start; got != tt.wantWidth {
+			t.Errorf("BrailleFont(%d) glyphs are %d columns wide, want %d", tt.scale, got, tt.wantWidth)
+		}
+		for _, r := range strings.Join(layout.Rows, "") {
+			if r != ' ' && (r < 0x2800 || r > 0x28FF) {
+				t.Errorf("BrailleFont(%d) drew %q, want braille patterns", tt.scale, r)
+				break
+			}
+		}
+	}
+
+	if BrailleFont(2) == BlockFont(2) {
+		t.Error("BrailleFont(2) is the block font")
+	}
+	if got := NewFontCache().EngineFont(types.RenderOptions{Engine: EngineBraille}, "standard"); got != BrailleFont(BrailleScale) {
+		t.Errorf("EngineFont(braille) = %v, want BrailleFont(BrailleScale)", got.Name)
+	}
+}
@@ -0,0 +1,551 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AnsiReset resets all terminal colors and attributes.
+const AnsiReset = "\033[0m"
+
+// Color is a 24-bit RGB color.
+//
+// Usage example:
+//
+//	c := Color{R: 255, G: 0, B: 0}
+//	fmt.Print(c.Foreground() + "red text" + AnsiReset)
+type Color struct {
+	R, G, B uint8
+}
+
+// Foreground returns the ANSI truecolor escape sequence that sets this color
+// as the terminal foreground color.
+//
+// Returns:
+//   - string: the escape sequence, e.g. "\033[38;2;255;0;0m"
+func (c Color) Foreground() string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// Hex returns the color formatted as "#rrggbb".
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ColorScheme decides the color of a single character cell.
+// Static output always uses frame 0; animated output advances the frame.
+//
+// Usage example:
+//
+//	scheme, _ := ParseColor("rainbow")
+//	c := scheme.ColorAt(0, line, col)
+type ColorScheme interface {
+	ColorAt(frame, line, col int) Color
+}
+
+// SolidColor is a ColorScheme that paints every cell the same color.
+type SolidColor Color
+
+// ColorAt implements ColorScheme.
+func (s SolidColor) ColorAt(frame, line, col int) Color {
+	return Color(s)
+}
+
+// PresetScheme is a named multi-color ColorScheme such as "rainbow".
+type PresetScheme struct {
+	Name    string
+	palette []Color
+	// index picks the palette entry of a cell; seed drives presets with
+	// random-looking patterns such as matrix rain
+	index func(seed uint64, frame, line, col int) int
+	seed  uint64
+}
+
+// WithSeed returns a copy of the preset whose random-looking patterns,
+// such as matrix rain, are drawn from seed. Identical seeds draw
+// identical patterns.
+//
+// Parameters:
+//   - seed: the pattern seed
+//
+// Returns:
+//   - *PresetScheme: the seeded copy
+//
+// Example:
+//
+//	scheme, _ := ParseColor("matrix")
+//	seeded := scheme.(*PresetScheme).WithSeed(42)
+func (p *PresetScheme) WithSeed(seed uint64) *PresetScheme {
+	seeded := *p
+	seeded.seed = seed
+	return &seeded
+}
+
+// ColorAt implements ColorScheme.
+func (p *PresetScheme) ColorAt(frame, line, col int) Color {
+	n := len(p.palette)
+	i := p.index(p.seed, frame, line, col) % n
+	if i < 0 {
+		i += n
+	}
+	return p.palette[i]
+}
+
+// presets holds the built-in color schemes, keyed by name.
+// Palettes are expressed as xterm-256 indexes to match the classic look.
+var presets = map[string]*PresetScheme{
+	"rainbow": {
+		Name:    "rainbow",
+		palette: xtermPalette(196, 202, 208, 214, 220, 226, 190, 154, 118, 82, 46, 47, 48, 49, 50, 51),
+		index:   func(_ uint64, frame, line, col int) int { return frame + col*2 },
+	},
+	"fire": {
+		Name:    "fire",
+		palette: xtermPalette(52, 88, 124, 160, 196, 202, 208, 214, 220, 226),
+		index:   func(_ uint64, frame, line, col int) int { return frame + line*3 + col },
+	},
+	"ocean": {
+		Name:    "ocean",
+		palette: xtermPalette(17, 18, 19, 20, 21, 25, 31, 37, 43, 49, 50, 51),
+		index:   func(_ uint64, frame, line, col int) int { return frame + line + col*2 },
+	},
+	"matrix": {
+		Name:    "matrix",
+		palette: xtermPalette(28, 46),
+		// A drop falls down each column, one row a frame, starting at a
+		// point in its cycle picked by the seed
+		index: func(seed uint64, frame, line, col int) int {
+			if (frame-line+int(scramble(seed, col)%matrixCycle))%matrixCycle == 0 {
+				return 1
+			}
+			return 0
+		},
+	},
+	"neon": {
+		Name:    "neon",
+		palette: xtermPalette(201, 165, 129, 93, 57, 51, 87, 123, 159, 195),
+		index:   func(_ uint64, frame, line, col int) int { return frame + line + col },
+	},
+}
+
+// matrixCycle is how many frames apart the drops of one matrix column fall.
+const matrixCycle = 17
+
+// scramble hashes seed and n into a well-mixed value (SplitMix64).
+func scramble(seed uint64, n int) uint64 {
+	z := seed + uint64(n)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// ParseColor parses a color option value into a ColorScheme.
+// Accepted forms are preset names (rainbow, fire, ocean, matrix, neon),
+// hex colors (#RRGGBB or #RGB), rgb(r,g,b) triples and X11/CSS color names.
+// A comma-separated list of colors (e.g. "red,green,blue") yields a
+// *ColorList that cycles per word by default, and "gradient:" followed by
+// dash-separated colors (e.g. "gradient:f00-ff0-0f0") a *Gradient, its
+// hex colors written with or without the #.
+// Matching is case-insensitive. An empty value or "none" returns a nil scheme
+// meaning no color should be applied.
+//
+// Parameters:
+//   - spec: the raw color option value
+//
+// Returns:
+//   - ColorScheme: the parsed scheme, or nil for no color
+//   - error: error if the value is not a recognised color
+//
+// Example:
+//
+//	scheme, err := ParseColor("#ff8800")
+//	if err != nil {
+//	    return fiber.NewError(fiber.StatusBadRequest, err.Error())
+//	}
+func ParseColor(spec string) (ColorScheme, error) {
+	s := strings.ToLower(strings.TrimSpace(spec))
+
+	switch {
+	case s == "" || s == "none":
+		return nil, nil
+	case presets[s] != nil:
+		return presets[s], nil
+	}
+
+	if strings.HasPrefix(s, gradientPrefix) {
+		return parseGradient(s)
+	}
+
+	if parts := splitColorList(s); len(parts) > 1 {
+		list := &ColorList{Colors: make([]Color, len(parts)), Mode: ColorModeWord}
+		for i, part := range parts {
+			c, err := ParseSolidColor(part)
+			if err != nil {
+				return nil, err
+			}
+			list.Colors[i] = c
+		}
+		return list, nil
+	}
+
+	c, err := ParseSolidColor(s)
+	if err != nil {
+		return nil, err
+	}
+	return SolidColor(c), nil
+}
+
+// ParseSolidColor parses a single color in hex, rgb() or named form.
+// Unlike ParseColor it does not accept presets.
+//
+// Parameters:
+//   - spec: the color value, e.g. "#ff0000", "rgb(255,0,0)" or "red"
+//
+// Returns:
+//   - Color: the parsed color
+//   - error: error if the value cannot be parsed
+func ParseSolidColor(spec string) (Color, error) {
+	s := strings.ToLower(strings.TrimSpace(spec))
+
+	switch {
+	case strings.HasPrefix(s, "#"):
+		return parseHexColor(s)
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		return parseRGBColor(s)
+	}
+
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+	return Color{}, fmt.Errorf("invalid color: %q", spec)
+}
+
+// parseHexColor parses "#rrggbb" and the "#rgb" shorthand.
+func parseHexColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid hex color: %q", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color: %q", s)
+	}
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// parseRGBColor parses "rgb(r,g,b)" with each component in 0-255.
+func parseRGBColor(s string) (Color, error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(s, "rgb("), ")")
+	parts := strings.Split(inner, ",")
+	if len(parts) != 3 {
+		return Color{}, fmt.Errorf("invalid rgb color: %q", s)
+	}
+
+	var rgb [3]uint8
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || v < 0 || v > 255 {
+			return Color{}, fmt.Errorf("invalid rgb color: %q (components must be 0-255)", s)
+		}
+		rgb[i] = uint8(v)
+	}
+	return Color{R: rgb[0], G: rgb[1], B: rgb[2]}, nil
+}
+
+// splitColorList splits a comma-separated color list, ignoring commas
+// nested inside rgb(...) components.
+func splitColorList(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// ColorMode selects how a ColorList is distributed across a banner.
+type ColorMode string
+
+// Supported color modes.
+const (
+	// ColorModeChar advances at every drawn cell of the output rather than
+	// with the source text, so each letter is striped in the colors
+	ColorModeChar ColorMode = "char"
+	ColorModeWord ColorMode = "word"
+	ColorModeLine ColorMode = "line"
+)
+
+// ParseColorMode parses the cmode option. An empty value selects word mode.
+//
+// Parameters:
+//   - s: the raw option value (char, word or line)
+//
+// Returns:
+//   - ColorMode: the parsed mode
+//   - error: error if the mode is not recognised
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ColorModeWord, nil
+	case ColorModeChar, ColorModeWord, ColorModeLine:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid color mode: %q (must be char, word or line)", s)
+	}
+}
+
+// ColorList is a ColorScheme that cycles through a list of colors, advancing
+// once per word or line of the source text depending on Mode, or in
+// ColorModeChar once per drawn cell along each output row. It needs the
+// glyph positions of a Layout to know which source word produced each
+// cell; until bound with WithLayout it cycles per output row, or per
+// column in ColorModeChar.
+//
+// Usage example:
+//
+//	scheme, _ := ParseColor("red,green,blue")
+//	list := scheme.(*ColorList)
+//	list.Mode = ColorModeLine
+//	out := Colorize(layout.String(), list.WithLayout(layout), 0)
+type ColorList struct {
+	Colors []Color
+	Mode   ColorMode
+
+	layout   *Layout
+	segments []int
+	// cells numbers the drawn cells of each row in ColorModeChar
+	cells [][]int
+}
+
+// WithLayout returns a copy of the list bound to the given layout.
+//
+// Parameters:
+//   - layout: the layout the colors will be applied to
+//
+// Returns:
+//   - *ColorList: a bound copy of the list
+func (l *ColorList) WithLayout(layout *Layout) *ColorList {
+	bound := &ColorList{Colors: l.Colors, Mode: l.Mode, layout: layout}
+	if layout == nil {
+		return bound
+	}
+	if bound.Mode == ColorModeChar {
+		bound.cells = make([][]int, len(layout.Rows))
+		for row, text := range layout.Rows {
+			cells := Cells(text)
+			bound.cells[row] = make([]int, len(cells))
+			n := -1
+			for col, cell := range cells {
+				// The right half of a wide character is part of its cell
+				if cell != " " && cell != "" {
+					n++
+				}
+				bound.cells[row][col] = max(n, 0)
+			}
+		}
+		return bound
+	}
+
+	bound.segments = make([]int, len(layout.Text))
+	segment, line := -1, 0
+	inWord := false
+	for i, r := range layout.Text {
+		switch {
+		case r == '\n':
+			line++
+			inWord = false
+		case r == ' ' || r == '\t':
+			inWord = false
+		default:
+			if bound.Mode == ColorModeLine {
+				segment = line
+			} else if !inWord {
+				segment++
+			}
+			inWord = true
+		}
+		bound.segments[i] = segment
+	}
+	return bound
+}
+
+// ColorAt implements ColorScheme.
+func (l *ColorList) ColorAt(frame, line, col int) Color {
+	if l.Mode == ColorModeChar {
+		cell := col
+		if line >= 0 && line < len(l.cells) && col < len(l.cells[line]) {
+			cell = l.cells[line][col]
+		}
+		return l.Colors[(cell+frame)%len(l.Colors)]
+	}
+	segment := line
+	if l.layout != nil {
+		span, ok := l.layout.GlyphAt(line, col)
+		if !ok {
+			return l.Colors[frame%len(l.Colors)]
+		}
+		segment = l.segments[span.Index]
+	}
+	if segment < 0 {
+		segment = 0
+	}
+	return l.Colors[(segment+frame)%len(l.Colors)]
+}
+
+// PresetNames returns the sorted names of the built-in color schemes.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ColorNames returns the sorted list of supported X11/CSS color names.
+func ColorNames() []string {
+	names := make([]string, 0, len(namedColors))
+	for name := range namedColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupColorName returns the color registered under an X11/CSS name.
+func LookupColorName(name string) (Color, bool) {
+	c, ok := namedColors[strings.ToLower(name)]
+	return c, ok
+}
+
+// Colorize applies a color scheme to rendered ASCII art.
+// Only non-space characters are colored, so backgrounds stay transparent,
+// and every colored line is terminated with a reset sequence.
+//
+// Parameters:
+//   - text: the rendered ASCII art
+//   - scheme: the color scheme to apply; nil leaves the text unchanged
+//   - frame: the animation frame (0 for static output)
+//
+// Returns:
+//   - string: the colorized text
+//
+// Example:
+//
+//	scheme, _ := ParseColor("fire")
+//	fmt.Print(Colorize(ascii, scheme, 0))
+func Colorize(text string, scheme ColorScheme, frame int) string {
+	if scheme == nil || text == "" {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) * 4)
+
+	lines := strings.Split(text, "\n")
+	for lineNum, line := range lines {
+		if lineNum > 0 {
+			b.WriteByte('\n')
+		}
+
+		colored := false
+		var current Color
+		col := 0
+		// Wide characters take two columns; combining marks go with the
+		// character they're drawn on
+		eachCluster(line, func(cluster string, width int) {
+			if cluster != " " && width > 0 {
+				c := scheme.ColorAt(frame, lineNum, col)
+				if !colored || c != current {
+					b.WriteString(c.Foreground())
+					current = c
+					colored = true
+				}
+			}
+			b.WriteString(cluster)
+			col += width
+		})
+		if colored {
+			b.WriteString(AnsiReset)
+		}
+	}
+
+	return b.String()
+}
+
+// ColorizeLayout colorizes a rendered layout. Schemes that depend on glyph
+// positions, such as color lists, are bound to the layout first.
+//
+// Parameters:
+//   - layout: the rendered layout; nil yields an empty string
+//   - scheme: the color scheme to apply; nil leaves the text unchanged
+//   - frame: the animation frame (0 for static output)
+//
+// Returns:
+//   - string: the colorized text
+func ColorizeLayout(layout *Layout, scheme ColorScheme, frame int) string {
+	if layout == nil {
+		return ""
+	}
+	return Colorize(layout.String(), bindScheme(scheme, layout), frame)
+}
+
+// bindScheme binds schemes that depend on glyph positions, such as color
+// lists and gradients, to layout, and returns others as they are.
+func bindScheme(scheme ColorScheme, layout *Layout) ColorScheme {
+	switch s := scheme.(type) {
+	case *ColorList:
+		return s.WithLayout(layout)
+	case *Gradient:
+		return s.WithLayout(layout)
+	}
+	return scheme
+}
+
+// xtermPalette converts xterm-256 color indexes to RGB colors.
+func xtermPalette(indexes ...int) []Color {
+	palette := make([]Color, len(indexes))
+	for i, idx := range indexes {
+		palette[i] = xterm256(idx)
+	}
+	return palette
+}
+
+// xterm256 returns the RGB value of an xterm-256 color index.
+func xterm256(idx int) Color {
+	switch {
+	case idx < 16:
+		return xtermBasic[idx]
+	case idx < 232:
+		idx -= 16
+		levels := [6]uint8{0, 95, 135, 175, 215, 255}
+		return Color{R: levels[idx/36], G: levels[(idx/6)%6], B: levels[idx%6]}
+	default:
+		v := uint8(8 + (idx-232)*10)
+		return Color{R: v, G: v, B: v}
+	}
+}
+
+// xtermBasic holds the 16 standard xterm system colors.
+var xtermBasic = [16]Color{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
@@ -0,0 +1,283 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Color
+		preset  string
+		isNil   bool
+		wantErr bool
+	}{
+		{name: "empty means no color", spec: "", isNil: true},
+		{name: "none means no color", spec: "none", isNil: true},
+		{name: "rainbow preset", spec: "rainbow", preset: "rainbow"},
+		{name: "preset is case-insensitive", spec: "FIRE", preset: "fire"},
+		{name: "hex color", spec: "#ff8800", want: Color{255, 136, 0}},
+		{name: "uppercase hex color", spec: "#FF8800", want: Color{255, 136, 0}},
+		{name: "short hex color", spec: "#f80", want: Color{255, 136, 0}},
+		{name: "rgb triple", spec: "rgb(10, 20, 30)", want: Color{10, 20, 30}},
+		{name: "named color", spec: "rebeccapurple", want: Color{102, 51, 153}},
+		{name: "basic named color", spec: "Red", want: Color{255, 0, 0}},
+		{name: "invalid hex digits", spec: "#gggggg", wantErr: true},
+		{name: "invalid hex length", spec: "#ff88", wantErr: true},
+		{name: "rgb out of range", spec: "rgb(256,0,0)", wantErr: true},
+		{name: "rgb wrong arity", spec: "rgb(1,2)", wantErr: true},
+		{name: "unknown name", spec: "notacolor", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, err := ParseColor(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			switch {
+			case tt.isNil:
+				if scheme != nil {
+					t.Errorf("ParseColor(%q) = %v, want nil", tt.spec, scheme)
+				}
+			case tt.preset != "":
+				p, ok := scheme.(*PresetScheme)
+				if !ok || p.Name != tt.preset {
+					t.Errorf("ParseColor(%q) = %v, want preset %s", tt.spec, scheme, tt.preset)
+				}
+			default:
+				solid, ok := scheme.(SolidColor)
+				if !ok {
+					t.Fatalf("ParseColor(%q) = %T, want SolidColor", tt.spec, scheme)
+				}
+				if Color(solid) != tt.want {
+					t.Errorf("ParseColor(%q) = %v, want %v", tt.spec, solid, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestColorForegroundAndHex(t *testing.T) {
+	c := Color{R: 1, G: 2, B: 255}
+
+	if got := c.Foreground(); got != "\033[38;2;1;2;255m" {
+		t.Errorf("Foreground() = %q", got)
+	}
+	if got := c.Hex(); got != "#0102ff" {
+		t.Errorf("Hex() = %q, want #0102ff", got)
+	}
+}
+
+func TestColorNames(t *testing.T) {
+	names := ColorNames()
+	if len(names) < 140 {
+		t.Errorf("expected the full CSS color list, got %d names", len(names))
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Fatalf("ColorNames not sorted at %d: %s > %s", i, names[i-1], names[i])
+		}
+	}
+
+	if _, ok := LookupColorName("DodgerBlue"); !ok {
+		t.Error("LookupColorName should be case-insensitive")
+	}
+}
+
+func TestPresetNames(t *testing.T) {
+	want := []string{"fire", "matrix", "neon", "ocean", "rainbow"}
+	got := PresetNames()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("PresetNames() = %v, want %v", got, want)
+	}
+}
+
+func TestColorize(t *testing.T) {
+	t.Run("nil scheme leaves text unchanged", func(t *testing.T) {
+		if got := Colorize("A B", nil, 0); got != "A B" {
+			t.Errorf("Colorize() = %q, want unchanged", got)
+		}
+	})
+
+	t.Run("solid color wraps visible characters", func(t *testing.T) {
+		red := SolidColor(Color{R: 255})
+		got := Colorize(" AB \n  ", red, 0)
+		want := " " + Color{R: 255}.Foreground() + "AB " + AnsiReset + "\n  "
+		if got != want {
+			t.Errorf("Colorize() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("preset varies color per column", func(t *testing.T) {
+		got := Colorize("AB", presets["rainbow"], 0)
+		if strings.Count(got, "\033[38;2;") != 2 {
+			t.Errorf("expected a color sequence per character, got %q", got)
+		}
+		if !strings.HasSuffix(got, AnsiReset) {
+			t.Errorf("expected trailing reset, got %q", got)
+		}
+	})
+}
+
+func TestXterm256(t *testing.T) {
+	tests := []struct {
+		idx  int
+		want Color
+	}{
+		{9, Color{255, 0, 0}},
+		{196, Color{255, 0, 0}},
+		{46, Color{0, 255, 0}},
+		{232, Color{8, 8, 8}},
+	}
+
+	for _, tt := range tests {
+		if got := xterm256(tt.idx); got != tt.want {
+			t.Errorf("xterm256(%d) = %v, want %v", tt.idx, got, tt.want)
+		}
+	}
+}
+
+func TestParseColorList(t *testing.T) {
+	scheme, err := ParseColor("red, #00ff00,rgb(0,0,255)")
+	if err != nil {
+		t.Fatalf("ParseColor failed: %v", err)
+	}
+	list, ok := scheme.(*ColorList)
+	if !ok {
+		t.Fatalf("expected *ColorList, got %T", scheme)
+	}
+	want := []Color{{255, 0, 0}, {0, 255, 0}, {0, 0, 255}}
+	for i, c := range want {
+		if list.Colors[i] != c {
+			t.Errorf("color %d = %v, want %v", i, list.Colors[i], c)
+		}
+	}
+	if list.Mode != ColorModeWord {
+		t.Errorf("default mode = %s, want word", list.Mode)
+	}
+
+	if _, err := ParseColor("red,rainbow"); err == nil {
+		t.Error("presets should not be accepted inside a color list")
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ColorMode
+		wantErr bool
+	}{
+		{"", ColorModeWord, false},
+		{"char", ColorModeChar, false},
+		{"WORD", ColorModeWord, false},
+		{"line", ColorModeLine, false},
+		{"pixel", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColorMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColorMode(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestColorListWithLayout(t *testing.T) {
+	red, green := Color{R: 255}, Color{G: 255}
+	font := testFont(t, "flf2a$ 2 2 3 0 1")
+
+	// "AB A\nB" -> glyph columns: A[0,2) B[2,4) ' '[4,5) A[5,7) on line 0.
+	layout := font.layout("AB A\nB")
+
+	tests := []struct {
+		mode      ColorMode
+		row, col  int
+		wantColor Color
+	}{
+		// Row 0 is "/\\|) /\\": a color per drawn cell, spaces skipped
+		{ColorModeChar, 0, 0, red},
+		{ColorModeChar, 0, 1, green},
+		{ColorModeChar, 0, 2, red},
+		{ColorModeChar, 0, 3, green},
+		{ColorModeChar, 0, 5, red},
+		{ColorModeWord, 0, 0, red},
+		{ColorModeWord, 0, 2, red},
+		{ColorModeWord, 0, 5, green},
+		{ColorModeWord, 2, 0, red},
+		{ColorModeLine, 0, 5, red},
+		{ColorModeLine, 2, 0, green},
+	}
+
+	for _, tt := range tests {
+		list := &ColorList{Colors: []Color{red, green}, Mode: tt.mode}
+		got := list.WithLayout(layout).ColorAt(0, tt.row, tt.col)
+		if got != tt.wantColor {
+			t.Errorf("mode %s cell (%d,%d) = %v, want %v", tt.mode, tt.row, tt.col, got, tt.wantColor)
+		}
+	}
+}
+
+func TestColorizeLayout(t *testing.T) {
+	if got := ColorizeLayout(nil, nil, 0); got != "" {
+		t.Errorf("nil layout should render empty, got %q", got)
+	}
+
+	font := testFont(t, "flf2a$ 2 2 3 0 1")
+	list := &ColorList{Colors: []Color{{R: 255}, {G: 255}}, Mode: ColorModeWord}
+	got := ColorizeLayout(font.layout("AB A"), list, 0)
+	if !strings.Contains(got, Color{R: 255}.Foreground()+"/\\B) "+Color{G: 255}.Foreground()+"/\\") {
+		t.Errorf("expected per-word colors, got %q", got)
+	}
+}
+
+func TestColorizeCells(t *testing.T) {
+	red, green := Color{R: 255}, Color{G: 255}
+	list := &ColorList{Colors: []Color{red, green}, Mode: ColorModeChar}
+
+	// Wide characters take one color for both their columns
+	layout := wideFont(t).layout("A日A")
+	got := ColorizeLayout(layout, list, 0)
+	want := red.Foreground() + "/" + green.Foreground() + "\\" + red.Foreground() + "日" + green.Foreground() + "/" + red.Foreground() + "\\" + AnsiReset
+	if first := strings.Split(got, "\n")[0]; first != want {
+		t.Errorf("first row = %q, want %q", first, want)
+	}
+
+	// Frames move the colors along
+	if got := list.WithLayout(layout).ColorAt(1, 0, 0); got != green {
+		t.Errorf("frame 1 first cell = %v, want %v", got, green)
+	}
+	// Unbound lists cycle per column
+	if got := list.ColorAt(0, 3, 1); got != green {
+		t.Errorf("unbound column 1 = %v, want %v", got, green)
+	}
+}
+
+func TestPresetWithSeed(t *testing.T) {
+	scheme, _ := ParseColor("matrix")
+	matrix := scheme.(*PresetScheme)
+
+	a, b, other := matrix.WithSeed(1), matrix.WithSeed(1), matrix.WithSeed(2)
+	same, differs := true, false
+	for col := 0; col < 40; col++ {
+		for line := 0; line < 6; line++ {
+			same = same && a.ColorAt(3, line, col) == b.ColorAt(3, line, col)
+			differs = differs || a.ColorAt(3, line, col) != other.ColorAt(3, line, col)
+			// Drops fall one row a frame
+			if a.ColorAt(3, line, col) != a.ColorAt(4, line+1, col) {
+				t.Fatalf("drop at frame 3 line %d col %d didn't fall", line, col)
+			}
+		}
+	}
+	if !same || !differs {
+		t.Errorf("equal seeds matched: %v, different seeds differed: %v; want both", same, differs)
+	}
+	if matrix.seed != 0 {
+		t.Error("WithSeed changed the shared preset")
+	}
+}
@@ -0,0 +1,238 @@
+package render
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// controlSignature is the magic first line of a FIGlet control file.
+const controlSignature = "flc2a"
+
+// ControlFile is a parsed FIGlet control file (.flc).
+// Control files translate input characters before rendering, for example
+// to map accented Latin characters onto the code points a font uses for
+// its extended glyphs. Translations are grouped into stages separated by
+// the "f" (freeze) command; each stage sees the output of the previous one.
+//
+// Usage example:
+//
+//	ctrl, err := LoadControlFile("./fonts/latin2.flc")
+//	if err != nil {
+//	    log.Printf("Invalid control file: %v", err)
+//	}
+//	text = ctrl.Apply(text)
+type ControlFile struct {
+	Name   string
+	stages [][]translation
+	// sum is the hex SHA-256 of the file, set by LoadControlFile.
+	sum string
+}
+
+// translation maps the inclusive rune range [from, to] onto target onwards.
+type translation struct {
+	from, to, target rune
+}
+
+// LoadControlFile reads and parses a control file from disk.
+// The control file name is the file name without its .flc extension.
+//
+// Parameters:
+//   - path: the path to the .flc file
+//
+// Returns:
+//   - *ControlFile: the parsed control file
+//   - error: error if the file cannot be read or parsed
+func LoadControlFile(path string) (*ControlFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open control file: %w", err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	ctrl, err := ParseControlFile(io.TeeReader(file, h))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse control file %s: %w", path, err)
+	}
+	// Hash anything the scanner did not consume
+	if _, err := io.Copy(h, file); err != nil {
+		return nil, fmt.Errorf("failed to read control file %s: %w", path, err)
+	}
+	ctrl.sum = hex.EncodeToString(h.Sum(nil))
+	base := path[strings.LastIndexAny(path, `/\`)+1:]
+	ctrl.Name = strings.TrimSuffix(base, ".flc")
+	return ctrl, nil
+}
+
+// ParseControlFile parses FIGlet control file commands.
+// Supported commands are "t" (translate a character or range), bare
+// numeric pairs (translate one code point to another) and "f" (start a new
+// stage). Input-encoding commands (h, j, b, u, g) are accepted but ignored
+// because request text always arrives as UTF-8.
+//
+// Parameters:
+//   - r: the control file contents
+//
+// Returns:
+//   - *ControlFile: the parsed control file
+//   - error: error if a command is malformed
+func ParseControlFile(r io.Reader) (*ControlFile, error) {
+	scanner := bufio.NewScanner(r)
+	ctrl := &ControlFile{stages: [][]translation{nil}}
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNum == 1 && strings.HasPrefix(line, controlSignature) {
+			continue
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		stage := len(ctrl.stages) - 1
+		switch {
+		case line[0] == 't':
+			tr, err := parseTranslate(strings.TrimSpace(line[1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			ctrl.stages[stage] = append(ctrl.stages[stage], tr)
+		case line[0] == 'f':
+			ctrl.stages = append(ctrl.stages, nil)
+		case line[0] == '-' || (line[0] >= '0' && line[0] <= '9'):
+			tr, err := parseNumericPair(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			ctrl.stages[stage] = append(ctrl.stages[stage], tr)
+		case strings.ContainsRune("hjbug", rune(line[0])):
+			// Input encoding selection; not applicable to UTF-8 input.
+		default:
+			return nil, fmt.Errorf("line %d: unknown command %q", lineNum, line[0])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ctrl, nil
+}
+
+// parseTranslate parses the arguments of a "t" command, e.g. "a-z A-Z".
+func parseTranslate(args string) (translation, error) {
+	in, rest, err := parseControlRange(args)
+	if err != nil {
+		return translation{}, err
+	}
+	out, _, err := parseControlRange(strings.TrimLeft(rest, " \t"))
+	if err != nil {
+		return translation{}, err
+	}
+	if in[1]-in[0] != out[1]-out[0] {
+		return translation{}, fmt.Errorf("translation ranges differ in length")
+	}
+	return translation{from: in[0], to: in[1], target: out[0]}, nil
+}
+
+// parseNumericPair parses a bare "in out" pair of code points.
+func parseNumericPair(line string) (translation, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return translation{}, fmt.Errorf("expected two code points")
+	}
+	in, err := strconv.ParseInt(fields[0], 0, 32)
+	if err != nil {
+		return translation{}, fmt.Errorf("invalid code point %q", fields[0])
+	}
+	out, err := strconv.ParseInt(fields[1], 0, 32)
+	if err != nil {
+		return translation{}, fmt.Errorf("invalid code point %q", fields[1])
+	}
+	return translation{from: rune(in), to: rune(in), target: rune(out)}, nil
+}
+
+// parseControlRange parses a character or "a-z" style range and returns
+// the remaining input.
+func parseControlRange(s string) ([2]rune, string, error) {
+	first, rest, err := parseControlChar(s)
+	if err != nil {
+		return [2]rune{}, "", err
+	}
+	if strings.HasPrefix(rest, "-") && len(rest) > 1 && rest[1] != ' ' && rest[1] != '\t' {
+		last, rest, err := parseControlChar(rest[1:])
+		if err != nil {
+			return [2]rune{}, "", err
+		}
+		if last < first {
+			return [2]rune{}, "", fmt.Errorf("invalid range %q-%q", first, last)
+		}
+		return [2]rune{first, last}, rest, nil
+	}
+	return [2]rune{first, first}, rest, nil
+}
+
+// parseControlChar parses one character, honouring backslash escapes such
+// as "\n", "\ " and numeric forms like "\65" or "\0x41".
+func parseControlChar(s string) (rune, string, error) {
+	if s == "" {
+		return 0, "", fmt.Errorf("missing character")
+	}
+
+	runes := []rune(s)
+	if runes[0] != '\\' || len(runes) == 1 {
+		return runes[0], string(runes[1:]), nil
+	}
+
+	switch e := runes[1]; {
+	case e == '-' || (e >= '0' && e <= '9'):
+		end := 2
+		for end < len(runes) && strings.ContainsRune("0123456789abcdefABCDEFxX", runes[end]) {
+			end++
+		}
+		n, err := strconv.ParseInt(string(runes[1:end]), 0, 32)
+		if err != nil {
+			return 0, "", fmt.Errorf("invalid numeric escape %q", string(runes[:end]))
+		}
+		return rune(n), string(runes[end:]), nil
+	default:
+		escapes := map[rune]rune{'a': 7, 'b': 8, 'e': 27, 'f': 12, 'n': 10, 'r': 13, 't': 9, 'v': 11}
+		if r, ok := escapes[e]; ok {
+			return r, string(runes[2:]), nil
+		}
+		return e, string(runes[2:]), nil
+	}
+}
+
+// Apply translates text through every stage of the control file.
+//
+// Parameters:
+//   - text: the input text
+//
+// Returns:
+//   - string: the translated text, with the same number of runes
+func (c *ControlFile) Apply(text string) string {
+	if c == nil {
+		return text
+	}
+
+	runes := []rune(text)
+	for _, stage := range c.stages {
+		for i, r := range runes {
+			for _, tr := range stage {
+				if r >= tr.from && r <= tr.to {
+					runes[i] = tr.target + (r - tr.from)
+					break
+				}
+			}
+		}
+	}
+	return string(runes)
+}
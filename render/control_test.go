@@ -0,0 +1,143 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestParseControlFile(t *testing.T) {
+	input := `flc2a
+# Upper-case everything, then map Ä onto a font-specific code point
+t a-z A-Z
+t \0xE4 \0xC4
+233 69
+u
+f
+t \0xC4 \256
+t \  _
+`
+	ctrl, err := ParseControlFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseControlFile failed: %v", err)
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"hello", "HELLO"},
+		{"ä", string(rune(256))},
+		{"é", "E"},
+		{"a b", "A_B"},
+		{"123", "123"},
+	}
+	for _, tt := range tests {
+		if got := ctrl.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseControlFileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"mismatched ranges", "t a-z A-C\n"},
+		{"reversed range", "t z-a A-Z\n"},
+		{"missing target", "t a\n"},
+		{"bad numeric pair", "65 zz\n"},
+		{"unknown command", "x a b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseControlFile(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestParseControlChar(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     rune
+		wantRest string
+	}{
+		{"a rest", 'a', " rest"},
+		{`\n`, '\n', ""},
+		{`\ x`, ' ', "x"},
+		{`\65`, 'A', ""},
+		{`\0x41-`, 'A', "-"},
+		{`\\`, '\\', ""},
+		{"é", 'é', ""},
+	}
+	for _, tt := range tests {
+		got, rest, err := parseControlChar(tt.in)
+		if err != nil || got != tt.want || rest != tt.wantRest {
+			t.Errorf("parseControlChar(%q) = %q, %q, %v", tt.in, got, rest, err)
+		}
+	}
+}
+
+func TestControlFileApplyNil(t *testing.T) {
+	var ctrl *ControlFile
+	if got := ctrl.Apply("abc"); got != "abc" {
+		t.Errorf("nil control file should be a no-op, got %q", got)
+	}
+}
+
+func TestLoadFontsWithControlFiles(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile("../fonts/standard.flf")
+	if err != nil {
+		t.Fatalf("Failed to read font: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "standard.flf"), data, 0644); err != nil {
+		t.Fatalf("Failed to write font: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "upper.flc"), []byte("flc2a\nt a-z A-Z\n"), 0644); err != nil {
+		t.Fatalf("Failed to write control file: %v", err)
+	}
+
+	cache := NewFontCache()
+	err = cache.LoadFonts(config.FontConfig{
+		Path:     dir,
+		Allowed:  []string{"standard"},
+		Controls: []string{"upper", "missing"},
+	})
+	if err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	if got := cache.ApplyControls("shout"); got != "SHOUT" {
+		t.Errorf("ApplyControls() = %q, want SHOUT", got)
+	}
+
+	lower, err := GenerateASCII("hi", types.RenderOptions{Font: "standard"}, cache)
+	if err != nil {
+		t.Fatalf("GenerateASCII failed: %v", err)
+	}
+	upper, _ := GenerateASCII("HI", types.RenderOptions{Font: "standard"}, cache)
+	if lower != upper {
+		t.Error("control file should be applied before rendering")
+	}
+
+	// Changing a control file changes the checksum after a reload
+	before := cache.ControlsChecksum()
+	if before == "" {
+		t.Fatal("expected a controls checksum")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "upper.flc"), []byte("flc2a\nt a-y A-Y\n"), 0644); err != nil {
+		t.Fatalf("Failed to write control file: %v", err)
+	}
+	cache.LoadFonts(config.FontConfig{Path: dir, Allowed: []string{"standard"}, Controls: []string{"upper"}})
+	if after := cache.ControlsChecksum(); after == before {
+		t.Error("controls checksum should change with the file contents")
+	}
+}
@@ -0,0 +1,188 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is the range of colors a terminal can show. The zero value,
+// TrueColor, is what Colorize draws.
+type ColorDepth int
+
+const (
+	// TrueColor is 24-bit RGB color.
+	TrueColor ColorDepth = iota
+	// Colors256 is the xterm 256 color palette.
+	Colors256
+	// Colors16 is the 8 basic colors and their bright variants.
+	Colors16
+	// NoColor is a terminal without color, such as TERM=dumb.
+	NoColor
+	// Discord is the 8 foreground colors of Discord's ansi code blocks.
+	Discord
+)
+
+// String returns the name of the color depth, as /validate reports it:
+// truecolor, 256, 16, none or discord.
+func (d ColorDepth) String() string {
+	switch d {
+	case TrueColor:
+		return "truecolor"
+	case Colors256:
+		return "256"
+	case Colors16:
+		return "16"
+	case NoColor:
+		return "none"
+	case Discord:
+		return "discord"
+	default:
+		return fmt.Sprintf("ColorDepth(%d)", int(d))
+	}
+}
+
+// discordPalette holds the colors Discord draws for foregrounds 30-37.
+var discordPalette = [8]Color{
+	{79, 84, 92}, {220, 50, 47}, {133, 153, 0}, {181, 137, 0},
+	{38, 139, 210}, {211, 54, 130}, {42, 161, 152}, {255, 255, 255},
+}
+
+// monochromeTerms are terminal types known to lack color.
+var monochromeTerms = []string{"dumb", "unknown", "vt52", "vt100", "vt102", "vt220"}
+
+// basicTerms are terminal types known to stop at the 16 basic colors.
+var basicTerms = []string{"ansi", "cygwin", "linux", "rxvt", "screen", "tmux", "xterm", "xterm-color", "xterm-16color"}
+
+// TermColorDepth guesses the colors a terminal shows from its TERM name.
+// Names ending in -256color get the 256 color palette, and truecolor or
+// 24bit, as COLORTERM holds on terminals that support it, get full color.
+// Unknown names, including an empty one, are assumed to be modern
+// terminals with full color.
+//
+// Parameters:
+//   - term: the TERM or COLORTERM value
+//
+// Returns:
+//   - ColorDepth: the colors the terminal is expected to show
+//
+// Example:
+//
+//	depth := TermColorDepth(os.Getenv("TERM"))
+func TermColorDepth(term string) ColorDepth {
+	term = strings.ToLower(strings.TrimSpace(term))
+	for _, name := range monochromeTerms {
+		if term == name {
+			return NoColor
+		}
+	}
+	for _, name := range basicTerms {
+		if term == name {
+			return Colors16
+		}
+	}
+	if strings.Contains(term, "256color") {
+		return Colors256
+	}
+	return TrueColor
+}
+
+// trueColor matches the foreground and background escape sequences
+// Color.Foreground and Color.Background write.
+var trueColor = regexp.MustCompile(`\x1b\[([34])8;2;(\d{1,3});(\d{1,3});(\d{1,3})m`)
+
+// ReduceColors rewrites the truecolor foregrounds and backgrounds in
+// colorized output for a terminal of the given depth: each becomes the
+// closest palette color, or is removed along with the resets for NoColor.
+// Discord's code blocks have no backgrounds to speak of, so they're
+// removed for Discord. Other escape sequences are left alone.
+//
+// Parameters:
+//   - output: text colorized by Colorize or ColorizeLayout
+//   - depth: the colors the terminal shows
+//
+// Returns:
+//   - string: the output with its colors reduced
+//
+// Example:
+//
+//	out := ReduceColors(ColorizeLayout(layout, scheme, 0), TermColorDepth("xterm-256color"))
+func ReduceColors(output string, depth ColorDepth) string {
+	if depth == TrueColor || !strings.Contains(output, "\x1b[") {
+		return output
+	}
+	if depth == NoColor {
+		return strings.ReplaceAll(trueColor.ReplaceAllString(output, ""), AnsiReset, "")
+	}
+	return trueColor.ReplaceAllStringFunc(output, func(seq string) string {
+		m := trueColor.FindStringSubmatch(seq)
+		// 3 for foregrounds, 4 for backgrounds
+		layer := m[1]
+		var rgb [3]uint8
+		for i := range rgb {
+			v, _ := strconv.Atoi(m[i+2])
+			rgb[i] = uint8(min(v, 255))
+		}
+		c := Color{R: rgb[0], G: rgb[1], B: rgb[2]}
+		if depth == Discord {
+			if layer == "4" {
+				return ""
+			}
+			return "\033[" + strconv.Itoa(30+nearest(c, discordPalette[:])) + "m"
+		}
+		if depth == Colors16 {
+			i := nearest(c, xtermBasic[:])
+			base := 30
+			if layer == "4" {
+				base = 40
+			}
+			if i < 8 {
+				return "\033[" + strconv.Itoa(base+i) + "m"
+			}
+			return "\033[" + strconv.Itoa(base+60+i-8) + "m"
+		}
+		return "\033[" + layer + "8;5;" + strconv.Itoa(index256(c)) + "m"
+	})
+}
+
+// index256 returns the xterm 256 color index closest to c, from the color
+// cube or the gray ramp.
+func index256(c Color) int {
+	level := func(v uint8) int {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		default:
+			return (int(v) - 35) / 40
+		}
+	}
+	cube := 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	gray := 232 + min(max((avg-3)/10, 0), 23)
+
+	if distance(c, xterm256(gray)) < distance(c, xterm256(cube)) {
+		return gray
+	}
+	return cube
+}
+
+// nearest returns the index of the palette color closest to c.
+func nearest(c Color, palette []Color) int {
+	best := 0
+	for i, p := range palette {
+		if distance(c, p) < distance(c, palette[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// distance returns the squared distance between two colors.
+func distance(a, b Color) int {
+	dr, dg, db := int(a.R)-int(b.R), int(a.G)-int(b.G), int(a.B)-int(b.B)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,63 @@
+package render
+
+import "testing"
+
+func TestTermColorDepth(t *testing.T) {
+	tests := []struct {
+		term string
+		want ColorDepth
+	}{
+		{term: "", want: TrueColor},
+		{term: "truecolor", want: TrueColor},
+		{term: "xterm-kitty", want: TrueColor},
+		{term: "xterm-256color", want: Colors256},
+		{term: "screen-256color", want: Colors256},
+		{term: "xterm", want: Colors16},
+		{term: "linux", want: Colors16},
+		{term: "dumb", want: NoColor},
+		{term: " VT100 ", want: NoColor},
+	}
+
+	for _, tt := range tests {
+		if got := TermColorDepth(tt.term); got != tt.want {
+			t.Errorf("TermColorDepth(%q) = %v, want %v", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestReduceColors(t *testing.T) {
+	red := Color{R: 255}.Foreground() + "HI" + AnsiReset
+	orange := Color{R: 255, G: 136}.Foreground() + "HI" + AnsiReset
+	gray := Color{R: 128, G: 128, B: 128}.Foreground() + "HI" + AnsiReset
+
+	tests := []struct {
+		name   string
+		output string
+		depth  ColorDepth
+		want   string
+	}{
+		{name: "truecolor unchanged", output: orange, depth: TrueColor, want: orange},
+		{name: "256 cube", output: red, depth: Colors256, want: "\x1b[38;5;196mHI" + AnsiReset},
+		{name: "256 nearest", output: orange, depth: Colors256, want: "\x1b[38;5;208mHI" + AnsiReset},
+		{name: "256 gray ramp", output: gray, depth: Colors256, want: "\x1b[38;5;244mHI" + AnsiReset},
+		{name: "16 bright", output: red, depth: Colors16, want: "\x1b[91mHI" + AnsiReset},
+		{name: "16 basic", output: Color{R: 0, G: 200, B: 200}.Foreground() + "HI", depth: Colors16, want: "\x1b[36mHI"},
+		{name: "discord", output: red, depth: Discord, want: "\x1b[31mHI" + AnsiReset},
+		{name: "discord gray", output: gray, depth: Discord, want: "\x1b[30mHI" + AnsiReset},
+		{name: "no color", output: red + "\n" + orange, depth: NoColor, want: "HI\nHI"},
+		{name: "other sequences kept", output: "\x1b[H" + red, depth: NoColor, want: "\x1b[HHI"},
+		{name: "plain text", output: "HI", depth: Colors16, want: "HI"},
+		{name: "256 background", output: Color{B: 255}.Background() + red, depth: Colors256, want: "\x1b[48;5;21m\x1b[38;5;196mHI" + AnsiReset},
+		{name: "16 background", output: Color{B: 255}.Background() + red, depth: Colors16, want: "\x1b[44m\x1b[91mHI" + AnsiReset},
+		{name: "discord drops backgrounds", output: Color{B: 255}.Background() + red, depth: Discord, want: "\x1b[31mHI" + AnsiReset},
+		{name: "no color drops backgrounds", output: Color{B: 255}.Background() + red, depth: NoColor, want: "HI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReduceColors(tt.output, tt.depth); got != tt.want {
+				t.Errorf("ReduceColors() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package render
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// figSignature is the magic prefix of every FIGlet 2 font file.
+const figSignature = "flf2a"
+
+// Parsing limits keep a corrupt or hostile font file from exhausting memory
+// or CPU. They are far above what any real FIGlet font needs.
+const (
+	maxFontHeight  = 64      // rows per glyph
+	maxGlyphWidth  = 256     // columns per glyph row
+	maxFontLines   = 200000  // lines in the whole file
+	maxFontGlyphs  = 65536   // glyphs in the font
+	maxFontLineLen = 8 << 10 // bytes per line
+)
+
+// deutschRunes are the non-ASCII characters that follow the printable ASCII
+// glyphs in every FIGlet font, in file order.
+var deutschRunes = []rune{196, 214, 220, 228, 246, 252, 223}
+
+// figFont is a parsed FIGlet font.
+type figFont struct {
+	hardblank rune
+	height    int
+	baseline  int
+	reverse   bool
+	glyphs    map[rune][]string
+}
+
+// fontScanner reads font lines, failing once the file exceeds maxFontLines.
+type fontScanner struct {
+	*bufio.Scanner
+	lines int
+	err   error
+}
+
+func newFontScanner(r io.Reader) *fontScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxFontLineLen)
+	return &fontScanner{Scanner: scanner}
+}
+
+// Scan advances to the next line, enforcing the line limit.
+func (s *fontScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.lines >= maxFontLines {
+		s.err = fmt.Errorf("font file exceeds %d lines", maxFontLines)
+		return false
+	}
+	if !s.Scanner.Scan() {
+		return false
+	}
+	s.lines++
+	return true
+}
+
+// Err returns the first limit or read error encountered.
+func (s *fontScanner) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.Scanner.Err()
+}
+
+// parseFIGFont reads a FIGlet (.flf) font.
+// Glyph rows are padded to a uniform width so every glyph occupies a
+// rectangular block of cells, which keeps column spans stable across rows.
+// Files exceeding the parsing limits are rejected.
+func parseFIGFont(r io.Reader) (*figFont, error) {
+	scanner := newFontScanner(r)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty font file")
+	}
+	font, commentLines, err := parseFIGHeader(scanner.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < commentLines; i++ {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("font truncated in comment block")
+		}
+	}
+
+	// Required glyphs: printable ASCII followed by the Deutsch set. Older fonts
+	// sometimes omit the Deutsch glyphs, so only ASCII is mandatory.
+	for code := rune(32); code <= 126; code++ {
+		glyph, err := readFIGGlyph(scanner, font.height)
+		if err != nil {
+			return nil, fmt.Errorf("glyph %q: %w", code, err)
+		}
+		font.glyphs[code] = glyph
+	}
+	for _, code := range deutschRunes {
+		glyph, err := readFIGGlyph(scanner, font.height)
+		if err != nil {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return font, nil
+		}
+		font.glyphs[code] = glyph
+	}
+
+	// Code-tagged glyphs: a line with the character code, then the glyph.
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		code, err := parseFIGCode(strings.Fields(line)[0])
+		if err != nil {
+			break
+		}
+		glyph, err := readFIGGlyph(scanner, font.height)
+		if err != nil {
+			break
+		}
+		if code >= 0 {
+			if len(font.glyphs) >= maxFontGlyphs {
+				return nil, fmt.Errorf("font has more than %d glyphs", maxFontGlyphs)
+			}
+			font.glyphs[rune(code)] = glyph
+		}
+	}
+
+	// A truncated trailing glyph is tolerated, but hitting a limit is not
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return font, nil
+}
+
+// parseFIGHeader parses the first line of a font file, e.g.
+// "flf2a$ 6 5 16 15 11 0 24463", returning the font skeleton and the
+// number of comment lines that follow it.
+func parseFIGHeader(header string) (*figFont, int, error) {
+	if !strings.HasPrefix(header, figSignature) || len(header) <= len(figSignature) {
+		return nil, 0, fmt.Errorf("invalid FIGlet header")
+	}
+
+	fields := strings.Fields(header[len(figSignature):])
+	if len(fields) < 6 {
+		return nil, 0, fmt.Errorf("invalid FIGlet header: expected at least 6 fields, got %d", len(fields))
+	}
+
+	nums := make([]int, len(fields))
+	for i := 1; i < len(fields); i++ {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid FIGlet header field %d: %w", i, err)
+		}
+		nums[i] = n
+	}
+
+	height, baseline, commentLines := nums[1], nums[2], nums[5]
+	if height < 1 || height > maxFontHeight {
+		return nil, 0, fmt.Errorf("invalid FIGlet header: height must be between 1 and %d, got %d", maxFontHeight, height)
+	}
+	if baseline < 1 || baseline > height {
+		return nil, 0, fmt.Errorf("invalid FIGlet header: baseline must be between 1 and height, got %d", baseline)
+	}
+	if commentLines < 0 || commentLines > maxFontLines {
+		return nil, 0, fmt.Errorf("invalid FIGlet header: invalid comment line count %d", commentLines)
+	}
+
+	font := &figFont{
+		hardblank: []rune(fields[0])[0],
+		height:    height,
+		baseline:  baseline,
+		reverse:   len(nums) > 6 && nums[6] == 1,
+		glyphs:    make(map[rune][]string),
+	}
+	return font, commentLines, nil
+}
+
+// readFIGGlyph reads one glyph of the given height, stripping the endmark
+// characters that terminate each row.
+func readFIGGlyph(scanner *fontScanner, height int) ([]string, error) {
+	rows := make([]string, height)
+	width := 0
+	for i := range rows {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unexpected end of font file")
+		}
+		rows[i] = stripEndmark(scanner.Text())
+		if n := StringWidth(rows[i]); n > width {
+			width = n
+		}
+	}
+	if width > maxGlyphWidth {
+		return nil, fmt.Errorf("glyph wider than %d columns", maxGlyphWidth)
+	}
+
+	for i, row := range rows {
+		if pad := width - StringWidth(row); pad > 0 {
+			rows[i] = row + strings.Repeat(" ", pad)
+		}
+	}
+	return rows, nil
+}
+
+// stripEndmark removes trailing whitespace and the run of endmark
+// characters (usually '@') from a glyph row.
+func stripEndmark(row string) string {
+	row = strings.TrimRight(row, " \t\r\n")
+	if row == "" {
+		return row
+	}
+	runes := []rune(row)
+	end := runes[len(runes)-1]
+	k := len(runes)
+	for k > 0 && runes[k-1] == end {
+		k--
+	}
+	return string(runes[:k])
+}
+
+// parseFIGCode parses a code tag in decimal, octal (leading 0) or hex (0x).
+func parseFIGCode(s string) (int64, error) {
+	return strconv.ParseInt(s, 0, 32)
+}
+
+// GlyphSpan records where one source character was drawn in a Layout.
+type GlyphSpan struct {
+	// Index is the position of the character in the source text, in runes.
+	Index int
+	// Line is the source text line the character belongs to.
+	Line int
+	// Start and End delimit the columns [Start, End) occupied by the glyph.
+	Start, End int
+}
+
+// Layout is rendered ASCII art together with the position of every glyph.
+// It lets later stages (such as per-word coloring) map output cells back
+// to the characters that produced them.
+type Layout struct {
+	// Rows holds the rendered output rows, right-trimmed.
+	Rows []string
+	// RowLines maps each output row to its source text line.
+	RowLines []int
+	// Spans lists the glyphs of each source line in drawing order.
+	Spans [][]GlyphSpan
+	// Text is the source text the layout was produced from.
+	Text []rune
+	// Hardblanks lists, for each output row, the columns the font drew
+	// with its hardblank, which Rows holds as spaces.
+	Hardblanks [][]int
+}
+
+// String joins the layout rows, terminating every row with a newline.
+func (l *Layout) String() string {
+	var b strings.Builder
+	for _, row := range l.Rows {
+		b.WriteString(row)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// GlyphAt returns the glyph drawn at the given output row and column.
+func (l *Layout) GlyphAt(row, col int) (GlyphSpan, bool) {
+	if row < 0 || row >= len(l.RowLines) {
+		return GlyphSpan{}, false
+	}
+	for _, span := range l.Spans[l.RowLines[row]] {
+		if col >= span.Start && col < span.End {
+			return span, true
+		}
+	}
+	return GlyphSpan{}, false
+}
+
+// layout draws text with the font. Each source line becomes a separate
+// banner stacked below the previous one. Glyphs are placed side by side at
+// full width; characters missing from the font are drawn as '?'.
+func (f *figFont) layout(text string) *Layout {
+	l, _ := f.layoutContext(context.Background(), text)
+	return l
+}
+
+// layoutContext is layout with cancellation, checked before each source
+// line is drawn.
+func (f *figFont) layoutContext(ctx context.Context, text string) (*Layout, error) {
+	l := &Layout{Text: []rune(text)}
+
+	index := 0
+	for lineNum, line := range strings.Split(text, "\n") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		runes := []rune(line)
+		indexes := make([]int, len(runes))
+		for i := range runes {
+			indexes[i] = index + i
+		}
+		index += len(runes) + 1
+
+		if f.reverse {
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+				indexes[i], indexes[j] = indexes[j], indexes[i]
+			}
+		}
+
+		glyphs := make([][]string, len(runes))
+		spans := make([]GlyphSpan, len(runes))
+		col := 0
+		for i, r := range runes {
+			glyph := f.glyph(r)
+			glyphs[i] = glyph
+			width := StringWidth(glyph[0])
+			spans[i] = GlyphSpan{Index: indexes[i], Line: lineNum, Start: col, End: col + width}
+			col += width
+		}
+		l.Spans = append(l.Spans, spans)
+
+		for row := 0; row < f.height; row++ {
+			var b strings.Builder
+			for _, glyph := range glyphs {
+				b.WriteString(glyph[row])
+			}
+			printed := strings.TrimRight(strings.ReplaceAll(b.String(), string(f.hardblank), " "), " ")
+			if row < f.baseline || printed != "" {
+				l.Rows = append(l.Rows, printed)
+				l.RowLines = append(l.RowLines, lineNum)
+				l.Hardblanks = append(l.Hardblanks, hardblankColumns(b.String(), f.hardblank))
+			}
+		}
+	}
+
+	return l, nil
+}
+
+// hardblankColumns returns the columns of row drawn with hardblank.
+func hardblankColumns(row string, hardblank rune) []int {
+	var cols []int
+	for col, cell := range Cells(row) {
+		if cell == string(hardblank) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// glyph returns the glyph for r, substituting '?' for missing characters.
+func (f *figFont) glyph(r rune) []string {
+	if r == '\t' {
+		r = ' '
+	}
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	return f.glyphs['?']
+}
@@ -0,0 +1,216 @@
+package render
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// testFont is a tiny two-row font with glyphs for ' ', 'A', 'B' and a
+// code-tagged 'é'. All other printable ASCII glyphs are '?' boxes.
+func testFont(t *testing.T, header string) *figFont {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString(header + "\n")
+	b.WriteString("comment line\n")
+	for code := 32; code <= 126; code++ {
+		switch code {
+		case ' ':
+			b.WriteString("$@\n$@@\n")
+		case 'A':
+			b.WriteString("/\\@\n||@@\n")
+		case 'B':
+			b.WriteString("B)@\nB)@@\n")
+		default:
+			b.WriteString("??@\n??@@\n")
+		}
+	}
+	for range deutschRunes {
+		b.WriteString("DD@\nDD@@\n")
+	}
+	b.WriteString("233  LATIN SMALL LETTER E WITH ACUTE\ne'@\ne_@@\n")
+
+	font, err := parseFIGFont(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("parseFIGFont failed: %v", err)
+	}
+	return font
+}
+
+func TestParseFIGFont(t *testing.T) {
+	font := testFont(t, "flf2a$ 2 2 3 0 1")
+
+	if font.height != 2 || font.baseline != 2 {
+		t.Errorf("height/baseline = %d/%d, want 2/2", font.height, font.baseline)
+	}
+	if font.hardblank != '$' {
+		t.Errorf("hardblank = %q, want $", font.hardblank)
+	}
+	if got := font.glyphs['A']; got[0] != "/\\" || got[1] != "||" {
+		t.Errorf("glyph A = %q", got)
+	}
+	if _, ok := font.glyphs[196]; !ok {
+		t.Error("Deutsch glyphs not loaded")
+	}
+	if got := font.glyphs[233]; got[0] != "e'" {
+		t.Errorf("code-tagged glyph = %q", got)
+	}
+}
+
+func TestParseFIGFontErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"bad signature", "tlf2a$ 2 2 3 0 0\n"},
+		{"too few fields", "flf2a$ 2 2\n"},
+		{"non-numeric field", "flf2a$ x 2 3 0 0\n"},
+		{"zero height", "flf2a$ 0 0 3 0 0\n"},
+		{"truncated comments", "flf2a$ 2 2 3 0 5\nonly one\n"},
+		{"truncated glyphs", "flf2a$ 2 2 3 0 0\n$@\n$@@\n"},
+		{"height too large", "flf2a$ 65 2 3 0 0\n"},
+		{"zero baseline", "flf2a$ 2 0 3 0 0\n"},
+		{"baseline below height", "flf2a$ 2 3 3 0 0\n"},
+		{"too many comment lines", "flf2a$ 2 2 3 0 999999999\n"},
+		{"glyph too wide", "flf2a$ 1 1 3 0 0\n" + strings.Repeat("#", maxGlyphWidth+1) + "@@\n"},
+		{"line too long", "flf2a$ 1 1 3 0 0\n" + strings.Repeat("#", maxFontLineLen+1) + "@@\n"},
+		{"too many lines", "flf2a$ 1 1 3 0 0\n" + strings.Repeat("$@@\n", 95) + strings.Repeat("\n", maxFontLines)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseFIGFont(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func FuzzParseFIGFont(f *testing.F) {
+	f.Add([]byte("flf2a$ 2 2 3 0 0\n" + strings.Repeat("ab@\ncd@@\n", 102) + "233\nx@\ny@@\n"))
+	f.Add([]byte("flf2a$ 1 1 3 0 1 1\ncomment\n" + strings.Repeat("$@@\n", 95) + "0x41\n\x00@@\n"))
+	f.Add([]byte("flf2a"))
+	for _, name := range []string{"standard", "small"} {
+		if data, err := os.ReadFile("../fonts/" + name + ".flf"); err == nil {
+			f.Add(data)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		font, err := parseFIGFont(strings.NewReader(string(data)))
+		if err != nil {
+			return
+		}
+		if font.height < 1 || font.height > maxFontHeight || len(font.glyphs) > maxFontGlyphs {
+			t.Fatalf("parsed font violates limits: height=%d glyphs=%d", font.height, len(font.glyphs))
+		}
+
+		l := font.layout("Hi é\n\x00\t?")
+		if len(l.Rows) > 2*font.height {
+			t.Fatalf("layout has %d rows for a 2-line text with height %d", len(l.Rows), font.height)
+		}
+		for _, row := range l.Rows {
+			if n := len([]rune(row)); n > 7*maxGlyphWidth {
+				t.Fatalf("row of %d columns exceeds glyph width limit", n)
+			}
+		}
+	})
+}
+
+func TestStripEndmark(t *testing.T) {
+	tests := map[string]string{
+		"abc@":    "abc",
+		"abc@@":   "abc",
+		"abc##  ": "abc",
+		"":        "",
+		"  @":     "  ",
+	}
+	for in, want := range tests {
+		if got := stripEndmark(in); got != want {
+			t.Errorf("stripEndmark(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFIGFontLayout(t *testing.T) {
+	font := testFont(t, "flf2a$ 2 2 3 0 1")
+
+	t.Run("glyphs placed side by side", func(t *testing.T) {
+		l := font.layout("AB")
+		if got := l.String(); got != "/\\B)\n||B)\n" {
+			t.Errorf("layout = %q", got)
+		}
+		span, ok := l.GlyphAt(1, 2)
+		if !ok || span.Index != 1 || span.Start != 2 || span.End != 4 {
+			t.Errorf("GlyphAt(1,2) = %+v, %v", span, ok)
+		}
+	})
+
+	t.Run("hardblank becomes space", func(t *testing.T) {
+		l := font.layout("A A")
+		if got := l.Rows[0]; got != "/\\ /\\" {
+			t.Errorf("row = %q", got)
+		}
+	})
+
+	t.Run("missing characters become question marks", func(t *testing.T) {
+		l := font.layout("世")
+		if got := l.Rows[0]; got != "??" {
+			t.Errorf("row = %q", got)
+		}
+	})
+
+	t.Run("extended characters use tagged glyphs", func(t *testing.T) {
+		l := font.layout("é")
+		if got := l.Rows[0]; got != "e'" {
+			t.Errorf("row = %q", got)
+		}
+	})
+
+	t.Run("newlines stack banner lines", func(t *testing.T) {
+		l := font.layout("A\nB")
+		if len(l.Rows) != 4 {
+			t.Fatalf("expected 4 rows, got %d", len(l.Rows))
+		}
+		if l.RowLines[3] != 1 {
+			t.Errorf("row 3 should belong to line 1, got %d", l.RowLines[3])
+		}
+		span, ok := l.GlyphAt(2, 0)
+		if !ok || span.Index != 2 || span.Line != 1 {
+			t.Errorf("GlyphAt(2,0) = %+v, %v", span, ok)
+		}
+	})
+
+	t.Run("right-to-left fonts reverse text", func(t *testing.T) {
+		rtl := testFont(t, "flf2a$ 2 2 3 0 1 1")
+		l := rtl.layout("AB")
+		if got := l.Rows[0]; got != "B)/\\" {
+			t.Errorf("row = %q", got)
+		}
+		if span, _ := l.GlyphAt(0, 0); span.Index != 1 {
+			t.Errorf("first glyph should be source index 1, got %d", span.Index)
+		}
+	})
+}
+
+func TestParseRepositoryFonts(t *testing.T) {
+	for _, name := range []string{"standard", "doom", "big", "small", "slant", "shadow", "3d", "bloody"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open("../fonts/" + name + ".flf")
+			if err != nil {
+				t.Skipf("font not available: %v", err)
+			}
+			defer f.Close()
+
+			font, err := parseFIGFont(f)
+			if err != nil {
+				t.Fatalf("parseFIGFont failed: %v", err)
+			}
+			if len(font.glyphs) < 95 {
+				t.Errorf("expected at least 95 glyphs, got %d", len(font.glyphs))
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package render
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ryanlewis/shout-sh/types"
@@ -9,48 +10,107 @@ import (
 const DefaultFont = "standard"
 
 // GenerateASCII generates ASCII art from text using the specified font.
-// If the requested font is not available, it falls back to the default font.
+// If the requested font is not available, the closest matching font name is
+// used (see FontCache.SuggestFont), falling back to the default font.
 // If no fonts are loaded at all, it returns an error.
 //
+// Deprecated: use GenerateASCIIContext, which stops when the request is
+// cancelled or times out.
+func GenerateASCII(text string, opts types.RenderOptions, cache *FontCache) (string, error) {
+	return GenerateASCIIContext(context.Background(), text, opts, cache)
+}
+
+// GenerateASCIIContext generates ASCII art from text using the specified
+// font. If the requested font is not available, the closest matching font
+// name is used (see FontCache.SuggestFont), falling back to the default
+// font. When ctx is done before rendering finishes, the returned error
+// wraps ctx.Err().
+//
 // Parameters:
+//   - ctx: bounds how long rendering may take
 //   - text: the text to render as ASCII art
 //   - opts: rendering options including font selection
 //   - cache: the font cache containing loaded fonts
 //
 // Returns:
 //   - string: the generated ASCII art
-//   - error: error if generation fails or no fonts are available
+//   - error: error if generation fails, no fonts are available or ctx is done
 //
 // Example:
 //
-//	ascii, err := GenerateASCII("HELLO", opts, fontCache)
+//	ascii, err := GenerateASCIIContext(ctx, "HELLO", opts, fontCache)
 //	if err != nil {
 //	    log.Printf("Failed to generate ASCII: %v", err)
 //	    return
 //	}
 //	fmt.Println(ascii)
-func GenerateASCII(text string, opts types.RenderOptions, cache *FontCache) (string, error) {
+func GenerateASCIIContext(ctx context.Context, text string, opts types.RenderOptions, cache *FontCache) (string, error) {
+	layout, err := GenerateLayoutContext(ctx, text, opts, cache)
+	if err != nil || layout == nil {
+		return "", err
+	}
+	return layout.String(), nil
+}
+
+// GenerateLayout renders text like GenerateASCII but returns the full Layout,
+// including the position of every glyph. It returns a nil layout for empty text.
+//
+// Deprecated: use GenerateLayoutContext, which stops when the request is
+// cancelled or times out.
+func GenerateLayout(text string, opts types.RenderOptions, cache *FontCache) (*Layout, error) {
+	return GenerateLayoutContext(context.Background(), text, opts, cache)
+}
+
+// GenerateLayoutContext renders text like GenerateASCIIContext but returns
+// the full Layout, including the position of every glyph. It returns a nil
+// layout for empty text. Cancellation is checked between stages and before
+// each line is drawn; when ctx is done before rendering finishes, the
+// returned error wraps ctx.Err(), so callers can detect a timeout with
+// errors.Is(err, context.DeadlineExceeded).
+//
+// Parameters:
+//   - ctx: bounds how long rendering may take
+//   - text: the text to render as ASCII art
+//   - opts: rendering options including font, engine and fill
+//   - cache: the font cache containing loaded fonts
+//
+// Returns:
+//   - *Layout: the rendered layout, nil for empty text
+//   - error: error if generation fails, no fonts are available or ctx is done
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(c.UserContext(), 2*time.Second)
+//	defer cancel()
+//	layout, err := GenerateLayoutContext(ctx, text, opts, fontCache)
+func GenerateLayoutContext(ctx context.Context, text string, opts types.RenderOptions, cache *FontCache) (*Layout, error) {
 	// Validate cache
 	if cache == nil {
-		return "", fmt.Errorf("font cache is nil")
+		return nil, fmt.Errorf("font cache is nil")
 	}
 
 	// Handle empty text
 	if text == "" {
-		return "", nil
+		return nil, nil
 	}
 
-	// Try to get the requested font, falling back to default
-	font := cache.GetFontOrDefault(opts.Font, DefaultFont)
+	// Try to get the requested font, then the closest match, then the
+	// default; the block engine draws with its own
+	font := cache.EngineFont(opts, DefaultFont)
 	if font == nil {
-		return "", fmt.Errorf("no fonts loaded")
+		return nil, fmt.Errorf("no fonts loaded")
 	}
 
-	// Render the text using the selected font
-	ascii, err := font.Render(text)
+	// Translate input through control files, then render with the selected font
+	text = cache.ApplyControls(text)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to render text: %w", err)
+	}
+	layout, err := font.LayoutContext(ctx, text)
 	if err != nil {
-		return "", fmt.Errorf("failed to render text: %w", err)
+		return nil, fmt.Errorf("failed to render text: %w", err)
 	}
+	FillLayout(layout, opts.Fill, opts.Hardblank)
 
-	return ascii, nil
+	return layout, nil
 }
@@ -1,6 +1,8 @@
 package render
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -176,6 +178,55 @@ func TestGenerateASCII_EmptyCache(t *testing.T) {
 	}
 }
 
+func TestGenerateLayoutContext_Cancelled(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard"}}); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GenerateLayoutContext(ctx, "TEST", types.RenderOptions{Font: "standard"}, cache)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	layout, err := GenerateLayoutContext(context.Background(), "TEST", types.RenderOptions{Font: "standard"}, cache)
+	if err != nil || layout == nil {
+		t.Errorf("expected layout with live context, got %v, %v", layout, err)
+	}
+}
+
+func TestGenerateASCIIContext(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard"}}); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+	font, _ := cache.GetFont("standard")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GenerateASCIIContext(ctx, "TEST", types.RenderOptions{Font: "standard"}, cache); !errors.Is(err, context.Canceled) {
+		t.Errorf("GenerateASCIIContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := font.RenderContext(ctx, "TEST"); !errors.Is(err, context.Canceled) {
+		t.Errorf("RenderContext() error = %v, want context.Canceled", err)
+	}
+
+	// The deprecated wrappers render as before
+	want, err := GenerateASCIIContext(context.Background(), "TEST", types.RenderOptions{Font: "standard"}, cache)
+	if err != nil || want == "" {
+		t.Fatalf("GenerateASCIIContext() = %q, %v", want, err)
+	}
+	if got, err := GenerateASCII("TEST", types.RenderOptions{Font: "standard"}, cache); got != want || err != nil {
+		t.Errorf("GenerateASCII() = %q, %v, want %q", got, err, want)
+	}
+	if got, err := font.Render("TEST"); got != want || err != nil {
+		t.Errorf("Render() = %q, %v, want %q", got, err, want)
+	}
+}
+
 func TestGenerateASCII_ConcurrentAccess(t *testing.T) {
 	// Setup: Load fonts for testing
 	cache := NewFontCache()
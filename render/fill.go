@@ -0,0 +1,81 @@
+package render
+
+import "github.com/ryanlewis/shout-sh/types"
+
+// FillLayout redraws the glyphs of a layout in one character: every
+// drawn cell, whatever character the font draws it with, becomes fill.
+// With types.FillText each glyph is drawn in its own character instead, so
+// HI is drawn with H and I; characters that aren't one column wide, such
+// as CJK, are drawn with #. Spaces are kept so the glyphs keep their
+// shape, and so are the font's hardblanks unless hardblank names a
+// character to draw them with instead.
+//
+// Parameters:
+//   - l: the layout to modify in place
+//   - fill: the character to draw with, types.FillText, or "" to leave the
+//     glyphs as they are
+//   - hardblank: the character to draw the font's hardblanks with, or ""
+//     for spaces
+//
+// Example:
+//
+//	render.FillLayout(layout, "█", ".")
+func FillLayout(l *Layout, fill, hardblank string) {
+	if l == nil {
+		return
+	}
+	if fill != "" {
+		fillGlyphs(l, fill)
+	}
+	if hardblank != "" {
+		fillHardblanks(l, hardblank)
+	}
+}
+
+// fillGlyphs draws the glyphs of l in fill, as FillLayout does.
+func fillGlyphs(l *Layout, fill string) {
+
+	cellOf := func(index int) string {
+		if fill != types.FillText {
+			return fill
+		}
+		if index < len(l.Text) {
+			if r := string(l.Text[index]); r != " " && StringWidth(r) == 1 {
+				return r
+			}
+		}
+		return "#"
+	}
+
+	for row, line := range l.RowLines {
+		cells := Cells(l.Rows[row])
+		for _, span := range l.Spans[line] {
+			cell := cellOf(span.Index)
+			for col := span.Start; col < span.End && col < len(cells); col++ {
+				// Both columns of a wide character are drawn
+				if cells[col] != " " {
+					cells[col] = cell
+				}
+			}
+		}
+		l.Rows[row] = JoinCells(cells)
+	}
+}
+
+// fillHardblanks draws the cells of l the font drew with its hardblank in
+// hardblank, padding rows they were trimmed from.
+func fillHardblanks(l *Layout, hardblank string) {
+	for row, cols := range l.Hardblanks {
+		if len(cols) == 0 || row >= len(l.Rows) {
+			continue
+		}
+		cells := Cells(l.Rows[row])
+		for _, col := range cols {
+			for len(cells) <= col {
+				cells = append(cells, " ")
+			}
+			cells[col] = hardblank
+		}
+		l.Rows[row] = JoinCells(cells)
+	}
+}
@@ -0,0 +1,69 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestFillLayout(t *testing.T) {
+	tests := []struct {
+		name string
+		fill string
+		want []string
+	}{
+		{name: "none", fill: "", want: []string{"/\\|)", "/\\|)"}},
+		{name: "block", fill: "█", want: []string{"████", "████"}},
+		{name: "text", fill: types.FillText, want: []string{"AABB", "AABB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &Layout{
+				Rows:     []string{"/\\|)", "/\\|)"},
+				RowLines: []int{0, 0},
+				Spans:    [][]GlyphSpan{{{Index: 0, Start: 0, End: 2}, {Index: 1, Start: 2, End: 4}}},
+				Text:     []rune("AB"),
+			}
+			FillLayout(layout, tt.fill, "")
+			if !reflect.DeepEqual(layout.Rows, tt.want) {
+				t.Errorf("rows = %q, want %q", layout.Rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillLayoutFont(t *testing.T) {
+	font := wideFont(t)
+	layout := font.layout("A A日")
+	FillLayout(layout, types.FillText, "")
+
+	// Spaces, and the hardblanks of the space glyph, stay blank; wide
+	// characters are drawn with #
+	want := []string{"AA  AA##", "AA  AA##"}
+	if !reflect.DeepEqual(layout.Rows, want) {
+		t.Errorf("rows = %q, want %q", layout.Rows, want)
+	}
+
+	// Masking after filling hides the letters of masked words
+	MaskLayout(layout, []WordRange{{Start: 2, End: 3}})
+	if strings.Contains(layout.Rows[0][4:], "A") {
+		t.Errorf("masked row = %q, shows the masked letter", layout.Rows[0])
+	}
+
+	// Hardblanks are drawn in their own character, trailing ones too
+	layout = font.layout("A ")
+	FillLayout(layout, "#", ".")
+	want = []string{"##..", "##.."}
+	if !reflect.DeepEqual(layout.Rows, want) {
+		t.Errorf("rows with hardblanks = %q, want %q", layout.Rows, want)
+	}
+	layout = font.layout("A ")
+	FillLayout(layout, "", ".")
+	want = []string{"/\\..", "||.."}
+	if !reflect.DeepEqual(layout.Rows, want) {
+		t.Errorf("rows with hardblanks, unfilled = %q, want %q", layout.Rows, want)
+	}
+}
@@ -0,0 +1,243 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Word filter actions, configured with SHOUT_TEXT_FILTER.
+const (
+	// FilterAllow renders text unchanged; the filter is off.
+	FilterAllow = "allow"
+	// FilterReject refuses to render text containing blocked words.
+	FilterReject = "reject"
+	// FilterMask renders blocked words as solid blocks.
+	FilterMask = "mask"
+)
+
+// MaskRune replaces the drawn cells of masked words.
+const MaskRune = '▓'
+
+// leetFold maps common character substitutions back to letters so
+// "h3ll0" matches "hello".
+var leetFold = map[rune]rune{'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's'}
+
+// WordFilter finds blocked words in text. Matching is case-insensitive,
+// on whole words, and ignores common digit-for-letter substitutions.
+//
+// Usage example:
+//
+//	filter := NewWordFilter([]string{"darn"})
+//	if matches := filter.Match("DARN IT"); len(matches) > 0 {
+//	    // reject or mask
+//	}
+type WordFilter struct {
+	words map[string]struct{}
+}
+
+// WordRange is the rune range [Start, End) of a matched word in the text.
+type WordRange struct {
+	Start, End int
+}
+
+// NewWordFilter creates a filter for the given words. Empty entries are
+// ignored.
+//
+// Parameters:
+//   - words: the blocked words
+//
+// Returns:
+//   - *WordFilter: the filter
+//
+// Example:
+//
+//	filter := NewWordFilter([]string{"darn", "heck"})
+func NewWordFilter(words []string) *WordFilter {
+	f := &WordFilter{words: make(map[string]struct{}, len(words))}
+	for _, w := range words {
+		if w = foldWord([]rune(strings.TrimSpace(w))); w != "" {
+			f.words[w] = struct{}{}
+		}
+	}
+	return f
+}
+
+// LoadWordFilter builds the filter described by the text configuration:
+// the words in cfg.FilterWords plus those in cfg.FilterFile (one per line,
+// "#" starts a comment). It returns nil when filtering is off.
+//
+// Parameters:
+//   - cfg: text settings with the filter action and word sources
+//
+// Returns:
+//   - *WordFilter: the filter, or nil if cfg.Filter is "allow" or no words are configured
+//   - error: error if the word list file cannot be read
+//
+// Example:
+//
+//	filter, err := LoadWordFilter(cfg.Text)
+//	if err != nil {
+//	    log.Fatalf("Failed to load word filter: %v", err)
+//	}
+func LoadWordFilter(cfg config.TextConfig) (*WordFilter, error) {
+	if cfg.Filter == "" || cfg.Filter == FilterAllow {
+		return nil, nil
+	}
+
+	words := append([]string(nil), cfg.FilterWords...)
+	if cfg.FilterFile != "" {
+		file, err := os.Open(cfg.FilterFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open word list: %w", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				words = append(words, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read word list: %w", err)
+		}
+	}
+
+	filter := NewWordFilter(words)
+	if len(filter.words) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// Match returns the positions of blocked words in text. A nil filter
+// matches nothing.
+//
+// Parameters:
+//   - text: the text to check
+//
+// Returns:
+//   - []WordRange: the rune ranges of blocked words, in order
+//
+// Example:
+//
+//	for _, m := range filter.Match(text) {
+//	    fmt.Println(string([]rune(text)[m.Start:m.End]))
+//	}
+func (f *WordFilter) Match(text string) []WordRange {
+	if f == nil || len(f.words) == 0 {
+		return nil
+	}
+
+	var matches []WordRange
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && isWordRune(runes[i]) {
+			i++
+		}
+		if _, blocked := f.words[foldWord(runes[start:i])]; blocked {
+			matches = append(matches, WordRange{Start: start, End: i})
+		}
+	}
+	return matches
+}
+
+// isWordRune reports whether r can be part of a word, including the
+// symbols used as letter substitutes.
+func isWordRune(r rune) bool {
+	_, leet := leetFold[r]
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || leet
+}
+
+// foldWord lowercases a word and undoes letter substitutions.
+func foldWord(word []rune) string {
+	var b strings.Builder
+	for _, r := range word {
+		if folded, ok := leetFold[r]; ok {
+			r = folded
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// MaskText replaces the characters of text in ranges with MaskRune, as
+// MaskLayout masks a drawn banner, for text shown as it is. Spaces are
+// kept.
+//
+// Parameters:
+//   - text: the text the ranges were matched in
+//   - ranges: rune ranges of text to mask
+//
+// Returns:
+//   - string: the masked text
+//
+// Example:
+//
+//	MaskText("oh darn", filter.Match("oh darn")) // "oh ▓▓▓▓"
+func MaskText(text string, ranges []WordRange) string {
+	if len(ranges) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	for _, r := range ranges {
+		for i := max(r.Start, 0); i < r.End && i < len(runes); i++ {
+			if !unicode.IsSpace(runes[i]) {
+				runes[i] = MaskRune
+			}
+		}
+	}
+	return string(runes)
+}
+
+// MaskLayout replaces the drawn cells of the given source characters with
+// MaskRune, so blocked words render as solid blocks in any font. Spaces
+// are kept so the blocks follow the shape of the glyphs.
+//
+// Parameters:
+//   - l: the layout to modify in place
+//   - ranges: rune ranges of the source text to mask
+//
+// Example:
+//
+//	render.MaskLayout(layout, filter.Match(text))
+func MaskLayout(l *Layout, ranges []WordRange) {
+	if l == nil || len(ranges) == 0 {
+		return
+	}
+
+	masked := func(index int) bool {
+		for _, r := range ranges {
+			if index >= r.Start && index < r.End {
+				return true
+			}
+		}
+		return false
+	}
+
+	for row, line := range l.RowLines {
+		cells := Cells(l.Rows[row])
+		for _, span := range l.Spans[line] {
+			if !masked(span.Index) {
+				continue
+			}
+			for col := span.Start; col < span.End && col < len(cells); col++ {
+				if cells[col] != " " {
+					cells[col] = string(MaskRune)
+				}
+			}
+		}
+		l.Rows[row] = JoinCells(cells)
+	}
+}
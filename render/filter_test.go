@@ -0,0 +1,116 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestWordFilterMatch(t *testing.T) {
+	filter := NewWordFilter([]string{"darn", " Heck ", ""})
+
+	tests := []struct {
+		text string
+		want []WordRange
+	}{
+		{text: "HELLO", want: nil},
+		{text: "darn", want: []WordRange{{0, 4}}},
+		{text: "OH DARN IT", want: []WordRange{{3, 7}}},
+		{text: "d4rn", want: []WordRange{{0, 4}}},
+		{text: "what the h3ck, darn!", want: []WordRange{{9, 13}, {15, 19}}},
+		{text: "darnation", want: nil},
+		{text: "CHECK", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := filter.Match(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Match(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Match(%q)[%d] = %v, want %v", tt.text, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	var nilFilter *WordFilter
+	if got := nilFilter.Match("darn"); got != nil {
+		t.Errorf("nil filter should match nothing, got %v", got)
+	}
+}
+
+func TestLoadWordFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# blocked words\ndarn\n\n  heck  \n"), 0644); err != nil {
+		t.Fatalf("Failed to write word list: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       config.TextConfig
+		wantNil   bool
+		wantErr   bool
+		wantMatch string
+	}{
+		{name: "allow disables filter", cfg: config.TextConfig{Filter: "allow", FilterWords: []string{"darn"}}, wantNil: true},
+		{name: "no words", cfg: config.TextConfig{Filter: "reject"}, wantNil: true},
+		{name: "inline words", cfg: config.TextConfig{Filter: "mask", FilterWords: []string{"darn"}}, wantMatch: "darn"},
+		{name: "word file", cfg: config.TextConfig{Filter: "reject", FilterFile: path}, wantMatch: "heck"},
+		{name: "missing file", cfg: config.TextConfig{Filter: "reject", FilterFile: "/nonexistent/words.txt"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := LoadWordFilter(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (filter == nil) != tt.wantNil {
+				t.Fatalf("filter = %v, wantNil %v", filter, tt.wantNil)
+			}
+			if tt.wantMatch != "" && len(filter.Match(tt.wantMatch)) != 1 {
+				t.Errorf("expected %q to be blocked", tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestMaskLayout(t *testing.T) {
+	font := testFont(t, "flf2a$ 2 2 3 0 1")
+	l := font.layout("AB A")
+
+	MaskLayout(l, []WordRange{{Start: 0, End: 2}})
+
+	// "AB" is masked, the space and the final "A" are untouched
+	if got, want := l.Rows[0], "▓▓▓▓ /\\"; got != want {
+		t.Errorf("row 0 = %q, want %q", got, want)
+	}
+	if got, want := l.Rows[1], "▓▓▓▓ ||"; got != want {
+		t.Errorf("row 1 = %q, want %q", got, want)
+	}
+	if strings.ContainsRune(l.String(), 'B') {
+		t.Error("masked glyph still visible")
+	}
+
+	MaskLayout(nil, []WordRange{{0, 1}})
+}
+
+func TestMaskText(t *testing.T) {
+	filter := NewWordFilter([]string{"darn"})
+	text := "oh darn it, DARN"
+	if got, want := MaskText(text, filter.Match(text)), "oh ▓▓▓▓ it, ▓▓▓▓"; got != want {
+		t.Errorf("MaskText() = %q, want %q", got, want)
+	}
+	if got := MaskText(text, nil); got != text {
+		t.Errorf("MaskText() without ranges = %q, want %q", got, text)
+	}
+}
@@ -1,65 +1,226 @@
 package render
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"sync"
 
-	"github.com/ryanlewis/go-figure"
 	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/fonts"
 )
 
+// EmbeddedFonts is the fallback font source used when a font is missing
+// from the configured fonts directory.
+var EmbeddedFonts fs.FS = fonts.FS
+
 // Font represents a loaded FIGlet font ready for rendering.
 //
 // Usage example:
 //
 //	font, exists := cache.GetFont("doom")
 //	if exists {
-//	    output, err := font.Render("HELLO")
+//	    output, err := font.RenderContext(ctx, "HELLO")
 //	}
 type Font struct {
 	Name     string
 	fontPath string
+	// fsys is set for embedded fonts; fontPath is then relative to it.
+	fsys fs.FS
+	// parsed holds the parsed font in memory; nil parses on every use.
+	parsed *parsedFonts
+	gen    uint64
+	// built is set for fonts drawn in code rather than read from a file,
+	// such as BlockFont.
+	built *figFont
+
+	sumOnce sync.Once
+	sum     string
+	sumErr  error
+}
+
+// Embedded reports whether the font is served from the embedded font set
+// rather than the fonts directory on disk.
+func (f *Font) Embedded() bool {
+	return f.fsys != nil
+}
+
+// open opens the font file from disk or the embedded font set.
+func (f *Font) open() (io.ReadCloser, error) {
+	if f.fsys != nil {
+		return f.fsys.Open(f.fontPath)
+	}
+	return os.Open(f.fontPath)
+}
+
+// Checksum returns the hex SHA-256 of the font file. It is computed on
+// first use and remembered; a reload creates new Font values, so a changed
+// file gets a new checksum.
+//
+// Returns:
+//   - string: the hex-encoded checksum
+//   - error: error if the font file cannot be read
+//
+// Example:
+//
+//	sum, err := font.Checksum()
+func (f *Font) Checksum() (string, error) {
+	f.sumOnce.Do(func() {
+		file, err := f.open()
+		if err != nil {
+			f.sumErr = fmt.Errorf("failed to open font file: %w", err)
+			return
+		}
+		defer file.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, file); err != nil {
+			f.sumErr = fmt.Errorf("failed to read font file: %w", err)
+			return
+		}
+		f.sum = hex.EncodeToString(h.Sum(nil))
+	})
+	return f.sum, f.sumErr
 }
 
 // Render generates ASCII art text using this font.
 //
+// Deprecated: use RenderContext, which stops when the request is
+// cancelled or times out.
+func (f *Font) Render(text string) (string, error) {
+	return f.RenderContext(context.Background(), text)
+}
+
+// RenderContext generates ASCII art text using this font, stopping with
+// the context's error once ctx is done.
+//
 // Parameters:
+//   - ctx: bounds how long rendering may take
 //   - text: the text to render
 //
 // Returns:
 //   - string: the rendered ASCII art
-//   - error: error if rendering fails
+//   - error: error if rendering fails or ctx is done
 //
 // Example:
 //
-//	output, err := font.Render("HELLO")
+//	output, err := font.RenderContext(ctx, "HELLO")
 //	if err != nil {
 //	    // handle error
 //	}
 //	fmt.Println(output)
-func (f *Font) Render(text string) (string, error) {
-	if f == nil {
-		return "", fmt.Errorf("font is nil")
+func (f *Font) RenderContext(ctx context.Context, text string) (string, error) {
+	layout, err := f.LayoutContext(ctx, text)
+	if err != nil {
+		return "", err
 	}
+	return layout.String(), nil
+}
+
+// Layout renders text with this font and records where each source
+// character was drawn, so callers can style the output per character,
+// word or line.
+//
+// Deprecated: use LayoutContext, which stops when the request is
+// cancelled or times out.
+func (f *Font) Layout(text string) (*Layout, error) {
+	return f.LayoutContext(context.Background(), text)
+}
 
-	// Open font file
-	file, err := os.Open(f.fontPath)
+// LayoutContext renders text with this font and records where each
+// source character was drawn, so callers can style the output per
+// character, word or line. It stops and returns the context's error once
+// ctx is done, checked after the font is parsed and before each line.
+//
+// Parameters:
+//   - ctx: bounds how long rendering may take
+//   - text: the text to render; newlines start a new banner line
+//
+// Returns:
+//   - *Layout: the rendered rows and glyph positions
+//   - error: error if the font cannot be read or parsed, or ctx is done
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	layout, err := font.LayoutContext(ctx, "HELLO WORLD")
+//	if err != nil {
+//	    // handle error
+//	}
+//	span, ok := layout.GlyphAt(0, 3)
+func (f *Font) LayoutContext(ctx context.Context, text string) (*Layout, error) {
+	parsed, err := f.figFont()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parsed.layoutContext(ctx, text)
+}
+
+// MissingRunes returns the characters of text the font has no glyph for,
+// which are drawn as '?', each once in the order they first appear. Line
+// breaks are never missing, and tabs are drawn as spaces.
+//
+// Parameters:
+//   - text: the text to check, after any control file translation
+//
+// Returns:
+//   - []rune: the missing characters, nil if the font has them all
+//   - error: error if the font cannot be read or parsed
+//
+// Example:
+//
+//	missing, err := font.MissingRunes("naïve")
+//	if err == nil && len(missing) > 0 {
+//	    log.Printf("%s lacks %q", font.Name, string(missing))
+//	}
+func (f *Font) MissingRunes(text string) ([]rune, error) {
+	parsed, err := f.figFont()
 	if err != nil {
-		return "", fmt.Errorf("failed to open font file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	var missing []rune
+	for _, r := range text {
+		if r == '\n' || r == '\r' || r == '\t' || slices.Contains(missing, r) {
+			continue
+		}
+		if _, ok := parsed.glyphs[r]; !ok {
+			missing = append(missing, r)
+		}
+	}
+	return missing, nil
+}
 
-	// Create figure with custom font
-	fig := figure.NewFigureWithFont(text, file, true)
-	return fig.String(), nil
+// figFont returns the parsed font, from the cache's memory budget when
+// the font is registered with one.
+func (f *Font) figFont() (*figFont, error) {
+	if f == nil {
+		return nil, fmt.Errorf("font is nil")
+	}
+	if f.built != nil {
+		return f.built, nil
+	}
+	if f.parsed != nil {
+		return f.parsed.get(f)
+	}
+	return f.parse()
 }
 
 // FontCache manages loaded fonts with thread-safe access.
-// Fonts are loaded once and cached for the lifetime of the application.
+// Fonts are registered by LoadFonts and parsed lazily on first use. Parsed
+// fonts stay in memory within the configured memory budget; the least
+// recently used ones are evicted and re-parsed when needed again.
 //
 // The type is safe for concurrent use.
 //
@@ -72,8 +233,10 @@ func (f *Font) Render(text string) (string, error) {
 //	}
 //	font := cache.GetFontOrDefault("doom", "standard")
 type FontCache struct {
-	mu    sync.RWMutex
-	fonts map[string]*Font
+	mu       sync.RWMutex
+	fonts    map[string]*Font
+	controls []*ControlFile
+	parsed   *parsedFonts
 }
 
 // NewFontCache creates a new empty font cache.
@@ -86,13 +249,19 @@ type FontCache struct {
 //	cache := NewFontCache()
 func NewFontCache() *FontCache {
 	return &FontCache{
-		fonts: make(map[string]*Font),
+		fonts:  make(map[string]*Font),
+		parsed: newParsedFonts(),
 	}
 }
 
-// LoadFonts loads all configured fonts from disk into the cache.
+// LoadFonts loads all configured fonts from disk into the cache, replacing
+// any previously loaded set. Calling it again reloads the fonts directory.
 // Fonts that fail to load are logged but don't cause the function to fail.
 // This ensures the service can start even if some fonts are missing.
+// When cfg.Embedded is set, fonts missing from disk fall back to the copies
+// embedded in the binary; fonts on disk always take precedence. Fonts listed
+// in cfg.Remote are downloaded once into cfg.CacheDir and served from there.
+// Reloading empties the parsed font cache and applies cfg.MemoryBudget.
 //
 // Parameters:
 //   - cfg: font configuration with paths and allowed fonts
@@ -107,34 +276,112 @@ func NewFontCache() *FontCache {
 //	    log.Fatal("Failed to load fonts:", err)
 //	}
 func (fc *FontCache) LoadFonts(cfg config.FontConfig) error {
-	fc.mu.Lock()
-	defer fc.mu.Unlock()
-
-	loadedCount := 0
+	// Build the new font set without holding the lock so renders in progress
+	// aren't blocked by disk access, then swap it in atomically.
+	loaded := make(map[string]*Font, len(cfg.Allowed))
 
 	for _, fontName := range cfg.Allowed {
 		fontPath := filepath.Join(cfg.Path, fontName+".flf")
 
 		// Validate font file exists and is readable
 		if err := ValidateFont(fontPath); err != nil {
+			if cfg.Embedded && embeddedFontExists(fontName) {
+				loaded[fontName] = &Font{
+					Name:     fontName,
+					fontPath: fontName + ".flf",
+					fsys:     EmbeddedFonts,
+				}
+				log.Printf("Loaded embedded font: %s", fontName)
+				continue
+			}
 			log.Printf("Warning: Could not load font %s: %v", fontName, err)
 			continue
 		}
 
 		// Store font with path for on-demand loading
-		fc.fonts[fontName] = &Font{
+		loaded[fontName] = &Font{
 			Name:     fontName,
 			fontPath: fontPath,
 		}
-
-		loadedCount++
 		log.Printf("Loaded font: %s", fontName)
 	}
 
-	log.Printf("Loaded %d fonts successfully", loadedCount)
+	// Remote fonts are registered by being configured, so they bypass the
+	// allowed list, but never shadow a local font of the same name
+	for _, spec := range cfg.Remote {
+		rf, err := ParseRemoteFont(spec)
+		if err != nil {
+			log.Printf("Warning: Invalid remote font %q: %v", spec, err)
+			continue
+		}
+		if _, exists := loaded[rf.Name]; exists {
+			log.Printf("Warning: Remote font %s ignored, a local font has the same name", rf.Name)
+			continue
+		}
+		fontPath, err := FetchRemoteFont(rf, cfg)
+		if err != nil {
+			log.Printf("Warning: Could not load remote font %s: %v", rf.Name, err)
+			continue
+		}
+		loaded[rf.Name] = &Font{
+			Name:     rf.Name,
+			fontPath: fontPath,
+		}
+		log.Printf("Loaded remote font: %s", rf.Name)
+	}
+
+	log.Printf("Loaded %d fonts successfully", len(loaded))
+
+	// Load control files; like fonts, a broken one is skipped with a warning
+	var controls []*ControlFile
+	for _, name := range cfg.Controls {
+		ctrl, err := LoadControlFile(filepath.Join(cfg.Path, name+".flc"))
+		if err != nil {
+			log.Printf("Warning: Could not load control file %s: %v", name, err)
+			continue
+		}
+		controls = append(controls, ctrl)
+		log.Printf("Loaded control file: %s", name)
+	}
+
+	// Fonts may have changed on disk, so drop everything parsed from the old set
+	gen := fc.parsed.reset(cfg.MemoryBudget)
+	for _, font := range loaded {
+		font.parsed = fc.parsed
+		font.gen = gen
+	}
+
+	fc.mu.Lock()
+	fc.fonts = loaded
+	fc.controls = controls
+	fc.mu.Unlock()
+
 	return nil
 }
 
+// ApplyControls translates text through the loaded control files, in the
+// order they were configured. Text is returned unchanged when no control
+// files are loaded.
+//
+// Parameters:
+//   - text: the input text
+//
+// Returns:
+//   - string: the translated text
+//
+// Example:
+//
+//	text = cache.ApplyControls(text)
+func (fc *FontCache) ApplyControls(text string) string {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	for _, ctrl := range fc.controls {
+		text = ctrl.Apply(text)
+	}
+	return text
+}
+
 // GetFont retrieves a font from the cache by name.
 //
 // Parameters:
@@ -190,6 +437,53 @@ func (fc *FontCache) GetFontOrDefault(name, defaultName string) *Font {
 	return nil
 }
 
+// ResolveFont picks the font used to render a request: the named font if
+// it is loaded, otherwise the closest match (see SuggestFont), otherwise
+// the default font.
+//
+// Parameters:
+//   - name: the requested font name
+//   - defaultName: the font to fall back to
+//
+// Returns:
+//   - *Font: the font to render with, nil if neither is loaded
+//
+// Example:
+//
+//	font := cache.ResolveFont(opts.Font, "standard")
+func (fc *FontCache) ResolveFont(name, defaultName string) *Font {
+	if font, exists := fc.GetFont(name); exists {
+		return font
+	}
+	if suggestion, ok := fc.SuggestFont(name); ok {
+		name = suggestion
+	}
+	return fc.GetFontOrDefault(name, defaultName)
+}
+
+// ControlsChecksum identifies the loaded control files and their contents,
+// so cached output can be invalidated when they change.
+//
+// Returns:
+//   - string: a hex checksum, or "" when no control files are loaded
+//
+// Example:
+//
+//	etagInput += cache.ControlsChecksum()
+func (fc *FontCache) ControlsChecksum() string {
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	if len(fc.controls) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, ctrl := range fc.controls {
+		io.WriteString(h, ctrl.sum)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // ListFonts returns a sorted list of all loaded font names.
 //
 // Returns:
@@ -214,6 +508,15 @@ func (fc *FontCache) ListFonts() []string {
 	return names
 }
 
+// embeddedFontExists reports whether the embedded font set contains a font.
+func embeddedFontExists(name string) bool {
+	if EmbeddedFonts == nil || !fs.ValidPath(name+".flf") {
+		return false
+	}
+	info, err := fs.Stat(EmbeddedFonts, name+".flf")
+	return err == nil && !info.IsDir()
+}
+
 // ValidateFont checks if a font file exists and is readable.
 // This function verifies that the file exists, is a regular file (not a directory),
 // and can be opened for reading.
@@ -346,3 +346,130 @@ func TestLoadFontsEmptyAllowedList(t *testing.T) {
 		t.Errorf("Expected no fonts with empty allowed list, got %d", len(cache.fonts))
 	}
 }
+
+func TestLoadFontsEmbeddedFallback(t *testing.T) {
+	cfg := config.FontConfig{
+		Path:     "/nonexistent/path",
+		Allowed:  []string{"standard", "doom", "not-bundled"},
+		Embedded: true,
+	}
+
+	cache := NewFontCache()
+	if err := cache.LoadFonts(cfg); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	font, exists := cache.GetFont("standard")
+	if !exists {
+		t.Fatal("standard font should fall back to the embedded copy")
+	}
+	if !font.Embedded() {
+		t.Error("fallback font should report Embedded() == true")
+	}
+	if _, exists := cache.GetFont("not-bundled"); exists {
+		t.Error("fonts that are not bundled should not be loaded")
+	}
+
+	output, err := font.Render("HI")
+	if err != nil || output == "" {
+		t.Errorf("embedded font failed to render: %q, %v", output, err)
+	}
+}
+
+func TestLoadFontsDiskOverridesEmbedded(t *testing.T) {
+	// Install small.flf under the name "standard" so the two are distinguishable
+	dir := t.TempDir()
+	data, err := os.ReadFile("../fonts/small.flf")
+	if err != nil {
+		t.Fatalf("Failed to read font: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "standard.flf"), data, 0644); err != nil {
+		t.Fatalf("Failed to write font: %v", err)
+	}
+
+	cache := NewFontCache()
+	err = cache.LoadFonts(config.FontConfig{Path: dir, Allowed: []string{"standard"}, Embedded: true})
+	if err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	font, _ := cache.GetFont("standard")
+	if font == nil || font.Embedded() {
+		t.Fatal("disk font should take precedence over the embedded one")
+	}
+
+	got, _ := font.Render("HI")
+	small := &Font{Name: "small", fontPath: "../fonts/small.flf"}
+	want, _ := small.Render("HI")
+	if got != want {
+		t.Error("expected the disk copy to be rendered")
+	}
+}
+
+func TestFontChecksum(t *testing.T) {
+	disk := &Font{Name: "standard", fontPath: "../fonts/standard.flf"}
+	embedded := &Font{Name: "standard", fontPath: "standard.flf", fsys: EmbeddedFonts}
+	other := &Font{Name: "doom", fontPath: "../fonts/doom.flf"}
+
+	sum, err := disk.Checksum()
+	if err != nil || len(sum) != 64 {
+		t.Fatalf("Checksum() = %q, %v", sum, err)
+	}
+	if embeddedSum, _ := embedded.Checksum(); embeddedSum != sum {
+		t.Error("embedded copy should have the same checksum as the file on disk")
+	}
+	if otherSum, _ := other.Checksum(); otherSum == sum {
+		t.Error("different fonts should have different checksums")
+	}
+
+	missing := &Font{Name: "missing", fontPath: "/nonexistent/missing.flf"}
+	if _, err := missing.Checksum(); err == nil {
+		t.Error("expected error for missing font file")
+	}
+}
+
+func TestFontCacheResolveFont(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard", "doom"}}); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"doom":    "doom",
+		"dooom":   "doom",
+		"zzzzzz":  "standard",
+		"":        "standard",
+		"Standar": "standard",
+	}
+	for name, want := range tests {
+		if got := cache.ResolveFont(name, "standard"); got == nil || got.Name != want {
+			t.Errorf("ResolveFont(%q) = %v, want %s", name, got, want)
+		}
+	}
+
+	if got := NewFontCache().ResolveFont("doom", "standard"); got != nil {
+		t.Errorf("expected nil from empty cache, got %v", got)
+	}
+}
+
+func TestFontMissingRunes(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard"}}); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+	font, _ := cache.GetFont("standard")
+
+	tests := map[string]string{
+		"HELLO WORLD":  "",
+		"Grüße":        "",
+		"♥ snow ☃ ♥":   "♥☃",
+		"A\tB\r\nC":    "",
+		"日本, 日本 again": "日本",
+	}
+	for text, want := range tests {
+		missing, err := font.MissingRunes(text)
+		if err != nil || string(missing) != want {
+			t.Errorf("MissingRunes(%q) = %q, %v, want %q", text, string(missing), err, want)
+		}
+	}
+}
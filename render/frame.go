@@ -0,0 +1,164 @@
+package render
+
+import "strings"
+
+// borderChars are the characters a border style is drawn with.
+type borderChars struct {
+	topLeft, topRight, bottomLeft, bottomRight, horizontal, vertical string
+}
+
+// borderStyles are the border styles of the border option.
+var borderStyles = map[string]borderChars{
+	"single":  {"┌", "┐", "└", "┘", "─", "│"},
+	"double":  {"╔", "╗", "╚", "╝", "═", "║"},
+	"rounded": {"╭", "╮", "╰", "╯", "─", "│"},
+}
+
+// Frame is the box a banner is drawn in: a border, padding inside it and
+// a color behind it. The zero value draws no box.
+//
+// Usage example:
+//
+//	accent := Color{R: 255, G: 215}
+//	out := FrameOutput(ColorizeLayout(layout, scheme, 0), Frame{Border: "rounded", Padding: 1, Accent: &accent})
+type Frame struct {
+	// Border is the border style: single, double or rounded; other values,
+	// such as "" or none, draw no border
+	Border string
+	// Padding is the space between the banner and the edge of the box:
+	// Padding lines above and below and, as terminal cells are about twice
+	// as tall as they are wide, twice as many columns either side
+	Padding int
+	// Accent colors the border; nil leaves it uncolored
+	Accent *Color
+	// Background is the color behind the box; nil leaves it transparent
+	Background *Color
+	// Width, when above 0, is the terminal width the box is aligned within
+	Width int
+	// Align places the box within Width: left, center or right
+	Align string
+}
+
+// Drawn reports whether the frame draws anything around a banner.
+func (f Frame) Drawn() bool {
+	_, bordered := borderStyles[f.Border]
+	return bordered || f.Padding > 0 || f.Background != nil
+}
+
+// Extra returns the columns the frame adds to the width of a banner.
+func (f Frame) Extra() int {
+	extra := 4 * f.Padding
+	if _, bordered := borderStyles[f.Border]; bordered {
+		extra += 2
+	}
+	return extra
+}
+
+// Background returns the ANSI truecolor escape sequence that sets this
+// color as the terminal background color.
+//
+// Returns:
+//   - string: the escape sequence, e.g. "\033[48;2;255;0;0m"
+func (c Color) Background() string {
+	return "\033[48" + strings.TrimPrefix(c.Foreground(), "\033[38")
+}
+
+// FrameOutput draws colorized output in a frame. Each line keeps its
+// colors; with a Background, resets within a line restore it, so the
+// box is filled behind the banner's own colors. The box is as wide as
+// the widest line, and placed within frame.Width by frame.Align; lines
+// are aligned within the box beforehand, as with AlignLayout.
+//
+// Parameters:
+//   - output: rendered output, such as ColorizeLayout returns
+//   - frame: the box to draw
+//
+// Returns:
+//   - string: the framed output, ending in a newline, or output as it is
+//     when frame draws nothing
+//
+// Example:
+//
+//	out := FrameOutput(" _ \n|_|\n", Frame{Border: "single"})
+//	// "┌───┐\n│ _ │\n│|_|│\n└───┘\n"
+func FrameOutput(output string, frame Frame) string {
+	if !frame.Drawn() {
+		return output
+	}
+	style, bordered := borderStyles[frame.Border]
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, StringWidth(StripANSI(line)))
+	}
+	padX := 2 * frame.Padding
+	inner := width + 2*padX
+
+	bg := ""
+	if frame.Background != nil {
+		bg = frame.Background.Background()
+	}
+	border := func(s string) string {
+		if !bordered {
+			return ""
+		}
+		if frame.Accent == nil {
+			return s
+		}
+		return frame.Accent.Foreground() + s + AnsiReset + bg
+	}
+	// Lines are filled to the edge of the box only where something shows
+	// there: a border or a background
+	filled := bordered || bg != ""
+
+	margin := ""
+	if frame.Width > 0 && (frame.Align == "center" || frame.Align == "right") {
+		indent := max(frame.Width-width-frame.Extra(), 0)
+		if frame.Align == "center" {
+			indent /= 2
+		}
+		margin = strings.Repeat(" ", indent)
+	}
+
+	var b strings.Builder
+	row := func(text string) {
+		if bg != "" {
+			text = strings.TrimSuffix(bg+text, bg)
+			if !strings.HasSuffix(text, AnsiReset) {
+				text += AnsiReset
+			}
+		}
+		b.WriteString(margin + text + "\n")
+	}
+	padding := ""
+	if filled {
+		padding = border(style.vertical) + strings.Repeat(" ", inner) + border(style.vertical)
+	}
+
+	if bordered {
+		row(border(style.topLeft + strings.Repeat(style.horizontal, inner) + style.topRight))
+	}
+	for range frame.Padding {
+		row(padding)
+	}
+	for _, line := range lines {
+		lineWidth := StringWidth(StripANSI(line))
+		if bg != "" {
+			line = strings.ReplaceAll(line, AnsiReset, AnsiReset+bg)
+		}
+		switch {
+		case filled:
+			line = border(style.vertical) + strings.Repeat(" ", padX) + line + strings.Repeat(" ", width-lineWidth+padX) + border(style.vertical)
+		case lineWidth > 0:
+			line = strings.Repeat(" ", padX) + line
+		}
+		row(line)
+	}
+	for range frame.Padding {
+		row(padding)
+	}
+	if bordered {
+		row(border(style.bottomLeft + strings.Repeat(style.horizontal, inner) + style.bottomRight))
+	}
+	return b.String()
+}
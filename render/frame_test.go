@@ -0,0 +1,103 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFrameOutput(t *testing.T) {
+	banner := " _ \n|_|\n"
+	gold, navy := Color{R: 255, G: 215}, Color{B: 128}
+
+	tests := []struct {
+		name  string
+		frame Frame
+		want  string
+	}{
+		{name: "no frame", frame: Frame{}, want: banner},
+		{name: "none draws no border", frame: Frame{Border: "none"}, want: banner},
+		{
+			name:  "single border",
+			frame: Frame{Border: "single"},
+			want:  "┌───┐\n│ _ │\n│|_|│\n└───┘\n",
+		},
+		{
+			name:  "rounded border with padding",
+			frame: Frame{Border: "rounded", Padding: 1},
+			want:  "╭───────╮\n│       │\n│   _   │\n│  |_|  │\n│       │\n╰───────╯\n",
+		},
+		{
+			name:  "padding alone",
+			frame: Frame{Padding: 1},
+			want:  "\n   _ \n  |_|\n\n",
+		},
+		{
+			name:  "centered within the terminal",
+			frame: Frame{Border: "double", Width: 11, Align: "center"},
+			want:  "   ╔═══╗\n   ║ _ ║\n   ║|_|║\n   ╚═══╝\n",
+		},
+		{
+			name:  "right within the terminal",
+			frame: Frame{Border: "double", Width: 11, Align: "right"},
+			want:  "      ╔═══╗\n      ║ _ ║\n      ║|_|║\n      ╚═══╝\n",
+		},
+		{
+			name:  "accent",
+			frame: Frame{Border: "single", Accent: &gold},
+			want: gold.Foreground() + "┌───┐" + AnsiReset + "\n" +
+				gold.Foreground() + "│" + AnsiReset + " _ " + gold.Foreground() + "│" + AnsiReset + "\n" +
+				gold.Foreground() + "│" + AnsiReset + "|_|" + gold.Foreground() + "│" + AnsiReset + "\n" +
+				gold.Foreground() + "└───┘" + AnsiReset + "\n",
+		},
+		{
+			name:  "background",
+			frame: Frame{Background: &navy},
+			want:  navy.Background() + " _ " + AnsiReset + "\n" + navy.Background() + "|_|" + AnsiReset + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FrameOutput(banner, tt.frame); got != tt.want {
+				t.Errorf("FrameOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameOutputKeepsBackground(t *testing.T) {
+	red, navy := Color{R: 255}, Color{B: 128}
+	banner := red.Foreground() + "A" + AnsiReset + "B\n" + "C\n"
+
+	got := FrameOutput(banner, Frame{Background: &navy})
+	// The banner's resets bring the background back, and the short line
+	// is filled out to the width of the box
+	want := navy.Background() + red.Foreground() + "A" + AnsiReset + navy.Background() + "B" + AnsiReset + "\n" +
+		navy.Background() + "C " + AnsiReset + "\n"
+	if got != want {
+		t.Errorf("FrameOutput() = %q, want %q", got, want)
+	}
+	if plain := ReduceColors(got, NoColor); plain != "AB\nC \n" {
+		t.Errorf("without colors = %q", plain)
+	}
+	if !strings.HasPrefix(navy.Background(), "\x1b[48;2;0;0;128m") {
+		t.Errorf("Background() = %q", navy.Background())
+	}
+}
+
+func TestFrameExtra(t *testing.T) {
+	tests := []struct {
+		frame Frame
+		want  int
+	}{
+		{frame: Frame{}, want: 0},
+		{frame: Frame{Border: "single"}, want: 2},
+		{frame: Frame{Padding: 2}, want: 8},
+		{frame: Frame{Border: "rounded", Padding: 1}, want: 6},
+	}
+	for _, tt := range tests {
+		if got := tt.frame.Extra(); got != tt.want {
+			t.Errorf("%+v.Extra() = %d, want %d", tt.frame, got, tt.want)
+		}
+	}
+}
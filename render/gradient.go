@@ -0,0 +1,203 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// gradientPrefix starts a gradient color option, as in "gradient:f00-0f0".
+const gradientPrefix = "gradient:"
+
+// GradientDirection is the way a Gradient runs across a banner.
+type GradientDirection string
+
+// Supported gradient directions.
+const (
+	// GradientHorizontal runs from the left edge to the right
+	GradientHorizontal GradientDirection = "h"
+	// GradientVertical runs from the top edge to the bottom
+	GradientVertical GradientDirection = "v"
+	// GradientDiagonal runs from the top left corner to the bottom right
+	GradientDiagonal GradientDirection = "d1"
+	// GradientAntiDiagonal runs from the bottom left corner to the top right
+	GradientAntiDiagonal GradientDirection = "d2"
+	// GradientRadial runs from the center to the edges
+	GradientRadial GradientDirection = "radial"
+)
+
+// gradientFrames is how many animation frames a gradient takes to move
+// from one end to the other.
+const gradientFrames = 20
+
+// cellAspect is how many times taller than wide a terminal cell is, so
+// diagonal and radial gradients look even.
+const cellAspect = 2
+
+// unboundBox is the box a gradient spans until bound to a layout.
+var unboundBox = gradientBox{right: 79, bottom: 7}
+
+// ParseGradientDirection parses the gdir option. An empty value selects
+// GradientHorizontal.
+//
+// Parameters:
+//   - s: the raw option value (h, v, d1, d2 or radial)
+//
+// Returns:
+//   - GradientDirection: the parsed direction
+//   - error: error if the direction is not recognised
+func ParseGradientDirection(s string) (GradientDirection, error) {
+	switch dir := GradientDirection(strings.ToLower(strings.TrimSpace(s))); dir {
+	case "":
+		return GradientHorizontal, nil
+	case GradientHorizontal, GradientVertical, GradientDiagonal, GradientAntiDiagonal, GradientRadial:
+		return dir, nil
+	default:
+		return "", fmt.Errorf("invalid gradient direction: %q (must be h, v, d1, d2 or radial)", s)
+	}
+}
+
+// gradientBox is the bounding box of the drawn cells of a banner, in rows
+// and columns, inclusive.
+type gradientBox struct {
+	left, top, right, bottom int
+}
+
+// Gradient is a ColorScheme that blends between two or more evenly spaced
+// color stops across the banner, in Direction. It spans the bounding box of
+// the drawn cells of a Layout once bound with WithLayout, so the gradient
+// fits the banner wherever alignment puts it; until then it spans 80x8
+// cells. Animation frames move the colors back and forth along it.
+//
+// Usage example:
+//
+//	scheme, _ := ParseColor("gradient:f00-ff0-0f0")
+//	gradient := scheme.(*Gradient)
+//	gradient.Direction = GradientVertical
+//	out := ColorizeLayout(layout, gradient, 0)
+type Gradient struct {
+	Stops     []Color
+	Direction GradientDirection
+
+	box gradientBox
+}
+
+// parseGradient parses the stops of a gradient:... color option, separated
+// by dashes, each a hex color with or without #, rgb() or a color name.
+func parseGradient(spec string) (*Gradient, error) {
+	parts := strings.Split(strings.TrimPrefix(spec, gradientPrefix), "-")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid gradient: %q (needs two or more colors, as in gradient:f00-0f0)", spec)
+	}
+	g := &Gradient{Stops: make([]Color, len(parts)), Direction: GradientHorizontal, box: unboundBox}
+	for i, part := range parts {
+		c, err := ParseSolidColor(part)
+		if err != nil && isHex(part) {
+			c, err = parseHexColor("#" + part)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid gradient: %q: %w", spec, err)
+		}
+		g.Stops[i] = c
+	}
+	return g, nil
+}
+
+// isHex reports whether s is a color in hex digits without the #.
+func isHex(s string) bool {
+	if len(s) != 3 && len(s) != 6 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithLayout returns a copy of the gradient spanning the drawn cells of
+// layout.
+//
+// Parameters:
+//   - layout: the layout the colors will be applied to
+//
+// Returns:
+//   - *Gradient: a bound copy of the gradient
+func (g *Gradient) WithLayout(layout *Layout) *Gradient {
+	bound := &Gradient{Stops: g.Stops, Direction: g.Direction, box: unboundBox}
+	if layout == nil {
+		return bound
+	}
+	found := false
+	for row, text := range layout.Rows {
+		for col, cell := range Cells(text) {
+			if cell == " " || cell == "" {
+				continue
+			}
+			if !found {
+				bound.box = gradientBox{left: col, top: row, right: col, bottom: row}
+				found = true
+			}
+			bound.box.left = min(bound.box.left, col)
+			bound.box.right = max(bound.box.right, col)
+			bound.box.bottom = row
+		}
+	}
+	return bound
+}
+
+// ColorAt implements ColorScheme.
+func (g *Gradient) ColorAt(frame, line, col int) Color {
+	// Moving with the frames, the position bounces between the ends
+	t := math.Mod(g.position(line, col)+float64(frame)/gradientFrames, 2)
+	if t > 1 {
+		t = 2 - t
+	}
+
+	// Blend the two stops either side of t
+	segments := len(g.Stops) - 1
+	pos := t * float64(segments)
+	i := min(int(pos), segments-1)
+	return blend(g.Stops[i], g.Stops[i+1], pos-float64(i))
+}
+
+// position returns how far along the gradient the cell is, from 0 to 1.
+func (g *Gradient) position(line, col int) float64 {
+	b := g.box
+	width, height := float64(b.right-b.left), float64(b.bottom-b.top)*cellAspect
+	x, y := float64(col-b.left), float64(line-b.top)*cellAspect
+
+	var t float64
+	switch g.Direction {
+	case GradientVertical:
+		t = ratio(y, height)
+	case GradientDiagonal:
+		t = ratio(x+y, width+height)
+	case GradientAntiDiagonal:
+		t = ratio(x+height-y, width+height)
+	case GradientRadial:
+		dx, dy := ratio(x, width)*2-1, ratio(y, height)*2-1
+		t = math.Sqrt(dx*dx+dy*dy) / math.Sqrt2
+	default:
+		t = ratio(x, width)
+	}
+	return min(max(t, 0), 1)
+}
+
+// ratio returns n/d, or 0.5 when d is 0 so a box one cell across takes
+// the middle of the gradient.
+func ratio(n, d float64) float64 {
+	if d == 0 {
+		return 0.5
+	}
+	return n / d
+}
+
+// blend mixes a and b, t of the way from a to b.
+func blend(a, b Color, t float64) Color {
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return Color{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B)}
+}
@@ -0,0 +1,121 @@
+package render
+
+import "testing"
+
+func TestParseGradient(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []Color
+		wantErr bool
+	}{
+		{name: "two stops", spec: "gradient:f00-00f", want: []Color{{R: 255}, {B: 255}}},
+		{name: "three stops", spec: "gradient:f00-ff0-0f0", want: []Color{{R: 255}, {R: 255, G: 255}, {G: 255}}},
+		{name: "hash, long hex and names", spec: "Gradient:#ff0000-00ff00-blue", want: []Color{{R: 255}, {G: 255}, {B: 255}}},
+		{name: "hex without a #", spec: "gradient:red-bad", want: []Color{{R: 255}, {R: 187, G: 170, B: 221}}},
+		{name: "one stop", spec: "gradient:f00", wantErr: true},
+		{name: "empty stop", spec: "gradient:f00--0f0", wantErr: true},
+		{name: "bad stop", spec: "gradient:f00-nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, err := ParseColor(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gradient, ok := scheme.(*Gradient)
+			if !ok {
+				t.Fatalf("ParseColor(%q) = %T, want *Gradient", tt.spec, scheme)
+			}
+			if len(gradient.Stops) != len(tt.want) {
+				t.Fatalf("stops = %v, want %v", gradient.Stops, tt.want)
+			}
+			for i, stop := range gradient.Stops {
+				if stop != tt.want[i] {
+					t.Errorf("stop %d = %v, want %v", i, stop, tt.want[i])
+				}
+			}
+			if gradient.Direction != GradientHorizontal {
+				t.Errorf("direction = %q, want %q", gradient.Direction, GradientHorizontal)
+			}
+		})
+	}
+}
+
+func TestParseGradientDirection(t *testing.T) {
+	for _, dir := range []string{"h", "v", "d1", "d2", "radial"} {
+		if got, err := ParseGradientDirection(dir); err != nil || string(got) != dir {
+			t.Errorf("ParseGradientDirection(%q) = %q, %v", dir, got, err)
+		}
+	}
+	if got, err := ParseGradientDirection(""); err != nil || got != GradientHorizontal {
+		t.Errorf("empty direction = %q, %v, want %q", got, err, GradientHorizontal)
+	}
+	if _, err := ParseGradientDirection("sideways"); err == nil {
+		t.Error("expected an error for an unknown direction")
+	}
+}
+
+func TestGradientColorAt(t *testing.T) {
+	red, yellow, green := Color{R: 255}, Color{R: 255, G: 255}, Color{G: 255}
+	// The drawn cells span columns 2 to 6 and rows 0 to 2; the padding on
+	// the left is outside the gradient's box
+	layout := &Layout{Rows: []string{"  #####", "  #   #", "  #####"}}
+
+	tests := []struct {
+		dir      GradientDirection
+		row, col int
+		want     Color
+	}{
+		{dir: GradientHorizontal, row: 1, col: 2, want: red},
+		{dir: GradientHorizontal, row: 1, col: 4, want: yellow},
+		{dir: GradientHorizontal, row: 0, col: 6, want: green},
+		{dir: GradientVertical, row: 0, col: 6, want: red},
+		{dir: GradientVertical, row: 1, col: 2, want: yellow},
+		{dir: GradientVertical, row: 2, col: 2, want: green},
+		{dir: GradientDiagonal, row: 0, col: 2, want: red},
+		{dir: GradientDiagonal, row: 2, col: 6, want: green},
+		{dir: GradientAntiDiagonal, row: 2, col: 2, want: red},
+		{dir: GradientAntiDiagonal, row: 0, col: 6, want: green},
+		{dir: GradientRadial, row: 1, col: 4, want: red},
+		{dir: GradientRadial, row: 0, col: 2, want: green},
+		{dir: GradientRadial, row: 2, col: 6, want: green},
+	}
+
+	for _, tt := range tests {
+		gradient := (&Gradient{Stops: []Color{red, yellow, green}, Direction: tt.dir}).WithLayout(layout)
+		if got := gradient.ColorAt(0, tt.row, tt.col); got != tt.want {
+			t.Errorf("%s at row %d col %d = %v, want %v", tt.dir, tt.row, tt.col, got, tt.want)
+		}
+	}
+
+	gradient := (&Gradient{Stops: []Color{red, green}}).WithLayout(layout)
+	// Halfway along, the stops blend
+	if got := gradient.ColorAt(0, 0, 4); got != (Color{R: 128, G: 128}) {
+		t.Errorf("middle = %v, want an even blend", got)
+	}
+	// Frames move the colors along, and back from the far end
+	if got := gradient.ColorAt(gradientFrames, 0, 2); got != green {
+		t.Errorf("first cell a pass later = %v, want %v", got, green)
+	}
+	if got := gradient.ColorAt(2*gradientFrames, 0, 2); got != red {
+		t.Errorf("first cell two passes later = %v, want %v", got, red)
+	}
+}
+
+func TestColorizeLayoutGradient(t *testing.T) {
+	red, blue := Color{R: 255}, Color{B: 255}
+	scheme, err := ParseColor("gradient:f00-00f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ColorizeLayout(&Layout{Rows: []string{"   ab"}}, scheme, 0)
+	want := "   " + red.Foreground() + "a" + blue.Foreground() + "b" + AnsiReset + "\n"
+	if got != want {
+		t.Errorf("ColorizeLayout() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package render
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// parsedFonts keeps recently used parsed fonts in memory within a byte
+// budget. When the budget is exceeded the least recently used fonts are
+// dropped back to their lazy-load state and re-parsed on next use.
+//
+// The type is safe for concurrent use.
+type parsedFonts struct {
+	mu      sync.Mutex
+	gen     uint64 // incremented by reset; fonts from older loads aren't kept
+	budget  int64
+	used    int64
+	order   *list.List // front is most recently used
+	entries map[*Font]*list.Element
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// parsedEntry is one resident parsed font.
+type parsedEntry struct {
+	font   *Font
+	parsed *figFont
+	size   int64
+}
+
+// CacheStats reports font cache occupancy.
+//
+// Usage example:
+//
+//	stats := cache.Stats()
+//	fmt.Printf("%d/%d bytes used\n", stats.Bytes, stats.Budget)
+type CacheStats struct {
+	// Fonts is the number of registered fonts.
+	Fonts int `json:"fonts"`
+	// Resident is the number of fonts currently held parsed in memory.
+	Resident int `json:"resident"`
+	// Bytes is the estimated memory used by resident fonts.
+	Bytes int64 `json:"bytes"`
+	// Budget is the configured memory budget; 0 means unlimited.
+	Budget int64 `json:"budget"`
+	// Hits counts renders served by a resident font.
+	Hits int64 `json:"hits"`
+	// Misses counts renders that had to parse the font file.
+	Misses int64 `json:"misses"`
+	// Evictions counts fonts dropped to stay within the budget.
+	Evictions int64 `json:"evictions"`
+}
+
+func newParsedFonts() *parsedFonts {
+	return &parsedFonts{
+		order:   list.New(),
+		entries: make(map[*Font]*list.Element),
+	}
+}
+
+// get returns the parsed form of font, parsing and caching it on a miss.
+func (p *parsedFonts) get(font *Font) (*figFont, error) {
+	p.mu.Lock()
+	if elem, ok := p.entries[font]; ok {
+		p.order.MoveToFront(elem)
+		p.mu.Unlock()
+		atomic.AddInt64(&p.hits, 1)
+		return elem.Value.(*parsedEntry).parsed, nil
+	}
+	p.mu.Unlock()
+	atomic.AddInt64(&p.misses, 1)
+
+	// Parse without holding the lock; concurrent misses for the same font
+	// may both parse it, and the first to finish is kept.
+	parsed, err := font.parse()
+	if err != nil {
+		return nil, err
+	}
+	p.put(font, parsed)
+	return parsed, nil
+}
+
+// put stores a parsed font and evicts older ones to fit the budget.
+// Fonts larger than the whole budget are never kept.
+func (p *parsedFonts) put(font *Font, parsed *figFont) {
+	size := parsed.size()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.entries[font]; ok || font.gen != p.gen {
+		return
+	}
+	if p.budget > 0 && size > p.budget {
+		return
+	}
+	p.entries[font] = p.order.PushFront(&parsedEntry{font: font, parsed: parsed, size: size})
+	p.used += size
+	p.evictLocked()
+}
+
+// evictLocked drops least recently used fonts until the budget is met.
+// The caller must hold p.mu.
+func (p *parsedFonts) evictLocked() {
+	for p.budget > 0 && p.used > p.budget {
+		elem := p.order.Back()
+		if elem == nil {
+			return
+		}
+		entry := p.order.Remove(elem).(*parsedEntry)
+		delete(p.entries, entry.font)
+		p.used -= entry.size
+		atomic.AddInt64(&p.evictions, 1)
+	}
+}
+
+// reset drops every resident font, applies a new budget and returns the
+// generation that newly loaded fonts must carry to be cached.
+func (p *parsedFonts) reset(budget int64) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.gen++
+	p.budget = budget
+	p.used = 0
+	p.order.Init()
+	p.entries = make(map[*Font]*list.Element)
+	return p.gen
+}
+
+// size estimates the memory held by a parsed font: the glyph row bytes plus
+// string headers and per-glyph map overhead.
+func (f *figFont) size() int64 {
+	const stringHeader, glyphOverhead = 16, 64
+
+	var n int64
+	for _, rows := range f.glyphs {
+		n += glyphOverhead + int64(len(rows))*stringHeader
+		for _, row := range rows {
+			n += int64(len(row))
+		}
+	}
+	return n
+}
+
+// parse reads and parses the font file.
+func (f *Font) parse() (*figFont, error) {
+	file, err := f.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open font file: %w", err)
+	}
+	defer file.Close()
+
+	parsed, err := parseFIGFont(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font %s: %w", f.Name, err)
+	}
+	return parsed, nil
+}
+
+// Stats reports how many fonts are registered and how much of the memory
+// budget the parsed fonts occupy.
+//
+// Returns:
+//   - CacheStats: a snapshot of the cache occupancy and counters
+//
+// Example:
+//
+//	stats := cache.Stats()
+//	log.Printf("font cache: %d resident, %d evictions", stats.Resident, stats.Evictions)
+func (fc *FontCache) Stats() CacheStats {
+	fc.mu.RLock()
+	registered := len(fc.fonts)
+	fc.mu.RUnlock()
+
+	p := fc.parsed
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return CacheStats{
+		Fonts:     registered,
+		Resident:  len(p.entries),
+		Bytes:     p.used,
+		Budget:    p.budget,
+		Hits:      atomic.LoadInt64(&p.hits),
+		Misses:    atomic.LoadInt64(&p.misses),
+		Evictions: atomic.LoadInt64(&p.evictions),
+	}
+}
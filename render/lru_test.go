@@ -0,0 +1,153 @@
+package render
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// loadedCache returns a cache with the given fonts and memory budget.
+func loadedCache(t *testing.T, budget int64, names ...string) *FontCache {
+	t.Helper()
+
+	cache := NewFontCache()
+	err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: names, MemoryBudget: budget})
+	if err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+	return cache
+}
+
+// renderWith renders text with a named font from the cache.
+func renderWith(t *testing.T, cache *FontCache, name string) {
+	t.Helper()
+
+	font, ok := cache.GetFont(name)
+	if !ok {
+		t.Fatalf("font %s not loaded", name)
+	}
+	if _, err := font.Render("HI"); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+}
+
+func TestFontCacheKeepsParsedFonts(t *testing.T) {
+	cache := loadedCache(t, 0, "standard", "doom")
+
+	renderWith(t, cache, "standard")
+	renderWith(t, cache, "standard")
+	renderWith(t, cache, "doom")
+
+	stats := cache.Stats()
+	if stats.Fonts != 2 || stats.Resident != 2 {
+		t.Errorf("expected 2 registered and 2 resident fonts, got %+v", stats)
+	}
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 0 {
+		t.Errorf("unexpected counters: %+v", stats)
+	}
+	if stats.Bytes <= 0 {
+		t.Errorf("expected resident bytes to be tracked, got %d", stats.Bytes)
+	}
+}
+
+func TestFontCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// Size the budget to hold the two smallest fonts but not all three
+	sizes := map[string]int64{}
+	for _, name := range []string{"standard", "doom", "small"} {
+		font := &Font{Name: name, fontPath: "../fonts/" + name + ".flf"}
+		parsed, err := font.parse()
+		if err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		sizes[name] = parsed.size()
+	}
+	budget := sizes["standard"] + sizes["small"]
+	if sizes["doom"] > budget {
+		t.Fatalf("test fonts unexpectedly large: %v", sizes)
+	}
+
+	cache := loadedCache(t, budget, "standard", "doom", "small")
+
+	renderWith(t, cache, "standard")
+	renderWith(t, cache, "small")
+	renderWith(t, cache, "standard") // standard is now most recently used
+	renderWith(t, cache, "doom")     // must evict small, then possibly standard
+
+	stats := cache.Stats()
+	if stats.Evictions == 0 {
+		t.Fatalf("expected evictions with budget %d, got %+v", budget, stats)
+	}
+	if stats.Bytes > budget {
+		t.Errorf("resident bytes %d exceed budget %d", stats.Bytes, budget)
+	}
+
+	doom, _ := cache.GetFont("doom")
+	small, _ := cache.GetFont("small")
+	cache.parsed.mu.Lock()
+	_, doomResident := cache.parsed.entries[doom]
+	_, smallResident := cache.parsed.entries[small]
+	cache.parsed.mu.Unlock()
+	if !doomResident || smallResident {
+		t.Errorf("expected doom resident and small evicted, got doom=%v small=%v", doomResident, smallResident)
+	}
+
+	// Evicted fonts are re-parsed transparently
+	renderWith(t, cache, "small")
+}
+
+func TestFontCacheSkipsFontsLargerThanBudget(t *testing.T) {
+	cache := loadedCache(t, 1, "standard")
+
+	renderWith(t, cache, "standard")
+	renderWith(t, cache, "standard")
+
+	stats := cache.Stats()
+	if stats.Resident != 0 || stats.Bytes != 0 || stats.Misses != 2 {
+		t.Errorf("oversized font should not be kept: %+v", stats)
+	}
+}
+
+func TestFontCacheReloadDropsParsedFonts(t *testing.T) {
+	cache := loadedCache(t, 0, "standard")
+	old, _ := cache.GetFont("standard")
+	renderWith(t, cache, "standard")
+
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard"}, MemoryBudget: 1 << 20}); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	// A render through a font from the previous load must not be cached again
+	if _, err := old.Render("HI"); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	stats := cache.Stats()
+	if stats.Resident != 0 || stats.Budget != 1<<20 {
+		t.Errorf("expected empty cache with new budget, got %+v", stats)
+	}
+}
+
+func TestFontCacheParsedConcurrency(t *testing.T) {
+	cache := loadedCache(t, 60000, "standard", "doom", "small")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := []string{"standard", "doom", "small"}[i%3]
+			for j := 0; j < 10; j++ {
+				font, _ := cache.GetFont(name)
+				if _, err := font.Render("HI"); err != nil {
+					t.Errorf("Render failed: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if stats := cache.Stats(); stats.Bytes > stats.Budget {
+		t.Errorf("resident bytes %d exceed budget %d", stats.Bytes, stats.Budget)
+	}
+}
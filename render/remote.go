@@ -0,0 +1,232 @@
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// remoteClient downloads remote fonts. Tests replace it to trust a local
+// TLS server.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// RemoteFont describes a font downloaded from a URL.
+//
+// Usage example:
+//
+//	rf, err := ParseRemoteFont("https://raw.githubusercontent.com/xero/figlet-fonts/master/Bloody.flf")
+//	if err != nil {
+//	    log.Printf("Invalid remote font: %v", err)
+//	}
+//	fmt.Println(rf.Name) // "bloody"
+type RemoteFont struct {
+	// Name is the font name used in requests.
+	Name string
+	// URL is the https location of the .flf file.
+	URL *url.URL
+	// SHA256 is the expected hex checksum of the file; empty if not pinned.
+	SHA256 string
+}
+
+// ParseRemoteFont parses a remote font spec of the form
+// "[name=]url[#sha256=hex]". Without an explicit name the font is named
+// after the file in the URL, lowercased with spaces replaced by dashes.
+//
+// Parameters:
+//   - spec: the remote font spec
+//
+// Returns:
+//   - *RemoteFont: the parsed remote font
+//   - error: error if the URL, name or checksum is invalid
+//
+// Example:
+//
+//	rf, err := ParseRemoteFont("blood=https://example.com/Bloody.flf#sha256=9f86d0...")
+func ParseRemoteFont(spec string) (*RemoteFont, error) {
+	spec = strings.TrimSpace(spec)
+
+	var name string
+	if i := strings.Index(spec, "="); i > 0 && !strings.Contains(spec[:i], "://") {
+		name, spec = spec[:i], spec[i+1:]
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote font URL %q: %w", spec, err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("remote font URL must be absolute https: %q", spec)
+	}
+
+	rf := &RemoteFont{Name: name}
+	if u.Fragment != "" {
+		sum, ok := strings.CutPrefix(u.Fragment, "sha256=")
+		if !ok {
+			return nil, fmt.Errorf("unsupported checksum %q: expected sha256=<hex>", u.Fragment)
+		}
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("invalid sha256 checksum %q", sum)
+		}
+		rf.SHA256 = strings.ToLower(sum)
+		u.Fragment = ""
+	}
+	rf.URL = u
+
+	if rf.Name == "" {
+		base := strings.TrimSuffix(path.Base(u.Path), ".flf")
+		rf.Name = strings.ReplaceAll(strings.ToLower(base), " ", "-")
+	}
+	if rf.Name == "" || rf.Name == "." || rf.Name == "/" || strings.ContainsAny(rf.Name, `/\`) {
+		return nil, fmt.Errorf("cannot derive a font name from %q", spec)
+	}
+
+	return rf, nil
+}
+
+// cachePath returns where the downloaded font is stored in dir. The file
+// name is derived from the URL so changing a font's URL never reuses a
+// stale download.
+func (rf *RemoteFont) cachePath(dir string) string {
+	sum := sha256.Sum256([]byte(rf.URL.String()))
+	return filepath.Join(dir, rf.Name+"-"+hex.EncodeToString(sum[:8])+".flf")
+}
+
+// FetchRemoteFont returns the path of a cached copy of a remote font,
+// downloading it first if it isn't cached yet. Downloads are limited to
+// the allowed hosts and to cfg.RemoteMaxBytes, verified against the pinned
+// checksum (if any), parsed to make sure they are valid FIGlet fonts and
+// only then written to cfg.CacheDir.
+//
+// Parameters:
+//   - rf: the remote font to fetch
+//   - cfg: font configuration with the cache directory, host allow-list and size cap
+//
+// Returns:
+//   - string: path of the cached font file
+//   - error: error if the font cannot be downloaded, verified or cached
+//
+// Example:
+//
+//	path, err := FetchRemoteFont(rf, cfg.Fonts)
+//	if err != nil {
+//	    log.Printf("Warning: Could not fetch font %s: %v", rf.Name, err)
+//	}
+func FetchRemoteFont(rf *RemoteFont, cfg config.FontConfig) (string, error) {
+	cached := rf.cachePath(cfg.CacheDir)
+	if data, err := os.ReadFile(cached); err == nil {
+		if verifyChecksum(data, rf.SHA256) == nil {
+			return cached, nil
+		}
+		// A pinned checksum changed or the cache is corrupt; download again
+	}
+
+	if !hostAllowed(rf.URL.Hostname(), cfg.RemoteHosts) {
+		return "", fmt.Errorf("host %s is not in the remote font allow-list", rf.URL.Hostname())
+	}
+
+	data, err := download(rf.URL, cfg)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyChecksum(data, rf.SHA256); err != nil {
+		return "", err
+	}
+	if _, err := parseFIGFont(bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("downloaded file is not a valid FIGlet font: %w", err)
+	}
+
+	if err := os.MkdirAll(cfg.CacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create font cache directory: %w", err)
+	}
+	// Write to a temporary file first so a partial write is never loaded
+	tmp, err := os.CreateTemp(cfg.CacheDir, ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to cache font: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to cache font: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to cache font: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cached); err != nil {
+		return "", fmt.Errorf("failed to cache font: %w", err)
+	}
+
+	return cached, nil
+}
+
+// download fetches u, following redirects only to allowed hosts and
+// reading at most cfg.RemoteMaxBytes.
+func download(u *url.URL, cfg config.FontConfig) ([]byte, error) {
+	client := *remoteClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if req.URL.Scheme != "https" || !hostAllowed(req.URL.Hostname(), cfg.RemoteHosts) {
+			return fmt.Errorf("redirect to %s is not allowed", req.URL.Host)
+		}
+		return nil
+	}
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to download font: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download font: %s", resp.Status)
+	}
+	if resp.ContentLength > cfg.RemoteMaxBytes {
+		return nil, fmt.Errorf("font is %d bytes, exceeds limit of %d", resp.ContentLength, cfg.RemoteMaxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, cfg.RemoteMaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download font: %w", err)
+	}
+	if int64(len(data)) > cfg.RemoteMaxBytes {
+		return nil, fmt.Errorf("font exceeds limit of %d bytes", cfg.RemoteMaxBytes)
+	}
+	return data, nil
+}
+
+// verifyChecksum compares the SHA-256 of data with the expected hex sum.
+// An empty expected sum always matches.
+func verifyChecksum(data []byte, want string) error {
+	if want == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch: got sha256 %s, want %s", got, want)
+	}
+	return nil
+}
+
+// hostAllowed reports whether host is in the allow-list (case-insensitive).
+func hostAllowed(host string, allowed []string) bool {
+	for _, h := range allowed {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestParseRemoteFont(t *testing.T) {
+	sum := strings.Repeat("ab", sha256.Size)
+
+	tests := []struct {
+		spec     string
+		wantName string
+		wantURL  string
+		wantSum  string
+		wantErr  bool
+	}{
+		{
+			spec:     "https://raw.githubusercontent.com/xero/figlet-fonts/master/Bloody.flf",
+			wantName: "bloody",
+			wantURL:  "https://raw.githubusercontent.com/xero/figlet-fonts/master/Bloody.flf",
+		},
+		{
+			spec:     "https://raw.githubusercontent.com/xero/figlet-fonts/master/ANSI%20Shadow.flf",
+			wantName: "ansi-shadow",
+			wantURL:  "https://raw.githubusercontent.com/xero/figlet-fonts/master/ANSI%20Shadow.flf",
+		},
+		{
+			spec:     "blood=https://example.com/Bloody.flf#sha256=" + strings.ToUpper(sum),
+			wantName: "blood",
+			wantURL:  "https://example.com/Bloody.flf",
+			wantSum:  sum,
+		},
+		{spec: "http://example.com/font.flf", wantErr: true},
+		{spec: "example.com/font.flf", wantErr: true},
+		{spec: "https://example.com/font.flf#md5=abc", wantErr: true},
+		{spec: "https://example.com/font.flf#sha256=abc", wantErr: true},
+		{spec: "https://example.com/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			rf, err := ParseRemoteFont(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", rf)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rf.Name != tt.wantName || rf.URL.String() != tt.wantURL || rf.SHA256 != tt.wantSum {
+				t.Errorf("got name=%q url=%q sum=%q", rf.Name, rf.URL, rf.SHA256)
+			}
+		})
+	}
+}
+
+// newFontServer serves the repository's small font over TLS and counts hits.
+func newFontServer(t *testing.T) (*httptest.Server, []byte, *int32) {
+	t.Helper()
+
+	data, err := os.ReadFile("../fonts/small.flf")
+	if err != nil {
+		t.Fatalf("Failed to read font: %v", err)
+	}
+
+	var hits int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/small.flf":
+			w.Write(data)
+		case "/redirect.flf":
+			http.Redirect(w, r, "https://elsewhere.invalid/small.flf", http.StatusFound)
+		case "/junk.flf":
+			w.Write([]byte("not a font"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	original := remoteClient
+	remoteClient = srv.Client()
+	t.Cleanup(func() { remoteClient = original })
+
+	return srv, data, &hits
+}
+
+func TestFetchRemoteFont(t *testing.T) {
+	srv, data, hits := newFontServer(t)
+	sum := sha256.Sum256(data)
+	goodSum := hex.EncodeToString(sum[:])
+
+	cfg := config.FontConfig{
+		CacheDir:       t.TempDir(),
+		RemoteHosts:    []string{"127.0.0.1"},
+		RemoteMaxBytes: 1 << 20,
+	}
+
+	tests := []struct {
+		name    string
+		spec    string
+		cfg     func(c config.FontConfig) config.FontConfig
+		wantErr string
+	}{
+		{name: "downloads font", spec: srv.URL + "/small.flf"},
+		{name: "pinned checksum matches", spec: "pinned=" + srv.URL + "/small.flf#sha256=" + goodSum},
+		{
+			name:    "pinned checksum mismatch",
+			spec:    "bad=" + srv.URL + "/small.flf#sha256=" + strings.Repeat("0", 64),
+			wantErr: "checksum mismatch",
+		},
+		{
+			name: "host not allowed",
+			spec: "other=" + srv.URL + "/small.flf",
+			cfg: func(c config.FontConfig) config.FontConfig {
+				c.RemoteHosts = []string{"raw.githubusercontent.com"}
+				return c
+			},
+			wantErr: "allow-list",
+		},
+		{
+			name: "too large",
+			spec: "big=" + srv.URL + "/small.flf",
+			cfg: func(c config.FontConfig) config.FontConfig {
+				c.RemoteMaxBytes = 100
+				return c
+			},
+			wantErr: "exceeds limit",
+		},
+		{name: "redirect off allow-list", spec: srv.URL + "/redirect.flf", wantErr: "not allowed"},
+		{name: "not a font", spec: srv.URL + "/junk.flf", wantErr: "not a valid FIGlet font"},
+		{name: "not found", spec: srv.URL + "/missing.flf", wantErr: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			if tt.cfg != nil {
+				c = tt.cfg(c)
+			}
+			rf, err := ParseRemoteFont(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseRemoteFont: %v", err)
+			}
+
+			path, err := FetchRemoteFont(rf, c)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cached, err := os.ReadFile(path)
+			if err != nil || string(cached) != string(data) {
+				t.Errorf("cached file does not match download: %v", err)
+			}
+		})
+	}
+
+	// A second fetch is served from the disk cache
+	rf, _ := ParseRemoteFont(srv.URL + "/small.flf")
+	before := atomic.LoadInt32(hits)
+	if _, err := FetchRemoteFont(rf, cfg); err != nil {
+		t.Fatalf("cached fetch failed: %v", err)
+	}
+	if atomic.LoadInt32(hits) != before {
+		t.Error("expected cached font to be reused without downloading")
+	}
+}
+
+func TestLoadFontsRemote(t *testing.T) {
+	srv, _, _ := newFontServer(t)
+
+	cfg := config.FontConfig{
+		Path:           "../fonts",
+		Allowed:        []string{"standard"},
+		Remote:         []string{"tiny=" + srv.URL + "/small.flf", "standard=" + srv.URL + "/small.flf", "broken=" + srv.URL + "/junk.flf"},
+		RemoteHosts:    []string{"127.0.0.1"},
+		RemoteMaxBytes: 1 << 20,
+		CacheDir:       t.TempDir(),
+	}
+
+	cache := NewFontCache()
+	if err := cache.LoadFonts(cfg); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	font, exists := cache.GetFont("tiny")
+	if !exists {
+		t.Fatal("remote font should be registered")
+	}
+	if out, err := font.Render("HI"); err != nil || out == "" {
+		t.Errorf("remote font failed to render: %q, %v", out, err)
+	}
+
+	if standard, _ := cache.GetFont("standard"); standard == nil || standard.fontPath != "../fonts/standard.flf" {
+		t.Error("remote font must not shadow a local font")
+	}
+	if _, exists := cache.GetFont("broken"); exists {
+		t.Error("invalid remote font should be skipped")
+	}
+}
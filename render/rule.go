@@ -0,0 +1,90 @@
+package render
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultRuleChar draws rules without a character of their own.
+const DefaultRuleChar = "─"
+
+// RuleStyles are the named characters of rules. The border styles draw
+// the character of their top edge, so a rule matches a box drawn above
+// or below it.
+var RuleStyles = map[string]string{
+	"single":   "─",
+	"rounded":  "─",
+	"double":   "═",
+	"heavy":    "━",
+	"dashed":   "┄",
+	"ascii":    "-",
+	"equals":   "=",
+	"block":    "█",
+	"gradient": "█",
+}
+
+// RuleStyleNames returns the sorted names of RuleStyles.
+func RuleStyleNames() []string {
+	names := make([]string, 0, len(RuleStyles))
+	for name := range RuleStyles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Rule draws a horizontal rule width columns wide by repeating pattern,
+// one or more characters, such as "=" or "-=". The pattern is cut where
+// it would pass width, so a wide character that doesn't fit is left out.
+// An empty pattern draws DefaultRuleChar.
+//
+// Parameters:
+//   - pattern: the characters to repeat
+//   - width: the width of the rule in columns
+//
+// Returns:
+//   - string: the rule, without a newline; "" for a width below 1
+//
+// Example:
+//
+//	Rule("-=", 5) // "-=-=-"
+func Rule(pattern string, width int) string {
+	if pattern == "" {
+		pattern = DefaultRuleChar
+	}
+	type cell struct {
+		cluster string
+		width   int
+	}
+	var cells []cell
+	eachCluster(pattern, func(cluster string, w int) {
+		cells = append(cells, cell{cluster, max(w, 1)})
+	})
+
+	var b strings.Builder
+	for col, i := 0, 0; ; i = (i + 1) % len(cells) {
+		if col+cells[i].width > width {
+			break
+		}
+		b.WriteString(cells[i].cluster)
+		col += cells[i].width
+	}
+	return b.String()
+}
+
+// ASCIIRule returns a rule in 7-bit ASCII, as ?ascii=strict asks: double
+// lines as =, other lines as -, and blocks as #, as ASCIIOnly draws them.
+// Wide characters keep their width, so the rule does too.
+//
+// Parameters:
+//   - rule: the rule, as Rule draws it
+//
+// Returns:
+//   - string: the rule in printable ASCII
+//
+// Example:
+//
+//	ASCIIRule("═══") // "==="
+func ASCIIRule(rule string) string {
+	return ASCIIOnly(strings.ReplaceAll(rule, "═", "="))
+}
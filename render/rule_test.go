@@ -0,0 +1,49 @@
+package render
+
+import "testing"
+
+func TestRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		width   int
+		want    string
+	}{
+		{pattern: "=", width: 4, want: "===="},
+		{pattern: "", width: 3, want: "───"},
+		{pattern: "-=", width: 5, want: "-=-=-"},
+		{pattern: "日", width: 5, want: "日日"},
+		{pattern: "=", width: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Rule(tt.pattern, tt.width); got != tt.want {
+			t.Errorf("Rule(%q, %d) = %q, want %q", tt.pattern, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRuleStylesMatchBorders(t *testing.T) {
+	for name, style := range borderStyles {
+		if RuleStyles[name] != style.horizontal {
+			t.Errorf("rule style %s = %q, want the border's %q", name, RuleStyles[name], style.horizontal)
+		}
+	}
+}
+
+func TestASCIIRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{rule: "───", want: "---"},
+		{rule: "═══", want: "==="},
+		{rule: "██", want: "##"},
+		{rule: "-=-", want: "-=-"},
+		{rule: "日日", want: "? ? "},
+	}
+	for _, tt := range tests {
+		if got := ASCIIRule(tt.rule); got != tt.want {
+			t.Errorf("ASCIIRule(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
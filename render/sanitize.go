@@ -0,0 +1,130 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeText cleans user input before rendering. It removes ANSI escape
+// sequences and control characters (keeping newlines, which separate banner
+// lines), drops invalid UTF-8, and normalizes whitespace: runs of spaces
+// and tabs collapse to a single space, lines are trimmed, and leading and
+// trailing blank lines are removed.
+//
+// Parameters:
+//   - text: the raw input text
+//
+// Returns:
+//   - string: the cleaned text, possibly empty
+//
+// Example:
+//
+//	SanitizeText("  HELLO\t\x1b[31mWORLD\x07 ") // "HELLO WORLD"
+func SanitizeText(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = StripANSI(text)
+
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			b.WriteRune(r)
+		case r == '\t' || r == '\r' || unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			// Drop control and invisible formatting characters
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// TruncateText shortens text to at most maxLen runes, trimming any
+// whitespace left dangling at the cut. A maxLen below 1 means no limit.
+//
+// Parameters:
+//   - text: the text to shorten
+//   - maxLen: the maximum length in runes
+//
+// Returns:
+//   - string: the text, truncated if it was too long
+//
+// Example:
+//
+//	TruncateText("HELLO WORLD", 6) // "HELLO"
+func TruncateText(text string, maxLen int) string {
+	if maxLen < 1 || utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	return strings.TrimRight(string([]rune(text)[:maxLen]), " \n")
+}
+
+// StripANSI removes terminal escape sequences: CSI sequences such as
+// "\x1b[31m", OSC sequences terminated by BEL or ST, and two-byte escapes.
+//
+// Parameters:
+//   - text: text that may contain escape sequences
+//
+// Returns:
+//   - string: the text without escape sequences
+//
+// Example:
+//
+//	StripANSI("\x1b[31mRED\x1b[0m") // "RED"
+func StripANSI(text string) string {
+	if !strings.ContainsAny(text, "\x1b\u009b") {
+		return text
+	}
+
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r != 0x1b && r != 0x9b {
+			b.WriteRune(r)
+			continue
+		}
+
+		// A bare ESC at the end of input
+		if r == 0x1b && i+1 >= len(runes) {
+			continue
+		}
+
+		switch {
+		case r == 0x9b || runes[i+1] == '[':
+			// CSI: parameters and intermediates, then a final byte in @–~
+			if r == 0x1b {
+				i++
+			}
+			for i+1 < len(runes) && (runes[i+1] < 0x40 || runes[i+1] > 0x7e) {
+				i++
+			}
+			i++
+		case runes[i+1] == ']':
+			// OSC: runs until BEL or ESC \
+			i++
+			for i+1 < len(runes) {
+				i++
+				if runes[i] == 0x07 {
+					break
+				}
+				if runes[i] == 0x1b && i+1 < len(runes) && runes[i+1] == '\\' {
+					i++
+					break
+				}
+			}
+		default:
+			// Two-byte escape such as ESC c
+			i++
+		}
+	}
+	return b.String()
+}
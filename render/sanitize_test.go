@@ -0,0 +1,58 @@
+package render
+
+import "testing"
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain", input: "HELLO", want: "HELLO"},
+		{name: "collapse spaces and tabs", input: "  HELLO \t  WORLD  ", want: "HELLO WORLD"},
+		{name: "keeps newlines", input: "HELLO\nWORLD", want: "HELLO\nWORLD"},
+		{name: "crlf", input: "HELLO\r\nWORLD", want: "HELLO\nWORLD"},
+		{name: "trims blank edge lines", input: "\n\nHI\n\n", want: "HI"},
+		{name: "keeps inner blank line", input: "A\n\nB", want: "A\n\nB"},
+		{name: "sgr escape", input: "\x1b[31mRED\x1b[0m", want: "RED"},
+		{name: "cursor escape", input: "A\x1b[2J\x1b[HB", want: "AB"},
+		{name: "osc title with bel", input: "\x1b]0;pwned\x07HI", want: "HI"},
+		{name: "osc with st", input: "\x1b]8;;http://x\x1b\\LINK", want: "LINK"},
+		{name: "c1 csi", input: "\u009b31mHI", want: "HI"},
+		{name: "two byte escape", input: "\x1bcHI", want: "HI"},
+		{name: "trailing escape", input: "HI\x1b", want: "HI"},
+		{name: "control characters", input: "H\x00I\x07!\x7f", want: "HI!"},
+		{name: "zero width", input: "H\u200bI", want: "HI"},
+		{name: "invalid utf8", input: "H\xffI", want: "HI"},
+		{name: "unicode kept", input: "CAFÉ", want: "CAFÉ"},
+		{name: "only whitespace", input: " \t\n ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.input); got != tt.want {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"HELLO", 10, "HELLO"},
+		{"HELLO", 5, "HELLO"},
+		{"HELLO WORLD", 6, "HELLO"},
+		{"ÉÉÉÉ", 2, "ÉÉ"},
+		{"HELLO", 0, "HELLO"},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateText(tt.input, tt.maxLen); got != tt.want {
+			t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package render
+
+import "strings"
+
+// SuggestFont finds the loaded font that best matches a misspelled or
+// abbreviated font name. Names are compared case-insensitively; a unique
+// prefix match wins ("star" → "starwars"), otherwise the font with the
+// smallest edit distance is chosen if it is close enough to be a plausible
+// typo (at most one edit per three characters, minimum one).
+//
+// Parameters:
+//   - name: the requested font name
+//
+// Returns:
+//   - string: the suggested font name
+//   - bool: true if a suggestion was found
+//
+// Example:
+//
+//	if suggestion, ok := cache.SuggestFont("dooom"); ok {
+//	    fmt.Printf("Did you mean %s?\n", suggestion) // "doom"
+//	}
+func (fc *FontCache) SuggestFont(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", false
+	}
+
+	fonts := fc.ListFonts()
+
+	var prefixed []string
+	for _, font := range fonts {
+		lower := strings.ToLower(font)
+		if lower == name {
+			return font, true
+		}
+		if strings.HasPrefix(lower, name) {
+			prefixed = append(prefixed, font)
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0], true
+	}
+
+	maxDistance := len([]rune(name)) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	best, bestDistance := "", maxDistance+1
+	for _, font := range fonts {
+		// fonts is sorted, so ties resolve alphabetically
+		if d := levenshtein(name, strings.ToLower(font)); d < bestDistance {
+			best, bestDistance = font, d
+		}
+	}
+	return best, best != ""
+}
+
+// levenshtein returns the edit distance between two strings, counting
+// insertions, deletions and substitutions of runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
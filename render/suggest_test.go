@@ -0,0 +1,79 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestSuggestFont(t *testing.T) {
+	cache := NewFontCache()
+	err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard", "doom", "slant", "small", "bloody", "big"}})
+	if err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "doom", want: "doom", wantOK: true},
+		{name: "DOOM", want: "doom", wantOK: true},
+		{name: "dooom", want: "doom", wantOK: true},
+		{name: "dom", want: "doom", wantOK: true},
+		{name: "standrad", want: "standard", wantOK: true},
+		{name: "blo", want: "bloody", wantOK: true},
+		{name: "b", wantOK: false},
+		{name: "sla", want: "slant", wantOK: true},
+		{name: "smal", want: "small", wantOK: true},
+		{name: "s", wantOK: false},
+		{name: "banner", wantOK: false},
+		{name: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cache.SuggestFont(tt.name)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("SuggestFont(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"doom", "doom", 0},
+		{"doom", "dooom", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"é", "e", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateASCII_FuzzyFontMatch(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard", "doom"}}); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+
+	want, _ := GenerateASCII("HI", types.RenderOptions{Font: "doom"}, cache)
+	got, err := GenerateASCII("HI", types.RenderOptions{Font: "dooom"}, cache)
+	if err != nil {
+		t.Fatalf("GenerateASCII failed: %v", err)
+	}
+	if got != want {
+		t.Error("misspelled font should render with the closest match")
+	}
+}
@@ -0,0 +1,224 @@
+package render
+
+import "strings"
+
+// tableJunctions are the characters where a table's lines meet, for each
+// border style that draws lines.
+var tableJunctions = map[string]struct {
+	down, up, right, left, cross string
+}{
+	"single":  {"┬", "┴", "├", "┤", "┼"},
+	"rounded": {"┬", "┴", "├", "┤", "┼"},
+	"double":  {"╦", "╩", "╠", "╣", "╬"},
+}
+
+// tableGap separates the columns of a table drawn without lines.
+const tableGap = "  "
+
+// Table is rows of text drawn in a grid of box-drawing lines.
+//
+// Usage example:
+//
+//	out := DrawTable(Table{Header: []string{"host", "cpu"}, Rows: [][]string{{"web-01", "12%"}}, Align: []string{"left", "right"}, Border: "single"}, nil)
+type Table struct {
+	// Header is the first row, drawn above a line of its own; nil for
+	// none
+	Header []string
+	// Rows are the rows of cells below the header; short rows are filled
+	// with empty cells
+	Rows [][]string
+	// Align places the cells of each column within it: left, center or
+	// right; columns without an entry are left
+	Align []string
+	// Border is the style of the lines: single, double or rounded; other
+	// values, such as "" or none, draw none, with spaces between columns
+	Border string
+	// Accent colors the lines; nil leaves them uncolored
+	Accent *Color
+	// Width, when above 0, is the terminal width the table is placed
+	// within
+	Width int
+	// Place puts the table within Width: left, center or right
+	Place string
+}
+
+// tableSegment is a run of a table line: cell text, or the lines between
+// cells.
+type tableSegment struct {
+	text string
+	line bool
+}
+
+// DrawTable draws a table, its cells colored by scheme as ColorizeLayout
+// colors a banner, bound to the cells alone, and its lines in the
+// accent. Cells should be single lines without control characters, such
+// as SanitizeText leaves them.
+//
+// Parameters:
+//   - t: the table to draw
+//   - scheme: the colors of the cells; nil leaves them uncolored
+//
+// Returns:
+//   - string: the table, each line ending in a newline; "" for a table
+//     without cells
+//
+// Example:
+//
+//	out := DrawTable(Table{Rows: [][]string{{"a", "b"}}, Border: "single"}, nil)
+//	// "┌───┬───┐\n│ a │ b │\n└───┴───┘\n"
+func DrawTable(t Table, scheme ColorScheme) string {
+	rows := t.Rows
+	if t.Header != nil {
+		rows = append([][]string{t.Header}, rows...)
+	}
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], StringWidth(cell))
+		}
+	}
+
+	style, bordered := borderStyles[t.Border]
+	junctions := tableJunctions[t.Border]
+	var lines [][]tableSegment
+	rule := func(left, middle, right string) {
+		if !bordered {
+			return
+		}
+		var b strings.Builder
+		b.WriteString(left)
+		for i, width := range widths {
+			if i > 0 {
+				b.WriteString(middle)
+			}
+			b.WriteString(strings.Repeat(style.horizontal, width+2))
+		}
+		b.WriteString(right)
+		lines = append(lines, []tableSegment{{text: b.String(), line: true}})
+	}
+	cells := func(row []string) {
+		gap, edge := tableGap, ""
+		if bordered {
+			gap, edge = " "+style.vertical+" ", style.vertical+" "
+		}
+		var line []tableSegment
+		if edge != "" {
+			line = append(line, tableSegment{text: edge, line: true})
+		}
+		for i, width := range widths {
+			if i > 0 {
+				line = append(line, tableSegment{text: gap, line: true})
+			}
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			align := "left"
+			if i < len(t.Align) {
+				align = t.Align[i]
+			}
+			line = append(line, tableSegment{text: padCell(cell, width, align)})
+		}
+		if edge != "" {
+			line = append(line, tableSegment{text: " " + style.vertical, line: true})
+		}
+		lines = append(lines, line)
+	}
+
+	rule(style.topLeft, junctions.down, style.topRight)
+	for i, row := range rows {
+		cells(row)
+		if i == 0 && t.Header != nil && len(rows) > 1 {
+			rule(junctions.right, junctions.cross, junctions.left)
+		}
+	}
+	rule(style.bottomLeft, junctions.up, style.bottomRight)
+
+	return drawTableLines(lines, t, scheme)
+}
+
+// drawTableLines joins the segments of each line of a table, coloring
+// the cells with scheme, bound to the cells with the lines left out, and
+// the lines with t.Accent, and places the table within t.Width.
+func drawTableLines(lines [][]tableSegment, t Table, scheme ColorScheme) string {
+	// The cells alone, the lines blanked, for the scheme to bind to
+	plain := make([]string, len(lines))
+	width := 0
+	for i, line := range lines {
+		var b strings.Builder
+		for _, segment := range line {
+			if segment.line {
+				b.WriteString(strings.Repeat(" ", StringWidth(segment.text)))
+			} else {
+				b.WriteString(segment.text)
+			}
+		}
+		plain[i] = b.String()
+		width = max(width, StringWidth(plain[i]))
+	}
+	scheme = bindScheme(scheme, TextLayout(strings.Join(plain, "\n")))
+
+	margin := ""
+	if t.Width > 0 && (t.Place == "center" || t.Place == "right") {
+		indent := max(t.Width-width, 0)
+		if t.Place == "center" {
+			indent /= 2
+		}
+		margin = strings.Repeat(" ", indent)
+	}
+
+	var b strings.Builder
+	for row, line := range lines {
+		b.WriteString(margin)
+		col := 0
+		for _, segment := range line {
+			switch {
+			case segment.line && t.Accent != nil:
+				b.WriteString(t.Accent.Foreground() + segment.text + AnsiReset)
+				col += StringWidth(segment.text)
+			case segment.line || scheme == nil:
+				b.WriteString(segment.text)
+				col += StringWidth(segment.text)
+			default:
+				colored := false
+				var current Color
+				eachCluster(segment.text, func(cluster string, w int) {
+					if cluster != " " && w > 0 {
+						c := scheme.ColorAt(0, row, col)
+						if !colored || c != current {
+							b.WriteString(c.Foreground())
+							current = c
+							colored = true
+						}
+					}
+					b.WriteString(cluster)
+					col += w
+				})
+				if colored {
+					b.WriteString(AnsiReset)
+				}
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// padCell pads cell with spaces to width columns, placing it by align.
+func padCell(cell string, width int, align string) string {
+	space := max(width-StringWidth(cell), 0)
+	switch align {
+	case "right":
+		return strings.Repeat(" ", space) + cell
+	case "center":
+		return strings.Repeat(" ", space/2) + cell + strings.Repeat(" ", space-space/2)
+	}
+	return cell + strings.Repeat(" ", space)
+}
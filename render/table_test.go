@@ -0,0 +1,66 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDrawTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		table Table
+		want  string
+	}{
+		{
+			name:  "single",
+			table: Table{Rows: [][]string{{"a", "b"}}, Border: "single"},
+			want:  "┌───┬───┐\n│ a │ b │\n└───┴───┘\n",
+		},
+		{
+			name:  "header and alignment",
+			table: Table{Header: []string{"host", "cpu"}, Rows: [][]string{{"web", "5%"}, {"db-01", "80%"}}, Align: []string{"left", "right"}, Border: "double"},
+			want: "╔═══════╦═════╗\n" +
+				"║ host  ║ cpu ║\n" +
+				"╠═══════╬═════╣\n" +
+				"║ web   ║  5% ║\n" +
+				"║ db-01 ║ 80% ║\n" +
+				"╚═══════╩═════╝\n",
+		},
+		{
+			name:  "short rows and centered cells",
+			table: Table{Rows: [][]string{{"abc", "d"}, {"e"}}, Align: []string{"center"}, Border: "rounded"},
+			want:  "╭─────┬───╮\n│ abc │ d │\n│  e  │   │\n╰─────┴───╯\n",
+		},
+		{
+			name:  "without lines",
+			table: Table{Header: []string{"k", "v"}, Rows: [][]string{{"key", "1"}}, Border: "none"},
+			want:  "k    v\nkey  1\n",
+		},
+		{
+			name:  "placed within the width",
+			table: Table{Rows: [][]string{{"a"}}, Border: "single", Width: 11, Place: "right"},
+			want:  "      ┌───┐\n      │ a │\n      └───┘\n",
+		},
+		{name: "no cells", table: Table{Border: "single"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := DrawTable(tt.table, nil); got != tt.want {
+			t.Errorf("%s: DrawTable() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDrawTableColors(t *testing.T) {
+	accent := Color{R: 255, G: 215}
+	scheme, err := ParseColor("red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := DrawTable(Table{Rows: [][]string{{"a"}}, Border: "single", Accent: &accent}, scheme)
+	for _, want := range []string{accent.Foreground() + "│ " + AnsiReset, Color{R: 255}.Foreground() + "a" + AnsiReset} {
+		if !strings.Contains(got, want) {
+			t.Errorf("table missing %q: %q", want, got)
+		}
+	}
+}
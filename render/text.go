@@ -0,0 +1,39 @@
+package render
+
+import "strings"
+
+// TextLayout lays out text as it is, a row per line and a glyph per
+// character, so plain text such as a caption or a rule can be aligned
+// with AlignLayout and colored with ColorizeLayout as banners are: color
+// lists cycle by its characters, words or lines, and gradients span it.
+//
+// Parameters:
+//   - text: the text, lines separated by newlines
+//
+// Returns:
+//   - *Layout: the layout of the text
+//
+// Example:
+//
+//	layout := AlignLayout(TextLayout("welcome back"), 80, "center")
+//	fmt.Print(ColorizeLayout(layout, scheme, 0))
+func TextLayout(text string) *Layout {
+	text = strings.TrimSuffix(text, "\n")
+	layout := &Layout{Text: []rune(text)}
+	index := 0
+	for line, row := range strings.Split(text, "\n") {
+		layout.Rows = append(layout.Rows, row)
+		layout.RowLines = append(layout.RowLines, line)
+		var spans []GlyphSpan
+		col := 0
+		eachCluster(row, func(cluster string, width int) {
+			spans = append(spans, GlyphSpan{Index: index, Line: line, Start: col, End: col + width})
+			index += len([]rune(cluster))
+			col += width
+		})
+		layout.Spans = append(layout.Spans, spans)
+		// The newline ending the line
+		index++
+	}
+	return layout
+}
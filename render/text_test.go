@@ -0,0 +1,31 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTextLayout(t *testing.T) {
+	layout := TextLayout("ab 日\ncd\n")
+	if want := []string{"ab 日", "cd"}; !reflect.DeepEqual(layout.Rows, want) {
+		t.Errorf("rows = %q, want %q", layout.Rows, want)
+	}
+	want := [][]GlyphSpan{
+		{{Index: 0, Line: 0, Start: 0, End: 1}, {Index: 1, Line: 0, Start: 1, End: 2}, {Index: 2, Line: 0, Start: 2, End: 3}, {Index: 3, Line: 0, Start: 3, End: 5}},
+		{{Index: 5, Line: 1, Start: 0, End: 1}, {Index: 6, Line: 1, Start: 1, End: 2}},
+	}
+	if !reflect.DeepEqual(layout.Spans, want) {
+		t.Errorf("spans = %v, want %v", layout.Spans, want)
+	}
+
+	// Color lists cycle by the words of the text
+	scheme, err := ParseColor("red,blue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ColorizeLayout(TextLayout("ab cd"), scheme, 0)
+	if red, blue := strings.Index(got, "\x1b[38;2;255;0;0ma"), strings.Index(got, "\x1b[38;2;0;0;255mc"); red < 0 || blue < red {
+		t.Errorf("expected ab in red and cd in blue, got %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// reloadDebounce groups bursts of filesystem events (e.g. a font being
+// copied in several writes) into a single reload.
+const reloadDebounce = 250 * time.Millisecond
+
+// Watch monitors the fonts directory and reloads the cache whenever a font
+// (.flf) or control file (.flc) is created, modified, removed or renamed.
+// New fonts become available as soon as they appear if they are in the
+// allowed list; deleted fonts are evicted (or fall back to their embedded
+// copy). It blocks until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: cancels the watcher
+//   - cfg: font configuration used for each reload
+//
+// Returns:
+//   - error: error if the directory cannot be watched
+//
+// Example:
+//
+//	go func() {
+//	    if err := cache.Watch(ctx, cfg.Fonts); err != nil {
+//	        log.Printf("Font watcher stopped: %v", err)
+//	    }
+//	}()
+func (fc *FontCache) Watch(ctx context.Context, cfg config.FontConfig) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create font watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(cfg.Path); err != nil {
+		return fmt.Errorf("failed to watch fonts directory %s: %w", cfg.Path, err)
+	}
+	log.Printf("Watching %s for font changes", cfg.Path)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if isFontFile(event.Name) {
+				debounce = time.After(reloadDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Warning: font watcher error: %v", err)
+
+		case <-debounce:
+			debounce = nil
+			log.Printf("Font directory changed, reloading fonts")
+			if err := fc.LoadFonts(cfg); err != nil {
+				log.Printf("Warning: font reload failed: %v", err)
+			}
+		}
+	}
+}
+
+// isFontFile reports whether a path is a font or control file.
+func isFontFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".flf", ".flc":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestFontCacheWatch(t *testing.T) {
+	dir := t.TempDir()
+	copyFont := func(name string) {
+		data, err := os.ReadFile(filepath.Join("../fonts", name+".flf"))
+		if err != nil {
+			t.Fatalf("Failed to read font %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".flf"), data, 0644); err != nil {
+			t.Fatalf("Failed to write font %s: %v", name, err)
+		}
+	}
+	copyFont("standard")
+
+	cfg := config.FontConfig{Path: dir, Allowed: []string{"standard", "doom"}}
+	cache := NewFontCache()
+	if err := cache.LoadFonts(cfg); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- cache.Watch(ctx, cfg) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch returned error: %v", err)
+		}
+	}()
+
+	waitFor := func(desc string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if cond() {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %s", desc)
+	}
+
+	// Give the watcher a moment to register the directory
+	time.Sleep(100 * time.Millisecond)
+
+	copyFont("doom")
+	waitFor("doom to be loaded", func() bool {
+		_, ok := cache.GetFont("doom")
+		return ok
+	})
+
+	if err := os.Remove(filepath.Join(dir, "doom.flf")); err != nil {
+		t.Fatalf("Failed to remove font: %v", err)
+	}
+	waitFor("doom to be evicted", func() bool {
+		_, ok := cache.GetFont("doom")
+		return !ok
+	})
+}
+
+func TestFontCacheWatchMissingDirectory(t *testing.T) {
+	cache := NewFontCache()
+	err := cache.Watch(context.Background(), config.FontConfig{Path: "/nonexistent/path"})
+	if err == nil {
+		t.Error("expected error watching a missing directory")
+	}
+}
+
+func TestIsFontFile(t *testing.T) {
+	tests := map[string]bool{
+		"fonts/doom.flf":   true,
+		"fonts/latin2.flc": true,
+		"fonts/README.md":  false,
+		"fonts/doom.flf~":  false,
+	}
+	for path, want := range tests {
+		if got := isFontFile(path); got != want {
+			t.Errorf("isFontFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestLoadFontsReplacesPreviousSet(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard", "doom"}}); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"standard"}}); err != nil {
+		t.Fatalf("LoadFonts failed: %v", err)
+	}
+
+	if _, ok := cache.GetFont("doom"); ok {
+		t.Error("reloading should evict fonts that are no longer available")
+	}
+}
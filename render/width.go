@@ -0,0 +1,130 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// widths measures characters as terminals draw them: East Asian wide
+// characters and emoji take two columns and combining marks none.
+// Characters of ambiguous width, such as the box drawing of ANSI art, take
+// one, whatever the server's locale.
+var widths = &runewidth.Condition{StrictEmojiNeutral: true}
+
+// StringWidth returns the columns s takes in a terminal, counting wide
+// characters twice and combining marks not at all. s must not hold ANSI
+// sequences; see StripANSI.
+//
+// Parameters:
+//   - s: the text to measure
+//
+// Returns:
+//   - int: the width in columns
+//
+// Example:
+//
+//	StringWidth("日本") // 4
+//	StringWidth("é")   // 1, even as e and a combining accent
+func StringWidth(s string) int {
+	if ascii(s) {
+		return len(s)
+	}
+	return widths.StringWidth(s)
+}
+
+// eachCluster calls fn with each character of s, with its combining
+// marks, and the columns it takes. ASCII text, the bulk of what fonts
+// draw, skips the segmenting.
+func eachCluster(s string, fn func(cluster string, width int)) {
+	if ascii(s) {
+		for i := range len(s) {
+			fn(s[i:i+1], 1)
+		}
+		return
+	}
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		cluster := g.Str()
+		fn(cluster, widths.StringWidth(cluster))
+	}
+}
+
+// ascii reports whether s is printable ASCII, a column per byte.
+func ascii(s string) bool {
+	for i := range len(s) {
+		if s[i] < ' ' || s[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cells splits a row of output into its columns, so stages that work on
+// columns, such as masking and animations, can index it by them. Each
+// cell holds the character drawn in that column with its combining marks;
+// the column taken by the right half of a wide character holds "".
+// JoinCells puts the row back together.
+//
+// Parameters:
+//   - row: a row of output, without ANSI sequences
+//
+// Returns:
+//   - []string: a cell per column
+//
+// Example:
+//
+//	cells := Cells("A日")  // ["A", "日", ""]
+//	cells[0] = " "
+//	row := JoinCells(cells) // " 日"
+func Cells(row string) []string {
+	cells := make([]string, 0, len(row))
+	marks := ""
+	eachCluster(row, func(cluster string, width int) {
+		switch {
+		case width == 0 && len(cells) > 0:
+			cells[len(cells)-1] += cluster
+		case width == 0:
+			// Marks before any character go with the first one
+			marks += cluster
+		case width > 1:
+			cells = append(cells, marks+cluster, "")
+			marks = ""
+		default:
+			cells = append(cells, marks+cluster)
+			marks = ""
+		}
+	})
+	return cells
+}
+
+// JoinCells joins the cells of a row back into it. A wide character whose
+// right half was overwritten or cut off, and a right half without its
+// character, are drawn as spaces, so the row stays as wide as its cells.
+//
+// Parameters:
+//   - cells: the columns of the row, as Cells splits it
+//
+// Returns:
+//   - string: the row
+//
+// Example:
+//
+//	row := JoinCells(Cells(row)[:79]) // the row cut to 79 columns
+func JoinCells(cells []string) string {
+	var b strings.Builder
+	for col, cell := range cells {
+		switch {
+		case cell == "":
+			if col == 0 || StringWidth(cells[col-1]) < 2 {
+				b.WriteByte(' ')
+			}
+		case StringWidth(cell) > 1 && (col+1 == len(cells) || cells[col+1] != ""):
+			b.WriteByte(' ')
+		default:
+			b.WriteString(cell)
+		}
+	}
+	return b.String()
+}
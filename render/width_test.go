@@ -0,0 +1,167 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wideFont is a two-row font whose glyphs are all two columns wide: 'A'
+// in ASCII, '日' with a wide character and 'é' with combining accents.
+func wideFont(t *testing.T) *figFont {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("flf2a$ 2 2 3 0 0\n")
+	for code := 32; code <= 126; code++ {
+		switch code {
+		case ' ':
+			b.WriteString("$$@\n$$@@\n")
+		case 'A':
+			b.WriteString("/\\@\n||@@\n")
+		default:
+			b.WriteString("??@\n??@@\n")
+		}
+	}
+	for range deutschRunes {
+		b.WriteString("DD@\nDD@@\n")
+	}
+	b.WriteString("0x65E5\n日@\n##@@\n")
+	b.WriteString("233\ne\u0301e\u0301@\n__@@\n")
+
+	font, err := parseFIGFont(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("parseFIGFont failed: %v", err)
+	}
+	return font
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{name: "ascii", s: "HELLO", want: 5},
+		{name: "cjk", s: "日本語", want: 6},
+		{name: "emoji", s: "🎉", want: 2},
+		{name: "combining marks", s: "e\u0301a\u0308", want: 2},
+		{name: "box drawing", s: "╔═╗", want: 3},
+		{name: "mixed", s: "A日é🎉", want: 6},
+		{name: "empty", s: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StringWidth(tt.s); got != tt.want {
+				t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCells(t *testing.T) {
+	tests := []struct {
+		row  string
+		want []string
+	}{
+		{row: "ab", want: []string{"a", "b"}},
+		{row: "a日b", want: []string{"a", "日", "", "b"}},
+		{row: "e\u0301x", want: []string{"e\u0301", "x"}},
+		{row: "\u0301e", want: []string{"\u0301e"}},
+	}
+
+	for _, tt := range tests {
+		got := Cells(tt.row)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Cells(%q) = %q, want %q", tt.row, got, tt.want)
+		}
+		if row := JoinCells(got); row != tt.row {
+			t.Errorf("JoinCells(Cells(%q)) = %q", tt.row, row)
+		}
+	}
+
+	// Halves of wide characters are drawn as spaces, keeping the width
+	for _, cells := range [][]string{
+		{"a", "日"},
+		{"", "b"},
+		{"日", "x", "b"},
+	} {
+		if got := JoinCells(cells); StringWidth(got) != len(cells) || strings.Contains(got, "日") {
+			t.Errorf("JoinCells(%q) = %q, want %d columns of spaces and ASCII", cells, got, len(cells))
+		}
+	}
+}
+
+func TestMixedWidthLayout(t *testing.T) {
+	font := wideFont(t)
+	layout := font.layout("A日é")
+
+	// Glyph rows are padded by columns, not runes, so every glyph is as
+	// wide on every row
+	for _, row := range layout.Rows {
+		if got := StringWidth(row); got != 6 {
+			t.Errorf("row %q is %d columns wide, want 6", row, got)
+		}
+	}
+	wantSpans := []GlyphSpan{
+		{Index: 0, Start: 0, End: 2},
+		{Index: 1, Start: 2, End: 4},
+		{Index: 2, Start: 4, End: 6},
+	}
+	if !reflect.DeepEqual(layout.Spans[0], wantSpans) {
+		t.Errorf("spans = %+v, want %+v", layout.Spans[0], wantSpans)
+	}
+	if got := LayoutWidth(layout); got != 6 {
+		t.Errorf("LayoutWidth() = %d, want 6", got)
+	}
+
+	aligned := AlignLayout(layout, 10, "right")
+	if want := "    /\\日e\u0301e\u0301"; aligned.Rows[0] != want {
+		t.Errorf("right aligned row = %q, want %q", aligned.Rows[0], want)
+	}
+	if got := aligned.Spans[0][1].Start; got != 6 {
+		t.Errorf("right aligned 日 starts at %d, want 6", got)
+	}
+
+	padded := PadLayout(layout, 8)
+	for _, row := range padded.Rows {
+		if got := StringWidth(row); got != 8 {
+			t.Errorf("padded row %q is %d columns wide, want 8", row, got)
+		}
+	}
+
+	cropped := CropLayout(layout, 3, 0)
+	if want := []string{"/\\ ", "||#"}; !reflect.DeepEqual(cropped.Rows, want) {
+		t.Errorf("cropped rows = %q, want %q", cropped.Rows, want)
+	}
+
+	masked := font.layout("A日é")
+	MaskLayout(masked, []WordRange{{Start: 1, End: 2}})
+	if want := "/\\" + strings.Repeat(string(MaskRune), 2) + "e\u0301e\u0301"; masked.Rows[0] != want {
+		t.Errorf("masked row = %q, want %q", masked.Rows[0], want)
+	}
+}
+
+// columnScheme records the columns it is asked for colors of.
+type columnScheme struct {
+	cols []int
+}
+
+func (s *columnScheme) ColorAt(frame, line, col int) Color {
+	s.cols = append(s.cols, col)
+	return Color{R: uint8(col)}
+}
+
+func TestColorizeMixedWidth(t *testing.T) {
+	scheme := &columnScheme{}
+	got := Colorize("日e\u0301x", scheme, 0)
+
+	// The accent takes no column and the color of its e
+	if want := []int{0, 2, 3}; !reflect.DeepEqual(scheme.cols, want) {
+		t.Errorf("colored columns = %v, want %v", scheme.cols, want)
+	}
+	if !strings.Contains(got, "e\u0301") {
+		t.Errorf("Colorize() = %q, split the e from its accent", got)
+	}
+}
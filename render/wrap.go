@@ -0,0 +1,252 @@
+package render
+
+import (
+	"context"
+	"strings"
+)
+
+// WrapText inserts line breaks between words so that each line of text,
+// drawn with font, is at most width columns wide. Existing line breaks are
+// kept, and a word too wide for a line of its own is left unbroken. A
+// width below 1 returns text unchanged.
+//
+// Parameters:
+//   - ctx: bounds how long measuring may take
+//   - font: the font the text will be drawn with
+//   - text: the text to wrap
+//   - width: the widest a rendered line may be, in columns
+//
+// Returns:
+//   - string: the text with line breaks added
+//   - error: error if the font cannot be read or ctx is done
+//
+// Example:
+//
+//	font := cache.GetFontOrDefault("small", DefaultFont)
+//	wrapped, err := WrapText(ctx, font, "THE QUICK BROWN FOX", 40)
+func WrapText(ctx context.Context, font *Font, text string, width int) (string, error) {
+	if width < 1 {
+		return text, nil
+	}
+
+	// Glyphs are drawn side by side, so a line is as wide as its words
+	// plus the spaces between them
+	measure := func(s string) (int, error) {
+		layout, err := font.LayoutContext(ctx, s)
+		if err != nil {
+			return 0, err
+		}
+		spans := layout.Spans[0]
+		if len(spans) == 0 {
+			return 0, nil
+		}
+		return spans[len(spans)-1].End, nil
+	}
+	space, err := measure(" ")
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		var b strings.Builder
+		lineWidth := 0
+		for _, word := range strings.Fields(line) {
+			wordWidth, err := measure(word)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case lineWidth == 0:
+				lineWidth = wordWidth
+			case lineWidth+space+wordWidth <= width:
+				b.WriteByte(' ')
+				lineWidth += space + wordWidth
+			default:
+				b.WriteByte('\n')
+				lineWidth = wordWidth
+			}
+			b.WriteString(word)
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// AlignLayout places each text line of layout at the center or right of a
+// terminal width columns wide, shifting its rows and glyph spans together
+// so later stages such as coloring still line up. Lines already too wide
+// stay where they are. The layout is returned as is for left alignment or
+// a width below 1; otherwise a shifted copy is returned.
+//
+// Parameters:
+//   - layout: the rendered layout
+//   - width: the terminal width in columns
+//   - align: "left", "center" or "right"
+//
+// Returns:
+//   - *Layout: the aligned layout
+//
+// Example:
+//
+//	layout = AlignLayout(layout, 80, "center")
+func AlignLayout(layout *Layout, width int, align string) *Layout {
+	if width < 1 || align != "center" && align != "right" {
+		return layout
+	}
+
+	widths := make([]int, len(layout.Spans))
+	for row, line := range layout.RowLines {
+		widths[line] = max(widths[line], StringWidth(layout.Rows[row]))
+	}
+	offsets := make([]int, len(widths))
+	for line, lineWidth := range widths {
+		offsets[line] = max(width-lineWidth, 0)
+		if align == "center" {
+			offsets[line] /= 2
+		}
+	}
+
+	out := *layout
+	out.Rows = make([]string, len(layout.Rows))
+	for row, text := range layout.Rows {
+		if text != "" {
+			text = strings.Repeat(" ", offsets[layout.RowLines[row]]) + text
+		}
+		out.Rows[row] = text
+	}
+	out.Spans = make([][]GlyphSpan, len(layout.Spans))
+	for line, spans := range layout.Spans {
+		for _, span := range spans {
+			span.Start += offsets[line]
+			span.End += offsets[line]
+			out.Spans[line] = append(out.Spans[line], span)
+		}
+	}
+	return &out
+}
+
+// AppendSection adds section, a line of plain text, below output, a
+// drawn banner, aligned as the banner is: at its left edge, centered
+// under it, or ending at its right edge. Output is returned as is for an
+// empty section.
+//
+// Parameters:
+//   - output: the drawn banner, each line ending in a newline
+//   - section: the line to add
+//   - align: "left", "center" or "right"
+//
+// Returns:
+//   - string: output with the section added, ending in a newline
+//
+// Example:
+//
+//	out := AppendSection("  HELLO\n", "by me", "right")
+//	// "  HELLO\n  by me\n"
+func AppendSection(output, section, align string) string {
+	if section == "" {
+		return output
+	}
+	left, right := -1, 0
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		line = StripANSI(line)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if left < 0 || indent < left {
+			left = indent
+		}
+		right = max(right, StringWidth(line))
+	}
+	left = max(left, 0)
+
+	offset := left
+	switch width := StringWidth(section); align {
+	case "center":
+		offset = max(left+(right-left-width)/2, 0)
+	case "right":
+		offset = max(right-width, 0)
+	}
+	return output + strings.Repeat(" ", offset) + section + "\n"
+}
+
+// PadLayout pads every row of layout with spaces to width, so animations
+// that move the banner, such as scroll, use the whole terminal width.
+// Layout is returned as is for a width below 1.
+//
+// Parameters:
+//   - layout: the rendered layout
+//   - width: the terminal width in columns
+//
+// Returns:
+//   - *Layout: the padded layout
+//
+// Example:
+//
+//	layout = PadLayout(AlignLayout(layout, 80, "center"), 80)
+func PadLayout(layout *Layout, width int) *Layout {
+	if width < 1 {
+		return layout
+	}
+	out := *layout
+	out.Rows = make([]string, len(layout.Rows))
+	for row, text := range layout.Rows {
+		out.Rows[row] = text + strings.Repeat(" ", max(width-StringWidth(text), 0))
+	}
+	return &out
+}
+
+// CropLayout cuts layout down to cols columns and rows rows, keeping the
+// top left. A size below 1 leaves that dimension uncropped.
+//
+// Parameters:
+//   - layout: the rendered layout
+//   - cols: the terminal width in columns
+//   - rows: the terminal height in rows
+//
+// Returns:
+//   - *Layout: the cropped layout
+//
+// Example:
+//
+//	frame := CropLayout(layout, 79, 23)
+func CropLayout(layout *Layout, cols, rows int) *Layout {
+	if cols < 1 && rows < 1 {
+		return layout
+	}
+	out := *layout
+	if rows > 0 && len(out.Rows) > rows {
+		out.Rows, out.RowLines = out.Rows[:rows], out.RowLines[:rows]
+	}
+	if cols > 0 {
+		cropped := make([]string, len(out.Rows))
+		for i, row := range out.Rows {
+			if cells := Cells(row); len(cells) > cols {
+				row = JoinCells(cells[:cols])
+			}
+			cropped[i] = row
+		}
+		out.Rows = cropped
+	}
+	return &out
+}
+
+// LayoutWidth returns the width of the widest row of layout, at least 1.
+//
+// Parameters:
+//   - layout: the rendered layout
+//
+// Returns:
+//   - int: the width in columns
+//
+// Example:
+//
+//	frames := 2 * LayoutWidth(layout)
+func LayoutWidth(layout *Layout) int {
+	width := 1
+	for _, row := range layout.Rows {
+		width = max(width, StringWidth(row))
+	}
+	return width
+}
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+func TestWrapText(t *testing.T) {
+	cache := NewFontCache()
+	if err := cache.LoadFonts(config.FontConfig{Path: "../fonts", Allowed: []string{"small"}}); err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+	font := cache.GetFontOrDefault("small", DefaultFont)
+
+	width := func(s string) int {
+		layout, err := font.Layout(s)
+		if err != nil {
+			t.Fatalf("layout %q: %v", s, err)
+		}
+		spans := layout.Spans[0]
+		return spans[len(spans)-1].End
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "fits", text: "HI THERE", width: width("HI THERE"), want: "HI THERE"},
+		{name: "one column short", text: "HI THERE", width: width("HI THERE") - 1, want: "HI\nTHERE"},
+		{name: "greedy", text: "A B C D", width: width("A B"), want: "A B\nC D"},
+		{name: "keeps line breaks", text: "A B\nC", width: 1000, want: "A B\nC"},
+		{name: "long word is not broken", text: "A WONDERFUL DAY", width: width("A"), want: "A\nWONDERFUL\nDAY"},
+		{name: "collapses spaces", text: "A   B", width: 1000, want: "A B"},
+		{name: "no limit", text: "A   B", width: 0, want: "A   B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WrapText(context.Background(), font, tt.text, tt.width)
+			if err != nil {
+				t.Fatalf("WrapText() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("WrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WrapText(ctx, font, "A B", 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestAlignLayout(t *testing.T) {
+	layout := &Layout{
+		Rows:     []string{"ab", "abcd"},
+		RowLines: []int{0, 1},
+		Spans: [][]GlyphSpan{
+			{{Index: 0, Line: 0, Start: 0, End: 2}},
+			{{Index: 2, Line: 1, Start: 0, End: 4}},
+		},
+	}
+
+	tests := []struct {
+		align  string
+		cols   int
+		want   []string
+		starts []int
+	}{
+		{align: "left", cols: 8, want: []string{"ab", "abcd"}, starts: []int{0, 0}},
+		{align: "center", cols: 8, want: []string{"   ab", "  abcd"}, starts: []int{3, 2}},
+		{align: "right", cols: 8, want: []string{"      ab", "    abcd"}, starts: []int{6, 4}},
+		{align: "center", cols: 0, want: []string{"ab", "abcd"}, starts: []int{0, 0}},
+		{align: "right", cols: 3, want: []string{" ab", "abcd"}, starts: []int{1, 0}},
+	}
+
+	for _, tt := range tests {
+		got := AlignLayout(layout, tt.cols, tt.align)
+		if !reflect.DeepEqual(got.Rows, tt.want) {
+			t.Errorf("%s in %d cols: rows = %q, want %q", tt.align, tt.cols, got.Rows, tt.want)
+		}
+		for line, start := range tt.starts {
+			if got.Spans[line][0].Start != start {
+				t.Errorf("%s in %d cols: line %d starts at %d, want %d", tt.align, tt.cols, line, got.Spans[line][0].Start, start)
+			}
+		}
+	}
+	if layout.Rows[0] != "ab" || layout.Spans[0][0].Start != 0 {
+		t.Error("AlignLayout modified its input")
+	}
+}
+
+func TestAppendSection(t *testing.T) {
+	banner := "  _ _\n |_|_|\n"
+
+	tests := []struct {
+		name    string
+		output  string
+		section string
+		align   string
+		want    string
+	}{
+		{name: "left", output: banner, section: "hi", align: "left", want: banner + " hi\n"},
+		{name: "center", output: banner, section: "hi", align: "center", want: banner + "  hi\n"},
+		{name: "right", output: banner, section: "hi", align: "right", want: banner + "    hi\n"},
+		{name: "wider than the banner", output: banner, section: "hello world", align: "center", want: banner + "hello world\n"},
+		{name: "colors aren't measured", output: "\x1b[31mAB\x1b[0m\n", section: "x", align: "right", want: "\x1b[31mAB\x1b[0m\n x\n"},
+		{name: "empty section", output: banner, section: "", align: "center", want: banner},
+	}
+
+	for _, tt := range tests {
+		if got := AppendSection(tt.output, tt.section, tt.align); got != tt.want {
+			t.Errorf("%s: AppendSection() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Package rendercache caches rendered banners, so popular ones are drawn
+// once: in memory on each instance and, for a fleet, in Redis, where one
+// instance renders a missing banner while the others wait for it.
+package rendercache
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// Cache keeps rendered banners by key, such as the banner's ETag. Banners
+// are looked for in memory, then in Redis, and rendered on a miss by one
+// caller at a time: concurrent misses on an instance wait for the first,
+// and with Redis, instances wait for the one holding the banner's lock.
+// Redis errors are logged and the banner rendered, so an outage only
+// costs renders.
+//
+// A nil Cache renders every banner. The type is safe for concurrent use.
+//
+// Usage example:
+//
+//	cache, err := rendercache.Open(cfg.Cache)
+//	if err != nil {
+//	    log.Fatalf("Failed to open render cache: %v", err)
+//	}
+//	defer cache.Close()
+//	output, err := cache.Get(ctx, etag, func() (string, error) { return render(text) })
+type Cache struct {
+	cfg    config.CacheConfig
+	local  *memoryTier // nil when SHOUT_CACHE_ENTRIES is 0
+	shared *redisTier  // nil without SHOUT_CACHE_REDIS_URL
+
+	mu       sync.Mutex
+	inflight map[string]*call
+
+	hits       int64
+	sharedHits int64
+	misses     int64
+}
+
+// call is a render in flight, which concurrent misses on its key wait for.
+type call struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// Stats reports how the render cache has fared.
+//
+// Usage example:
+//
+//	stats := cache.Stats()
+//	fmt.Printf("%d hits, %d misses\n", stats.Hits+stats.SharedHits, stats.Misses)
+type Stats struct {
+	// Entries is the number of banners held in memory.
+	Entries int `json:"entries"`
+	// Hits counts banners served from memory.
+	Hits int64 `json:"hits"`
+	// SharedHits counts banners served from Redis, rendered by another
+	// instance or before a restart.
+	SharedHits int64 `json:"sharedHits"`
+	// Misses counts banners that had to be rendered.
+	Misses int64 `json:"misses"`
+}
+
+// Open creates the render cache the configuration describes.
+//
+// Parameters:
+//   - cfg: render cache settings
+//
+// Returns:
+//   - *Cache: the cache, or nil when it keeps nothing in memory or Redis
+//   - error: error if Redis can't be reached
+//
+// Example:
+//
+//	cache, err := rendercache.Open(cfg.Cache)
+//	if err != nil {
+//	    log.Fatalf("Failed to open render cache: %v", err)
+//	}
+func Open(cfg config.CacheConfig) (*Cache, error) {
+	if cfg.Entries == 0 && cfg.RedisURL == "" {
+		return nil, nil
+	}
+	c := &Cache{cfg: cfg, inflight: make(map[string]*call)}
+	if cfg.Entries > 0 {
+		c.local = newMemoryTier(cfg.Entries)
+	}
+	if cfg.RedisURL != "" {
+		shared, err := newRedisTier(cfg.RedisURL, cfg.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		c.shared = shared
+	}
+	return c, nil
+}
+
+// Get returns the banner cached under key, calling render to draw it on a
+// miss. Banners render fails for aren't cached. An empty key, or a nil
+// Cache, always renders.
+//
+// Parameters:
+//   - ctx: bounds the Redis calls and the wait for another render
+//   - key: identifies the banner, and must change with anything that
+//     changes its output
+//   - render: draws the banner
+//
+// Returns:
+//   - string: the banner
+//   - error: the error render returned
+//
+// Example:
+//
+//	output, err := cache.Get(ctx, etag, func() (string, error) {
+//	    return renderer.Render(ctx, text, opts)
+//	})
+func (c *Cache) Get(ctx context.Context, key string, render func() (string, error)) (string, error) {
+	if c == nil || key == "" {
+		return render()
+	}
+	if c.local != nil {
+		if value, ok := c.local.get(key); ok {
+			atomic.AddInt64(&c.hits, 1)
+			return value, nil
+		}
+	}
+
+	c.mu.Lock()
+	if pending, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-pending.done:
+			return pending.value, pending.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	pending := &call{done: make(chan struct{})}
+	c.inflight[key] = pending
+	c.mu.Unlock()
+
+	pending.value, pending.err = c.fetch(ctx, key, render)
+	if pending.err == nil && c.local != nil {
+		c.local.set(key, pending.value, c.cfg.TTL)
+	}
+	c.mu.Lock()
+	delete(c.inflight, key)
+	c.mu.Unlock()
+	close(pending.done)
+	return pending.value, pending.err
+}
+
+// fetch returns the banner from Redis, waiting for another instance that
+// is rendering it, or renders it and shares it through Redis.
+func (c *Cache) fetch(ctx context.Context, key string, render func() (string, error)) (string, error) {
+	if c.shared == nil {
+		atomic.AddInt64(&c.misses, 1)
+		return render()
+	}
+
+	value, ok, err := c.shared.get(ctx, key)
+	if err != nil {
+		log.Printf("Warning: render cache unavailable: %v", err)
+		atomic.AddInt64(&c.misses, 1)
+		return render()
+	}
+	if ok {
+		atomic.AddInt64(&c.sharedHits, 1)
+		return value, nil
+	}
+
+	locked, err := c.shared.lock(ctx, key, c.cfg.LockTimeout)
+	if err != nil {
+		log.Printf("Warning: render cache unavailable: %v", err)
+	}
+	if err == nil && !locked {
+		value, ok, err := c.shared.wait(ctx, key, c.cfg.LockTimeout)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Warning: render cache unavailable: %v", err)
+		}
+		if ok {
+			atomic.AddInt64(&c.sharedHits, 1)
+			return value, nil
+		}
+	}
+
+	atomic.AddInt64(&c.misses, 1)
+	value, err = render()
+	if err == nil {
+		if err := c.shared.set(ctx, key, value, c.cfg.TTL); err != nil {
+			log.Printf("Warning: render cache unavailable: %v", err)
+		}
+	}
+	if locked {
+		if err := c.shared.unlock(ctx, key); err != nil {
+			log.Printf("Warning: render cache unavailable: %v", err)
+		}
+	}
+	return value, err
+}
+
+// Stats reports the cache's occupancy and hit counts. A nil Cache reports
+// zeroes.
+//
+// Returns:
+//   - Stats: the current counters
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	stats := Stats{
+		Hits:       atomic.LoadInt64(&c.hits),
+		SharedHits: atomic.LoadInt64(&c.sharedHits),
+		Misses:     atomic.LoadInt64(&c.misses),
+	}
+	if c.local != nil {
+		stats.Entries = c.local.len()
+	}
+	return stats
+}
+
+// Close closes the connection to Redis, if there is one.
+func (c *Cache) Close() error {
+	if c == nil || c.shared == nil {
+		return nil
+	}
+	return c.shared.close()
+}
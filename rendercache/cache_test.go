@@ -0,0 +1,181 @@
+package rendercache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// counter renders value, counting its calls.
+type counter struct {
+	calls int64
+	value string
+	delay time.Duration
+}
+
+func (r *counter) render() (string, error) {
+	atomic.AddInt64(&r.calls, 1)
+	time.Sleep(r.delay)
+	return r.value, nil
+}
+
+func testConfig(redisURL string) config.CacheConfig {
+	return config.CacheConfig{
+		Entries:     2,
+		TTL:         time.Minute,
+		RedisURL:    redisURL,
+		Namespace:   "test:",
+		LockTimeout: time.Second,
+	}
+}
+
+func TestOpenDisabled(t *testing.T) {
+	cache, err := Open(config.CacheConfig{})
+	if err != nil || cache != nil {
+		t.Fatalf("Open() = %v, %v, want nil cache", cache, err)
+	}
+	r := &counter{value: "HELLO"}
+	for range 2 {
+		if got, err := cache.Get(context.Background(), "k", r.render); err != nil || got != "HELLO" {
+			t.Fatalf("Get() = %q, %v", got, err)
+		}
+	}
+	if r.calls != 2 {
+		t.Errorf("renders = %d, want 2 without a cache", r.calls)
+	}
+	if stats := cache.Stats(); stats != (Stats{}) {
+		t.Errorf("Stats() = %+v, want zeroes", stats)
+	}
+}
+
+func TestCacheMemory(t *testing.T) {
+	cache, err := Open(testConfig(""))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ctx := context.Background()
+	a, b, c := &counter{value: "A"}, &counter{value: "B"}, &counter{value: "C"}
+
+	cache.Get(ctx, "a", a.render)
+	cache.Get(ctx, "b", b.render)
+	if got, _ := cache.Get(ctx, "a", a.render); got != "A" || a.calls != 1 {
+		t.Errorf("Get(a) = %q after %d renders, want A from memory", got, a.calls)
+	}
+	// c pushes out b, the least recently used
+	cache.Get(ctx, "c", c.render)
+	cache.Get(ctx, "b", b.render)
+	if b.calls != 2 {
+		t.Errorf("b renders = %d, want 2 after it was dropped", b.calls)
+	}
+
+	failed := func() (string, error) { return "", errors.New("boom") }
+	if _, err := cache.Get(ctx, "d", failed); err == nil {
+		t.Error("Get() should return the render's error")
+	}
+	d := &counter{value: "D"}
+	if got, _ := cache.Get(ctx, "d", d.render); got != "D" || d.calls != 1 {
+		t.Errorf("Get(d) = %q, want a failed render not to be cached", got)
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Hits != 1 || stats.Misses != 6 {
+		t.Errorf("Stats() = %+v, want 2 entries, 1 hit and 6 misses", stats)
+	}
+}
+
+func TestCacheStampede(t *testing.T) {
+	cache, err := Open(testConfig(""))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	r := &counter{value: "HELLO", delay: 50 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := cache.Get(context.Background(), "k", r.render); err != nil || got != "HELLO" {
+				t.Errorf("Get() = %q, %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if r.calls != 1 {
+		t.Errorf("renders = %d, want 1 for concurrent misses", r.calls)
+	}
+}
+
+func TestCacheRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+
+	first, err := Open(testConfig("redis://" + server.Addr()))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer first.Close()
+	second, err := Open(testConfig("redis://" + server.Addr()))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer second.Close()
+
+	r := &counter{value: "HELLO"}
+	first.Get(ctx, "k", r.render)
+	if got, _ := second.Get(ctx, "k", r.render); got != "HELLO" || r.calls != 1 {
+		t.Errorf("second Get() = %q after %d renders, want HELLO from Redis", got, r.calls)
+	}
+	if stats := second.Stats(); stats.SharedHits != 1 {
+		t.Errorf("Stats() = %+v, want a shared hit", stats)
+	}
+	if !server.Exists("test:k") || server.TTL("test:k") != time.Minute {
+		t.Errorf("Redis key test:k missing or without the TTL: %v", server.Keys())
+	}
+
+	// Another instance rendering the banner holds its lock
+	server.Set("test:slow:lock", "1")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		server.Set("test:slow", "SLOW")
+		server.Del("test:slow:lock")
+	}()
+	slow := &counter{value: "SLOW"}
+	if got, _ := first.Get(ctx, "slow", slow.render); got != "SLOW" || slow.calls != 0 {
+		t.Errorf("Get() = %q after %d renders, want the other instance's banner", got, slow.calls)
+	}
+
+	// A lock whose holder failed is given up on
+	server.Set("test:failed:lock", "1")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		server.Del("test:failed:lock")
+	}()
+	failed := &counter{value: "FAILED"}
+	if got, _ := first.Get(ctx, "failed", failed.render); got != "FAILED" || failed.calls != 1 {
+		t.Errorf("Get() = %q after %d renders, want it rendered", got, failed.calls)
+	}
+
+	// An outage costs renders, not requests
+	server.Close()
+	down := &counter{value: "DOWN"}
+	if got, err := second.Get(ctx, "down", down.render); err != nil || got != "DOWN" {
+		t.Errorf("Get() during an outage = %q, %v", got, err)
+	}
+}
+
+func TestOpenRedisUnreachable(t *testing.T) {
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	if _, err := Open(testConfig("redis://" + addr)); err == nil {
+		t.Error("Open should fail when Redis is unreachable")
+	}
+}
@@ -0,0 +1,78 @@
+package rendercache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// memoryTier keeps recently used banners in memory, dropping the least
+// recently used beyond maxEntries and any older than their TTL.
+//
+// The type is safe for concurrent use.
+type memoryTier struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+}
+
+// memoryEntry is one banner held in memory.
+type memoryEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+func newMemoryTier(maxEntries int) *memoryTier {
+	return &memoryTier{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the banner under key, if it's held and hasn't expired.
+func (m *memoryTier) get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+		return "", false
+	}
+	m.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set holds value under key for ttl, dropping the least recently used
+// banner if the tier is full.
+func (m *memoryTier) set(key, value string, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expires := time.Now().Add(ttl)
+	if elem, ok := m.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value, entry.expires = value, expires
+		m.order.MoveToFront(elem)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// len returns the number of banners held.
+func (m *memoryTier) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
@@ -0,0 +1,114 @@
+package rendercache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisDialTimeout bounds the connection check in newRedisTier.
+const redisDialTimeout = 5 * time.Second
+
+// lockPoll is how often an instance waiting on another's render looks for
+// the banner in Redis.
+var lockPoll = 50 * time.Millisecond
+
+// redisTier shares banners between instances through Redis. Keys are the
+// namespace followed by the banner's key, and expire with their TTL; the
+// render lock of a banner is its key followed by ":lock".
+type redisTier struct {
+	client    *redis.Client
+	namespace string
+}
+
+// newRedisTier connects to Redis and checks the connection.
+func newRedisTier(rawURL, namespace string) (*redisTier, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisDialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &redisTier{client: client, namespace: namespace}, nil
+}
+
+// get returns the banner under key, or false if Redis doesn't hold it.
+func (r *redisTier) get(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.client.Get(ctx, r.namespace+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read cached banner: %w", err)
+	}
+	return value, true, nil
+}
+
+// set stores value under key for ttl.
+func (r *redisTier) set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, r.namespace+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache banner: %w", err)
+	}
+	return nil
+}
+
+// lock takes the render lock of key for timeout, reporting whether this
+// instance got it and should render the banner.
+func (r *redisTier) lock(ctx context.Context, key string, timeout time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, r.namespace+key+":lock", 1, timeout).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to lock banner: %w", err)
+	}
+	return ok, nil
+}
+
+// unlock releases the render lock of key.
+func (r *redisTier) unlock(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.namespace+key+":lock").Err()
+}
+
+// wait polls Redis for the banner under key while another instance renders
+// it, for up to timeout. It returns false if the banner didn't appear, as
+// when the other instance failed.
+func (r *redisTier) wait(ctx context.Context, key string, timeout time.Duration) (string, bool, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	tick := time.NewTicker(lockPoll)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		case <-deadline.C:
+			return "", false, nil
+		case <-tick.C:
+		}
+		value, ok, err := r.get(ctx, key)
+		if err != nil || ok {
+			return value, ok, err
+		}
+		// Once the lock is gone the banner is either there or its render
+		// failed, so there's nothing more to wait for
+		locked, err := r.client.Exists(ctx, r.namespace+key+":lock").Result()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check banner lock: %w", err)
+		}
+		if locked == 0 {
+			return r.get(ctx, key)
+		}
+	}
+}
+
+// close closes the connection pool.
+func (r *redisTier) close() error {
+	return r.client.Close()
+}
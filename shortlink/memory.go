@@ -0,0 +1,116 @@
+package shortlink
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps specs in process memory, holding at most a fixed
+// number of entries. When full, the oldest entry is dropped. Entries are
+// lost on restart.
+//
+// Usage example:
+//
+//	store := NewMemoryStore(10000)
+//	err := store.Save(ctx, "abcd2345", spec, 24*time.Hour)
+type MemoryStore struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front is the oldest entry
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// memoryEntry is one saved spec.
+type memoryEntry struct {
+	id      string
+	spec    Spec
+	expires time.Time
+}
+
+// NewMemoryStore creates an in-memory store.
+//
+// Parameters:
+//   - maxEntries: the most specs kept at once; below 1 means unlimited
+//
+// Returns:
+//   - *MemoryStore: an empty store
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Save stores spec under id, dropping expired entries and, if the store is
+// still full, the oldest one.
+func (m *MemoryStore) Save(_ context.Context, id string, spec Spec, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.expireLocked(now)
+	if elem, ok := m.entries[id]; ok {
+		if now.Before(elem.Value.(*memoryEntry).expires) {
+			return ErrExists
+		}
+		m.removeLocked(elem)
+	}
+	for m.max > 0 && len(m.entries) >= m.max {
+		m.removeLocked(m.order.Front())
+	}
+
+	m.entries[id] = m.order.PushBack(&memoryEntry{id: id, spec: spec, expires: now.Add(ttl)})
+	return nil
+}
+
+// Load returns the spec saved under id.
+func (m *MemoryStore) Load(_ context.Context, id string) (Spec, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[id]
+	if !ok {
+		return Spec{}, ErrNotFound
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !m.now().Before(entry.expires) {
+		m.removeLocked(elem)
+		return Spec{}, ErrNotFound
+	}
+	return entry.spec, nil
+}
+
+// Close is a no-op; the store is garbage collected with its entries.
+func (m *MemoryStore) Close() error { return nil }
+
+// Len returns the number of entries held, including expired ones not yet
+// dropped.
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// expireLocked drops expired entries from the front of the list. Entries
+// saved with a shorter TTL than an older one may outlive their expiry
+// until they reach the front, but Load never returns them. The caller
+// must hold m.mu.
+func (m *MemoryStore) expireLocked(now time.Time) {
+	for elem := m.order.Front(); elem != nil; elem = m.order.Front() {
+		if now.Before(elem.Value.(*memoryEntry).expires) {
+			return
+		}
+		m.removeLocked(elem)
+	}
+}
+
+// removeLocked drops an entry. The caller must hold m.mu.
+func (m *MemoryStore) removeLocked(elem *list.Element) {
+	entry := m.order.Remove(elem).(*memoryEntry)
+	delete(m.entries, entry.id)
+}
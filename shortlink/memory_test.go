@@ -0,0 +1,57 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storeContract(t, NewMemoryStore(10))
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	store := NewMemoryStore(10)
+	store.now = func() time.Time { return now }
+
+	if err := store.Save(ctx, "abcd2345", Spec{Text: "A"}, time.Minute); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if _, err := store.Load(ctx, "abcd2345"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load after expiry error = %v, want ErrNotFound", err)
+	}
+	// An expired ID can be reused
+	if err := store.Save(ctx, "abcd2345", Spec{Text: "B"}, time.Minute); err != nil {
+		t.Errorf("Save over expired entry failed: %v", err)
+	}
+	if store.Len() != 1 {
+		t.Errorf("Len = %d, want 1", store.Len())
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	for _, id := range []string{"aaaa2345", "bbbb2345", "cccc2345"} {
+		if err := store.Save(ctx, id, Spec{Text: id}, time.Hour); err != nil {
+			t.Fatalf("Save(%s) failed: %v", id, err)
+		}
+	}
+
+	if store.Len() != 2 {
+		t.Errorf("Len = %d, want 2", store.Len())
+	}
+	if _, err := store.Load(ctx, "aaaa2345"); !errors.Is(err, ErrNotFound) {
+		t.Error("oldest entry should be evicted")
+	}
+	for _, id := range []string{"bbbb2345", "cccc2345"} {
+		if _, err := store.Load(ctx, id); err != nil {
+			t.Errorf("Load(%s) failed: %v", id, err)
+		}
+	}
+}
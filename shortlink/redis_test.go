@@ -0,0 +1,40 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := NewRedisStore("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedisStore failed: %v", err)
+	}
+	defer store.Close()
+
+	storeContract(t, store)
+
+	// Keys expire with their TTL
+	if err := store.Save(context.Background(), "ttl23456", Spec{Text: "A"}, time.Minute); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	server.FastForward(time.Minute)
+	if _, err := store.Load(context.Background(), "ttl23456"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load after expiry error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNewRedisStoreUnreachable(t *testing.T) {
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	if _, err := NewRedisStore("redis://" + addr); err == nil {
+		t.Error("NewRedisStore should fail when the server is unreachable")
+	}
+}
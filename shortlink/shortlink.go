@@ -0,0 +1,159 @@
+// Package shortlink saves render specs under short random IDs so a shout
+// can be shared as a short URL and replayed later with the same options.
+package shortlink
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+// Store backends, configured with SHOUT_SHORTLINK_STORE.
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// IDLength is the number of characters in a short link ID.
+const IDLength = 8
+
+// idAlphabet leaves out characters that are easily confused, such as 0/O
+// and 1/l/I.
+const idAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// idAttempts is how many IDs Create tries before giving up on collisions.
+const idAttempts = 3
+
+var (
+	// ErrNotFound is returned by Load for unknown or expired IDs.
+	ErrNotFound = errors.New("short link not found")
+	// ErrExists is returned by Save when the ID is already taken.
+	ErrExists = errors.New("short link ID already exists")
+)
+
+// Spec is a saved render request: the text and the options to render it
+// with. Options are stored flat alongside the text, using their JSON names.
+type Spec struct {
+	Text string `json:"text"`
+	types.RenderOptions
+}
+
+// Store keeps specs until their TTL expires. Implementations must be safe
+// for concurrent use.
+type Store interface {
+	// Save stores spec under id for ttl. It returns ErrExists if id is taken.
+	Save(ctx context.Context, id string, spec Spec, ttl time.Duration) error
+	// Load returns the spec saved under id, or ErrNotFound.
+	Load(ctx context.Context, id string) (Spec, error)
+	// Close releases the store's resources.
+	Close() error
+}
+
+// Open creates the store selected by the configuration.
+//
+// Parameters:
+//   - cfg: short link settings
+//
+// Returns:
+//   - Store: the store, or nil when short links are disabled
+//   - error: error if the store cannot be created or reached
+//
+// Example:
+//
+//	store, err := shortlink.Open(cfg.Shortlinks)
+//	if err != nil {
+//	    log.Fatalf("Failed to open short link store: %v", err)
+//	}
+func Open(cfg config.ShortlinkConfig) (Store, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Store {
+	case StoreMemory, "":
+		return NewMemoryStore(cfg.MaxEntries), nil
+	case StoreRedis:
+		return NewRedisStore(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown short link store: %q", cfg.Store)
+	}
+}
+
+// Create saves spec under a new random ID, retrying on the rare ID
+// collision.
+//
+// Parameters:
+//   - ctx: context for the store calls
+//   - store: where to save the spec
+//   - spec: the render request to save
+//   - ttl: how long the spec lives
+//
+// Returns:
+//   - string: the new ID
+//   - error: error if the spec cannot be saved
+//
+// Example:
+//
+//	id, err := shortlink.Create(ctx, store, spec, cfg.Shortlinks.TTL)
+func Create(ctx context.Context, store Store, spec Spec, ttl time.Duration) (string, error) {
+	for i := 0; i < idAttempts; i++ {
+		id, err := NewID()
+		if err != nil {
+			return "", err
+		}
+		err = store.Save(ctx, id, spec, ttl)
+		if errors.Is(err, ErrExists) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return id, nil
+	}
+	return "", fmt.Errorf("failed to find a free ID after %d attempts", idAttempts)
+}
+
+// NewID returns a random short link ID.
+//
+// Returns:
+//   - string: IDLength characters from an unambiguous alphabet
+//   - error: error if the system random source fails
+func NewID() (string, error) {
+	max := big.NewInt(int64(len(idAlphabet)))
+	id := make([]byte, IDLength)
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate ID: %w", err)
+		}
+		id[i] = idAlphabet[n.Int64()]
+	}
+	return string(id), nil
+}
+
+// ValidID reports whether id could have been returned by NewID, so
+// lookups of malformed IDs can be answered without touching the store.
+//
+// Parameters:
+//   - id: the ID to check
+//
+// Returns:
+//   - bool: true if id has the right length and alphabet
+func ValidID(id string) bool {
+	if len(id) != IDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(idAlphabet, id[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
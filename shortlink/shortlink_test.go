@@ -0,0 +1,137 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/types"
+)
+
+func TestNewID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := NewID()
+		if err != nil {
+			t.Fatalf("NewID failed: %v", err)
+		}
+		if !ValidID(id) {
+			t.Errorf("NewID returned invalid ID %q", id)
+		}
+		if seen[id] {
+			t.Errorf("NewID returned duplicate ID %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestValidID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"abcd2345", true},
+		{"ABCD2345", true},
+		{"abcd234", false},
+		{"abcd23456", false},
+		{"abcd2340", false}, // 0 is not in the alphabet
+		{"abcd/345", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidID(tt.id); got != tt.want {
+			t.Errorf("ValidID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+// collidingStore reports the first saves as collisions.
+type collidingStore struct {
+	*MemoryStore
+	collisions int
+}
+
+func (s *collidingStore) Save(ctx context.Context, id string, spec Spec, ttl time.Duration) error {
+	if s.collisions > 0 {
+		s.collisions--
+		return ErrExists
+	}
+	return s.MemoryStore.Save(ctx, id, spec, ttl)
+}
+
+func TestCreate(t *testing.T) {
+	spec := Spec{Text: "HELLO", RenderOptions: types.RenderOptions{Font: "doom"}}
+
+	tests := []struct {
+		name       string
+		collisions int
+		wantErr    bool
+	}{
+		{name: "first try", collisions: 0},
+		{name: "retries collisions", collisions: idAttempts - 1},
+		{name: "gives up", collisions: idAttempts, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &collidingStore{MemoryStore: NewMemoryStore(10), collisions: tt.collisions}
+			id, err := Create(context.Background(), store, spec, time.Hour)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := store.Load(context.Background(), id)
+			if err != nil || got != spec {
+				t.Errorf("Load(%q) = %+v, %v; want %+v", id, got, err, spec)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, err := Open(config.ShortlinkConfig{Enabled: false, Store: StoreMemory})
+	if err != nil || store != nil {
+		t.Errorf("disabled Open() = %v, %v; want nil, nil", store, err)
+	}
+
+	store, err = Open(config.ShortlinkConfig{Enabled: true, Store: StoreMemory, MaxEntries: 5})
+	if err != nil {
+		t.Fatalf("Open(memory) failed: %v", err)
+	}
+	if _, ok := store.(*MemoryStore); !ok {
+		t.Errorf("Open(memory) = %T, want *MemoryStore", store)
+	}
+
+	if _, err := Open(config.ShortlinkConfig{Enabled: true, Store: "sqlite"}); err == nil {
+		t.Error("Open should reject unknown stores")
+	}
+	if _, err := Open(config.ShortlinkConfig{Enabled: true, Store: StoreRedis, RedisURL: "not a url"}); err == nil {
+		t.Error("Open should reject invalid Redis URLs")
+	}
+}
+
+// storeContract checks the behavior every Store must share.
+func storeContract(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	spec := Spec{Text: "HI", RenderOptions: types.RenderOptions{Font: "small", Color: "fire", Speed: 3}}
+
+	if err := store.Save(ctx, "abcd2345", spec, time.Hour); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save(ctx, "abcd2345", spec, time.Hour); !errors.Is(err, ErrExists) {
+		t.Errorf("second Save error = %v, want ErrExists", err)
+	}
+	got, err := store.Load(ctx, "abcd2345")
+	if err != nil || got != spec {
+		t.Errorf("Load = %+v, %v; want %+v", got, err, spec)
+	}
+	if _, err := store.Load(ctx, "zzzz2345"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load of unknown ID error = %v, want ErrNotFound", err)
+	}
+}
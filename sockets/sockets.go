@@ -0,0 +1,389 @@
+// Package sockets provides the server's listening sockets: inherited from
+// systemd socket activation, inherited from the process being upgraded,
+// or opened afresh. Upgrade hands them to a new copy of the binary, so a
+// deploy restarts the server without refusing connections or cutting
+// streams short.
+package sockets
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables sockets are inherited through.
+const (
+	// envListenPID, envListenFDs and envListenFDNames are set by systemd
+	// socket activation
+	envListenPID     = "LISTEN_PID"
+	envListenFDs     = "LISTEN_FDS"
+	envListenFDNames = "LISTEN_FDNAMES"
+	// envUpgradeFDs names the sockets an upgrade hands over, separated
+	// by colons
+	envUpgradeFDs = "SHOUT_UPGRADE_FDS"
+	// envReadyFD is the pipe the new process reports readiness on
+	envReadyFD = "SHOUT_UPGRADE_READY_FD"
+	// envNotifySocket is where systemd expects readiness notifications
+	envNotifySocket = "NOTIFY_SOCKET"
+)
+
+// firstFD is the first inherited file descriptor, after stdin, stdout
+// and stderr.
+const firstFD = 3
+
+// ErrUpgraded is returned by Upgrade once a new process has taken over.
+var ErrUpgraded = errors.New("sockets: already upgraded")
+
+// fileListener is a listener whose socket can be handed to another
+// process, as TCP and Unix listeners can.
+type fileListener interface {
+	net.Listener
+	File() (*os.File, error)
+}
+
+// named is a listener and the name it is handed over by.
+type named struct {
+	name string
+	ln   fileListener
+}
+
+// Set holds the server's listening sockets.
+//
+// Usage example:
+//
+//	socks, err := sockets.Inherit()
+//	ln, err := socks.Listen("public", ":8080")
+//	socks.Ready()
+type Set struct {
+	mu sync.Mutex
+	// inherited are the sockets not yet claimed by Listen
+	inherited []named
+	// active are the sockets in use, which Upgrade hands over
+	active []named
+	// ready is the pipe to the process that started this one in an
+	// upgrade; nil otherwise
+	ready *os.File
+	// upgraded is set once a new process has taken over
+	upgraded bool
+}
+
+// Inherit collects the sockets passed to the process, by systemd socket
+// activation or by the process upgrading to this one. Without any, Listen
+// opens new sockets.
+//
+// Returns:
+//   - *Set: the inherited sockets
+//   - error: error if an inherited file descriptor isn't a socket
+//
+// Example:
+//
+//	socks, err := sockets.Inherit()
+//	if err != nil {
+//	    log.Fatalf("Failed to inherit sockets: %v", err)
+//	}
+func Inherit() (*Set, error) {
+	s := &Set{}
+	names, err := inheritedNames()
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		fd := firstFD + i
+		f := os.NewFile(uintptr(fd), name)
+		ln, err := net.FileListener(f)
+		// FileListener keeps a duplicate, closed on exec
+		f.Close()
+		if err != nil {
+			s.closeInherited()
+			return nil, fmt.Errorf("inheriting socket %d (%s): %w", fd, name, err)
+		}
+		fl, ok := ln.(fileListener)
+		if !ok {
+			ln.Close()
+			s.closeInherited()
+			return nil, fmt.Errorf("inheriting socket %d (%s): not a stream socket", fd, name)
+		}
+		s.inherited = append(s.inherited, named{name: name, ln: fl})
+	}
+
+	if fd := os.Getenv(envReadyFD); fd != "" {
+		n, err := strconv.Atoi(fd)
+		if err != nil {
+			s.closeInherited()
+			return nil, fmt.Errorf("invalid %s %q", envReadyFD, fd)
+		}
+		s.ready = os.NewFile(uintptr(n), "ready")
+	}
+
+	// Processes this one starts inherit nothing unless it upgrades
+	for _, env := range []string{envListenPID, envListenFDs, envListenFDNames, envUpgradeFDs, envReadyFD} {
+		os.Unsetenv(env)
+	}
+	return s, nil
+}
+
+// inheritedNames returns the names of the inherited sockets, in file
+// descriptor order from firstFD.
+func inheritedNames() ([]string, error) {
+	if names := os.Getenv(envUpgradeFDs); names != "" {
+		return strings.Split(names, ":"), nil
+	}
+
+	// Variables meant for another process, such as the one that started
+	// this one, are ignored, as sd_listen_fds does
+	if os.Getenv(envListenPID) != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv(envListenFDs))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid %s %q", envListenFDs, os.Getenv(envListenFDs))
+	}
+	names := make([]string, n)
+	given := strings.Split(os.Getenv(envListenFDNames), ":")
+	for i := range names {
+		names[i] = "unknown"
+		if i < len(given) && given[i] != "" {
+			names[i] = given[i]
+		}
+	}
+	return names, nil
+}
+
+// Listen returns the inherited socket named name, or else the one
+// listening on addr, or else a new TCP socket listening on addr. The
+// socket is handed over by name on Upgrade.
+//
+// Parameters:
+//   - name: the socket's name, such as "public"; systemd sockets are
+//     named by FileDescriptorName=
+//   - addr: the host and port to listen on
+//
+// Returns:
+//   - net.Listener: the listening socket
+//   - error: error if a new socket can't be opened
+//
+// Example:
+//
+//	ln, err := socks.Listen("admin", "0.0.0.0:9090")
+func (s *Set) Listen(name, addr string) (net.Listener, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.claim(name, addr)
+	if i < 0 {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		s.active = append(s.active, named{name: name, ln: ln.(fileListener)})
+		return ln, nil
+	}
+	ln := s.inherited[i].ln
+	s.inherited = append(s.inherited[:i], s.inherited[i+1:]...)
+	s.active = append(s.active, named{name: name, ln: ln})
+	return ln, nil
+}
+
+// claim returns the index of the inherited socket for name and addr, or
+// -1 if none was inherited.
+func (s *Set) claim(name, addr string) int {
+	for i, in := range s.inherited {
+		if in.name == name {
+			return i
+		}
+	}
+	want, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return -1
+	}
+	for i, in := range s.inherited {
+		if got, ok := in.ln.Addr().(*net.TCPAddr); ok && sameAddr(got, want) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameAddr reports whether a socket bound to got serves want. A socket
+// on every interface, such as systemd's ListenStream=8080, serves any
+// host on its port.
+func sameAddr(got, want *net.TCPAddr) bool {
+	if got.Port != want.Port {
+		return false
+	}
+	if want.IP == nil || want.IP.IsUnspecified() {
+		return got.IP == nil || got.IP.IsUnspecified()
+	}
+	return got.IP.Equal(want.IP)
+}
+
+// Ready closes the inherited sockets no Listen call claimed, then tells
+// the process that started this one in an upgrade, and systemd when it
+// supervises the service, that the server is up.
+//
+// Returns:
+//   - error: error if the notifications fail
+//
+// Example:
+//
+//	if err := socks.Ready(); err != nil {
+//	    log.Printf("Warning: readiness not reported: %v", err)
+//	}
+func (s *Set) Ready() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeInherited()
+	var errs []error
+	if s.ready != nil {
+		if _, err := s.ready.Write([]byte{1}); err != nil {
+			errs = append(errs, fmt.Errorf("reporting readiness: %w", err))
+		}
+		s.ready.Close()
+		s.ready = nil
+	}
+	if err := notify(fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid())); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Upgrade starts a new process from the binary on disk, with the same
+// arguments and environment, and hands it the active sockets. It returns
+// once the new process is ready; the caller should then stop accepting
+// connections and exit once its requests and streams are done. When the
+// new process fails to start, or isn't ready within timeout, it is
+// killed and the caller keeps serving.
+//
+// Parameters:
+//   - timeout: how long the new process has to become ready
+//
+// Returns:
+//   - error: error if the new process didn't take over
+//
+// Example:
+//
+//	if err := socks.Upgrade(time.Minute); err != nil {
+//	    log.Printf("Upgrade failed: %v", err)
+//	}
+func (s *Set) Upgrade(timeout time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.upgraded {
+		return ErrUpgraded
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding binary: %w", err)
+	}
+
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	names := make([]string, 0, len(s.active))
+	for _, a := range s.active {
+		f, err := a.ln.File()
+		if err != nil {
+			return fmt.Errorf("handing over socket %s: %w", a.name, err)
+		}
+		files = append(files, f)
+		names = append(names, a.name)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("creating readiness pipe: %w", err)
+	}
+	defer r.Close()
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(files, w)
+	cmd.Env = append(os.Environ(),
+		envUpgradeFDs+"="+strings.Join(names, ":"),
+		fmt.Sprintf("%s=%d", envReadyFD, firstFD+len(files)),
+	)
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		return fmt.Errorf("starting %s: %w", exe, err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	// The pipe reads a byte once the new process is ready, or EOF when it
+	// exits before then
+	readiness := make(chan error, 1)
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		readiness <- err
+	}()
+	select {
+	case err = <-readiness:
+		if err == nil {
+			s.upgraded = true
+			return nil
+		}
+		err = fmt.Errorf("new process exited before it was ready")
+	case <-time.After(timeout):
+		err = fmt.Errorf("new process not ready after %s", timeout)
+	}
+	cmd.Process.Kill()
+	<-exited
+	return err
+}
+
+// Close closes the active sockets, and any inherited ones never claimed.
+func (s *Set) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeInherited()
+	var errs []error
+	for _, a := range s.active {
+		if err := a.ln.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	s.active = nil
+	return errors.Join(errs...)
+}
+
+// closeInherited closes the inherited sockets no Listen call claimed.
+func (s *Set) closeInherited() {
+	for _, in := range s.inherited {
+		in.ln.Close()
+	}
+	s.inherited = nil
+}
+
+// notify sends state to systemd's notification socket, if the service
+// has one.
+func notify(state string) error {
+	addr := os.Getenv(envNotifySocket)
+	if addr == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("notifying systemd: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("notifying systemd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package sockets
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envTestChild makes the test binary act as the process upgraded to.
+const envTestChild = "SHOUT_SOCKETS_TEST_CHILD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestChild) != "" {
+		upgradedChild()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestInheritedNames(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "nothing inherited",
+		},
+		{
+			name: "systemd",
+			env:  map[string]string{envListenPID: pid, envListenFDs: "2", envListenFDNames: "public:admin"},
+			want: []string{"public", "admin"},
+		},
+		{
+			name: "systemd without names",
+			env:  map[string]string{envListenPID: pid, envListenFDs: "2"},
+			want: []string{"unknown", "unknown"},
+		},
+		{
+			name: "systemd for another process",
+			env:  map[string]string{envListenPID: "1", envListenFDs: "2"},
+		},
+		{
+			name:    "systemd invalid count",
+			env:     map[string]string{envListenPID: pid, envListenFDs: "two"},
+			wantErr: true,
+		},
+		{
+			name: "upgrade",
+			env:  map[string]string{envUpgradeFDs: "public:admin:gopher"},
+			want: []string{"public", "admin", "gopher"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{envListenPID, envListenFDs, envListenFDNames, envUpgradeFDs} {
+				t.Setenv(env, tt.env[env])
+			}
+			got, err := inheritedNames()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inheritedNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("inheritedNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListen(t *testing.T) {
+	listen := func(addr string) fileListener {
+		t.Helper()
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ln.(fileListener)
+	}
+	byName := listen("127.0.0.1:0")
+	byAddr := listen("127.0.0.1:0")
+	unused := listen("127.0.0.1:0")
+	s := &Set{inherited: []named{
+		{name: "public", ln: byName},
+		{name: "unknown", ln: byAddr},
+		{name: "unknown", ln: unused},
+	}}
+	defer s.Close()
+
+	ln, err := s.Listen("public", "127.0.0.1:1")
+	if err != nil || ln != byName {
+		t.Errorf("Listen(public) = %v, %v, want the socket named public", ln, err)
+	}
+	ln, err = s.Listen("admin", byAddr.Addr().String())
+	if err != nil || ln != byAddr {
+		t.Errorf("Listen(admin) = %v, %v, want the socket on its address", ln, err)
+	}
+	ln, err = s.Listen("gopher", "127.0.0.1:0")
+	if err != nil || ln == unused {
+		t.Errorf("Listen(gopher) = %v, %v, want a new socket", ln, err)
+	}
+
+	if err := s.Ready(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unused.Accept(); err == nil {
+		t.Error("unclaimed socket still open after Ready")
+	}
+	got := []string{}
+	for _, a := range s.active {
+		got = append(got, a.name)
+	}
+	if want := []string{"public", "admin", "gopher"}; !slices.Equal(got, want) {
+		t.Errorf("active = %v, want %v", got, want)
+	}
+}
+
+func TestSameAddr(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+		same bool
+	}{
+		{got: "127.0.0.1:8080", want: "127.0.0.1:8080", same: true},
+		{got: "[::]:8080", want: "0.0.0.0:8080", same: true},
+		{got: "0.0.0.0:8080", want: ":8080", same: true},
+		{got: "0.0.0.0:8080", want: "0.0.0.0:9090"},
+		{got: "127.0.0.1:8080", want: "0.0.0.0:8080"},
+		{got: "0.0.0.0:8080", want: "127.0.0.1:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.got+" "+tt.want, func(t *testing.T) {
+			got, err := net.ResolveTCPAddr("tcp", tt.got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := net.ResolveTCPAddr("tcp", tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if same := sameAddr(got, want); same != tt.same {
+				t.Errorf("sameAddr(%s, %s) = %v, want %v", tt.got, tt.want, same, tt.same)
+			}
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	addr := t.TempDir() + "/notify"
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv(envNotifySocket, addr)
+
+	s := &Set{}
+	if err := s.Ready(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 128)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid()); string(buf[:n]) != want {
+		t.Errorf("notification = %q, want %q", buf[:n], want)
+	}
+}
+
+// TestUpgrade upgrades to a copy of the test binary, which answers one
+// connection on the inherited socket with its PID.
+func TestUpgrade(t *testing.T) {
+	t.Setenv(envTestChild, "1")
+
+	s := &Set{}
+	ln, err := s.Listen("public", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Upgrade(30 * time.Second); err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if err := s.Upgrade(30 * time.Second); err != ErrUpgraded {
+		t.Errorf("second Upgrade() error = %v, want ErrUpgraded", err)
+	}
+
+	// Connections queued after this process stops accepting reach the
+	// new one
+	addr := ln.Addr().String()
+	s.Close()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid := strings.TrimSpace(line); pid == strconv.Itoa(os.Getpid()) {
+		t.Error("connection answered by the old process")
+	}
+}
+
+// upgradedChild is the process TestUpgrade upgrades to.
+func upgradedChild() {
+	s, err := Inherit()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ln, err := s.Listen("public", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := s.Ready(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(30 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(conn, "%d\n", os.Getpid())
+	conn.Close()
+}
@@ -0,0 +1,246 @@
+package termgif
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default colors, matching the help page.
+var (
+	defaultFG = color.RGBA{0xee, 0xee, 0xee, 0xff}
+	defaultBG = color.RGBA{0x12, 0x12, 0x12, 0xff}
+)
+
+// cell is one character on the screen.
+type cell struct {
+	r      rune
+	fg, bg color.RGBA
+}
+
+// screen is a minimal terminal emulator: it understands the text, cursor
+// movement, erase and color sequences that shout.sh streams produce, and
+// ignores everything else.
+type screen struct {
+	cols, rows int
+	cells      []cell
+	row, col   int
+	savedRow   int
+	savedCol   int
+	// pending is set after writing in the last column; the next character
+	// wraps first
+	pending bool
+	fg, bg  color.RGBA
+	bold    bool
+}
+
+// newScreen returns a blank screen of the given size.
+func newScreen(cols, rows int) *screen {
+	s := &screen{cols: cols, rows: rows, cells: make([]cell, cols*rows), fg: defaultFG, bg: defaultBG}
+	s.erase(0, len(s.cells))
+	return s
+}
+
+// write interprets terminal output.
+func (s *screen) write(text string) {
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch r {
+		case '\x1b':
+			i += s.escape(text[i:])
+			continue
+		case '\r':
+			s.col, s.pending = 0, false
+		case '\n':
+			// Streams rely on the terminal adding the carriage return
+			s.col, s.pending = 0, false
+			s.lineFeed()
+		case '\t':
+			s.col = min((s.col/8+1)*8, s.cols-1)
+		default:
+			if r >= ' ' {
+				s.put(r)
+			}
+		}
+		i += size
+	}
+}
+
+// put draws r at the cursor and advances it.
+func (s *screen) put(r rune) {
+	if s.pending {
+		s.col, s.pending = 0, false
+		s.lineFeed()
+	}
+	fg := s.fg
+	if s.bold {
+		fg = brighten(fg)
+	}
+	s.cells[s.row*s.cols+s.col] = cell{r: r, fg: fg, bg: s.bg}
+	if s.col == s.cols-1 {
+		s.pending = true
+	} else {
+		s.col++
+	}
+}
+
+// lineFeed moves the cursor down, scrolling at the bottom.
+func (s *screen) lineFeed() {
+	if s.row < s.rows-1 {
+		s.row++
+		return
+	}
+	copy(s.cells, s.cells[s.cols:])
+	s.erase((s.rows-1)*s.cols, len(s.cells))
+}
+
+// erase blanks the cells in [from, to).
+func (s *screen) erase(from, to int) {
+	for i := max(from, 0); i < min(to, len(s.cells)); i++ {
+		s.cells[i] = cell{r: ' ', fg: s.fg, bg: s.bg}
+	}
+}
+
+// escape handles the escape sequence at the start of text and returns its
+// length.
+func (s *screen) escape(text string) int {
+	if len(text) < 2 || text[1] != '[' {
+		return min(2, len(text))
+	}
+	end := 2
+	for end < len(text) && (text[end] < 0x40 || text[end] > 0x7e) {
+		end++
+	}
+	if end == len(text) {
+		return end
+	}
+	params := text[2:end]
+	if strings.HasPrefix(params, "?") {
+		// Private modes such as cursor visibility
+		return end + 1
+	}
+
+	args := strings.Split(params, ";")
+	arg := func(n, def int) int {
+		if n >= len(args) {
+			return def
+		}
+		v, err := strconv.Atoi(args[n])
+		if err != nil || v < 1 {
+			return def
+		}
+		return v
+	}
+
+	cursor := s.row*s.cols + s.col
+	switch text[end] {
+	case 'A':
+		s.row = max(s.row-arg(0, 1), 0)
+	case 'B':
+		s.row = min(s.row+arg(0, 1), s.rows-1)
+	case 'C':
+		s.col = min(s.col+arg(0, 1), s.cols-1)
+	case 'D':
+		s.col = max(s.col-arg(0, 1), 0)
+	case 'H', 'f':
+		s.row = min(arg(0, 1)-1, s.rows-1)
+		s.col = min(arg(1, 1)-1, s.cols-1)
+	case 'J':
+		switch arg(0, 0) {
+		case 0:
+			s.erase(cursor, len(s.cells))
+		case 1:
+			s.erase(0, cursor+1)
+		default:
+			s.erase(0, len(s.cells))
+		}
+	case 'K':
+		start := s.row * s.cols
+		switch arg(0, 0) {
+		case 0:
+			s.erase(cursor, start+s.cols)
+		case 1:
+			s.erase(start, cursor+1)
+		default:
+			s.erase(start, start+s.cols)
+		}
+	case 's':
+		s.savedRow, s.savedCol = s.row, s.col
+	case 'u':
+		s.row, s.col = s.savedRow, s.savedCol
+	case 'm':
+		s.sgr(args)
+		return end + 1
+	default:
+		return end + 1
+	}
+	s.pending = false
+	return end + 1
+}
+
+// sgr applies a Select Graphic Rendition sequence.
+func (s *screen) sgr(args []string) {
+	for i := 0; i < len(args); i++ {
+		n, _ := strconv.Atoi(args[i])
+		switch {
+		case n == 0:
+			s.fg, s.bg, s.bold = defaultFG, defaultBG, false
+		case n == 1:
+			s.bold = true
+		case n == 22:
+			s.bold = false
+		case n >= 30 && n <= 37:
+			s.fg = xterm[n-30]
+		case n >= 90 && n <= 97:
+			s.fg = xterm[n-90+8]
+		case n == 39:
+			s.fg = defaultFG
+		case n >= 40 && n <= 47:
+			s.bg = xterm[n-40]
+		case n >= 100 && n <= 107:
+			s.bg = xterm[n-100+8]
+		case n == 49:
+			s.bg = defaultBG
+		case n == 38 || n == 48:
+			c, used := extendedColor(args[i+1:])
+			i += used
+			if used == 0 {
+				continue
+			}
+			if n == 38 {
+				s.fg = c
+			} else {
+				s.bg = c
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments after 38 or 48: "5;n" for a palette
+// color or "2;r;g;b" for true color. It returns the color and how many
+// arguments it used.
+func extendedColor(args []string) (color.RGBA, int) {
+	num := func(i int) uint8 {
+		v, _ := strconv.Atoi(args[i])
+		return uint8(min(max(v, 0), 255))
+	}
+	switch {
+	case len(args) >= 2 && args[0] == "5":
+		return xterm[num(1)], 2
+	case len(args) >= 4 && args[0] == "2":
+		return color.RGBA{num(1), num(2), num(3), 0xff}, 4
+	}
+	return color.RGBA{}, 0
+}
+
+// brighten returns the bright variant of one of the eight basic colors,
+// as bold text is drawn on most terminals.
+func brighten(c color.RGBA) color.RGBA {
+	for i := 0; i < 8; i++ {
+		if xterm[i] == c {
+			return xterm[i+8]
+		}
+	}
+	return c
+}
@@ -0,0 +1,142 @@
+package termgif
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// EncodePNG writes the current screen as a PNG image, drawn as Encode
+// draws GIF frames.
+//
+// Parameters:
+//   - w: where to write the PNG
+//
+// Returns:
+//   - error: error if writing fails
+func (r *Recorder) EncodePNG(w io.Writer) error {
+	return png.Encode(w, r.draw())
+}
+
+// EncodeSVG writes the current screen as an SVG image: a background, a
+// rectangle for each run of cells on another background, and each row as
+// text in a monospace font, colored run by run. The text stays
+// selectable, and is drawn at the size of the 7x13 pixel font times the
+// recorder's scale.
+//
+// Parameters:
+//   - w: where to write the SVG
+//
+// Returns:
+//   - error: error if writing fails
+func (r *Recorder) EncodeSVG(w io.Writer) error {
+	s := r.screen
+	cw, ch := CellWidth*r.scale, CellHeight*r.scale
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		s.cols*cw, s.rows*ch, s.cols*cw, s.rows*ch)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(defaultBG))
+	for row := 0; row < s.rows; row++ {
+		cells := s.cells[row*s.cols : (row+1)*s.cols]
+		for start := 0; start < len(cells); {
+			end := start + 1
+			for end < len(cells) && cells[end].bg == cells[start].bg {
+				end++
+			}
+			if bg := cells[start].bg; bg != defaultBG {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+					start*cw, row*ch, (end-start)*cw, ch, hexColor(bg))
+			}
+			start = end
+		}
+	}
+	fmt.Fprintf(&b, `<g font-family="DejaVu Sans Mono,Menlo,Consolas,monospace" font-size="%d" xml:space="preserve">`+"\n", ch*10/13)
+	for row := 0; row < s.rows; row++ {
+		cells := s.cells[row*s.cols : (row+1)*s.cols]
+		last := len(cells)
+		for last > 0 && cells[last-1].r == ' ' {
+			last--
+		}
+		if last == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text y="%d">`, row*ch+face.Ascent*r.scale)
+		for start := 0; start < last; {
+			end := start + 1
+			for end < last && cells[end].fg == cells[start].fg {
+				end++
+			}
+			var text strings.Builder
+			for _, c := range cells[start:end] {
+				text.WriteRune(c.r)
+			}
+			// textLength keeps runs on the grid whatever the font's advance
+			fmt.Fprintf(&b, `<tspan x="%d" textLength="%d" lengthAdjust="spacing" fill="%s">%s</tspan>`,
+				start*cw, (end-start)*cw, hexColor(cells[start].fg), html.EscapeString(text.String()))
+			start = end
+		}
+		b.WriteString("</text>\n")
+	}
+	b.WriteString("</g>\n</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// EncodeHTML writes the current screen as an HTML pre element, on the
+// screen's background, with a span for each run of cells colored
+// differently from the default. Trailing blanks of each row are left out.
+//
+// Parameters:
+//   - w: where to write the HTML
+//
+// Returns:
+//   - error: error if writing fails
+func (r *Recorder) EncodeHTML(w io.Writer) error {
+	s := r.screen
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<pre style="background:%s;color:%s;padding:1em;margin:0">`, hexColor(defaultBG), hexColor(defaultFG))
+	for row := 0; row < s.rows; row++ {
+		cells := s.cells[row*s.cols : (row+1)*s.cols]
+		last := len(cells)
+		for last > 0 && cells[last-1].r == ' ' && cells[last-1].bg == defaultBG {
+			last--
+		}
+		for start := 0; start < last; {
+			end := start + 1
+			for end < last && cells[end].fg == cells[start].fg && cells[end].bg == cells[start].bg {
+				end++
+			}
+			var text strings.Builder
+			for _, c := range cells[start:end] {
+				text.WriteRune(c.r)
+			}
+			var style []string
+			if fg := cells[start].fg; fg != defaultFG {
+				style = append(style, "color:"+hexColor(fg))
+			}
+			if bg := cells[start].bg; bg != defaultBG {
+				style = append(style, "background:"+hexColor(bg))
+			}
+			if len(style) > 0 {
+				fmt.Fprintf(&b, `<span style="%s">%s</span>`, strings.Join(style, ";"), html.EscapeString(text.String()))
+			} else {
+				b.WriteString(html.EscapeString(text.String()))
+			}
+			start = end
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("</pre>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hexColor formats c as #rrggbb.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,72 @@
+package termgif
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestEncodePNG(t *testing.T) {
+	rec := New(3, 2, 2)
+	rec.Write("\x1b[41mA")
+
+	var b bytes.Buffer
+	if err := rec.EncodePNG(&b); err != nil {
+		t.Fatalf("EncodePNG() error = %v", err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 3*CellWidth*2 || h != 2*CellHeight*2 {
+		t.Errorf("size = %dx%d, want %dx%d", w, h, 3*CellWidth*2, 2*CellHeight*2)
+	}
+	if r, g, b, _ := img.At(1, 1).RGBA(); r>>8 != 0xcd || g != 0 || b != 0 {
+		t.Errorf("background = %x,%x,%x, want red", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestEncodeSVG(t *testing.T) {
+	rec := New(6, 2, 1)
+	rec.Write("\x1b[31mA<\x1b[32mB\x1b[44m \x1b[0m")
+
+	var b bytes.Buffer
+	if err := rec.EncodeSVG(&b); err != nil {
+		t.Fatalf("EncodeSVG() error = %v", err)
+	}
+	svg := b.String()
+	if err := xml.Unmarshal(b.Bytes(), new(struct{})); err != nil {
+		t.Fatalf("invalid SVG: %v\n%s", err, svg)
+	}
+	for _, want := range []string{
+		`width="42" height="26"`,
+		`fill="#cd0000">A&lt;</tspan>`,
+		`x="14" textLength="7" lengthAdjust="spacing" fill="#00cd00">B</tspan>`,
+		`<rect x="21" y="0" width="7" height="13" fill="#0000ee"/>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG doesn't contain %q:\n%s", want, svg)
+		}
+	}
+	// The blank second row has no text
+	if n := strings.Count(svg, "<text"); n != 1 {
+		t.Errorf("SVG has %d text rows, want 1", n)
+	}
+}
+
+func TestEncodeHTML(t *testing.T) {
+	rec := New(6, 2, 1)
+	rec.Write("\x1b[31mA<\x1b[0mB\x1b[44m \x1b[0m")
+
+	var b bytes.Buffer
+	if err := rec.EncodeHTML(&b); err != nil {
+		t.Fatalf("EncodeHTML() error = %v", err)
+	}
+	want := `<pre style="background:#121212;color:#eeeeee;padding:1em;margin:0">` +
+		`<span style="color:#cd0000">A&lt;</span>B<span style="background:#0000ee"> </span>` + "\n\n</pre>\n"
+	if got := b.String(); got != want {
+		t.Errorf("EncodeHTML() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+// Package termgif records terminal output as an animated GIF. It plays
+// the output on a small emulated screen and draws each frame with a fixed
+// 7x13 pixel font, so streams can be embedded where a terminal isn't
+// available, such as a README. The screen can also be saved as a still
+// PNG or SVG image.
+package termgif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Cell size in pixels, before scaling.
+const (
+	CellWidth  = 7
+	CellHeight = 13
+)
+
+// minDelay is the shortest frame delay browsers honor, in 1/100 s.
+const minDelay = 2
+
+// finalDelay holds the last frame before the animation loops, in 1/100 s.
+const finalDelay = 200
+
+// face draws the characters.
+var face = basicfont.Face7x13
+
+// xterm holds the xterm 256 colors: 16 basic colors, a 6x6x6 color cube
+// and 24 grays.
+var xterm = func() [256]color.RGBA {
+	var p [256]color.RGBA
+	basic := [16][3]uint8{
+		{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+		{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+		{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+		{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+	}
+	for i, c := range basic {
+		p[i] = color.RGBA{c[0], c[1], c[2], 0xff}
+	}
+	levels := [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+	for i := 0; i < 216; i++ {
+		p[16+i] = color.RGBA{levels[i/36], levels[i/6%6], levels[i%6], 0xff}
+	}
+	for i := 0; i < 24; i++ {
+		v := uint8(8 + 10*i)
+		p[232+i] = color.RGBA{v, v, v, 0xff}
+	}
+	return p
+}()
+
+// palette is xterm as a GIF palette; frames are quantized to it.
+var palette = func() color.Palette {
+	p := make(color.Palette, len(xterm))
+	for i, c := range xterm {
+		p[i] = c
+	}
+	return p
+}()
+
+// Recorder collects frames of terminal output into an animated GIF.
+//
+// Usage example:
+//
+//	rec := termgif.New(80, 24, 1)
+//	rec.Write("\x1b[31mHELLO\x1b[0m")
+//	rec.Frame(time.Second)
+//	err := rec.Encode(w)
+type Recorder struct {
+	screen *screen
+	scale  int
+	anim   gif.GIF
+	// indexes caches the palette index of each color seen
+	indexes map[color.RGBA]uint8
+}
+
+// New creates a recorder for a terminal of cols by rows characters, drawn
+// scale times larger than the 7x13 pixel font.
+//
+// Parameters:
+//   - cols: the screen width in characters
+//   - rows: the screen height in characters
+//   - scale: the pixel scale, at least 1
+//
+// Returns:
+//   - *Recorder: an empty recording
+func New(cols, rows, scale int) *Recorder {
+	return &Recorder{
+		screen:  newScreen(max(cols, 1), max(rows, 1)),
+		scale:   max(scale, 1),
+		indexes: map[color.RGBA]uint8{},
+	}
+}
+
+// Write plays terminal output on the screen. Nothing is drawn until Frame.
+//
+// Parameters:
+//   - output: text with terminal escape sequences
+func (r *Recorder) Write(output string) {
+	r.screen.write(output)
+}
+
+// Frame adds the current screen as a frame shown for delay. A delay of 0
+// holds the frame for a moment before the animation loops, which suits
+// the last frame.
+//
+// Parameters:
+//   - delay: how long the frame is shown
+func (r *Recorder) Frame(delay time.Duration) {
+	d := finalDelay
+	if delay > 0 {
+		d = max(int(delay/(10*time.Millisecond)), minDelay)
+	}
+	r.anim.Image = append(r.anim.Image, r.draw())
+	r.anim.Delay = append(r.anim.Delay, d)
+}
+
+// Len returns the number of frames recorded.
+func (r *Recorder) Len() int {
+	return len(r.anim.Image)
+}
+
+// Encode writes the recording as a looping animated GIF. An empty
+// recording gets a single frame of the current screen.
+//
+// Parameters:
+//   - w: where to write the GIF
+//
+// Returns:
+//   - error: error if writing fails
+func (r *Recorder) Encode(w io.Writer) error {
+	if len(r.anim.Image) == 0 {
+		r.Frame(0)
+	}
+	return gif.EncodeAll(w, &r.anim)
+}
+
+// draw renders the screen as a paletted image.
+func (r *Recorder) draw() *image.Paletted {
+	s := r.screen
+	cw, ch := CellWidth*r.scale, CellHeight*r.scale
+	img := image.NewPaletted(image.Rect(0, 0, s.cols*cw, s.rows*ch), palette)
+
+	for row := 0; row < s.rows; row++ {
+		for col := 0; col < s.cols; col++ {
+			c := s.cells[row*s.cols+col]
+			x, y := col*cw, row*ch
+			fg, bg := r.index(c.fg), r.index(c.bg)
+			r.fill(img, image.Rect(x, y, x+cw, y+ch), bg)
+
+			if block, ok := r.block(c, x, y, cw, ch); ok {
+				r.fill(img, block, fg)
+				continue
+			}
+			if shade, ok := shades[c.r]; ok {
+				r.fill(img, image.Rect(x, y, x+cw, y+ch), r.index(mix(c.bg, c.fg, shade)))
+				continue
+			}
+			r.glyph(img, c.r, col*CellWidth, row*CellHeight, fg)
+		}
+	}
+	return img
+}
+
+// shades are the shade characters and how much of the foreground they show.
+var shades = map[rune]float64{'░': 0.25, '▒': 0.5, '▓': 0.75}
+
+// block returns the area covered by a block element character.
+func (r *Recorder) block(c cell, x, y, cw, ch int) (image.Rectangle, bool) {
+	switch c.r {
+	case '█':
+		return image.Rect(x, y, x+cw, y+ch), true
+	case '▀':
+		return image.Rect(x, y, x+cw, y+ch/2), true
+	case '▄':
+		return image.Rect(x, y+ch/2, x+cw, y+ch), true
+	case '▌':
+		return image.Rect(x, y, x+cw/2, y+ch), true
+	case '▐':
+		return image.Rect(x+cw/2, y, x+cw, y+ch), true
+	}
+	return image.Rectangle{}, false
+}
+
+// glyph draws character ch with its top left corner at unscaled pixel
+// (x, y). Characters the font lacks are drawn as a replacement character.
+func (r *Recorder) glyph(img *image.Paletted, ch rune, x, y int, index uint8) {
+	if ch == ' ' {
+		return
+	}
+	dot := fixed.P(x, y+face.Ascent)
+	dr, mask, maskp, _, ok := face.Glyph(dot, ch)
+	if !ok {
+		dr, mask, maskp, _, _ = face.Glyph(dot, '\ufffd')
+	}
+	for py := dr.Min.Y; py < dr.Max.Y; py++ {
+		for px := dr.Min.X; px < dr.Max.X; px++ {
+			_, _, _, a := mask.At(maskp.X+px-dr.Min.X, maskp.Y+py-dr.Min.Y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			r.fill(img, image.Rect(px*r.scale, py*r.scale, (px+1)*r.scale, (py+1)*r.scale), index)
+		}
+	}
+}
+
+// fill sets every pixel of rect to the palette color at index.
+func (r *Recorder) fill(img *image.Paletted, rect image.Rectangle, index uint8) {
+	rect = rect.Intersect(img.Rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for i := range row {
+			row[i] = index
+		}
+	}
+}
+
+// index returns the palette index closest to c.
+func (r *Recorder) index(c color.RGBA) uint8 {
+	i, ok := r.indexes[c]
+	if !ok {
+		i = uint8(palette.Index(c))
+		r.indexes[c] = i
+	}
+	return i
+}
+
+// mix blends from a toward b by t.
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	blend := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{blend(a.R, b.R), blend(a.G, b.G), blend(a.B, b.B), 0xff}
+}
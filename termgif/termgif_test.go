@@ -0,0 +1,150 @@
+package termgif
+
+import (
+	"bytes"
+	"image/gif"
+	"slices"
+	"testing"
+	"time"
+)
+
+// text returns the characters on row of the screen.
+func (s *screen) text(row int) string {
+	var b []rune
+	for _, c := range s.cells[row*s.cols : (row+1)*s.cols] {
+		b = append(b, c.r)
+	}
+	return string(b)
+}
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name   string
+		cols   int
+		output string
+		want   []string
+	}{
+		{name: "text", cols: 5, output: "ab\ncd", want: []string{"ab   ", "cd   ", "     "}},
+		{name: "carriage return", cols: 5, output: "abc\rX", want: []string{"Xbc  ", "     ", "     "}},
+		{name: "wraps", cols: 3, output: "abcd", want: []string{"abc", "d  ", "   "}},
+		{name: "last column waits to wrap", cols: 3, output: "abc\nd", want: []string{"abc", "d  ", "   "}},
+		{name: "scrolls", cols: 2, output: "a\nb\nc\nd", want: []string{"b ", "c ", "d "}},
+		{name: "cursor position", cols: 4, output: "\x1b[2;3Hx", want: []string{"    ", "  x ", "    "}},
+		{name: "home and clear", cols: 3, output: "abc\x1b[H\x1b[2Jz", want: []string{"z  ", "   ", "   "}},
+		{name: "erase line", cols: 4, output: "abcd\x1b[1;2H\x1b[K", want: []string{"a   ", "    ", "    "}},
+		{name: "ignores private modes", cols: 3, output: "\x1b[?25la", want: []string{"a  ", "   ", "   "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScreen(tt.cols, 3)
+			s.write(tt.output)
+			var got []string
+			for row := 0; row < s.rows; row++ {
+				got = append(got, s.text(row))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("screen = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenColors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		fg, bg int
+	}{
+		{name: "basic", output: "\x1b[31;42mx", fg: 1, bg: 2},
+		{name: "bright", output: "\x1b[94;103mx", fg: 12, bg: 11},
+		{name: "bold brightens", output: "\x1b[1;32mx", fg: 10, bg: -1},
+		{name: "256 colors", output: "\x1b[38;5;196;48;5;21mx", fg: 196, bg: 21},
+		{name: "reset", output: "\x1b[31m\x1b[0mx", fg: -1, bg: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScreen(2, 1)
+			s.write(tt.output)
+			wantFG, wantBG := defaultFG, defaultBG
+			if tt.fg >= 0 {
+				wantFG = xterm[tt.fg]
+			}
+			if tt.bg >= 0 {
+				wantBG = xterm[tt.bg]
+			}
+			if c := s.cells[0]; c.fg != wantFG || c.bg != wantBG {
+				t.Errorf("colors = %v on %v, want %v on %v", c.fg, c.bg, wantFG, wantBG)
+			}
+		})
+	}
+
+	s := newScreen(2, 1)
+	s.write("\x1b[38;2;1;2;3mx")
+	if got := s.cells[0].fg; got.R != 1 || got.G != 2 || got.B != 3 {
+		t.Errorf("true color = %v, want 1,2,3", got)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	tests := []struct {
+		name      string
+		scale     int
+		frames    []time.Duration
+		wantDelay []int
+	}{
+		{name: "empty recording", scale: 1, wantDelay: []int{finalDelay}},
+		{name: "delays", scale: 1, frames: []time.Duration{time.Second, 250 * time.Millisecond, 0}, wantDelay: []int{100, 25, finalDelay}},
+		{name: "short delays", scale: 2, frames: []time.Duration{time.Millisecond}, wantDelay: []int{minDelay}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := New(4, 2, tt.scale)
+			for i, d := range tt.frames {
+				rec.Write(string(rune('A' + i)))
+				rec.Frame(d)
+			}
+			var b bytes.Buffer
+			if err := rec.Encode(&b); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			anim, err := gif.DecodeAll(&b)
+			if err != nil {
+				t.Fatalf("invalid GIF: %v", err)
+			}
+			if !slices.Equal(anim.Delay, tt.wantDelay) {
+				t.Errorf("delays = %v, want %v", anim.Delay, tt.wantDelay)
+			}
+			bounds := anim.Image[0].Bounds()
+			if w, h := bounds.Dx(), bounds.Dy(); w != 4*CellWidth*tt.scale || h != 2*CellHeight*tt.scale {
+				t.Errorf("size = %dx%d, want %dx%d", w, h, 4*CellWidth*tt.scale, 2*CellHeight*tt.scale)
+			}
+		})
+	}
+}
+
+func TestRecorderDraws(t *testing.T) {
+	rec := New(2, 1, 1)
+	rec.Write("\x1b[41m \x1b[32m█")
+	rec.Frame(0)
+	img := rec.anim.Image[0]
+
+	if got := img.ColorIndexAt(CellWidth/2, CellHeight/2); palette[got] != xterm[1] {
+		t.Errorf("background = %v, want %v", palette[got], xterm[1])
+	}
+	if got := img.ColorIndexAt(CellWidth+CellWidth/2, CellHeight/2); palette[got] != xterm[2] {
+		t.Errorf("full block = %v, want %v", palette[got], xterm[2])
+	}
+
+	// A glyph sets some pixels to the foreground
+	rec = New(1, 1, 1)
+	rec.Write("W")
+	rec.Frame(0)
+	fg := rec.index(defaultFG)
+	if !slices.Contains(rec.anim.Image[0].Pix, fg) {
+		t.Error("glyph drew no pixels")
+	}
+}
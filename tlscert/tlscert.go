@@ -0,0 +1,123 @@
+// Package tlscert provides the certificate of the public port when it
+// serves HTTPS, read from files or obtained from an ACME CA such as Let's
+// Encrypt, and the plain HTTP handler that redirects to HTTPS, so small
+// deployments don't need a reverse proxy in front of them.
+package tlscert
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Provider supplies the public port's certificate.
+//
+// Usage example:
+//
+//	certs, err := tlscert.Open(cfg.TLS)
+//	ln = tls.NewListener(ln, certs.TLSConfig())
+type Provider struct {
+	// manager obtains and renews certificates with ACME; nil when they
+	// come from files
+	manager *autocert.Manager
+	// cert is the certificate read from files
+	cert *tls.Certificate
+}
+
+// Open creates the certificate provider selected by the configuration:
+// certificate files, or ACME for the allowed domains.
+//
+// Parameters:
+//   - cfg: the TLS settings
+//
+// Returns:
+//   - *Provider: the provider, or nil when the public port serves plain
+//     HTTP
+//   - error: error if the certificate files can't be loaded
+//
+// Example:
+//
+//	certs, err := tlscert.Open(cfg.TLS)
+//	if err != nil {
+//	    log.Fatalf("Failed to set up TLS: %v", err)
+//	}
+func Open(cfg config.TLSConfig) (*Provider, error) {
+	switch {
+	case cfg.CertFile != "":
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS certificate: %w", err)
+		}
+		return &Provider{cert: &cert}, nil
+	case len(cfg.ACMEDomains) > 0:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.ACMEDomains...),
+			Cache:      autocert.DirCache(cfg.ACMECacheDir),
+			Email:      cfg.ACMEEmail,
+		}
+		if cfg.ACMEDirectory != "" {
+			manager.Client = &acme.Client{DirectoryURL: cfg.ACMEDirectory}
+		}
+		return &Provider{manager: manager}, nil
+	}
+	return nil, nil
+}
+
+// TLSConfig returns the TLS settings of the public port. Only HTTP/1.1
+// is offered, as the server doesn't speak HTTP/2; with ACME, TLS-ALPN
+// challenges are answered too.
+//
+// Returns:
+//   - *tls.Config: settings for tls.NewListener
+func (p *Provider) TLSConfig() *tls.Config {
+	if p.manager != nil {
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: p.manager.GetCertificate,
+			NextProtos:     []string{"http/1.1", acme.ALPNProto},
+		}
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*p.cert},
+		NextProtos:   []string{"http/1.1"},
+	}
+}
+
+// RedirectHandler returns the handler of the plain HTTP port: it answers
+// ACME HTTP challenges, and permanently redirects everything else to the
+// same host and path over HTTPS.
+//
+// Parameters:
+//   - httpsPort: the port HTTPS is served on, left out of redirects when
+//     it is 443
+//
+// Returns:
+//   - http.Handler: the redirecting handler
+//
+// Example:
+//
+//	http.ListenAndServe(":80", certs.RedirectHandler(443))
+func (p *Provider) RedirectHandler(httpsPort int) http.Handler {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+	if p.manager == nil {
+		return redirect
+	}
+	return p.manager.HTTPHandler(redirect)
+}
@@ -0,0 +1,190 @@
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"golang.org/x/crypto/acme"
+)
+
+// writeCert writes a self-signed certificate for shout.example.com and
+// its key to dir, returning their paths.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "shout.example.com"},
+		DNSNames:     []string{"shout.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+
+	tests := []struct {
+		name      string
+		cfg       config.TLSConfig
+		wantNil   bool
+		wantErr   bool
+		wantProto []string
+	}{
+		{
+			name:    "plain HTTP",
+			wantNil: true,
+		},
+		{
+			name:      "certificate files",
+			cfg:       config.TLSConfig{CertFile: certFile, KeyFile: keyFile},
+			wantProto: []string{"http/1.1"},
+		},
+		{
+			name:    "missing key",
+			cfg:     config.TLSConfig{CertFile: certFile, KeyFile: filepath.Join(dir, "nonesuch.pem")},
+			wantErr: true,
+		},
+		{
+			name:      "ACME",
+			cfg:       config.TLSConfig{ACMEDomains: []string{"shout.example.com"}, ACMECacheDir: dir},
+			wantProto: []string{"http/1.1", acme.ALPNProto},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certs, err := Open(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (certs == nil) != tt.wantNil {
+				t.Fatalf("Open() = %v, wantNil %v", certs, tt.wantNil)
+			}
+			if tt.wantNil {
+				return
+			}
+			if got := certs.TLSConfig().NextProtos; !slices.Equal(got, tt.wantProto) {
+				t.Errorf("NextProtos = %v, want %v", got, tt.wantProto)
+			}
+		})
+	}
+}
+
+func TestACMEHostPolicy(t *testing.T) {
+	certs, err := Open(config.TLSConfig{ACMEDomains: []string{"shout.example.com"}, ACMECacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unlisted hosts are refused before the CA is contacted
+	_, err = certs.TLSConfig().GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.example.com"})
+	if err == nil {
+		t.Error("GetCertificate() for an unlisted domain succeeded")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		acme      bool
+		httpsPort int
+		target    string
+		want      int
+		wantLoc   string
+	}{
+		{
+			name:      "default port",
+			httpsPort: 443,
+			target:    "http://shout.example.com/HI?f=doom",
+			want:      http.StatusPermanentRedirect,
+			wantLoc:   "https://shout.example.com/HI?f=doom",
+		},
+		{
+			name:      "other port",
+			httpsPort: 8443,
+			target:    "http://shout.example.com:8080/p/HI",
+			want:      http.StatusPermanentRedirect,
+			wantLoc:   "https://shout.example.com:8443/p/HI",
+		},
+		{
+			name:      "ACME redirect",
+			acme:      true,
+			httpsPort: 443,
+			target:    "http://shout.example.com/fonts",
+			want:      http.StatusPermanentRedirect,
+			wantLoc:   "https://shout.example.com/fonts",
+		},
+		{
+			name:      "ACME challenge",
+			acme:      true,
+			httpsPort: 443,
+			target:    "http://shout.example.com/.well-known/acme-challenge/token",
+			want:      http.StatusNotFound,
+		},
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.TLSConfig{CertFile: certFile, KeyFile: keyFile}
+			if tt.acme {
+				cfg = config.TLSConfig{ACMEDomains: []string{"shout.example.com"}, ACMECacheDir: dir}
+			}
+			certs, err := Open(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rec := httptest.NewRecorder()
+			certs.RedirectHandler(tt.httpsPort).ServeHTTP(rec, httptest.NewRequest("GET", tt.target, nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLoc {
+				t.Errorf("Location = %q, want %q", got, tt.wantLoc)
+			}
+		})
+	}
+}
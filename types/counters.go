@@ -0,0 +1,153 @@
+package types
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RenderBuckets are the upper bounds, in seconds, of the render duration
+// histogram.
+var RenderBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// MetricsSnapshot is a copy of the counters of Metrics, read at one time,
+// as /stats reports them.
+//
+// Usage example:
+//
+//	snap := metrics.Snapshot()
+//	fmt.Printf("%d static renders, %d errors\n", snap.Requests.Static, snap.Requests.Errors)
+type MetricsSnapshot struct {
+	Requests RequestCounts `json:"requests"`
+	Streams  StreamCounts  `json:"streams"`
+	Bots     BotCounts     `json:"bots"`
+	// RenderDuration is how long static renders took, in seconds
+	RenderDuration Histogram `json:"renderDuration"`
+}
+
+// RequestCounts are the requests served, by kind.
+type RequestCounts struct {
+	Static          int64 `json:"static"`
+	Party           int64 `json:"party"`
+	Fonts           int64 `json:"fonts"`
+	RejectedStreams int64 `json:"rejectedStreams"`
+	Errors          int64 `json:"errors"`
+	Filtered        int64 `json:"filtered"`
+	Countdown       int64 `json:"countdown"`
+	Clock           int64 `json:"clock"`
+	Fortune         int64 `json:"fortune"`
+}
+
+// StreamCounts are the frames and streams affected by slow clients and
+// load.
+type StreamCounts struct {
+	DroppedFrames int64 `json:"droppedFrames"`
+	Degraded      int64 `json:"degraded"`
+}
+
+// BotCounts are the requests turned away as bots, by why they were
+// classified.
+type BotCounts struct {
+	Scanner int64 `json:"scanner"`
+	Probe   int64 `json:"probe"`
+	Flood   int64 `json:"flood"`
+}
+
+// IncStatic counts a static render, over HTTP, gRPC or Gopher.
+func (m *Metrics) IncStatic() { atomic.AddInt64(&m.staticRequests, 1) }
+
+// IncParty counts a party mode stream started.
+func (m *Metrics) IncParty() { atomic.AddInt64(&m.partyRequests, 1) }
+
+// IncCountdown counts a countdown stream started.
+func (m *Metrics) IncCountdown() { atomic.AddInt64(&m.countdownRequests, 1) }
+
+// IncClock counts a /date or /time render.
+func (m *Metrics) IncClock() { atomic.AddInt64(&m.clockRequests, 1) }
+
+// IncFortune counts a /fortune render.
+func (m *Metrics) IncFortune() { atomic.AddInt64(&m.fortuneRequests, 1) }
+
+// IncError counts a render that failed.
+func (m *Metrics) IncError() { atomic.AddInt64(&m.totalErrors, 1) }
+
+// IncFiltered counts a request caught by the word filter.
+func (m *Metrics) IncFiltered() { atomic.AddInt64(&m.filteredRequests, 1) }
+
+// IncRejectedStream counts a stream refused at the stream limit.
+func (m *Metrics) IncRejectedStream() { atomic.AddInt64(&m.rejectedStreams, 1) }
+
+// IncDegradedStream counts a stream started degraded under load.
+func (m *Metrics) IncDegradedStream() { atomic.AddInt64(&m.degradedStreams, 1) }
+
+// AddDroppedFrames counts stream frames skipped for a client that read
+// slower than the frame rate.
+//
+// Parameters:
+//   - n: the frames skipped
+func (m *Metrics) AddDroppedFrames(n int64) { atomic.AddInt64(&m.droppedFrames, n) }
+
+// IncScanner counts a request turned away from a scanning tool.
+func (m *Metrics) IncScanner() { atomic.AddInt64(&m.scannerRequests, 1) }
+
+// IncProbe counts a request turned away for probing for vulnerable paths.
+func (m *Metrics) IncProbe() { atomic.AddInt64(&m.probeRequests, 1) }
+
+// IncFlood counts a request turned away from a client sending too many.
+func (m *Metrics) IncFlood() { atomic.AddInt64(&m.floodRequests, 1) }
+
+// ObserveRenderDuration records how long a static render took.
+//
+// Parameters:
+//   - d: the time from the start of the render to its output
+//
+// Example:
+//
+//	start := time.Now()
+//	output, err := renderer.Render(ctx, text, opts)
+//	metrics.ObserveRenderDuration(time.Since(start))
+func (m *Metrics) ObserveRenderDuration(d time.Duration) {
+	m.rendersMu.Lock()
+	defer m.rendersMu.Unlock()
+	if m.renders.Counts == nil {
+		m.renders = newHistogram(RenderBuckets)
+	}
+	m.renders.observe(d.Seconds())
+}
+
+// Snapshot returns a copy of the counters and the render duration
+// histogram. The /stats and /metrics endpoints both report from it.
+//
+// Returns:
+//   - MetricsSnapshot: the counters as they stand
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.rendersMu.Lock()
+	renders := m.renders.clone()
+	m.rendersMu.Unlock()
+	if renders.Counts == nil {
+		renders = newHistogram(RenderBuckets)
+	}
+
+	return MetricsSnapshot{
+		Requests: RequestCounts{
+			Static:          atomic.LoadInt64(&m.staticRequests),
+			Party:           atomic.LoadInt64(&m.partyRequests),
+			Fonts:           atomic.LoadInt64(&m.fontRequests),
+			RejectedStreams: atomic.LoadInt64(&m.rejectedStreams),
+			Errors:          atomic.LoadInt64(&m.totalErrors),
+			Filtered:        atomic.LoadInt64(&m.filteredRequests),
+			Countdown:       atomic.LoadInt64(&m.countdownRequests),
+			Clock:           atomic.LoadInt64(&m.clockRequests),
+			Fortune:         atomic.LoadInt64(&m.fortuneRequests),
+		},
+		Streams: StreamCounts{
+			DroppedFrames: atomic.LoadInt64(&m.droppedFrames),
+			Degraded:      atomic.LoadInt64(&m.degradedStreams),
+		},
+		Bots: BotCounts{
+			Scanner: atomic.LoadInt64(&m.scannerRequests),
+			Probe:   atomic.LoadInt64(&m.probeRequests),
+			Flood:   atomic.LoadInt64(&m.floodRequests),
+		},
+		RenderDuration: renders,
+	}
+}
@@ -0,0 +1,326 @@
+package types
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Alignments are the values of the align option.
+var Alignments = []string{"left", "center", "right"}
+
+// Borders are the values of the border option.
+var Borders = []string{"single", "double", "rounded", "none"}
+
+// ColorModes are the values of the cmode option.
+var ColorModes = []string{"char", "word", "line"}
+
+// GradientDirections are the values of the gdir option.
+var GradientDirections = []string{"h", "v", "d1", "d2", "radial"}
+
+// Engines are the values of the engine option.
+var Engines = []string{"figlet", "block", "braille"}
+
+// MaxScale is the largest value of the scale option.
+const MaxScale = 4
+
+// MaxPadding is the largest value of the pad option.
+const MaxPadding = 4
+
+// FillText is the fill option value that draws each glyph with its own
+// letter rather than one character.
+const FillText = "text"
+
+// OptionRules are the server's defaults and limits for RenderOptions, as
+// Normalize and Validate apply them.
+//
+// Usage example:
+//
+//	rules := OptionRules{
+//	    Defaults:   RenderOptions{Font: "standard", Align: "center"},
+//	    MinSpeed:   1,
+//	    MaxSpeed:   10,
+//	    MaxTimeout: 300,
+//	}
+//	opts = opts.Normalize(rules)
+type OptionRules struct {
+	// Defaults fill in the options a request leaves out
+	Defaults RenderOptions
+	// MinSpeed and MaxSpeed bound Speed; 0 leaves a side open
+	MinSpeed int
+	MaxSpeed int
+	// MaxTimeout caps Timeout, in seconds; 0 is no cap
+	MaxTimeout int
+	// MaxWidth caps MaxWidth, in columns; 0 is no cap
+	MaxWidth int
+	// Colors checks the color and cmode options, such as against the
+	// renderer's presets and color names; nil accepts any color
+	Colors func(color, mode string) error
+	// SolidColor checks the bg and accent options, which take one color;
+	// nil accepts any color
+	SolidColor func(color string) error
+}
+
+// FieldError is one invalid render option.
+type FieldError struct {
+	// Field is the option's long query parameter name, such as "align"
+	Field string
+	// Value is the value received
+	Value string
+	// Message says what is wrong with the value, naming the option
+	Message string
+}
+
+// Error returns the message, which names the option and its value.
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationError holds every render option that failed validation, in
+// the order of RenderOptions' fields.
+//
+// Example:
+//
+//	var invalid *types.ValidationError
+//	if errors.As(err, &invalid) {
+//	    for _, field := range invalid.Fields {
+//	        log.Printf("%s: %s", field.Field, field.Message)
+//	    }
+//	}
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+// Error returns the messages of the invalid options, separated by
+// semicolons.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the FieldErrors, for errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, field := range e.Fields {
+		errs[i] = field
+	}
+	return errs
+}
+
+// Normalize fills the options o leaves out from rules.Defaults, clamps
+// Speed between rules.MinSpeed and MaxSpeed, and caps Timeout and MaxWidth
+// at theirs. Values Normalize can't repair, such as an unknown alignment,
+// are left for Validate to report.
+//
+// Parameters:
+//   - rules: the defaults and limits to apply
+//
+// Returns:
+//   - RenderOptions: a copy of o with the rules applied
+//
+// Example:
+//
+//	opts := RenderOptions{Speed: 15}.Normalize(OptionRules{MinSpeed: 1, MaxSpeed: 10})
+//	// opts.Speed == 10
+func (o RenderOptions) Normalize(rules OptionRules) RenderOptions {
+	d := rules.Defaults
+	out := RenderOptions{
+		Font:        cmp.Or(o.Font, d.Font),
+		Color:       cmp.Or(o.Color, d.Color),
+		ColorMode:   cmp.Or(o.ColorMode, d.ColorMode),
+		GradientDir: cmp.Or(o.GradientDir, d.GradientDir),
+		MaxWidth:    cmp.Or(o.MaxWidth, d.MaxWidth),
+		Timeout:     cmp.Or(o.Timeout, d.Timeout),
+		Speed:       cmp.Or(o.Speed, d.Speed),
+		Align:       cmp.Or(o.Align, d.Align),
+		Border:      cmp.Or(o.Border, d.Border),
+		Theme:       cmp.Or(o.Theme, d.Theme),
+		Background:  cmp.Or(o.Background, d.Background),
+		Accent:      cmp.Or(o.Accent, d.Accent),
+		Padding:     cmp.Or(o.Padding, d.Padding),
+		Engine:      cmp.Or(o.Engine, d.Engine),
+		Scale:       cmp.Or(o.Scale, d.Scale),
+		Fill:        cmp.Or(o.Fill, d.Fill),
+		Hardblank:   cmp.Or(o.Hardblank, d.Hardblank),
+	}
+	// Speed 0 means the route's default, so it stays 0
+	if out.Speed != 0 {
+		if rules.MinSpeed > 0 {
+			out.Speed = max(out.Speed, rules.MinSpeed)
+		}
+		if rules.MaxSpeed > 0 {
+			out.Speed = min(out.Speed, rules.MaxSpeed)
+		}
+	}
+	if rules.MaxTimeout > 0 {
+		out.Timeout = min(out.Timeout, rules.MaxTimeout)
+	}
+	if rules.MaxWidth > 0 {
+		out.MaxWidth = min(out.MaxWidth, rules.MaxWidth)
+	}
+	return out
+}
+
+// Validate checks o against rules and the registries of alignments,
+// borders and color modes, reporting every invalid option rather than
+// stopping at the first. Empty options are valid; they take the route's
+// defaults.
+//
+// Parameters:
+//   - rules: the limits, and the color check, to validate against
+//
+// Returns:
+//   - error: a *ValidationError listing each invalid option, or nil
+//
+// Example:
+//
+//	if err := opts.Validate(rules); err != nil {
+//	    return apierrors.ErrBadOption.WithDetail("%v", err)
+//	}
+func (o RenderOptions) Validate(rules OptionRules) error {
+	var fields []*FieldError
+	fail := func(field, value, format string, args ...any) {
+		fields = append(fields, &FieldError{Field: field, Value: value, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if o.Color != "" || o.ColorMode != "" {
+		if rules.Colors != nil {
+			if err := rules.Colors(o.Color, o.ColorMode); err != nil {
+				fail("color", o.Color, "%v", err)
+			}
+		} else if o.ColorMode != "" && !slices.Contains(ColorModes, strings.ToLower(o.ColorMode)) {
+			fail("cmode", o.ColorMode, "invalid color mode %q: must be %s", o.ColorMode, orList(ColorModes))
+		}
+	}
+	if o.GradientDir != "" && !slices.Contains(GradientDirections, o.GradientDir) {
+		fail("gdir", o.GradientDir, "gdir must be %s, got %q", orList(GradientDirections), o.GradientDir)
+	}
+	if o.MaxWidth < 0 || (rules.MaxWidth > 0 && o.MaxWidth > rules.MaxWidth) {
+		fail("maxwidth", fmt.Sprint(o.MaxWidth), "maxwidth must be between 0 and %d, got %d", rules.MaxWidth, o.MaxWidth)
+	}
+	if o.Timeout < 0 || (rules.MaxTimeout > 0 && o.Timeout > rules.MaxTimeout) {
+		fail("timeout", fmt.Sprint(o.Timeout), "timeout must be between 0 and %d seconds, got %d", rules.MaxTimeout, o.Timeout)
+	}
+	if o.Speed != 0 && ((rules.MinSpeed > 0 && o.Speed < rules.MinSpeed) || (rules.MaxSpeed > 0 && o.Speed > rules.MaxSpeed)) {
+		fail("speed", fmt.Sprint(o.Speed), "speed must be between %d and %d, got %d", rules.MinSpeed, rules.MaxSpeed, o.Speed)
+	}
+	if o.Align != "" && !slices.Contains(Alignments, o.Align) {
+		fail("align", o.Align, "align must be %s, got %q", orList(Alignments), o.Align)
+	}
+	if o.Border != "" && !slices.Contains(Borders, o.Border) {
+		fail("border", o.Border, "border must be %s, got %q", orList(Borders), o.Border)
+	}
+	for _, color := range []struct{ field, value string }{{"bg", o.Background}, {"accent", o.Accent}} {
+		if color.value != "" && rules.SolidColor != nil {
+			if err := rules.SolidColor(color.value); err != nil {
+				fail(color.field, color.value, "%s: %v", color.field, err)
+			}
+		}
+	}
+	if o.Padding < 0 || o.Padding > MaxPadding {
+		fail("pad", fmt.Sprint(o.Padding), "pad must be between 0 and %d, got %d", MaxPadding, o.Padding)
+	}
+	if o.Engine != "" && !slices.Contains(Engines, o.Engine) {
+		fail("engine", o.Engine, "engine must be %s, got %q", orList(Engines), o.Engine)
+	}
+	if o.Scale < 0 || o.Scale > MaxScale {
+		fail("scale", fmt.Sprint(o.Scale), "scale must be between 1 and %d, got %d", MaxScale, o.Scale)
+	}
+	if o.Fill != "" && o.Fill != FillText && !fillRune(o.Fill) {
+		fail("fill", o.Fill, "fill must be one character a column wide or %s, got %q", FillText, o.Fill)
+	}
+	if o.Hardblank != "" && !fillRune(o.Hardblank) {
+		fail("hardblank", o.Hardblank, "hardblank must be one character a column wide, got %q", o.Hardblank)
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// ParseQueryOptions reads render options from query parameters by the
+// short or long names in the query tags of RenderOptions, short names
+// taking precedence as they do on requests.
+//
+// Parameters:
+//   - query: the parameters, such as a preset's "f=doom&c=fire"
+//
+// Returns:
+//   - RenderOptions: the options given
+//   - error: error naming a parameter that isn't an option, or a number
+//     option that isn't a number
+//
+// Example:
+//
+//	query, _ := url.ParseQuery("f=doom&c=fire&s=8")
+//	opts, err := types.ParseQueryOptions(query)
+func ParseQueryOptions(query url.Values) (RenderOptions, error) {
+	var opts RenderOptions
+	v := reflect.ValueOf(&opts).Elem()
+	t := v.Type()
+	known := make(map[string]bool)
+	for i := range t.NumField() {
+		tag := t.Field(i).Tag.Get("query")
+		if tag == "" {
+			continue
+		}
+		names := strings.Split(tag, ",")
+		var raw string
+		for _, name := range names {
+			known[name] = true
+			if raw == "" {
+				raw = query.Get(name)
+			}
+		}
+		if raw == "" {
+			continue
+		}
+		switch field := v.Field(i); field.Kind() {
+		case reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return RenderOptions{}, fmt.Errorf("option %s must be a number, got %q", names[len(names)-1], raw)
+			}
+			field.SetInt(int64(n))
+		default:
+			field.SetString(raw)
+		}
+	}
+	for name := range query {
+		if !known[name] {
+			return RenderOptions{}, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return opts, nil
+}
+
+// orList joins values as "a, b or c".
+func orList(values []string) string {
+	if len(values) < 2 {
+		return strings.Join(values, "")
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+// fillRune reports whether fill is a character the fill and hardblank
+// options can draw with: one visible character, a column wide in terminals.
+func fillRune(fill string) bool {
+	r, size := utf8.DecodeRuneInString(fill)
+	if size != len(fill) || r == utf8.RuneError || !unicode.IsGraphic(r) || unicode.IsSpace(r) || unicode.Is(unicode.Mn, r) {
+		return false
+	}
+	kind := width.LookupRune(r).Kind()
+	return kind != width.EastAsianWide && kind != width.EastAsianFullwidth
+}
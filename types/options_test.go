@@ -0,0 +1,172 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestRenderOptionsNormalize(t *testing.T) {
+	rules := OptionRules{
+		Defaults:   RenderOptions{Font: "standard", Color: "rainbow", Align: "center"},
+		MinSpeed:   2,
+		MaxSpeed:   8,
+		MaxTimeout: 60,
+		MaxWidth:   120,
+	}
+	tests := []struct {
+		name  string
+		input RenderOptions
+		want  RenderOptions
+	}{
+		{
+			name:  "defaults",
+			input: RenderOptions{},
+			want:  RenderOptions{Font: "standard", Color: "rainbow", Align: "center"},
+		},
+		{
+			name:  "request overrides defaults",
+			input: RenderOptions{Font: "doom", Align: "left", Border: "double"},
+			want:  RenderOptions{Font: "doom", Color: "rainbow", Align: "left", Border: "double"},
+		},
+		{
+			name:  "clamped",
+			input: RenderOptions{Speed: 10, Timeout: 600, MaxWidth: 500},
+			want:  RenderOptions{Font: "standard", Color: "rainbow", Align: "center", Speed: 8, Timeout: 60, MaxWidth: 120},
+		},
+		{
+			name:  "slow speed raised",
+			input: RenderOptions{Speed: 1},
+			want:  RenderOptions{Font: "standard", Color: "rainbow", Align: "center", Speed: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.input.Normalize(rules); got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderOptionsValidate(t *testing.T) {
+	rules := OptionRules{
+		MinSpeed:   1,
+		MaxSpeed:   10,
+		MaxTimeout: 60,
+		MaxWidth:   120,
+		Colors: func(color, mode string) error {
+			if color == "plaid" {
+				return fmt.Errorf("unknown color %q", color)
+			}
+			return nil
+		},
+		SolidColor: func(color string) error {
+			if color == "plaid" {
+				return fmt.Errorf("unknown color %q", color)
+			}
+			return nil
+		},
+	}
+	tests := []struct {
+		name       string
+		input      RenderOptions
+		wantFields []string
+	}{
+		{name: "empty", input: RenderOptions{}},
+		{name: "valid", input: RenderOptions{Color: "red", Speed: 5, Timeout: 30, MaxWidth: 80, Align: "right", Border: "rounded"}},
+		{name: "unknown color", input: RenderOptions{Color: "plaid"}, wantFields: []string{"color"}},
+		{name: "speed out of range", input: RenderOptions{Speed: 11}, wantFields: []string{"speed"}},
+		{name: "negative timeout", input: RenderOptions{Timeout: -1}, wantFields: []string{"timeout"}},
+		{name: "gradient direction", input: RenderOptions{Color: "gradient:f00-00f", GradientDir: "radial"}},
+		{name: "unknown gradient direction", input: RenderOptions{GradientDir: "up"}, wantFields: []string{"gdir"}},
+		{name: "frame", input: RenderOptions{Border: "none", Background: "navy", Accent: "gold", Padding: 2}},
+		{name: "unknown background", input: RenderOptions{Background: "plaid"}, wantFields: []string{"bg"}},
+		{name: "padding out of range", input: RenderOptions{Padding: 5}, wantFields: []string{"pad"}},
+		{name: "block engine", input: RenderOptions{Engine: "block", Scale: 2}},
+		{name: "braille engine", input: RenderOptions{Engine: "braille"}},
+		{name: "unknown engine", input: RenderOptions{Engine: "sixel"}, wantFields: []string{"engine"}},
+		{name: "scale out of range", input: RenderOptions{Engine: "block", Scale: 9}, wantFields: []string{"scale"}},
+		{name: "fill character", input: RenderOptions{Fill: "█"}},
+		{name: "fill with text", input: RenderOptions{Fill: "text"}},
+		{name: "fill with a word", input: RenderOptions{Fill: "ab"}, wantFields: []string{"fill"}},
+		{name: "fill with a space", input: RenderOptions{Fill: " "}, wantFields: []string{"fill"}},
+		{name: "fill with a wide character", input: RenderOptions{Fill: "日"}, wantFields: []string{"fill"}},
+		{name: "hardblank character", input: RenderOptions{Hardblank: "."}},
+		{name: "hardblank with text", input: RenderOptions{Hardblank: "text"}, wantFields: []string{"hardblank"}},
+		{
+			name:       "several",
+			input:      RenderOptions{MaxWidth: 200, Align: "middle", Border: "dotted"},
+			wantFields: []string{"maxwidth", "align", "border"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.input.Validate(rules)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			var fields []string
+			for _, field := range invalid.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("invalid fields = %v, want %v (%v)", fields, tt.wantFields, err)
+			}
+		})
+	}
+}
+
+func TestParseQueryOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    RenderOptions
+		wantErr string
+	}{
+		{name: "short names", query: "f=doom&c=red,blue&s=8&a=left", want: RenderOptions{Font: "doom", Color: "red,blue", Speed: 8, Align: "left"}},
+		{name: "long names", query: "font=doom&maxwidth=40&border=double", want: RenderOptions{Font: "doom", MaxWidth: 40, Border: "double"}},
+		{name: "short name first", query: "font=doom&f=small", want: RenderOptions{Font: "small"}},
+		{name: "unknown option", query: "f=doom&volume=11", wantErr: `unknown option "volume"`},
+		{name: "not a number", query: "s=fast", wantErr: `option speed must be a number, got "fast"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseQueryOptions(query)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ParseQueryOptions() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseQueryOptions() = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderOptionsValidateColorMode(t *testing.T) {
+	// Without a color check only the mode is validated
+	err := RenderOptions{Color: "anything", ColorMode: "sparkle"}.Validate(OptionRules{})
+	want := `invalid color mode "sparkle": must be char, word or line`
+	if err == nil || err.Error() != want {
+		t.Errorf("Validate() = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,250 @@
+package types
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the request latency
+// histograms.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// StreamBuckets are the upper bounds, in seconds, of the stream duration
+// histograms.
+var StreamBuckets = []float64{1, 5, 10, 30, 60, 120, 300, 600}
+
+// Histogram counts observations into buckets, as Prometheus histograms do.
+type Histogram struct {
+	// Bounds are the buckets' upper bounds, in ascending order
+	Bounds []float64 `json:"bounds"`
+	// Counts are the observations in each bucket, not cumulative, with
+	// one more for those above the last bound
+	Counts []uint64 `json:"counts"`
+	Sum    float64  `json:"sum"`
+	Count  uint64   `json:"count"`
+}
+
+// newHistogram returns an empty histogram with the given bucket bounds.
+func newHistogram(bounds []float64) Histogram {
+	return Histogram{Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+// observe adds v to the histogram.
+func (h *Histogram) observe(v float64) {
+	i, _ := slices.BinarySearch(h.Bounds, v)
+	h.Counts[i]++
+	h.Sum += v
+	h.Count++
+}
+
+// clone returns a copy of h that doesn't share its counts.
+func (h Histogram) clone() Histogram {
+	h.Counts = slices.Clone(h.Counts)
+	return h
+}
+
+// Summary is the count and total of observations, such as response sizes.
+type Summary struct {
+	Sum   float64
+	Count uint64
+}
+
+// observe adds v to the summary.
+func (s *Summary) observe(v float64) {
+	s.Sum += v
+	s.Count++
+}
+
+// RouteStats are the requests one route answered with one method.
+type RouteStats struct {
+	Method string
+	// Route is the route's path pattern, such as /badge/:label/:value
+	Route string
+	// Statuses counts responses by status code
+	Statuses map[int]uint64
+	// Latency is in seconds
+	Latency Histogram
+	// Size is the response body size in bytes, streams excepted
+	Size Summary
+}
+
+// StreamStats are the streams one route served.
+type StreamStats struct {
+	Route string
+	// Open is the number of streams still running
+	Open int64
+	// Duration is how long closed streams ran, in seconds
+	Duration Histogram
+	// Bytes is what closed streams sent
+	Bytes Summary
+}
+
+// routeKey identifies a RouteStats.
+type routeKey struct {
+	method string
+	route  string
+}
+
+// lockedRoute and lockedStream guard a route's stats.
+type lockedRoute struct {
+	mu    sync.Mutex
+	stats RouteStats
+}
+
+type lockedStream struct {
+	mu    sync.Mutex
+	stats StreamStats
+}
+
+// RouteStarted counts a request in route's in-flight gauge until the
+// returned function is called.
+//
+// Parameters:
+//   - route: the route's path pattern
+//
+// Returns:
+//   - func(): called when the route has answered
+//
+// Example:
+//
+//	done := metrics.RouteStarted("/date")
+//	defer done()
+func (m *Metrics) RouteStarted(route string) func() {
+	count, _ := m.inFlight.LoadOrStore(route, new(int64))
+	atomic.AddInt64(count.(*int64), 1)
+	return func() { atomic.AddInt64(count.(*int64), -1) }
+}
+
+// ObserveRequest records an answered request in its route's status
+// counts, latency histogram and response size summary. Streams are
+// recorded by StreamClosed instead.
+//
+// Parameters:
+//   - method: the request method
+//   - route: the route's path pattern
+//   - status: the response status code
+//   - latency: how long the request took to answer
+//   - size: the response body size in bytes
+//
+// Example:
+//
+//	metrics.ObserveRequest("GET", "/date", 200, 3*time.Millisecond, 512)
+func (m *Metrics) ObserveRequest(method, route string, status int, latency time.Duration, size int64) {
+	key := routeKey{method: method, route: route}
+	value, ok := m.routes.Load(key)
+	if !ok {
+		value, _ = m.routes.LoadOrStore(key, &lockedRoute{stats: RouteStats{
+			Method:   method,
+			Route:    route,
+			Statuses: make(map[int]uint64),
+			Latency:  newHistogram(LatencyBuckets),
+		}})
+	}
+	r := value.(*lockedRoute)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Statuses[status]++
+	r.stats.Latency.observe(latency.Seconds())
+	r.stats.Size.observe(float64(size))
+}
+
+// StreamOpened counts a stream of route as open until StreamClosed.
+//
+// Parameters:
+//   - route: the route's path pattern
+func (m *Metrics) StreamOpened(route string) {
+	s := m.stream(route)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Open++
+}
+
+// StreamClosed records a stream of route that has ended, with how long
+// it ran and the bytes it sent.
+//
+// Parameters:
+//   - route: the route's path pattern
+//   - duration: how long the stream ran
+//   - sent: the bytes sent
+func (m *Metrics) StreamClosed(route string, duration time.Duration, sent int64) {
+	s := m.stream(route)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Open--
+	s.stats.Duration.observe(duration.Seconds())
+	s.stats.Bytes.observe(float64(sent))
+}
+
+// stream returns the stats of route's streams, creating them if needed.
+func (m *Metrics) stream(route string) *lockedStream {
+	value, ok := m.streams.Load(route)
+	if !ok {
+		value, _ = m.streams.LoadOrStore(route, &lockedStream{stats: StreamStats{
+			Route:    route,
+			Duration: newHistogram(StreamBuckets),
+		}})
+	}
+	return value.(*lockedStream)
+}
+
+// Routes returns a copy of the stats of every route that has answered a
+// request, sorted by route and method.
+//
+// Returns:
+//   - []RouteStats: the routes' stats
+func (m *Metrics) Routes() []RouteStats {
+	var routes []RouteStats
+	m.routes.Range(func(_, value any) bool {
+		r := value.(*lockedRoute)
+		r.mu.Lock()
+		stats := r.stats
+		stats.Statuses = make(map[int]uint64, len(r.stats.Statuses))
+		for status, count := range r.stats.Statuses {
+			stats.Statuses[status] = count
+		}
+		stats.Latency = r.stats.Latency.clone()
+		r.mu.Unlock()
+		routes = append(routes, stats)
+		return true
+	})
+	slices.SortFunc(routes, func(a, b RouteStats) int {
+		return cmp.Or(cmp.Compare(a.Route, b.Route), cmp.Compare(a.Method, b.Method))
+	})
+	return routes
+}
+
+// Streams returns a copy of the stats of every route that has opened a
+// stream, sorted by route.
+//
+// Returns:
+//   - []StreamStats: the streams' stats
+func (m *Metrics) Streams() []StreamStats {
+	var streams []StreamStats
+	m.streams.Range(func(_, value any) bool {
+		s := value.(*lockedStream)
+		s.mu.Lock()
+		stats := s.stats
+		stats.Duration = s.stats.Duration.clone()
+		s.mu.Unlock()
+		streams = append(streams, stats)
+		return true
+	})
+	slices.SortFunc(streams, func(a, b StreamStats) int { return cmp.Compare(a.Route, b.Route) })
+	return streams
+}
+
+// InFlight returns the requests each route is answering now.
+//
+// Returns:
+//   - map[string]int64: requests in flight by route
+func (m *Metrics) InFlight() map[string]int64 {
+	counts := make(map[string]int64)
+	m.inFlight.Range(func(route, count any) bool {
+		counts[route.(string)] = atomic.LoadInt64(count.(*int64))
+		return true
+	})
+	return counts
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		wantCounts []uint64
+	}{
+		{name: "below first bound", values: []float64{0.5}, wantCounts: []uint64{1, 0, 0, 0}},
+		{name: "on a bound", values: []float64{1, 2}, wantCounts: []uint64{1, 1, 0, 0}},
+		{name: "between bounds", values: []float64{1.5, 4}, wantCounts: []uint64{0, 1, 1, 0}},
+		{name: "above last bound", values: []float64{9}, wantCounts: []uint64{0, 0, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistogram([]float64{1, 2, 5})
+			var sum float64
+			for _, v := range tt.values {
+				h.observe(v)
+				sum += v
+			}
+			if !slices.Equal(h.Counts, tt.wantCounts) {
+				t.Errorf("counts = %v, want %v", h.Counts, tt.wantCounts)
+			}
+			if h.Count != uint64(len(tt.values)) || h.Sum != sum {
+				t.Errorf("count, sum = %d, %v, want %d, %v", h.Count, h.Sum, len(tt.values), sum)
+			}
+		})
+	}
+}
+
+func TestRouteMetrics(t *testing.T) {
+	m := &Metrics{}
+	done := m.RouteStarted("/date")
+	if got := m.InFlight()["/date"]; got != 1 {
+		t.Errorf("in flight = %d, want 1", got)
+	}
+	m.ObserveRequest("GET", "/date", 200, 3*time.Millisecond, 100)
+	m.ObserveRequest("GET", "/date", 429, 20*time.Millisecond, 50)
+	m.ObserveRequest("POST", "/ans", 200, time.Second, 2048)
+	done()
+	if got := m.InFlight()["/date"]; got != 0 {
+		t.Errorf("in flight = %d, want 0", got)
+	}
+
+	routes := m.Routes()
+	if len(routes) != 2 || routes[0].Route != "/ans" || routes[1].Route != "/date" {
+		t.Fatalf("routes = %+v, want /ans and /date", routes)
+	}
+	date := routes[1]
+	if date.Statuses[200] != 1 || date.Statuses[429] != 1 {
+		t.Errorf("statuses = %v, want a 200 and a 429", date.Statuses)
+	}
+	if date.Latency.Count != 2 || date.Latency.Counts[0] != 1 || date.Latency.Counts[2] != 1 {
+		t.Errorf("latency = %+v, want one request in the 5ms bucket and one in the 25ms", date.Latency)
+	}
+	if date.Size.Count != 2 || date.Size.Sum != 150 {
+		t.Errorf("size = %+v, want 2 responses of 150 bytes", date.Size)
+	}
+	// Routes returns copies
+	routes[1].Statuses[200] = 99
+	if m.Routes()[1].Statuses[200] != 1 {
+		t.Error("Routes() shares its status counts")
+	}
+
+	m.StreamOpened("/p/*")
+	m.StreamOpened("/p/*")
+	m.StreamClosed("/p/*", 90*time.Second, 4096)
+	streams := m.Streams()
+	if len(streams) != 1 {
+		t.Fatalf("streams = %+v, want /p/*", streams)
+	}
+	if s := streams[0]; s.Open != 1 || s.Duration.Count != 1 || s.Duration.Counts[5] != 1 || s.Bytes.Sum != 4096 {
+		t.Errorf("streams = %+v, want one open, and one closed after 90s with 4096 bytes", s)
+	}
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// themeName matches the names of themes: lowercase letters, digits and
+// dashes.
+var themeName = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Theme is a named look for banners (SHOUT_TEXT_THEMES). Its slots fill in
+// the render options a request leaves out, and options the request gives
+// override them, so ?theme=ocean&accent=gold keeps the theme but for the
+// border's color.
+//
+// Usage example:
+//
+//	theme, err := ParseTheme("ocean:fg=gradient:00bfff-00008b&accent=skyblue&border=rounded&pad=1")
+//	opts = opts.Normalize(OptionRules{Defaults: theme.Options()})
+type Theme struct {
+	Name string `json:"name"`
+	// FG colors the text, as the color option does, gradients included
+	FG string `json:"fg,omitempty"`
+	// BG is the color behind the banner, as the bg option
+	BG string `json:"bg,omitempty"`
+	// Accent colors the border, as the accent option
+	Accent string `json:"accent,omitempty"`
+	// Border is the border style, as the border option
+	Border string `json:"border,omitempty"`
+	// Padding is the space inside the border, as the pad option
+	Padding int `json:"pad,omitempty"`
+}
+
+// themeSlots are the parameters of a theme definition.
+var themeSlots = []string{"fg", "bg", "accent", "border", "pad"}
+
+// ParseTheme parses a theme as written in SHOUT_TEXT_THEMES: its name, a
+// colon, and its slots as query parameters (fg, bg, accent, border and
+// pad). Colors are checked for their form only; see RenderOptions.Validate
+// with OptionRules.Colors to check them against the renderer.
+//
+// Parameters:
+//   - entry: the theme, such as "mono:fg=white&bg=black&border=double"
+//
+// Returns:
+//   - Theme: the parsed theme
+//   - error: error naming the theme and what is wrong with it
+//
+// Example:
+//
+//	theme, err := types.ParseTheme("mono:fg=white&bg=black&border=double")
+func ParseTheme(entry string) (Theme, error) {
+	name, query, _ := strings.Cut(entry, ":")
+	if !themeName.MatchString(name) {
+		return Theme{}, fmt.Errorf("theme %q must be a name of lowercase letters, digits and dashes, :, and slots, e.g. mono:fg=white&bg=black", entry)
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return Theme{}, fmt.Errorf("theme %s: %w", name, err)
+	}
+	for slot := range values {
+		if !slices.Contains(themeSlots, slot) {
+			return Theme{}, fmt.Errorf("theme %s: unknown slot %q: must be %s", name, slot, orList(themeSlots))
+		}
+	}
+	theme := Theme{
+		Name:   name,
+		FG:     values.Get("fg"),
+		BG:     values.Get("bg"),
+		Accent: values.Get("accent"),
+		Border: values.Get("border"),
+	}
+	if pad := values.Get("pad"); pad != "" {
+		if theme.Padding, err = strconv.Atoi(pad); err != nil {
+			return Theme{}, fmt.Errorf("theme %s: pad must be a number, got %q", name, pad)
+		}
+	}
+	if err := theme.Options().Validate(OptionRules{}); err != nil {
+		return Theme{}, fmt.Errorf("theme %s: %w", name, err)
+	}
+	return theme, nil
+}
+
+// Options returns the render options the theme's slots set, with Theme
+// naming it.
+//
+// Returns:
+//   - RenderOptions: the theme as options, to be used as defaults
+func (t Theme) Options() RenderOptions {
+	return RenderOptions{
+		Theme:      t.Name,
+		Color:      t.FG,
+		Background: t.BG,
+		Accent:     t.Accent,
+		Border:     t.Border,
+		Padding:    t.Padding,
+	}
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		want    Theme
+		wantErr bool
+	}{
+		{
+			name:  "every slot",
+			entry: "ocean:fg=gradient:00bfff-0000cd&bg=black&accent=skyblue&border=rounded&pad=1",
+			want:  Theme{Name: "ocean", FG: "gradient:00bfff-0000cd", BG: "black", Accent: "skyblue", Border: "rounded", Padding: 1},
+		},
+		{name: "some slots", entry: "plain:border=single", want: Theme{Name: "plain", Border: "single"}},
+		{name: "no slots", entry: "bare", want: Theme{Name: "bare"}},
+		{name: "bad name", entry: "Ocean:fg=blue", wantErr: true},
+		{name: "unknown slot", entry: "loud:f=doom", wantErr: true},
+		{name: "bad border", entry: "wavy:border=wavy", wantErr: true},
+		{name: "pad not a number", entry: "roomy:pad=lots", wantErr: true},
+		{name: "pad out of range", entry: "roomy:pad=9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTheme(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTheme(%q) error = %v, wantErr %v", tt.entry, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseTheme(%q) = %+v, want %+v", tt.entry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThemeOptions(t *testing.T) {
+	theme := Theme{Name: "mono", FG: "white", BG: "black", Accent: "gray", Border: "double", Padding: 2}
+	want := RenderOptions{Theme: "mono", Color: "white", Background: "black", Accent: "gray", Border: "double", Padding: 2}
+	if got := theme.Options(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Options() = %+v, want %+v", got, want)
+	}
+
+	// Options a request gives take precedence over the theme's slots
+	opts := RenderOptions{Accent: "gold", Border: "none"}.Normalize(OptionRules{Defaults: theme.Options()})
+	want = RenderOptions{Theme: "mono", Color: "white", Background: "black", Accent: "gold", Border: "none", Padding: 2}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("Normalize() = %+v, want %+v", opts, want)
+	}
+}
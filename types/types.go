@@ -1,12 +1,15 @@
 package types
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // RenderOptions represents options for rendering ASCII art.
 // These options control the visual appearance and behavior of the generated text.
+// The query tag lists the short and long parameter names, and the help tag
+// describes the option on the /help page.
 //
 // Usage example:
 //
@@ -16,33 +19,72 @@ import (
 //	    Speed: 5,
 //	}
 type RenderOptions struct {
-	Font     string `json:"font" query:"f,font"`
-	Color    string `json:"color" query:"c,color"`
-	MaxWidth int    `json:"maxwidth" query:"mw,maxwidth"`
-	Timeout  int    `json:"timeout" query:"t,timeout"`
-	Speed    int    `json:"speed" query:"s,speed"`
-	Align    string `json:"align" query:"a,align"`
-	Border   string `json:"border" query:"b,border"`
+	Font        string `json:"font" query:"f,font" help:"Font name, or random; misspelled names use the closest match"`
+	Color       string `json:"color" query:"c,color" help:"Color preset, #RRGGBB, rgb(r,g,b), color name, a comma-separated list, or gradient: and dash-separated colors, such as gradient:f00-ff0-0f0"`
+	ColorMode   string `json:"cmode" query:"cmode" help:"How a color list cycles: per drawn character of the banner (char), or per word or line of the text"`
+	GradientDir string `json:"gdir" query:"gdir" help:"Direction of a gradient: h, v, d1 (top left to bottom right), d2 (bottom left to top right) or radial"`
+	MaxWidth    int    `json:"maxwidth" query:"mw,maxwidth" help:"Maximum banner width in columns"`
+	Timeout     int    `json:"timeout" query:"t,timeout" help:"Animation timeout in seconds; for static renders, a shorter render budget"`
+	Speed       int    `json:"speed" query:"s,speed" help:"Animation speed (1-10)"`
+	Align       string `json:"align" query:"a,align" help:"Text alignment: left, center or right"`
+	Border      string `json:"border" query:"b,border" help:"Border style: single, double, rounded or none"`
+	Theme       string `json:"theme" query:"theme" help:"Named look from the server's themes, filling in the text color, background, accent, border and padding; options given alongside override it"`
+	Background  string `json:"bg" query:"bg" help:"Color behind the banner: #RRGGBB, rgb(r,g,b) or a color name"`
+	Accent      string `json:"accent" query:"accent" help:"Color of the border: #RRGGBB, rgb(r,g,b) or a color name"`
+	Padding     int    `json:"pad" query:"pad" help:"Space between the banner and its border or background: lines above and below, and twice as many columns either side, 0-4"`
+	Engine      string `json:"engine" query:"engine" help:"Renderer: figlet draws with the font; block and braille with a built-in 5x7 pixel font in block characters or braille dots"`
+	Scale       int    `json:"scale" query:"scale" help:"Size of the block and braille engines' pixels, 1-4"`
+	Fill        string `json:"fill" query:"fill" help:"Character to draw every glyph with, such as █ or #, or text to draw each glyph with its own letter"`
+	Hardblank   string `json:"hardblank" query:"hardblank" help:"Character to draw the font's hardblanks with, the blanks it keeps inside and between glyphs, rather than spaces"`
 }
 
 // ConnectionManager manages concurrent streaming connections.
-// It enforces a maximum number of simultaneous streams to prevent resource exhaustion.
+// It enforces a maximum number of simultaneous streams to prevent resource
+// exhaustion, and optionally limits per key, such as a client's IP or API
+// key, in the same count, so a stream takes its slots in every scope at
+// once or in none.
+//
+// A key's count is kept while it has streams open and for the idle time
+// after its last one closes, so clients reconnecting between streams reuse
+// their entry, and dropped after that so the keys don't grow with every
+// client seen.
 //
 // The type is safe for concurrent use.
 //
 // Usage example:
 //
-//	cm := NewConnectionManager(100)
-//	if cm.TryAcquire() {
-//	    defer cm.Release()
+//	cm := NewKeyedConnectionManager(100, 5*time.Minute)
+//	ip := KeyLimit{Key: "ip:" + addr, Max: 3}
+//	if cm.TryAcquire(ip) {
+//	    defer cm.Release(ip.Key)
 //	    // Handle streaming connection
 //	}
 type ConnectionManager struct {
 	activeStreams int64
 	maxStreams    int64
+	idle          time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]*keyStreams
+	lastSweep time.Time
+}
+
+// keyStreams is the count of one key of a ConnectionManager.
+type keyStreams struct {
+	active int64
+	// idleSince is when active last dropped to zero
+	idleSince time.Time
+}
+
+// KeyLimit caps the streams one key holds at once. A Max of 0 is
+// unlimited, but the key's streams are still counted.
+type KeyLimit struct {
+	Key string
+	Max int64
 }
 
 // NewConnectionManager creates a new ConnectionManager with the specified maximum concurrent streams.
+// Keys are dropped as soon as their last stream closes.
 //
 // Parameters:
 //   - maxStreams: the maximum number of concurrent streaming connections allowed
@@ -54,15 +96,38 @@ type ConnectionManager struct {
 //
 //	cm := NewConnectionManager(100)
 func NewConnectionManager(maxStreams int64) *ConnectionManager {
+	return NewKeyedConnectionManager(maxStreams, 0)
+}
+
+// NewKeyedConnectionManager creates a ConnectionManager that keeps the
+// count of a key for idle after its last stream closes.
+//
+// Parameters:
+//   - maxStreams: the maximum number of concurrent streaming connections allowed
+//   - idle: how long a key without streams is kept
+//
+// Returns:
+//   - *ConnectionManager: a new connection manager instance
+//
+// Example:
+//
+//	cm := NewKeyedConnectionManager(100, 5*time.Minute)
+func NewKeyedConnectionManager(maxStreams int64, idle time.Duration) *ConnectionManager {
 	return &ConnectionManager{
 		maxStreams: maxStreams,
+		idle:       idle,
+		keys:       make(map[string]*keyStreams),
 	}
 }
 
-// TryAcquire attempts to acquire a streaming connection slot.
+// TryAcquire attempts to acquire a streaming connection slot, and one
+// under each of limits.
+//
+// Parameters:
+//   - limits: the keys the stream also counts against
 //
 // Returns:
-//   - bool: true if a slot was acquired, false if at maximum capacity
+//   - bool: true if the slots were acquired, false if any is at maximum capacity
 //
 // Example:
 //
@@ -70,23 +135,92 @@ func NewConnectionManager(maxStreams int64) *ConnectionManager {
 //	    defer cm.Release()
 //	    // Stream content
 //	}
-func (cm *ConnectionManager) TryAcquire() bool {
-	current := atomic.LoadInt64(&cm.activeStreams)
-	if current >= cm.maxStreams {
-		return false
+func (cm *ConnectionManager) TryAcquire(limits ...KeyLimit) bool {
+	_, ok := cm.Acquire(limits...)
+	return ok
+}
+
+// Acquire is TryAcquire, also reporting the limit that refused the stream:
+// one with an empty Key for the overall maximum.
+//
+// Parameters:
+//   - limits: the keys the stream also counts against
+//
+// Returns:
+//   - KeyLimit: the limit reached, when the stream is refused
+//   - bool: true if the slots were acquired
+//
+// Example:
+//
+//	if full, ok := cm.Acquire(ip, key); !ok {
+//	    return fmt.Errorf("%s allows %d streams at once", full.Key, full.Max)
+//	}
+//	defer cm.Release(ip.Key, key.Key)
+func (cm *ConnectionManager) Acquire(limits ...KeyLimit) (KeyLimit, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if atomic.LoadInt64(&cm.activeStreams) >= cm.maxStreams {
+		return KeyLimit{Max: cm.maxStreams}, false
+	}
+	for _, limit := range limits {
+		if k := cm.keys[limit.Key]; limit.Max > 0 && k != nil && k.active >= limit.Max {
+			return limit, false
+		}
 	}
+
 	atomic.AddInt64(&cm.activeStreams, 1)
-	return true
+	for _, limit := range limits {
+		k := cm.keys[limit.Key]
+		if k == nil {
+			k = &keyStreams{}
+			cm.keys[limit.Key] = k
+		}
+		k.active++
+	}
+	cm.sweep(time.Now())
+	return KeyLimit{}, true
 }
 
-// Release releases a streaming connection slot.
-// Should be called when a streaming connection ends.
+// Release releases a streaming connection slot, and the slots of keys.
+// Should be called when a streaming connection ends, with the keys it was
+// acquired under.
 //
 // Example:
 //
 //	cm.Release()
-func (cm *ConnectionManager) Release() {
+func (cm *ConnectionManager) Release(keys ...string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	atomic.AddInt64(&cm.activeStreams, -1)
+	now := time.Now()
+	for _, key := range keys {
+		k := cm.keys[key]
+		if k == nil {
+			continue
+		}
+		if k.active--; k.active > 0 {
+			continue
+		}
+		if cm.idle <= 0 {
+			delete(cm.keys, key)
+			continue
+		}
+		k.idleSince = now
+	}
+}
+
+// sweep drops the keys idle for longer than cm.idle, at most once per
+// idle period. The caller holds cm.mu.
+func (cm *ConnectionManager) sweep(now time.Time) {
+	if cm.idle <= 0 || now.Sub(cm.lastSweep) < cm.idle {
+		return
+	}
+	cm.lastSweep = now
+	for key, k := range cm.keys {
+		if k.active == 0 && now.Sub(k.idleSince) >= cm.idle {
+			delete(cm.keys, key)
+		}
+	}
 }
 
 // GetActiveCount returns the current number of active streaming connections.
@@ -102,6 +236,37 @@ func (cm *ConnectionManager) GetActiveCount() int64 {
 	return atomic.LoadInt64(&cm.activeStreams)
 }
 
+// GetKeyCount returns the number of streams key holds.
+//
+// Parameters:
+//   - key: the key, as given to TryAcquire
+//
+// Returns:
+//   - int64: the number of the key's active connections
+//
+// Example:
+//
+//	open := cm.GetKeyCount("ip:203.0.113.7")
+func (cm *ConnectionManager) GetKeyCount(key string) int64 {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if k := cm.keys[key]; k != nil {
+		return k.active
+	}
+	return 0
+}
+
+// GetKeyTotal returns the number of keys held, including idle ones not yet
+// dropped.
+//
+// Returns:
+//   - int: the number of keys
+func (cm *ConnectionManager) GetKeyTotal() int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return len(cm.keys)
+}
+
 // Config represents the complete application configuration.
 // All settings are grouped into logical sections for better organization.
 //
@@ -158,17 +323,78 @@ type TextConfig struct {
 	DefaultBorder string `yaml:"defaultBorder"`
 }
 
-// Metrics tracks application metrics for monitoring.
-// All fields should be accessed atomically for thread safety.
+// Metrics tracks application metrics for monitoring. Counters are only
+// read and written through its methods, which are safe for concurrent
+// use, and read together with Snapshot.
 //
 // Usage example:
 //
-//	atomic.AddInt64(&metrics.StaticRequests, 1)
-//	count := atomic.LoadInt64(&metrics.StaticRequests)
+//	metrics.IncStatic()
+//	metrics.ObserveRenderDuration(time.Since(start))
+//	fmt.Println(metrics.Snapshot().Requests.Static)
 type Metrics struct {
-	StaticRequests  int64 `json:"staticRequests"`
-	PartyRequests   int64 `json:"partyRequests"`
-	FontRequests    int64 `json:"fontRequests"`
-	RejectedStreams int64 `json:"rejectedStreams"`
-	TotalErrors     int64 `json:"totalErrors"`
+	staticRequests   int64
+	partyRequests    int64
+	fontRequests     int64
+	rejectedStreams  int64
+	totalErrors      int64
+	filteredRequests int64
+	// countdownRequests counts countdown streams started.
+	countdownRequests int64
+	// clockRequests counts /date and /time renders.
+	clockRequests int64
+	// fortuneRequests counts /fortune renders.
+	fortuneRequests int64
+	// droppedFrames counts stream frames skipped for clients that read
+	// slower than the frame rate.
+	droppedFrames int64
+	// degradedStreams counts streams started degraded under load.
+	degradedStreams int64
+	// scannerRequests, probeRequests and floodRequests count requests
+	// turned away as bots, by why they were classified.
+	scannerRequests int64
+	probeRequests   int64
+	floodRequests   int64
+	// renders is how long static renders took, in seconds, guarded by
+	// rendersMu
+	rendersMu sync.Mutex
+	renders   Histogram
+	// countries counts requests by client country, as *int64, when GeoIP
+	// is on.
+	countries sync.Map
+	// routes holds a *lockedRoute per method and route, streams a
+	// *lockedStream per route and inFlight an *int64 per route.
+	routes   sync.Map
+	streams  sync.Map
+	inFlight sync.Map
+	// usage counts renders by option and value, as *int64, when usage
+	// analytics are on.
+	usage sync.Map
+}
+
+// AddCountry counts a request from country.
+//
+// Parameters:
+//   - country: ISO country code of the client
+//
+// Example:
+//
+//	metrics.AddCountry("DE")
+func (m *Metrics) AddCountry(country string) {
+	count, _ := m.countries.LoadOrStore(country, new(int64))
+	atomic.AddInt64(count.(*int64), 1)
+}
+
+// Countries returns the request counts by country, which are empty when
+// GeoIP is off.
+//
+// Returns:
+//   - map[string]int64: requests by ISO country code
+func (m *Metrics) Countries() map[string]int64 {
+	counts := make(map[string]int64)
+	m.countries.Range(func(country, count any) bool {
+		counts[country.(string)] = atomic.LoadInt64(count.(*int64))
+		return true
+	})
+	return counts
 }
@@ -17,6 +17,7 @@ func TestRenderOptions(t *testing.T) {
 			input: `{
 				"font": "doom",
 				"color": "rainbow",
+				"cmode": "word",
 				"maxwidth": 80,
 				"timeout": 10,
 				"speed": 5,
@@ -24,13 +25,14 @@ func TestRenderOptions(t *testing.T) {
 				"border": "double"
 			}`,
 			expected: RenderOptions{
-				Font:     "doom",
-				Color:    "rainbow",
-				MaxWidth: 80,
-				Timeout:  10,
-				Speed:    5,
-				Align:    "center",
-				Border:   "double",
+				Font:      "doom",
+				Color:     "rainbow",
+				ColorMode: "word",
+				MaxWidth:  80,
+				Timeout:   10,
+				Speed:     5,
+				Align:     "center",
+				Border:    "double",
 			},
 		},
 		{
@@ -54,6 +56,9 @@ func TestRenderOptions(t *testing.T) {
 			if opts.Color != tt.expected.Color {
 				t.Errorf("Color mismatch: got %s, want %s", opts.Color, tt.expected.Color)
 			}
+			if opts.ColorMode != tt.expected.ColorMode {
+				t.Errorf("ColorMode mismatch: got %s, want %s", opts.ColorMode, tt.expected.ColorMode)
+			}
 			if opts.MaxWidth != tt.expected.MaxWidth {
 				t.Errorf("MaxWidth mismatch: got %d, want %d", opts.MaxWidth, tt.expected.MaxWidth)
 			}
@@ -113,6 +118,62 @@ func TestConnectionManager(t *testing.T) {
 	}
 }
 
+func TestConnectionManagerKeys(t *testing.T) {
+	cm := NewKeyedConnectionManager(3, time.Hour)
+	ip := KeyLimit{Key: "ip:1.2.3.4", Max: 1}
+	key := KeyLimit{Key: "key:acme", Max: 2}
+
+	if !cm.TryAcquire(key, ip) {
+		t.Fatal("first stream refused")
+	}
+	if full, ok := cm.Acquire(key, ip); ok || full != ip {
+		t.Errorf("Acquire() = %+v, %v, want refused by %+v", full, ok, ip)
+	}
+	// A refused stream takes no slot anywhere
+	if cm.GetActiveCount() != 1 || cm.GetKeyCount(key.Key) != 1 {
+		t.Errorf("counts = %d, %d after a refusal, want 1, 1", cm.GetActiveCount(), cm.GetKeyCount(key.Key))
+	}
+	if !cm.TryAcquire(key, KeyLimit{Key: "ip:5.6.7.8", Max: 1}) {
+		t.Error("key's second stream from another IP refused")
+	}
+	if full, ok := cm.Acquire(KeyLimit{Key: "ip:9.9.9.9"}); !ok {
+		t.Errorf("Acquire() refused by %+v, want a slot under the maximum of 3", full)
+	}
+	if full, ok := cm.Acquire(); ok || full.Key != "" || full.Max != 3 {
+		t.Errorf("Acquire() = %+v, %v, want refused by the maximum", full, ok)
+	}
+
+	cm.Release(key.Key, ip.Key)
+	if !cm.TryAcquire(ip) {
+		t.Error("stream refused after release")
+	}
+	cm.Release(ip.Key)
+
+	// Idle keys are kept until the idle time has passed
+	if got := cm.GetKeyTotal(); got != 4 {
+		t.Errorf("GetKeyTotal() = %d, want 4 with one idle", got)
+	}
+	cm.mu.Lock()
+	cm.keys[ip.Key].idleSince = time.Now().Add(-2 * time.Hour)
+	cm.lastSweep = time.Time{}
+	cm.mu.Unlock()
+	cm.Release("ip:9.9.9.9")
+	if !cm.TryAcquire() {
+		t.Fatal("stream refused after release")
+	}
+	if got := cm.GetKeyTotal(); got != 3 {
+		t.Errorf("GetKeyTotal() = %d after a sweep, want 3", got)
+	}
+
+	// Without an idle time keys are dropped with their last stream
+	cm = NewConnectionManager(2)
+	cm.TryAcquire(ip)
+	cm.Release(ip.Key)
+	if got := cm.GetKeyTotal(); got != 0 {
+		t.Errorf("GetKeyTotal() = %d, want 0", got)
+	}
+}
+
 func TestConfig(t *testing.T) {
 	cfg := Config{
 		Server: ServerConfig{
@@ -190,27 +251,40 @@ func TestConfig(t *testing.T) {
 }
 
 func TestMetrics(t *testing.T) {
-	m := &Metrics{
-		StaticRequests:  100,
-		PartyRequests:   50,
-		FontRequests:    10,
-		RejectedStreams: 5,
-		TotalErrors:     2,
+	m := &Metrics{}
+	for range 3 {
+		m.IncStatic()
 	}
+	m.IncParty()
+	m.IncRejectedStream()
+	m.IncError()
+	m.IncFiltered()
+	m.AddDroppedFrames(4)
+	m.IncProbe()
+	m.ObserveRenderDuration(3 * time.Millisecond)
+	m.ObserveRenderDuration(2 * time.Second)
 
-	if m.StaticRequests != 100 {
-		t.Errorf("StaticRequests should be 100, got %d", m.StaticRequests)
+	snap := m.Snapshot()
+	wantRequests := RequestCounts{Static: 3, Party: 1, RejectedStreams: 1, Errors: 1, Filtered: 1}
+	if snap.Requests != wantRequests {
+		t.Errorf("Requests = %+v, want %+v", snap.Requests, wantRequests)
 	}
-	if m.PartyRequests != 50 {
-		t.Errorf("PartyRequests should be 50, got %d", m.PartyRequests)
+	if snap.Streams != (StreamCounts{DroppedFrames: 4}) {
+		t.Errorf("Streams = %+v, want 4 dropped frames", snap.Streams)
 	}
-	if m.FontRequests != 10 {
-		t.Errorf("FontRequests should be 10, got %d", m.FontRequests)
+	if snap.Bots != (BotCounts{Probe: 1}) {
+		t.Errorf("Bots = %+v, want 1 probe", snap.Bots)
 	}
-	if m.RejectedStreams != 5 {
-		t.Errorf("RejectedStreams should be 5, got %d", m.RejectedStreams)
+	if snap.RenderDuration.Count != 2 || snap.RenderDuration.Counts[len(RenderBuckets)-1] != 1 {
+		t.Errorf("RenderDuration = %+v, want 2 renders, 1 under 2.5s", snap.RenderDuration)
+	}
+
+	// The snapshot is a copy
+	m.ObserveRenderDuration(time.Millisecond)
+	if snap.RenderDuration.Count != 2 {
+		t.Error("Snapshot should not change with later renders")
 	}
-	if m.TotalErrors != 2 {
-		t.Errorf("TotalErrors should be 2, got %d", m.TotalErrors)
+	if empty := (&Metrics{}).Snapshot(); len(empty.RenderDuration.Counts) != len(RenderBuckets)+1 {
+		t.Errorf("empty RenderDuration = %+v, want a bucket per bound", empty.RenderDuration)
 	}
 }
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// Options whose usage is counted by AddUsage.
+const (
+	UsageFont      = "font"
+	UsageColor     = "color"
+	UsageAnimation = "animation"
+)
+
+// usageKey identifies a usage counter.
+type usageKey struct {
+	option string
+	value  string
+}
+
+// AddUsage counts a render that used value for option, such as the doom
+// font or the fire color preset. Callers keep values to a bounded set.
+//
+// Parameters:
+//   - option: UsageFont, UsageColor or UsageAnimation
+//   - value: the font, color or animation name
+//
+// Example:
+//
+//	metrics.AddUsage(types.UsageFont, "doom")
+func (m *Metrics) AddUsage(option, value string) {
+	count, ok := m.usage.Load(usageKey{option: option, value: value})
+	if !ok {
+		// value may share a request's buffers, which are reused
+		count, _ = m.usage.LoadOrStore(usageKey{option: option, value: strings.Clone(value)}, new(int64))
+	}
+	atomic.AddInt64(count.(*int64), 1)
+}
+
+// Usage returns the render counts of option by value, which are empty
+// when usage analytics are off.
+//
+// Parameters:
+//   - option: UsageFont, UsageColor or UsageAnimation
+//
+// Returns:
+//   - map[string]int64: renders by value
+//
+// Example:
+//
+//	fonts := metrics.Usage(types.UsageFont)
+func (m *Metrics) Usage(option string) map[string]int64 {
+	counts := make(map[string]int64)
+	m.usage.Range(func(key, count any) bool {
+		if k := key.(usageKey); k.option == option {
+			counts[k.value] = atomic.LoadInt64(count.(*int64))
+		}
+		return true
+	})
+	return counts
+}
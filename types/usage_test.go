@@ -0,0 +1,24 @@
+package types
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestUsage(t *testing.T) {
+	m := &Metrics{}
+	m.AddUsage(UsageFont, "doom")
+	m.AddUsage(UsageFont, "doom")
+	m.AddUsage(UsageFont, "small")
+	m.AddUsage(UsageColor, "fire")
+
+	if got, want := m.Usage(UsageFont), map[string]int64{"doom": 2, "small": 1}; !maps.Equal(got, want) {
+		t.Errorf("Usage(font) = %v, want %v", got, want)
+	}
+	if got, want := m.Usage(UsageColor), map[string]int64{"fire": 1}; !maps.Equal(got, want) {
+		t.Errorf("Usage(color) = %v, want %v", got, want)
+	}
+	if got := m.Usage(UsageAnimation); len(got) != 0 {
+		t.Errorf("Usage(animation) = %v, want none", got)
+	}
+}
@@ -0,0 +1,191 @@
+// Package vhost holds the branding and defaults of the host names one
+// instance serves, such as shout.example.com and yell.example.org, read
+// from a JSON file and chosen by the request's Host header.
+package vhost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ryanlewis/shout-sh/config"
+	"github.com/ryanlewis/shout-sh/render"
+)
+
+// maxFooter caps the length of a footer, in characters.
+const maxFooter = 200
+
+// Host is the branding and defaults of one host name.
+type Host struct {
+	// Name is the host name the entry is listed under
+	Name string `json:"-"`
+	// Font, Color and Align replace the server's defaults for requests
+	// without the option; Color is the host's theme, a preset or a color
+	Font  string `json:"font"`
+	Color string `json:"color"`
+	Align string `json:"align"`
+	// Footer is a line added below static banners
+	Footer string `json:"footer"`
+	// RequestsPerMinute limits each client of the host; 0 leaves the
+	// server's limits alone
+	RequestsPerMinute int `json:"requests_per_minute"`
+}
+
+// Hosts holds the hosts of a JSON file, loaded once.
+//
+// The file lists the hosts by name; a name starting with "*." matches
+// every subdomain:
+//
+//	{
+//	  "hosts": {
+//	    "shout.example.com": {"font": "doom", "color": "fire", "footer": "shout.example.com"},
+//	    "*.yell.example.org": {"font": "slant", "color": "ocean", "requests_per_minute": 30}
+//	  }
+//	}
+//
+// Usage example:
+//
+//	hosts, err := vhost.LoadFile("hosts.json")
+//	host := hosts.Lookup(c.Hostname())
+type Hosts struct {
+	// exact maps lowercase host names to their hosts
+	exact map[string]*Host
+	// wildcards maps the suffixes of "*." names, such as ".example.org",
+	// to their hosts
+	wildcards map[string]*Host
+}
+
+// hostsFile is the JSON layout of a hosts file.
+type hostsFile struct {
+	Hosts map[string]*Host `json:"hosts"`
+}
+
+// validAlignments are the values the align option accepts.
+var validAlignments = map[string]bool{"left": true, "center": true, "right": true}
+
+// Open loads the hosts file selected by the configuration.
+//
+// Parameters:
+//   - cfg: virtual host settings
+//
+// Returns:
+//   - *Hosts: the hosts, or nil when every host is served alike
+//   - error: error if the file can't be loaded
+//
+// Example:
+//
+//	hosts, err := vhost.Open(cfg.VHosts)
+//	if err != nil {
+//	    log.Fatalf("Failed to load virtual hosts: %v", err)
+//	}
+func Open(cfg config.VHostsConfig) (*Hosts, error) {
+	if cfg.File == "" {
+		return nil, nil
+	}
+	return LoadFile(cfg.File)
+}
+
+// LoadFile reads a hosts file.
+//
+// Parameters:
+//   - path: the JSON file of hosts
+//
+// Returns:
+//   - *Hosts: the hosts
+//   - error: error if the file can't be read, or a host has an invalid
+//     color, alignment, footer or rate limit
+func LoadFile(path string) (*Hosts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	var file hostsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid hosts file %s: %w", path, err)
+	}
+
+	hosts := &Hosts{exact: make(map[string]*Host), wildcards: make(map[string]*Host)}
+	for name, host := range file.Hosts {
+		if host == nil {
+			return nil, fmt.Errorf("host %q is empty", name)
+		}
+		if err := host.validate(); err != nil {
+			return nil, fmt.Errorf("host %q: %w", name, err)
+		}
+		host.Name = name
+		key := strings.ToLower(name)
+		if suffix, ok := strings.CutPrefix(key, "*"); ok && strings.HasPrefix(suffix, ".") {
+			hosts.wildcards[suffix] = host
+			continue
+		}
+		if _, ok := hosts.exact[key]; ok {
+			return nil, fmt.Errorf("host %q is listed twice", name)
+		}
+		hosts.exact[key] = host
+	}
+	return hosts, nil
+}
+
+// validate checks the host's options, which are otherwise only checked
+// when a request uses them.
+func (h *Host) validate() error {
+	if h.Color != "" {
+		if _, err := render.ParseColor(h.Color); err != nil {
+			return err
+		}
+	}
+	if h.Align != "" && !validAlignments[h.Align] {
+		return fmt.Errorf("invalid alignment %q, want left, center or right", h.Align)
+	}
+	if utf8.RuneCountInString(h.Footer) > maxFooter {
+		return fmt.Errorf("footer is longer than %d characters", maxFooter)
+	}
+	// Footers are sent to terminals, which act on control characters
+	if strings.IndexFunc(h.Footer, unicode.IsControl) >= 0 {
+		return fmt.Errorf("footer must be a single line without control characters")
+	}
+	if h.RequestsPerMinute < 0 {
+		return fmt.Errorf("requests_per_minute must not be negative")
+	}
+	return nil
+}
+
+// Lookup returns the host serving a request for host, with or without a
+// port: its own entry, or else the wildcard entry of the closest parent
+// domain.
+//
+// Parameters:
+//   - host: the request's host
+//
+// Returns:
+//   - *Host: the host, or nil if the file doesn't list it
+//
+// Example:
+//
+//	if host := hosts.Lookup("shout.example.com:8080"); host != nil {
+//	    font = host.Font
+//	}
+func (h *Hosts) Lookup(host string) *Host {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if v, ok := h.exact[host]; ok {
+		return v
+	}
+	for i := strings.IndexByte(host, '.'); i >= 0; {
+		if v, ok := h.wildcards[host[i:]]; ok {
+			return v
+		}
+		next := strings.IndexByte(host[i+1:], '.')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return nil
+}
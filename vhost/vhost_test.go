@@ -0,0 +1,128 @@
+package vhost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanlewis/shout-sh/config"
+)
+
+// writeHosts writes a hosts file and returns its path.
+func writeHosts(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hosts.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: `{"hosts": {"shout.example.com": {"font": "doom", "color": "fire", "align": "left", "footer": "shout.example.com", "requests_per_minute": 30}}}`,
+		},
+		{
+			name:    "custom color",
+			content: `{"hosts": {"shout.example.com": {"color": "#ff8800,#0088ff"}}}`,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"hosts": [`,
+			wantErr: true,
+		},
+		{
+			name:    "empty host",
+			content: `{"hosts": {"shout.example.com": null}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown color",
+			content: `{"hosts": {"shout.example.com": {"color": "nonesuch"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid alignment",
+			content: `{"hosts": {"shout.example.com": {"align": "justify"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "footer with escape",
+			content: `{"hosts": {"shout.example.com": {"footer": "\u001b[2J"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative rate limit",
+			content: `{"hosts": {"shout.example.com": {"requests_per_minute": -1}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "listed twice",
+			content: `{"hosts": {"shout.example.com": {}, "SHOUT.example.com": {}}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFile(writeHosts(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	hosts, err := LoadFile(writeHosts(t, `{"hosts": {
+		"shout.example.com": {"font": "doom"},
+		"*.example.org": {"font": "slant"},
+		"*.yell.example.org": {"font": "small"}
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "shout.example.com", want: "shout.example.com"},
+		{host: "Shout.Example.com:8080", want: "shout.example.com"},
+		{host: "shout.example.com.", want: "shout.example.com"},
+		{host: "yell.example.org", want: "*.example.org"},
+		{host: "a.yell.example.org", want: "*.yell.example.org"},
+		{host: "example.org"},
+		{host: "other.example.com"},
+		{host: "localhost"},
+		{host: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got := ""
+			if host := hosts.Lookup(tt.host); host != nil {
+				got = host.Name
+			}
+			if got != tt.want {
+				t.Errorf("Lookup(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	hosts, err := Open(config.VHostsConfig{})
+	if err != nil || hosts != nil {
+		t.Errorf("Open() without a file = %v, %v, want nil, nil", hosts, err)
+	}
+	if _, err := Open(config.VHostsConfig{File: filepath.Join(t.TempDir(), "nonesuch.json")}); err == nil {
+		t.Error("Open() with a missing file succeeded")
+	}
+}